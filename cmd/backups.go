@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/backup"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/vault"
+)
+
+// backupsCmd represents the backups command
+var backupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "List backups taken before destructive operations.",
+	Long: `List backups taken before destructive operations.
+
+Before a purge or an overwrite of remote changes, the remote file is
+saved to an encrypted local backup.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		p := ioStreams.Messages
+
+		backups, err := backup.List()
+		if err != nil {
+			display.ErrorText(p.Text(message.BackupsListFailed, err), ioStreams)
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(ioStreams.UserOutput)
+
+		for _, b := range backups {
+			fmt.Fprintf(ioStreams.UserOutput, "|- %s %s", p.Style(message.Path, b.ID), b.Path)
+			if len(b.Version) > 0 {
+				fmt.Fprintf(ioStreams.UserOutput, "(%s)", b.Version)
+			}
+			fmt.Fprintf(ioStreams.UserOutput, " %s %s\n", p.Style(message.Store, "["+b.Store+"]"), p.Text(message.BackupBefore, b.Reason))
+		}
+
+		fmt.Fprintf(ioStreams.UserOutput, "\n%s\n\n", p.Style(message.Summary, p.Text(message.BackupsSummary, len(backups))))
+	},
+}
+
+// restoreBackupCmd represents the backups restore command
+var restoreBackupCmd = &cobra.Command{
+	Use:   "restore {id}",
+	Short: "Restore a backup to the local file.",
+	Long: `Restore a backup to the local file.
+
+Push the restored file to restore it remotely.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			display.ErrorText(ioStreams.Messages.Text(message.BackupRestoreUsage), ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions([]string{})
+
+		if err := RestoreBackup(args[0], uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// RestoreBackup ...
+func RestoreBackup(id string, opt cfg.UserOptions, io models.IO) error {
+	b, err := backup.Get(id)
+	if err != nil {
+		return err
+	}
+
+	//-------------------------------------------------
+	//- Restore relative to the catalog that was used.
+	//-------------------------------------------------
+	fullPath := b.Path
+	clog := catalog.Catalog{Context: b.Context}
+	if c, err := catalog.Get(opt.Catalog); err == nil && c.Context == b.Context {
+		fullPath, clog = c.GetFullPath(b.Path), c
+	}
+
+	//-------------------------------------------------
+	//- Decrypt using the key in the access vault.
+	//-------------------------------------------------
+	fileEntry := catalog.File{Path: b.Path, Store: b.Store}
+
+	access, err := vault.GetBy(b.Vault, cfg.DefaultAccessVault, clog, &fileEntry, opt.Prompt, io)
+	if err != nil {
+		return fmt.Errorf("Could not open the %s vault holding the key for backup %s! (%s)", b.Vault, id, err)
+	}
+
+	key, err := backup.Key(access, b.Context, io)
+	if err != nil {
+		return err
+	}
+
+	data, err := b.Open(key)
+	if err != nil {
+		return err
+	}
+
+	p := io.Messages
+
+	if _, err := os.Stat(fullPath); err == nil {
+		confirmed, err := prompt.Confirm(p.Text(message.BackupReplace, fullPath, id), prompt.Warn, io)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			fmt.Fprintf(io.UserOutput, "\n%s\n", p.Style(message.Failure, p.Text(message.Aborted)))
+			return nil
+		}
+	}
+
+	if err := localFile.Save(fullPath, data); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(io.UserOutput)
+	p.Println(io.UserOutput, message.BackupRestored, p.Style(message.Path, fullPath))
+
+	push := fmt.Sprintf("cstore push %s", b.Path)
+	if len(b.Version) > 0 {
+		push = fmt.Sprintf("%s -v %s", push, b.Version)
+	}
+
+	fmt.Fprintf(io.UserOutput, "\n%s\n\n", p.Text(message.BackupPushHint, push))
+
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(backupsCmd)
+	backupsCmd.AddCommand(restoreBackupCmd)
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/session"
+)
+
+// bundleDebugCmd represents the bundle-debug command
+var bundleDebugCmd = &cobra.Command{
+	Use:   "bundle-debug",
+	Short: "Save the last failed command in an encrypted support bundle.",
+	Long: `Save the last failed command in an encrypted support bundle.
+
+The bundle holds a transcript of the command's output, the store calls
+and HTTP requests it made, the cstore version, the user config, and a
+summary of the catalog without file data. Credentials and text matching
+the redaction patterns are removed.
+
+Attach the bundle to an issue and share the key printed only with the
+maintainers. Maintainers open a bundle with '--open'.
+
+	$ cstore bundle-debug
+	$ cstore bundle-debug --open cstore-debug-20260301-120000.bundle --key {key}`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		if len(uo.Open) > 0 {
+			if err := openDebugBundle(uo.Open, uo.Key, ioStreams); err != nil {
+				display.ErrorText(ioStreams.Messages.Text(message.BundleOpenFailed, uo.Open, err), ioStreams)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if err := BundleDebug(uo, ioStreams); err != nil {
+			display.ErrorText(ioStreams.Messages.Text(message.BundleFailed, err), ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// BundleDebug saves the transcript of the last failed command with the
+// environment it ran in to an encrypted bundle.
+func BundleDebug(opt cfg.UserOptions, io models.IO) error {
+	events, err := session.Failed()
+	if err != nil {
+		return err
+	}
+
+	transcript, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	environment, err := json.MarshalIndent(debugEnvironment(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		"transcript.json":  transcript,
+		"environment.json": environment,
+	}
+
+	if clog, err := catalog.Get(opt.Catalog); err == nil {
+		summary, err := json.MarshalIndent(debugCatalog(clog), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		files["catalog.json"] = summary
+	}
+
+	bundle, key, err := session.Bundle(files)
+	if err != nil {
+		return err
+	}
+
+	output := opt.OutputFile
+	if len(output) == 0 {
+		output = fmt.Sprintf("cstore-debug-%s.bundle", time.Now().Format("20060102-150405"))
+	}
+
+	if err := ioutil.WriteFile(output, bundle, 0600); err != nil {
+		return err
+	}
+
+	p := io.Messages
+
+	fmt.Fprintln(io.UserOutput, p.Text(message.BundleSaved, p.Style(message.Path, output)))
+	fmt.Fprintln(io.UserOutput)
+	fmt.Fprintln(io.UserOutput, p.Text(message.BundleKey))
+	fmt.Fprintln(io.UserOutput, p.Style(message.Heading, key))
+
+	return nil
+}
+
+func openDebugBundle(bundlePath, key string, io models.IO) error {
+	if len(key) == 0 {
+		return fmt.Errorf("--key is required")
+	}
+
+	b, err := ioutil.ReadFile(bundlePath)
+	if err != nil {
+		return err
+	}
+
+	files, err := session.OpenBundle(b, key)
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(io.Export, "==> %s <==\n%s\n\n", name, files[name])
+	}
+
+	return nil
+}
+
+// debugEnvironment describes where cstore ran. Settings that may hold
+// credentials are redacted.
+func debugEnvironment() map[string]interface{} {
+	settings := map[string]string{}
+
+	for _, key := range viper.AllKeys() {
+		value := fmt.Sprint(viper.Get(key))
+
+		if isSensitiveName(key) && len(value) > 0 {
+			value = display.Redacted
+		}
+
+		settings[key] = session.Redact(value)
+	}
+
+	variables := []string{}
+	for _, e := range os.Environ() {
+		name := strings.SplitN(e, "=", 2)[0]
+
+		if strings.HasPrefix(name, "CSTORE_") || strings.HasPrefix(name, "AWS_") {
+			variables = append(variables, name)
+		}
+	}
+	sort.Strings(variables)
+
+	return map[string]interface{}{
+		"version":   cfg.Version,
+		"goVersion": runtime.Version(),
+		"platform":  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		"settings":  settings,
+		"variables": variables,
+	}
+}
+
+// debugFile describes a file in the catalog without its data.
+type debugFile struct {
+	Path     string        `json:"path"`
+	Store    string        `json:"store"`
+	Type     string        `json:"type"`
+	Vaults   catalog.Vault `json:"vaults"`
+	Tags     []string      `json:"tags,omitempty"`
+	Pipeline []string      `json:"pipeline,omitempty"`
+	Versions int           `json:"versions,omitempty"`
+	IsRef    bool          `json:"isRef,omitempty"`
+}
+
+func debugCatalog(clog catalog.Catalog) map[string]interface{} {
+	files := []debugFile{}
+
+	for _, f := range clog.Files {
+		files = append(files, debugFile{
+			Path:     session.Redact(f.Path),
+			Store:    f.Store,
+			Type:     f.Type,
+			Vaults:   f.Vaults,
+			Tags:     f.Tags,
+			Pipeline: f.Pipeline,
+			Versions: len(f.Versions),
+			IsRef:    f.IsRef,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return map[string]interface{}{
+		"version": clog.Version,
+		"files":   files,
+	}
+}
+
+func isSensitiveName(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, s := range []string{"secret", "token", "password", "key", "credential"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	RootCmd.AddCommand(bundleDebugCmd)
+
+	bundleDebugCmd.Flags().StringVarP(&uo.OutputFile, "output-file", "o", "", "Path of the bundle to save.")
+	bundleDebugCmd.Flags().StringVarP(&uo.Open, "open", "", "", "Decrypt a bundle and send its contents to stdout.")
+	bundleDebugCmd.Flags().StringVarP(&uo.Key, "key", "k", "", "Key printed when the bundle was saved.")
+}
@@ -0,0 +1,442 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/cache"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/logger"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/path"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:     "check",
+	Aliases: []string{"status"},
+	Short:   "Check cataloged files for overdue rotations and sunset keys.",
+	Long: `Check cataloged files for overdue rotations and sunset keys.
+
+Compares when each file or key was last modified remotely to the
+rotation interval declared in the catalog.
+
+Deprecated keys still in a file after their sunset date fail the
+check.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		checks, err := checkRotationsFor(uo.Catalog, uo, ioStreams)
+		if err != nil {
+			display.ErrorText(ioStreams.Messages.Text(message.CheckRotationsFailed, uo.Catalog, err), ioStreams)
+			os.Exit(1)
+		}
+
+		//-------------------------------------------------
+		//- Render user template when specified.
+		//-------------------------------------------------
+		if len(uo.Template) > 0 {
+			renderChecks(checks, time.Now(), uo, ioStreams)
+			return
+		}
+
+		p := ioStreams.Messages
+
+		fmt.Fprintln(ioStreams.UserOutput)
+
+		overdue := printRotations(checks, time.Now(), ioStreams)
+
+		fmt.Fprintf(ioStreams.UserOutput, "\n%s\n\n", p.Style(message.Summary, p.Text(message.CheckOverdueSummary, overdue, len(checks))))
+
+		deprecations, err := checkDeprecationsFor(uo.Catalog, uo, ioStreams)
+		if err != nil {
+			display.ErrorText(p.Text(message.CheckDeprecationsFailed, uo.Catalog, err), ioStreams)
+			os.Exit(1)
+		}
+
+		sunset := 0
+		if len(deprecations) > 0 {
+			sunset = printDeprecations(deprecations, time.Now(), ioStreams)
+
+			fmt.Fprintf(ioStreams.UserOutput, "\n%s\n\n", p.Style(message.Summary, p.Text(message.CheckSunsetSummary, sunset, len(deprecations))))
+		}
+
+		if (overdue > 0 && uo.FailOverdue) || sunset > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// checkReport is the data available to check templates.
+type checkReport struct {
+	Now          time.Time
+	Rotations    []rotationCheck
+	Deprecations []deprecationCheck
+}
+
+// renderChecks sends the rotations and deprecations to stdout using the
+// user's template, exiting like the text report when any check fails.
+func renderChecks(checks []rotationCheck, now time.Time, opt cfg.UserOptions, io models.IO) {
+	deprecations, err := checkDeprecationsFor(opt.Catalog, opt, io)
+	if err != nil {
+		display.ErrorText(io.Messages.Text(message.CheckDeprecationsFailed, opt.Catalog, err), io)
+		os.Exit(1)
+	}
+
+	report := checkReport{Now: now, Rotations: checks, Deprecations: deprecations}
+
+	if err := display.Template(opt.Template, report, io.Export); err != nil {
+		display.ErrorText(io.Messages.Text(message.TemplateFailed, err), io)
+		os.Exit(1)
+	}
+
+	overdue := 0
+	for _, c := range checks {
+		if c.Overdue(now) {
+			overdue++
+		}
+	}
+
+	sunset := 0
+	for _, c := range deprecations {
+		if c.PastSunset(now) {
+			sunset++
+		}
+	}
+
+	if (overdue > 0 && opt.FailOverdue) || sunset > 0 {
+		os.Exit(1)
+	}
+}
+
+// rotationCheck is a file or key with a declared rotation interval and
+// the time it was last rotated. Rotated is zero when the store does not
+// know when the file or key changed.
+type rotationCheck struct {
+	Path     string
+	Store    string
+	Key      string
+	Interval string
+	Every    time.Duration
+	Rotated  time.Time
+}
+
+// Due ...
+func (r rotationCheck) Due() time.Time {
+	return r.Rotated.Add(r.Every)
+}
+
+// Overdue ...
+func (r rotationCheck) Overdue(now time.Time) bool {
+	return !r.Rotated.IsZero() && now.After(r.Due())
+}
+
+func checkRotationsFor(catalogPath string, opt cfg.UserOptions, io models.IO) ([]rotationCheck, error) {
+	basePath := path.RemoveFileName(catalogPath)
+
+	checks := []rotationCheck{}
+
+	//-------------------------------------------------
+	//- Get catalog containing files to check.
+	//-------------------------------------------------
+	clog, err := catalog.Get(catalogPath)
+	if err != nil {
+		return checks, err
+	}
+
+	for _, fileEntry := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, "") {
+		fullPath := path.BuildPath(basePath, fileEntry.Path)
+
+		//-------------------------------------------------
+		//- If entry is catalog, check child entries.
+		//-------------------------------------------------
+		if fileEntry.IsRef {
+			children, err := checkRotationsFor(fullPath, opt, io)
+			if err != nil {
+				return checks, err
+			}
+
+			checks = append(checks, children...)
+
+			continue
+		}
+
+		if !fileEntry.Rotation.Declared() {
+			continue
+		}
+
+		fileChecks, err := checkRotationsOf(fileEntry, fullPath, clog, opt, io)
+		if err != nil {
+			display.ErrorText(io.Messages.Text(message.CheckRotationsFailed, fullPath, err), io)
+			continue
+		}
+
+		checks = append(checks, fileChecks...)
+	}
+
+	return checks, nil
+}
+
+func checkRotationsOf(fileEntry catalog.File, fullPath string, clog catalog.Catalog, opt cfg.UserOptions, io models.IO) ([]rotationCheck, error) {
+	checks := []rotationCheck{}
+
+	//-------------------------------------------------
+	//- Parse the declared intervals.
+	//-------------------------------------------------
+	if len(fileEntry.Rotation.Every) > 0 {
+		every, err := catalog.ParseInterval(fileEntry.Rotation.Every)
+		if err != nil {
+			return checks, err
+		}
+
+		checks = append(checks, rotationCheck{Interval: fileEntry.Rotation.Every, Every: every})
+	}
+
+	for key, interval := range fileEntry.Rotation.Keys {
+		every, err := catalog.ParseInterval(interval)
+		if err != nil {
+			return checks, fmt.Errorf("%s key %s", err, key)
+		}
+
+		checks = append(checks, rotationCheck{Key: key, Interval: interval, Every: every})
+	}
+
+	//-------------------------------------------------
+	//- Get when the file and keys were last modified.
+	//-------------------------------------------------
+	modified, keysModified, err := modifiedTimesOf(fileEntry, clog, len(fileEntry.Rotation.Keys) > 0, opt, io)
+	if err != nil {
+		return checks, err
+	}
+
+	for i := range checks {
+		checks[i].Path = fullPath
+		checks[i].Store = fileEntry.Store
+		checks[i].Rotated = modified
+
+		if len(checks[i].Key) > 0 {
+			checks[i].Rotated = keysModified[checks[i].Key]
+		}
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Key < checks[j].Key
+	})
+
+	return checks, nil
+}
+
+// modifiedTimesOf returns when a file and, when requested, its keys
+// were last modified. Times cached by a recent check are used unless
+// the user requests a refresh.
+func modifiedTimesOf(fileEntry catalog.File, clog catalog.Catalog, withKeys bool, opt cfg.UserOptions, io models.IO) (time.Time, map[string]time.Time, error) {
+	modified, changedCached := time.Time{}, false
+	keysModified, keysCached := map[string]time.Time{}, !withKeys
+
+	if !opt.Refresh {
+		modified, changedCached = cache.GetChanged(clog.Context, fileEntry.Key(), cache.MetadataTTL)
+
+		if withKeys {
+			keysModified, keysCached = cache.GetKeys(clog.Context, fileEntry.Key(), cache.MetadataTTL)
+		}
+	}
+
+	if changedCached && keysCached {
+		return modified, keysModified, nil
+	}
+
+	//--------------------------------------------------
+	//- Get the remote store and vault components ready.
+	//--------------------------------------------------
+	remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+	if err != nil {
+		return modified, keysModified, err
+	}
+
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return modified, keysModified, fmt.Errorf("Failed to refresh %s credentials. (%s)", remoteComp.store.Name(), err)
+	}
+
+	if !changedCached {
+		file, _ := localFile.GetBy(clog.GetFullPath(fileEntry.Path))
+
+		done := measure(remoteComp.store.Name(), "changed")
+		modified, err = remoteComp.store.Changed(&fileEntry, file, "")
+		done(err)
+		if err != nil {
+			return modified, keysModified, err
+		}
+
+		if err := cache.SaveChanged(clog.Context, fileEntry.Key(), modified); err != nil {
+			logger.L.Print(err)
+		}
+	}
+
+	if _, ok := store.Unwrap(remoteComp.store).(contract.IKeyStore); ok && !keysCached {
+		done := measure(remoteComp.store.Name(), "keys")
+		keysModified, err = remoteComp.store.(contract.IKeyStore).KeysModified(&fileEntry, "")
+		done(err)
+		if err != nil {
+			return modified, keysModified, err
+		}
+
+		if err := cache.SaveKeys(clog.Context, fileEntry.Key(), keysModified); err != nil {
+			logger.L.Print(err)
+		}
+	}
+
+	return modified, keysModified, nil
+}
+
+func printRotations(checks []rotationCheck, now time.Time, io models.IO) int {
+	overdue := 0
+	p := io.Messages
+
+	for _, c := range checks {
+		name := p.Text(message.WholeFile)
+		if len(c.Key) > 0 {
+			name = c.Key
+		}
+
+		fmt.Fprintf(io.UserOutput, "|- %s %s ", p.Style(message.Path, c.Path), p.Text(message.CheckRotation, name, c.Interval))
+
+		switch {
+		case c.Rotated.IsZero():
+			fmt.Fprintln(io.UserOutput, p.Style(message.Caution, p.Text(message.CheckRotationUnknown)))
+		case c.Overdue(now):
+			overdue++
+			fmt.Fprintln(io.UserOutput, p.Style(message.Failure, p.Text(message.CheckOverdue, formatDays(now.Sub(c.Due())))))
+		default:
+			fmt.Fprintln(io.UserOutput, p.Style(message.Success, p.Text(message.CheckDue, formatDays(c.Due().Sub(now)))))
+		}
+	}
+
+	return overdue
+}
+
+// deprecationCheck is a deprecated key and whether a file still
+// contains it.
+type deprecationCheck struct {
+	Path        string
+	Key         string
+	Deprecation catalog.Deprecation
+	Found       bool
+}
+
+// PastSunset is true when the key is still in the file after its
+// sunset date or the sunset date is invalid.
+func (c deprecationCheck) PastSunset(now time.Time) bool {
+	passed, err := c.Deprecation.Sunsetted(now)
+	return err != nil || (c.Found && passed)
+}
+
+func checkDeprecationsFor(catalogPath string, opt cfg.UserOptions, io models.IO) ([]deprecationCheck, error) {
+	basePath := path.RemoveFileName(catalogPath)
+
+	checks := []deprecationCheck{}
+
+	clog, err := catalog.Get(catalogPath)
+	if err != nil {
+		return checks, err
+	}
+
+	for _, fileEntry := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, "") {
+		fullPath := path.BuildPath(basePath, fileEntry.Path)
+
+		if fileEntry.IsRef {
+			children, err := checkDeprecationsFor(fullPath, opt, io)
+			if err != nil {
+				return checks, err
+			}
+
+			checks = append(checks, children...)
+
+			continue
+		}
+
+		if len(fileEntry.Deprecated) == 0 || !fileEntry.SupportsConfig() {
+			continue
+		}
+
+		//-------------------------------------------------
+		//- Look for the deprecated keys in the file.
+		//-------------------------------------------------
+		file, err := exampleSource(fileEntry, clog.GetFullPath(fullPath), clog, opt, io)
+		if err != nil {
+			display.ErrorText(io.Messages.Text(message.CheckDeprecationsFailed, fullPath, err), io)
+			continue
+		}
+
+		environment := gotenv.Parse(bytes.NewReader(file))
+
+		for _, key := range fileEntry.DeprecatedKeys() {
+			_, found := environment[key]
+
+			checks = append(checks, deprecationCheck{
+				Path:        fullPath,
+				Key:         key,
+				Deprecation: fileEntry.Deprecated[key],
+				Found:       found,
+			})
+		}
+	}
+
+	return checks, nil
+}
+
+func printDeprecations(checks []deprecationCheck, now time.Time, io models.IO) int {
+	sunset := 0
+	p := io.Messages
+
+	for _, c := range checks {
+		fmt.Fprintf(io.UserOutput, "|- %s %s ", p.Style(message.Path, c.Path), c.Deprecation.Message(c.Key))
+
+		passed, err := c.Deprecation.Sunsetted(now)
+		sunsetTime, _ := c.Deprecation.SunsetTime()
+
+		switch {
+		case err != nil:
+			sunset++
+			fmt.Fprintln(io.UserOutput, p.Style(message.Failure, fmt.Sprintf("(%s)", err)))
+		case !c.Found:
+			fmt.Fprintln(io.UserOutput, p.Style(message.Success, p.Text(message.CheckRemoved)))
+		case passed:
+			sunset++
+			fmt.Fprintln(io.UserOutput, p.Style(message.Failure, p.Text(message.CheckPastSunset, formatDays(now.Sub(sunsetTime)))))
+		case sunsetTime.IsZero():
+			fmt.Fprintln(io.UserOutput, p.Style(message.Caution, p.Text(message.CheckNoSunset)))
+		default:
+			fmt.Fprintln(io.UserOutput, p.Style(message.Caution, p.Text(message.CheckSunsets, formatDays(sunsetTime.Sub(now)))))
+		}
+	}
+
+	return sunset
+}
+
+func formatDays(d time.Duration) string {
+	if days := int(d.Hours() / 24); days > 0 {
+		return fmt.Sprintf("%dd", days)
+	}
+
+	return fmt.Sprintf("%dh", int(d.Hours()))
+}
+
+func init() {
+	RootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	checkCmd.Flags().BoolVarP(&uo.Refresh, "refresh", "", false, "Query stores instead of using recently cached modified times.")
+	checkCmd.Flags().BoolVarP(&uo.FailOverdue, "fail-overdue", "", false, "Exit with a non-zero status when any rotation is overdue.")
+	checkCmd.Flags().StringVarP(&uo.Template, "template", "", "", "Format output using a Go template or template file and send to stdout.")
+}
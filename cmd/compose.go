@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/compose"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/env"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+// composeCmd represents the compose command
+var composeCmd = &cobra.Command{
+	Use:   "compose [service_1] [service_2] ...",
+	Short: "Export an env file for each docker-compose service.",
+	Long: `Export an env file for each docker-compose service.
+
+The files mapped to each service in the catalog's compose section are
+pulled and merged in the order listed into an env file for the service.
+When services are not specified, every mapped service is exported.
+
+Use --patch to add each env file to the service's env_file list in
+docker-compose.override.yml.
+
+	compose:
+	  services:
+	    api: [shared.env, api/.env]
+	    worker: [shared.env, worker/.env]`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions([]string{})
+
+		if err := Compose(uo, args, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Compose writes the merged env file for each requested service and
+// patches the compose override file when requested.
+func Compose(opt cfg.UserOptions, services []string, io models.IO) error {
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	if len(clog.Compose.Services) == 0 {
+		return fmt.Errorf("%s does not map files to compose services. Add a compose section to the catalog.", opt.Catalog)
+	}
+
+	if len(services) == 0 {
+		for service := range clog.Compose.Services {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+
+	//-------------------------------------------------
+	//- Merge the files for each service pulling each
+	//- file once.
+	//-------------------------------------------------
+	p := io.Messages
+
+	fmt.Fprintln(io.UserOutput)
+
+	layers := map[string]env.Layer{}
+	envFiles := map[string]string{}
+
+	for _, service := range services {
+		paths, found := clog.Compose.Services[service]
+		if !found {
+			return fmt.Errorf("%s does not map files to the %s service.", opt.Catalog, service)
+		}
+
+		serviceLayers := []env.Layer{}
+
+		for _, filePath := range paths {
+			layer, pulled := layers[filePath]
+
+			if !pulled {
+				fileEntry, found := catalog.File{}, false
+				for _, f := range clog.Files {
+					if f.Path == filePath && !f.IsRef {
+						fileEntry, found = f, true
+					}
+				}
+
+				if !found {
+					return fmt.Errorf("%s is not aware of %s mapped to the %s service. Use 'list' command to view available files.", opt.Catalog, filePath, service)
+				}
+
+				if layer, err = pullLayer("compose", clog, fileEntry, opt, io); err != nil {
+					return err
+				}
+
+				layers[filePath] = layer
+			}
+
+			serviceLayers = append(serviceLayers, layer)
+		}
+
+		merged, collisions := env.Merge(serviceLayers)
+
+		for _, c := range collisions {
+			fmt.Fprintln(io.UserOutput, p.Style(message.Caution, p.Text(message.ComposeCollision, c.Key, strings.Join(c.Sources, ", "), service, c.Sources[len(c.Sources)-1])))
+		}
+
+		envFile := clog.Compose.EnvFile(service)
+
+		if err := localFile.Save(clog.GetFullPath(envFile), env.Format(merged)); err != nil {
+			return err
+		}
+
+		envFiles[service] = envFile
+
+		p.Println(io.UserOutput, message.ComposeExporting, p.Style(message.Path, envFile), p.Style(message.Store, service))
+	}
+
+	//-------------------------------------------------
+	//- Reference the env files in the override file.
+	//-------------------------------------------------
+	if opt.PatchCompose {
+		if err := patchCompose(clog, envFiles); err != nil {
+			return fmt.Errorf("Failed to patch %s. (%s)", clog.Compose.OverrideFile(), err)
+		}
+
+		p.Println(io.UserOutput, message.ComposePatched, p.Style(message.Path, clog.Compose.OverrideFile()))
+	}
+
+	p.Summary(io.UserOutput, message.ComposeSummary, len(envFiles))
+
+	return nil
+}
+
+// patchCompose adds the env files to the override file. A new override
+// file declares the same version as docker-compose.yml.
+func patchCompose(clog catalog.Catalog, envFiles map[string]string) error {
+	override := clog.Compose.OverrideFile()
+	overridePath := clog.GetFullPath(override)
+
+	file, err := ioutil.ReadFile(overridePath)
+	if os.IsNotExist(err) {
+		file = []byte{}
+
+		base, _ := ioutil.ReadFile(clog.GetFullPath(filepath.Join(filepath.Dir(override), "docker-compose.yml")))
+		if version := compose.Version(base); len(version) > 0 {
+			file = []byte(fmt.Sprintf("version: '%s'\n", version))
+		}
+	} else if err != nil {
+		return err
+	}
+
+	//-------------------------------------------------
+	//- env_file paths are relative to the folder of
+	//- the override file.
+	//-------------------------------------------------
+	relative := map[string]string{}
+
+	for service, envFile := range envFiles {
+		rel, err := filepath.Rel(filepath.Dir(override), envFile)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, "../") {
+			rel = "./" + rel
+		}
+
+		relative[service] = rel
+	}
+
+	patched, err := compose.Patch(file, relative)
+	if err != nil {
+		return err
+	}
+
+	return localFile.Save(overridePath, patched)
+}
+
+func init() {
+	RootCmd.AddCommand(composeCmd)
+
+	composeCmd.Flags().BoolVarP(&uo.PatchCompose, "patch", "", false, "Add the env files to the services in the compose override file.")
+	composeCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Set a version to identify a file specific state.")
+	composeCmd.Flags().BoolVarP(&uo.InjectSecrets, "inject-secrets", "i", false, "Inject secrets into the env files.")
+	composeCmd.Flags().StringVarP(&uo.Justification, "justification", "", "", "Explain why access is needed when using protected files.")
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+// contextCmd represents the context command
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "List, create, and switch catalog contexts.",
+	Long: `List, create, and switch catalog contexts.
+
+The context namespaces files in the remote store. Switching contexts
+lets the same catalog target different namespaces, like one per
+developer or stack, without editing the catalog.`,
+}
+
+// listContextsCmd represents the context list command
+var listContextsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the catalog's contexts.",
+	Long:  `List the catalog's contexts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions([]string{})
+
+		if err := ListContexts(uo.Catalog, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// useContextCmd represents the context use command
+var useContextCmd = &cobra.Command{
+	Use:   "use {context}",
+	Short: "Switch the context used with the catalog.",
+	Long: `Switch the context used with the catalog.
+
+The selection is saved for this working copy only; the catalog file
+is not changed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			display.ErrorText(ioStreams.Messages.Text(message.ContextUseUsage), ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions([]string{})
+
+		if err := SwitchContext(uo.Catalog, args[0], false, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// createContextCmd represents the context create command
+var createContextCmd = &cobra.Command{
+	Use:   "create {context}",
+	Short: "Add a context to the catalog and switch to it.",
+	Long:  `Add a context to the catalog and switch to it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			display.ErrorText(ioStreams.Messages.Text(message.ContextCreateUsage), ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions([]string{})
+
+		if err := SwitchContext(uo.Catalog, args[0], true, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// ListContexts ...
+func ListContexts(catalogName string, io models.IO) error {
+	clog, err := catalog.Get(catalogName)
+	if err != nil {
+		return err
+	}
+
+	p := io.Messages
+
+	fmt.Fprintln(io.UserOutput)
+
+	for _, context := range clog.KnownContexts() {
+		if context == clog.Context {
+			fmt.Fprint(io.UserOutput, "* "+p.Style(message.Path, context))
+		} else {
+			fmt.Fprintf(io.UserOutput, "  %s", context)
+		}
+
+		if context == clog.DefaultContext() {
+			fmt.Fprint(io.UserOutput, " "+p.Text(message.ContextDefault))
+		}
+
+		fmt.Fprintln(io.UserOutput)
+	}
+
+	fmt.Fprintln(io.UserOutput)
+
+	return nil
+}
+
+// SwitchContext selects the context used with the catalog, adding it to
+// the catalog first when create is true.
+func SwitchContext(catalogName, context string, create bool, io models.IO) error {
+	clog, err := catalog.Get(catalogName)
+	if err != nil {
+		return err
+	}
+
+	if create {
+		if err := catalog.ValidContext(context); err != nil {
+			return err
+		}
+
+		if clog.Knows(context) {
+			return fmt.Errorf("%s already has context %s. Use 'context use %s' to switch to it.", catalogName, context, context)
+		}
+
+		clog.Contexts = append(clog.Contexts, context)
+
+		if err := catalog.Write(clog.GetFullPath(catalogName), clog); err != nil {
+			return err
+		}
+	} else if !clog.Knows(context) {
+		return fmt.Errorf("%s does not have context %s. Use 'context create %s' to add it.", catalogName, context, context)
+	}
+
+	if err := catalog.UseContext(clog.GetFullPath(catalogName), clog, context); err != nil {
+		return err
+	}
+
+	p := io.Messages
+
+	fmt.Fprintln(io.UserOutput)
+	p.Println(io.UserOutput, message.ContextUsing, p.Style(message.Path, context))
+	fmt.Fprintln(io.UserOutput)
+
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(contextCmd)
+
+	contextCmd.AddCommand(listContextsCmd)
+	contextCmd.AddCommand(useContextCmd)
+	contextCmd.AddCommand(createContextCmd)
+}
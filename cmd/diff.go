@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/diff"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/env"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/store"
+	"github.com/turnerlabs/cstore/components/structured"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare local files to the files in their stores.",
+	Long: `Compare local files to the files in their stores.
+
+Files are pulled into memory and compared to the local files without
+saving anything. Env files are compared by key and other files by line.
+Lines starting with '-' are only in the store and lines starting with
+'+' are only in the local file; so, a push would make the '+' changes.
+
+Values are masked unless the key type is plain or reference. Use
+--reveal to show every value.
+
+Use --exit-code to exit with 1 when any file differs, or 2 when a file
+cannot be compared, to detect drift in CI.
+
+	$ cstore diff .env
+	$ cstore diff -t prod --exit-code`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		results, err := diffFiles(uo, ioStreams)
+
+		writeOutput(commandOutput{Command: "diff", Files: resultOutputs(results)}, err, uo, ioStreams)
+
+		//-------------------------------------------------
+		//- Render user template when specified.
+		//-------------------------------------------------
+		if len(uo.Template) > 0 && uo.OutputFormat != outputJSON && err == nil {
+			if terr := display.Template(uo.Template, resultOutputs(results), ioStreams.Export); terr != nil {
+				err = errors.New(ioStreams.Messages.Text(message.TemplateFailed, terr))
+			}
+		}
+
+		if err != nil {
+			display.Error(err, ioStreams)
+
+			if uo.ExitCode {
+				os.Exit(2)
+			}
+			os.Exit(1)
+		}
+
+		if uo.ExitCode && differing(results) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// Diff prints the differences between the requested local files and
+// their stored copies returning the number of files that differ.
+func Diff(opt cfg.UserOptions, io models.IO) (int, error) {
+	results, err := diffFiles(opt, io)
+	return differing(results), err
+}
+
+// differing returns the number of files that differ from their stores.
+func differing(results []fileResult) int {
+	differ := 0
+	for _, r := range results {
+		if r.Result == resultDiffers {
+			differ++
+		}
+	}
+	return differ
+}
+
+// diffFiles prints the differences between the requested local files
+// and their stored copies returning whether each file differs. With
+// JSON or template output, the differences are sent to stderr instead
+// of stdout.
+func diffFiles(opt cfg.UserOptions, io models.IO) ([]fileResult, error) {
+	results := []fileResult{}
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return results, err
+	}
+
+	files := []catalog.File{}
+	for _, f := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, opt.Version) {
+		if !f.IsRef {
+			files = append(files, f)
+		}
+	}
+
+	if len(files) == 0 {
+		return results, fmt.Errorf("%s is not aware of requested files. Use 'list' command to view available files.", opt.Catalog)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	fmt.Fprintln(io.UserOutput)
+
+	w := io.Export
+	if opt.OutputFormat == outputJSON || len(opt.Template) > 0 {
+		w = io.UserOutput
+	}
+
+	differ := 0
+
+	for _, fileEntry := range files {
+		remote, storeName, err := pullForDiff(clog, fileEntry, opt, io)
+		if err != nil {
+			results = append(results, fileResult{Path: fileEntry.Path, Store: fileEntry.Store, Result: resultFailed, Err: err})
+			return results, err
+		}
+
+		local, err := localFile.GetBy(clog.GetFullPath(fileEntry.Path))
+		localName := "local"
+		if err != nil {
+			local, localName = []byte{}, "local, missing"
+		}
+
+		a := fmt.Sprintf("%s (%s)", fileEntry.Path, storeName)
+		b := fmt.Sprintf("%s (%s)", fileEntry.Path, localName)
+
+		different := false
+		if fileEntry.SupportsConfig() || fileEntry.Flatten {
+			different = printEnvDiff(fileEntry, a, b, remote, local, opt.Reveal, w)
+		} else {
+			different = printLineDiff(a, b, remote, local, opt.Reveal, w)
+		}
+
+		result := fileResult{Path: fileEntry.Path, Store: storeName, Result: resultMatches}
+		if different {
+			result.Result, result.Keys = resultDiffers, changedKeys(fileEntry, remote, local)
+			differ++
+		}
+
+		results = append(results, result)
+	}
+
+	io.Messages.Summary(io.UserOutput, message.DiffSummary, differ, len(files))
+
+	return results, nil
+}
+
+// pullForDiff retrieves the stored copy of a file as it would be saved
+// by a pull.
+func pullForDiff(clog catalog.Catalog, fileEntry catalog.File, opt cfg.UserOptions, io models.IO) ([]byte, string, error) {
+	fileEntry = overrideFileSettings(fileEntry, opt)
+
+	fileEntryTemp := fileEntry
+	remoteComp, err := getRemoteComponents(&fileEntryTemp, clog, opt, io)
+	if err != nil {
+		return nil, "", fmt.Errorf("Could not retrieve %s! (%s)", fileEntry.Path, err)
+	}
+
+	if err := justify("diff", fileEntry, clog, remoteComp, opt); err != nil {
+		return nil, "", fmt.Errorf("Could not retrieve %s! (%s)", fileEntry.Path, err)
+	}
+
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return nil, "", fmt.Errorf("Failed to refresh %s credentials. (%s)", remoteComp.store.Name(), err)
+	}
+
+	done := measure(remoteComp.store.Name(), "pull")
+	file, _, err := remoteComp.store.Pull(&fileEntry, opt.Version)
+	done(err)
+	if err != nil {
+		return nil, "", fmt.Errorf("Could not retrieve %s! (%s)", fileEntry.Path, err)
+	}
+
+	file, err = applyTransforms(file, fileEntry, fileEntry.Transforms.Pull)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to transform %s! (%s)", fileEntry.Path, err)
+	}
+
+	file, err = renameKeys(file, fileEntry, false)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to rename keys in %s! (%s)", fileEntry.Path, err)
+	}
+
+	return file, remoteComp.store.Name(), nil
+}
+
+// printEnvDiff prints the keys added, removed, or changed between two
+// env files, or flattened files, returning true when any key differs.
+func printEnvDiff(fileEntry catalog.File, a, b string, remote, local []byte, reveal bool, w io.Writer) bool {
+	changes := diff.Env(keyValues(fileEntry, remote), keyValues(fileEntry, local))
+
+	if len(changes) == 0 {
+		return false
+	}
+
+	printDiffHeader(a, b, w)
+
+	for _, c := range changes {
+		if c.Change != diff.Added {
+			color.New(color.FgRed).Fprintf(w, "-%s=%s\n", c.Key, maskValue(fileEntry, c.Key, c.Old, reveal))
+		}
+
+		if c.Change != diff.Removed {
+			color.New(color.FgGreen).Fprintf(w, "+%s=%s\n", c.Key, maskValue(fileEntry, c.Key, c.New, reveal))
+		}
+	}
+
+	return true
+}
+
+// maskValue masks a key's value unless the key type is plain or
+// reference.
+func maskValue(fileEntry catalog.File, key, value string, reveal bool) string {
+	if reveal {
+		return value
+	}
+
+	if fileEntry.Flatten {
+		key = strings.Replace(key, structured.Separator, "/", -1)
+	}
+
+	switch fileEntry.KeyType(key) {
+	case catalog.KeyTypePlain, catalog.KeyTypeReference:
+		return value
+	default:
+		return env.Mask(value)
+	}
+}
+
+// printLineDiff prints the lines that differ between two files in the
+// unified diff format returning true when any line differs.
+func printLineDiff(a, b string, remote, local []byte, reveal bool, w io.Writer) bool {
+	if bytes.Equal(remote, local) {
+		return false
+	}
+
+	if bytes.IndexByte(remote, 0) > -1 || bytes.IndexByte(local, 0) > -1 {
+		fmt.Fprintf(w, "Binary files %s and %s differ\n", a, b)
+		return true
+	}
+
+	hunks := diff.Hunks(diff.Lines(splitLines(remote), splitLines(local)), diffContext)
+
+	printDiffHeader(a, b, w)
+
+	for _, h := range hunks {
+		color.New(color.FgCyan).Fprintln(w, h.Header())
+
+		for _, l := range h.Lines {
+			text := l.Text
+			if !reveal {
+				text = maskLine(text)
+			}
+
+			switch l.Op {
+			case diff.Delete:
+				color.New(color.FgRed).Fprintf(w, "-%s\n", text)
+			case diff.Insert:
+				color.New(color.FgGreen).Fprintf(w, "+%s\n", text)
+			default:
+				fmt.Fprintf(w, " %s\n", text)
+			}
+		}
+	}
+
+	return true
+}
+
+func printDiffHeader(a, b string, w io.Writer) {
+	color.New(color.Bold).Fprintf(w, "--- %s\n", a)
+	color.New(color.Bold).Fprintf(w, "+++ %s\n", b)
+}
+
+func splitLines(file []byte) []string {
+	if len(file) == 0 {
+		return []string{}
+	}
+
+	return strings.Split(strings.TrimSuffix(string(file), "\n"), "\n")
+}
+
+// maskLine masks the value after the first '=' or ':' in a line, like
+// "password: secret" or "key = value", keeping the key visible. Lines
+// without a key, like the body of a certificate, are masked entirely.
+func maskLine(line string) string {
+	key, value := "", line
+	if sep := strings.IndexAny(line, "=:"); sep > -1 {
+		key, value = line[:sep+1], line[sep+1:]
+	}
+
+	indent := value[:len(value)-len(strings.TrimLeft(value, " \t"))]
+	value = strings.TrimSpace(value)
+
+	if len(key) > 0 {
+		switch value {
+		case "", "{", "[", "|", ">":
+			return line
+		}
+	}
+
+	return key + indent + env.Mask(value)
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	diffCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Set a version to identify a file specific state.")
+	diffCmd.Flags().BoolVarP(&uo.ExitCode, "exit-code", "", false, "Exit with 1 when any file differs from its store.")
+	diffCmd.Flags().BoolVarP(&uo.Reveal, "reveal", "", false, "Show values instead of masking them.")
+	diffCmd.Flags().StringVarP(&uo.Justification, "justification", "", "", "Explain why access is needed when using protected files.")
+	diffCmd.Flags().StringVarP(&uo.Template, "template", "", "", "Format output using a Go template or template file and send to stdout.")
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+// discoverCmd represents the discover command
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "List files in a store and add them to the catalog.",
+	Long: `List files in a store and add them to the catalog.
+
+Lists the files stored under a remote path that the current credentials
+can read. Files in the catalog's context that are not cataloged can be
+added to the catalog; so, a deleted catalog can be recovered without
+pushing the files again.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions([]string{})
+
+		if err := Discover(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Discover ...
+func Discover(opt cfg.UserOptions, io models.IO) error {
+	if len(opt.Store) == 0 {
+		return errors.New("--store is required to discover files")
+	}
+
+	//-------------------------------------------------
+	//- Get the catalog, creating one when missing.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		if context := contextOf(opt.RemotePath); len(context) > 0 {
+			clog = catalog.New(context)
+		} else if clog, err = catalog.GetMake(opt.Catalog, io); err != nil {
+			return err
+		}
+	}
+
+	//--------------------------------------------------
+	//- Get the remote store and vault components ready.
+	//--------------------------------------------------
+	probe := catalog.File{Store: opt.Store}
+
+	remoteComp, err := getRemoteComponents(&probe, clog, opt, io)
+	if err != nil {
+		return err
+	}
+
+	discoverable, ok := remoteComp.store.(contract.IDiscoverableStore)
+	if !ok {
+		return fmt.Errorf("%s does not support discovering files", remoteComp.store.Name())
+	}
+
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return fmt.Errorf("Failed to refresh %s credentials. (%s)", remoteComp.store.Name(), err)
+	}
+
+	done := measure(remoteComp.store.Name(), "discover")
+	files, err := discoverable.Discover(opt.RemotePath)
+	done(err)
+	if err != nil {
+		return err
+	}
+
+	p := io.Messages
+
+	if len(files) == 0 {
+		p.Println(io.UserOutput, message.DiscoverNoFiles)
+		return nil
+	}
+
+	//-------------------------------------------------
+	//- List the files found.
+	//-------------------------------------------------
+	entries := map[string]catalog.File{}
+	order := []string{}
+
+	fmt.Fprintln(io.UserOutput)
+
+	for _, f := range files {
+		fmt.Fprintf(io.UserOutput, "|- %s", p.Style(message.Path, f.Context+"/"+f.Path))
+
+		if len(f.Version) > 0 {
+			fmt.Fprint(io.UserOutput, " "+p.Text(message.OfVersion, f.Version))
+		}
+
+		if f.Keys > 0 {
+			fmt.Fprint(io.UserOutput, " "+p.Text(message.DiscoverKeys, f.Keys))
+		}
+
+		if !f.Modified.IsZero() {
+			fmt.Fprint(io.UserOutput, " "+p.Text(message.DiscoverModified, f.Modified.Local().Format("2006-01-02 15:04")))
+		}
+
+		entry, cataloged := clog.LookupEntry(f.Path, nil)
+
+		switch {
+		case f.Context != clog.Context:
+			fmt.Fprintln(io.UserOutput, " "+p.Style(message.Caution, p.Text(message.DiscoverOtherContext)))
+			continue
+		case cataloged:
+			fmt.Fprintln(io.UserOutput, " "+p.Style(message.Success, p.Text(message.DiscoverCataloged)))
+			continue
+		default:
+			fmt.Fprintln(io.UserOutput)
+		}
+
+		if existing, found := entries[f.Path]; found {
+			entry = existing
+		} else {
+			order = append(order, f.Path)
+		}
+
+		entry.Store = remoteComp.store.Name()
+		entry.Vaults = probe.Vaults
+		entry.Data = copyData(probe.Data)
+
+		if f.Keys > 0 {
+			entry.Type = store.EnvFeature
+		}
+
+		if len(f.Version) > 0 {
+			entry.Versions = append(entry.Versions, f.Version)
+		}
+
+		entries[f.Path] = entry
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(io.UserOutput)
+		p.Println(io.UserOutput, message.DiscoverNoneUncataloged, clog.Context)
+		return nil
+	}
+
+	//-------------------------------------------------
+	//- Add uncataloged files to the catalog.
+	//-------------------------------------------------
+	fmt.Fprintln(io.UserOutput)
+
+	confirmed, err := prompt.Confirm(p.Text(message.DiscoverAdd, len(entries), clog.Context, opt.Catalog), prompt.Normal, io)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		p.Println(io.UserOutput, message.DiscoverNoneAdded)
+		return nil
+	}
+
+	for _, filePath := range order {
+		if err := clog.UpdateEntry(entries[filePath]); err != nil {
+			return err
+		}
+	}
+
+	if err := catalog.Write(clog.GetFullPath(opt.Catalog), clog); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(io.UserOutput)
+	p.Println(io.UserOutput, message.DiscoverAdded, len(entries), opt.Catalog)
+
+	return nil
+}
+
+// contextOf returns the context a remote path like "/my-app/" is in.
+func contextOf(remotePath string) string {
+	return strings.SplitN(strings.Trim(remotePath, "/"), "/", 2)[0]
+}
+
+func copyData(data map[string]string) map[string]string {
+	if len(data) == 0 {
+		return nil
+	}
+
+	c := map[string]string{}
+	for k, v := range data {
+		c[k] = v
+	}
+
+	return c
+}
+
+func init() {
+	RootCmd.AddCommand(discoverCmd)
+
+	discoverCmd.Flags().StringVarP(&uo.Store, "store", "s", "", "Set the store to list files from.")
+	discoverCmd.Flags().StringVarP(&uo.RemotePath, "path", "", "", "Set the remote path to list files under, like /my-app/. (default: the catalog context)")
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/store"
+	"github.com/turnerlabs/cstore/components/transform"
+)
+
+// encryptCmd represents the encrypt command
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt {file} {key} [key] ...",
+	Short: "Mark keys in an env file to encrypt before they are pushed.",
+	Long: `Mark keys in an env file to encrypt before they are pushed.
+
+The values of marked keys are encrypted locally with a data key wrapped
+for each recipient, like a KMS key, an age public key, or a passphrase.
+Other values stay readable in the store. The keys and their recipients
+are saved in the catalog; push the file to encrypt the values.
+
+	$ cstore encrypt .env DB_PASSWORD API_TOKEN -r kms:alias/my-app
+	$ cstore push .env`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			display.ErrorText("Specify a file and the keys to encrypt. (cstore encrypt {file} {key} [key] ...)", ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions(args[:1])
+
+		if err := Encrypt(uo, args[1:], ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Encrypt marks the keys as encrypted for the recipients in the file's
+// catalog entry.
+func Encrypt(opt cfg.UserOptions, keys []string, io models.IO) error {
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	paths := opt.GetPaths(clog.CWD)
+
+	fileEntry, found := catalog.File{}, false
+	for _, f := range clog.Files {
+		if len(paths) > 0 && f.Path == paths[0] && !f.IsRef {
+			fileEntry, found = f, true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("%s is not aware of %s. Use 'list' command to view available files.", opt.Catalog, strings.Join(paths, ""))
+	}
+
+	if !fileEntry.SupportsConfig() {
+		return fmt.Errorf("Encrypted keys not supported for %s due to incompatible file type.", fileEntry.Path)
+	}
+
+	//-------------------------------------------------
+	//- Use the file's envelope recipients unless
+	//- others are specified.
+	//-------------------------------------------------
+	recipients := []catalog.Recipient{}
+
+	switch {
+	case len(opt.Recipients) > 0:
+		recipients, err = store.ParseRecipients(opt.Recipients)
+	case len(fileEntry.Encryption.Recipients) > 0:
+		recipients = fileEntry.Encryption.Recipients
+	default:
+		recipients, err = store.PromptRecipients(clog, opt, io)
+	}
+	if err != nil {
+		return err
+	}
+
+	spec := []string{}
+	for _, r := range recipients {
+		spec = append(spec, r.String())
+	}
+
+	fmt.Fprintln(io.UserOutput)
+
+	//-------------------------------------------------
+	//- Warn about keys missing from the local file.
+	//-------------------------------------------------
+	if file, err := localFile.GetBy(clog.GetFullPath(fileEntry.Path)); err == nil {
+		local := map[string]bool{}
+
+		transform.Map(file, func(key, value string) (string, bool, error) {
+			local[key] = true
+			return value, false, nil
+		})
+
+		for _, key := range keys {
+			if !local[key] {
+				display.Warn(fmt.Sprintf("%s is not in %s.", key, fileEntry.Path), io)
+			}
+		}
+	}
+
+	if fileEntry.EncryptedKeys == nil {
+		fileEntry.EncryptedKeys = map[string]string{}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fileEntry.EncryptedKeys[key] = strings.Join(spec, ", ")
+	}
+
+	if err := clog.UpdateEntry(fileEntry); err != nil {
+		return err
+	}
+
+	if err := catalog.Write(clog.GetFullPath(opt.Catalog), clog); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		fmt.Fprint(io.UserOutput, "Encrypting [")
+		color.New(color.FgBlue).Fprint(io.UserOutput, key)
+		fmt.Fprint(io.UserOutput, "] for [")
+		color.New(color.Bold).Fprint(io.UserOutput, strings.Join(spec, ", "))
+		fmt.Fprintln(io.UserOutput, "]")
+	}
+
+	fmt.Fprintf(io.UserOutput, "\nPush %s to encrypt the values in the store.\n\n", fileEntry.Path)
+
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(encryptCmd)
+
+	encryptCmd.Flags().StringVarP(&uo.Recipients, "recipient", "r", "", "Comma separated recipients able to decrypt the values, like 'kms:alias/my-app, age:age1..., passphrase'.")
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/path"
+	"github.com/turnerlabs/cstore/components/store"
+	"github.com/turnerlabs/cstore/components/token"
+)
+
+// encryptionCmd represents the encryption command
+var encryptionCmd = &cobra.Command{
+	Use:   "encryption",
+	Short: "Report how each cataloged key is encrypted at rest.",
+	Long: `Report how each cataloged key is encrypted at rest.
+
+Lists every key in every cataloged file with the mechanism protecting
+it in the store: none, sse, kms, client-side, or unknown. Secret keys
+saved in plain text are flagged. Values are never included.
+
+	$ cstore encryption
+	$ cstore encryption --fail-plaintext --output json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		reports, err := encryptionReportsFor(uo.Catalog, uo, ioStreams)
+
+		writeOutput(commandOutput{Command: "encryption", Files: encryptionOutputs(reports)}, err, uo, ioStreams)
+
+		if err != nil {
+			display.ErrorText(ioStreams.Messages.Text(message.EncryptionFailed, uo.Catalog, err), ioStreams)
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(ioStreams.UserOutput)
+
+		exposed, total := printEncryption(reports, ioStreams)
+
+		p := ioStreams.Messages
+		fmt.Fprintf(ioStreams.UserOutput, "\n%s\n\n", p.Style(message.Summary, p.Text(message.EncryptionSummary, exposed, total)))
+
+		if exposed > 0 && uo.FailPlaintext {
+			os.Exit(1)
+		}
+	},
+}
+
+// encryptionReport is how a file or key is encrypted. Key is empty when
+// the file is reported as a whole.
+type encryptionReport struct {
+	Path       string
+	Store      string
+	Key        string
+	KeyType    string
+	Encryption contract.Encryption
+	Err        error
+}
+
+// Exposed reports whether a secret is saved in plain text.
+func (r encryptionReport) Exposed() bool {
+	return r.Encryption.Mechanism == contract.EncryptionNone && r.KeyType != catalog.KeyTypePlain && r.KeyType != catalog.KeyTypeReference
+}
+
+func encryptionReportsFor(catalogPath string, opt cfg.UserOptions, io models.IO) ([]encryptionReport, error) {
+	basePath := path.RemoveFileName(catalogPath)
+
+	reports := []encryptionReport{}
+
+	//-------------------------------------------------
+	//- Get catalog containing files to report.
+	//-------------------------------------------------
+	clog, err := catalog.Get(catalogPath)
+	if err != nil {
+		return reports, err
+	}
+
+	for _, fileEntry := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, "") {
+		fullPath := path.BuildPath(basePath, fileEntry.Path)
+
+		//-------------------------------------------------
+		//- If entry is catalog, report child entries.
+		//-------------------------------------------------
+		if fileEntry.IsRef {
+			children, err := encryptionReportsFor(fullPath, opt, io)
+			if err != nil {
+				return reports, err
+			}
+
+			reports = append(reports, children...)
+
+			continue
+		}
+
+		fileReports, err := encryptionReportsOf(fileEntry, clog, opt, io)
+		if err != nil {
+			fileReports = []encryptionReport{{Encryption: contract.Encryption{Mechanism: contract.EncryptionUnknown}, Err: err}}
+		}
+
+		for i := range fileReports {
+			fileReports[i].Path = fullPath
+			fileReports[i].Store = fileEntry.Store
+		}
+
+		reports = append(reports, fileReports...)
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		if reports[i].Path != reports[j].Path {
+			return reports[i].Path < reports[j].Path
+		}
+		return reports[i].Key < reports[j].Key
+	})
+
+	return reports, nil
+}
+
+// encryptionReportsOf describes the encryption of each key in a file.
+// Stores saving keys individually report each stored key; otherwise,
+// keys are read from the local env file and share the file's
+// encryption. Keys encrypted before they are pushed and keys holding
+// secret tokens are described by the catalog and local file.
+func encryptionReportsOf(fileEntry catalog.File, clog catalog.Catalog, opt cfg.UserOptions, io models.IO) ([]encryptionReport, error) {
+	remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+	if err != nil {
+		return nil, err
+	}
+
+	described, ok := remoteComp.store.(contract.IEncryptionStore)
+	if !ok {
+		return nil, fmt.Errorf("%s store cannot report encryption", remoteComp.store.Name())
+	}
+
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return nil, fmt.Errorf("Failed to refresh %s credentials. (%s)", remoteComp.store.Name(), err)
+	}
+
+	done := measure(remoteComp.store.Name(), "encryption")
+	fileEncryption, keys, err := described.Encryption(&fileEntry, "")
+	done(err)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _ := localFile.GetBy(clog.GetFullPath(fileEntry.Path))
+
+	if keys == nil && fileEntry.SupportsConfig() {
+		keys = map[string]contract.Encryption{}
+
+		for name := range gotenv.Parse(bytes.NewReader(data)) {
+			keys[name] = fileEncryption
+		}
+	}
+
+	if len(keys) == 0 {
+		return []encryptionReport{{KeyType: catalog.KeyTypeSecret, Encryption: fileEncryption}}, nil
+	}
+
+	//-------------------------------------------------
+	//- Values replaced by tokens are kept in the
+	//- secrets vault.
+	//-------------------------------------------------
+	tokenized := map[string]string{}
+
+	if fileEntry.SupportsSecrets() && len(data) > 0 {
+		tokens, _ := token.Find(data, fileEntry.Type, false)
+
+		for _, t := range tokens {
+			tokenized[t.EnvVar] = remoteComp.secrets.BuildKey(clog.Context, t.Secret(), t.Prop)
+		}
+	}
+
+	reports := []encryptionReport{}
+
+	for key, e := range keys {
+		if secret, found := tokenized[strings.ToLower(key)]; found {
+			e = contract.Encryption{
+				Mechanism: contract.EncryptionUnknown,
+				Detail:    fmt.Sprintf("value is %s in %s vault", secret, remoteComp.secrets.Name()),
+			}
+		}
+
+		if recipients, found := fileEntry.EncryptedKeys[key]; found {
+			e = contract.Encryption{
+				Mechanism: contract.EncryptionClientSide,
+				Detail:    fmt.Sprintf("encrypted key for %s", recipients),
+			}
+		}
+
+		reports = append(reports, encryptionReport{
+			Key:        key,
+			KeyType:    fileEntry.KeyType(key),
+			Encryption: e,
+		})
+	}
+
+	return reports, nil
+}
+
+func printEncryption(reports []encryptionReport, io models.IO) (int, int) {
+	exposed, secrets := 0, 0
+	p := io.Messages
+
+	for _, r := range reports {
+		name := p.Text(message.WholeFile)
+		if len(r.Key) > 0 {
+			name = r.Key
+		}
+
+		fmt.Fprintf(io.UserOutput, "|- %s %s ", p.Style(message.Path, r.Path), name)
+
+		if r.Err != nil {
+			fmt.Fprintln(io.UserOutput, p.Style(message.Caution, fmt.Sprintf("(%s)", r.Err)))
+			continue
+		}
+
+		if r.KeyType != catalog.KeyTypePlain && r.KeyType != catalog.KeyTypeReference {
+			secrets++
+		}
+
+		mechanism := r.Encryption.Mechanism
+		if len(r.Encryption.Detail) > 0 {
+			mechanism = fmt.Sprintf("%s, %s", mechanism, r.Encryption.Detail)
+		}
+
+		switch {
+		case r.Exposed():
+			exposed++
+			fmt.Fprintln(io.UserOutput, p.Style(message.Failure, p.Text(message.EncryptionExposed, mechanism)))
+		case r.Encryption.Mechanism == contract.EncryptionNone:
+			fmt.Fprintf(io.UserOutput, "(%s) %s\n", mechanism, r.KeyType)
+		case r.Encryption.Mechanism == contract.EncryptionUnknown:
+			fmt.Fprintln(io.UserOutput, p.Style(message.Caution, fmt.Sprintf("(%s)", mechanism)))
+		default:
+			fmt.Fprintln(io.UserOutput, p.Style(message.Success, fmt.Sprintf("(%s)", mechanism)))
+		}
+	}
+
+	return exposed, secrets
+}
+
+// encryptionOutputs converts the encryption reported for each file.
+func encryptionOutputs(reports []encryptionReport) []fileOutput {
+	files := []fileOutput{}
+	index := map[string]int{}
+
+	for _, r := range reports {
+		i, found := index[r.Path]
+		if !found {
+			i = len(files)
+			index[r.Path] = i
+			files = append(files, fileOutput{Path: r.Path, Store: r.Store})
+		}
+
+		if r.Err != nil {
+			files[i].Error = r.Err.Error()
+			continue
+		}
+
+		files[i].Keys = append(files[i].Keys, keyOutput{
+			Name:             r.Key,
+			Encryption:       r.Encryption.Mechanism,
+			EncryptionDetail: r.Encryption.Detail,
+			Exposed:          r.Exposed(),
+		})
+	}
+
+	return files
+}
+
+func init() {
+	RootCmd.AddCommand(encryptionCmd)
+
+	encryptionCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	encryptionCmd.Flags().BoolVarP(&uo.FailPlaintext, "fail-plaintext", "", false, "Exit with a non-zero status when any secret key is saved in plain text.")
+}
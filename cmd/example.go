@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/env"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+// exampleCmd represents the example command
+var exampleCmd = &cobra.Command{
+	Use:   "example [file]",
+	Short: "Generate example files listing the keys of env files.",
+	Long: `Generate example files listing the keys of env files.
+
+Writes a {file}.example next to each cataloged env file containing
+the comments, key names, and key types, but no values; so, it can be
+committed as the template new developers copy from.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		if err := Example(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Example ...
+func Example(opt cfg.UserOptions, io models.IO) error {
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	p := io.Messages
+
+	for _, fileEntry := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, "") {
+		if fileEntry.IsRef || !fileEntry.SupportsConfig() {
+			continue
+		}
+
+		fullPath := clog.GetFullPath(fileEntry.Path)
+
+		file, err := exampleSource(fileEntry, fullPath, clog, opt, io)
+		if err != nil {
+			display.ErrorText(p.Text(message.ExampleFailed, fileEntry.Path, err), io)
+			continue
+		}
+
+		examplePath := fmt.Sprintf("%s.example", fullPath)
+
+		example := env.Example(file, fileEntry.KeyType)
+
+		if current, err := localFile.GetBy(examplePath); err == nil && bytes.Equal(current, example) {
+			p.Println(io.UserOutput, message.UpToDate, p.Style(message.Path, fileEntry.Path+".example"))
+			continue
+		}
+
+		if err := localFile.Save(examplePath, example); err != nil {
+			return err
+		}
+
+		p.Println(io.UserOutput, message.ExampleGenerated, p.Style(message.Path, fileEntry.Path+".example"), p.Style(message.Store, fileEntry.Path))
+
+		count++
+	}
+
+	if count == 0 {
+		p.Println(io.UserOutput, message.ExampleUnchanged)
+	}
+
+	return nil
+}
+
+// exampleSource returns the local copy of a file or pulls the file
+// when it has not been restored.
+func exampleSource(fileEntry catalog.File, fullPath string, clog catalog.Catalog, opt cfg.UserOptions, io models.IO) ([]byte, error) {
+	if file, err := localFile.GetBy(fullPath); err == nil {
+		return file, nil
+	}
+
+	remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := justify("pull", fileEntry, clog, remoteComp, opt); err != nil {
+		return nil, err
+	}
+
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return nil, fmt.Errorf("failed to refresh %s credentials (%s)", remoteComp.store.Name(), err)
+	}
+
+	done := measure(remoteComp.store.Name(), "pull")
+	file, _, err := remoteComp.store.Pull(&fileEntry, "")
+	done(err)
+
+	return file, err
+}
+
+func init() {
+	RootCmd.AddCommand(exampleCmd)
+
+	exampleCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	exampleCmd.Flags().StringVarP(&uo.Justification, "justification", "", "", "Explain why access is needed when pulling protected files.")
+}
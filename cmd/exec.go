@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/env"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/session"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+// execCmd represents the exec command
+var execCmd = &cobra.Command{
+	Use:   "exec",
+	Short: "Run a command using environment variables from file(s).",
+	Long: `Run a command using environment variables from file(s).
+
+Files are pulled and merged in the order they are listed. When a variable
+is defined in more than one file, the value from the file listed last is
+used and the collision is reported. When files are selected by tags, they
+are merged in path order.
+
+Everything after '--' is the command to run. When no command is specified,
+the merged variables are sent to stdout using the export format.
+
+	$ cstore exec base.env service.env -- ./my-application
+	$ eval $( cstore exec base.env service.env )`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filePaths, command := args, []string{}
+
+		if dash := cmd.ArgsLenAtDash(); dash > -1 {
+			filePaths, command = args[:dash], args[dash:]
+		}
+
+		setupUserOptions(filePaths)
+
+		code, err := Exec(uo, command, ioStreams)
+		if err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+
+		// the command's exit code is not a cstore failure
+		session.Finish(nil)
+		os.Exit(code)
+	},
+}
+
+// Exec merges the environment variables from the requested files and
+// runs the command with them returning the command's exit code.
+func Exec(opt cfg.UserOptions, command []string, io models.IO) (int, error) {
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return 0, err
+	}
+
+	//-------------------------------------------------
+	//- Retrieve and merge the requested files.
+	//-------------------------------------------------
+	layers, err := pullLayers(clog, opt, io)
+	if err != nil {
+		return 0, err
+	}
+
+	merged, collisions := env.Merge(layers)
+
+	p := io.Messages
+
+	for _, c := range collisions {
+		fmt.Fprintln(io.UserOutput, p.Style(message.Caution, p.Text(message.ExecCollision, c.Key, strings.Join(c.Sources, ", "), c.Sources[len(c.Sources)-1])))
+	}
+
+	//----------------------------------------------------
+	//- Remove environment variables already exported
+	//----------------------------------------------------
+	if opt.NoOverwrite {
+		for key := range merged {
+			if _, exists := os.LookupEnv(key); exists {
+				delete(merged, key)
+			}
+		}
+	}
+
+	//----------------------------------------------------
+	//- Without a command, send merged variables to stdout.
+	//----------------------------------------------------
+	if len(command) == 0 {
+		script, format, err := formatEnvExport(env.Format(merged), opt.ExportFormat, clog.Context, env.MergeValueTypes(layers))
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := script.WriteTo(io.Export); err != nil {
+			return 0, err
+		}
+
+		fmt.Fprintln(io.UserOutput)
+		p.Println(io.UserOutput, message.Exported, format)
+
+		return 0, nil
+	}
+
+	//----------------------------------------------------
+	//- Run the command with the merged variables.
+	//----------------------------------------------------
+	c := exec.Command(command[0], command[1:]...)
+	c.Env = os.Environ()
+	for key, value := range merged {
+		c.Env = append(c.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	c.Stdin = io.UserInput
+	c.Stdout = io.Export
+	c.Stderr = io.UserOutput
+
+	if err := c.Start(); err != nil {
+		return 0, err
+	}
+
+	//----------------------------------------------------
+	//- Forward signals to the command; so, it can shut
+	//- down gracefully when cstore is a container's
+	//- entrypoint.
+	//----------------------------------------------------
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+
+	go func() {
+		for sig := range signals {
+			c.Process.Signal(sig)
+		}
+	}()
+
+	err = c.Wait()
+
+	signal.Stop(signals)
+	close(signals)
+
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+				if status.Signaled() {
+					return 128 + int(status.Signal()), nil
+				}
+
+				return status.ExitStatus(), nil
+			}
+		}
+
+		return 0, err
+	}
+
+	return 0, nil
+}
+
+// forwardedSignals are passed on to the command run by exec.
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// pullLayers retrieves the contents of each requested env file in the
+// order the files should be merged.
+func pullLayers(clog catalog.Catalog, opt cfg.UserOptions, io models.IO) ([]env.Layer, error) {
+	layers := []env.Layer{}
+
+	files := []catalog.File{}
+
+	if paths := opt.GetPaths(clog.CWD); len(paths) > 0 {
+		for _, p := range paths {
+			found := false
+
+			for _, f := range clog.Files {
+				if f.Path == p && !f.IsRef {
+					files = append(files, f)
+					found = true
+				}
+			}
+
+			if !found {
+				return layers, fmt.Errorf("%s is not aware of %s. Use 'list' command to view available files.", opt.Catalog, p)
+			}
+		}
+	} else {
+		for _, f := range clog.FilesMatching([]string{}, opt.TagFilter, opt.Version) {
+			if !f.IsRef {
+				files = append(files, f)
+			}
+		}
+
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Path < files[j].Path
+		})
+	}
+
+	if len(files) == 0 {
+		return layers, fmt.Errorf("%s is not aware of requested files. Use 'list' command to view available files.", opt.Catalog)
+	}
+
+	for _, fileEntry := range files {
+		layer, err := pullLayer("exec", clog, fileEntry, opt, io)
+		if err != nil {
+			return layers, err
+		}
+
+		layers = append(layers, layer)
+	}
+
+	return layers, nil
+}
+
+// pullLayer retrieves the contents of an env file to be merged by the
+// command named by "action".
+func pullLayer(action string, clog catalog.Catalog, fileEntry catalog.File, opt cfg.UserOptions, io models.IO) (env.Layer, error) {
+	if !fileEntry.SupportsConfig() {
+		return env.Layer{}, fmt.Errorf("%s cannot be merged due to incompatible file type %s.", fileEntry.Path, fileEntry.Type)
+	}
+
+	fileEntry = overrideFileSettings(fileEntry, opt)
+
+	fileEntryTemp := fileEntry
+	remoteComp, err := getRemoteComponents(&fileEntryTemp, clog, opt, io)
+	if err != nil {
+		return env.Layer{}, fmt.Errorf("Could not retrieve %s! (%s)", fileEntry.Path, err)
+	}
+
+	if err := justify(action, fileEntry, clog, remoteComp, opt); err != nil {
+		return env.Layer{}, fmt.Errorf("Could not retrieve %s! (%s)", fileEntry.Path, err)
+	}
+
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return env.Layer{}, fmt.Errorf("Failed to refresh %s credentials. (%s)", remoteComp.store.Name(), err)
+	}
+
+	done := measure(remoteComp.store.Name(), "pull")
+	file, _, err := remoteComp.store.Pull(&fileEntry, opt.Version)
+	done(err)
+	if err != nil {
+		return env.Layer{}, fmt.Errorf("Could not retrieve %s! (%s)", fileEntry.Path, err)
+	}
+
+	file, err = applyTransforms(file, fileEntry, fileEntry.Transforms.Pull)
+	if err != nil {
+		return env.Layer{}, fmt.Errorf("Failed to transform %s! (%s)", fileEntry.Path, err)
+	}
+
+	file, err = renderTemplate(file, fileEntry, clog, remoteComp, opt, io)
+	if err != nil {
+		return env.Layer{}, fmt.Errorf("Failed to render %s! (%s)", fileEntry.Path, err)
+	}
+
+	file, err = renameKeys(file, fileEntry, false)
+	if err != nil {
+		return env.Layer{}, fmt.Errorf("Failed to rename keys in %s! (%s)", fileEntry.Path, err)
+	}
+
+	if opt.InjectSecrets {
+		file = injectSecrets(file, fileEntry, fileEntry.Path, clog, remoteComp, io)
+	}
+
+	p := io.Messages
+	p.Println(io.UserOutput, message.Retrieving, p.Style(message.Path, fileEntry.Path), p.Style(message.Store, remoteComp.store.Name()))
+
+	return env.Layer{
+		Name:       fileEntry.Path,
+		Data:       file,
+		ValueTypes: fileEntry.ValueTypes,
+	}, nil
+}
+
+func init() {
+	RootCmd.AddCommand(execCmd)
+
+	execCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	execCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Set a version to identify a file specific state.")
+	execCmd.Flags().StringVarP(&uo.ExportFormat, "format", "g", "", "Format environment variables sent to stdout when no command is specified.")
+	execCmd.Flags().BoolVarP(&uo.InjectSecrets, "inject-secrets", "i", false, "Inject secrets into the environment variables.")
+	execCmd.Flags().BoolVarP(&uo.NoOverwrite, "no-overwrite", "n", false, "Only set the environment variables that are not exported in the current environment.")
+	execCmd.Flags().StringVarP(&uo.Justification, "justification", "", "", "Explain why access is needed when using protected files.")
+}
@@ -4,22 +4,32 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/audit"
+	"github.com/turnerlabs/cstore/components/backup"
 	"github.com/turnerlabs/cstore/components/catalog"
 	"github.com/turnerlabs/cstore/components/cfg"
 	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/env"
+	"github.com/turnerlabs/cstore/components/export"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/metrics"
 	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/policy"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/session"
 	"github.com/turnerlabs/cstore/components/store"
+	"github.com/turnerlabs/cstore/components/token"
+	"github.com/turnerlabs/cstore/components/transform"
 	"github.com/turnerlabs/cstore/components/vault"
 )
 
-//"\xE2\x9C\x94" This is a checkmark on mac, but question mark on windows; so,
-// will use (done) for now to support multiple platforms.
-const (
-	checkMark = "(done)"
-	none      = ""
-)
+const none = ""
 
 // If the user specifies options during file push, make sure the exiting
 // file options are overridden with the desired user options.
@@ -39,6 +49,10 @@ func updateUserOptions(file catalog.File, opt cfg.UserOptions) catalog.File {
 		file.Store = opt.Store
 	}
 
+	if len(opt.Owner) > 0 {
+		file.Owner = opt.Owner
+	}
+
 	if len(opt.SecretsVault) > 0 {
 		file.Vaults.Secrets = opt.SecretsVault
 	}
@@ -47,6 +61,10 @@ func updateUserOptions(file catalog.File, opt cfg.UserOptions) catalog.File {
 		file.Vaults.Access = opt.AccessVault
 	}
 
+	if opt.Flatten {
+		file.Flatten = true
+	}
+
 	return file
 }
 
@@ -74,22 +92,284 @@ func getRemoteComponents(fileEntry *catalog.File, clog catalog.Catalog, uo cfg.U
 	remote.access = v
 	fileEntry.Vaults.Access = v.Name()
 
+	//-------------------------------------------------
+	//- Stop before credentials are used when the
+	//- policy does not allow the store.
+	//-------------------------------------------------
+	pol, err := policy.Load(uo.Policy, repoPolicy(clog))
+	if err != nil {
+		return remote, fmt.Errorf("Could not load policy! (%s)", err)
+	}
+
+	if len(fileEntry.Store) > 0 {
+		if err := pol.AllowsStore(fileEntry.Store); err != nil {
+			return remote, err
+		}
+	}
+
 	st, err := store.Select(fileEntry, clog, remote.access, uo, io)
 	if err != nil {
 		return remote, err
 	}
-	remote.store = st
 	fileEntry.Store = st.Name()
 
+	if err := allowedByPolicy(pol, st, fileEntry); err != nil {
+		return remote, err
+	}
+
+	if st, err = store.Structured(st, fileEntry); err != nil {
+		return remote, err
+	}
+
+	if st, err = store.Blob(st, fileEntry); err != nil {
+		return remote, err
+	}
+
+	if st, err = store.Pipeline(st, clog, fileEntry, remote.access, uo, io); err != nil {
+		return remote, err
+	}
+	remote.store = st
+
 	return remote, nil
 }
 
+// repoPolicy returns the policy file committed next to the catalog or
+// an empty path when there is none.
+func repoPolicy(clog catalog.Catalog) string {
+	path := clog.GetFullPath(policy.RepoFileName)
+
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+
+	return path
+}
+
+// allowedByPolicy returns an error when the policy does not allow the
+// store or the location the store is configured to use.
+func allowedByPolicy(pol policy.Policy, st contract.IStore, fileEntry *catalog.File) error {
+	if err := pol.AllowsStore(st.Name()); err != nil {
+		return err
+	}
+
+	if !pol.RestrictsEndpoints(st.Name()) {
+		return nil
+	}
+
+	locatable, ok := st.(contract.ILocatableStore)
+	if !ok {
+		return fmt.Errorf("%s store endpoints are restricted by policy, but the store cannot report its location", st.Name())
+	}
+
+	loc, err := locatable.Locate(fileEntry, "", "")
+	if err != nil {
+		return err
+	}
+
+	return pol.AllowsEndpoint(st.Name(), loc.Remote)
+}
+
+// measure starts timing a store call and returns a func that records
+// the call when it completes; so, it can be reported with --metrics.
+func measure(storeName, op string) func(error) {
+	start := time.Now()
+
+	return func(err error) {
+		metrics.Record(storeName, op, time.Since(start), err)
+		session.Call(storeName, op, time.Since(start), err)
+	}
+}
+
+// saveSharedPrompts saves non-secret prompt answers given during the
+// command in the catalog; so, others using it are not asked again.
+func saveSharedPrompts(catalogName string) error {
+	remembered := prompt.Remembered()
+	if len(remembered) == 0 {
+		return nil
+	}
+
+	clog, err := catalog.Get(catalogName)
+	if err != nil {
+		return nil
+	}
+
+	if clog.Prompts == nil {
+		clog.Prompts = map[string]string{}
+	}
+
+	changed := false
+	for name, value := range remembered {
+		if clog.Prompts[name] != value {
+			clog.Prompts[name] = value
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return catalog.Write(clog.GetFullPath(catalogName), clog)
+}
+
+// applyTransforms applies the transformations declared in the catalog
+// for the keys in a file.
+func applyTransforms(file []byte, fileEntry catalog.File, transforms map[string][]string) ([]byte, error) {
+	if len(transforms) == 0 {
+		return file, nil
+	}
+
+	if !fileEntry.SupportsConfig() {
+		return file, fmt.Errorf("transforms not supported for file type %s", fileEntry.Type)
+	}
+
+	return transform.Env(file, transforms)
+}
+
+// renameKeys renames the keys of a file to the names declared in the
+// catalog or, when reversed, back to the names they are stored under.
+func renameKeys(file []byte, fileEntry catalog.File, reverse bool) ([]byte, error) {
+	if len(fileEntry.Rename) == 0 {
+		return file, nil
+	}
+
+	if !fileEntry.SupportsConfig() {
+		return file, fmt.Errorf("renames not supported for file type %s", fileEntry.Type)
+	}
+
+	names, err := fileEntry.Renames(reverse)
+	if err != nil {
+		return file, err
+	}
+
+	return env.Rename(file, names), nil
+}
+
+// snapshot saves a backup of the remote file before a destructive
+// operation and explains how to restore it.
+func snapshot(remoteComp remoteComponents, fileEntry catalog.File, clog catalog.Catalog, version, reason string, io models.IO) error {
+	done := measure(remoteComp.store.Name(), "pull")
+	data, _, err := remoteComp.store.Pull(&fileEntry, version)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("Backup of %s failed. (%s) Use --no-backup to continue without a backup.", fileEntry.Path, err)
+	}
+
+	key, err := backup.Key(remoteComp.access, clog.Context, io)
+	if err != nil {
+		return fmt.Errorf("Backup of %s failed. (%s) Use --no-backup to continue without a backup.", fileEntry.Path, err)
+	}
+
+	id, err := backup.Save(backup.Backup{
+		Context: clog.Context,
+		Path:    fileEntry.Path,
+		Store:   remoteComp.store.Name(),
+		Version: version,
+		Reason:  reason,
+		Vault:   remoteComp.access.Name(),
+		Data:    data,
+	}, key)
+	if err != nil {
+		return fmt.Errorf("Backup of %s failed. (%s) Use --no-backup to continue without a backup.", fileEntry.Path, err)
+	}
+
+	io.Messages.Println(io.UserOutput, message.BackupSaved, id)
+
+	return nil
+}
+
+// justify reports access to a protected file with the user's reason
+// to the catalog's audit endpoint. Access is denied when the reason is
+// missing or the event cannot be delivered.
+func justify(action string, fileEntry catalog.File, clog catalog.Catalog, remoteComp remoteComponents, opt cfg.UserOptions) error {
+	if !fileEntry.Protected {
+		return nil
+	}
+
+	if len(strings.TrimSpace(opt.Justification)) == 0 {
+		return fmt.Errorf("%s is protected, use --justification to explain why access is needed", fileEntry.Path)
+	}
+
+	if len(clog.Audit.Endpoint) == 0 {
+		return fmt.Errorf("%s is protected, but the catalog does not define an audit endpoint", fileEntry.Path)
+	}
+
+	event := audit.NewEvent(action, clog.Context, fileEntry.Path, remoteComp.store.Name(), opt.Version, opt.Justification)
+
+	if err := audit.Send(clog.Audit.Endpoint, event); err != nil {
+		return fmt.Errorf("access to %s could not be audited (%s)", fileEntry.Path, err)
+	}
+
+	return nil
+}
+
+// checkFreeze blocks pushes to protected files during a catalog freeze
+// unless the user breaks glass with a reason that is reported to the
+// catalog's audit endpoint.
+func checkFreeze(fileEntry catalog.File, clog catalog.Catalog, remoteComp remoteComponents, opt cfg.UserOptions, io models.IO) error {
+	if !fileEntry.Protected {
+		return nil
+	}
+
+	freeze, ends, active, err := clog.ActiveFreeze(time.Now())
+	if err != nil || !active {
+		return err
+	}
+
+	if len(strings.TrimSpace(opt.BreakGlass)) == 0 {
+		return fmt.Errorf("%s is in effect until %s, use --break-glass with a reason to push protected files", freeze.Name, ends.Local().Format(time.RFC822))
+	}
+
+	if len(clog.Audit.Endpoint) == 0 {
+		return fmt.Errorf("%s is in effect, but the catalog does not define an audit endpoint to report breaking glass", freeze.Name)
+	}
+
+	event := audit.NewEvent("break-glass", clog.Context, fileEntry.Path, remoteComp.store.Name(), opt.Version, opt.BreakGlass)
+
+	if err := audit.Send(clog.Audit.Endpoint, event); err != nil {
+		return fmt.Errorf("breaking glass could not be audited (%s)", err)
+	}
+
+	display.Warn(fmt.Sprintf("Pushing %s during %s. (%s)", fileEntry.Path, freeze.Name, opt.BreakGlass), io)
+
+	return nil
+}
+
+// injectSecrets replaces the tokens in a file with secrets retrieved
+// from the secrets vault. Tokens that cannot be resolved are reported
+// and left in place.
+func injectSecrets(file []byte, fileEntry catalog.File, displayPath string, clog catalog.Catalog, remoteComp remoteComponents, io models.IO) []byte {
+	tokens, err := token.Find(file, fileEntry.Type, false)
+	if err != nil {
+		display.Error(fmt.Errorf("Failed to find tokens in file %s. (%s)", fileEntry.Path, err), io)
+	}
+
+	for k, t := range tokens {
+
+		value, err := remoteComp.secrets.Get(clog.Context, t.Secret(), t.Prop)
+		if err != nil {
+			display.Error(fmt.Errorf("Failed to get value for %s/%s for %s! (%s)", t.Secret(), t.Prop, displayPath, t.Secret()), io)
+			continue
+		}
+
+		t.Value = value
+		tokens[k] = t
+	}
+
+	fileWithSecrets, err := token.Replace(file, fileEntry.Type, tokens)
+	if err != nil {
+		display.Error(fmt.Errorf("Failed to replace tokens in file %s. (%s)", fileEntry.Path, err), io)
+	}
+
+	return fileWithSecrets
+}
+
 func getFilePathsToPush(clog catalog.Catalog, opt cfg.UserOptions) []string {
 	paths := opt.GetPaths(clog.CWD)
 
 	if len(paths) == 0 {
-		if len(opt.TagList) > 0 {
-			paths = clog.GetPathsBy(opt.TagList, opt.AllTags)
+		if !opt.TagFilter.IsEmpty() {
+			paths = clog.GetPathsMatching(opt.TagFilter)
 		} else {
 			paths = clog.GetPaths()
 		}
@@ -130,19 +410,65 @@ func overrideFileSettings(fileEntry catalog.File, opt cfg.UserOptions) catalog.F
 	return fileEntry
 }
 
-func bufferExportScript(file []byte) (bytes.Buffer, error) {
-	reader := bytes.NewReader(file)
-	pairs := gotenv.Parse(reader)
-	var b bytes.Buffer
-
-	for key, value := range pairs {
-		_, err := b.WriteString(fmt.Sprintf("export %s='%s'\n", key, value))
-		if err != nil {
-			return b, err
+// formatEnvExport converts env file contents to the requested export
+// format returning the formatted data and a description of the format.
+// Formats supporting types emit values as their declared value types.
+func formatEnvExport(file []byte, format, name string, valueTypes map[string]string) (bytes.Buffer, string, error) {
+	switch format {
+	case "json":
+		b, err := toTypedFormat(file, valueTypes, env.FormatJSON)
+		return b, "JSON", err
+	case "yaml":
+		b, err := toTypedFormat(file, valueTypes, env.FormatYAML)
+		return b, "YAML", err
+	case "tfvars":
+		b, err := toTypedFormat(file, valueTypes, env.FormatTFVars)
+		return b, "Terraform variables", err
+	case "task-def-secrets":
+		b, err := toTaskDefSecretFormat(file)
+		return b, "AWS task definition secrets", err
+	case "task-def-env":
+		b, err := toTaskDefEnvFormat(file)
+		return b, "AWS task definition environment", err
+	case "k8s-secret":
+		b, err := env.FormatK8sSecret(store.K8sName(name), gotenv.Parse(bytes.NewReader(file)))
+		return *bytes.NewBuffer(b), "Kubernetes secret", err
+	case "docker-args":
+		return *bytes.NewBuffer(env.FormatDockerArgs(gotenv.Parse(bytes.NewReader(file)))), "Docker arguments", nil
+	case "shell-export", "terminal-export":
+		b, err := bufferExportScript(file)
+		return b, "Terminal export commands", err
+	default:
+		if f, found := export.Get(format); found {
+			b, err := toTypedFormat(file, valueTypes, f.Convert)
+			return b, f.Description, err
 		}
+
+		b, err := bufferExportScript(file)
+		return b, "Terminal export commands", err
+	}
+}
+
+func toTypedFormat(file []byte, valueTypes map[string]string, format func(map[string]interface{}) ([]byte, error)) (bytes.Buffer, error) {
+	var buff bytes.Buffer
+
+	values, err := catalog.TypedValues(gotenv.Parse(bytes.NewReader(file)), valueTypes)
+	if err != nil {
+		return buff, err
 	}
 
-	return b, nil
+	b, err := format(values)
+	if err != nil {
+		return buff, err
+	}
+
+	_, err = buff.Write(b)
+
+	return buff, err
+}
+
+func bufferExportScript(file []byte) (bytes.Buffer, error) {
+	return *bytes.NewBuffer(env.FormatShellExport(gotenv.Parse(bytes.NewReader(file)))), nil
 }
 
 func toTaskDefSecretFormat(file []byte) (bytes.Buffer, error) {
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history {file}",
+	Short: "List when a key's value changed.",
+	Long: `List when a key's value changed.
+
+Lists each change to a key's value, oldest first, with the revision
+kept by the store and the principal that made the change when the
+store records it. Values are not displayed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			display.ErrorText(ioStreams.Messages.Text(message.SpecifyFile, "cstore history {file} --key {key}"), ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions(args)
+
+		if err := History(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// History ...
+func History(opt cfg.UserOptions, io models.IO) error {
+	if len(opt.Key) == 0 {
+		return errors.New("--key is required to list changes")
+	}
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	paths := opt.GetPaths(clog.CWD)
+
+	fileEntry, found := clog.LookupEntry(paths[0], nil)
+	if !found {
+		return fmt.Errorf("%s is not aware of %s. Use 'list' command to view available files.", opt.Catalog, strings.Join(paths, ""))
+	}
+
+	if len(opt.Version) > 0 && fileEntry.Missing(opt.Version) {
+		return fmt.Errorf("version %s of %s not found in %s", opt.Version, fileEntry.Path, opt.Catalog)
+	}
+
+	fileEntry = overrideFileSettings(fileEntry, opt)
+
+	//----------------------------------------------------
+	//- Get the remote store and vaults components ready.
+	//----------------------------------------------------
+	remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+	if err != nil {
+		return err
+	}
+
+	historical, ok := remoteComp.store.(contract.IKeyHistoryStore)
+	if !ok {
+		return fmt.Errorf("%s store does not keep key history", remoteComp.store.Name())
+	}
+
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return fmt.Errorf("Failed to refresh %s credentials. (%s)", remoteComp.store.Name(), err)
+	}
+
+	done := measure(remoteComp.store.Name(), "history")
+	changes, err := historical.KeyHistory(&fileEntry, opt.Key, opt.Version)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("Failed to get the history of %s. (%s)", opt.Key, err)
+	}
+
+	//-------------------------------------------------
+	//- List the changes.
+	//-------------------------------------------------
+	p := io.Messages
+
+	fmt.Fprintln(io.UserOutput)
+	fmt.Fprint(io.UserOutput, p.Text(message.HistoryKey, p.Style(message.Heading, opt.Key), fileEntry.Path))
+	if len(opt.Version) > 0 {
+		fmt.Fprint(io.UserOutput, " "+p.Text(message.OfVersion, opt.Version))
+	}
+	fmt.Fprintf(io.UserOutput, " [%s]\n\n", remoteComp.store.Name())
+
+	for _, c := range changes {
+		fmt.Fprintf(io.UserOutput, "|- %s %s", p.Style(message.Path, c.Modified.Local().Format("2006-01-02 15:04:05")), p.Text(message.HistoryRevision, c.Revision))
+
+		if c.Removed {
+			fmt.Fprint(io.UserOutput, " "+p.Style(message.Caution, p.Text(message.HistoryRemoved)))
+		} else {
+			fmt.Fprint(io.UserOutput, " "+p.Text(message.HistoryChanged))
+		}
+
+		if len(c.By) > 0 {
+			fmt.Fprint(io.UserOutput, " "+p.Text(message.ChangedBy, c.By))
+		}
+
+		fmt.Fprintln(io.UserOutput)
+	}
+
+	fmt.Fprintf(io.UserOutput, "\n%s\n\n", p.Style(message.Summary, p.Text(message.HistorySummary, len(changes))))
+
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVarP(&uo.Key, "key", "k", "", "Set the key to list changes for.")
+	historyCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "List changes to a key in a specific version of the file.")
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/store"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a catalog for the repository.",
+	Long: `Create a catalog for the repository.
+
+With --wizard, the repository is scanned for env and config files.
+Each file found is proposed as a catalog entry with a store and tags,
+the resulting catalog is previewed, and the files are pushed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions([]string{})
+
+		if !uo.Wizard {
+			if err := Init(uo, ioStreams); err != nil {
+				display.Error(err, ioStreams)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if err := Wizard(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Init ...
+func Init(opt cfg.UserOptions, io models.IO) error {
+	if _, err := catalog.Get(opt.Catalog); err == nil {
+		io.Messages.Println(io.UserOutput, message.InitExists, opt.Catalog)
+		return nil
+	}
+
+	clog, err := catalog.GetMake(opt.Catalog, io)
+	if err != nil {
+		return err
+	}
+
+	if err := catalog.Write(clog.GetFullPath(opt.Catalog), clog); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(io.UserOutput)
+	io.Messages.Println(io.UserOutput, message.InitCreated, opt.Catalog, clog.Context)
+
+	return nil
+}
+
+// wizardEntry is a file the user chose to catalog.
+type wizardEntry struct {
+	path string
+	tags string
+	file catalog.File
+}
+
+// Wizard ...
+func Wizard(opt cfg.UserOptions, io models.IO) error {
+	if err := cfg.Writable("push"); err != nil {
+		return err
+	}
+
+	clog, err := catalog.Get(opt.Catalog)
+	created := err != nil
+	if created {
+		if clog, err = catalog.GetMake(opt.Catalog, io); err != nil {
+			return err
+		}
+	}
+
+	//-------------------------------------------------
+	//- Find env and config files not yet cataloged.
+	//-------------------------------------------------
+	found, err := catalog.Scan(".")
+	if err != nil {
+		return err
+	}
+
+	scanOpt := opt
+	scanOpt.AddPaths(found)
+
+	candidates := []string{}
+	for i, filePath := range scanOpt.GetPaths(clog.CWD) {
+		if _, cataloged := clog.LookupEntry(filePath, nil); !cataloged {
+			candidates = append(candidates, found[i])
+		}
+	}
+
+	p := io.Messages
+
+	if len(candidates) == 0 {
+		p.Println(io.UserOutput, message.WizardNoFiles)
+		return nil
+	}
+
+	fmt.Fprintln(io.UserOutput)
+	p.Println(io.UserOutput, message.WizardFound, len(candidates))
+
+	//-------------------------------------------------
+	//- Propose an entry for each file.
+	//-------------------------------------------------
+	entries := []wizardEntry{}
+
+	for _, filePath := range candidates {
+		confirmed, err := prompt.Confirm(p.Text(message.WizardCatalog, filePath), prompt.Normal, io)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			continue
+		}
+
+		fileOpt := opt
+		fileOpt.AddPaths([]string{filePath})
+
+		fileEntry, _ := clog.LookupEntry(fileOpt.GetPaths(clog.CWD)[0], nil)
+
+		if fileEntry.Store, err = prompt.GetValFromUser("Store", prompt.Options{
+			Description:  p.Text(message.WizardStore, filePath),
+			DefaultValue: suggestStore(fileEntry.Type, opt.Store),
+		}, io); err != nil {
+			return err
+		}
+
+		if fileOpt.Tags, err = prompt.GetValFromUser("Tags", prompt.Options{
+			Description:  p.Text(message.WizardTags, filePath),
+			DefaultValue: strings.Join(suggestTags(fileEntry.Path, fileOpt), "|"),
+		}, io); err != nil {
+			return err
+		}
+
+		if err := fileOpt.ParseTags(); err != nil {
+			return err
+		}
+
+		fileEntry.Tags = opt.TagsFrom(fileEntry.Path)
+		if len(fileOpt.Tags) > 0 {
+			fileEntry.Tags = fileOpt.TagList
+		}
+
+		entries = append(entries, wizardEntry{
+			path: filePath,
+			tags: fileOpt.Tags,
+			file: fileEntry,
+		})
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(io.UserOutput)
+		p.Println(io.UserOutput, message.WizardNoneSelected)
+		return nil
+	}
+
+	//-------------------------------------------------
+	//- Preview the catalog after the pushes.
+	//-------------------------------------------------
+	preview := clog
+	preview.Context = clog.DefaultContext()
+	preview.Files = map[string]catalog.File{}
+	for key, f := range clog.Files {
+		preview.Files[key] = f
+	}
+
+	for _, entry := range entries {
+		preview.Files[entry.file.Key()] = entry.file
+	}
+
+	b, err := yaml.Marshal(&preview)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.UserOutput, "\n%s\n\n", p.Style(message.Heading, p.Text(message.WizardProposed, opt.Catalog)))
+	fmt.Fprintln(io.UserOutput, string(b))
+
+	confirmed, err := prompt.Confirm(p.Text(message.WizardPush, len(entries)), prompt.Warn, io)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		p.Println(io.UserOutput, message.WizardNonePushed)
+		return nil
+	}
+
+	if created {
+		if err := catalog.Write(clog.GetFullPath(opt.Catalog), clog); err != nil {
+			return err
+		}
+	}
+
+	//-------------------------------------------------
+	//- Push each file using the chosen store and tags.
+	//-------------------------------------------------
+	failed := 0
+	for _, entry := range entries {
+		fileOpt := opt
+		fileOpt.AddPaths([]string{entry.path})
+		fileOpt.Store = entry.file.Store
+		fileOpt.Tags = entry.tags
+
+		err := fileOpt.ParseTags()
+		if err == nil {
+			err = Push(fileOpt, io)
+		}
+
+		if err != nil {
+			display.ErrorText(p.Text(message.PushFailed, entry.path, err), io)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d file(s) failed to push", failed, len(entries))
+	}
+
+	return nil
+}
+
+// suggestStore returns the preferred store when it supports the file
+// type, even as a blob, and otherwise the first default store that
+// supports the file type.
+func suggestStore(fileType, preferred string) string {
+	stores := store.Get()
+
+	if s, found := stores[preferred]; found && store.SupportsFile(s, fileType) {
+		return preferred
+	}
+
+	for _, name := range []string{cfg.DefaultStore, "aws-s3"} {
+		if s, found := stores[name]; found && s.SupportsFileType(fileType) {
+			return name
+		}
+	}
+
+	return cfg.DefaultStore
+}
+
+// suggestTags returns the folder names of the file and the environment
+// it is named for.
+func suggestTags(filePath string, opt cfg.UserOptions) []string {
+	tags := opt.TagsFrom(filePath)
+
+	if environment := catalog.EnvironmentOf(filePath); len(environment) > 0 {
+		tags = append(tags, environment)
+	}
+
+	return tags
+}
+
+func init() {
+	RootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().BoolVarP(&uo.Wizard, "wizard", "", false, "Scan for env and config files, propose catalog entries, and push them.")
+	initCmd.Flags().StringVarP(&uo.Store, "store", "s", "", "Set the store proposed for each file when it supports the file type.")
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/path"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+var inventoryHeader = []string{"catalog", "file", "store", "type", "owner", "key", "last_modified"}
+
+// inventoryCmd represents the inventory command
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Export an inventory of cataloged files and keys.",
+	Long: `Export an inventory of cataloged files and keys.
+
+Lists every file, store, key name, type, owner, and last modified time
+as CSV or TSV sent to stdout. Values are never included.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		w := csv.NewWriter(ioStreams.Export)
+
+		switch strings.ToLower(uo.InventoryFormat) {
+		case "csv":
+		case "tsv":
+			w.Comma = '\t'
+		default:
+			display.ErrorText(fmt.Sprintf("Unknown inventory format %s. Use csv or tsv.", uo.InventoryFormat), ioStreams)
+			os.Exit(1)
+		}
+
+		rows, err := inventoryRowsFor(uo.Catalog, uo, ioStreams)
+		if err != nil {
+			display.Error(fmt.Errorf("Failed to build inventory for %s. (%s)", uo.Catalog, err), ioStreams)
+			os.Exit(1)
+		}
+
+		w.Write(inventoryHeader)
+		w.WriteAll(rows)
+
+		if err := w.Error(); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+func inventoryRowsFor(catalogPath string, opt cfg.UserOptions, io models.IO) ([][]string, error) {
+	basePath := path.RemoveFileName(catalogPath)
+
+	rows := [][]string{}
+
+	//-------------------------------------------------
+	//- Get catalog containing files to inventory.
+	//-------------------------------------------------
+	clog, err := catalog.Get(catalogPath)
+	if err != nil {
+		return rows, err
+	}
+
+	for _, fileEntry := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, "") {
+		fullPath := path.BuildPath(basePath, fileEntry.Path)
+
+		//-------------------------------------------------
+		//- If entry is catalog, add child entries.
+		//-------------------------------------------------
+		if fileEntry.IsRef {
+			children, err := inventoryRowsFor(fullPath, opt, io)
+			if err != nil {
+				return rows, err
+			}
+
+			rows = append(rows, children...)
+
+			continue
+		}
+
+		keys, err := inventoryKeysFor(fileEntry, clog, opt, io)
+		if err != nil {
+			display.Error(fmt.Errorf("Failed to get keys for %s. (%s)", fullPath, err), io)
+		}
+
+		for _, key := range keys {
+			modified := ""
+			if !key.Modified.IsZero() {
+				modified = key.Modified.UTC().Format(time.RFC3339)
+			}
+
+			rows = append(rows, []string{catalogPath, fullPath, fileEntry.Store, fileEntry.Type, fileEntry.Owner, key.Name, modified})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i][1] != rows[j][1] {
+			return rows[i][1] < rows[j][1]
+		}
+		return rows[i][5] < rows[j][5]
+	})
+
+	return rows, nil
+}
+
+// inventoryKeysFor returns the keys in a file. Key/value stores report
+// each key; otherwise, keys are read from the local env file and share
+// the file's last modified time. Files without keys are a single row
+// with an empty key.
+func inventoryKeysFor(fileEntry catalog.File, clog catalog.Catalog, opt cfg.UserOptions, io models.IO) ([]listKey, error) {
+	file := []listKey{{}}
+
+	remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+	if err != nil {
+		return file, err
+	}
+
+	if _, ok := store.Unwrap(remoteComp.store).(contract.IKeyStore); ok {
+		keys, err := listKeysFor(fileEntry, clog, opt, io)
+		if err != nil || len(keys) == 0 {
+			return file, err
+		}
+		return keys, nil
+	}
+
+	data, _ := localFile.GetBy(clog.GetFullPath(fileEntry.Path))
+
+	done := measure(remoteComp.store.Name(), "changed")
+	modified, err := remoteComp.store.Changed(&fileEntry, data, "")
+	done(err)
+	if err != nil {
+		return file, err
+	}
+
+	file[0].Modified = modified
+
+	if !fileEntry.SupportsConfig() || len(data) == 0 {
+		return file, nil
+	}
+
+	keys := []listKey{}
+	for name := range gotenv.Parse(bytes.NewReader(data)) {
+		keys = append(keys, listKey{Name: name, Modified: modified})
+	}
+
+	if len(keys) == 0 {
+		return file, nil
+	}
+
+	return keys, nil
+}
+
+func init() {
+	RootCmd.AddCommand(inventoryCmd)
+
+	inventoryCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	inventoryCmd.Flags().StringVarP(&uo.InventoryFormat, "format", "", "csv", "Set the inventory format to csv or tsv.")
+}
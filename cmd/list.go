@@ -2,12 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/cache"
 	"github.com/turnerlabs/cstore/components/catalog"
 	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
 	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/logger"
+	"github.com/turnerlabs/cstore/components/message"
 	"github.com/turnerlabs/cstore/components/models"
 	"github.com/turnerlabs/cstore/components/path"
 )
@@ -20,66 +25,178 @@ var listCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		setupUserOptions(args)
 
-		fmt.Fprintln(ioStreams.UserOutput)
-
-		total, err := listFilesFor(uo.Catalog, uo, ioStreams)
+		entries, err := listEntriesFor(uo.Catalog, uo, ioStreams)
 		if err != nil {
-			display.Error(fmt.Errorf("Failed to list files for %s. (%s)", uo.Catalog, err), ioStreams.UserOutput)
+			display.Error(fmt.Errorf("Failed to list files for %s. (%s)", uo.Catalog, err), ioStreams)
+			writeOutput(commandOutput{Command: "list"}, err, uo, ioStreams)
+			return
+		}
+
+		//-------------------------------------------------
+		//- Send JSON to stdout when specified.
+		//-------------------------------------------------
+		if uo.OutputFormat == outputJSON {
+			writeOutput(commandOutput{Command: "list", Files: listOutputs(entries)}, nil, uo, ioStreams)
+			return
+		}
+
+		//-------------------------------------------------
+		//- Render user template when specified.
+		//-------------------------------------------------
+		if len(uo.Template) > 0 {
+			if err := display.Template(uo.Template, entries, ioStreams.Export); err != nil {
+				display.ErrorText(ioStreams.Messages.Text(message.TemplateFailed, err), ioStreams)
+			}
 			return
 		}
 
-		color.New(color.Bold).Fprintf(ioStreams.UserOutput, "\n%d file(s) stored remotely.\n", total)
+		fmt.Fprintln(ioStreams.UserOutput)
+
+		printEntries(entries, uo, ioStreams)
+
+		p := ioStreams.Messages
 
-		fmt.Fprintf(ioStreams.UserOutput, "\nUse -g and -v to display file tags and versions.\n\n")
+		fmt.Fprintf(ioStreams.UserOutput, "\n%s\n", p.Style(message.Summary, p.Text(message.ListSummary, len(entries))))
+
+		fmt.Fprintf(ioStreams.UserOutput, "\n%s\n\n", p.Text(message.ListHint))
 	},
 }
 
-func listFilesFor(catalogPath string, opt cfg.UserOptions, io models.IO) (int, error) {
+// listEntry is the file data available to list templates.
+type listEntry struct {
+	Path     string
+	Catalog  string
+	Store    string
+	Type     string
+	Tags     []string
+	Versions []string
+	Keys     []listKey
+}
+
+// listKey is a file key and the time it was last modified. Modified
+// is zero when the store does not know when the key changed.
+type listKey struct {
+	Name     string
+	Modified time.Time
+}
+
+func listEntriesFor(catalogPath string, opt cfg.UserOptions, io models.IO) ([]listEntry, error) {
 	basePath := path.RemoveFileName(catalogPath)
 
+	entries := []listEntry{}
+
 	//-------------------------------------------------
 	//- Get catalog containing files to list.
 	//-------------------------------------------------
 	clog, err := catalog.Get(catalogPath)
 	if err != nil {
-		return 0, err
+		return entries, err
 	}
 
-	//-------------------------------------------------
-	//- Print catalog entries.
-	//-------------------------------------------------
-	total := 0
-
-	for _, fileEntry := range clog.FilesBy(opt.GetPaths(clog.CWD), opt.TagList, opt.AllTags, opt.Version) {
+	for _, fileEntry := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, opt.Version) {
 		fullPath := path.BuildPath(basePath, fileEntry.Path)
 
 		//-------------------------------------------------
-		//- If entry is catalog, print child entries.
+		//- If entry is catalog, add child entries.
 		//-------------------------------------------------
 		if fileEntry.IsRef {
-			count, err := listFilesFor(fullPath, opt, io)
+			children, err := listEntriesFor(fullPath, opt, io)
 			if err != nil {
-				return 0, err
+				return entries, err
 			}
 
-			total += count
+			entries = append(entries, children...)
 
 			continue
 		}
 
+		entry := listEntry{
+			Path:     fullPath,
+			Catalog:  catalogPath,
+			Store:    fileEntry.Store,
+			Type:     fileEntry.Type,
+			Tags:     fileEntry.Tags,
+			Versions: fileEntry.Versions,
+		}
+
 		//-------------------------------------------------
-		//- Print file entry and versions.
+		//- If user specified, get key modified times.
 		//-------------------------------------------------
+		if opt.ViewKeys {
+			keys, err := listKeysFor(fileEntry, clog, opt, io)
+			if err != nil {
+				display.Error(fmt.Errorf("Failed to get keys for %s. (%s)", fullPath, err), io)
+			}
+
+			entry.Keys = keys
+		}
 
-		fmt.Fprintf(io.UserOutput, "|-")
-		color.New(color.FgBlue).Fprintf(io.UserOutput, " %s ", fullPath)
-		color.New(color.Bold).Fprintf(io.UserOutput, "[%s]", fileEntry.Store)
-		fmt.Fprintf(io.UserOutput, "\n")
+		entries = append(entries, entry)
+	}
 
-		if opt.ViewTags && len(fileEntry.Tags) > 0 {
-			fmt.Fprintf(io.UserOutput, "|")
-			color.New(color.Bold).Fprintln(io.UserOutput, "   tags")
-			for _, tag := range fileEntry.Tags {
+	return entries, nil
+}
+
+func listKeysFor(fileEntry catalog.File, clog catalog.Catalog, opt cfg.UserOptions, io models.IO) ([]listKey, error) {
+	keys := []listKey{}
+
+	//-------------------------------------------------
+	//- Use recently cached times unless refreshing.
+	//-------------------------------------------------
+	modified, cached := map[string]time.Time{}, false
+	if !opt.Refresh {
+		modified, cached = cache.GetKeys(clog.Context, fileEntry.Key(), cache.MetadataTTL)
+	}
+
+	if !cached {
+		remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+		if err != nil {
+			return keys, err
+		}
+
+		keyStore, ok := remoteComp.store.(contract.IKeyStore)
+		if !ok {
+			return keys, fmt.Errorf("%s store does not track keys", remoteComp.store.Name())
+		}
+
+		done := measure(remoteComp.store.Name(), "keys")
+		modified, err = keyStore.KeysModified(&fileEntry, "")
+		done(err)
+		if err != nil {
+			return keys, err
+		}
+
+		if err := cache.SaveKeys(clog.Context, fileEntry.Key(), modified); err != nil {
+			logger.L.Print(err)
+		}
+	}
+
+	for name, t := range modified {
+		keys = append(keys, listKey{
+			Name:     name,
+			Modified: t,
+		})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+
+	return keys, nil
+}
+
+func printEntries(entries []listEntry, opt cfg.UserOptions, io models.IO) {
+	p := io.Messages
+
+	//-------------------------------------------------
+	//- Print file entry and versions.
+	//-------------------------------------------------
+	for _, entry := range entries {
+		fmt.Fprintf(io.UserOutput, "|- %s %s\n", p.Style(message.Path, entry.Path), p.Style(message.Store, "["+entry.Store+"]"))
+
+		if opt.ViewTags && len(entry.Tags) > 0 {
+			fmt.Fprintf(io.UserOutput, "|   %s\n", p.Style(message.Heading, p.Text(message.ListTags)))
+			for _, tag := range entry.Tags {
 				fmt.Fprintf(io.UserOutput, "|    |- %s\n", tag)
 			}
 
@@ -88,25 +205,35 @@ func listFilesFor(catalogPath string, opt cfg.UserOptions, io models.IO) (int, e
 			}
 		}
 
-		if opt.ViewVersions && len(fileEntry.Versions) > 0 {
-			fmt.Fprintf(io.UserOutput, "|")
-			color.New(color.Bold).Fprintln(io.UserOutput, "   versions")
-			for _, ver := range fileEntry.Versions {
+		if opt.ViewVersions && len(entry.Versions) > 0 {
+			fmt.Fprintf(io.UserOutput, "|   %s\n", p.Style(message.Heading, p.Text(message.ListVersions)))
+			for _, ver := range entry.Versions {
 				fmt.Fprintf(io.UserOutput, "|    |- %s\n", ver)
 			}
 			fmt.Fprintln(io.UserOutput, "|")
 		}
 
-		total++
+		if opt.ViewKeys && len(entry.Keys) > 0 {
+			fmt.Fprintf(io.UserOutput, "|   %s\n", p.Style(message.Heading, p.Text(message.ListKeys)))
+			for _, key := range entry.Keys {
+				modified := p.Text(message.ListUnknown)
+				if !key.Modified.IsZero() {
+					modified = key.Modified.Local().Format(time.RFC822)
+				}
+				fmt.Fprintf(io.UserOutput, "|    |- %s\n", p.Text(message.ListKeyModified, key.Name, modified))
+			}
+			fmt.Fprintln(io.UserOutput, "|")
+		}
 	}
-
-	return total, nil
 }
 
 func init() {
 	RootCmd.AddCommand(listCmd)
 
-	listCmd.Flags().StringVarP(&uo.Tags, "tags", "t", "", "Specify a list of tags used to filter files.")
+	listCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
 	listCmd.Flags().BoolVarP(&uo.ViewTags, "view-tags", "g", false, "Display a list of tags for each file.")
 	listCmd.Flags().BoolVarP(&uo.ViewVersions, "view-version", "v", false, "Display a list of versions for each file.")
+	listCmd.Flags().BoolVarP(&uo.ViewKeys, "keys", "k", false, "Display when each key was last modified for key/value stores.")
+	listCmd.Flags().BoolVarP(&uo.Refresh, "refresh", "", false, "Query stores instead of using recently cached key times.")
+	listCmd.Flags().StringVarP(&uo.Template, "template", "", "", "Format output using a Go template or template file and send to stdout.")
 }
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/metrics"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+// printMetrics displays the store calls made by the command as text,
+// JSON, or Prometheus metrics. Time not spent in store calls is spent
+// in cstore itself. JSON and Prometheus metrics can replace a file
+// instead.
+func printMetrics(format, file string, io models.IO) error {
+	summary := metrics.Summarize()
+
+	out := bytes.Buffer{}
+
+	switch strings.ToLower(format) {
+	case "json":
+		b, err := json.Marshal(summary)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(&out, string(b))
+	case "prometheus":
+		if err := metrics.WritePrometheus(&out, summary); err != nil {
+			return err
+		}
+	case "text":
+		if len(file) > 0 {
+			return fmt.Errorf("Metrics file %s requires json or prometheus metrics.", file)
+		}
+
+		p := io.Messages
+
+		fmt.Fprintf(io.UserOutput, "%s %s\n", p.Style(message.Heading, p.Text(message.MetricsHeading)), p.Text(message.MetricsElapsed, summary.Elapsed, summary.StoreTime(), summary.Elapsed-summary.StoreTime()))
+
+		for _, s := range summary.Stores {
+			ops := []string{}
+			for op, count := range s.Ops {
+				ops = append(ops, fmt.Sprintf("%s %d", op, count))
+			}
+			sort.Strings(ops)
+
+			fmt.Fprintf(io.UserOutput, "|- %s %s\n", p.Style(message.Store, "["+s.Store+"]"), p.Text(message.MetricsCalls, s.Calls, strings.Join(ops, ", ")))
+			fmt.Fprintf(io.UserOutput, "|    |- %s\n", p.Text(message.MetricsErrors, s.Errors, s.Retries, s.Throttles))
+			fmt.Fprintf(io.UserOutput, "|    |- %s\n", p.Text(message.MetricsLatency, s.P50, s.P90, s.P99))
+		}
+
+		for _, f := range summary.Files {
+			results := []string{}
+			for result, count := range f.Results {
+				results = append(results, fmt.Sprintf("%s %d", result, count))
+			}
+			sort.Strings(results)
+
+			fmt.Fprintf(io.UserOutput, "|- %s %s", p.Style(message.Path, "["+f.Path+"]"), strings.Join(results, ", "))
+			if !f.LastSync.IsZero() {
+				fmt.Fprint(io.UserOutput, ", "+p.Text(message.MetricsLastSync, f.LastSync.Local().Format(time.RFC822)))
+			}
+			fmt.Fprintln(io.UserOutput)
+		}
+
+		fmt.Fprintln(io.UserOutput)
+
+		return nil
+	default:
+		return fmt.Errorf("Unknown metrics format %s. Use text, json, or prometheus.", format)
+	}
+
+	if len(file) > 0 {
+		return replaceFile(file, out.Bytes())
+	}
+
+	_, err := out.WriteTo(display.Loud(io.UserOutput))
+
+	return err
+}
+
+// replaceFile writes a temporary file and renames it; so, readers like
+// the node_exporter textfile collector never see a partial file.
+func replaceFile(file string, b []byte) error {
+	temp := fmt.Sprintf("%s.%d", file, os.Getpid())
+
+	if err := ioutil.WriteFile(temp, b, 0644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temp, file); err != nil {
+		os.Remove(temp)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/cache"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/diff"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/session"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+const resultMigrated = "migrated"
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [file_1] [file_2] ...",
+	Short: "Move files from one store to another.",
+	Long: `Move files from one store to another.
+
+Each cataloged file in the --from store, including its versions, is
+pulled, pushed to the --to store, and pulled back to verify it matches
+before its catalog entry is updated. The catalog is saved after each
+file; so, an interrupted run resumes where it stopped. Use --purge to
+delete each file from the --from store once it is verified and
+--dry-run to list the files without moving them.
+
+	$ cstore migrate --from harbor --to aws-parameter --dry-run
+	$ cstore migrate --from harbor --to aws-parameter --purge`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		if len(uo.From) == 0 || len(uo.To) == 0 {
+			display.ErrorText(ioStreams.Messages.Text(message.MigrateUsage), ioStreams)
+			os.Exit(1)
+		}
+
+		if err := Migrate(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Migrate ...
+func Migrate(opt cfg.UserOptions, io models.IO) error {
+	if !opt.DryRun {
+		if err := cfg.Writable("migrate"); err != nil {
+			return err
+		}
+	}
+
+	if opt.From == opt.To {
+		return fmt.Errorf("files are already in %s", opt.To)
+	}
+
+	target, found := store.Get()[opt.To]
+	if !found {
+		return fmt.Errorf("%s store not found. Use 'stores' command to view available stores.", opt.To)
+	}
+
+	//-------------------------------------------------
+	//- Get the local catalog listing the files.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{}
+	files := clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, "")
+
+	for key, fileEntry := range files {
+		if !fileEntry.IsRef && fileEntry.Store == opt.From {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return files[keys[i]].Path < files[keys[j]].Path
+	})
+
+	p := io.Messages
+
+	if len(keys) == 0 {
+		display.ErrorText(p.Text(message.MigrateNoFiles, opt.From), io)
+		return nil
+	}
+
+	//-------------------------------------------------
+	//- List the plan and confirm it with the user.
+	//-------------------------------------------------
+	plan := ""
+	for _, key := range keys {
+		fileEntry := files[key]
+
+		if len(fileEntry.Versions) > 0 {
+			plan += p.Text(message.MigrateMoveVersions, fileEntry.Path, len(fileEntry.Versions), opt.From, opt.To)
+		} else {
+			plan += p.Text(message.MigrateMove, fileEntry.Path, opt.From, opt.To)
+		}
+
+		if !store.SupportsFile(target, fileEntry.Type) {
+			plan += " " + p.Text(message.MigrateUnsupported, fileEntry.Type)
+		} else if opt.PurgeSource {
+			plan += " " + p.Text(message.MigrateAndPurge)
+		}
+
+		plan += "\n"
+	}
+
+	if opt.DryRun {
+		fmt.Fprintf(io.UserOutput, "\n%s\n", plan)
+		fmt.Fprintf(io.UserOutput, "%s\n\n", p.Style(message.Summary, p.Text(message.MigrateDryRun, len(keys))))
+		return nil
+	}
+
+	warning := message.MigrateConfirm
+	if opt.PurgeSource {
+		warning = message.MigrateConfirmPurge
+	}
+
+	confirmed, err := prompt.Confirm(p.Text(warning, plan), prompt.Warn, io)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		fmt.Fprintf(io.UserOutput, "\n%s\n", p.Style(message.Failure, p.Text(message.Aborted)))
+		session.Finish(nil)
+		os.Exit(0)
+	}
+
+	//-------------------------------------------------
+	//- Migrate each file and save the catalog after
+	//- each one; so, a failure part way through
+	//- leaves finished files in the new store.
+	//-------------------------------------------------
+	migrated := 0
+	results := []fileResult{}
+
+	fmt.Fprintln(io.UserOutput)
+	for _, key := range keys {
+		fileEntry := files[key]
+
+		migratedEntry, err := migrateFile(fileEntry, clog, opt, io)
+		if err != nil {
+			display.ErrorText(p.Text(message.MigrateFailed, fileEntry.Path, err), io)
+			results = append(results, fileResult{Path: fileEntry.Path, Store: opt.From, Result: resultFailed, Err: err})
+			continue
+		}
+
+		//-------------------------------------------------
+		//- UpdateEntry refuses store changes; so, the
+		//- verified entry replaces the old one directly.
+		//-------------------------------------------------
+		clog.Files[key] = migratedEntry
+
+		if err := catalog.Write(clog.GetFullPath(opt.Catalog), clog); err != nil {
+			return err
+		}
+
+		migrated++
+		results = append(results, fileResult{Path: fileEntry.Path, Store: opt.To, Result: resultMigrated})
+
+		if opt.PurgeSource {
+			if err := purgeMigrated(fileEntry, clog, opt, io); err != nil {
+				display.ErrorText(p.Text(message.MigratePurgeFailed, fileEntry.Path, opt.From, err), io)
+			}
+		}
+	}
+
+	printResults(results, io)
+
+	p.Summary(io.UserOutput, message.MigrateSummary, migrated, len(keys), opt.To)
+
+	if migrated < len(keys) {
+		return errors.New("Run the command again to retry the files that failed.")
+	}
+
+	return nil
+}
+
+// migrateFile copies the working copy and each version of a file to the
+// new store, verifying each copy, and returns the entry to catalog.
+func migrateFile(fileEntry catalog.File, clog catalog.Catalog, opt cfg.UserOptions, io models.IO) (catalog.File, error) {
+	if !store.SupportsFile(store.Get()[opt.To], fileEntry.Type) {
+		return fileEntry, fmt.Errorf("%s store does not support %s files", opt.To, fileEntry.Type)
+	}
+
+	//----------------------------------------------------
+	//- Get the old and new stores ready.
+	//----------------------------------------------------
+	sourceEntry := overrideFileSettings(fileEntry, opt)
+
+	source, err := getRemoteComponents(&sourceEntry, clog, opt, io)
+	if err != nil {
+		return fileEntry, err
+	}
+
+	if err := justify("migrate", sourceEntry, clog, source, opt); err != nil {
+		return fileEntry, err
+	}
+
+	targetEntry := sourceEntry
+	targetEntry.Store = opt.To
+	targetEntry.Pinned = ""
+	targetEntry.Data = catalog.KnownData(opt.To, sourceEntry.Data)
+
+	target, err := getRemoteComponents(&targetEntry, clog, opt, io)
+	if err != nil {
+		return fileEntry, err
+	}
+
+	//----------------------------------------------------
+	//- Copy the working copy and each version.
+	//----------------------------------------------------
+	p := io.Messages
+
+	for _, version := range append([]string{""}, sourceEntry.Versions...) {
+		if len(version) > 0 {
+			p.Print(io.UserOutput, message.MigratingVersion, p.Style(message.Path, fileEntry.Path), version, p.Style(message.Store, target.store.Name()))
+		} else {
+			p.Print(io.UserOutput, message.Migrating, p.Style(message.Path, fileEntry.Path), p.Style(message.Store, target.store.Name()))
+		}
+
+		if err := copyVersion(source, &sourceEntry, target, &targetEntry, version); err != nil {
+			fmt.Fprintln(io.UserOutput)
+			return fileEntry, err
+		}
+
+		p.Println(io.UserOutput, message.Done)
+	}
+
+	return targetEntry, nil
+}
+
+// copyVersion pulls a file version from the old store, pushes it to the
+// new store, and verifies the new store returns the same contents.
+func copyVersion(source remoteComponents, sourceEntry *catalog.File, target remoteComponents, targetEntry *catalog.File, version string) error {
+	if err := store.Refresh(source.store); err != nil {
+		return fmt.Errorf("Failed to refresh %s credentials. (%s)", source.store.Name(), err)
+	}
+
+	done := measure(source.store.Name(), "pull")
+	data, _, err := source.store.Pull(sourceEntry, version)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("could not pull from %s (%s)", source.store.Name(), err)
+	}
+
+	if err := store.Refresh(target.store); err != nil {
+		return fmt.Errorf("Failed to refresh %s credentials. (%s)", target.store.Name(), err)
+	}
+
+	done = measure(target.store.Name(), "push")
+	err = target.store.Push(targetEntry, data, version)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("could not push to %s (%s)", target.store.Name(), err)
+	}
+
+	//-------------------------------------------------
+	//- Verify the copy matches.
+	//-------------------------------------------------
+	done = measure(target.store.Name(), "pull")
+	copied, _, err := target.store.Pull(targetEntry, version)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("verification failed (%s)", err)
+	}
+
+	if !sameContents(*targetEntry, data, copied) {
+		return fmt.Errorf("verification failed, the %s checksum does not match the %s checksum", target.store.Name(), source.store.Name())
+	}
+
+	return nil
+}
+
+// sameContents compares checksums of the file contents. Key/value stores
+// do not keep the order or comments of env files; so, env files with the
+// same keys and values, or flattened files with the same values, are
+// also the same.
+func sameContents(fileEntry catalog.File, a, b []byte) bool {
+	if cache.Checksum(a) == cache.Checksum(b) {
+		return true
+	}
+
+	if !fileEntry.SupportsConfig() && !fileEntry.Flatten {
+		return false
+	}
+
+	return len(diff.Env(keyValues(fileEntry, a), keyValues(fileEntry, b))) == 0
+}
+
+// purgeMigrated deletes each version and the working copy of a migrated
+// file from the old store.
+func purgeMigrated(fileEntry catalog.File, clog catalog.Catalog, opt cfg.UserOptions, io models.IO) error {
+	sourceEntry := overrideFileSettings(fileEntry, opt)
+
+	source, err := getRemoteComponents(&sourceEntry, clog, opt, io)
+	if err != nil {
+		return err
+	}
+
+	versions := append(append([]string{}, sourceEntry.Versions...), none)
+
+	for _, version := range versions {
+		if err := purgeWithBackup(source, &sourceEntry, clog, opt, version, io); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVarP(&uo.From, "from", "", "", "Set the store files are moved from.")
+	migrateCmd.Flags().StringVarP(&uo.To, "to", "", "", "Set the store files are moved to. The 'stores' command lists options.")
+	migrateCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	migrateCmd.Flags().BoolVarP(&uo.DryRun, "dry-run", "", false, "List the files that would be migrated without moving them.")
+	migrateCmd.Flags().BoolVarP(&uo.PurgeSource, "purge", "", false, "Delete each file from the old store once it is verified in the new store.")
+	migrateCmd.Flags().BoolVarP(&uo.NoBackup, "no-backup", "", false, "Purge without saving a local backup of the remote file(s).")
+	migrateCmd.Flags().StringVarP(&uo.Justification, "justification", "", "", "Explain why access is needed when migrating protected files.")
+}
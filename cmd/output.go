@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// commandOutput is the document --output json sends to stdout; so,
+// scripts can read the results of a command without parsing the text
+// sent to stderr.
+type commandOutput struct {
+	Command   string           `json:"command"`
+	Time      time.Time        `json:"time"`
+	Files     []fileOutput     `json:"files"`
+	Revisions []revisionOutput `json:"revisions,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// fileOutput is a file and what the command did with it.
+type fileOutput struct {
+	Path     string      `json:"path"`
+	Store    string      `json:"store,omitempty"`
+	Type     string      `json:"type,omitempty"`
+	Result   string      `json:"result,omitempty"`
+	Tags     []string    `json:"tags,omitempty"`
+	Versions []string    `json:"versions,omitempty"`
+	Keys     []keyOutput `json:"keys,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// keyOutput is a key of a file. Values are never included.
+type keyOutput struct {
+	Name             string     `json:"name"`
+	Change           string     `json:"change,omitempty"`
+	Modified         *time.Time `json:"modified,omitempty"`
+	Encryption       string     `json:"encryption,omitempty"`
+	EncryptionDetail string     `json:"encryption_detail,omitempty"`
+	Exposed          bool       `json:"exposed,omitempty"`
+}
+
+// revisionOutput is a revision of a file or secret.
+type revisionOutput struct {
+	ID       string    `json:"id"`
+	Modified time.Time `json:"modified"`
+	Latest   bool      `json:"latest,omitempty"`
+	Pinned   bool      `json:"pinned,omitempty"`
+	Labels   []string  `json:"labels,omitempty"`
+	By       string    `json:"by,omitempty"`
+}
+
+// checkOutput returns an error when the output format is not known.
+func checkOutput(format string) error {
+	switch format {
+	case "", outputText, outputJSON:
+		return nil
+	default:
+		return fmt.Errorf("%s output is not supported, use text or json", format)
+	}
+}
+
+// writeOutput sends the results of a command to stdout as JSON when
+// --output json is used.
+func writeOutput(out commandOutput, err error, opt cfg.UserOptions, io models.IO) {
+	if opt.OutputFormat != outputJSON {
+		return
+	}
+
+	out.Time = time.Now().UTC()
+
+	if out.Files == nil {
+		out.Files = []fileOutput{}
+	}
+
+	if err != nil {
+		out.Error = err.Error()
+	}
+
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintln(io.Export, string(b))
+}
+
+// resultOutputs converts the results of a bulk operation.
+func resultOutputs(results []fileResult) []fileOutput {
+	files := []fileOutput{}
+
+	for _, r := range results {
+		f := fileOutput{
+			Path:   r.Path,
+			Store:  r.Store,
+			Result: r.Result,
+		}
+
+		for _, k := range r.Keys {
+			f.Keys = append(f.Keys, keyOutput{Name: k.Key, Change: string(k.Change)})
+		}
+
+		if r.Err != nil {
+			f.Error = r.Err.Error()
+		}
+
+		files = append(files, f)
+	}
+
+	return files
+}
+
+// listOutputs converts the files listed.
+func listOutputs(entries []listEntry) []fileOutput {
+	files := []fileOutput{}
+
+	for _, e := range entries {
+		f := fileOutput{
+			Path:     e.Path,
+			Store:    e.Store,
+			Type:     e.Type,
+			Tags:     e.Tags,
+			Versions: e.Versions,
+		}
+
+		for _, k := range e.Keys {
+			key := keyOutput{Name: k.Name}
+			if !k.Modified.IsZero() {
+				modified := k.Modified.UTC()
+				key.Modified = &modified
+			}
+			f.Keys = append(f.Keys, key)
+		}
+
+		files = append(files, f)
+	}
+
+	return files
+}
+
+// revisionOutputs converts the revisions listed.
+func revisionOutputs(revisions []contract.Revision, pinned string) []revisionOutput {
+	out := []revisionOutput{}
+
+	for _, r := range revisions {
+		out = append(out, revisionOutput{
+			ID:       r.ID,
+			Modified: r.Modified.UTC(),
+			Latest:   r.Latest,
+			Pinned:   len(pinned) > 0 && r.ID == pinned,
+			Labels:   r.Labels,
+			By:       r.By,
+		})
+	}
+
+	return out
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/diff"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+func TestCheckOutput(t *testing.T) {
+	tests := []struct {
+		format string
+		valid  bool
+	}{
+		{"", true},
+		{outputText, true},
+		{outputJSON, true},
+		{"yaml", false},
+	}
+
+	for _, test := range tests {
+		// act
+		err := checkOutput(test.format)
+
+		// assert
+		if (err == nil) != test.valid {
+			t.Errorf("\nEXPECTED: %s valid %t \nACTUAL: %v", test.format, test.valid, err)
+		}
+	}
+}
+
+func TestWriteOutputText(t *testing.T) {
+	for _, format := range []string{"", outputText} {
+		// arrange
+		var export bytes.Buffer
+
+		// act
+		writeOutput(commandOutput{Command: "pull"}, nil, cfg.UserOptions{OutputFormat: format}, models.IO{Export: &export})
+
+		// assert
+		if export.Len() > 0 {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "nothing sent to stdout", export.String())
+		}
+	}
+}
+
+func TestWriteOutputJSON(t *testing.T) {
+	// arrange
+	var export bytes.Buffer
+
+	results := []fileResult{
+		{Path: ".env", Store: "aws-parameter", Result: resultRetrieved, Keys: []diff.KeyChange{{Key: "DB_PASS", Change: diff.Changed, Old: "old-secret", New: "new-secret"}}},
+		{Path: "prod.env", Store: "aws-s3", Result: resultFailed, Err: errors.New("access denied")},
+	}
+
+	// act
+	writeOutput(commandOutput{Command: "pull", Files: resultOutputs(results)}, errors.New("1 file failed"), cfg.UserOptions{OutputFormat: outputJSON}, models.IO{Export: &export})
+
+	// assert
+	out := commandOutput{}
+	if err := json.Unmarshal(export.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Command != "pull" || out.Error != "1 file failed" || out.Time.IsZero() {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %+v", "pull document with error", out)
+	}
+
+	if len(out.Files) != 2 || out.Files[0].Keys[0].Change != "changed" || out.Files[1].Error != "access denied" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %+v", "two files", out.Files)
+	}
+
+	if strings.Contains(export.String(), "secret") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "no values", export.String())
+	}
+}
+
+func TestWriteOutputJSONWithoutFiles(t *testing.T) {
+	// arrange
+	var export bytes.Buffer
+
+	// act
+	writeOutput(commandOutput{Command: "push"}, nil, cfg.UserOptions{OutputFormat: outputJSON}, models.IO{Export: &export})
+
+	// assert
+	if !strings.Contains(export.String(), `"files": []`) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", `"files": []`, export.String())
+	}
+}
+
+func TestListOutputs(t *testing.T) {
+	// arrange
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+
+	entries := []listEntry{{
+		Path:  ".env",
+		Store: "aws-parameter",
+		Type:  "env",
+		Tags:  []string{"dev"},
+		Keys:  []listKey{{Name: "DB_HOST", Modified: modified}, {Name: "PORT"}},
+	}}
+
+	// act
+	files := listOutputs(entries)
+
+	// assert
+	if len(files) != 1 || len(files[0].Keys) != 2 {
+		t.Fatalf("\nEXPECTED: %s \nACTUAL: %+v", "one file with two keys", files)
+	}
+
+	if files[0].Keys[0].Modified == nil || files[0].Keys[0].Modified.Location() != time.UTC || !files[0].Keys[0].Modified.Equal(modified) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", modified.UTC(), files[0].Keys[0].Modified)
+	}
+
+	if files[0].Keys[1].Modified != nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "no modified time", files[0].Keys[1].Modified)
+	}
+}
+
+func TestRevisionOutputs(t *testing.T) {
+	// arrange
+	revisions := []contract.Revision{
+		{ID: "3", Modified: time.Now(), Latest: true},
+		{ID: "2", Modified: time.Now().Add(-time.Hour), By: "jane"},
+	}
+
+	// act
+	out := revisionOutputs(revisions, "2")
+
+	// assert
+	if len(out) != 2 || !out[0].Latest || out[0].Pinned || !out[1].Pinned || out[1].By != "jane" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %+v", "latest 3 and pinned 2", out)
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/path"
+)
+
+// iamResources maps stores saving each key separately to the format
+// of the IAM resource for a remote key.
+var iamResources = map[string]string{
+	"aws-parameter": "arn:aws:ssm:*:*:parameter%s",
+}
+
+// iamReadActions are the actions granted on the keys a role can read.
+var iamReadActions = []string{"ssm:GetParameter", "ssm:GetParameters"}
+
+// policiesCmd represents the policies command
+var policiesCmd = &cobra.Command{
+	Use:   "policies",
+	Short: "Generate IAM policies for the roles declared in the catalog.",
+	Long: `Generate IAM policies for the roles declared in the catalog.
+
+Each role is granted read access to the keys declared for it and no
+other keys in the same file. Policies are sent to stdout as JSON.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		resources, err := roleResourcesFor(uo.Catalog, uo, ioStreams)
+		if err != nil {
+			display.Error(fmt.Errorf("Failed to generate policies for %s. (%s)", uo.Catalog, err), ioStreams)
+			os.Exit(1)
+		}
+
+		var output interface{}
+
+		if len(uo.Role) > 0 {
+			if _, found := resources[uo.Role]; !found {
+				display.ErrorText(fmt.Sprintf("No keys are declared for role %s in %s.", uo.Role, uo.Catalog), ioStreams)
+				os.Exit(1)
+			}
+
+			output = buildIAMPolicy(resources[uo.Role])
+		} else {
+			policies := map[string]iamPolicy{}
+			for role, r := range resources {
+				policies[role] = buildIAMPolicy(r)
+			}
+
+			output = policies
+		}
+
+		b, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(ioStreams.Export, string(b))
+	},
+}
+
+// iamPolicy ...
+type iamPolicy struct {
+	Version   string         `json:"Version"`
+	Statement []iamStatement `json:"Statement"`
+}
+
+// iamStatement ...
+type iamStatement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+}
+
+func buildIAMPolicy(resources []string) iamPolicy {
+	sort.Strings(resources)
+
+	return iamPolicy{
+		Version: "2012-10-17",
+		Statement: []iamStatement{
+			{
+				Effect:   "Allow",
+				Action:   iamReadActions,
+				Resource: resources,
+			},
+		},
+	}
+}
+
+// roleResourcesFor returns the IAM resources of the keys each role can
+// read.
+func roleResourcesFor(catalogPath string, opt cfg.UserOptions, io models.IO) (map[string][]string, error) {
+	basePath := path.RemoveFileName(catalogPath)
+
+	resources := map[string][]string{}
+
+	//-------------------------------------------------
+	//- Get catalog containing files with roles.
+	//-------------------------------------------------
+	clog, err := catalog.Get(catalogPath)
+	if err != nil {
+		return resources, err
+	}
+
+	for _, fileEntry := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, "") {
+		fullPath := path.BuildPath(basePath, fileEntry.Path)
+
+		//-------------------------------------------------
+		//- If entry is catalog, add child resources.
+		//-------------------------------------------------
+		if fileEntry.IsRef {
+			children, err := roleResourcesFor(fullPath, opt, io)
+			if err != nil {
+				return resources, err
+			}
+
+			for role, r := range children {
+				resources[role] = append(resources[role], r...)
+			}
+
+			continue
+		}
+
+		if len(fileEntry.Roles) == 0 {
+			continue
+		}
+
+		if err := fileEntry.CheckRoles(); err != nil {
+			return resources, fmt.Errorf("%s (%s)", fullPath, err)
+		}
+
+		format, supported := iamResources[fileEntry.Store]
+		if !supported {
+			display.Warn(fmt.Sprintf("Roles for %s were skipped. The %s store does not save keys separately.", fullPath, fileEntry.Store), io)
+			continue
+		}
+
+		//--------------------------------------------------
+		//- Get the remote store and vault components ready.
+		//--------------------------------------------------
+		remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+		if err != nil {
+			return resources, err
+		}
+
+		locatable, ok := remoteComp.store.(contract.ILocatableStore)
+		if !ok {
+			return resources, fmt.Errorf("%s store cannot locate keys", remoteComp.store.Name())
+		}
+
+		for _, role := range fileEntry.RoleNames() {
+			for _, pattern := range fileEntry.Roles[role] {
+				location, err := locatable.Locate(&fileEntry, pattern, "")
+				if err != nil {
+					return resources, err
+				}
+
+				resources[role] = append(resources[role], fmt.Sprintf(format, location.Remote))
+			}
+		}
+	}
+
+	return resources, nil
+}
+
+func init() {
+	RootCmd.AddCommand(policiesCmd)
+
+	policiesCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	policiesCmd.Flags().StringVarP(&uo.Role, "role", "", "", "Send only the policy for the role to stdout.")
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/diff"
+	"github.com/turnerlabs/cstore/components/display"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+// previewCmd represents the preview command
+var previewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Render a report of pending changes for review.",
+	Long: `Render a report of pending changes for review.
+
+Files are pulled into memory and compared to the local files, like the
+diff command, and the changes a push would make are rendered as a
+markdown or HTML report to attach to a pull request. Use tags or a
+version to select the target environment.
+
+Values are masked unless the key type is plain or reference. Use
+--reveal to show every value.
+
+Use --serve to host the HTML report for reviewers instead of printing
+it.
+
+	$ cstore preview -t prod > preview.md
+	$ cstore preview -t prod --format html -o preview.html
+	$ cstore preview -t prod --serve localhost:8080`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		switch strings.ToLower(uo.PreviewFormat) {
+		case "markdown", "md", "html":
+		default:
+			display.ErrorText(ioStreams.Messages.Text(message.PreviewFormatUnknown, uo.PreviewFormat), ioStreams)
+			os.Exit(1)
+		}
+
+		if err := Preview(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Preview renders the pending changes of the requested files as a
+// report for review.
+func Preview(opt cfg.UserOptions, io models.IO) error {
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	files := []catalog.File{}
+	for _, f := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, opt.Version) {
+		if !f.IsRef {
+			files = append(files, f)
+		}
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("%s is not aware of requested files. Use 'list' command to view available files.", opt.Catalog)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	report := diff.Report{Title: previewTitle(clog, opt)}
+
+	for _, fileEntry := range files {
+		remote, storeName, err := pullForDiff(clog, fileEntry, opt, io)
+		if err != nil {
+			return err
+		}
+
+		local, err := localFile.GetBy(clog.GetFullPath(fileEntry.Path))
+		missing := err != nil
+		if missing {
+			local = []byte{}
+		}
+
+		fr := buildFileReport(fileEntry, remote, local, opt.Reveal)
+		fr.Store = storeName
+		fr.LocalMissing = missing
+
+		report.Files = append(report.Files, fr)
+	}
+
+	html := strings.ToLower(opt.PreviewFormat) == "html"
+
+	var b bytes.Buffer
+	if html || len(opt.Serve) > 0 {
+		err = report.HTML(&b)
+	} else {
+		err = report.Markdown(&b)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to render preview. (%s)", err)
+	}
+
+	p := io.Messages
+
+	p.Println(io.UserOutput, message.DiffSummary, len(report.Differs()), len(report.Files))
+
+	switch {
+	case len(opt.Serve) > 0:
+		return servePreview(opt.Serve, b.Bytes(), io)
+	case len(opt.OutputFile) > 0:
+		if err := localFile.Save(opt.OutputFile, b.Bytes()); err != nil {
+			return fmt.Errorf("Failed to save %s! (%s)", opt.OutputFile, err)
+		}
+		p.Println(io.UserOutput, message.PreviewSaved, opt.OutputFile)
+	default:
+		io.Export.Write(b.Bytes())
+	}
+
+	return nil
+}
+
+// buildFileReport compares a stored file to the local file masking
+// values the same way as the diff command.
+func buildFileReport(fileEntry catalog.File, remote, local []byte, reveal bool) diff.FileReport {
+	fr := diff.FileReport{Path: fileEntry.Path}
+
+	if fileEntry.SupportsConfig() {
+		for _, c := range diff.Env(
+			gotenv.Parse(bytes.NewReader(remote)),
+			gotenv.Parse(bytes.NewReader(local)),
+		) {
+			if c.Change != diff.Added {
+				c.Old = maskValue(fileEntry, c.Key, c.Old, reveal)
+			}
+			if c.Change != diff.Removed {
+				c.New = maskValue(fileEntry, c.Key, c.New, reveal)
+			}
+			fr.Keys = append(fr.Keys, c)
+		}
+		return fr
+	}
+
+	if bytes.Equal(remote, local) {
+		return fr
+	}
+
+	if bytes.IndexByte(remote, 0) > -1 || bytes.IndexByte(local, 0) > -1 {
+		fr.Binary = true
+		return fr
+	}
+
+	fr.Hunks = diff.Hunks(diff.Lines(splitLines(remote), splitLines(local)), diffContext)
+
+	if !reveal {
+		for _, h := range fr.Hunks {
+			for i := range h.Lines {
+				h.Lines[i].Text = maskLine(h.Lines[i].Text)
+			}
+		}
+	}
+
+	return fr
+}
+
+// previewTitle names the branch and target environment being reviewed.
+func previewTitle(clog catalog.Catalog, opt cfg.UserOptions) string {
+	title := "Pending config changes"
+
+	git := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	git.Dir = clog.CWD
+	if out, err := git.Output(); err == nil {
+		if branch := strings.TrimSpace(string(out)); len(branch) > 0 && branch != "HEAD" {
+			title = fmt.Sprintf("%s on %s", title, branch)
+		}
+	}
+
+	target := []string{}
+	if len(opt.Tags) > 0 {
+		target = append(target, "tags "+opt.Tags)
+	}
+	if len(opt.Version) > 0 {
+		target = append(target, "version "+opt.Version)
+	}
+
+	if len(target) > 0 {
+		title = fmt.Sprintf("%s (%s)", title, strings.Join(target, "; "))
+	}
+
+	return title
+}
+
+// servePreview hosts the rendered report until the command is stopped.
+func servePreview(addr string, page []byte, io models.IO) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	})
+
+	io.Messages.Println(io.UserOutput, message.PreviewServing, addr)
+
+	return http.ListenAndServe(addr, mux)
+}
+
+func init() {
+	RootCmd.AddCommand(previewCmd)
+
+	previewCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	previewCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Set a version to identify a file specific state.")
+	previewCmd.Flags().StringVarP(&uo.PreviewFormat, "format", "", "markdown", "Set the report format to markdown or html.")
+	previewCmd.Flags().StringVarP(&uo.OutputFile, "output-file", "o", "", "Path of the report to save instead of sending it to stdout.")
+	previewCmd.Flags().StringVarP(&uo.Serve, "serve", "", "", "Host the HTML report at an address, like localhost:8080.")
+	previewCmd.Flags().BoolVarP(&uo.Reveal, "reveal", "", false, "Show values instead of masking them.")
+	previewCmd.Flags().StringVarP(&uo.Justification, "justification", "", "", "Explain why access is needed when using protected files.")
+}
@@ -2,21 +2,36 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 	"time"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/assemble"
+	"github.com/turnerlabs/cstore/components/cache"
 	"github.com/turnerlabs/cstore/components/catalog"
 	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/diff"
 	"github.com/turnerlabs/cstore/components/display"
 	"github.com/turnerlabs/cstore/components/env"
 	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/hook"
 	"github.com/turnerlabs/cstore/components/logger"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/metrics"
 	"github.com/turnerlabs/cstore/components/models"
 	"github.com/turnerlabs/cstore/components/path"
-	"github.com/turnerlabs/cstore/components/token"
+	"github.com/turnerlabs/cstore/components/pool"
+	"github.com/turnerlabs/cstore/components/store"
+	"github.com/turnerlabs/cstore/components/structured"
 )
 
 // pullCmd represents the pull command
@@ -27,30 +42,195 @@ var pullCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, userSpecifiedFilePaths []string) {
 		setupUserOptions(userSpecifiedFilePaths)
 
-		if count, total, err := Pull(uo.Catalog, uo, ioStreams); err != nil {
-			display.Error(fmt.Errorf("%s for %s", err, uo.Catalog), ioStreams.UserOutput)
+		if uo.OutputFormat == outputJSON && (uo.Stdout || uo.ExportEnv || len(uo.ExportFormat) > 0) {
+			display.ErrorText(ioStreams.Messages.Text(message.PullOutputConflict), ioStreams)
+			os.Exit(1)
+		}
+
+		count, total, results, err := pullFiles(uo.Catalog, uo, ioStreams)
+
+		writeOutput(commandOutput{Command: "pull", Files: resultOutputs(results)}, err, uo, ioStreams)
+
+		if err != nil && err != ErrPartialPull {
+			display.Error(fmt.Errorf("%s for %s", err, uo.Catalog), ioStreams)
 			os.Exit(1)
-		} else {
-			color.New(color.Bold).Fprintf(ioStreams.UserOutput, "\n%d of %d requested file(s) retrieved.\n\n", count, total)
+		}
+
+		ioStreams.Messages.Summary(ioStreams.UserOutput, message.PullSummary, count, total)
+
+		if err == ErrPartialPull {
+			display.ErrorText(ioStreams.Messages.Text(message.PullPartial, total-count, uo.Failures), ioStreams)
+			os.Exit(partialPullExitCode)
 		}
 	},
 }
 
+// partialPullExitCode is the exit status when --continue-on-error was
+// used and some files could not be retrieved.
+const partialPullExitCode = 3
+
+// ErrPartialPull is returned with the counts of files retrieved when
+// --continue-on-error is used and some files failed.
+var ErrPartialPull = errors.New("some files could not be retrieved")
+
+// pullFailure is a file that could not be retrieved.
+type pullFailure struct {
+	Path  string `json:"path"`
+	Store string `json:"store,omitempty"`
+	Error string `json:"error"`
+}
+
+// pullManifest lists the files that could not be retrieved; so, they
+// can be retried or reported by automation.
+type pullManifest struct {
+	Catalog   string        `json:"catalog"`
+	Time      time.Time     `json:"time"`
+	Requested int           `json:"requested"`
+	Retrieved int           `json:"retrieved"`
+	Failures  []pullFailure `json:"failures"`
+}
+
 // Pull ...
 func Pull(catalogPath string, opt cfg.UserOptions, io models.IO) (int, int, error) {
+	restoredCount, fileCount, _, err := pullFiles(catalogPath, opt, io)
+	return restoredCount, fileCount, err
+}
+
+// pullFiles pulls the requested files returning the counts of files
+// retrieved and requested and the result of each file.
+func pullFiles(catalogPath string, opt cfg.UserOptions, io models.IO) (int, int, []fileResult, error) {
+	restoredCount, fileCount, results, err := pull(catalogPath, opt, io)
+	if err != nil {
+		return 0, 0, results, err
+	}
+
+	printResults(results, io)
+
+	if !opt.ContinueOnError {
+		return restoredCount, fileCount, results, nil
+	}
+
+	//-------------------------------------------------
+	//- Write the failure manifest for automation.
+	//-------------------------------------------------
+	manifest := pullManifest{
+		Catalog:   catalogPath,
+		Time:      time.Now().UTC(),
+		Requested: fileCount,
+		Retrieved: restoredCount,
+		Failures:  []pullFailure{},
+	}
+
+	for _, r := range results {
+		if r.Result != resultFailed {
+			continue
+		}
+
+		failure := pullFailure{Path: r.Path, Store: r.Store}
+		if r.Err != nil {
+			failure.Error = r.Err.Error()
+		}
+
+		manifest.Failures = append(manifest.Failures, failure)
+	}
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return restoredCount, fileCount, results, err
+	}
+
+	if err := ioutil.WriteFile(opt.Failures, append(b, '\n'), 0644); err != nil {
+		return restoredCount, fileCount, results, fmt.Errorf("Could not write %s! (%s)", opt.Failures, err)
+	}
+
+	if len(manifest.Failures) > 0 {
+		return restoredCount, fileCount, results, ErrPartialPull
+	}
+
+	return restoredCount, fileCount, results, nil
+}
+
+// changedKeys returns the keys that differ between two copies of an env
+// file.
+func changedKeys(fileEntry catalog.File, previous, pulled []byte) []diff.KeyChange {
+	if !fileEntry.SupportsConfig() && !fileEntry.Flatten {
+		return nil
+	}
+
+	return diff.Env(keyValues(fileEntry, previous), keyValues(fileEntry, pulled))
+}
+
+// keyChangeMessages are the messages listing the keys a pull changed.
+var keyChangeMessages = map[diff.Change]message.ID{
+	diff.Added:   message.KeyAdded,
+	diff.Removed: message.KeyRemoved,
+	diff.Changed: message.KeyChanged,
+}
+
+// keyValues reads the keys of an env file or, when the file is
+// flattened, the dotted paths of a structured file.
+func keyValues(fileEntry catalog.File, data []byte) map[string]string {
+	if fileEntry.Flatten {
+		values, _, err := structured.Flatten(fileEntry.Type, data)
+		if err != nil {
+			return map[string]string{}
+		}
+		return values
+	}
+
+	return gotenv.Parse(bytes.NewReader(data))
+}
+
+// pullJob is a file being pulled. Files are retrieved from their
+// stores concurrently and then saved one at a time.
+type pullJob struct {
+	fileEntry  catalog.File
+	remoteComp remoteComponents
+	fullPath   string
+
+	// base is the copy cached by the last pull; so, local and
+	// remote changes can be merged.
+	base []byte
+
+	file     []byte
+	etag     string
+	upToDate bool
+	err      error
+}
+
+func pull(catalogPath string, opt cfg.UserOptions, io models.IO) (int, int, []fileResult, error) {
 	restoredCount := 0
 	fileCount := 0
+	results := []fileResult{}
+	p := io.Messages
 
 	//-------------------------------------------------
 	//- Get the local catalog for reference.
 	//-------------------------------------------------
 	clog, err := catalog.Get(catalogPath)
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, results, err
 	}
 
 	root := path.RemoveFileName(catalogPath)
 
+	asOf, err := opt.AsOfTime()
+	if err != nil {
+		return 0, 0, results, err
+	}
+
+	if opt.Pin && len(opt.Revision) == 0 {
+		return 0, 0, results, errors.New("--revision is required to pin files")
+	}
+
+	if len(opt.Revision) > 0 && !asOf.IsZero() {
+		return 0, 0, results, errors.New("--revision and --as-of cannot be used together")
+	}
+
+	if (opt.Pin || opt.Unpin) && len(opt.Version) > 0 {
+		return 0, 0, results, errors.New("only the working copy of a file can be pinned")
+	}
+
 	//----------------------------------------------------------
 	//- Attempt to restore requested files.
 	//-
@@ -66,34 +246,57 @@ func Pull(catalogPath string, opt cfg.UserOptions, io models.IO) (int, int, erro
 	//----------------------------------------------------------
 	fmt.Fprintln(io.UserOutput)
 
-	files := clog.FilesBy(opt.GetPaths(clog.CWD), opt.TagList, opt.AllTags, opt.Version)
+	files := clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, opt.Version)
 
 	if len(opt.Version) > 0 && len(files) == 0 {
-		files = clog.FilesBy(opt.GetPaths(clog.CWD), opt.TagList, opt.AllTags, "")
+		files = clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, "")
 	}
 
 	if len(files) == 0 {
-		return 0, 0, fmt.Errorf("%s is not aware of requested files. Use 'list' command to view available files.", opt.Catalog)
+		return 0, 0, results, fmt.Errorf("%s is not aware of requested files. Use 'list' command to view available files.", opt.Catalog)
+	}
+
+	keys := []string{}
+	for key := range files {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+
+	jobs := []pullJob{}
+
+	// saved lists the catalog files written locally; so, the files
+	// assembled from them can be rebuilt.
+	saved := map[string]bool{}
+
+	// pinned is true when files were pinned or unpinned in the catalog.
+	pinned := false
 
-	for _, fileEntry := range files {
+	for _, key := range keys {
 
 		//-----------------------------------------------------
 		//- Override saved file settings with user preferences.
 		//-----------------------------------------------------
-		fileEntry = overrideFileSettings(fileEntry, opt)
+		fileEntry := overrideFileSettings(files[key], opt)
 
 		//----------------------------------------------------
 		//- Check for a linked catalog with child files.
 		//----------------------------------------------------
 		if fileEntry.IsRef {
-			c, t, err := Pull(path.BuildPath(root, fileEntry.Path), opt, io)
+			c, t, r, err := pull(path.BuildPath(root, fileEntry.Path), opt, io)
 			if err != nil {
-				return 0, 0, err
+				if !opt.ContinueOnError {
+					return 0, 0, results, err
+				}
+
+				display.ErrorText(p.Text(message.LinkedPullFailed, path.BuildPath(root, fileEntry.Path), err), io)
+				results = append(results, fileResult{Path: path.BuildPath(root, fileEntry.Path), Result: resultFailed, Err: err})
+				fileCount++
+				continue
 			}
 
 			restoredCount += c
 			fileCount += t
+			results = append(results, r...)
 			continue
 		}
 
@@ -101,23 +304,155 @@ func Pull(catalogPath string, opt cfg.UserOptions, io models.IO) (int, int, erro
 
 		//----------------------------------------------------
 		//- Get the remote store and vaults components ready.
+		//- Prompts are answered here; so, retrieving files
+		//- concurrently does not ask for input.
 		//----------------------------------------------------
 		fileEntryTemp := fileEntry
 		remoteComp, err := getRemoteComponents(&fileEntryTemp, clog, opt, io)
-		if err != nil {
-			display.Error(fmt.Errorf("Could not retrieve %s! (%s)", path.BuildPath(root, fileEntry.Path), err), io.UserOutput)
+
+		job := pullJob{
+			fileEntry:  fileEntry,
+			remoteComp: remoteComp,
+			fullPath:   clog.GetFullPath(path.BuildPath(root, fileEntry.Path)),
+			err:        err,
+		}
+
+		//----------------------------------------------------
+		//- Keep the copy from the last pull before it is
+		//- replaced; so, changes can be merged.
+		//----------------------------------------------------
+		if opt.Merge && fileEntry.SupportsConfig() {
+			if base, _, err := cached(fileEntry, clog, remoteComp, opt, io); err == nil {
+				job.base, _ = renameKeys(base, fileEntry, false)
+			}
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	waves, err := pullWaves(jobs)
+	if err != nil {
+		return 0, 0, results, err
+	}
+
+	//----------------------------------------------------
+	//- Files are retrieved and saved after the files
+	//- they depend on.
+	//----------------------------------------------------
+	jobs = []pullJob{}
+	for _, wave := range waves {
+		retrieveAll(wave, clog, opt, io)
+		jobs = append(jobs, wave...)
+	}
+
+	for _, job := range jobs {
+		fileEntry, fullPath, remoteComp := job.fileEntry, job.fullPath, job.remoteComp
+		file, etag, upToDate, err := job.file, job.etag, job.upToDate, job.err
+
+		outcome := fileResult{Path: path.BuildPath(root, fileEntry.Path), Store: fileEntry.Store, Result: resultRetrieved}
+		if remoteComp.store != nil {
+			outcome.Store = remoteComp.store.Name()
+		}
+
+		failed := func(err error) {
+			outcome.Result, outcome.Err = resultFailed, err
+			results = append(results, outcome)
+		}
+
+		//----------------------------------------------------
+		//- When offline, use the last copy pulled instead.
+		//----------------------------------------------------
+		source, offline := "", err != nil
+		if offline {
+			if !opt.Offline || !asOf.IsZero() {
+				metrics.RecordFile(clog.Context, path.BuildPath(root, fileEntry.Path), metrics.FileFailed, clog.LastPull(fileEntry.Key()))
+				display.ErrorText(p.Text(message.RetrieveFailed, path.BuildPath(root, fileEntry.Path), err), io)
+				failed(err)
+				continue
+			}
+
+			offlineCopy, pulled, cerr := cached(fileEntry, clog, remoteComp, opt, io)
+			if cerr != nil {
+				metrics.RecordFile(clog.Context, path.BuildPath(root, fileEntry.Path), metrics.FileFailed, clog.LastPull(fileEntry.Key()))
+				display.ErrorText(p.Text(message.RetrieveBothFailed, path.BuildPath(root, fileEntry.Path), err, cerr), io)
+				failed(err)
+				continue
+			}
+
+			metrics.RecordFile(clog.Context, path.BuildPath(root, fileEntry.Path), metrics.FileOffline, pulled)
+
+			file = stampStale(offlineCopy, fileEntry, pulled)
+			source = "offline cache"
+			outcome.Result = resultOffline
+
+			display.Warn(p.Text(message.OfflineCopy, path.BuildPath(root, fileEntry.Path), time.Since(pulled).Round(time.Minute), err), io)
+		} else {
+			source = remoteComp.store.Name()
+
+			result := metrics.FilePulled
+			if upToDate {
+				result = metrics.FileUpToDate
+			}
+
+			metrics.RecordFile(clog.Context, path.BuildPath(root, fileEntry.Path), result, time.Now())
+
+			//----------------------------------------------------
+			//- Keep a copy for offline use when opted in unless
+			//- protected.
+			//----------------------------------------------------
+			if opt.Cache && !upToDate && !fileEntry.Protected {
+				if err := cacheCopy(fileEntry, clog, remoteComp, file, opt, io); err != nil {
+					logger.L.Print(err)
+				}
+			}
+		}
+
+		if upToDate {
+			if local, err := localFile.GetBy(fullPath); err == nil {
+				warnDeprecated(path.BuildPath(root, fileEntry.Path), fileEntry, local, io)
+			}
+
+			p.Println(io.UserOutput, message.UpToDate, p.Style(message.Path, path.BuildPath(root, fileEntry.Path)))
+
+			outcome.Result = resultUpToDate
+			results = append(results, outcome)
+			saved[fileEntry.Path] = true
+
+			restoredCount++
 			continue
 		}
 
 		//----------------------------------------------------
-		//- Pull remote file from store.
+		//- Transform values as declared in the catalog.
 		//----------------------------------------------------
-		file, _, err := remoteComp.store.Pull(&fileEntry, opt.Version)
+		file, err = applyTransforms(file, fileEntry, fileEntry.Transforms.Pull)
+		if err != nil {
+			display.ErrorText(p.Text(message.TransformFailed, path.BuildPath(root, fileEntry.Path), err), io)
+			failed(err)
+			continue
+		}
+
+		file, err = renameKeys(file, fileEntry, false)
 		if err != nil {
-			display.Error(fmt.Errorf("Could not retrieve %s! (%s)", path.BuildPath(root, fileEntry.Path), err), io.UserOutput)
+			display.ErrorText(p.Text(message.RenameFailed, path.BuildPath(root, fileEntry.Path), err), io)
+			failed(err)
 			continue
 		}
 
+		//----------------------------------------------------
+		//- If user specifies, merge local and remote changes.
+		//----------------------------------------------------
+		if opt.Merge {
+			file, err = mergeLocal(path.BuildPath(root, fileEntry.Path), fullPath, fileEntry, job.base, file, io)
+			if err != nil {
+				display.ErrorText(p.Text(message.MergeFailed, path.BuildPath(root, fileEntry.Path), err), io)
+				failed(err)
+				continue
+			}
+		}
+
+		warnDeprecated(path.BuildPath(root, fileEntry.Path), fileEntry, file, io)
+
 		//----------------------------------------------------
 		//- Remove environment variables already exported
 		//----------------------------------------------------
@@ -125,37 +460,61 @@ func Pull(catalogPath string, opt cfg.UserOptions, io models.IO) (int, int, erro
 			file = env.DiffCurrent(file)
 		}
 
+		//-------------------------------------------------
+		//- Render templates into the copy sent to stdout
+		//- or saved to the alternate path; so, the file
+		//- saved locally is still the template to push.
+		//-------------------------------------------------
+		fileWithSecrets, err := renderTemplate(file, fileEntry, clog, remoteComp, opt, io)
+		if err != nil {
+			display.ErrorText(p.Text(message.RenderFailed, path.BuildPath(root, fileEntry.Path), err), io)
+			failed(err)
+			continue
+		}
+
 		//-------------------------------------------------
 		//- If user specifies, inject secrets into file.
 		//-------------------------------------------------
-		fileWithSecrets := file
 		if opt.InjectSecrets {
 			if !fileEntry.SupportsSecrets() {
-				display.Error(fmt.Errorf("Secrets not supported for %s due to incompatible file type.", fileEntry.Path), io.UserOutput)
+				display.ErrorText(p.Text(message.SecretsNotSupported, fileEntry.Path, fileEntry.Type), io)
+				failed(fmt.Errorf("secrets not supported for %s files", fileEntry.Type))
 				continue
 			}
 
-			tokens, err := token.Find(fileWithSecrets, fileEntry.Type, false)
-			if err != nil {
-				display.Error(fmt.Errorf("Failed to find tokens in file %s. (%s)", fileEntry.Path, err), io.UserOutput)
-			}
+			fileWithSecrets = injectSecrets(fileWithSecrets, fileEntry, path.BuildPath(root, fileEntry.Path), clog, remoteComp, io)
+		}
 
-			for k, t := range tokens {
+		//----------------------------------------------------
+		//- If user specifies, alias deprecated keys for the
+		//- consumers still reading the old names.
+		//----------------------------------------------------
+		if opt.AliasDeprecated && fileEntry.SupportsConfig() {
+			fileWithSecrets = env.Alias(fileWithSecrets, fileEntry.Aliases())
+		}
 
-				value, err := remoteComp.secrets.Get(clog.Context, t.Secret(), t.Prop)
-				if err != nil {
-					display.Error(fmt.Errorf("Failed to get value for %s/%s for %s! (%s)", t.Secret(), t.Prop, path.BuildPath(root, fileEntry.Path), t.Secret()), io.UserOutput)
-					continue
-				}
+		results = append(results, outcome)
 
-				t.Value = value
-				tokens[k] = t
-			}
+		//----------------------------------------------------
+		//- If user specifies, report values instead of saving.
+		//----------------------------------------------------
+		if opt.Report {
+			printValueReport(path.BuildPath(root, fileEntry.Path), fileEntry, fileWithSecrets, io)
 
-			fileWithSecrets, err = token.Replace(fileWithSecrets, fileEntry.Type, tokens)
-			if err != nil {
-				display.Error(fmt.Errorf("Failed to replace tokens in file %s. (%s)", fileEntry.Path, err), io.UserOutput)
+			restoredCount++
+			continue
+		}
+
+		//----------------------------------------------------
+		//- If user specifies, send only file contents to stdout.
+		//----------------------------------------------------
+		if opt.Stdout {
+			if _, err := io.Export.Write(fileWithSecrets); err != nil {
+				return 0, 0, results, err
 			}
+
+			restoredCount++
+			continue
 		}
 
 		//----------------------------------------------------
@@ -164,41 +523,26 @@ func Pull(catalogPath string, opt cfg.UserOptions, io models.IO) (int, int, erro
 		if opt.ExportEnv || len(opt.ExportFormat) > 0 {
 
 			script := bytes.Buffer{}
-			msg := "\n%s sent to stdout.\n"
+			format := ""
 
 			switch fileEntry.Type {
 			case "env":
-				switch opt.ExportFormat {
-				case "task-def-secrets":
-					msg = fmt.Sprintf(msg, "AWS task definition secrets")
-					script, err = toTaskDefSecretFormat(fileWithSecrets)
-					if err != nil {
-						logger.L.Print(err)
-					}
-				case "task-def-env":
-					msg = fmt.Sprintf(msg, "AWS task definition environment")
-					script, err = toTaskDefEnvFormat(fileWithSecrets)
-					if err != nil {
-						logger.L.Print(err)
-					}
-				default:
-					msg = fmt.Sprintf(msg, "Terminal export commands")
-					script, err = bufferExportScript(fileWithSecrets)
-					if err != nil {
-						logger.L.Print(err)
-					}
+				script, format, err = formatEnvExport(fileWithSecrets, opt.ExportFormat, fileEntry.Path, fileEntry.ValueTypes)
+				if err != nil {
+					logger.L.Print(err)
 				}
 			case "json":
 				script.Write(fileWithSecrets)
-				msg = fmt.Sprintf(msg, "JSON")
+				format = "JSON"
 			}
 
 			if script.Len() > 0 {
 				if _, err := script.WriteTo(io.Export); err != nil {
-					return 0, 0, err
+					return 0, 0, results, err
 				}
 
-				fmt.Fprintf(io.UserOutput, msg)
+				fmt.Fprintln(io.UserOutput)
+				p.Println(io.UserOutput, message.Exported, format)
 
 				restoredCount++
 				continue
@@ -208,35 +552,78 @@ func Pull(catalogPath string, opt cfg.UserOptions, io models.IO) (int, int, erro
 		//-----------------------------------------------------
 		//- Save editable, secret, and alternate files locally.
 		//-----------------------------------------------------
-		fullPath := clog.GetFullPath(path.BuildPath(root, fileEntry.Path))
+		if fileEntry.Render && len(fileEntry.AternatePath) == 0 && len(opt.AlternateRestorePath) == 0 && !opt.InjectSecrets {
+			display.Warn(p.Text(message.NotRendered, path.BuildPath(root, fileEntry.Path)), io)
+		}
 
-		if len(opt.AlternateRestorePath) == 0 {
-			if err = localFile.Save(fullPath, file); err != nil {
-				return 0, 0, err
+		previous, _ := localFile.GetBy(fullPath)
+
+		if err := saveRetrieved(fullPath, file, fileWithSecrets, fileEntry, clog, root, opt); err != nil {
+			if !opt.ContinueOnError {
+				return 0, 0, results, err
 			}
+
+			display.ErrorText(p.Text(message.SaveFailed, path.BuildPath(root, fileEntry.Path), err), io)
+			results[len(results)-1].Result, results[len(results)-1].Err = resultFailed, err
+			continue
 		}
 
-		if opt.InjectSecrets {
-			if err = localFile.Save(fmt.Sprintf("%s.secrets", fullPath), fileWithSecrets); err != nil {
-				return 0, 0, err
-			}
+		if len(opt.AlternateRestorePath) == 0 {
+			saved[fileEntry.Path] = true
+			results[len(results)-1].Keys = changedKeys(fileEntry, previous, file)
+		}
+
+		p.Print(io.UserOutput, message.Retrieving, p.Style(message.Path, path.BuildPath(root, fileEntry.Path)), p.Style(message.Store, source))
+		if !asOf.IsZero() {
+			p.Print(io.UserOutput, message.RetrievingAsOf, asOf.Format(time.RFC822))
+		}
+		if revision := pullRevision(fileEntry, opt); len(revision) > 0 && !offline {
+			p.Print(io.UserOutput, message.RetrievingRevision, revision)
+		}
+		fmt.Fprintln(io.UserOutput)
+
+		for _, c := range results[len(results)-1].Keys {
+			p.Detail(io.UserOutput, keyChangeMessages[c.Change], c.Key)
 		}
 
-		if len(fileEntry.AternatePath) > 0 || len(opt.AlternateRestorePath) > 0 {
-			fullAternatePath := clog.GetFullPath(path.BuildPath(root, fileEntry.AternatePath))
+		restoredCount++
 
-			if err = localFile.Save(fullAternatePath, fileWithSecrets); err != nil {
-				return 0, 0, err
+		//-------------------------------------------------
+		//- Run the hooks reacting to the new file.
+		//-------------------------------------------------
+		if len(fileEntry.Hooks.PostPull) > 0 && !opt.NoHooks {
+			absPath, _ := filepath.Abs(fullPath)
+
+			if err := hook.Run(fileEntry.Hooks.PostPull, hook.Event{
+				Hook:    "post-pull",
+				Path:    fileEntry.Path,
+				File:    absPath,
+				Context: clog.Context,
+				Store:   source,
+				Version: opt.Version,
+			}, clog.Location(), io.UserOutput); err != nil {
+				display.Error(err, io)
 			}
 		}
 
-		fmt.Fprint(io.UserOutput, "Retrieving [")
-		color.New(color.FgBlue).Fprintf(io.UserOutput, path.BuildPath(root, fileEntry.Path))
-		fmt.Fprint(io.UserOutput, "] <- [")
-		color.New(color.Bold).Fprintf(io.UserOutput, remoteComp.store.Name())
-		fmt.Fprintln(io.UserOutput, "]")
+		if offline || !asOf.IsZero() {
+			continue
+		}
 
-		restoredCount++
+		//-------------------------------------------------
+		//- If user specifies, pin or unpin the revision.
+		//-------------------------------------------------
+		if opt.Pin || opt.Unpin {
+			entry := clog.Files[fileEntry.Key()]
+
+			entry.Pinned = ""
+			if opt.Pin {
+				entry.Pinned = opt.Revision
+			}
+
+			clog.Files[fileEntry.Key()] = entry
+			pinned = true
+		}
 
 		//-------------------------------------------------
 		//- Save the time the user last pulled file.
@@ -245,19 +632,457 @@ func Pull(catalogPath string, opt cfg.UserOptions, io models.IO) (int, int, erro
 			logger.L.Print(err)
 			continue
 		}
+
+		if len(etag) > 0 {
+			if err := clog.RecordETag(fileEntry.Key(), opt.Version, etag, file); err != nil {
+				logger.L.Print(err)
+			}
+		}
+	}
+
+	if pinned {
+		if err := catalog.Write(clog.GetFullPath(catalogPath), clog); err != nil {
+			return 0, 0, results, err
+		}
 	}
 
-	return restoredCount, fileCount, nil
+	results = append(results, assembleFiles(clog, root, saved, io)...)
+
+	return restoredCount, fileCount, results, nil
+}
+
+// mergeLocal combines the changes made to the local copy of an env file
+// since the last pull with the changes made in the store. Keys changed
+// on both sides keep the local value and are reported.
+func mergeLocal(displayPath, fullPath string, fileEntry catalog.File, base, remote []byte, io models.IO) ([]byte, error) {
+	if !fileEntry.SupportsConfig() {
+		return nil, fmt.Errorf("%s files cannot be merged", fileEntry.Type)
+	}
+
+	local, err := localFile.GetBy(fullPath)
+	if err != nil {
+		return remote, nil
+	}
+
+	if len(base) == 0 {
+		display.Warn(io.Messages.Text(message.MergeBaseMissing, displayPath), io)
+	}
+
+	merged, conflicts := env.Resolve(base, local, remote)
+
+	for _, key := range conflicts {
+		display.Warn(io.Messages.Text(message.MergeKeptLocal, key, displayPath), io)
+	}
+
+	return merged, nil
+}
+
+// saveRetrieved saves the editable, secret, and alternate copies of a
+// retrieved file.
+func saveRetrieved(fullPath string, file, fileWithSecrets []byte, fileEntry catalog.File, clog catalog.Catalog, root string, opt cfg.UserOptions) error {
+	if len(opt.AlternateRestorePath) == 0 {
+		if err := localFile.Save(fullPath, file); err != nil {
+			return err
+		}
+	}
+
+	if opt.InjectSecrets {
+		if err := localFile.Save(fmt.Sprintf("%s.secrets", fullPath), fileWithSecrets); err != nil {
+			return err
+		}
+	}
+
+	if len(fileEntry.AternatePath) > 0 || len(opt.AlternateRestorePath) > 0 {
+		fullAternatePath := clog.GetFullPath(path.BuildPath(root, fileEntry.AternatePath))
+
+		if err := localFile.Save(fullAternatePath, fileWithSecrets); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assembleFiles rebuilds the files assembled from the catalog files
+// just saved. Every fragment is read from its local copy; so, a file is
+// rebuilt when any one of its fragments changes.
+func assembleFiles(clog catalog.Catalog, root string, saved map[string]bool, io models.IO) []fileResult {
+	results := []fileResult{}
+	p := io.Messages
+
+	outputs := []string{}
+	for output := range clog.Assemble {
+		outputs = append(outputs, output)
+	}
+	sort.Strings(outputs)
+
+	for _, output := range outputs {
+		assembly := clog.Assemble[output]
+
+		pulled := false
+		for _, source := range assembly.Sources() {
+			pulled = pulled || saved[source]
+		}
+
+		if !pulled {
+			continue
+		}
+
+		outcome := fileResult{Path: path.BuildPath(root, output), Result: resultAssembled}
+
+		if err := assembleFile(clog, root, output, assembly); err != nil {
+			display.ErrorText(p.Text(message.AssembleFailed, path.BuildPath(root, output), err), io)
+
+			outcome.Result, outcome.Err = resultFailed, err
+			results = append(results, outcome)
+			continue
+		}
+
+		p.Println(io.UserOutput, message.Assembling, p.Style(message.Path, path.BuildPath(root, output)), p.Style(message.Store, p.Text(message.FileCount, len(assembly.Sources()))))
+
+		results = append(results, outcome)
+	}
+
+	return results
+}
+
+func assembleFile(clog catalog.Catalog, root, output string, assembly catalog.Assembly) error {
+	for _, f := range clog.Files {
+		if f.Path == output {
+			return fmt.Errorf("%s is a catalog file and cannot be overwritten", output)
+		}
+	}
+
+	read := func(p string) ([]byte, error) {
+		return localFile.GetBy(clog.GetFullPath(path.BuildPath(root, p)))
+	}
+
+	var tmpl []byte
+	if len(assembly.Template) > 0 {
+		b, err := read(assembly.Template)
+		if err != nil {
+			return err
+		}
+		tmpl = b
+	}
+
+	fragments := []assemble.Fragment{}
+
+	for _, p := range assembly.Includes {
+		b, err := read(p)
+		if err != nil {
+			return err
+		}
+
+		fragments = append(fragments, assemble.Fragment{Path: p, Data: b})
+	}
+
+	file, err := assemble.Render(tmpl, fragments)
+	if err != nil {
+		return err
+	}
+
+	return localFile.Save(clog.GetFullPath(path.BuildPath(root, output)), file)
+}
+
+// pullWaves groups the jobs by the dependencies of their files; so,
+// each file is retrieved after the files it depends on.
+func pullWaves(jobs []pullJob) ([][]pullJob, error) {
+	files := []catalog.File{}
+	byPath := map[string]pullJob{}
+
+	for _, job := range jobs {
+		files = append(files, job.fileEntry)
+		byPath[job.fileEntry.Path] = job
+	}
+
+	fileWaves, err := catalog.Waves(files)
+	if err != nil {
+		return nil, err
+	}
+
+	waves := [][]pullJob{}
+	for _, fw := range fileWaves {
+		wave := []pullJob{}
+		for _, f := range fw {
+			wave = append(wave, byPath[f.Path])
+		}
+		waves = append(waves, wave)
+	}
+
+	return waves, nil
+}
+
+// retrieveAll retrieves the files from their stores, running up to
+// --concurrency retrievals at once.
+func retrieveAll(jobs []pullJob, clog catalog.Catalog, opt cfg.UserOptions, io models.IO) {
+	pool.Run(concurrency(opt), len(jobs), func(i int) error {
+		job := &jobs[i]
+		if job.err != nil {
+			return job.err
+		}
+
+		job.file, job.etag, job.upToDate, job.err = retrieve(job.fileEntry, clog, job.remoteComp, job.fullPath, opt, io)
+
+		return job.err
+	})
+}
+
+// concurrency returns how many files are handled at once. When the flag
+// is not used, the user config sets it.
+func concurrency(opt cfg.UserOptions) int {
+	if opt.Concurrency > 0 {
+		return opt.Concurrency
+	}
+
+	if n := viper.GetInt(concurrencyToken); n > 0 {
+		return n
+	}
+
+	return 1
+}
+
+// retrieve pulls a file from the store unless it is unchanged since the
+// last pull.
+func retrieve(fileEntry catalog.File, clog catalog.Catalog, remoteComp remoteComponents, fullPath string, opt cfg.UserOptions, io models.IO) ([]byte, string, bool, error) {
+	var err error
+
+	//----------------------------------------------------
+	//- Skip files unchanged since the last pull. The etag
+	//- of the latest state is also recorded; so, pushes
+	//- can detect changes made by others since the pull.
+	//----------------------------------------------------
+	etag := ""
+	if conditional, ok := remoteComp.store.(contract.IConditionalStore); ok && pullsLatest(fileEntry, opt) {
+		done := measure(remoteComp.store.Name(), "etag")
+		etag, err = conditional.ETag(&fileEntry, opt.Version)
+		done(err)
+
+		if err != nil {
+			logger.L.Print(err)
+		}
+
+		if current, err := localFile.GetBy(fullPath); err == nil && restoresFileOnly(fileEntry, opt) && clog.IsUnchanged(fileEntry.Key(), opt.Version, etag, current) {
+			return current, etag, true, nil
+		}
+	}
+
+	//----------------------------------------------------
+	//- Require and report a reason for protected files.
+	//----------------------------------------------------
+	if err := justify("pull", fileEntry, clog, remoteComp, opt); err != nil {
+		return nil, etag, false, err
+	}
+
+	//----------------------------------------------------
+	//- Refresh credentials that would expire mid-pull.
+	//----------------------------------------------------
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return nil, etag, false, fmt.Errorf("failed to refresh %s credentials (%s)", remoteComp.store.Name(), err)
+	}
+
+	//----------------------------------------------------
+	//- Pull the revision requested or pinned.
+	//----------------------------------------------------
+	if revision := pullRevision(fileEntry, opt); len(revision) > 0 {
+		revisioned, ok := remoteComp.store.(contract.IRevisionStore)
+		if !ok {
+			return nil, etag, false, fmt.Errorf("%s store does not keep file revisions", remoteComp.store.Name())
+		}
+
+		done := measure(remoteComp.store.Name(), "pull")
+		file, _, err := revisioned.PullRevision(&fileEntry, opt.Version, revision)
+		done(err)
+
+		return file, etag, false, err
+	}
+
+	//----------------------------------------------------
+	//- Pull the state at a point in time when specified.
+	//----------------------------------------------------
+	if asOf, _ := opt.AsOfTime(); !asOf.IsZero() {
+		historical, ok := remoteComp.store.(contract.IHistoricalStore)
+		if !ok {
+			return nil, etag, false, fmt.Errorf("%s store does not keep file history", remoteComp.store.Name())
+		}
+
+		done := measure(remoteComp.store.Name(), "pull")
+		file, _, err := historical.PullAsOf(&fileEntry, opt.Version, asOf)
+		done(err)
+
+		return file, etag, false, err
+	}
+
+	//----------------------------------------------------
+	//- Pull remote file from store.
+	//----------------------------------------------------
+	done := measure(remoteComp.store.Name(), "pull")
+	file, _, err := remoteComp.store.Pull(&fileEntry, opt.Version)
+	done(err)
+	if err != nil {
+		return nil, etag, false, err
+	}
+
+	return file, etag, false, nil
+}
+
+// cacheCopy encrypts a pulled file with the cache key kept in the
+// access vault and saves it for offline use.
+func cacheCopy(fileEntry catalog.File, clog catalog.Catalog, remoteComp remoteComponents, file []byte, opt cfg.UserOptions, io models.IO) error {
+	if remoteComp.access == nil {
+		return errors.New("offline copy not saved, access vault unavailable")
+	}
+
+	key, err := cache.Key(remoteComp.access, clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	return cache.Save(key, clog.Context, fileEntry.Key(), opt.Version, file)
+}
+
+// cached returns the copy of a file saved for offline use and when it
+// was pulled.
+func cached(fileEntry catalog.File, clog catalog.Catalog, remoteComp remoteComponents, opt cfg.UserOptions, io models.IO) ([]byte, time.Time, error) {
+	if !cache.Has(clog.Context, fileEntry.Key(), opt.Version) {
+		return nil, time.Time{}, errors.New("no offline copy is available")
+	}
+
+	if remoteComp.access == nil {
+		return nil, time.Time{}, errors.New("offline copy cannot be decrypted, access vault unavailable")
+	}
+
+	key, err := cache.Key(remoteComp.access, clog.Context, io)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return cache.Get(key, clog.Context, fileEntry.Key(), opt.Version)
+}
+
+// stampStale adds a comment to offline copies of env files warning
+// they may be stale.
+func stampStale(file []byte, fileEntry catalog.File, pulled time.Time) []byte {
+	if !fileEntry.SupportsConfig() {
+		return file
+	}
+
+	stamp := fmt.Sprintf("# cstore offline copy pulled %s; may be stale\n", pulled.Local().Format(time.RFC822))
+
+	return append([]byte(stamp), file...)
+}
+
+// printValueReport prints the size and entropy of each value in an env
+// file with the values masked.
+func printValueReport(filePath string, fileEntry catalog.File, file []byte, io models.IO) {
+	p := io.Messages
+
+	fmt.Fprintf(io.UserOutput, "|- %s\n", p.Style(message.Path, filePath))
+
+	if !fileEntry.SupportsConfig() {
+		fmt.Fprintf(io.UserOutput, "|    |- %s\n|\n", p.Text(message.ReportUnsupported, fileEntry.Type))
+		return
+	}
+
+	for _, stats := range env.Report(file) {
+		fmt.Fprintf(io.UserOutput, "|    |- %s", p.Text(message.ReportValue, stats.Key, stats.Masked, stats.Size, stats.Entropy, stats.Bits))
+
+		if stats.Large {
+			fmt.Fprint(io.UserOutput, " "+p.Style(message.Caution, p.Text(message.ReportLarge)))
+		}
+
+		if stats.Weak() && isSecretKeyType(fileEntry.KeyType(stats.Key)) {
+			fmt.Fprint(io.UserOutput, " "+p.Style(message.Failure, p.Text(message.ReportWeak)))
+		}
+
+		fmt.Fprintln(io.UserOutput)
+	}
+
+	fmt.Fprintln(io.UserOutput, "|")
+}
+
+// warnDeprecated warns about each deprecated key in an env file.
+func warnDeprecated(filePath string, fileEntry catalog.File, file []byte, io models.IO) {
+	if len(fileEntry.Deprecated) == 0 || !fileEntry.SupportsConfig() {
+		return
+	}
+
+	environment := gotenv.Parse(bytes.NewReader(file))
+
+	for _, key := range fileEntry.DeprecatedKeys() {
+		if _, exists := environment[key]; exists {
+			display.Warn(fmt.Sprintf("%s in %s", fileEntry.Deprecated[key].Message(key), filePath), io)
+		}
+	}
+}
+
+// isSecretKeyType returns true when values of the key type are expected
+// to be hard to guess.
+func isSecretKeyType(keyType string) bool {
+	return keyType == catalog.KeyTypeSecret || keyType == catalog.KeyTypeGenerated
+}
+
+// pullRevision returns the revision of a file to pull. Pinned files
+// pull their revision unless another state is requested or the file is
+// being unpinned.
+func pullRevision(fileEntry catalog.File, opt cfg.UserOptions) string {
+	switch {
+	case len(opt.Revision) > 0:
+		return opt.Revision
+	case opt.Unpin, len(opt.Version) > 0, len(opt.AsOf) > 0:
+		return ""
+	default:
+		return fileEntry.Pinned
+	}
+}
+
+// pullsLatest returns true when a pull retrieves the latest state of
+// the file instead of a revision or a point in time.
+func pullsLatest(fileEntry catalog.File, opt cfg.UserOptions) bool {
+	return len(opt.AsOf) == 0 && len(pullRevision(fileEntry, opt)) == 0
+}
+
+// restoresFileOnly returns true when a pull will only restore the
+// file itself, so an unchanged file does not need to be retrieved.
+func restoresFileOnly(fileEntry catalog.File, opt cfg.UserOptions) bool {
+	return !opt.Force &&
+		!opt.Stdout &&
+		!opt.Report &&
+		pullsLatest(fileEntry, opt) &&
+		!opt.ExportEnv &&
+		len(opt.ExportFormat) == 0 &&
+		!opt.InjectSecrets &&
+		!opt.NoOverwrite &&
+		len(opt.AlternateRestorePath) == 0 &&
+		len(fileEntry.AternatePath) == 0
 }
 
 func init() {
 	RootCmd.AddCommand(pullCmd)
 
 	pullCmd.Flags().BoolVarP(&uo.ExportEnv, "export", "e", false, "Append export command to environment variables and send to stdout.")
-	pullCmd.Flags().StringVarP(&uo.Tags, "tags", "t", "", "Specify a list of tags used to filter files.")
+	pullCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
 	pullCmd.Flags().StringVarP(&uo.ExportFormat, "format", "g", "", "Format environment variables and send to stdout")
 	pullCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Set a version to identify a file specific state.")
 	pullCmd.Flags().BoolVarP(&uo.InjectSecrets, "inject-secrets", "i", false, "Generate *.secrets file containing configuration including secrets.")
 	pullCmd.Flags().StringVarP(&uo.AlternateRestorePath, "alt", "a", "", "Set an alternate path to clone the file to during a restore.")
 	pullCmd.Flags().BoolVarP(&uo.NoOverwrite, "no-overwrite", "n", false, "Only pulls the environment variables that are not exported in the current environment.")
+	pullCmd.Flags().BoolVarP(&uo.Force, "force", "", false, "Retrieve file(s) even when unchanged since the last pull.")
+	pullCmd.Flags().BoolVarP(&uo.Merge, "merge", "", false, "Merge the local and remote changes to env files instead of replacing the local file.")
+	pullCmd.Flags().BoolVarP(&uo.Stdout, "stdout", "", false, "Send only the file contents to stdout instead of saving files.")
+	pullCmd.Flags().StringVarP(&uo.Revision, "revision", "", "", "Retrieve file(s) as saved in a store revision. Use 'versions' command to view available revisions.")
+	pullCmd.Flags().BoolVarP(&uo.Pin, "pin", "", false, "Record the revision in the catalog; so, later pulls retrieve it instead of the latest state.")
+	pullCmd.Flags().BoolVarP(&uo.Unpin, "unpin", "", false, "Remove the pinned revision from the catalog and retrieve the latest state.")
+	pullCmd.Flags().StringVarP(&uo.AsOf, "as-of", "", "", "Retrieve file(s) as they were at a time, like 2006-01-02 15:04, from stores keeping history.")
+	pullCmd.Flags().BoolVarP(&uo.AliasDeprecated, "alias-deprecated", "", false, "Add deprecated keys missing from exported or injected env files with the values of their replacements.")
+	pullCmd.Flags().BoolVarP(&uo.Report, "report", "", false, "Display the size and entropy of each value with values masked instead of saving files.")
+	pullCmd.Flags().BoolVarP(&uo.ContinueOnError, "continue-on-error", "", false, "Retrieve every file possible, write the files that failed to --failures, and exit with 3 when any failed.")
+	pullCmd.Flags().StringVarP(&uo.Failures, "failures", "", "cstore-failures.json", "Set the file --continue-on-error lists failed files in.")
+	pullCmd.Flags().IntVarP(&uo.Concurrency, concurrencyToken, "", 0, "Retrieve up to this many files from their stores at once. (default 1 or concurrency in the user config)")
+	pullCmd.Flags().BoolVarP(&uo.Offline, "offline", "", false, "Use the last copy pulled when the store cannot be reached.")
+	pullCmd.Flags().BoolP(cacheToken, "", false, "Keep an encrypted copy of each pulled file for --offline. (default false or offline-cache in the user config)")
+	pullCmd.Flags().BoolVarP(&uo.NoHooks, "no-hooks", "", false, "Skip the post-pull hooks declared in the catalog.")
+	pullCmd.Flags().StringVarP(&uo.Justification, "justification", "", "", "Explain why access is needed when pulling protected files.")
+
+	viper.BindPFlag(cacheToken, pullCmd.Flags().Lookup(cacheToken))
 }
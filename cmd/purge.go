@@ -3,16 +3,21 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/cache"
 	"github.com/turnerlabs/cstore/components/catalog"
 	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
 	"github.com/turnerlabs/cstore/components/display"
 	"github.com/turnerlabs/cstore/components/logger"
+	"github.com/turnerlabs/cstore/components/message"
 	"github.com/turnerlabs/cstore/components/models"
 	"github.com/turnerlabs/cstore/components/path"
 	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/session"
+	"github.com/turnerlabs/cstore/components/store"
 )
 
 // purgeCmd represents the purge command
@@ -28,7 +33,7 @@ Purge does not delete linked catalogs or their files.`,
 		setupUserOptions(userSpecifiedFilePaths)
 
 		if err := Purge(uo, ioStreams); err != nil {
-			display.Error(fmt.Errorf("%s for %s", err, uo.Catalog), ioStreams.UserOutput)
+			display.Error(fmt.Errorf("%s for %s", err, uo.Catalog), ioStreams)
 			os.Exit(1)
 		}
 	},
@@ -36,8 +41,14 @@ Purge does not delete linked catalogs or their files.`,
 
 // Purge ...
 func Purge(opt cfg.UserOptions, io models.IO) error {
+	if err := cfg.Writable("purge"); err != nil {
+		return err
+	}
+
 	count := 0
 	purged := 0
+	results := []fileResult{}
+	p := io.Messages
 
 	//-------------------------------------------------
 	//- Get the local catalog for reference.
@@ -50,24 +61,31 @@ func Purge(opt cfg.UserOptions, io models.IO) error {
 	//-------------------------------------------------
 	//- Confirm file deletes with user.
 	//-------------------------------------------------
-	files := clog.FilesBy(opt.GetPaths(clog.CWD), opt.TagList, opt.AllTags, opt.Version)
+	files := clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, opt.Version)
 
 	if len(files) == 0 {
-		display.ErrorText("No matching files stored remotely!", ioStreams.UserOutput)
+		display.ErrorText(p.Text(message.PurgeNoFiles), io)
+		session.Finish(nil)
 		os.Exit(0)
 	}
 
 	fileList := ""
 	for _, f := range files {
 		if len(opt.Version) > 0 {
-			fileList = fmt.Sprintf("%sDelete [%s](%s) from [%s]\n", fileList, f.Path, opt.Version, f.Store)
+			fileList += p.Text(message.PurgeDeleteVersion, f.Path, opt.Version, f.Store) + "\n"
 		} else {
-			fileList = fmt.Sprintf("%sDelete [%s] from [%s]\n", fileList, f.Path, f.Store)
+			fileList += p.Text(message.PurgeDelete, f.Path, f.Store) + "\n"
 		}
 	}
 
-	if !prompt.Confirm(fmt.Sprintf("File data will be permanently deleted from remote storage! Local files and secrets stored in AWS Secrets Manager will not be affected.\n\n%s \nContinue?", fileList), prompt.Danger, io) {
-		color.New(color.Bold, color.FgRed).Fprint(ioStreams.UserOutput, "\nOperation Aborted!\n")
+	confirmed, err := prompt.Confirm(p.Text(message.PurgeConfirm, fileList), prompt.Danger, io)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		fmt.Fprintf(io.UserOutput, "\n%s\n", p.Style(message.Failure, p.Text(message.Aborted)))
+		session.Finish(nil)
 		os.Exit(0)
 	}
 
@@ -96,16 +114,22 @@ func Purge(opt cfg.UserOptions, io models.IO) error {
 		//----------------------------------------------------
 		remoteComp, err := getRemoteComponents(&fileEntryTemp, clog, opt, io)
 		if err != nil {
-			display.Error(fmt.Errorf("Purge aborted for %s! (%s)", fileEntry.Path, err), ioStreams.UserOutput)
+			display.ErrorText(p.Text(message.PurgeAborted, fileEntry.Path, err), io)
+			results = append(results, fileResult{Path: fileEntry.Path, Store: fileEntry.Store, Result: resultFailed, Err: err})
 			continue
 		}
 
+		result := fileResult{Path: fileEntry.Path, Store: remoteComp.store.Name(), Result: resultPurged}
+
 		//----------------------------------------------------
 		//- If version specified, delete it.
 		//----------------------------------------------------
 		if len(opt.Version) > 0 {
-			if err = remoteComp.store.Purge(&fileEntry, opt.Version); err != nil {
-				logger.L.Print(err)
+			if err = purgeWithBackup(remoteComp, &fileEntry, clog, opt, opt.Version, io); err != nil {
+				displayPurgeError(err, fileEntry.Path, opt.Version, io)
+				result.Result, result.Err = resultFailed, err
+				results = append(results, result)
+				continue
 			}
 
 			for i, ver := range fileEntry.Versions {
@@ -117,6 +141,7 @@ func Purge(opt cfg.UserOptions, io models.IO) error {
 			clog.Files[key] = fileEntry
 
 			purged++
+			results = append(results, result)
 		}
 
 		//----------------------------------------------------
@@ -129,8 +154,8 @@ func Purge(opt cfg.UserOptions, io models.IO) error {
 			undeletedVersions := []string{}
 
 			for _, version := range fileEntry.Versions {
-				if err = remoteComp.store.Purge(&fileEntry, version); err != nil {
-					display.Error(fmt.Errorf("Purge aborted for %s (%s). (%s)", fileEntry.Path, version, err), io.UserOutput)
+				if err = purgeWithBackup(remoteComp, &fileEntry, clog, opt, version, io); err != nil {
+					displayPurgeError(err, fileEntry.Path, version, io)
 					undeletedVersions = append(undeletedVersions, version)
 					continue
 				}
@@ -144,22 +169,28 @@ func Purge(opt cfg.UserOptions, io models.IO) error {
 			//- Delete the file.
 			//----------------------------------------------------
 			if len(undeletedVersions) == 0 {
-				if err = remoteComp.store.Purge(&fileEntry, none); err != nil {
-					display.ErrorText(fmt.Sprintf("Purge aborted for %s (%s)", fileEntry.Path, err), io.UserOutput)
+				if err = purgeWithBackup(remoteComp, &fileEntry, clog, opt, none, io); err != nil {
+					displayPurgeError(err, fileEntry.Path, none, io)
+					result.Result, result.Err = resultFailed, err
+					results = append(results, result)
 					continue
 				}
 
 				delete(clog.Files, key)
 				purged++
+			} else {
+				result.Result, result.Err = resultFailed, fmt.Errorf("%d version(s) not purged", len(undeletedVersions))
 			}
 
+			results = append(results, result)
+
 			//----------------------------------------------------
 			//- Delete the ghost .cstore reference file.
 			//----------------------------------------------------
 			fullPath := clog.GetFullPath(path.RemoveFileName(fileEntry.Path))
 			if len(fullPath) > 0 && !clog.AnyFilesIn(path.RemoveFileName(fileEntry.Path)) {
 				if err := os.Remove(fmt.Sprintf("%s%s", fullPath, catalog.GhostFile)); err != nil {
-					display.Error(fmt.Errorf(".cstore file could not be removed for %s! (%s)", fileEntry.Path, err), io.UserOutput)
+					display.ErrorText(p.Text(message.PurgeGhostFailed, fileEntry.Path, err), io)
 				}
 			}
 
@@ -186,14 +217,70 @@ func Purge(opt cfg.UserOptions, io models.IO) error {
 		}
 	}
 
-	color.New(color.Bold).Fprintf(ioStreams.UserOutput, "\n%d of %d file(s) purged from remote storage.\n\n", purged, count)
+	printResults(results, io)
+
+	p.Summary(io.UserOutput, message.PurgeSummary, purged, count)
 
 	return nil
 }
 
+// purgeWithBackup saves a backup of the remote file version before it
+// is purged unless the user opts out.
+func purgeWithBackup(remoteComp remoteComponents, fileEntry *catalog.File, clog catalog.Catalog, opt cfg.UserOptions, version string, io models.IO) error {
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return fmt.Errorf("failed to refresh %s credentials (%s)", remoteComp.store.Name(), err)
+	}
+
+	if !opt.NoBackup {
+		if err := snapshot(remoteComp, *fileEntry, clog, version, "purge", io); err != nil {
+			return err
+		}
+	}
+
+	done := measure(remoteComp.store.Name(), "purge")
+	err := remoteComp.store.Purge(fileEntry, version)
+	done(err)
+
+	if err := cache.ClearMetadata(clog.Context, fileEntry.Key()); err != nil {
+		logger.L.Print(err)
+	}
+
+	return err
+}
+
+// displayPurgeError reports each key that could not be deleted when
+// a purge partially fails, so the purge can be retried.
+func displayPurgeError(err error, filePath, version string, io models.IO) {
+	if len(version) > 0 {
+		filePath = fmt.Sprintf("%s (%s)", filePath, version)
+	}
+
+	perr, ok := err.(contract.PurgeError)
+	if !ok {
+		display.ErrorText(io.Messages.Text(message.PurgeAborted, filePath, err), io)
+		return
+	}
+
+	display.ErrorText(io.Messages.Text(message.PurgeIncomplete, filePath, err), io)
+
+	keys := []string{}
+	for key := range perr.Failed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		io.Messages.Println(io.UserOutput, message.KeyFailed, io.Messages.Style(message.Failure, key), perr.Failed[key])
+	}
+
+	fmt.Fprintln(io.UserOutput)
+	io.Messages.Println(io.UserOutput, message.PurgeRetry)
+}
+
 func init() {
 	RootCmd.AddCommand(purgeCmd)
 
-	purgeCmd.Flags().StringVarP(&uo.Tags, "tags", "t", "", "Specify a list of tags used to filter files.")
+	purgeCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
 	purgeCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Remove specific version.")
+	purgeCmd.Flags().BoolVarP(&uo.NoBackup, "no-backup", "", false, "Purge without saving a local backup of the remote file(s).")
 }
@@ -1,20 +1,34 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"time"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/cache"
 	"github.com/turnerlabs/cstore/components/catalog"
 	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/diff"
 	"github.com/turnerlabs/cstore/components/display"
 	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/hook"
 	"github.com/turnerlabs/cstore/components/logger"
+	"github.com/turnerlabs/cstore/components/message"
 	"github.com/turnerlabs/cstore/components/models"
 	"github.com/turnerlabs/cstore/components/path"
+	"github.com/turnerlabs/cstore/components/pipeline"
+	"github.com/turnerlabs/cstore/components/policy"
+	"github.com/turnerlabs/cstore/components/pool"
 	"github.com/turnerlabs/cstore/components/prompt"
 	"github.com/turnerlabs/cstore/components/store"
 	"github.com/turnerlabs/cstore/components/token"
@@ -27,35 +41,109 @@ var pushCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, userSpecifiedFilePaths []string) {
 		setupUserOptions(userSpecifiedFilePaths)
 
-		if err := Push(uo, ioStreams); err != nil {
-			display.Error(err, ioStreams.UserOutput)
+		results, err := pushFiles(uo, ioStreams)
+
+		writeOutput(commandOutput{Command: "push", Files: resultOutputs(results)}, err, uo, ioStreams)
+
+		if err != nil {
+			display.Error(err, ioStreams)
 			os.Exit(1)
 		}
 	},
 }
 
+// stagedFile is a file that passed all checks and is ready to push.
+type stagedFile struct {
+	path       string
+	fileEntry  catalog.File
+	remoteComp remoteComponents
+	data       []byte
+
+	// local is the local file pushed before transforms.
+	local []byte
+
+	// existed and previous hold the remote state before the push; so,
+	// a change set can be rolled back and the keys changed listed.
+	existed  bool
+	previous []byte
+
+	// resume lists the keys left by an interrupted push when resuming.
+	resume []string
+}
+
 // Push ...
 func Push(opt cfg.UserOptions, io models.IO) error {
+	_, err := pushFiles(opt, io)
+	return err
+}
+
+// pushFiles pushes the requested files returning the result of each
+// file.
+func pushFiles(opt cfg.UserOptions, io models.IO) ([]fileResult, error) {
+	if err := cfg.Writable("push"); err != nil {
+		return nil, err
+	}
+
+	if len(opt.Paths) > 0 && cfg.IsTagExpression(opt.Tags) {
+		return nil, errors.New("Tags set on pushed files must be a | delimited list. Tag expressions select files to push when no files are specified.")
+	}
+
 	filesPushed := []string{}
+	failed := map[string]error{}
 	fileCount := 0
+	results := []fileResult{}
+	p := io.Messages
 
 	//-------------------------------------------------
 	//- Get or create the local catalog for push.
 	//-------------------------------------------------
 	clog, err := catalog.GetMake(opt.Catalog, io)
 	if err != nil {
-		return err
+		return results, err
+	}
+
+	//-------------------------------------------------
+	//- Load the policy pushes must satisfy.
+	//-------------------------------------------------
+	pol, err := policy.Load(opt.Policy, repoPolicy(clog))
+	if err != nil {
+		return results, fmt.Errorf("Could not load policy! (%s)", err)
 	}
 
 	//-------------------------------------------------
-	//- Process each file the user wants to push.
+	//- Get the files in the change set when specified.
 	//-------------------------------------------------
+	filePaths := getFilePathsToPush(clog, opt)
+
+	if len(opt.ChangeSet) > 0 {
+		if len(opt.Paths) > 0 || !opt.TagFilter.IsEmpty() {
+			return results, errors.New("Files and tags cannot be specified with a change set.")
+		}
+
+		if opt.Resume {
+			return results, errors.New("Change sets are rolled back instead of resumed.")
+		}
+
+		set, found := clog.ChangeSets[opt.ChangeSet]
+		if !found || len(set) == 0 {
+			return results, fmt.Errorf("%s does not define change set %s.", opt.Catalog, opt.ChangeSet)
+		}
+
+		filePaths = removeDups(set)
+	}
+
+	//-------------------------------------------------
+	//- Stage each file the user wants to push.
+	//-------------------------------------------------
+	staged := []stagedFile{}
+
 	fmt.Fprintln(io.UserOutput)
-	for _, filePath := range getFilePathsToPush(clog, opt) {
+	for _, filePath := range filePaths {
 
 		file, err := localFile.GetBy(clog.GetFullPath(filePath))
 		if err != nil {
-			display.Error(err, io.UserOutput)
+			display.Error(err, io)
+			results = append(results, fileResult{Path: filePath, Result: resultNotPushed, Err: err})
 			continue
 		}
 
@@ -70,49 +158,94 @@ func Push(opt cfg.UserOptions, io models.IO) error {
 		//- If file is a catalog, link it to this catalog.
 		//-------------------------------------------------
 		if fileEntry.IsRef {
-			fmt.Fprintf(io.UserOutput, "Linking %s   %s \n", fileEntry.Path, checkMark)
+			p.Println(io.UserOutput, message.Linking, fileEntry.Path, p.Text(message.Done))
 			if err := clog.UpdateEntry(fileEntry); err != nil {
-				display.Error(err, io.UserOutput)
+				display.Error(err, io)
 			}
 			continue
 		} else {
 			fileCount++
 		}
 
+		if len(fileEntry.Pinned) > 0 && len(opt.Version) == 0 {
+			display.Warn(p.Text(message.PushPinned, fileEntry.Path, fileEntry.Pinned), io)
+		}
+
+		results = append(results, fileResult{Path: filePath, Store: fileEntry.Store, Result: resultNotPushed})
+
 		//--------------------------------------------------
 		//- Get the remote store and vault components ready.
 		//--------------------------------------------------
 		remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
 		if err != nil {
-			display.Error(err, io.UserOutput)
+			display.Error(err, io)
+			results[len(results)-1].Err = err
 			continue
 		}
+		results[len(results)-1].Store = remoteComp.store.Name()
 
 		//--------------------------------------------------
 		//- Begin push process.
 		//--------------------------------------------------
-		fmt.Fprint(io.UserOutput, "Pushing [")
-		color.New(color.FgBlue).Fprintf(io.UserOutput, fileEntry.Path)
-		fmt.Fprint(io.UserOutput, "]")
-
 		if len(opt.Version) > 0 {
-			fmt.Fprintf(io.UserOutput, "(%s)", opt.Version)
+			p.Println(io.UserOutput, message.PushingVersion, p.Style(message.Path, fileEntry.Path), opt.Version, p.Style(message.Store, remoteComp.store.Name()))
+		} else {
+			p.Println(io.UserOutput, message.Pushing, p.Style(message.Path, fileEntry.Path), p.Style(message.Store, remoteComp.store.Name()))
 		}
 
-		fmt.Fprint(io.UserOutput, " -> [")
-		color.New(color.Bold).Fprintf(io.UserOutput, remoteComp.store.Name())
-		fmt.Fprintln(io.UserOutput, "]")
+		//-------------------------------------------------
+		//- Block pushes to protected files during freezes.
+		//-------------------------------------------------
+		if err := checkFreeze(fileEntry, clog, remoteComp, opt, io); err != nil {
+			display.ErrorText(p.Text(message.PushBlocked, filePath, err), io)
+			results[len(results)-1].Err = err
+			continue
+		}
 
 		//--------------------------------------------------------
 		//- Ensure file has not been modified by another user.
 		//--------------------------------------------------------
-		if lastModified, err := remoteComp.store.Changed(&fileEntry, file, opt.Version); err != nil {
-			display.Error(fmt.Errorf("Failed to determine when '%s' version %s was last modified. (%s)", filePath, opt.Version, err), io.UserOutput)
+		done := measure(remoteComp.store.Name(), "changed")
+		lastModified, err := remoteComp.store.Changed(&fileEntry, file, opt.Version)
+		done(err)
+		if err != nil {
+			display.ErrorText(p.Text(message.PushModifiedFailed, filePath, opt.Version, err), io)
+			results[len(results)-1].Err = err
 			continue
-		} else {
-			if !fileEntry.IsCurrent(lastModified, clog.Context) {
-				if !prompt.Confirm(fmt.Sprintf("Remote file '%s' was modified on %s. Overwrite?", filePath, lastModified.Format(time.RFC822)), prompt.Warn, io) {
-					fmt.Fprintf(io.UserOutput, "Skipping %s\n", filePath)
+		}
+
+		//--------------------------------------------------------
+		//- Stores reporting etags show exactly whether the file
+		//- changed since it was last pulled on this machine.
+		//--------------------------------------------------------
+		conflict, checked := remoteConflict(fileEntry, clog, remoteComp, opt)
+
+		if !opt.Resume && conflict && !opt.Force {
+			err := fmt.Errorf("%s changed in %s since it was last pulled, use 'cstore pull %s --merge' to merge the changes or push with --force to overwrite them", filePath, remoteComp.store.Name(), filePath)
+			display.ErrorText(p.Text(message.PushBlocked, filePath, err), io)
+			results[len(results)-1].Err = err
+			continue
+		}
+
+		if !opt.Resume && (conflict || (!checked && !fileEntry.IsCurrent(lastModified, clog.Context))) {
+			if !conflict && !opt.Force {
+				confirmed, err := prompt.Confirm(p.Text(message.PushOverwrite, filePath, lastModified.Format(time.RFC822)), prompt.Warn, io)
+				if err != nil {
+					display.Error(err, io)
+					results[len(results)-1].Err = err
+					continue
+				}
+
+				if !confirmed {
+					p.Println(io.UserOutput, message.PushSkipping, filePath)
+					continue
+				}
+			}
+
+			if !opt.NoBackup {
+				if err := snapshot(remoteComp, fileEntry, clog, opt.Version, "overwrite", io); err != nil {
+					display.Error(err, io)
+					results[len(results)-1].Err = err
 					continue
 				}
 			}
@@ -123,18 +256,21 @@ func Push(opt cfg.UserOptions, io models.IO) error {
 		//----------------------------------------------------
 		if opt.ModifySecrets {
 			if !fileEntry.SupportsSecrets() {
-				display.Error(fmt.Errorf("Secrets not supported for %s due to incompatible file type %s.", filePath, fileEntry.Type), io.UserOutput)
+				err := errors.New(p.Text(message.SecretsNotSupported, filePath, fileEntry.Type))
+				display.ErrorText(err.Error(), io)
+				results[len(results)-1].Err = err
 				continue
 			}
 
 			tokens, err := token.Find(file, fileEntry.Type, true)
 			if err != nil {
-				display.Error(fmt.Errorf("Failed to find tokens in file %s. (%s)", filePath, err), io.UserOutput)
+				display.ErrorText(p.Text(message.TokensFailed, filePath, err), io)
+				results[len(results)-1].Err = err
 				continue
 			}
 
 			if len(tokens) == 0 {
-				display.Error(fmt.Errorf("To set secrets, tokens in %s must be in the format {{ENV/TOKEN::VALUE}}. Learn about additional limitations at https://github.com/turnerlabs/cstore/blob/master/docs/SECRETS.md.", filePath), io.UserOutput)
+				display.ErrorText(p.Text(message.TokensMissing, filePath), io)
 			}
 
 			for _, t := range tokens {
@@ -150,12 +286,26 @@ func Push(opt cfg.UserOptions, io models.IO) error {
 			}
 		}
 
+		//-------------------------------------------------
+		//- Store renamed keys under their catalog names.
+		//-------------------------------------------------
+		local := file
+
+		file, err = renameKeys(file, fileEntry, true)
+		if err != nil {
+			display.ErrorText(p.Text(message.PushBlocked, filePath, err), io)
+			results[len(results)-1].Err = err
+			continue
+		}
+
 		//-------------------------------------------------
 		//- Validate version and file version data.
 		//-------------------------------------------------
 		if len(opt.Version) > 0 {
 			if !remoteComp.store.SupportsFeature(store.VersionFeature) {
-				display.Error(fmt.Errorf("%s store does not support %s feature.", remoteComp.store.Name(), store.VersionFeature), io.UserOutput)
+				err := errors.New(p.Text(message.VersionUnsupported, remoteComp.store.Name(), store.VersionFeature))
+				display.ErrorText(err.Error(), io)
+				results[len(results)-1].Err = err
 				continue
 			}
 
@@ -165,43 +315,147 @@ func Push(opt cfg.UserOptions, io models.IO) error {
 		}
 
 		//-------------------------------------------------
-		//- Push file to file store.
+		//- Validate the key types, value types, and roles.
 		//-------------------------------------------------
-		if err = remoteComp.store.Push(&fileEntry, file, opt.Version); err != nil {
-			display.Error(err, io.UserOutput)
+		if err := fileEntry.CheckKeyTypes(); err != nil {
+			display.ErrorText(p.Text(message.PushBlocked, filePath, err), io)
+			results[len(results)-1].Err = err
+			continue
+		}
+
+		if err := fileEntry.CheckValueTypes(typedValues(fileEntry, file)); err != nil {
+			display.ErrorText(p.Text(message.PushBlocked, filePath, err), io)
+			results[len(results)-1].Err = err
+			continue
+		}
+
+		if err := fileEntry.CheckRoles(); err != nil {
+			display.ErrorText(p.Text(message.PushBlocked, filePath, err), io)
+			results[len(results)-1].Err = err
 			continue
 		}
 
 		//-------------------------------------------------
-		//- Update the catalog with file entry changes.
+		//- Classify JSON fields that look like secrets.
 		//-------------------------------------------------
-		if err := clog.UpdateEntry(fileEntry); err != nil {
-			display.Error(err, io.UserOutput)
+		if err := classifySuspects(&fileEntry, &clog, remoteComp, file, io); err != nil {
+			display.ErrorText(p.Text(message.PushBlocked, filePath, err), io)
+			results[len(results)-1].Err = err
 			continue
 		}
 
 		//-------------------------------------------------
-		//- Save the time the user last pulled file.
+		//- Block pushes that violate the policy.
 		//-------------------------------------------------
-		if err := clog.RecordPull(fileEntry.Key(), time.Now().Add(time.Second*1)); err != nil {
-			logger.L.Print(err)
+		if err := satisfiesPolicy(pol, fileEntry, remoteComp.store.Name(), opt.Version, file, io); err != nil {
+			results[len(results)-1].Err = err
 			continue
 		}
 
-		//---------------------------------------------------------------------
-		//- Create the ghost .cstore reference file when not in cStore.yml dir.
-		//---------------------------------------------------------------------
-		justThePath := path.RemoveFileName(filePath)
+		//-------------------------------------------------
+		//- Transform values as declared in the catalog.
+		//-------------------------------------------------
+		transformed, err := applyTransforms(file, fileEntry, fileEntry.Transforms.Push)
+		if err != nil {
+			display.ErrorText(p.Text(message.TransformFailed, filePath, err), io)
+			results[len(results)-1].Err = err
+			continue
+		}
+
+		//-------------------------------------------------
+		//- Enforce catalog quotas.
+		//-------------------------------------------------
+		if err := clog.CheckQuotas(fileEntry, transformed); err != nil {
+			display.ErrorText(p.Text(message.PushBlocked, filePath, err), io)
+			results[len(results)-1].Err = err
+			continue
+		}
 
-		if len(clog.GetFullPath(justThePath)) > 0 {
-			if err := catalog.WriteGhost(clog.GetFullPath(justThePath), catalog.Ghost{
-				Location: justThePath,
-			}); err != nil {
+		//-------------------------------------------------
+		//- Run the hooks gating the push.
+		//-------------------------------------------------
+		if len(fileEntry.Hooks.PrePush) > 0 && !opt.NoHooks {
+			if err := runPrePushHooks(fileEntry, clog, remoteComp.store.Name(), transformed, opt, io); err != nil {
+				display.ErrorText(p.Text(message.PushBlocked, filePath, err), io)
+				results[len(results)-1].Err = err
+				continue
+			}
+		}
+
+		sf := stagedFile{
+			path:       filePath,
+			fileEntry:  fileEntry,
+			remoteComp: remoteComp,
+			data:       transformed,
+			local:      local,
+			existed:    !lastModified.IsZero(),
+		}
+
+		//-------------------------------------------------
+		//- Read the remote copy to list the keys changed
+		//- in the JSON results.
+		//-------------------------------------------------
+		if opt.OutputFormat == outputJSON && sf.existed && (fileEntry.SupportsConfig() || fileEntry.Flatten) {
+			done := measure(remoteComp.store.Name(), "pull")
+			previous, _, err := remoteComp.store.Pull(&sf.fileEntry, opt.Version)
+			done(err)
+			if err != nil {
 				logger.L.Print(err)
+			} else {
+				sf.previous = previous
 			}
 		}
 
-		filesPushed = append(filesPushed, fileEntry.Path)
+		//-------------------------------------------------
+		//- Get the keys left by an interrupted push.
+		//-------------------------------------------------
+		if opt.Resume {
+			if sf.resume, err = resumeKeys(sf, clog.Context, opt); err != nil {
+				display.ErrorText(p.Text(message.ResumeFailed, filePath, err), io)
+				results[len(results)-1].Err = err
+				continue
+			}
+		}
+
+		staged = append(staged, sf)
+	}
+
+	//-------------------------------------------------
+	//- Push the staged files.
+	//-------------------------------------------------
+	waves, err := stagedWaves(staged)
+	if err != nil {
+		return results, err
+	}
+
+	if len(opt.ChangeSet) > 0 {
+		staged = []stagedFile{}
+		for _, wave := range waves {
+			staged = append(staged, wave...)
+		}
+
+		if err := commitChangeSet(opt.ChangeSet, staged, fileCount, opt, io); err != nil {
+			for i := range results {
+				if results[i].Err == nil {
+					results[i].Err = err
+				}
+			}
+			return results, err
+		}
+
+		for _, sf := range staged {
+			filesPushed = append(filesPushed, recordPush(sf, &clog, opt, io)...)
+		}
+	} else {
+		filesPushed, failed = pushWaves(waves, filePaths, &clog, opt, io)
+	}
+
+	//-------------------------------------------------
+	//- If user specified, read back the pushed files.
+	//-------------------------------------------------
+	unverified := map[string]error{}
+	if opt.Verify {
+		unverified = verifyPushes(staged, filesPushed, opt, io)
 	}
 
 	//-------------------------------------------------
@@ -211,7 +465,7 @@ func Push(opt cfg.UserOptions, io models.IO) error {
 
 	if !reflect.DeepEqual(original, clog) {
 		if err := catalog.Write(clog.GetFullPath(opt.Catalog), clog); err != nil {
-			return err
+			return results, err
 		}
 	}
 
@@ -225,18 +479,514 @@ func Push(opt cfg.UserOptions, io models.IO) error {
 		}
 	}
 
-	color.New(color.Bold).Fprintf(io.UserOutput, "\n%d of %d file(s) pushed to remote store.\n\n", len(filesPushed), fileCount)
+	//-------------------------------------------------
+	//- Show which files were pushed; errors for the
+	//- rest were displayed when each file was checked
+	//- and are listed for the files that failed to push.
+	//-------------------------------------------------
+	for i := range results {
+		for _, pushed := range filesPushed {
+			if results[i].Path == pushed {
+				results[i].Result = resultPushed
+				if opt.Verify {
+					results[i].Result = resultVerified
+				}
+			}
+		}
+
+		if err, found := failed[results[i].Path]; found {
+			results[i].Err = err
+		}
+
+		if opt.OutputFormat == outputJSON && results[i].Result != resultNotPushed {
+			results[i].Keys = pushedKeys(results[i].Path, staged)
+		}
+
+		if err, found := unverified[results[i].Path]; found {
+			results[i].Result, results[i].Err = resultUnverified, err
+		}
+	}
+
+	printResults(results, io)
+
+	p.Summary(io.UserOutput, message.PushSummary, len(filesPushed), fileCount)
+
+	if len(unverified) > 0 {
+		return results, fmt.Errorf("%d of %d pushed file(s) could not be verified.", len(unverified), len(filesPushed))
+	}
+
+	return results, nil
+}
+
+// remoteConflict compares the etag of the remote file with the etag
+// recorded when it was last pulled or pushed on this machine. The
+// second value is false when the store or the records cannot tell.
+func remoteConflict(fileEntry catalog.File, clog catalog.Catalog, remoteComp remoteComponents, opt cfg.UserOptions) (bool, bool) {
+	conditional, ok := remoteComp.store.(contract.IConditionalStore)
+	if !ok {
+		return false, false
+	}
+
+	recorded, found := clog.PulledETag(fileEntry.Key(), opt.Version)
+	if !found {
+		return false, false
+	}
+
+	done := measure(remoteComp.store.Name(), "etag")
+	etag, err := conditional.ETag(&fileEntry, opt.Version)
+	done(err)
+	if err != nil {
+		logger.L.Print(err)
+		return false, false
+	}
+
+	//-------------------------------------------------
+	//- A removed file has no changes to overwrite.
+	//-------------------------------------------------
+	if len(etag) == 0 {
+		return false, true
+	}
+
+	return etag != recorded, true
+}
+
+// stagedWaves groups the staged files by their dependencies; so, each
+// file is pushed after the files it depends on.
+func stagedWaves(staged []stagedFile) ([][]stagedFile, error) {
+	files := []catalog.File{}
+	byPath := map[string]stagedFile{}
+
+	for _, sf := range staged {
+		files = append(files, sf.fileEntry)
+		byPath[sf.fileEntry.Path] = sf
+	}
+
+	fileWaves, err := catalog.Waves(files)
+	if err != nil {
+		return nil, err
+	}
+
+	waves := [][]stagedFile{}
+	for _, fw := range fileWaves {
+		wave := []stagedFile{}
+		for _, f := range fw {
+			wave = append(wave, byPath[f.Path])
+		}
+		waves = append(waves, wave)
+	}
+
+	return waves, nil
+}
+
+// pushWaves pushes each wave of files, running up to --concurrency
+// pushes at once, and returns the paths recorded and the error of each
+// file not pushed. Files are skipped when a file they depend on was
+// requested but not pushed.
+func pushWaves(waves [][]stagedFile, requested []string, clog *catalog.Catalog, opt cfg.UserOptions, io models.IO) ([]string, map[string]error) {
+	filesPushed := []string{}
+	failed := map[string]error{}
+
+	pending := map[string]bool{}
+	for _, p := range requested {
+		pending[p] = true
+	}
+
+	for _, wave := range waves {
+
+		//-------------------------------------------------
+		//- Skip files whose dependencies did not land.
+		//-------------------------------------------------
+		ready := []stagedFile{}
+		for _, sf := range wave {
+			blocked := ""
+			for _, dep := range sf.fileEntry.DependsOn {
+				if pending[dep] {
+					blocked = dep
+					break
+				}
+			}
+
+			if len(blocked) > 0 {
+				display.ErrorText(io.Messages.Text(message.PushSkipped, sf.path, blocked), io)
+				failed[sf.path] = fmt.Errorf("%s not pushed", blocked)
+				continue
+			}
+
+			ready = append(ready, sf)
+		}
+
+		//-------------------------------------------------
+		//- Push the independent files at once.
+		//-------------------------------------------------
+		errs := pool.Run(concurrency(opt), len(ready), func(i int) error {
+			return pushStaged(&ready[i], opt)
+		})
+
+		//-------------------------------------------------
+		//- Record the pushes one at a time since they
+		//- update the catalog.
+		//-------------------------------------------------
+		for i, sf := range ready {
+			if errs[i] != nil {
+				display.Error(errs[i], io)
+				saveResume(sf, clog.Context, opt, errs[i], io)
+				failed[sf.path] = errs[i]
+				continue
+			}
+
+			delete(pending, sf.path)
+
+			if err := cache.ClearResume(clog.Context, sf.fileEntry.Key(), opt.Version); err != nil {
+				logger.L.Print(err)
+			}
+
+			filesPushed = append(filesPushed, recordPush(sf, clog, opt, io)...)
+		}
+	}
+
+	return filesPushed, failed
+}
+
+// pushStaged refreshes expiring credentials and pushes a staged file.
+func pushStaged(sf *stagedFile, opt cfg.UserOptions) error {
+	if err := store.Refresh(sf.remoteComp.store); err != nil {
+		return fmt.Errorf("Failed to refresh %s credentials. (%s)", sf.remoteComp.store.Name(), err)
+	}
+
+	done := measure(sf.remoteComp.store.Name(), "push")
+	var err error
+	if resumable, ok := sf.remoteComp.store.(contract.IResumableStore); ok && sf.resume != nil {
+		err = resumable.Resume(&sf.fileEntry, sf.data, opt.Version, sf.resume)
+	} else {
+		err = sf.remoteComp.store.Push(&sf.fileEntry, sf.data, opt.Version)
+	}
+	done(err)
+
+	return err
+}
+
+// resumeKeys returns the keys left to push by an interrupted push of
+// the same file contents.
+func resumeKeys(sf stagedFile, context string, opt cfg.UserOptions) ([]string, error) {
+	if _, ok := store.Unwrap(sf.remoteComp.store).(contract.IResumableStore); !ok {
+		return nil, fmt.Errorf("%s store cannot resume pushes", sf.remoteComp.store.Name())
+	}
+
+	record, found := cache.GetResume(context, sf.fileEntry.Key(), opt.Version)
+	if !found {
+		return nil, errors.New("no interrupted push was recorded")
+	}
+
+	if record.Store != sf.remoteComp.store.Name() || record.Checksum != cache.Checksum(sf.data) {
+		return nil, errors.New("the file changed since the push was interrupted, push without --resume")
+	}
+
+	return record.Remaining, nil
+}
+
+// saveResume records the keys left to push when a push to a resumable
+// store fails partway through.
+func saveResume(sf stagedFile, context string, opt cfg.UserOptions, err error, io models.IO) {
+	pushErr, ok := err.(contract.PushError)
+	if !ok {
+		return
+	}
+
+	if err := cache.SaveResume(context, sf.fileEntry.Key(), opt.Version, cache.Resume{
+		Store:     sf.remoteComp.store.Name(),
+		Checksum:  cache.Checksum(sf.data),
+		Remaining: pushErr.Remaining,
+	}); err != nil {
+		logger.L.Print(err)
+		return
+	}
+
+	io.Messages.Println(io.UserOutput, message.ResumeHint, sf.path)
+}
+
+// commitChangeSet pushes every file in the change set or none of them.
+// The remote state of each file is saved before pushing; so, files
+// already pushed can be restored when a later push fails.
+func commitChangeSet(name string, staged []stagedFile, fileCount int, opt cfg.UserOptions, io models.IO) error {
+	if len(staged) != fileCount {
+		return fmt.Errorf("Change set %s was not pushed because %d of %d file(s) failed checks.", name, fileCount-len(staged), fileCount)
+	}
+
+	for i := range staged {
+		if !staged[i].existed {
+			continue
+		}
+
+		done := measure(staged[i].remoteComp.store.Name(), "pull")
+		previous, _, err := staged[i].remoteComp.store.Pull(&staged[i].fileEntry, opt.Version)
+		done(err)
+		if err != nil {
+			return fmt.Errorf("Change set %s was not pushed because the remote state of %s could not be saved for rollback. (%s)", name, staged[i].path, err)
+		}
+
+		staged[i].previous = previous
+	}
+
+	for i := range staged {
+		if err := pushStaged(&staged[i], opt); err != nil {
+			display.ErrorText(io.Messages.Text(message.PushFailed, staged[i].path, err), io)
+
+			// The failed file is rolled back too since stores
+			// saving each key separately may have written part of it.
+			rollback(staged[:i+1], opt, io)
+
+			return fmt.Errorf("Change set %s was rolled back.", name)
+		}
+	}
+
+	fmt.Fprintln(io.UserOutput)
+	io.Messages.Println(io.UserOutput, message.ChangeSetCommitted, io.Messages.Style(message.Path, name))
+
+	return nil
+}
+
+// rollback restores the remote state of files pushed in a failed change
+// set, newest first. Files that did not exist before are purged.
+func rollback(pushed []stagedFile, opt cfg.UserOptions, io models.IO) {
+	for i := len(pushed) - 1; i >= 0; i-- {
+		sf := pushed[i]
+
+		var err error
+		if sf.existed {
+			done := measure(sf.remoteComp.store.Name(), "push")
+			err = sf.remoteComp.store.Push(&sf.fileEntry, sf.previous, opt.Version)
+			done(err)
+		} else {
+			done := measure(sf.remoteComp.store.Name(), "purge")
+			err = sf.remoteComp.store.Purge(&sf.fileEntry, opt.Version)
+			done(err)
+		}
+
+		if err != nil {
+			display.ErrorText(io.Messages.Text(message.RollbackFailed, sf.path, err), io)
+			continue
+		}
+
+		io.Messages.Println(io.UserOutput, message.RolledBack, io.Messages.Style(message.Path, sf.path))
+	}
+}
+
+// recordPush updates the catalog after a file is pushed and returns the
+// path when recorded.
+func recordPush(sf stagedFile, clog *catalog.Catalog, opt cfg.UserOptions, io models.IO) []string {
+
+	//-------------------------------------------------
+	//- Update the catalog with file entry changes.
+	//-------------------------------------------------
+	if err := clog.UpdateEntry(sf.fileEntry); err != nil {
+		display.Error(err, io)
+		return []string{}
+	}
+
+	//-------------------------------------------------
+	//- Save the time the user last pulled file.
+	//-------------------------------------------------
+	if err := clog.RecordPull(sf.fileEntry.Key(), time.Now().Add(time.Second*1)); err != nil {
+		logger.L.Print(err)
+		return []string{}
+	}
+
+	if err := cache.ClearMetadata(clog.Context, sf.fileEntry.Key()); err != nil {
+		logger.L.Print(err)
+	}
+
+	//-------------------------------------------------
+	//- Save the etag of the pushed file; so, the next
+	//- push only conflicts with changes from others.
+	//-------------------------------------------------
+	if conditional, ok := sf.remoteComp.store.(contract.IConditionalStore); ok {
+		done := measure(sf.remoteComp.store.Name(), "etag")
+		etag, err := conditional.ETag(&sf.fileEntry, opt.Version)
+		done(err)
+
+		if err != nil || len(etag) == 0 {
+			logger.L.Print(err)
+		} else if err := clog.RecordETag(sf.fileEntry.Key(), opt.Version, etag, sf.local); err != nil {
+			logger.L.Print(err)
+		}
+	}
+
+	//---------------------------------------------------------------------
+	//- Create the ghost .cstore reference file when not in cStore.yml dir.
+	//---------------------------------------------------------------------
+	justThePath := path.RemoveFileName(sf.path)
+
+	if len(clog.GetFullPath(justThePath)) > 0 {
+		if err := catalog.WriteGhost(clog.GetFullPath(justThePath), catalog.Ghost{
+			Location: justThePath,
+		}); err != nil {
+			logger.L.Print(err)
+		}
+	}
+
+	return []string{sf.fileEntry.Path}
+}
+
+// runPrePushHooks writes the data about to be pushed to a temporary
+// file and runs the pre-push hooks against it.
+func runPrePushHooks(fileEntry catalog.File, clog catalog.Catalog, storeName string, data []byte, opt cfg.UserOptions, io models.IO) error {
+	dir, err := ioutil.TempDir("", "cstore-hook")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, filepath.Base(fileEntry.Path))
+
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		return err
+	}
+
+	return hook.Run(fileEntry.Hooks.PrePush, hook.Event{
+		Hook:    "pre-push",
+		Path:    fileEntry.Path,
+		File:    file,
+		Context: clog.Context,
+		Store:   storeName,
+		Version: opt.Version,
+	}, clog.Location(), io.UserOutput)
+}
+
+// typedValues returns the values of an env file to validate against
+// their value types. Secret tokens are skipped since their values are
+// kept in the secrets vault.
+func typedValues(fileEntry catalog.File, file []byte) map[string]string {
+	values := map[string]string{}
+
+	if !fileEntry.SupportsConfig() {
+		return values
+	}
+
+	for key, value := range gotenv.Parse(bytes.NewReader(file)) {
+		if !strings.Contains(value, "{{") {
+			values[key] = value
+		}
+	}
+
+	return values
+}
+
+// classifySuspects asks the user to classify JSON fields with names that
+// suggest a secret and plaintext values. Classifications are saved as
+// key types; so, fields are only asked about once. Fields classified as
+// secrets block the push until their values are replaced with tokens.
+func classifySuspects(fileEntry *catalog.File, clog *catalog.Catalog, remoteComp remoteComponents, file []byte, io models.IO) error {
+	if strings.ToLower(fileEntry.Type) != "json" || pipeline.Encrypts(fileEntry.Pipeline) {
+		return nil
+	}
+
+	if _, ok := store.Unwrap(remoteComp.store).(contract.IReencryptingStore); ok {
+		return nil
+	}
+
+	suspects, err := token.Suspects(file)
+	if err != nil {
+		return fmt.Errorf("could not check for secrets (%s)", err)
+	}
+
+	secrets := []string{}
+
+	for _, field := range suspects {
+		keyType, declared := fileEntry.DeclaredKeyType(field)
+
+		if !declared {
+			keyType, err = prompt.Select(fmt.Sprintf("Key Type (%s)", field), []string{catalog.KeyTypePlain, catalog.KeyTypeSecret, catalog.KeyTypeReference}, prompt.Options{
+				Description: fmt.Sprintf("%s in %s looks like a secret stored in plaintext. Classify it as plain or reference when it is not sensitive.", field, fileEntry.Path),
+			}, io)
+			if err != nil {
+				return err
+			}
+
+			if fileEntry.KeyTypes == nil {
+				fileEntry.KeyTypes = map[string]string{}
+			}
+			fileEntry.KeyTypes[field] = keyType
+
+			if entry, found := clog.Files[fileEntry.Key()]; found {
+				if entry.KeyTypes == nil {
+					entry.KeyTypes = map[string]string{}
+				}
+				entry.KeyTypes[field] = keyType
+				clog.Files[fileEntry.Key()] = entry
+			}
+		}
+
+		if keyType == catalog.KeyTypeSecret || keyType == catalog.KeyTypeGenerated {
+			secrets = append(secrets, field)
+		}
+	}
+
+	if len(secrets) > 0 {
+		return fmt.Errorf("%s in plaintext, replace the values with tokens like {{ENV/SECRET::VALUE}} and push with -m", strings.Join(secrets, ", "))
+	}
+
+	return nil
+}
+
+// pushedKeys returns the keys a push changed. Keys are only listed when
+// the file is new or its remote copy was read before the push.
+func pushedKeys(filePath string, staged []stagedFile) []diff.KeyChange {
+	for _, sf := range staged {
+		if sf.path == filePath && (!sf.existed || sf.previous != nil) {
+			return changedKeys(sf.fileEntry, sf.previous, sf.data)
+		}
+	}
 
 	return nil
 }
 
+// satisfiesPolicy evaluates the policy for a file and displays any
+// violations preventing the push. An error naming the violated rules is
+// returned when the push is blocked.
+func satisfiesPolicy(pol policy.Policy, fileEntry catalog.File, storeName, version string, file []byte, io models.IO) error {
+	in := policy.NewInput(fileEntry.Path, fileEntry.Type, storeName, version, fileEntry.Tags, file)
+
+	violations, err := pol.Evaluate(in)
+	if err != nil {
+		display.ErrorText(io.Messages.Text(message.PolicyFailed, fileEntry.Path, err), io)
+		return fmt.Errorf("policy could not be evaluated (%s)", err)
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	display.ErrorText(io.Messages.Text(message.PolicyBlocked, fileEntry.Path), io)
+
+	rules := []string{}
+	for _, v := range violations {
+		fmt.Fprintln(io.UserOutput, io.Messages.Text(message.PolicyViolation, io.Messages.Style(message.Failure, v.Rule), v.Message))
+		rules = append(rules, v.Rule)
+	}
+
+	return fmt.Errorf("blocked by policy rule(s) %s", strings.Join(rules, ", "))
+}
+
 func init() {
 	RootCmd.AddCommand(pushCmd)
 
 	pushCmd.Flags().StringVarP(&uo.Store, "store", "s", "", "Set the context store used to store files. The 'stores' command lists options.")
 	pushCmd.Flags().BoolVarP(&uo.DeleteLocalFiles, "delete", "d", false, "Delete the local file after pushing.")
-	pushCmd.Flags().StringVarP(&uo.Tags, "tags", "t", "", "Set a list of tags used to identify the file.")
+	pushCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Set a list of tags used to identify the file. Repeat to set more tags.")
 	pushCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Set a version to identify the file current state.")
 	pushCmd.Flags().StringVarP(&uo.AlternateRestorePath, "alt", "a", "", "Set an alternate path to clone the file to during a restore.")
 	pushCmd.Flags().BoolVarP(&uo.ModifySecrets, "modify-secrets", "m", false, "Store secrets for tokens in file.")
+	pushCmd.Flags().StringVarP(&uo.Owner, "owner", "", "", "Set the person or team responsible for the file.")
+	pushCmd.Flags().BoolVarP(&uo.NoHooks, "no-hooks", "", false, "Skip the pre-push hooks declared in the catalog.")
+	pushCmd.Flags().BoolVarP(&uo.Resume, "resume", "", false, "Push only the keys left by an interrupted push to a key/value store.")
+	pushCmd.Flags().IntVarP(&uo.Concurrency, concurrencyToken, "", 0, "Push up to this many files at once. Files are pushed after the files they depend on. (default 1 or concurrency in the user config)")
+	pushCmd.Flags().StringVarP(&uo.BreakGlass, "break-glass", "", "", "Push protected files during a catalog freeze, reporting the reason to the audit endpoint.")
+	pushCmd.Flags().StringVarP(&uo.ChangeSet, "change-set", "", "", "Push the files in a catalog change set together, rolling back on failure.")
+	pushCmd.Flags().BoolVarP(&uo.NoBackup, "no-backup", "", false, "Overwrite remote changes without saving a local backup.")
+	pushCmd.Flags().BoolVarP(&uo.Force, "force", "", false, "Overwrite remote changes made since the file was last pulled.")
+	pushCmd.Flags().BoolVarP(&uo.Verify, "verify", "", false, "Pull each pushed file back and verify the store returns the pushed contents.")
+	pushCmd.Flags().BoolVarP(&uo.Flatten, "flatten", "", false, "Save json, yaml, and toml files in key/value stores as a key for each value.")
+	pushCmd.Flags().StringP(policyToken, "", "", "Set a policy file that files must satisfy before being pushed.")
+
+	viper.BindPFlag(policyToken, pushCmd.Flags().Lookup(policyToken))
 }
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/diff"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/policy"
+)
+
+// keyStore saves files in memory and fails the first push of a file
+// after writing part of it, like stores saving each key separately.
+type keyStore struct {
+	contract.IStore
+
+	files map[string]string
+	fail  map[string]bool
+}
+
+func (s keyStore) Name() string {
+	return "key-store"
+}
+
+func (s keyStore) Push(file *catalog.File, fileData []byte, version string) error {
+	if s.fail[file.Path] {
+		delete(s.fail, file.Path)
+		s.files[file.Path] = "partial"
+		return contract.PushError{Remaining: []string{"B"}, Err: errors.New("throttled")}
+	}
+
+	s.files[file.Path] = string(fileData)
+	return nil
+}
+
+func (s keyStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+	return []byte(s.files[file.Path]), contract.Attributes{}, nil
+}
+
+func (s keyStore) Purge(file *catalog.File, version string) error {
+	delete(s.files, file.Path)
+	return nil
+}
+
+func TestCommitChangeSetRollsBackPartialPush(t *testing.T) {
+	tests := []struct {
+		existed  bool
+		expected map[string]string
+	}{
+		{true, map[string]string{"api/.env": "A=1\n", "web/.env": "B=1\n"}},
+		{false, map[string]string{"api/.env": "A=1\n"}},
+	}
+
+	for _, test := range tests {
+		// arrange
+		st := keyStore{files: map[string]string{"api/.env": "A=1\n"}, fail: map[string]bool{"web/.env": true}}
+		if test.existed {
+			st.files["web/.env"] = "B=1\n"
+		}
+
+		staged := []stagedFile{
+			{path: "api/.env", fileEntry: catalog.File{Path: "api/.env"}, remoteComp: remoteComponents{store: st}, data: []byte("A=2\n"), existed: true},
+			{path: "web/.env", fileEntry: catalog.File{Path: "web/.env"}, remoteComp: remoteComponents{store: st}, data: []byte("A=2\nB=2\n"), existed: test.existed},
+		}
+
+		// act
+		err := commitChangeSet("release", staged, len(staged), cfg.UserOptions{}, models.IO{UserOutput: ioutil.Discard})
+
+		// assert
+		if err == nil {
+			t.Error("\nEXPECTED: error for failed change set")
+		}
+
+		if len(st.files) != len(test.expected) {
+			t.Errorf("\nEXPECTED: %v \nACTUAL: %v", test.expected, st.files)
+		}
+
+		for path, data := range test.expected {
+			if st.files[path] != data {
+				t.Errorf("\nEXPECTED: %s \nACTUAL: %s", data, st.files[path])
+			}
+		}
+	}
+}
+
+func TestSatisfiesPolicyReturnsViolatedRules(t *testing.T) {
+	// arrange
+	pol := policy.Policy{Rules: []policy.Rule{{Name: "owner-tag", Type: policy.RequiredTags, Tags: []string{"owner"}}}}
+
+	fileEntry := catalog.File{Path: ".env", Type: "env", Tags: []string{"dev"}}
+
+	// act
+	err := satisfiesPolicy(pol, fileEntry, "aws-s3", "", []byte("A=1\n"), models.IO{UserOutput: ioutil.Discard})
+
+	// assert
+	expected := "blocked by policy rule(s) owner-tag"
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
+
+func TestPushedKeys(t *testing.T) {
+	// arrange
+	fileEntry := catalog.File{Path: ".env", Type: "env"}
+
+	staged := []stagedFile{
+		{path: ".env", fileEntry: fileEntry, data: []byte("A=2\nC=1\n"), existed: true, previous: []byte("A=1\nB=1\n")},
+		{path: "new.env", fileEntry: catalog.File{Path: "new.env", Type: "env"}, data: []byte("D=1\n")},
+		{path: "unread.env", fileEntry: catalog.File{Path: "unread.env", Type: "env"}, data: []byte("E=1\n"), existed: true},
+	}
+
+	// act
+	changed := pushedKeys(".env", staged)
+	added := pushedKeys("new.env", staged)
+	unknown := pushedKeys("unread.env", staged)
+
+	// assert
+	if len(changed) != 3 {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %+v", "A changed, B removed, C added", changed)
+	}
+
+	if len(added) != 1 || added[0].Key != "D" || added[0].Change != diff.Added {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %+v", "D added", added)
+	}
+
+	if unknown != nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %+v", "no keys", unknown)
+	}
+}
@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/local"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/metrics"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/store"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	oldKeyEnvVar = "CSTORE_OLD_KEY"
+
+	reencryptAttempts = 5
+	reencryptBackoff  = time.Second
+)
+
+// reencryptCmd represents the reencrypt command
+var reencryptCmd = &cobra.Command{
+	Use:   "reencrypt [file_1] [file_2] ...",
+	Short: "Re-encrypt files after rotating the client-side encryption key.",
+	Long: `Re-encrypt files after rotating the client-side encryption key.
+
+Each file using client-side encryption is decrypted using the old key,
+encrypted using the key now in the access vault, pushed, and verified.
+Progress is saved; so, an interrupted run resumes where it stopped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		if len(uo.Paths) == 0 && uo.TagFilter.IsEmpty() && !uo.All {
+			display.ErrorText(ioStreams.Messages.Text(message.ReencryptUsage), ioStreams)
+			os.Exit(1)
+		}
+
+		if err := Reencrypt(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// reencryptProgress records the files re-encrypted during a key
+// rotation; so, an interrupted run can resume.
+type reencryptProgress struct {
+	Done map[string]time.Time `yaml:"done"`
+}
+
+// Reencrypt ...
+func Reencrypt(opt cfg.UserOptions, io models.IO) error {
+	if err := cfg.Writable("reencrypt"); err != nil {
+		return err
+	}
+
+	reencrypted, failed := 0, 0
+	p := io.Messages
+
+	//-------------------------------------------------
+	//- Get the local catalog listing the files.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	//-------------------------------------------------
+	//- Get the key the files are encrypted with now.
+	//-------------------------------------------------
+	oldKey := os.Getenv(oldKeyEnvVar)
+	if len(oldKey) == 0 {
+		if oldKey, err = prompt.GetValFromUser(oldKeyEnvVar, prompt.Options{
+			Description: p.Text(message.ReencryptOldKey),
+			HideInput:   true,
+		}, io); err != nil {
+			return err
+		}
+	}
+
+	if len(oldKey) == 0 {
+		return fmt.Errorf("The old key is required. Set %s or enter it when prompted.", oldKeyEnvVar)
+	}
+
+	progressName := reencryptProgressName(clog.Context, oldKey)
+
+	progress, err := getReencryptProgress(progressName)
+	if err != nil {
+		return fmt.Errorf("Could not read re-encryption progress. (%s)", err)
+	}
+
+	fmt.Fprintln(io.UserOutput)
+	for _, fileEntry := range clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, "") {
+
+		if fileEntry.IsRef {
+			p.Println(io.UserOutput, message.ReencryptSkipLinked, fileEntry.Path)
+			continue
+		}
+
+		//--------------------------------------------------
+		//- Get the remote store and vault components ready.
+		//--------------------------------------------------
+		remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+		if err != nil {
+			display.ErrorText(p.Text(message.ReencryptFailed, fileEntry.Path, err), io)
+			failed++
+			continue
+		}
+
+		if _, ok := store.Unwrap(remoteComp.store).(contract.IReencryptingStore); !ok {
+			p.Println(io.UserOutput, message.ReencryptSkipStore, fileEntry.Path, remoteComp.store.Name())
+			continue
+		}
+		reencrypter := remoteComp.store.(contract.IReencryptingStore)
+
+		//--------------------------------------------------
+		//- Re-encrypt the working copy and each version.
+		//--------------------------------------------------
+		for _, version := range append([]string{""}, fileEntry.Versions...) {
+			progressKey := fmt.Sprintf("%s|%s", fileEntry.Key(), version)
+
+			if len(version) > 0 {
+				p.Print(io.UserOutput, message.ReencryptingVersion, p.Style(message.Path, fileEntry.Path), version, p.Style(message.Store, remoteComp.store.Name()))
+			} else {
+				p.Print(io.UserOutput, message.Reencrypting, p.Style(message.Path, fileEntry.Path), p.Style(message.Store, remoteComp.store.Name()))
+			}
+
+			if _, done := progress.Done[progressKey]; done {
+				p.Println(io.UserOutput, message.ReencryptAlreadyDone)
+				reencrypted++
+				continue
+			}
+
+			if err := reencryptFile(reencrypter, remoteComp, &fileEntry, oldKey, version); err != nil {
+				fmt.Fprintln(io.UserOutput)
+				display.ErrorText(p.Text(message.ReencryptFailed, fileEntry.Path, err), io)
+				failed++
+				continue
+			}
+
+			p.Println(io.UserOutput, message.Done)
+			reencrypted++
+
+			//-------------------------------------------------
+			//- Save progress and the pushed file right away.
+			//-------------------------------------------------
+			progress.Done[progressKey] = time.Now()
+
+			if err := saveReencryptProgress(progressName, progress); err != nil {
+				display.ErrorText(p.Text(message.ReencryptProgressFailed, err), io)
+			}
+
+			if err := clog.UpdateEntry(fileEntry); err != nil {
+				return err
+			}
+
+			if err := catalog.Write(clog.GetFullPath(opt.Catalog), clog); err != nil {
+				return err
+			}
+		}
+	}
+
+	//-------------------------------------------------
+	//- Forget progress once every file is finished.
+	//-------------------------------------------------
+	if failed == 0 {
+		os.Remove(local.BuildPath(progressName))
+	}
+
+	p.Summary(io.UserOutput, message.ReencryptSummary, reencrypted, reencrypted+failed)
+
+	if failed > 0 {
+		return errors.New("Run the command again to retry the files that failed.")
+	}
+
+	return nil
+}
+
+// reencryptFile re-encrypts a file, backing off when the store rate
+// limits requests, and verifies the file can be pulled using the
+// current key.
+func reencryptFile(reencrypter contract.IReencryptingStore, remoteComp remoteComponents, fileEntry *catalog.File, oldKey, version string) error {
+	var data []byte
+
+	err := withBackoff(remoteComp.store.Name(), func() error {
+		if err := store.Refresh(remoteComp.store); err != nil {
+			return err
+		}
+
+		done := measure(remoteComp.store.Name(), "reencrypt")
+		b, err := reencrypter.Reencrypt(fileEntry, oldKey, version)
+		done(err)
+
+		data = b
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	//-------------------------------------------------
+	//- Verify the file decrypts using the current key.
+	//-------------------------------------------------
+	var pulled []byte
+
+	err = withBackoff(remoteComp.store.Name(), func() error {
+		done := measure(remoteComp.store.Name(), "pull")
+		b, _, err := remoteComp.store.Pull(fileEntry, version)
+		done(err)
+
+		pulled = b
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("verification failed (%s)", err)
+	}
+
+	if !bytes.Equal(data, pulled) {
+		return errors.New("verification failed, pulled file does not match the re-encrypted file")
+	}
+
+	return nil
+}
+
+// withBackoff retries a store call rate limited by the store, doubling
+// the wait between attempts.
+func withBackoff(storeName string, call func() error) error {
+	wait := reencryptBackoff
+
+	var err error
+	for attempt := 1; attempt <= reencryptAttempts; attempt++ {
+		if err = call(); err == nil || !rateLimited(err) {
+			return err
+		}
+
+		metrics.Throttled(storeName)
+
+		if attempt < reencryptAttempts {
+			metrics.Retried(storeName, 1)
+			time.Sleep(wait)
+			wait *= 2
+		}
+	}
+
+	return err
+}
+
+// rateLimited reports whether a store error indicates too many requests.
+func rateLimited(err error) bool {
+	msg := strings.ToLower(err.Error())
+
+	for _, s := range []string{"429", "too many requests", "toomanyrequests", "rate limit", "throttl"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func reencryptProgressName(context, oldKey string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s", context, oldKey)))
+	return fmt.Sprintf("reencrypt/%s.yml", hex.EncodeToString(sum[:]))
+}
+
+func getReencryptProgress(name string) (reencryptProgress, error) {
+	progress := reencryptProgress{Done: map[string]time.Time{}}
+
+	if local.Missing(name) {
+		return progress, nil
+	}
+
+	b, err := local.Get(name, "")
+	if err != nil {
+		return progress, err
+	}
+
+	if err := yaml.Unmarshal(b, &progress); err != nil {
+		return progress, err
+	}
+
+	if progress.Done == nil {
+		progress.Done = map[string]time.Time{}
+	}
+
+	return progress, nil
+}
+
+func saveReencryptProgress(name string, progress reencryptProgress) error {
+	b, err := yaml.Marshal(progress)
+	if err != nil {
+		return err
+	}
+
+	return local.Update(name, "", b)
+}
+
+func init() {
+	RootCmd.AddCommand(reencryptCmd)
+
+	reencryptCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	reencryptCmd.Flags().BoolVarP(&uo.All, "all", "", false, "Re-encrypt every cataloged file using client-side encryption.")
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/logger"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+// rehashCmd represents the rehash command
+var rehashCmd = &cobra.Command{
+	Use:   "rehash",
+	Short: "Change the hash used to build file keys in the catalog.",
+	Long: `Change the hash used to build file keys in the catalog.
+
+Each file is keyed using the new hash. Files in stores using the key
+remotely, like OCI registries, are pulled and pushed again under the
+new key. The catalog is saved after each file; so, an interrupted run
+resumes where it stopped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions([]string{})
+
+		if !catalog.IsHash(uo.Hash) {
+			display.ErrorText(fmt.Sprintf("Unknown hash %s. Use %s.", uo.Hash, strings.Join(catalog.Hashes, " or ")), ioStreams)
+			os.Exit(1)
+		}
+
+		if err := Rehash(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Rehash ...
+func Rehash(opt cfg.UserOptions, io models.IO) error {
+	if err := cfg.Writable("rehash"); err != nil {
+		return err
+	}
+
+	rehashed, failed := 0, 0
+	p := io.Messages
+
+	//-------------------------------------------------
+	//- Get the local catalog listing the files.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	catalogPath := clog.GetFullPath(opt.Catalog)
+
+	//-------------------------------------------------
+	//- Save the new hash first; so, files not yet
+	//- rehashed are read using their legacy keys.
+	//-------------------------------------------------
+	clog.Hash = opt.Hash
+
+	if err := catalog.Write(catalogPath, clog); err != nil {
+		return err
+	}
+
+	keys := []string{}
+	for key := range clog.Files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintln(io.UserOutput)
+
+	for _, key := range keys {
+		fileEntry := clog.Files[key]
+
+		if fileEntry.HashAlgorithm() == opt.Hash {
+			continue
+		}
+
+		rehashedEntry, pushed, err := rehashFile(fileEntry, clog, opt, io)
+		if err != nil {
+			failed++
+			display.Error(fmt.Errorf("Failed to rehash %s. (%s)", fileEntry.Path, err), io)
+			continue
+		}
+
+		delete(clog.Files, key)
+		clog.Files[rehashedEntry.Key()] = rehashedEntry
+
+		if err := catalog.Write(catalogPath, clog); err != nil {
+			return err
+		}
+
+		if err := clog.MoveRecords(key, rehashedEntry.Key()); err != nil {
+			logger.L.Print(err)
+		}
+
+		if pushed {
+			if err := clog.RecordPull(rehashedEntry.Key(), time.Now().Add(time.Second*1)); err != nil {
+				logger.L.Print(err)
+			}
+		}
+
+		rehashed++
+
+		p.Println(io.UserOutput, message.Rehashed, p.Style(message.Path, fileEntry.Path))
+	}
+
+	summary := p.Style(message.Summary, p.Text(message.RehashSummary, rehashed, opt.Hash))
+
+	if failed > 0 {
+		summary += p.Style(message.Failure, p.Text(message.RehashFailed, failed))
+	}
+
+	fmt.Fprintf(io.UserOutput, "\n%s\n\n", summary)
+
+	if failed > 0 {
+		return fmt.Errorf("%d file(s) could not be rehashed", failed)
+	}
+
+	return nil
+}
+
+// rehashFile returns the file entry keyed using the new hash. Files in
+// stores using the key remotely are pulled, pushed under the new key,
+// and then purged from the legacy key.
+func rehashFile(fileEntry catalog.File, clog catalog.Catalog, opt cfg.UserOptions, io models.IO) (catalog.File, bool, error) {
+	rehashedEntry := fileEntry.HashedWith(opt.Hash)
+
+	if fileEntry.IsRef {
+		return rehashedEntry, false, nil
+	}
+
+	//--------------------------------------------------
+	//- Get the remote store and vault components ready.
+	//--------------------------------------------------
+	remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+	if err != nil {
+		return rehashedEntry, false, err
+	}
+
+	if !remoteComp.store.SupportsFeature(store.HashedKeyFeature) {
+		return rehashedEntry, false, nil
+	}
+
+	//-------------------------------------------------
+	//- Copy the store data; so, purging the legacy key
+	//- does not remove data saved by the new push.
+	//-------------------------------------------------
+	rehashedEntry.Data = map[string]string{}
+	for k, v := range fileEntry.Data {
+		rehashedEntry.Data[k] = v
+	}
+
+	versions := append([]string{""}, fileEntry.Versions...)
+
+	for _, version := range versions {
+		if err := store.Refresh(remoteComp.store); err != nil {
+			return rehashedEntry, false, fmt.Errorf("Failed to refresh %s credentials. (%s)", remoteComp.store.Name(), err)
+		}
+
+		done := measure(remoteComp.store.Name(), "pull")
+		data, _, err := remoteComp.store.Pull(&fileEntry, version)
+		done(err)
+		if err != nil {
+			return rehashedEntry, false, err
+		}
+
+		done = measure(remoteComp.store.Name(), "push")
+		err = remoteComp.store.Push(&rehashedEntry, data, version)
+		done(err)
+		if err != nil {
+			return rehashedEntry, false, err
+		}
+
+		done = measure(remoteComp.store.Name(), "purge")
+		err = remoteComp.store.Purge(&fileEntry, version)
+		done(err)
+		if err != nil {
+			display.Warn(fmt.Sprintf("%s was pushed under the new key, but the legacy copy could not be purged. (%s)", fileEntry.Path, err), io)
+		}
+	}
+
+	return rehashedEntry, true, nil
+}
+
+func init() {
+	RootCmd.AddCommand(rehashCmd)
+
+	rehashCmd.Flags().StringVarP(&uo.Hash, "hash", "", catalog.HashSHA256, "Specify the hash used to build file keys.")
+}
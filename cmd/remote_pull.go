@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/remote"
+)
+
+// remotePullCmd represents the remote-pull command
+var remotePullCmd = &cobra.Command{
+	Use:   "remote-pull {file_1} {file_2} ...",
+	Short: "Retrieve file(s) and write them to a remote host over SSH.",
+	Long: `Retrieve file(s) and write them to a remote host over SSH.
+
+Files are pulled locally and written to the remote host without being
+saved to the local disk. The ssh client and its configuration are used
+to connect.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setupUserOptions(args)
+
+		if count, total, err := RemotePull(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		} else {
+			ioStreams.Messages.Summary(ioStreams.UserOutput, message.RemotePullSummary, count, total, uo.RemoteHost)
+		}
+	},
+}
+
+// RemotePull ...
+func RemotePull(opt cfg.UserOptions, io models.IO) (int, int, error) {
+	written, fileCount := 0, 0
+
+	if len(opt.RemoteHost) == 0 {
+		return 0, 0, errors.New("Specify the remote host with --host user@server.")
+	}
+
+	mode, err := strconv.ParseUint(opt.RemoteMode, 8, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid file mode %s. (example: 0600)", opt.RemoteMode)
+	}
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	files := clog.FilesMatching(opt.GetPaths(clog.CWD), opt.TagFilter, opt.Version)
+	if len(files) == 0 {
+		return 0, 0, fmt.Errorf("%s is not aware of requested files. Use 'list' command to view available files.", opt.Catalog)
+	}
+
+	//-------------------------------------------------
+	//- Always retrieve the remote copy.
+	//-------------------------------------------------
+	opt.Force = true
+
+	p := io.Messages
+
+	fmt.Fprintln(io.UserOutput)
+	for _, fileEntry := range files {
+
+		if fileEntry.IsRef {
+			p.Println(io.UserOutput, message.RemotePullSkipLinked, fileEntry.Path)
+			continue
+		}
+
+		fileCount++
+
+		fileEntry = overrideFileSettings(fileEntry, opt)
+
+		//----------------------------------------------------
+		//- Get the remote store and vaults components ready.
+		//----------------------------------------------------
+		fileEntryTemp := fileEntry
+		remoteComp, err := getRemoteComponents(&fileEntryTemp, clog, opt, io)
+		if err != nil {
+			display.ErrorText(p.Text(message.RetrieveFailed, fileEntry.Path, err), io)
+			continue
+		}
+
+		file, _, _, err := retrieve(fileEntry, clog, remoteComp, clog.GetFullPath(fileEntry.Path), opt, io)
+		if err != nil {
+			display.ErrorText(p.Text(message.RetrieveFailed, fileEntry.Path, err), io)
+			continue
+		}
+
+		file, err = applyTransforms(file, fileEntry, fileEntry.Transforms.Pull)
+		if err != nil {
+			display.ErrorText(p.Text(message.TransformFailed, fileEntry.Path, err), io)
+			continue
+		}
+
+		file, err = renderTemplate(file, fileEntry, clog, remoteComp, opt, io)
+		if err != nil {
+			display.ErrorText(p.Text(message.RenderFailed, fileEntry.Path, err), io)
+			continue
+		}
+
+		file, err = renameKeys(file, fileEntry, false)
+		if err != nil {
+			display.ErrorText(p.Text(message.RenameFailed, fileEntry.Path, err), io)
+			continue
+		}
+
+		//-------------------------------------------------
+		//- If user specifies, inject secrets into file.
+		//-------------------------------------------------
+		if opt.InjectSecrets {
+			if !fileEntry.SupportsSecrets() {
+				display.ErrorText(p.Text(message.SecretsNotSupported, fileEntry.Path, fileEntry.Type), io)
+				continue
+			}
+
+			file = injectSecrets(file, fileEntry, fileEntry.Path, clog, remoteComp, io)
+		}
+
+		//-------------------------------------------------
+		//- Write the file to the remote host.
+		//-------------------------------------------------
+		remotePath := path.Join(opt.RemoteDir, fileEntry.Path)
+
+		if err := remote.Write(opt.RemoteHost, remotePath, os.FileMode(mode), file); err != nil {
+			display.ErrorText(p.Text(message.RemotePullWriteFailed, fileEntry.Path, opt.RemoteHost, err), io)
+			continue
+		}
+
+		p.Println(io.UserOutput, message.RemotePullWritten, p.Style(message.Path, fileEntry.Path), p.Style(message.Store, remoteComp.store.Name()), opt.RemoteHost+":"+remotePath)
+
+		written++
+	}
+
+	return written, fileCount, nil
+}
+
+func init() {
+	RootCmd.AddCommand(remotePullCmd)
+
+	remotePullCmd.Flags().StringVarP(&uo.RemoteHost, "host", "", "", "Set the remote host to write files to, like user@server.")
+	remotePullCmd.Flags().StringVarP(&uo.RemoteDir, "remote-dir", "", ".", "Set the remote directory files are written to using their catalog paths.")
+	remotePullCmd.Flags().StringVarP(&uo.RemoteMode, "mode", "", "0600", "Set the permissions of the remote files.")
+	remotePullCmd.Flags().VarP(cfg.NewTagsValue(&uo.Tags), "tags", "t", "Specify a list of tags used to filter files. Repeat to require every tag.")
+	remotePullCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Set a version to identify a file specific state.")
+	remotePullCmd.Flags().BoolVarP(&uo.InjectSecrets, "inject-secrets", "i", false, "Write the configuration including secrets.")
+	remotePullCmd.Flags().StringVarP(&uo.Justification, "justification", "", "", "Explain why access is needed when pulling protected files.")
+}
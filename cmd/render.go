@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/policy"
+	"github.com/turnerlabs/cstore/components/render"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+// renderTemplate resolves the placeholders in files stored as templates.
+// Secrets come from the file's secrets vault and store values are looked
+// up using the file's access vault.
+func renderTemplate(file []byte, fileEntry catalog.File, clog catalog.Catalog, remoteComp remoteComponents, opt cfg.UserOptions, io models.IO) ([]byte, error) {
+	if !fileEntry.Render {
+		return file, nil
+	}
+
+	pol, err := policy.Load(opt.Policy, repoPolicy(clog))
+	if err != nil {
+		return file, fmt.Errorf("Could not load policy! (%s)", err)
+	}
+
+	ready := map[string]contract.IValueStore{}
+
+	sources := render.Sources{
+		Env: os.LookupEnv,
+		Secret: func(group, prop string) (string, error) {
+			return remoteComp.secrets.Get(clog.Context, group, prop)
+		},
+		Store: func(name, key string) (string, error) {
+			values, found := ready[name]
+			if !found {
+				var err error
+				if values, err = valueStore(name, fileEntry, clog, remoteComp, pol, opt, io); err != nil {
+					return "", err
+				}
+				ready[name] = values
+			}
+
+			done := measure(name, "value")
+			value, err := values.Value(key)
+			done(err)
+
+			return value, err
+		},
+	}
+
+	//-------------------------------------------------
+	//- Offline copies have no vaults to resolve from.
+	//-------------------------------------------------
+	if remoteComp.store == nil {
+		sources.Secret, sources.Store = nil, nil
+	}
+
+	return render.Render(file, sources)
+}
+
+// valueStore gets a store ready to look up values using the vaults
+// and settings of the file being rendered.
+func valueStore(name string, fileEntry catalog.File, clog catalog.Catalog, remoteComp remoteComponents, pol policy.Policy, opt cfg.UserOptions, io models.IO) (contract.IValueStore, error) {
+	if err := pol.AllowsStore(name); err != nil {
+		return nil, err
+	}
+
+	lookupEntry := fileEntry
+	lookupEntry.Store = name
+	lookupEntry.Data = map[string]string{}
+	for k, v := range fileEntry.Data {
+		lookupEntry.Data[k] = v
+	}
+
+	st, err := store.Select(&lookupEntry, clog, remoteComp.access, opt, io)
+	if err != nil {
+		return nil, fmt.Errorf("%s store could not be used (%s)", name, err)
+	}
+
+	values, ok := st.(contract.IValueStore)
+	if !ok {
+		return nil, fmt.Errorf("%s store cannot look up values by name", name)
+	}
+
+	if err := store.Refresh(st); err != nil {
+		return nil, fmt.Errorf("Failed to refresh %s credentials. (%s)", name, err)
+	}
+
+	return values, nil
+}
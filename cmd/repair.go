@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/env"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/token"
+)
+
+// repairCmd represents the repair command
+var repairCmd = &cobra.Command{
+	Use:   "repair {file}",
+	Short: "Find and fix differences between a file's catalog entry, local copy, and store.",
+	Long: `Find and fix differences between a file's catalog entry, local copy, and store.
+
+Checks for catalog settings naming keys that are no longer in the file,
+unknown key and value types, secret tokens without a value in the
+secrets vault, keys missing from the store or the local file, and
+files missing on either side. Each fix is confirmed before it is made;
+use -y to make every fix.
+
+	$ cstore repair app.env`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			display.ErrorText(ioStreams.Messages.Text(message.RepairUsage), ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions(args)
+
+		found, fixed, err := Repair(uo, ioStreams)
+		if err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+
+		ioStreams.Messages.Summary(ioStreams.UserOutput, message.RepairSummary, fixed, found)
+
+		if fixed < found {
+			os.Exit(1)
+		}
+	},
+}
+
+// repairIssue is an inconsistency found for a file and the fix offered
+// for it.
+type repairIssue struct {
+	Problem string
+	Fix     string
+	apply   func() error
+}
+
+// Repair checks a file's catalog entry, local copy, and remote copy
+// against each other and makes the fixes the user confirms. The number
+// of issues found and fixed is returned.
+func Repair(opt cfg.UserOptions, io models.IO) (int, int, error) {
+	if err := cfg.Writable("repair"); err != nil {
+		return 0, 0, err
+	}
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	paths := opt.GetPaths(clog.CWD)
+
+	fileEntry, found := catalog.File{}, false
+	for _, f := range clog.Files {
+		if len(paths) > 0 && f.Path == paths[0] && !f.IsRef {
+			fileEntry, found = f, true
+		}
+	}
+
+	if !found {
+		return 0, 0, fmt.Errorf("%s is not aware of %s. Use 'list' command to view available files.", opt.Catalog, strings.Join(paths, ""))
+	}
+
+	fullPath := clog.GetFullPath(fileEntry.Path)
+
+	//----------------------------------------------------
+	//- Get the remote store and vaults components ready.
+	//- Store settings missing from the catalog entry are
+	//- asked for here.
+	//----------------------------------------------------
+	remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	//----------------------------------------------------
+	//- Read both copies of the file.
+	//----------------------------------------------------
+	local, localErr := []byte{}, error(nil)
+	if _, err := os.Stat(fullPath); err == nil {
+		local, localErr = localFile.GetBy(fullPath)
+	} else {
+		localErr = err
+	}
+
+	done := measure(remoteComp.store.Name(), "pull")
+	remote, _, remoteErr := remoteComp.store.Pull(&fileEntry, opt.Version)
+	done(remoteErr)
+
+	if localErr != nil && remoteErr != nil {
+		return 0, 0, fmt.Errorf("%s is missing locally and could not be pulled from %s. (%s)", fileEntry.Path, remoteComp.store.Name(), remoteErr)
+	}
+
+	issues := []repairIssue{}
+	p := io.Messages
+
+	// pushes counts the confirmed fixes made by pushing the file.
+	pushes := 0
+
+	saveEntry := func() error {
+		if err := clog.UpdateEntry(fileEntry); err != nil {
+			return err
+		}
+
+		return catalog.Write(clog.GetFullPath(opt.Catalog), clog)
+	}
+
+	//----------------------------------------------------
+	//- Check the catalog entry. Stale keys are removed
+	//- from every section; so, their types are not also
+	//- checked.
+	//----------------------------------------------------
+	stale := map[string]bool{}
+
+	if fileEntry.SupportsConfig() {
+		keys := map[string]bool{}
+
+		for _, file := range [][]byte{local, remote} {
+			for key := range gotenv.Parse(bytes.NewReader(file)) {
+				keys[key] = true
+			}
+		}
+
+		refs := fileEntry.KeyReferences()
+
+		names := []string{}
+		for key := range refs {
+			if !keys[key] {
+				names = append(names, key)
+				stale[key] = true
+			}
+		}
+		sort.Strings(names)
+
+		for _, key := range names {
+			key := key
+
+			issues = append(issues, repairIssue{
+				Problem: p.Text(message.RepairStaleKey, key, strings.Join(refs[key], ", ")),
+				Fix:     p.Text(message.RepairRemoveKey, key),
+				apply: func() error {
+					fileEntry.RemoveKeyReferences(key)
+					return saveEntry()
+				},
+			})
+		}
+	}
+
+	for _, check := range []struct {
+		section string
+		types   map[string]string
+		valid   func(string) bool
+	}{
+		{"keyTypes", fileEntry.KeyTypes, catalog.IsKeyType},
+		{"valueTypes", fileEntry.ValueTypes, catalog.IsValueType},
+	} {
+		check := check
+
+		names := []string{}
+		for key, t := range check.types {
+			if !stale[key] && !check.valid(strings.ToLower(t)) {
+				names = append(names, key)
+			}
+		}
+		sort.Strings(names)
+
+		for _, key := range names {
+			key := key
+
+			issues = append(issues, repairIssue{
+				Problem: p.Text(message.RepairUnknownType, key, check.types[key], check.section),
+				Fix:     p.Text(message.RepairRemoveType, key, check.section),
+				apply: func() error {
+					delete(check.types, key)
+					return saveEntry()
+				},
+			})
+		}
+	}
+
+	//----------------------------------------------------
+	//- Check the secret tokens have values.
+	//----------------------------------------------------
+	if localErr == nil && fileEntry.SupportsSecrets() {
+		tokens, err := token.Find(local, fileEntry.Type, false)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		names := []string{}
+		for name := range tokens {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			t := tokens[name]
+
+			if _, err := remoteComp.secrets.Get(clog.Context, t.Secret(), t.Prop); err == nil {
+				continue
+			}
+
+			issues = append(issues, repairIssue{
+				Problem: p.Text(message.RepairMissingSecret, t.Formatted(), remoteComp.secrets.Name(), strings.ToUpper(t.EnvVar)),
+				Fix:     p.Text(message.RepairEnterSecret, t.Secret(), t.Prop),
+				apply: func() error {
+					value, err := prompt.GetValFromUser(fmt.Sprintf("%s/%s", t.Secret(), t.Prop), prompt.Options{
+						Description: p.Text(message.RepairSecretValue, strings.ToUpper(t.EnvVar), fileEntry.Path),
+						HideInput:   true,
+					}, io)
+					if err != nil {
+						return err
+					}
+
+					return remoteComp.secrets.Set(clog.Context, t.Secret(), t.Prop, value)
+				},
+			})
+		}
+	}
+
+	//----------------------------------------------------
+	//- Compare the local and remote copies.
+	//----------------------------------------------------
+	switch {
+	case localErr != nil:
+		issues = append(issues, repairIssue{
+			Problem: p.Text(message.RepairMissingLocally, fileEntry.Path),
+			Fix:     p.Text(message.RepairRestoreLocal, fileEntry.Path, remoteComp.store.Name()),
+			apply: func() error {
+				return localFile.Save(fullPath, remote)
+			},
+		})
+
+	case remoteErr != nil:
+		issues = append(issues, repairIssue{
+			Problem: p.Text(message.RepairMissingRemotely, fileEntry.Path, remoteComp.store.Name(), remoteErr),
+			Fix:     p.Text(message.RepairPushLocal, fileEntry.Path),
+			apply: func() error {
+				pushes++
+				return nil
+			},
+		})
+
+	case fileEntry.SupportsConfig():
+		localValues := gotenv.Parse(bytes.NewReader(local))
+		remoteValues := gotenv.Parse(bytes.NewReader(remote))
+
+		remoteOnly := missingKeys(remoteValues, localValues)
+		if len(remoteOnly) > 0 {
+			issues = append(issues, repairIssue{
+				Problem: p.Text(message.RepairRemoteOnly, strings.Join(remoteOnly, ", "), remoteComp.store.Name()),
+				Fix:     p.Text(message.RepairAddKeys, fileEntry.Path),
+				apply: func() error {
+					return appendKeys(fullPath, remoteOnly, remoteValues)
+				},
+			})
+		}
+
+		localOnly := missingKeys(localValues, remoteValues)
+		if len(localOnly) > 0 {
+			issues = append(issues, repairIssue{
+				Problem: p.Text(message.RepairLocalOnly, strings.Join(localOnly, ", "), remoteComp.store.Name()),
+				Fix:     p.Text(message.RepairPushLocal, fileEntry.Path),
+				apply: func() error {
+					pushes++
+					return nil
+				},
+			})
+		}
+	}
+
+	//----------------------------------------------------
+	//- Confirm and make each fix.
+	//----------------------------------------------------
+	fmt.Fprintln(io.UserOutput)
+
+	if len(issues) == 0 {
+		p.Println(io.UserOutput, message.RepairNoIssues, p.Style(message.Path, fileEntry.Path))
+
+		return 0, 0, nil
+	}
+
+	fixed := 0
+
+	for _, issue := range issues {
+		display.Warn(issue.Problem, io)
+
+		confirmed, err := prompt.Confirm(issue.Fix, prompt.Warn, io)
+		if err != nil {
+			return len(issues), fixed, err
+		}
+
+		if !confirmed {
+			continue
+		}
+
+		if err := issue.apply(); err != nil {
+			display.ErrorText(p.Text(message.RepairFixFailed, err), io)
+			continue
+		}
+
+		fixed++
+	}
+
+	//----------------------------------------------------
+	//- Push last; so, the push includes the other fixes.
+	//----------------------------------------------------
+	if pushes > 0 {
+		pushOpt := opt
+		pushOpt.AddPaths([]string{fileEntry.Path})
+
+		if err := Push(pushOpt, io); err != nil {
+			display.ErrorText(p.Text(message.RepairPushFailed, err), io)
+			fixed -= pushes
+		}
+	}
+
+	return len(issues), fixed, nil
+}
+
+// missingKeys returns the keys in values missing from other.
+func missingKeys(values, other gotenv.Env) []string {
+	keys := []string{}
+
+	for key := range values {
+		if _, found := other[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// appendKeys adds the keys with their values to the end of a local env
+// file.
+func appendKeys(fullPath string, keys []string, values gotenv.Env) error {
+	file, err := localFile.GetBy(fullPath)
+	if err != nil {
+		return err
+	}
+
+	added := gotenv.Env{}
+	for _, key := range keys {
+		added[key] = values[key]
+	}
+
+	buf := bytes.NewBuffer(file)
+	if len(file) > 0 && !bytes.HasSuffix(file, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	buf.Write(env.Format(added))
+
+	return localFile.Save(fullPath, buf.Bytes())
+}
+
+func init() {
+	RootCmd.AddCommand(repairCmd)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore {file}",
+	Short: "Promote an earlier revision of a file to the latest.",
+	Long: `Promote an earlier revision of a file to the latest.
+
+The revision is pulled from the store and saved as the latest state;
+so, pulls retrieve it and the replaced state remains a revision. Use
+--secret to make an earlier revision of a secret in the secrets vault
+current instead. The local file is not changed.
+
+	$ cstore restore .env --revision 3HL4kqtJlcpXroDTDmJ
+	$ cstore restore .env --secret dev/DB --revision a1b2c3d4`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			display.ErrorText(ioStreams.Messages.Text(message.SpecifyFile, "cstore restore {file} --revision {revision}"), ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions(args)
+
+		if err := Restore(uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Restore saves an earlier revision of a file or secret as the latest.
+func Restore(opt cfg.UserOptions, io models.IO) error {
+	if len(opt.Revision) == 0 {
+		return errors.New("--revision is required to restore. Use 'versions' command to view available revisions.")
+	}
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	fileEntry, remoteComp, err := getRevisionComponents(clog, opt, io)
+	if err != nil {
+		return err
+	}
+
+	if err := checkFreeze(fileEntry, clog, remoteComp, opt, io); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(io.UserOutput)
+
+	//-------------------------------------------------
+	//- Make the secret revision current.
+	//-------------------------------------------------
+	if len(opt.Secret) > 0 {
+		vault, ok := remoteComp.secrets.(contract.IRevisionVault)
+		if !ok {
+			return fmt.Errorf("%s vault does not keep secret revisions", remoteComp.secrets.Name())
+		}
+
+		if err := vault.Restore(clog.Context, opt.Secret, opt.Revision); err != nil {
+			return fmt.Errorf("Failed to restore %s. (%s)", opt.Secret, err)
+		}
+
+		printRestored(opt.Secret, opt.Revision, remoteComp.secrets.Name(), io)
+
+		return nil
+	}
+
+	//-------------------------------------------------
+	//- Save the file revision as the latest state.
+	//-------------------------------------------------
+	revisioned, ok := remoteComp.store.(contract.IRevisionStore)
+	if !ok {
+		return fmt.Errorf("%s store does not keep file revisions", remoteComp.store.Name())
+	}
+
+	if err := justify("restore", fileEntry, clog, remoteComp, opt); err != nil {
+		return err
+	}
+
+	done := measure(remoteComp.store.Name(), "pull")
+	file, _, err := revisioned.PullRevision(&fileEntry, opt.Version, opt.Revision)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("Could not retrieve revision %s of %s! (%s)", opt.Revision, fileEntry.Path, err)
+	}
+
+	done = measure(remoteComp.store.Name(), "push")
+	err = remoteComp.store.Push(&fileEntry, file, opt.Version)
+	done(err)
+	if err != nil {
+		return fmt.Errorf("Failed to restore %s! (%s)", fileEntry.Path, err)
+	}
+
+	printRestored(fileEntry.Path, opt.Revision, remoteComp.store.Name(), io)
+
+	if len(fileEntry.Pinned) > 0 && len(opt.Version) == 0 {
+		display.Warn(io.Messages.Text(message.RestorePinned, fileEntry.Path, fileEntry.Pinned), io)
+	}
+
+	return nil
+}
+
+func printRestored(name, revision, source string, io models.IO) {
+	p := io.Messages
+
+	p.Println(io.UserOutput, message.Restored, p.Style(message.Path, name), revision, p.Style(message.Store, source))
+	fmt.Fprintln(io.UserOutput)
+}
+
+func init() {
+	RootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVarP(&uo.Revision, "revision", "", "", "Set the revision to restore. Use 'versions' command to view available revisions.")
+	restoreCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Restore a revision of a specific version of the file.")
+	restoreCmd.Flags().StringVarP(&uo.Secret, "secret", "", "", "Restore a revision of a secret, like dev/DB, in the secrets vault.")
+	restoreCmd.Flags().StringVarP(&uo.Justification, "justification", "", "", "Explain why access is needed when using protected files.")
+	restoreCmd.Flags().StringVarP(&uo.BreakGlass, "break-glass", "", "", "Restore protected files during a freeze, reporting the reason.")
+}
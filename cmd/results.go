@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/turnerlabs/cstore/components/diff"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+const (
+	resultRetrieved  = "retrieved"
+	resultUpToDate   = "up to date"
+	resultOffline    = "offline copy"
+	resultAssembled  = "assembled"
+	resultPushed     = "pushed"
+	resultVerified   = "pushed and verified"
+	resultUnverified = "not verified"
+	resultNotPushed  = "not pushed"
+	resultPurged     = "purged"
+	resultFailed     = "failed"
+	resultDiffers    = "differs"
+	resultMatches    = "matches"
+)
+
+// resultMessages are the messages displaying each result. Results are
+// written to JSON output as is.
+var resultMessages = map[string]message.ID{
+	resultRetrieved:  message.ResultRetrieved,
+	resultUpToDate:   message.ResultUpToDate,
+	resultOffline:    message.ResultOffline,
+	resultAssembled:  message.ResultAssembled,
+	resultPushed:     message.ResultPushed,
+	resultVerified:   message.ResultVerified,
+	resultUnverified: message.ResultUnverified,
+	resultNotPushed:  message.ResultNotPushed,
+	resultPurged:     message.ResultPurged,
+	resultFailed:     message.ResultFailed,
+	resultDiffers:    message.ResultDiffers,
+	resultMatches:    message.ResultMatches,
+	resultMigrated:   message.ResultMigrated,
+}
+
+// fileResult is what happened to a file during a bulk operation.
+type fileResult struct {
+	Path   string
+	Store  string
+	Result string
+	Err    error
+
+	// Keys are the keys of an env file the operation changed.
+	Keys []diff.KeyChange
+}
+
+// printResults displays one row for each file; so, operations spanning
+// many files can be reviewed without scrolling through their output.
+// Nothing is displayed for a single file.
+func printResults(results []fileResult, io models.IO) {
+	if len(results) <= 1 {
+		return
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+
+	w := tabwriter.NewWriter(io.UserOutput, 0, 4, 2, ' ', 0)
+
+	p := io.Messages
+
+	fmt.Fprintln(w)
+	p.Println(w, message.ResultsHeader)
+
+	for _, r := range results {
+		text := r.Result
+		if id, found := resultMessages[r.Result]; found {
+			text = p.Text(id)
+		}
+
+		result := p.Style(message.Success, text)
+
+		switch r.Result {
+		case resultFailed, resultNotPushed, resultUnverified:
+			result = p.Style(message.Failure, text)
+			if r.Err != nil {
+				result = fmt.Sprintf("%s (%s)", result, r.Err)
+			}
+		case resultOffline:
+			result = p.Style(message.Caution, text)
+		}
+
+		store := r.Store
+		if len(store) == 0 {
+			store = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Path, store, result)
+	}
+
+	w.Flush()
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/mattn/go-colorable"
@@ -10,20 +15,56 @@ import (
 	"github.com/spf13/viper"
 	"github.com/turnerlabs/cstore/components/catalog"
 	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/cipher"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/local"
+	"github.com/turnerlabs/cstore/components/logger"
+	"github.com/turnerlabs/cstore/components/message"
 	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/session"
 )
 
 const (
-	secretsToken = "secrets"
-	accessToken  = "access"
-	catalogToken = "catalog"
-	promptToken  = "prompt"
-	loggingToken = "logging"
-	commandToken = "store-command"
+	secretsToken      = "secrets"
+	accessToken       = "access"
+	catalogToken      = "catalog"
+	promptToken       = "prompt"
+	loggingToken      = "logging"
+	commandToken      = "store-command"
+	yesToken          = "assume-yes"
+	answersToken      = "answers"
+	helperToken       = "credential-helpers"
+	policyToken       = "policy"
+	quietToken        = "quiet"
+	fipsToken         = "fips"
+	metricsToken      = "metrics"
+	metricsFileToken  = "metrics-file"
+	promptHelperToken = "prompt-helper"
+	readOnlyToken     = "read-only"
+	redactToken       = "redact"
+	forgetToken       = "forget"
+	promptTTLToken    = "prompt-ttl"
+	concurrencyToken  = "concurrency"
+	cacheToken        = "offline-cache"
+	outputToken       = "output"
+	noPromptToken     = "no-prompt"
+	setToken          = "set"
+	localeToken       = "locale"
+	themeToken        = "theme"
+	verboseToken      = "verbose"
+
+	helperEnvVar       = "CSTORE_CREDENTIAL_HELPER"
+	promptHelperEnvVar = "CSTORE_PROMPT_HELPER"
+	readOnlyEnvVar     = "CSTORE_READ_ONLY"
+	promptEnvVar       = "CSTORE_PROMPT"
+	localeEnvVar       = "CSTORE_LOCALE"
+	themeEnvVar        = "CSTORE_THEME"
 )
 
 var (
 	cfgFile   string
+	logOutput io.Writer = os.Stderr
 	uo        cfg.UserOptions
 	ioStreams = models.IO{
 		UserOutput: colorable.NewColorableStderr(),
@@ -39,17 +80,52 @@ var RootCmd = &cobra.Command{
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
 	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if err := saveSharedPrompts(uo.Catalog); err != nil {
+			display.Warn(fmt.Sprintf("Prompt answers were not saved in %s. (%s)", uo.Catalog, err), ioStreams)
+		}
+
+		if err := prompt.SaveMemory(); err != nil {
+			display.Warn(fmt.Sprintf("Prompt answers were not remembered. (%s)", err), ioStreams)
+		}
+
+		if format := viper.GetString(metricsToken); len(format) > 0 {
+			if err := printMetrics(format, viper.GetString(metricsFileToken), ioStreams); err != nil {
+				display.Error(err, ioStreams)
+				os.Exit(1)
+			}
+		}
+	},
 }
 
 // Execute adds all child commands to the root command sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
+	recordSession()
+
+	err := RootCmd.Execute()
+
+	session.Finish(err)
+
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(-1)
 	}
 }
 
+// recordSession records the command's output, store calls, and HTTP
+// requests; so, a failed command can be sent to maintainers in a
+// support bundle.
+func recordSession() {
+	session.Start(os.Args[1:], cfg.Version)
+
+	http.DefaultTransport = session.Transport{Base: http.DefaultTransport}
+
+	ioStreams.UserOutput = io.MultiWriter(ioStreams.UserOutput, session.Writer{})
+	logOutput = io.MultiWriter(logOutput, session.Writer{})
+	logger.L.SetOutput(logOutput)
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -59,6 +135,22 @@ func init() {
 	RootCmd.PersistentFlags().StringP(commandToken, "", "", "Command to send to the store.")
 	RootCmd.PersistentFlags().BoolP(promptToken, "p", false, "Prompt user for configuration.")
 	RootCmd.PersistentFlags().BoolP(loggingToken, "l", false, "Set the format of the output to be log friendly instead of terminal friendly.")
+	RootCmd.PersistentFlags().BoolP(yesToken, "y", false, "Accept confirmations and use default values for prompts without waiting for input.")
+	RootCmd.PersistentFlags().StringP(answersToken, "", "", "Answer prompts using values from a yml file mapping prompt names to values.")
+	RootCmd.PersistentFlags().BoolP(quietToken, "q", false, "Suppress all output except errors, prompts, and data sent to stdout.")
+	RootCmd.PersistentFlags().BoolP(fipsToken, "", false, "Restrict client-side encryption to FIPS-approved algorithms and stores.")
+	RootCmd.PersistentFlags().StringP(metricsToken, "", "", "Print store call counts, retries, and latencies after the command. Use --metrics=json for JSON or --metrics=prometheus for Prometheus.")
+	RootCmd.PersistentFlags().Lookup(metricsToken).NoOptDefVal = "text"
+	RootCmd.PersistentFlags().StringP(metricsFileToken, "", "", "Replace a file with the json or prometheus metrics instead of printing them.")
+	RootCmd.PersistentFlags().StringP(promptHelperToken, "", "", "Answer prompts and confirmations using a helper program instead of the terminal.")
+	RootCmd.PersistentFlags().BoolP(readOnlyToken, "", false, "Disable commands that change remote files, like push and purge.")
+	RootCmd.PersistentFlags().BoolP(forgetToken, "", false, "Forget prompt answers remembered from earlier commands.")
+	RootCmd.PersistentFlags().StringP(outputToken, "", outputText, "Set the format of command results. Use json to send results to stdout as JSON.")
+	RootCmd.PersistentFlags().BoolP(noPromptToken, "", false, "Never wait for input. Prompts without a value fail with a missing input error.")
+	RootCmd.PersistentFlags().StringArrayP(setToken, "", []string{}, "Answer a prompt, like --set HARBOR_SHIPMENT=my-app. Repeat for each prompt.")
+	RootCmd.PersistentFlags().StringP(localeToken, "", "", "Display messages in a locale, like fr, using translations saved in $HOME/.cstore/messages.")
+	RootCmd.PersistentFlags().StringP(themeToken, "", "", "Display messages using a theme: default, plain, or emoji.")
+	RootCmd.PersistentFlags().BoolP(verboseToken, "", false, "Display details, like the keys changed by a pull.")
 
 	viper.BindPFlag(catalogToken, RootCmd.PersistentFlags().Lookup(catalogToken))
 	viper.BindPFlag(secretsToken, RootCmd.PersistentFlags().Lookup(secretsToken))
@@ -66,6 +158,20 @@ func init() {
 	viper.BindPFlag(promptToken, RootCmd.PersistentFlags().Lookup(promptToken))
 	viper.BindPFlag(loggingToken, RootCmd.PersistentFlags().Lookup(loggingToken))
 	viper.BindPFlag(commandToken, RootCmd.PersistentFlags().Lookup(commandToken))
+	viper.BindPFlag(yesToken, RootCmd.PersistentFlags().Lookup(yesToken))
+	viper.BindPFlag(answersToken, RootCmd.PersistentFlags().Lookup(answersToken))
+	viper.BindPFlag(quietToken, RootCmd.PersistentFlags().Lookup(quietToken))
+	viper.BindPFlag(fipsToken, RootCmd.PersistentFlags().Lookup(fipsToken))
+	viper.BindPFlag(metricsToken, RootCmd.PersistentFlags().Lookup(metricsToken))
+	viper.BindPFlag(metricsFileToken, RootCmd.PersistentFlags().Lookup(metricsFileToken))
+	viper.BindPFlag(promptHelperToken, RootCmd.PersistentFlags().Lookup(promptHelperToken))
+	viper.BindPFlag(readOnlyToken, RootCmd.PersistentFlags().Lookup(readOnlyToken))
+	viper.BindPFlag(forgetToken, RootCmd.PersistentFlags().Lookup(forgetToken))
+	viper.BindPFlag(outputToken, RootCmd.PersistentFlags().Lookup(outputToken))
+	viper.BindPFlag(noPromptToken, RootCmd.PersistentFlags().Lookup(noPromptToken))
+	viper.BindPFlag(localeToken, RootCmd.PersistentFlags().Lookup(localeToken))
+	viper.BindPFlag(themeToken, RootCmd.PersistentFlags().Lookup(themeToken))
+	viper.BindPFlag(verboseToken, RootCmd.PersistentFlags().Lookup(verboseToken))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -90,11 +196,248 @@ func setupUserOptions(userSpecifiedFilePaths []string) {
 	uo.AccessVault = viper.GetString(accessToken)
 	uo.Prompt = viper.GetBool(promptToken)
 	uo.StoreCommand = viper.GetString(commandToken)
+	uo.Policy = viper.GetString(policyToken)
+	uo.Cache = viper.GetBool(cacheToken)
+	uo.OutputFormat = viper.GetString(outputToken)
 
 	uo.AddPaths(userSpecifiedFilePaths)
-	uo.ParseTags()
 
 	if viper.GetBool(loggingToken) {
 		color.NoColor = true
 	}
+
+	if err := setupMessages(); err != nil {
+		display.Error(err, ioStreams)
+		os.Exit(1)
+	}
+
+	if err := uo.ParseTags(); err != nil {
+		display.Error(err, ioStreams)
+		os.Exit(1)
+	}
+
+	if err := checkOutput(uo.OutputFormat); err != nil {
+		display.Error(err, ioStreams)
+		os.Exit(1)
+	}
+
+	redactOutput(uo.Catalog)
+
+	if viper.GetBool(quietToken) {
+		ioStreams.UserOutput = display.QuietWriter{W: ioStreams.UserOutput}
+	}
+
+	uo.CredentialHelpers = viper.GetStringMapString(helperToken)
+	if uo.CredentialHelpers == nil {
+		uo.CredentialHelpers = map[string]string{}
+	}
+
+	if helper := os.Getenv(helperEnvVar); len(helper) > 0 {
+		uo.CredentialHelpers["*"] = helper
+	}
+
+	if viper.GetBool(fipsToken) {
+		if err := cipher.EnableFIPS(); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	}
+
+	if readOnly, _ := strconv.ParseBool(os.Getenv(readOnlyEnvVar)); readOnly || viper.GetBool(readOnlyToken) {
+		cfg.EnableReadOnly()
+	}
+
+	prompt.AssumeYes(viper.GetBool(yesToken))
+
+	promptHelper := viper.GetString(promptHelperToken)
+	if helper := os.Getenv(promptHelperEnvVar); len(helper) > 0 && len(promptHelper) == 0 {
+		promptHelper = helper
+	}
+
+	if len(promptHelper) > 0 {
+		prompt.UseBackend(prompt.Helper{Name: promptHelper})
+	}
+
+	if err := setupPrompts(); err != nil {
+		display.Error(err, ioStreams)
+		os.Exit(1)
+	}
+
+	if answers := viper.GetString(answersToken); len(answers) > 0 {
+		if err := prompt.LoadAnswers(answers); err != nil {
+			display.Error(fmt.Errorf("Could not load answers file %s! (%s)", answers, err), ioStreams)
+			os.Exit(1)
+		}
+	}
+	rememberPrompts()
+
+	if clog, err := catalog.Get(uo.Catalog); err == nil {
+		for _, warning := range clog.Warnings() {
+			display.Warn(warning, ioStreams)
+		}
+
+		if !uo.Prompt {
+			prompt.UseShared(clog.Prompts)
+		}
+	}
+}
+
+// setupMessages selects the locale and theme used to display messages.
+// Locales set by the user must have translations; the language of the
+// environment is only used when translated.
+func setupMessages() error {
+	p := message.Printer{}
+
+	theme := viper.GetString(themeToken)
+	if name := os.Getenv(themeEnvVar); len(name) > 0 && len(theme) == 0 {
+		theme = name
+	}
+
+	if len(theme) > 0 {
+		t, err := message.ParseTheme(theme)
+		if err != nil {
+			return err
+		}
+		p.Theme = t
+	}
+
+	if p.Theme.NoColor {
+		color.NoColor = true
+	}
+
+	switch {
+	case viper.GetBool(quietToken):
+		p.Theme.Verbosity = message.Quiet
+	case viper.GetBool(verboseToken):
+		p.Theme.Verbosity = message.Verbose
+	}
+
+	locale, required := viper.GetString(localeToken), true
+	if l := os.Getenv(localeEnvVar); len(l) > 0 && len(locale) == 0 {
+		locale = l
+	}
+
+	if len(locale) == 0 {
+		locale, required = languageOf(os.Getenv("LANG")), false
+	}
+
+	if len(locale) > 0 && locale != message.DefaultLocale {
+		name := fmt.Sprintf("messages/%s.yml", locale)
+
+		if local.Missing(name) {
+			if required {
+				return fmt.Errorf("No translations for locale %s were found in %s.", locale, local.BuildPath(name))
+			}
+		} else {
+			data, err := local.Get(name, "")
+			if err != nil {
+				return err
+			}
+
+			translations, err := message.ParseTranslations(data)
+			if err != nil {
+				return fmt.Errorf("Could not load translations from %s! (%s)", local.BuildPath(name), err)
+			}
+
+			p.Locale, p.Translations = locale, translations
+		}
+	}
+
+	ioStreams.Messages = p
+
+	return nil
+}
+
+// languageOf returns the language of a POSIX locale, like fr for
+// fr_FR.UTF-8.
+func languageOf(posix string) string {
+	if i := strings.IndexAny(posix, "_.@"); i > -1 {
+		posix = posix[:i]
+	}
+
+	if posix == "C" || posix == "POSIX" {
+		return ""
+	}
+
+	return strings.ToLower(posix)
+}
+
+// setupPrompts applies the prompt values passed with --set and stops
+// reading input from the user when prompting is disabled.
+func setupPrompts() error {
+	values, err := RootCmd.PersistentFlags().GetStringArray(setToken)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		i := strings.Index(value, "=")
+		if i < 1 {
+			return fmt.Errorf("%s is not a prompt value, use --set {name}={value}", value)
+		}
+
+		prompt.Set(value[:i], value[i+1:])
+	}
+
+	noPrompt := viper.GetBool(noPromptToken)
+	if enabled, err := strconv.ParseBool(os.Getenv(promptEnvVar)); err == nil && !enabled {
+		noPrompt = true
+	}
+
+	if noPrompt && uo.Prompt {
+		return errors.New("-p cannot be used with --no-prompt")
+	}
+
+	prompt.Interactive(!noPrompt)
+
+	return nil
+}
+
+// rememberPrompts loads the answers to prompts remembered from earlier
+// commands. When prompting is requested, they are only default values.
+func rememberPrompts() {
+	if viper.GetBool(forgetToken) {
+		if err := prompt.Forget(); err != nil {
+			display.Error(fmt.Errorf("Could not forget prompt answers! (%s)", err), ioStreams)
+			os.Exit(1)
+		}
+	}
+
+	ttl := prompt.DefaultMemoryTTL
+	if viper.IsSet(promptTTLToken) {
+		ttl = viper.GetDuration(promptTTLToken)
+	}
+
+	if uo.Prompt {
+		ttl = 0
+	}
+
+	if err := prompt.UseMemory(ttl); err != nil {
+		display.Warn(fmt.Sprintf("Remembered prompt answers were not loaded. (%s)", err), ioStreams)
+	}
+}
+
+// redactOutput replaces text matching the redaction patterns in the
+// user config and catalog in all output, errors, and logs.
+func redactOutput(catalogName string) {
+	patterns := viper.GetStringSlice(redactToken)
+
+	if clog, err := catalog.Get(catalogName); err == nil {
+		patterns = append(patterns, clog.Redact...)
+	}
+
+	if len(patterns) == 0 {
+		return
+	}
+
+	compiled, err := display.CompilePatterns(patterns)
+	if err != nil {
+		display.Error(err, ioStreams)
+		os.Exit(1)
+	}
+
+	ioStreams.UserOutput = display.RedactingWriter{W: ioStreams.UserOutput, Patterns: compiled}
+	logger.L.SetOutput(display.RedactingWriter{W: logOutput, Patterns: compiled})
+
+	session.UsePatterns(compiled)
 }
@@ -27,7 +27,7 @@ var storesCmd = &cobra.Command{
 			fmt.Fprintf(ioStreams.UserOutput, "Use 'cstore stores STORE_NAME' cmd for details.\n")
 
 			for _, store := range store.Get() {
-				fmt.Print("|-")
+				fmt.Fprint(ioStreams.UserOutput, "|-")
 				color.New(color.FgBlue).Fprintf(ioStreams.UserOutput, "%s\n", store.Name())
 			}
 
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+// typesCmd represents the types command
+var typesCmd = &cobra.Command{
+	Use:   "types",
+	Short: "Manage the key types of env files.",
+	Long: `Manage the key types of env files.
+
+Key types describe how each value is treated in the store, like plain
+or secret. See docs/KEY_TYPES.md.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// setTypesCmd represents the types set command
+var setTypesCmd = &cobra.Command{
+	Use:   "set {file} {key}={type} [{key}={type}] ...",
+	Short: "Set the key types of an env file without changing values.",
+	Long: `Set the key types of an env file without changing values.
+
+Types are declared in the catalog and, when the store keeps a type for
+each key, changed in the store without pushing the values. Types can be
+named by key type or by the store's name for it, like Harbor's 'hidden'.
+
+	$ cstore types set .env LOG_LEVEL=plain DB_PASSWORD=hidden`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			display.ErrorText(ioStreams.Messages.Text(message.TypesUsage), ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions(args[:1])
+
+		if err := SetTypes(uo, args[1:], ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// SetTypes declares key types in the file's catalog entry and changes
+// them in the store when it keeps a type for each key.
+func SetTypes(opt cfg.UserOptions, assignments []string, io models.IO) error {
+	if err := cfg.Writable("types"); err != nil {
+		return err
+	}
+
+	types, err := parseKeyTypes(assignments)
+	if err != nil {
+		return err
+	}
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	paths := opt.GetPaths(clog.CWD)
+
+	fileEntry, found := catalog.File{}, false
+	for _, f := range clog.Files {
+		if len(paths) > 0 && f.Path == paths[0] && !f.IsRef {
+			fileEntry, found = f, true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("%s is not aware of %s. Use 'list' command to view available files.", opt.Catalog, strings.Join(paths, ""))
+	}
+
+	if !fileEntry.SupportsConfig() {
+		return fmt.Errorf("Key types not supported for %s due to incompatible file type.", fileEntry.Path)
+	}
+
+	if fileEntry.KeyTypes == nil {
+		fileEntry.KeyTypes = map[string]string{}
+	}
+
+	for key, keyType := range types {
+		fileEntry.KeyTypes[key] = keyType
+	}
+
+	if err := fileEntry.CheckKeyTypes(); err != nil {
+		return err
+	}
+
+	//-------------------------------------------------
+	//- Change the types in the store without pushing
+	//- the values.
+	//-------------------------------------------------
+	applied := false
+
+	if len(fileEntry.Store) > 0 {
+		remoteEntry := overrideFileSettings(fileEntry, opt)
+
+		remoteComp, err := getRemoteComponents(&remoteEntry, clog, opt, io)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := store.Unwrap(remoteComp.store).(contract.IKeyTypeStore); ok {
+			if err := store.Refresh(remoteComp.store); err != nil {
+				return fmt.Errorf("Failed to refresh %s credentials. (%s)", remoteComp.store.Name(), err)
+			}
+
+			done := measure(remoteComp.store.Name(), "types")
+			err := remoteComp.store.(contract.IKeyTypeStore).SetKeyTypes(&remoteEntry, types, opt.Version)
+			done(err)
+			if err != nil {
+				return fmt.Errorf("could not set key types in %s (%s)", remoteComp.store.Name(), err)
+			}
+
+			fileEntry.Data = remoteEntry.Data
+			applied = true
+		}
+	}
+
+	if err := clog.UpdateEntry(fileEntry); err != nil {
+		return err
+	}
+
+	if err := catalog.Write(clog.GetFullPath(opt.Catalog), clog); err != nil {
+		return err
+	}
+
+	keys := []string{}
+	for key := range types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	p := io.Messages
+
+	fmt.Fprintln(io.UserOutput)
+	for _, key := range keys {
+		p.Println(io.UserOutput, message.TypesSetting, p.Style(message.Path, key), p.Style(message.Store, types[key]))
+	}
+
+	if !applied {
+		fmt.Fprintln(io.UserOutput)
+		p.Println(io.UserOutput, message.TypesPushHint, fileEntry.Path)
+	}
+	fmt.Fprintln(io.UserOutput)
+
+	return nil
+}
+
+// parseKeyTypes reads {key}={type} arguments.
+func parseKeyTypes(assignments []string) (map[string]string, error) {
+	types := map[string]string{}
+
+	for _, a := range assignments {
+		parts := strings.SplitN(a, "=", 2)
+		if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 {
+			return nil, fmt.Errorf("%s is not formatted as {key}={type}", a)
+		}
+
+		keyType, err := catalog.ParseKeyType(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		types[strings.TrimSpace(parts[0])] = keyType
+	}
+
+	return types, nil
+}
+
+func init() {
+	RootCmd.AddCommand(typesCmd)
+	typesCmd.AddCommand(setTypesCmd)
+
+	setTypesCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Set the key types of a specific version of the file.")
+}
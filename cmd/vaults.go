@@ -1,19 +1,29 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
 	"github.com/turnerlabs/cstore/components/vault"
 )
 
 // vaultsCmd represents the vaults command
 var vaultsCmd = &cobra.Command{
-	Use:   "vaults",
-	Short: "List available vaults or vault details.",
-	Long:  `List available vaults or vault details.`,
+	Use:     "vaults",
+	Aliases: []string{"vault"},
+	Short:   "List available vaults or vault details.",
+	Long:    `List available vaults or vault details.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		if len(args) == 1 {
@@ -27,7 +37,7 @@ var vaultsCmd = &cobra.Command{
 
 			fmt.Fprintf(ioStreams.UserOutput, "Use 'cstore vaults VAULT_NAME' cmd for details.\n")
 			for _, v := range vault.Get() {
-				fmt.Print("|-")
+				fmt.Fprint(ioStreams.UserOutput, "|-")
 				color.New(color.FgBlue).Fprintf(ioStreams.UserOutput, "%s\n", v.Name())
 			}
 
@@ -36,6 +46,197 @@ var vaultsCmd = &cobra.Command{
 	},
 }
 
+// exportVaultsCmd represents the vaults export command
+var exportVaultsCmd = &cobra.Command{
+	Use:   "export {file}",
+	Short: "Export vault secrets to an encrypted file.",
+	Long: `Export vault secrets to an encrypted file.
+
+Cached credentials and encryption keys in vaults able to list their
+secrets are encrypted with a passphrase; so, they can be moved to a new
+machine with 'vault import' instead of copying dotfiles.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			display.ErrorText("Specify the file to export vault secrets to.", ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions([]string{})
+
+		if err := ExportVaults(args[0], ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// importVaultsCmd represents the vaults import command
+var importVaultsCmd = &cobra.Command{
+	Use:   "import {file}",
+	Short: "Import vault secrets from an encrypted file.",
+	Long: `Import vault secrets from an encrypted file.
+
+Secrets exported with 'vault export' are saved in the same vaults on
+this machine. Secrets with different values are only overwritten when
+confirmed or when --force is set.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			display.ErrorText("Specify the file to import vault secrets from.", ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions([]string{})
+
+		if err := ImportVaults(args[0], uo, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// ExportVaults ...
+func ExportVaults(path string, io models.IO) error {
+	p := io.Messages
+
+	names := vault.Listable()
+	sort.Strings(names)
+
+	export, err := vault.Collect(names)
+	if err != nil {
+		return err
+	}
+
+	if len(export) == 0 {
+		fmt.Fprintln(io.UserOutput, p.Text(message.VaultsEmpty, strings.Join(names, ", ")))
+		return nil
+	}
+
+	passphrase, err := prompt.GetValFromUser("Passphrase", prompt.Options{
+		Description: "Passphrase used to encrypt the export. It is needed to import the secrets.",
+		HideInput:   true,
+	}, io)
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := prompt.GetValFromUser("Confirm Passphrase", prompt.Options{HideInput: true}, io)
+	if err != nil {
+		return err
+	}
+
+	if passphrase != confirmed {
+		return errors.New("passphrases do not match")
+	}
+
+	sealed, err := vault.Seal(export, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, sealed, 0600); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(io.UserOutput)
+
+	for _, name := range names {
+		if secrets, found := export[name]; found {
+			fmt.Fprintf(io.UserOutput, "|- %s %s\n", p.Style(message.Path, name), p.Text(message.VaultSecrets, len(secrets)))
+		}
+	}
+
+	fmt.Fprintf(io.UserOutput, "\n%s\n", p.Text(message.VaultsExported, path))
+
+	return nil
+}
+
+// ImportVaults ...
+func ImportVaults(path string, opt cfg.UserOptions, io models.IO) error {
+	p := io.Messages
+
+	sealed, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := prompt.GetValFromUser("Passphrase", prompt.Options{
+		Description: fmt.Sprintf("Passphrase used to encrypt %s.", path),
+		HideInput:   true,
+	}, io)
+	if err != nil {
+		return err
+	}
+
+	export, err := vault.Open(sealed, passphrase)
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for name := range export {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	imported, skipped := 0, 0
+
+	fmt.Fprintln(io.UserOutput)
+
+	for _, name := range names {
+		v, found := vault.Get()[name]
+		if !found {
+			display.Warn(p.Text(message.VaultUnavailable, name, len(export[name])), io)
+			skipped += len(export[name])
+			continue
+		}
+
+		keys := []string{}
+		for key := range export[name] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			value := export[name][key]
+
+			// Keys were built by the vault; so, they are set as
+			// groups without props to keep them unchanged.
+			existing, err := v.Get("", key, "")
+			if err == nil && existing == value {
+				continue
+			}
+
+			if err == nil && !opt.Force {
+				overwrite, err := prompt.Confirm(p.Text(message.VaultOverwrite, key, name), prompt.Warn, io)
+				if err != nil {
+					return err
+				}
+
+				if !overwrite {
+					skipped++
+					continue
+				}
+			}
+
+			if err := v.Set("", key, "", value); err != nil {
+				return fmt.Errorf("Failed to import %s into the %s vault. (%s)", key, name, err)
+			}
+
+			imported++
+		}
+
+		fmt.Fprintf(io.UserOutput, "|- %s\n", p.Style(message.Path, name))
+	}
+
+	fmt.Fprintf(io.UserOutput, "\n%s\n", p.Text(message.VaultsImported, imported, skipped))
+
+	return nil
+}
+
 func init() {
 	RootCmd.AddCommand(vaultsCmd)
+	vaultsCmd.AddCommand(exportVaultsCmd)
+	vaultsCmd.AddCommand(importVaultsCmd)
+
+	importVaultsCmd.Flags().BoolVarP(&uo.Force, "force", "", false, "Overwrite secrets with different values without confirming.")
 }
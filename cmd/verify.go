@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/metrics"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/pool"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+const (
+	verifyAttempts = 5
+	verifyBackoff  = time.Second
+)
+
+// verifyPushes reads back each pushed file and compares it with the
+// contents pushed, returning the files that could not be verified.
+func verifyPushes(staged []stagedFile, pushed []string, opt cfg.UserOptions, io models.IO) map[string]error {
+	isPushed := map[string]bool{}
+	for _, p := range pushed {
+		isPushed[p] = true
+	}
+
+	verifying := []stagedFile{}
+	for _, sf := range staged {
+		if isPushed[sf.fileEntry.Path] {
+			verifying = append(verifying, sf)
+		}
+	}
+
+	errs := pool.Run(concurrency(opt), len(verifying), func(i int) error {
+		return verifyPush(verifying[i], opt)
+	})
+
+	unverified := map[string]error{}
+	p := io.Messages
+
+	fmt.Fprintln(io.UserOutput)
+	for i, sf := range verifying {
+		if errs[i] != nil {
+			display.ErrorText(p.Text(message.PushUnverified, sf.path, errs[i]), io)
+			unverified[sf.path] = errs[i]
+			continue
+		}
+
+		p.Println(io.UserOutput, message.PushVerified, p.Style(message.Path, sf.path))
+	}
+
+	return unverified
+}
+
+// verifyPush pulls a pushed file until it matches the contents pushed.
+// Eventually consistent stores may return the previous contents for a
+// short time; so, the pull is retried, doubling the wait each time.
+func verifyPush(sf stagedFile, opt cfg.UserOptions) error {
+	wait := verifyBackoff
+	storeName := sf.remoteComp.store.Name()
+
+	var err error
+	for attempt := 1; attempt <= verifyAttempts; attempt++ {
+		if attempt > 1 {
+			metrics.Retried(storeName, 1)
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		if err = store.Refresh(sf.remoteComp.store); err != nil {
+			continue
+		}
+
+		fileEntry := sf.fileEntry
+
+		done := measure(storeName, "pull")
+		pulled, _, pullErr := sf.remoteComp.store.Pull(&fileEntry, opt.Version)
+		done(pullErr)
+
+		if err = pullErr; err != nil {
+			continue
+		}
+
+		if sameContents(fileEntry, sf.data, pulled) {
+			return nil
+		}
+
+		err = fmt.Errorf("%s still returned different contents after %d attempt(s)", storeName, attempt)
+	}
+
+	return err
+}
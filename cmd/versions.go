@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/store"
+)
+
+// versionsCmd represents the versions command
+var versionsCmd = &cobra.Command{
+	Use:   "versions {file}",
+	Short: "List the revisions of a file kept by its store.",
+	Long: `List the revisions of a file kept by its store.
+
+Lists each saved state of the file, newest first, marking the latest
+revision and the revision pinned in the catalog. Use --secret to list
+the revisions of a secret in the secrets vault instead.
+
+	$ cstore versions .env
+	$ cstore versions .env --secret dev/DB`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			display.ErrorText(ioStreams.Messages.Text(message.SpecifyFile, "cstore versions {file}"), ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions(args)
+
+		out, err := listVersions(uo, ioStreams)
+
+		writeOutput(out, err, uo, ioStreams)
+
+		if err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Versions lists the revisions of a file or secret.
+func Versions(opt cfg.UserOptions, io models.IO) error {
+	_, err := listVersions(opt, io)
+	return err
+}
+
+// listVersions lists the revisions of a file or secret returning them
+// for JSON output.
+func listVersions(opt cfg.UserOptions, io models.IO) (commandOutput, error) {
+	out := commandOutput{Command: "versions"}
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return out, err
+	}
+
+	fileEntry, remoteComp, err := getRevisionComponents(clog, opt, io)
+	if err != nil {
+		return out, err
+	}
+
+	//-------------------------------------------------
+	//- Get the revisions of the secret or file.
+	//-------------------------------------------------
+	name, source := fileEntry.Path, remoteComp.store.Name()
+	revisions := []contract.Revision{}
+
+	if len(opt.Secret) > 0 {
+		vault, ok := remoteComp.secrets.(contract.IRevisionVault)
+		if !ok {
+			return out, fmt.Errorf("%s vault does not keep secret revisions", remoteComp.secrets.Name())
+		}
+
+		name, source = opt.Secret, remoteComp.secrets.Name()
+
+		revisions, err = vault.Revisions(clog.Context, opt.Secret)
+		if err != nil {
+			return out, fmt.Errorf("Failed to get the revisions of %s. (%s)", opt.Secret, err)
+		}
+	} else {
+		revisioned, ok := remoteComp.store.(contract.IRevisionStore)
+		if !ok {
+			return out, fmt.Errorf("%s store does not keep file revisions", remoteComp.store.Name())
+		}
+
+		done := measure(remoteComp.store.Name(), "versions")
+		revisions, err = revisioned.Revisions(&fileEntry, opt.Version)
+		done(err)
+		if err != nil {
+			return out, fmt.Errorf("Failed to get the revisions of %s. (%s)", fileEntry.Path, err)
+		}
+	}
+
+	//-------------------------------------------------
+	//- List the revisions.
+	//-------------------------------------------------
+	p := io.Messages
+
+	fmt.Fprintln(io.UserOutput)
+	fmt.Fprint(io.UserOutput, p.Style(message.Heading, name))
+	if len(opt.Version) > 0 {
+		fmt.Fprint(io.UserOutput, " "+p.Text(message.OfVersion, opt.Version))
+	}
+	fmt.Fprintf(io.UserOutput, " [%s]\n\n", source)
+
+	for _, r := range revisions {
+		fmt.Fprintf(io.UserOutput, "|- %s %s", p.Style(message.Path, r.Modified.Local().Format("2006-01-02 15:04:05")), r.ID)
+
+		if r.Latest {
+			fmt.Fprint(io.UserOutput, " "+p.Style(message.Success, p.Text(message.VersionsLatest)))
+		}
+
+		if len(opt.Secret) == 0 && len(opt.Version) == 0 && r.ID == fileEntry.Pinned {
+			fmt.Fprint(io.UserOutput, " "+p.Style(message.Caution, p.Text(message.VersionsPinned)))
+		}
+
+		if len(r.Labels) > 0 {
+			fmt.Fprintf(io.UserOutput, " (%s)", strings.Join(r.Labels, ", "))
+		}
+
+		if len(r.By) > 0 {
+			fmt.Fprint(io.UserOutput, " "+p.Text(message.ChangedBy, r.By))
+		}
+
+		fmt.Fprintln(io.UserOutput)
+	}
+
+	fmt.Fprintf(io.UserOutput, "\n%s\n\n", p.Style(message.Summary, p.Text(message.VersionsSummary, len(revisions))))
+
+	pinned := ""
+	if len(opt.Secret) == 0 && len(opt.Version) == 0 {
+		pinned = fileEntry.Pinned
+	}
+
+	out.Files = []fileOutput{{Path: name, Store: source}}
+	out.Revisions = revisionOutputs(revisions, pinned)
+
+	return out, nil
+}
+
+// getRevisionComponents looks up the requested file and gets its store
+// and vaults ready to work with revisions.
+func getRevisionComponents(clog catalog.Catalog, opt cfg.UserOptions, io models.IO) (catalog.File, remoteComponents, error) {
+	paths := opt.GetPaths(clog.CWD)
+
+	fileEntry, found := clog.LookupEntry(paths[0], nil)
+	if !found {
+		return fileEntry, remoteComponents{}, fmt.Errorf("%s is not aware of %s. Use 'list' command to view available files.", opt.Catalog, strings.Join(paths, ""))
+	}
+
+	if len(opt.Version) > 0 && fileEntry.Missing(opt.Version) {
+		return fileEntry, remoteComponents{}, fmt.Errorf("version %s of %s not found in %s", opt.Version, fileEntry.Path, opt.Catalog)
+	}
+
+	fileEntry = overrideFileSettings(fileEntry, opt)
+
+	//----------------------------------------------------
+	//- Get the remote store and vaults components ready.
+	//----------------------------------------------------
+	remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+	if err != nil {
+		return fileEntry, remoteComp, err
+	}
+
+	if err := store.Refresh(remoteComp.store); err != nil {
+		return fileEntry, remoteComp, fmt.Errorf("Failed to refresh %s credentials. (%s)", remoteComp.store.Name(), err)
+	}
+
+	return fileEntry, remoteComp, nil
+}
+
+func init() {
+	RootCmd.AddCommand(versionsCmd)
+
+	versionsCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "List the revisions of a specific version of the file.")
+	versionsCmd.Flags().StringVarP(&uo.Secret, "secret", "", "", "List the revisions of a secret, like dev/DB, in the secrets vault.")
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	localFile "github.com/turnerlabs/cstore/components/file"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/token"
+)
+
+// whichCmd represents the which command
+var whichCmd = &cobra.Command{
+	Use:   "which {file} [key]",
+	Short: "Explain where a file or key is stored.",
+	Long: `Explain where a file or key is stored.
+
+Displays the resolved store, remote location, credentials source,
+encryption key, and version used when the file is pushed or pulled.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 || len(args) > 2 {
+			display.ErrorText("Specify a file and optionally a key. (cstore which {file} [key])", ioStreams)
+			os.Exit(1)
+		}
+
+		setupUserOptions(args[:1])
+
+		key := ""
+		if len(args) == 2 {
+			key = args[1]
+		}
+
+		if err := Which(uo, key, ioStreams); err != nil {
+			display.Error(err, ioStreams)
+			os.Exit(1)
+		}
+	},
+}
+
+// Which ...
+func Which(opt cfg.UserOptions, key string, io models.IO) error {
+
+	//-------------------------------------------------
+	//- Get the local catalog for reference.
+	//-------------------------------------------------
+	clog, err := catalog.Get(opt.Catalog)
+	if err != nil {
+		return err
+	}
+
+	paths := opt.GetPaths(clog.CWD)
+
+	fileEntry, found := catalog.File{}, false
+	for _, f := range clog.Files {
+		if len(paths) > 0 && f.Path == paths[0] && !f.IsRef {
+			fileEntry, found = f, true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("%s is not aware of %s. Use 'list' command to view available files.", opt.Catalog, strings.Join(paths, ""))
+	}
+
+	fileEntry = overrideFileSettings(fileEntry, opt)
+
+	//----------------------------------------------------
+	//- Get the remote store and vaults components ready.
+	//----------------------------------------------------
+	remoteComp, err := getRemoteComponents(&fileEntry, clog, opt, io)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(io.UserOutput)
+
+	whichRow("File", fileEntry.Path, io)
+	if len(key) > 0 {
+		whichRow("Key", key, io)
+	}
+	whichRow("Catalog", fmt.Sprintf("%s (context %s)", clog.GetFullPath(opt.Catalog), clog.Context), io)
+
+	version := "working copy"
+	if len(opt.Version) > 0 {
+		version = opt.Version
+		if fileEntry.Missing(opt.Version) {
+			version = fmt.Sprintf("%s (not found in catalog)", opt.Version)
+		}
+	}
+	whichRow("Version", version, io)
+
+	whichRow("Store", remoteComp.store.Name(), io)
+
+	//-------------------------------------------------
+	//- Describe the remote location when supported.
+	//-------------------------------------------------
+	if locator, ok := remoteComp.store.(contract.ILocatableStore); ok {
+		location, err := locator.Locate(&fileEntry, key, opt.Version)
+		if err != nil {
+			return fmt.Errorf("Failed to locate %s. (%s)", fileEntry.Path, err)
+		}
+
+		whichRow("Location", location.Remote, io)
+		whichRow("Credentials", location.Credentials, io)
+		whichRow("Encryption", location.Encryption, io)
+	} else {
+		whichRow("Location", "unknown, store cannot describe locations", io)
+	}
+
+	whichRow("Access", fmt.Sprintf("%s vault", remoteComp.access.Name()), io)
+
+	if helper := opt.CredentialHelper(remoteComp.store.Name()); len(helper) > 0 {
+		whichRow("Helper", helper, io)
+	}
+
+	whichRow("Secrets", fmt.Sprintf("%s vault", remoteComp.secrets.Name()), io)
+
+	//-------------------------------------------------
+	//- Describe how the key is resolved.
+	//-------------------------------------------------
+	if len(key) > 0 {
+		if t, found := fileEntry.Transforms.Push[key]; found {
+			whichRow("Push", strings.Join(t, ", "), io)
+		}
+
+		if t, found := fileEntry.Transforms.Pull[key]; found {
+			whichRow("Pull", strings.Join(t, ", "), io)
+		}
+
+		if name, found := fileEntry.Rename[key]; found {
+			whichRow("Rename", name, io)
+		}
+
+		if file, err := localFile.GetBy(clog.GetFullPath(fileEntry.Path)); err == nil && fileEntry.SupportsSecrets() {
+			tokens, _ := token.Find(file, fileEntry.Type, false)
+
+			for _, t := range tokens {
+				if t.EnvVar == strings.ToLower(key) {
+					whichRow("Secret", fmt.Sprintf("%s in %s vault", remoteComp.secrets.BuildKey(clog.Context, t.Secret(), t.Prop), remoteComp.secrets.Name()), io)
+				}
+			}
+		}
+	}
+
+	fmt.Fprintln(io.UserOutput)
+
+	return nil
+}
+
+func whichRow(label, value string, io models.IO) {
+	color.New(color.Bold).Fprintf(io.UserOutput, "%-12s ", label)
+	fmt.Fprintln(io.UserOutput, value)
+}
+
+func init() {
+	RootCmd.AddCommand(whichCmd)
+
+	whichCmd.Flags().StringVarP(&uo.Version, "ver", "v", "", "Explain where a specific version is stored.")
+}
@@ -0,0 +1,67 @@
+package assemble
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// Fragment is a catalog file inserted into an assembled file.
+type Fragment struct {
+	Path string
+	Data []byte
+}
+
+// Render builds a file from the fragments in order. When a template is
+// provided, it is rendered with the fragments in '.Includes' and an
+// 'include' function inserting a fragment by path; so, shared sections
+// can be placed anywhere in the file.
+//
+//	global
+//	{{ include "proxy/global.cfg" }}
+//	{{ range .Includes }}{{ . }}{{ end }}
+func Render(tmpl []byte, fragments []Fragment) ([]byte, error) {
+	if tmpl == nil {
+		buf := bytes.Buffer{}
+
+		for _, f := range fragments {
+			buf.Write(f.Data)
+
+			if len(f.Data) > 0 && !bytes.HasSuffix(f.Data, []byte("\n")) {
+				buf.WriteString("\n")
+			}
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	includes := []string{}
+	byPath := map[string]string{}
+
+	for _, f := range fragments {
+		includes = append(includes, string(f.Data))
+		byPath[f.Path] = string(f.Data)
+	}
+
+	t, err := template.New("assemble").Option("missingkey=error").Funcs(template.FuncMap{
+		"include": func(path string) (string, error) {
+			data, found := byPath[path]
+			if !found {
+				return "", fmt.Errorf("%s is not listed in the includes", path)
+			}
+
+			return data, nil
+		},
+	}).Parse(string(tmpl))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.Buffer{}
+
+	if err := t.Execute(&buf, struct{ Includes []string }{includes}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
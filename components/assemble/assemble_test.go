@@ -0,0 +1,42 @@
+package assemble
+
+import (
+	"strings"
+	"testing"
+)
+
+var fragments = []Fragment{
+	{Path: "proxy/global.cfg", Data: []byte("global\n  maxconn 4096\n")},
+	{Path: "proxy/site-a.cfg", Data: []byte("frontend a\n  bind :80")},
+}
+
+func TestRenderJoinsFragments(t *testing.T) {
+	file, err := Render(nil, fragments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "global\n  maxconn 4096\nfrontend a\n  bind :80\n"
+
+	if string(file) != expected {
+		t.Errorf("expected %q, got %q", expected, file)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	file, err := Render([]byte("# managed by cstore\n{{ include \"proxy/global.cfg\" }}{{ range .Includes }}-{{ end }}\n"), fragments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# managed by cstore\nglobal\n  maxconn 4096\n--\n"
+
+	if string(file) != expected {
+		t.Errorf("expected %q, got %q", expected, file)
+	}
+
+	_, err = Render([]byte("{{ include \"proxy/site-b.cfg\" }}"), fragments)
+	if err == nil || !strings.Contains(err.Error(), "proxy/site-b.cfg is not listed in the includes") {
+		t.Errorf("expected unlisted include error, got %v", err)
+	}
+}
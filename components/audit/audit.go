@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"time"
+)
+
+// TokenEnvVar holds an optional bearer token sent with each event.
+const TokenEnvVar = "CSTORE_AUDIT_TOKEN"
+
+// Timeout limits how long sending an event can take.
+var Timeout = 10 * time.Second
+
+// Event is sent as JSON to the audit endpoint when a protected file
+// is accessed.
+type Event struct {
+	Time          time.Time `json:"time"`
+	Action        string    `json:"action"`
+	Context       string    `json:"context"`
+	File          string    `json:"file"`
+	Store         string    `json:"store"`
+	Version       string    `json:"version,omitempty"`
+	User          string    `json:"user"`
+	Host          string    `json:"host"`
+	Justification string    `json:"justification"`
+}
+
+// NewEvent creates an event for the current user and host.
+func NewEvent(action, context, file, store, version, justification string) Event {
+	e := Event{
+		Time:          time.Now().UTC(),
+		Action:        action,
+		Context:       context,
+		File:          file,
+		Store:         store,
+		Version:       version,
+		Justification: justification,
+	}
+
+	if u, err := user.Current(); err == nil {
+		e.User = u.Username
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		e.Host = host
+	}
+
+	return e
+}
+
+// Send posts the event to the endpoint. Only HTTPS endpoints are
+// allowed since events identify users and files.
+func Send(endpoint string, e Event) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "https" {
+		return errors.New("audit endpoint must use https")
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if token := os.Getenv(TokenEnvVar); len(token) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	client := http.Client{Timeout: Timeout}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit endpoint returned %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSend(t *testing.T) {
+	// arrange
+	received := Event{}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	defer func(t http.RoundTripper) { http.DefaultTransport = t }(http.DefaultTransport)
+	http.DefaultTransport = server.Client().Transport
+
+	e := NewEvent("pull", "app", ".env", "aws-s3", "", "INC-1234 outage")
+
+	// act
+	err := Send(server.URL, e)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if received.Justification != e.Justification || received.File != e.File {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", e, received)
+	}
+}
+
+func TestSendRequiresHTTPS(t *testing.T) {
+	// act
+	err := Send("http://siem.example.com/events", Event{})
+
+	// assert
+	if err == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "https error", err)
+	}
+}
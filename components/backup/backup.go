@@ -0,0 +1,158 @@
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/cipher"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/crypto"
+	"github.com/turnerlabs/cstore/components/local"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/setting"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	dir = "backups"
+	ext = ".bak"
+
+	keyGroup = "CSTORE"
+	keyProp  = "BACKUP_KEY"
+)
+
+// Backup is a snapshot of a remote file taken before a destructive
+// operation. Backups are saved in the user's cStore directory with the
+// file's data encrypted using a key kept in the access vault; so, the
+// data cannot be read using only the files on disk.
+type Backup struct {
+	ID      string    `yaml:"-"`
+	Context string    `yaml:"context"`
+	Path    string    `yaml:"path"`
+	Store   string    `yaml:"store"`
+	Version string    `yaml:"version,omitempty"`
+	Reason  string    `yaml:"reason"`
+	Created time.Time `yaml:"created"`
+
+	// Vault names the access vault holding the key.
+	Vault string `yaml:"vault"`
+
+	// Data is encrypted. Use Open to decrypt it.
+	Data []byte `yaml:"data"`
+}
+
+// Key returns the key backups of a context are encrypted with. A new
+// key is generated and saved in the access vault the first time a
+// backup is saved.
+func Key(access contract.IVault, context string, io models.IO) (string, error) {
+	return setting.Setting{
+		Description:  "Key used to encrypt local backups taken before destructive operations. Backups cannot be restored without it.",
+		Group:        keyGroup,
+		Prop:         keyProp,
+		HideInput:    true,
+		AutoSave:     true,
+		DefaultValue: cipher.GenerateAES256Key(),
+		Vault:        access,
+	}.Get(context, io)
+}
+
+// Save encrypts the data and saves a backup returning its id.
+func Save(b Backup, key string) (string, error) {
+	if b.Created.IsZero() {
+		b.Created = time.Now()
+	}
+
+	b.ID = fmt.Sprintf("%s-%s", b.Created.UTC().Format("20060102T150405Z"), cipher.GenKey(6))
+
+	sealed, err := crypto.Seal(b.Data, []byte(b.ID), []crypto.Wrapper{passphrase(key)})
+	if err != nil {
+		return "", err
+	}
+	b.Data = sealed
+
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return "", err
+	}
+
+	return b.ID, local.Update(name(b.ID), "", data)
+}
+
+// Get returns a backup with its data encrypted.
+func Get(id string) (Backup, error) {
+	b := Backup{}
+
+	data, err := local.Get(name(id), "")
+	if err != nil {
+		return b, fmt.Errorf("backup %s could not be read (%s)", id, err)
+	}
+
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("backup %s could not be read (%s)", id, err)
+	}
+
+	b.ID = id
+
+	return b, nil
+}
+
+// Open decrypts the backup's data.
+func (b Backup) Open(key string) ([]byte, error) {
+	data, err := crypto.Open(b.Data, []byte(b.ID), []crypto.Unwrapper{passphrase(key)})
+	if err != nil {
+		return nil, fmt.Errorf("backup %s could not be decrypted (%s)", b.ID, err)
+	}
+
+	return data, nil
+}
+
+// List returns all backups, newest first.
+func List() ([]Backup, error) {
+	backups := []Backup{}
+
+	files, err := ioutil.ReadDir(local.BuildPath(dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return backups, nil
+		}
+		return backups, err
+	}
+
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ext) {
+			continue
+		}
+
+		b, err := Get(strings.TrimSuffix(f.Name(), ext))
+		if err != nil {
+			return backups, err
+		}
+
+		backups = append(backups, b)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Created.After(backups[j].Created)
+	})
+
+	return backups, nil
+}
+
+// Remove ...
+func Remove(id string) error {
+	return os.Remove(local.BuildPath(name(id)))
+}
+
+func name(id string) string {
+	return fmt.Sprintf("%s/%s%s", dir, id, ext)
+}
+
+func passphrase(key string) crypto.Passphrase {
+	return crypto.Passphrase{Get: func() (string, error) {
+		return key, nil
+	}}
+}
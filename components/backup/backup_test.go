@@ -0,0 +1,97 @@
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSaveAndGet(t *testing.T) {
+	// arrange
+	home, err := ioutil.TempDir("", "cstore-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	key := "01234567890123456789012345678901"
+
+	// act
+	id, err := Save(Backup{
+		Context: "app",
+		Path:    ".env",
+		Store:   "aws-s3",
+		Reason:  "purge",
+		Vault:   "env",
+		Data:    []byte("SECRET=1\n"),
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := b.Open(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := ioutil.ReadFile(home + "/.cstore/backups/" + id + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if string(data) != "SECRET=1\n" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "SECRET=1\n", data)
+	}
+
+	if b.ID != id || b.Path != ".env" || b.Vault != "env" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", id, b.ID)
+	}
+
+	if strings.Contains(string(raw), "SECRET=1") || strings.Contains(string(raw), key) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "encrypted backup", raw)
+	}
+
+	if _, err := os.Stat(home + "/.cstore/backup.key"); !os.IsNotExist(err) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "no local key", err)
+	}
+}
+
+func TestOpenWrongKey(t *testing.T) {
+	// arrange
+	home, err := ioutil.TempDir("", "cstore-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	id, err := Save(Backup{Path: ".env", Data: []byte("SECRET=1\n")}, "01234567890123456789012345678901")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = b.Open("10987654321098765432109876543210")
+
+	// assert
+	if err == nil {
+		t.Error("\nEXPECTED: error for wrong key")
+	}
+}
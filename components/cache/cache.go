@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/cipher"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/local"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/setting"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	dir = "cache"
+	ext = ".cache"
+
+	keyGroup = "CSTORE"
+	keyProp  = "CACHE_KEY"
+)
+
+// entry is a pulled file saved for offline use.
+type entry struct {
+	Pulled time.Time `yaml:"pulled"`
+	Data   []byte    `yaml:"data"`
+}
+
+// Key returns the key offline copies are encrypted with. A new key is
+// generated and saved in the access vault the first time a file is
+// cached; so, copies cannot be read using only the files on disk.
+func Key(access contract.IVault, context string, io models.IO) (string, error) {
+	return setting.Setting{
+		Description:  "Key used to encrypt the copies of pulled files kept for offline use.",
+		Group:        keyGroup,
+		Prop:         keyProp,
+		HideInput:    true,
+		AutoSave:     true,
+		DefaultValue: cipher.GenerateAES256Key(),
+		Vault:        access,
+	}.Get(context, io)
+}
+
+// Save encrypts and caches the contents of a pulled file; so, it can be
+// used when the store cannot be reached.
+func Save(key, context, fileKey, version string, data []byte) error {
+	b, err := yaml.Marshal(entry{
+		Pulled: time.Now(),
+		Data:   data,
+	})
+	if err != nil {
+		return err
+	}
+
+	return local.Update(name(context, fileKey, version), key, b)
+}
+
+// Has reports whether a copy of a file is cached.
+func Has(context, fileKey, version string) bool {
+	return !local.Missing(name(context, fileKey, version))
+}
+
+// Get returns the cached contents of a file and when it was pulled.
+func Get(key, context, fileKey, version string) ([]byte, time.Time, error) {
+	if !Has(context, fileKey, version) {
+		return nil, time.Time{}, fmt.Errorf("no offline copy is available")
+	}
+
+	b, err := local.Get(name(context, fileKey, version), key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	e := entry{}
+	if err := yaml.Unmarshal(b, &e); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return e.Data, e.Pulled, nil
+}
+
+func name(context, fileKey, version string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s", context, fileKey, version)))
+	return fmt.Sprintf("%s/%s%s", dir, hex.EncodeToString(sum[:]), ext)
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+func TestSaveAndGet(t *testing.T) {
+	// arrange
+	home, err := ioutil.TempDir("", "cstore-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	homedir.DisableCache = true
+
+	key := "01234567890123456789012345678901"
+
+	// act
+	if err := Save(key, "app", "abc123", "", []byte("SECRET=1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, pulled, err := Get(key, "app", "abc123", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, missing := Get(key, "app", "abc123", "v2")
+
+	// assert
+	if string(data) != "SECRET=1\n" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "SECRET=1\n", data)
+	}
+
+	if pulled.IsZero() {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "pulled time", pulled)
+	}
+
+	if missing == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "missing version error", missing)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	// arrange
+	home, err := ioutil.TempDir("", "cstore-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	homedir.DisableCache = true
+
+	changed := time.Date(2020, 3, 14, 10, 15, 0, 0, time.UTC)
+
+	// act
+	if err := SaveChanged("app", "abc123", changed); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveKeys("app", "abc123", map[string]time.Time{"DB_PASS": changed}); err != nil {
+		t.Fatal(err)
+	}
+
+	cachedChanged, foundChanged := GetChanged("app", "abc123", MetadataTTL)
+	keys, foundKeys := GetKeys("app", "abc123", MetadataTTL)
+	_, expired := GetChanged("app", "abc123", -time.Second)
+
+	if err := ClearMetadata("app", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, cleared := GetKeys("app", "abc123", MetadataTTL)
+
+	// assert
+	if !foundChanged || !cachedChanged.Equal(changed) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s (found %t)", changed, cachedChanged, foundChanged)
+	}
+
+	if !foundKeys || !keys["DB_PASS"].Equal(changed) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v (found %t)", "DB_PASS cached", keys, foundKeys)
+	}
+
+	if expired {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "expired metadata ignored", "found")
+	}
+
+	if cleared {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "cleared metadata ignored", "found")
+	}
+}
+
+func TestResume(t *testing.T) {
+	// arrange
+	home, err := ioutil.TempDir("", "cstore-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+	homedir.DisableCache = true
+
+	record := Resume{
+		Store:     "aws-parameter",
+		Checksum:  Checksum([]byte("A=1\nB=2\n")),
+		Remaining: []string{"B"},
+	}
+
+	// act
+	if err := SaveResume("app", "abc123", "", record); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, found := GetResume("app", "abc123", "")
+	_, otherVersion := GetResume("app", "abc123", "v1")
+
+	if err := ClearResume("app", "abc123", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	_, cleared := GetResume("app", "abc123", "")
+
+	// assert
+	if !found || saved.Checksum != record.Checksum || len(saved.Remaining) != 1 || saved.Remaining[0] != "B" {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v (found %t)", record, saved, found)
+	}
+
+	if otherVersion || cleared {
+		t.Errorf("\nEXPECTED: %t \nACTUAL: %t %t", false, otherVersion, cleared)
+	}
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/local"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// MetadataTTL is how long remote metadata is used before the store
+// is queried again.
+const MetadataTTL = 5 * time.Minute
+
+const (
+	metadataDir = "cache/metadata"
+
+	changedKind = "changed"
+	keysKind    = "keys"
+)
+
+// metadata is remote file information saved to avoid querying the
+// store on every list or check.
+type metadata struct {
+	Fetched time.Time            `yaml:"fetched"`
+	Changed time.Time            `yaml:"changed,omitempty"`
+	Keys    map[string]time.Time `yaml:"keys,omitempty"`
+}
+
+// SaveChanged caches when a remote file last changed.
+func SaveChanged(context, fileKey string, changed time.Time) error {
+	return saveMetadata(metadataName(context, fileKey, changedKind), metadata{Changed: changed})
+}
+
+// GetChanged returns when a remote file last changed when cached less
+// than maxAge ago.
+func GetChanged(context, fileKey string, maxAge time.Duration) (time.Time, bool) {
+	m, found := getMetadata(metadataName(context, fileKey, changedKind), maxAge)
+	return m.Changed, found
+}
+
+// SaveKeys caches when each key in a remote file last changed.
+func SaveKeys(context, fileKey string, keys map[string]time.Time) error {
+	return saveMetadata(metadataName(context, fileKey, keysKind), metadata{Keys: keys})
+}
+
+// GetKeys returns when each key in a remote file last changed when
+// cached less than maxAge ago.
+func GetKeys(context, fileKey string, maxAge time.Duration) (map[string]time.Time, bool) {
+	m, found := getMetadata(metadataName(context, fileKey, keysKind), maxAge)
+	if m.Keys == nil {
+		m.Keys = map[string]time.Time{}
+	}
+	return m.Keys, found
+}
+
+// ClearMetadata removes the cached metadata for a file after it
+// changes remotely.
+func ClearMetadata(context, fileKey string) error {
+	for _, kind := range []string{changedKind, keysKind} {
+		if err := os.Remove(local.BuildPath(metadataName(context, fileKey, kind))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func saveMetadata(name string, m metadata) error {
+	m.Fetched = time.Now()
+
+	b, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return local.Update(name, "", b)
+}
+
+func getMetadata(name string, maxAge time.Duration) (metadata, bool) {
+	m := metadata{}
+
+	if local.Missing(name) {
+		return m, false
+	}
+
+	b, err := local.Get(name, "")
+	if err != nil {
+		return m, false
+	}
+
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return metadata{}, false
+	}
+
+	if time.Since(m.Fetched) > maxAge {
+		return metadata{}, false
+	}
+
+	return m, true
+}
+
+func metadataName(context, fileKey, kind string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s", context, fileKey)))
+	return fmt.Sprintf("%s/%s.%s.yml", metadataDir, hex.EncodeToString(sum[:]), kind)
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/local"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const resumeDir = "cache/resume"
+
+// Resume records the keys left to push when a push to a key/value
+// store fails partway through.
+type Resume struct {
+	Saved time.Time `yaml:"saved"`
+	Store string    `yaml:"store"`
+
+	// Checksum identifies the file contents being pushed; so, a push
+	// is only resumed for the same contents.
+	Checksum string `yaml:"checksum"`
+
+	Remaining []string `yaml:"remaining"`
+}
+
+// Checksum returns the checksum saved with a resume record for the
+// file contents.
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveResume records the keys left to push for a file version.
+func SaveResume(context, fileKey, version string, r Resume) error {
+	r.Saved = time.Now()
+
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return local.Update(resumeName(context, fileKey, version), "", b)
+}
+
+// GetResume returns the keys left to push for a file version and false
+// when no push was interrupted.
+func GetResume(context, fileKey, version string) (Resume, bool) {
+	r := Resume{}
+	name := resumeName(context, fileKey, version)
+
+	if local.Missing(name) {
+		return r, false
+	}
+
+	b, err := local.Get(name, "")
+	if err != nil {
+		return r, false
+	}
+
+	if err := yaml.Unmarshal(b, &r); err != nil {
+		return Resume{}, false
+	}
+
+	return r, true
+}
+
+// ClearResume removes the resume record after a file is pushed.
+func ClearResume(context, fileKey, version string) error {
+	if err := os.Remove(local.BuildPath(resumeName(context, fileKey, version))); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func resumeName(context, fileKey, version string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s", context, fileKey, version)))
+	return fmt.Sprintf("%s/%s.yml", resumeDir, hex.EncodeToString(sum[:]))
+}
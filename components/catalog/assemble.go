@@ -0,0 +1,26 @@
+package catalog
+
+// Assembly builds a file from fragments stored as separate catalog
+// files, like the shared and per-site sections of a proxy config.
+type Assembly struct {
+	// Template is the path of a catalog file rendered with Go's
+	// text/template to build the file. When empty, the includes are
+	// joined in the order listed.
+	Template string `yaml:"template,omitempty"`
+
+	// Includes are the paths of the catalog files inserted into the
+	// file in the order listed.
+	Includes []string `yaml:"includes"`
+}
+
+// Sources returns the paths of the catalog files the assembly is built
+// from.
+func (a Assembly) Sources() []string {
+	sources := []string{}
+
+	if len(a.Template) > 0 {
+		sources = append(sources, a.Template)
+	}
+
+	return append(sources, a.Includes...)
+}
@@ -0,0 +1,53 @@
+package catalog
+
+import (
+	"fmt"
+	"path"
+)
+
+const (
+	// DefaultComposeFolder is where service env files are written when
+	// the catalog does not specify a folder.
+	DefaultComposeFolder = "compose"
+
+	// DefaultComposeOverride is the compose file patched to reference
+	// the service env files.
+	DefaultComposeOverride = "docker-compose.override.yml"
+)
+
+// Compose maps docker-compose services to the env files merged into an
+// env_file for each service.
+type Compose struct {
+	// Folder is where service env files are written relative to the
+	// catalog.
+	Folder string `yaml:"folder,omitempty"`
+
+	// Override is the compose file patched to reference the service
+	// env files relative to the catalog.
+	Override string `yaml:"override,omitempty"`
+
+	// Services maps service names to the paths of the files merged
+	// in the order listed.
+	Services map[string][]string `yaml:"services,omitempty"`
+}
+
+// EnvFile returns the path of the service's env file relative to the
+// catalog.
+func (c Compose) EnvFile(service string) string {
+	folder := c.Folder
+	if len(folder) == 0 {
+		folder = DefaultComposeFolder
+	}
+
+	return path.Join(folder, fmt.Sprintf("%s.env", service))
+}
+
+// OverrideFile returns the path of the compose file patched relative
+// to the catalog.
+func (c Compose) OverrideFile() string {
+	if len(c.Override) == 0 {
+		return DefaultComposeOverride
+	}
+
+	return c.Override
+}
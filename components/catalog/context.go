@@ -0,0 +1,102 @@
+package catalog
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/turnerlabs/cstore/components/local"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const contextsName = "contexts.yml"
+
+var contextRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidContext returns an error when a context name cannot be used as
+// a prefix in every store.
+func ValidContext(context string) error {
+	if !contextRegex.MatchString(context) {
+		return fmt.Errorf("invalid context %s, use letters, numbers, '.', '_', and '-'", context)
+	}
+	return nil
+}
+
+// DefaultContext returns the context saved in the catalog file.
+func (c Catalog) DefaultContext() string {
+	if len(c.defaultContext) > 0 {
+		return c.defaultContext
+	}
+	return c.Context
+}
+
+// KnownContexts returns the default context followed by the contexts
+// created for the catalog.
+func (c Catalog) KnownContexts() []string {
+	contexts := []string{c.DefaultContext()}
+
+	for _, ctx := range c.Contexts {
+		if ctx != c.DefaultContext() {
+			contexts = append(contexts, ctx)
+		}
+	}
+
+	return contexts
+}
+
+// Knows ...
+func (c Catalog) Knows(context string) bool {
+	for _, ctx := range c.KnownContexts() {
+		if ctx == context {
+			return true
+		}
+	}
+	return false
+}
+
+// UseContext selects the context used with a catalog in this working
+// copy without changing the catalog file. Selecting the default context
+// clears the selection.
+func UseContext(catalogPath string, clog Catalog, context string) error {
+	key, err := filepath.Abs(catalogPath)
+	if err != nil {
+		return err
+	}
+
+	selected := getSelectedContexts()
+
+	if context == clog.DefaultContext() {
+		delete(selected, key)
+	} else {
+		selected[key] = context
+	}
+
+	b, err := yaml.Marshal(selected)
+	if err != nil {
+		return err
+	}
+
+	return local.Update(contextsName, "", b)
+}
+
+// selectedContext returns the context selected for a catalog.
+func selectedContext(catalogPath string) (string, bool) {
+	key, err := filepath.Abs(catalogPath)
+	if err != nil {
+		return "", false
+	}
+
+	context, found := getSelectedContexts()[key]
+
+	return context, found
+}
+
+func getSelectedContexts() map[string]string {
+	selected := map[string]string{}
+
+	if b, err := local.Get(contextsName, ""); err == nil {
+		yaml.Unmarshal(b, &selected)
+	}
+
+	return selected
+}
@@ -0,0 +1,76 @@
+package catalog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/turnerlabs/cstore/components/cfg"
+)
+
+func TestSelectedContext(t *testing.T) {
+	// arrange
+	home, err := ioutil.TempDir("", "cstore-context")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	os.Setenv("HOME", home)
+	homedir.DisableCache = true
+
+	cfg.Version = "v2.0.0"
+
+	catalogPath := filepath.Join(home, DefaultFileName)
+	if err := Write(catalogPath, Catalog{Version: "v2", Context: "app", Contexts: []string{"app-dev"}, Files: map[string]File{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	clog, err := Get(catalogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	if err := UseContext(catalogPath, clog, "app-dev"); err != nil {
+		t.Fatal(err)
+	}
+
+	selected, err := Get(catalogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Write(catalogPath, selected); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if selected.Context != "app-dev" || selected.DefaultContext() != "app" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s (default %s)", "app-dev (default app)", selected.Context, selected.DefaultContext())
+	}
+
+	b, err := ioutil.ReadFile(catalogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), "context: app\n") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "catalog saved with default context app", string(b))
+	}
+}
+
+func TestValidContext(t *testing.T) {
+	for context, valid := range map[string]bool{"app-dev": true, "jane.doe_1": true, "": false, "a/b": false, "-x": false} {
+		// act
+		err := ValidContext(context)
+
+		// assert
+		if (err == nil) != valid {
+			t.Errorf("\nEXPECTED: %s valid %t \nACTUAL: %v", context, valid, err)
+		}
+	}
+}
@@ -6,15 +6,24 @@ import (
 	"github.com/turnerlabs/cstore/components/prompt"
 )
 
-func create(io models.IO) Catalog {
-	val := prompt.GetValFromUser("Context", prompt.Options{
+func create(io models.IO) (Catalog, error) {
+	val, err := prompt.GetValFromUser("Context", prompt.Options{
 		Description:  "The project name categorizing the remotely stored files. This gives context to all files in this catalog and is often used as a prefix in the remote store. To avoid overriding existing data in the remote store, ensure context is unique.",
 		DefaultValue: getContext(),
 	}, io)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	return New(val), nil
+}
 
+// New returns an empty catalog for the context.
+func New(context string) Catalog {
 	return Catalog{
 		Version: cfg.Version[0:2],
-		Context: val,
+		Context: context,
+		Hash:    HashSHA256,
 		Files:   map[string]File{},
 	}
 }
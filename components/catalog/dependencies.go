@@ -0,0 +1,86 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Waves groups files into the order they are pushed and pulled. Files
+// in a wave only depend on files in earlier waves or on files not being
+// pushed or pulled; so, the files in a wave can be processed at once.
+// Files in each wave are sorted by path.
+func Waves(files []File) ([][]File, error) {
+	remaining := map[string]File{}
+	for _, f := range files {
+		remaining[f.Path] = f
+	}
+
+	waves := [][]File{}
+
+	for len(remaining) > 0 {
+		wave := []File{}
+
+		for _, f := range remaining {
+			ready := true
+			for _, dep := range f.DependsOn {
+				if _, waiting := remaining[dep]; waiting {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				wave = append(wave, f)
+			}
+		}
+
+		if len(wave) == 0 {
+			paths := []string{}
+			for p := range remaining {
+				paths = append(paths, p)
+			}
+			sort.Strings(paths)
+
+			return waves, fmt.Errorf("dependencies of %s form a cycle", strings.Join(paths, ", "))
+		}
+
+		sort.Slice(wave, func(i, j int) bool {
+			return wave[i].Path < wave[j].Path
+		})
+
+		for _, f := range wave {
+			delete(remaining, f.Path)
+		}
+
+		waves = append(waves, wave)
+	}
+
+	return waves, nil
+}
+
+// checkDependencies verifies each dependency is a cataloged file and
+// dependencies do not form a cycle.
+func (c Catalog) checkDependencies(catalogName string) error {
+	files := []File{}
+	paths := map[string]bool{}
+
+	for _, f := range c.Files {
+		files = append(files, f)
+		paths[f.Path] = true
+	}
+
+	for _, f := range files {
+		for _, dep := range f.DependsOn {
+			if !paths[dep] {
+				return fmt.Errorf("%s depends on %s which is not in %s", f.Path, dep, catalogName)
+			}
+		}
+	}
+
+	if _, err := Waves(files); err != nil {
+		return fmt.Errorf("%s in %s", err, catalogName)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWaves(t *testing.T) {
+	// arrange
+	files := []File{
+		{Path: "api/.env", DependsOn: []string{"bootstrap/.env"}},
+		{Path: "web/.env", DependsOn: []string{"bootstrap/.env", "api/.env"}},
+		{Path: "bootstrap/.env"},
+		{Path: "worker/.env", DependsOn: []string{"bootstrap/.env"}},
+		{Path: "jobs/.env", DependsOn: []string{"shared/.env"}},
+	}
+
+	// act
+	waves, err := Waves(files)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := [][]string{}
+	for _, w := range waves {
+		paths := []string{}
+		for _, f := range w {
+			paths = append(paths, f.Path)
+		}
+		actual = append(actual, paths)
+	}
+
+	expected := [][]string{
+		{"bootstrap/.env", "jobs/.env"},
+		{"api/.env", "worker/.env"},
+		{"web/.env"},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected, actual)
+	}
+}
+
+func TestCheckDependencies(t *testing.T) {
+	// arrange
+	tests := map[string]map[string]File{
+		"a depends on c which is not in cstore.yml": {
+			"a": {Path: "a", DependsOn: []string{"c"}},
+		},
+		"dependencies of a, b form a cycle in cstore.yml": {
+			"a": {Path: "a", DependsOn: []string{"b"}},
+			"b": {Path: "b", DependsOn: []string{"a"}},
+		},
+	}
+
+	for expected, files := range tests {
+		c := Catalog{Files: files}
+
+		// act
+		err := c.checkDependencies("cstore.yml")
+
+		// assert
+		if err == nil || err.Error() != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+		}
+	}
+}
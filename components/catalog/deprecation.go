@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SunsetFormat is the layout of deprecation sunset dates.
+const SunsetFormat = "2006-01-02"
+
+// Deprecation declares a key is being replaced by another key.
+type Deprecation struct {
+	// Replacement is the name of the key replacing the deprecated key.
+	Replacement string `yaml:"replacement,omitempty"`
+
+	// Sunset is the date, like "2019-06-30", after which the deprecated
+	// key fails checks.
+	Sunset string `yaml:"sunset,omitempty"`
+}
+
+// SunsetTime returns the start of the day after the sunset date in
+// local time. It is zero when no sunset date is declared.
+func (d Deprecation) SunsetTime() (time.Time, error) {
+	if len(d.Sunset) == 0 {
+		return time.Time{}, nil
+	}
+
+	t, err := time.ParseInLocation(SunsetFormat, d.Sunset, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown sunset date %s, expected %s", d.Sunset, SunsetFormat)
+	}
+
+	return t.AddDate(0, 0, 1), nil
+}
+
+// Sunsetted returns true when the sunset date has passed.
+func (d Deprecation) Sunsetted(now time.Time) (bool, error) {
+	sunset, err := d.SunsetTime()
+	if err != nil || sunset.IsZero() {
+		return false, err
+	}
+
+	return !now.Before(sunset), nil
+}
+
+// Message describes the deprecation of a key for warnings.
+func (d Deprecation) Message(key string) string {
+	msg := fmt.Sprintf("%s is deprecated", key)
+
+	if len(d.Replacement) > 0 {
+		msg = fmt.Sprintf("%s, use %s", msg, d.Replacement)
+	}
+
+	if len(d.Sunset) > 0 {
+		msg = fmt.Sprintf("%s (sunset %s)", msg, d.Sunset)
+	}
+
+	return msg
+}
+
+// DeprecatedKeys returns the deprecated keys sorted by name.
+func (f File) DeprecatedKeys() []string {
+	keys := []string{}
+	for key := range f.Deprecated {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Aliases maps deprecated keys to the keys replacing them.
+func (f File) Aliases() map[string]string {
+	aliases := map[string]string{}
+
+	for key, d := range f.Deprecated {
+		if len(d.Replacement) > 0 && d.Replacement != key {
+			aliases[key] = d.Replacement
+		}
+	}
+
+	return aliases
+}
@@ -0,0 +1,71 @@
+package catalog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSunsetted(t *testing.T) {
+	// arrange
+	d := Deprecation{Replacement: "DATABASE_URL", Sunset: "2019-06-30"}
+
+	tests := map[time.Time]bool{
+		time.Date(2019, 6, 29, 12, 0, 0, 0, time.Local): false,
+		time.Date(2019, 6, 30, 23, 0, 0, 0, time.Local): false,
+		time.Date(2019, 7, 1, 0, 0, 0, 0, time.Local):   true,
+	}
+
+	for now, expected := range tests {
+		// act
+		actual, err := d.Sunsetted(now)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %t for %s \nACTUAL: %t", expected, now, actual)
+		}
+	}
+}
+
+func TestSunsettedWithoutDate(t *testing.T) {
+	// act
+	actual, err := Deprecation{Replacement: "DATABASE_URL"}.Sunsetted(time.Now())
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual {
+		t.Errorf("\nEXPECTED: %t \nACTUAL: %t", false, actual)
+	}
+}
+
+func TestSunsettedInvalidDate(t *testing.T) {
+	// act
+	_, err := Deprecation{Sunset: "June 30"}.Sunsetted(time.Now())
+
+	// assert
+	if err == nil {
+		t.Errorf("\nEXPECTED: error \nACTUAL: nil")
+	}
+}
+
+func TestAliases(t *testing.T) {
+	// arrange
+	f := File{Deprecated: map[string]Deprecation{
+		"DB_URL":   {Replacement: "DATABASE_URL"},
+		"OLD_FLAG": {Sunset: "2019-06-30"},
+	}}
+
+	// act
+	aliases := f.Aliases()
+
+	// assert
+	if len(aliases) != 1 || aliases["DB_URL"] != "DATABASE_URL" {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", map[string]string{"DB_URL": "DATABASE_URL"}, aliases)
+	}
+}
@@ -1,7 +1,11 @@
 package catalog
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/turnerlabs/cstore/components/local"
@@ -9,7 +13,15 @@ import (
 	yaml "gopkg.in/yaml.v2"
 )
 
-const name = "pulls.yml"
+const (
+	name      = "pulls.yml"
+	etagsName = "etags.yml"
+)
+
+type etagRecord struct {
+	ETag     string `yaml:"etag"`
+	Checksum string `yaml:"checksum"`
+}
 
 // RemoveRecords ...
 func (c Catalog) RemoveRecords(fileName string) error {
@@ -29,7 +41,53 @@ func (c Catalog) RemoveRecords(fileName string) error {
 		return err
 	}
 
-	return local.Update(name, "", b)
+	if err := local.Update(name, "", b); err != nil {
+		return err
+	}
+
+	etags := getETags()
+
+	delete(etags, c.ContextKey(fileName))
+
+	return saveETags(etags)
+}
+
+// MoveRecords moves the pull and etag records of a file to a new file
+// key, like after the catalog hash changes.
+func (c Catalog) MoveRecords(oldName, newName string) error {
+	pulls := map[string]time.Time{}
+
+	b, err := local.Get(name, "")
+	if err == nil {
+		if err = yaml.Unmarshal(b, &pulls); err != nil {
+			return err
+		}
+	}
+
+	if pulled, found := pulls[c.ContextKey(oldName)]; found {
+		pulls[c.ContextKey(newName)] = pulled
+		delete(pulls, c.ContextKey(oldName))
+
+		b, err = yaml.Marshal(pulls)
+		if err != nil {
+			return err
+		}
+
+		if err := local.Update(name, "", b); err != nil {
+			return err
+		}
+	}
+
+	etags := getETags()
+
+	for key, record := range etags {
+		if key == c.ContextKey(oldName) || strings.HasPrefix(key, c.ContextKey(oldName)+"/") {
+			etags[c.ContextKey(newName)+strings.TrimPrefix(key, c.ContextKey(oldName))] = record
+			delete(etags, key)
+		}
+	}
+
+	return saveETags(etags)
 }
 
 // RecordPull ...
@@ -56,6 +114,20 @@ func (c Catalog) RecordPull(fileName string, lastPull time.Time) error {
 	return local.Update(name, "", b)
 }
 
+// LastPull returns when the file was last pulled on this machine or
+// zero when it was never pulled.
+func (c Catalog) LastPull(fileName string) time.Time {
+	pulls := map[string]time.Time{}
+
+	if b, err := local.Get(name, ""); err == nil {
+		if err = yaml.Unmarshal(b, &pulls); err != nil {
+			logger.L.Print(err)
+		}
+	}
+
+	return pulls[c.ContextKey(fileName)]
+}
+
 // IsCurrent ...
 func (f File) IsCurrent(lastChange time.Time, context string) bool {
 
@@ -81,3 +153,79 @@ func (f File) IsCurrent(lastChange time.Time, context string) bool {
 
 	return false
 }
+
+// RecordETag saves the remote etag and a checksum of the local
+// contents of a pulled file.
+func (c Catalog) RecordETag(fileName, version, etag string, data []byte) error {
+	etags := getETags()
+
+	etags[etagKey(c.ContextKey(fileName), version)] = etagRecord{
+		ETag:     etag,
+		Checksum: checksum(data),
+	}
+
+	return saveETags(etags)
+}
+
+// IsUnchanged returns true when the remote etag matches the etag
+// recorded during the last pull and the local file has not been
+// modified since.
+func (c Catalog) IsUnchanged(fileName, version, etag string, data []byte) bool {
+	if len(etag) == 0 {
+		return false
+	}
+
+	record, found := getETags()[etagKey(c.ContextKey(fileName), version)]
+	if !found {
+		return false
+	}
+
+	return record.ETag == etag && record.Checksum == checksum(data)
+}
+
+// PulledETag returns the remote etag recorded when the file was last
+// pulled or pushed on this machine.
+func (c Catalog) PulledETag(fileName, version string) (string, bool) {
+	record, found := getETags()[etagKey(c.ContextKey(fileName), version)]
+	if !found || len(record.ETag) == 0 {
+		return "", false
+	}
+
+	return record.ETag, true
+}
+
+func getETags() map[string]etagRecord {
+	etags := map[string]etagRecord{}
+
+	b, err := local.Get(etagsName, "")
+	if err != nil {
+		return etags
+	}
+
+	if err = yaml.Unmarshal(b, &etags); err != nil {
+		logger.L.Print(err)
+	}
+
+	return etags
+}
+
+func saveETags(etags map[string]etagRecord) error {
+	b, err := yaml.Marshal(etags)
+	if err != nil {
+		return err
+	}
+
+	return local.Update(etagsName, "", b)
+}
+
+func etagKey(key, version string) string {
+	if len(version) > 0 {
+		return fmt.Sprintf("%s/%s", key, version)
+	}
+	return key
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,5 +1,7 @@
 package catalog
 
+import "github.com/turnerlabs/cstore/components/cfg"
+
 func keepFilesWithVersion(files map[string]File, version string) map[string]File {
 	filtered := map[string]File{}
 
@@ -35,9 +37,17 @@ func keepFilesWithPaths(files map[string]File, paths []string) map[string]File {
 }
 
 func keepFilesWithTags(files map[string]File, tags []string, allTags bool) map[string]File {
+	if allTags {
+		return keepFilesMatching(files, cfg.AllTags(tags))
+	}
+
+	return keepFilesMatching(files, cfg.AnyTags(tags))
+}
+
+func keepFilesMatching(files map[string]File, tags cfg.TagExpression) map[string]File {
 	filtered := map[string]File{}
 
-	if len(tags) == 0 {
+	if tags.IsEmpty() {
 		return files
 	}
 
@@ -48,44 +58,10 @@ func keepFilesWithTags(files map[string]File, tags []string, allTags bool) map[s
 	}
 
 	for key, file := range files {
-		if allTags {
-			if allTagsMatch(file.Tags, tags) {
-				filtered[key] = file
-			}
-		} else {
-			if anyTagMatches(file.Tags, tags) {
-				filtered[key] = file
-			}
+		if tags.Matches(file.Tags) {
+			filtered[key] = file
 		}
 	}
 
 	return filtered
 }
-
-func allTagsMatch(tags, targetTags []string) bool {
-	for _, t := range targetTags {
-		matches := false
-
-		for _, tl := range tags {
-			if tl == t {
-				matches = true
-			}
-		}
-
-		if !matches {
-			return false
-		}
-	}
-	return true
-}
-
-func anyTagMatches(tags []string, targetTags []string) bool {
-	for _, lt := range targetTags {
-		for _, t := range tags {
-			if lt == t {
-				return true
-			}
-		}
-	}
-	return false
-}
@@ -2,6 +2,8 @@ package catalog
 
 import (
 	"testing"
+
+	"github.com/turnerlabs/cstore/components/cfg"
 )
 
 func TestWhenAnyTagIsFoundReturnFile(t *testing.T) {
@@ -171,3 +173,27 @@ func TestWhenNoTagsArePassedAllFilesShouldMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestFilesMatchingTagExpression(t *testing.T) {
+	// arrange
+	c := Catalog{
+		Files: map[string]File{
+			"web":    {Path: "web/.env", Tags: []string{"web", "dev"}},
+			"legacy": {Path: "legacy/.env", Tags: []string{"web", "legacy"}},
+			"api":    {Path: "api/.env", Tags: []string{"api"}},
+		},
+	}
+
+	tags, err := cfg.ParseTagExpression("web and not legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	results := c.FilesMatching([]string{}, tags, "")
+
+	// assert
+	if _, found := results["web"]; len(results) != 1 || !found {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "web/.env", results)
+	}
+}
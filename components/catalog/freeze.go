@@ -0,0 +1,76 @@
+package catalog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/schedule"
+)
+
+// maxFreezeDuration limits how long a single freeze can last.
+const maxFreezeDuration = 31 * 24 * time.Hour
+
+// Freeze is a recurring window when protected files cannot be pushed
+// without breaking glass.
+type Freeze struct {
+	// Name describes the freeze, like "weekend release freeze".
+	Name string `yaml:"name"`
+
+	// Schedule is a cron expression for when the freeze starts, like
+	// "0 18 * * 5" for Fridays at 18:00.
+	Schedule string `yaml:"schedule"`
+
+	// Duration is how long the freeze lasts after it starts, like "60h".
+	Duration string `yaml:"duration"`
+
+	// Zone is the time zone the schedule uses, like "America/New_York".
+	// Schedules without a zone use UTC.
+	Zone string `yaml:"zone,omitempty"`
+}
+
+// ActiveFreeze returns the freeze in effect at the time and when it
+// ends.
+func (c Catalog) ActiveFreeze(t time.Time) (Freeze, time.Time, bool, error) {
+	for _, f := range c.Freezes {
+		start, active, err := f.startedBefore(t)
+		if err != nil {
+			return f, time.Time{}, false, fmt.Errorf("invalid freeze %s (%s)", f.Name, err)
+		}
+
+		if active {
+			d, _ := time.ParseDuration(f.Duration)
+			return f, start.Add(d), true, nil
+		}
+	}
+
+	return Freeze{}, time.Time{}, false, nil
+}
+
+// startedBefore returns when the freeze started if it is in effect at
+// the time.
+func (f Freeze) startedBefore(t time.Time) (time.Time, bool, error) {
+	cron, err := schedule.Parse(f.Schedule)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	d, err := time.ParseDuration(f.Duration)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if d <= 0 || d > maxFreezeDuration {
+		return time.Time{}, false, fmt.Errorf("duration %s must be between 1m and %s", f.Duration, maxFreezeDuration)
+	}
+
+	zone := time.UTC
+	if len(f.Zone) > 0 {
+		if zone, err = time.LoadLocation(f.Zone); err != nil {
+			return time.Time{}, false, err
+		}
+	}
+
+	start, active := cron.Within(t.In(zone), d)
+
+	return start, active, nil
+}
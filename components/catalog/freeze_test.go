@@ -0,0 +1,53 @@
+package catalog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActiveFreeze(t *testing.T) {
+	// arrange
+	c := Catalog{
+		Freezes: []Freeze{
+			Freeze{Name: "weekend", Schedule: "0 18 * * 5", Duration: "60h"},
+		},
+	}
+
+	saturday := time.Date(2019, 3, 9, 12, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2019, 3, 12, 12, 0, 0, 0, time.UTC)
+
+	// act
+	f, ends, active, err := c.ActiveFreeze(saturday)
+	_, _, later, _ := c.ActiveFreeze(tuesday)
+
+	// assert
+	if err != nil || !active || f.Name != "weekend" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s %t %v", "weekend", f.Name, active, err)
+	}
+
+	expected := time.Date(2019, 3, 11, 6, 0, 0, 0, time.UTC)
+	if !ends.Equal(expected) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, ends)
+	}
+
+	if later {
+		t.Errorf("\nEXPECTED: %t \nACTUAL: %t", false, later)
+	}
+}
+
+func TestActiveFreezeInvalid(t *testing.T) {
+	// arrange
+	c := Catalog{
+		Freezes: []Freeze{
+			Freeze{Name: "forever", Schedule: "0 0 * * *", Duration: "8760h"},
+		},
+	}
+
+	// act
+	_, _, _, err := c.ActiveFreeze(time.Now())
+
+	// assert
+	if err == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "error", err)
+	}
+}
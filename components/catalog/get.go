@@ -17,11 +17,11 @@ import (
 func GetMake(catalogName string, io models.IO) (Catalog, error) {
 
 	c, err := Get(catalogName)
-	if err != nil {
-		c = create(io)
+	if os.IsNotExist(err) {
+		return create(io)
 	}
 
-	return c, nil
+	return c, err
 }
 
 // Get ...
@@ -32,7 +32,9 @@ func Get(catalogName string) (Catalog, error) {
 		CWD: g.Location,
 	}
 
-	b, err := ioutil.ReadFile(fmt.Sprintf("%s%s", c.Location(), catalogName))
+	fullPath := fmt.Sprintf("%s%s", c.Location(), catalogName)
+
+	b, err := ioutil.ReadFile(fullPath)
 	if err == nil {
 		if err = yaml.Unmarshal(b, &c); err != nil {
 			return c, err
@@ -45,6 +47,25 @@ func Get(catalogName string) (Catalog, error) {
 		if c.Files == nil {
 			c.Files = map[string]File{}
 		}
+
+		if !IsHash(c.HashAlgorithm()) {
+			return c, fmt.Errorf("unknown hash %s in %s, use %s", c.Hash, catalogName, strings.Join(Hashes, " or "))
+		}
+
+		c.applyHashes()
+
+		if err := c.checkData(catalogName); err != nil {
+			return c, err
+		}
+
+		if err := c.checkDependencies(catalogName); err != nil {
+			return c, err
+		}
+
+		if context, found := selectedContext(fullPath); found && context != c.Context && c.Knows(context) {
+			c.defaultContext = c.Context
+			c.Context = context
+		}
 	}
 
 	return c, err
@@ -0,0 +1,94 @@
+package catalog
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	// HashMD5 is the legacy algorithm used to build file keys when a
+	// catalog does not specify one.
+	HashMD5 = "md5"
+
+	// HashSHA256 builds file keys from a SHA-256 digest truncated to
+	// the length of the legacy keys.
+	HashSHA256 = "sha256"
+)
+
+// Hashes lists the supported hash algorithms.
+var Hashes = []string{HashMD5, HashSHA256}
+
+// IsHash ...
+func IsHash(hash string) bool {
+	for _, h := range Hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// HashAlgorithm returns the algorithm used to build the keys of files
+// added to the catalog.
+func (c Catalog) HashAlgorithm() string {
+	if len(c.Hash) == 0 {
+		return HashMD5
+	}
+	return c.Hash
+}
+
+// HashedWith returns a copy of the file keyed using the algorithm.
+func (f File) HashedWith(hash string) File {
+	f.hash = hash
+	return f
+}
+
+// HashAlgorithm returns the algorithm used to build the file's key.
+func (f File) HashAlgorithm() string {
+	if len(f.hash) == 0 {
+		return HashMD5
+	}
+	return f.hash
+}
+
+// applyHashes records the algorithm each file was keyed with. Files
+// keyed with the legacy algorithm keep it until they are rehashed;
+// so, catalogs can be migrated one file at a time.
+func (c *Catalog) applyHashes() {
+	for key, file := range c.Files {
+		file.hash = c.HashAlgorithm()
+
+		if key != hashPath(c.HashAlgorithm(), file.Path) && key == hashPath(HashMD5, file.Path) {
+			file.hash = HashMD5
+		}
+
+		c.Files[key] = file
+	}
+}
+
+// lookup returns the key of the file entry for a path, checking legacy
+// keys when the entry is not found.
+func (c Catalog) lookup(path string) (string, bool) {
+	for _, hash := range []string{c.HashAlgorithm(), HashMD5} {
+		if _, found := c.Files[hashPath(hash, path)]; found {
+			return hashPath(hash, path), true
+		}
+	}
+
+	return "", false
+}
+
+func hashPath(hash, path string) string {
+	switch hash {
+	case HashSHA256:
+		sum := sha256.Sum256([]byte(path))
+		return hex.EncodeToString(sum[:])[:32]
+	case HashMD5, "":
+		sum := md5.Sum([]byte(path))
+		return hex.EncodeToString(sum[:])
+	default:
+		panic(fmt.Sprintf("unknown hash algorithm %s", hash))
+	}
+}
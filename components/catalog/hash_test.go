@@ -0,0 +1,52 @@
+package catalog
+
+import (
+	"testing"
+)
+
+func TestHashPath(t *testing.T) {
+	// act
+	legacy := hashPath(HashMD5, "service/dev/.env")
+	current := hashPath(HashSHA256, "service/dev/.env")
+
+	// assert
+	if len(current) != len(legacy) {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %d", len(legacy), len(current))
+	}
+
+	if current == legacy {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "different keys", current)
+	}
+}
+
+func TestLegacyKeys(t *testing.T) {
+	// arrange
+	legacy := File{Path: "legacy/.env"}
+	c := Catalog{
+		Hash: HashSHA256,
+		Files: map[string]File{
+			hashPath(HashMD5, "legacy/.env"):     legacy,
+			hashPath(HashSHA256, "current/.env"): {Path: "current/.env"},
+		},
+	}
+
+	// act
+	c.applyHashes()
+
+	legacyEntry, legacyFound := c.LookupEntry("legacy/.env", []byte{})
+	currentEntry, currentFound := c.LookupEntry("current/.env", []byte{})
+	newEntry, _ := c.LookupEntry("new/.env", []byte{})
+
+	// assert
+	if !legacyFound || legacyEntry.HashAlgorithm() != HashMD5 || legacyEntry.Key() != hashPath(HashMD5, "legacy/.env") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s (found %t)", HashMD5, legacyEntry.HashAlgorithm(), legacyFound)
+	}
+
+	if !currentFound || currentEntry.HashAlgorithm() != HashSHA256 {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s (found %t)", HashSHA256, currentEntry.HashAlgorithm(), currentFound)
+	}
+
+	if newEntry.Key() != hashPath(HashSHA256, "new/.env") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", hashPath(HashSHA256, "new/.env"), newEntry.Key())
+	}
+}
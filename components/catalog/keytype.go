@@ -0,0 +1,99 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key types describe how a key's value must be treated, regardless of
+// the store it is pushed to. Stores map them to their own types.
+const (
+	// KeyTypePlain values are not sensitive and can be readable in the
+	// store.
+	KeyTypePlain = "plain"
+
+	// KeyTypeSecret values are sensitive and must be protected.
+	KeyTypeSecret = "secret"
+
+	// KeyTypeReference values point to a value kept elsewhere, like an
+	// ARN or a service discovery name.
+	KeyTypeReference = "reference"
+
+	// KeyTypeGenerated values are sensitive values created by a tool,
+	// like a random password, and are protected like secrets.
+	KeyTypeGenerated = "generated"
+)
+
+// KeyTypes lists the supported key types.
+var KeyTypes = []string{KeyTypePlain, KeyTypeSecret, KeyTypeReference, KeyTypeGenerated}
+
+// keyTypeNames maps the type names stores display to key types; so,
+// types can be set using the names seen in a store.
+var keyTypeNames = map[string]string{
+	"basic":        KeyTypePlain,
+	"discover":     KeyTypeReference,
+	"hidden":       KeyTypeSecret,
+	"string":       KeyTypePlain,
+	"securestring": KeyTypeSecret,
+}
+
+// ParseKeyType returns the key type named by a key type or a store
+// type, like Harbor's "hidden".
+func ParseKeyType(name string) (string, error) {
+	t := strings.ToLower(strings.TrimSpace(name))
+
+	if IsKeyType(t) {
+		return t, nil
+	}
+
+	if keyType, found := keyTypeNames[t]; found {
+		return keyType, nil
+	}
+
+	return "", fmt.Errorf("unknown key type %s, use %s", name, strings.Join(KeyTypes, ", "))
+}
+
+// IsKeyType ...
+func IsKeyType(keyType string) bool {
+	for _, t := range KeyTypes {
+		if t == keyType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeclaredKeyType returns the key type declared in the catalog for a
+// key and whether one was declared.
+func (f File) DeclaredKeyType(key string) (string, bool) {
+	t, found := f.KeyTypes[key]
+	return strings.ToLower(t), found
+}
+
+// KeyType returns the key type declared for a key. Undeclared keys are
+// secrets.
+func (f File) KeyType(key string) string {
+	if t, found := f.DeclaredKeyType(key); found {
+		return t
+	}
+	return KeyTypeSecret
+}
+
+// CheckKeyTypes returns an error when a key type declared in the
+// catalog is not supported.
+func (f File) CheckKeyTypes() error {
+	keys := []string{}
+	for key := range f.KeyTypes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if t, _ := f.DeclaredKeyType(key); !IsKeyType(t) {
+			return fmt.Errorf("unknown key type %s for %s, use %s", f.KeyTypes[key], key, strings.Join(KeyTypes, ", "))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package catalog
+
+import (
+	"testing"
+)
+
+func TestKeyType(t *testing.T) {
+	// arrange
+	f := File{
+		KeyTypes: map[string]string{
+			"URL":     "plain",
+			"DB_HOST": "Reference",
+		},
+	}
+
+	tests := map[string]string{
+		"URL":         KeyTypePlain,
+		"DB_HOST":     KeyTypeReference,
+		"DB_PASSWORD": KeyTypeSecret,
+	}
+
+	for key, expected := range tests {
+		// act
+		actual := f.KeyType(key)
+
+		// assert
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+		}
+	}
+}
+
+func TestCheckKeyTypes(t *testing.T) {
+	// arrange
+	valid := File{KeyTypes: map[string]string{"URL": "plain", "TOKEN": "generated"}}
+	invalid := File{KeyTypes: map[string]string{"URL": "hidden"}}
+
+	// act
+	validErr := valid.CheckKeyTypes()
+	invalidErr := invalid.CheckKeyTypes()
+
+	// assert
+	if validErr != nil {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %s", nil, validErr)
+	}
+
+	if invalidErr == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "unknown key type error", invalidErr)
+	}
+}
+
+func TestParseKeyType(t *testing.T) {
+	// arrange
+	tests := map[string]string{
+		"secret":       KeyTypeSecret,
+		"Plain":        KeyTypePlain,
+		"hidden":       KeyTypeSecret,
+		"basic":        KeyTypePlain,
+		"discover":     KeyTypeReference,
+		"SecureString": KeyTypeSecret,
+	}
+
+	for name, expected := range tests {
+		// act
+		actual, err := ParseKeyType(name)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+		}
+	}
+
+	if _, err := ParseKeyType("public"); err == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "unknown key type error", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/turnerlabs/cstore/components/cfg"
 	"github.com/turnerlabs/cstore/components/models"
 	"github.com/turnerlabs/cstore/components/path"
 )
@@ -26,9 +27,56 @@ type Catalog struct {
 	Version string `yaml:"version"`
 	Context string `yaml:"context"`
 
+	// Hash is the algorithm used to build file keys from file paths.
+	// Catalogs without a hash use the legacy md5 keys.
+	Hash string `yaml:"hash,omitempty"`
+
+	// Contexts lists additional contexts the catalog's files can be
+	// stored under. The context used is selected per working copy.
+	Contexts []string `yaml:"contexts,omitempty"`
+
+	// defaultContext is the context saved in the catalog file when a
+	// different context is selected.
+	defaultContext string
+
+	// warnings are problems found loading the catalog.
+	warnings []string
+
+	Quotas Quotas `yaml:"quotas,omitempty"`
+
+	Audit Audit `yaml:"audit,omitempty"`
+
+	// Freezes are recurring windows when protected files cannot be
+	// pushed without breaking glass.
+	Freezes []Freeze `yaml:"freezes,omitempty"`
+
+	// Prompts saves non-secret prompt answers, like regions and bucket
+	// names, so others using the catalog are not asked again.
+	Prompts map[string]string `yaml:"prompts,omitempty"`
+
+	// Redact lists regular expressions, like account IDs and internal
+	// host names, replaced in all output and error messages.
+	Redact []string `yaml:"redact,omitempty"`
+
+	// ChangeSets name groups of file paths pushed together.
+	ChangeSets map[string][]string `yaml:"changeSets,omitempty"`
+
+	// Compose maps docker-compose services to the files exported to
+	// each service's env_file.
+	Compose Compose `yaml:"compose,omitempty"`
+
+	// Assemble maps the paths of files built at pull time to the
+	// fragments and template they are built from.
+	Assemble map[string]Assembly `yaml:"assemble,omitempty"`
+
 	Files map[string]File `yaml:"files"`
 }
 
+// Audit configures where access to protected files is reported.
+type Audit struct {
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
 // Vault ...
 type Vault struct {
 	Access  string `yaml:"access,omitempty"`
@@ -68,16 +116,126 @@ type File struct {
 
 	// Versions stores an identifier for user versioned copies of the data.
 	Versions []string `ymal:"versions,omitempty"`
+
+	// DependsOn lists the paths of files pushed and pulled before this
+	// file, like shared configuration the file refers to.
+	DependsOn []string `yaml:"dependsOn,omitempty"`
+
+	// Pinned is the store revision pulled instead of the latest state
+	// of the file until it is unpinned.
+	Pinned string `yaml:"pinned,omitempty"`
+
+	// Transforms lists the transformations applied to key values
+	// when the file is pushed or pulled.
+	Transforms Transforms `yaml:"transforms,omitempty"`
+
+	// Pipeline lists the stages, like gzip and aes, the file is passed
+	// through before it is pushed and in reverse after it is pulled.
+	Pipeline []string `yaml:"pipeline,omitempty"`
+
+	// Encryption lists the recipients able to decrypt the file when
+	// the envelope pipeline stage encrypts it.
+	Encryption Encryption `yaml:"encryption,omitempty"`
+
+	// Protected requires a justification to pull the file that is
+	// reported to the catalog's audit endpoint.
+	Protected bool `yaml:"protected,omitempty"`
+
+	// KeyTypes maps keys to their key type, like plain or secret, so
+	// every store treats the values consistently.
+	KeyTypes map[string]string `yaml:"keyTypes,omitempty"`
+
+	// ValueTypes maps keys to the type of their value, like int or
+	// bool, so pushes validate values and exports emit typed values.
+	ValueTypes map[string]string `yaml:"valueTypes,omitempty"`
+
+	// Flatten saves json, yaml, and toml files in key/value stores as
+	// a key for each value named by its dotted path, like db.port.
+	Flatten bool `yaml:"flatten,omitempty"`
+
+	// EncryptedKeys maps keys whose values are encrypted before they
+	// are pushed to the recipients, like kms:alias/my-app, able to
+	// decrypt them. Other values stay readable in the store.
+	EncryptedKeys map[string]string `yaml:"encryptedKeys,omitempty"`
+
+	// Roles maps role names to the keys each role can read. Policies
+	// generated for the roles grant access to those keys only.
+	Roles map[string][]string `yaml:"roles,omitempty"`
+
+	// Deprecated maps keys being renamed to their replacement and the
+	// date the old name stops being supported.
+	Deprecated map[string]Deprecation `yaml:"deprecated,omitempty"`
+
+	// Rename maps stored keys to the names used locally, like
+	// DATABASE_URL to SPRING_DATASOURCE_URL. Keys are renamed when the
+	// file is pulled and stored under their original names when pushed.
+	Rename map[string]string `yaml:"rename,omitempty"`
+
+	// Render marks files stored as Go templates. Placeholders are
+	// resolved from the environment, stores, and vaults when pulled.
+	Render bool `yaml:"render,omitempty"`
+
+	// Hooks lists local commands run when the file is pushed or pulled.
+	Hooks Hooks `yaml:"hooks,omitempty"`
+
+	// Owner identifies the person or team responsible for the file.
+	Owner string `yaml:"owner,omitempty"`
+
+	// Rotation declares how often the file or its keys must be
+	// rotated.
+	Rotation Rotation `yaml:"rotation,omitempty"`
+
+	// hash is the algorithm used to build the file's key.
+	hash string
+}
+
+// Hooks lists the commands run for a file.
+type Hooks struct {
+	// PrePush commands run before the file is pushed. A command
+	// exiting with a non-zero status aborts the push.
+	PrePush []string `yaml:"prePush,omitempty"`
+
+	// PostPull commands run after the file is written by a pull.
+	PostPull []string `yaml:"postPull,omitempty"`
+}
+
+// Encryption records the recipients whose keys wrap the data key of an
+// envelope encrypted file.
+type Encryption struct {
+	Recipients []Recipient `yaml:"recipients,omitempty"`
+}
+
+// Recipient is a key able to unwrap a data key, like a KMS key id or an
+// age public key. Passphrase recipients have no id.
+type Recipient struct {
+	Type string `yaml:"type"`
+	ID   string `yaml:"id,omitempty"`
+}
+
+// String ...
+func (r Recipient) String() string {
+	if len(r.ID) == 0 {
+		return r.Type
+	}
+
+	return r.Type + ":" + r.ID
+}
+
+// Transforms maps keys to the transformations applied to their values
+// in the order listed.
+type Transforms struct {
+	Push map[string][]string `yaml:"push,omitempty"`
+	Pull map[string][]string `yaml:"pull,omitempty"`
 }
 
 // Key ...
 func (f File) Key() string {
-	return hashPath(f.Path)
+	return hashPath(f.hash, f.Path)
 }
 
 // ContextKey ...
 func (f File) ContextKey(context string) string {
-	return buildKey(context, hashPath(f.Path))
+	return buildKey(context, f.Key())
 }
 
 // SupportsSecrets ...
@@ -204,8 +362,18 @@ func (c Catalog) GetTagsBy(path string) []string {
 
 // GetPathsBy ...
 func (c Catalog) GetPathsBy(tags []string, all bool) []string {
+	if all {
+		return c.GetPathsMatching(cfg.AllTags(tags))
+	}
+
+	return c.GetPathsMatching(cfg.AnyTags(tags))
+}
+
+// GetPathsMatching returns the paths of files with tags matching the
+// expression.
+func (c Catalog) GetPathsMatching(tags cfg.TagExpression) []string {
 	paths := []string{}
-	for _, file := range keepFilesWithTags(c.Files, tags, all) {
+	for _, file := range keepFilesMatching(c.Files, tags) {
 		paths = append(paths, file.Path)
 	}
 
@@ -247,10 +415,20 @@ func (c Catalog) GetFullPath(path string) string {
 
 // FilesBy ...
 func (c Catalog) FilesBy(paths, tags []string, allTags bool, version string) map[string]File {
+	if allTags {
+		return c.FilesMatching(paths, cfg.AllTags(tags), version)
+	}
+
+	return c.FilesMatching(paths, cfg.AnyTags(tags), version)
+}
+
+// FilesMatching returns the files with the paths and tags matching the
+// expression that have the version. Linked catalogs are always returned.
+func (c Catalog) FilesMatching(paths []string, tags cfg.TagExpression, version string) map[string]File {
 
 	filtered := keepFilesWithPaths(c.Files, paths)
 
-	filtered = keepFilesWithTags(filtered, tags, allTags)
+	filtered = keepFilesMatching(filtered, tags)
 
 	filtered = keepFilesWithVersion(filtered, version)
 
@@ -283,11 +461,11 @@ func (c *Catalog) Exists(file File) bool {
 // LookupEntry ...
 func (c *Catalog) LookupEntry(path string, data []byte) (File, bool) {
 
-	if file, found := c.Files[hashPath(path)]; found && !file.IsRef {
-		return file, true
+	if key, found := c.lookup(path); found && !c.Files[key].IsRef {
+		return c.Files[key], true
 	}
 
-	return createNew(path, data), false
+	return createNew(path, data).HashedWith(c.HashAlgorithm()), false
 }
 
 // UpdateEntry adds the new entry returning the modified
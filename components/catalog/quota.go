@@ -0,0 +1,119 @@
+package catalog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/subosito/gotenv"
+)
+
+// Quotas limit what can be pushed using a catalog. A zero value
+// disables the limit.
+type Quotas struct {
+	// MaxFileSize is the largest file that can be pushed, like "64KB".
+	MaxFileSize string `yaml:"maxFileSize,omitempty"`
+
+	// MaxKeys is the most keys an env file can contain.
+	MaxKeys int `yaml:"maxKeys,omitempty"`
+
+	// MaxEntries is the most files the catalog can contain.
+	MaxEntries int `yaml:"maxEntries,omitempty"`
+}
+
+// QuotaError is returned when a push exceeds a catalog quota.
+type QuotaError struct {
+	Quota  string
+	Limit  string
+	Actual string
+}
+
+func (e QuotaError) Error() string {
+	return fmt.Sprintf("%s of %s exceeds the catalog quota of %s", e.Quota, e.Actual, e.Limit)
+}
+
+// CheckQuotas returns an error when pushing the file would exceed the
+// catalog quotas.
+func (c Catalog) CheckQuotas(file File, data []byte) error {
+	q := c.Quotas
+
+	if len(q.MaxFileSize) > 0 {
+		limit, err := ParseSize(q.MaxFileSize)
+		if err != nil {
+			return fmt.Errorf("invalid maxFileSize quota (%s)", err)
+		}
+
+		if int64(len(data)) > limit {
+			return QuotaError{Quota: "file size", Limit: FormatSize(limit), Actual: FormatSize(int64(len(data)))}
+		}
+	}
+
+	if q.MaxKeys > 0 && file.SupportsConfig() {
+		if keys := len(gotenv.Parse(bytes.NewReader(data))); keys > q.MaxKeys {
+			return QuotaError{Quota: "key count", Limit: strconv.Itoa(q.MaxKeys), Actual: strconv.Itoa(keys)}
+		}
+	}
+
+	if q.MaxEntries > 0 {
+		entries := 0
+		for _, f := range c.Files {
+			if !f.IsRef {
+				entries++
+			}
+		}
+
+		if !c.Exists(file) {
+			entries++
+		}
+
+		if entries > q.MaxEntries {
+			return QuotaError{Quota: "catalog entries", Limit: strconv.Itoa(q.MaxEntries), Actual: strconv.Itoa(entries)}
+		}
+	}
+
+	return nil
+}
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize converts a size like "64KB" or "1MB" to bytes. A number
+// without a unit is bytes.
+func ParseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), 64)
+			if err != nil {
+				return 0, fmt.Errorf("unknown size %s", size)
+			}
+			return int64(n * float64(u.bytes)), nil
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unknown size %s", size)
+	}
+
+	return n, nil
+}
+
+// FormatSize converts bytes to a readable size.
+func FormatSize(bytes int64) string {
+	for _, u := range sizeUnits {
+		if bytes >= u.bytes && u.bytes > 1 {
+			return fmt.Sprintf("%.1f %s", float64(bytes)/float64(u.bytes), u.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", bytes)
+}
@@ -0,0 +1,73 @@
+package catalog
+
+import (
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"512":   512,
+		"64KB":  64 * 1024,
+		"1.5MB": 1572864,
+		"2 gb":  2 * 1024 * 1024 * 1024,
+	}
+
+	for size, expected := range tests {
+		// act
+		actual, err := ParseSize(size)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %d \nACTUAL: %d", expected, actual)
+		}
+	}
+}
+
+func TestCheckQuotas(t *testing.T) {
+	// arrange
+	existing := File{Path: "a/.env", Type: "env"}
+	added := File{Path: "b/.env", Type: "env"}
+
+	c := Catalog{
+		Quotas: Quotas{
+			MaxFileSize: "20B",
+			MaxKeys:     2,
+			MaxEntries:  1,
+		},
+		Files: map[string]File{
+			existing.Key(): existing,
+		},
+	}
+
+	tests := []struct {
+		file  File
+		data  string
+		quota string
+	}{
+		{existing, "A=1\nB=2\n", ""},
+		{existing, "A=1\nB=2\nC=3\n", "key count"},
+		{existing, "A=123456789012345678901234567890\n", "file size"},
+		{added, "A=1\n", "catalog entries"},
+	}
+
+	for _, test := range tests {
+		// act
+		err := c.CheckQuotas(test.file, []byte(test.data))
+
+		// assert
+		quota := ""
+		if qerr, ok := err.(QuotaError); ok {
+			quota = qerr.Quota
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		if quota != test.quota {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", test.quota, quota)
+		}
+	}
+}
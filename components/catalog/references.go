@@ -0,0 +1,67 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+)
+
+// KeyReferences maps each key named by the file's catalog settings,
+// like key types and transforms, to the settings naming it.
+func (f File) KeyReferences() map[string][]string {
+	refs := map[string][]string{}
+
+	add := func(section string, keys map[string]string) {
+		for key := range keys {
+			refs[key] = append(refs[key], section)
+		}
+	}
+
+	add("keyTypes", f.KeyTypes)
+	add("valueTypes", f.ValueTypes)
+	add("encryptedKeys", f.EncryptedKeys)
+	add("rotation.keys", f.Rotation.Keys)
+	add("rename", f.Rename)
+
+	for key := range f.Transforms.Push {
+		refs[key] = append(refs[key], "transforms.push")
+	}
+
+	for key := range f.Transforms.Pull {
+		refs[key] = append(refs[key], "transforms.pull")
+	}
+
+	for role, keys := range f.Roles {
+		for _, key := range keys {
+			refs[key] = append(refs[key], fmt.Sprintf("roles.%s", role))
+		}
+	}
+
+	for key := range refs {
+		sort.Strings(refs[key])
+	}
+
+	return refs
+}
+
+// RemoveKeyReferences removes a key from every catalog setting naming
+// it. Deprecations are kept since deprecated keys are expected to be
+// removed from the file.
+func (f *File) RemoveKeyReferences(key string) {
+	delete(f.KeyTypes, key)
+	delete(f.ValueTypes, key)
+	delete(f.EncryptedKeys, key)
+	delete(f.Rotation.Keys, key)
+	delete(f.Rename, key)
+	delete(f.Transforms.Push, key)
+	delete(f.Transforms.Pull, key)
+
+	for role, keys := range f.Roles {
+		kept := []string{}
+		for _, k := range keys {
+			if k != key {
+				kept = append(kept, k)
+			}
+		}
+		f.Roles[role] = kept
+	}
+}
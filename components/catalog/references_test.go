@@ -0,0 +1,44 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyReferences(t *testing.T) {
+	// arrange
+	f := File{
+		KeyTypes:      map[string]string{"URL": "plain", "OLD": "secret"},
+		ValueTypes:    map[string]string{"PORT": "int"},
+		EncryptedKeys: map[string]string{"OLD": "passphrase"},
+		Transforms:    Transforms{Pull: map[string][]string{"OLD": {"trim"}}},
+		Roles:         map[string][]string{"web": {"URL", "OLD"}},
+		Deprecated:    map[string]Deprecation{"OLD": {Replacement: "URL"}},
+	}
+
+	// act
+	refs := f.KeyReferences()
+
+	// assert
+	expected := []string{"encryptedKeys", "keyTypes", "roles.web", "transforms.pull"}
+
+	if !reflect.DeepEqual(refs["OLD"], expected) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected, refs["OLD"])
+	}
+
+	// act
+	f.RemoveKeyReferences("OLD")
+
+	// assert
+	if _, found := f.KeyReferences()["OLD"]; found {
+		t.Errorf("expected OLD references to be removed, got %v", f.KeyReferences()["OLD"])
+	}
+
+	if !reflect.DeepEqual(f.Roles["web"], []string{"URL"}) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", []string{"URL"}, f.Roles["web"])
+	}
+
+	if _, found := f.Deprecated["OLD"]; !found {
+		t.Error("expected the deprecation to be kept")
+	}
+}
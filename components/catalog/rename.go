@@ -0,0 +1,40 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Renames returns the names used locally for stored keys, or the
+// stored names of local keys when reversed, after checking each key is
+// renamed to a distinct name.
+func (f File) Renames(reverse bool) (map[string]string, error) {
+	names := map[string]string{}
+
+	keys := []string{}
+	for key := range f.Rename {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := f.Rename[key]
+
+		if len(key) == 0 || len(name) == 0 {
+			return nil, fmt.Errorf("rename of %q to %q needs both names", key, name)
+		}
+
+		from, to := key, name
+		if reverse {
+			from, to = name, key
+		}
+
+		if other, found := names[from]; found {
+			return nil, fmt.Errorf("%s and %s cannot both be renamed %s", other, to, from)
+		}
+
+		names[from] = to
+	}
+
+	return names, nil
+}
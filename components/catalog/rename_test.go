@@ -0,0 +1,39 @@
+package catalog
+
+import (
+	"testing"
+)
+
+func TestRenamesReverse(t *testing.T) {
+	// arrange
+	f := File{Rename: map[string]string{"DATABASE_URL": "SPRING_DATASOURCE_URL"}}
+
+	// act
+	names, err := f.Renames(true)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names["SPRING_DATASOURCE_URL"] != "DATABASE_URL" {
+		t.Errorf("\nEXPECTED: DATABASE_URL \nACTUAL: %s", names["SPRING_DATASOURCE_URL"])
+	}
+}
+
+func TestRenamesToTheSameNameFail(t *testing.T) {
+	// arrange
+	f := File{Rename: map[string]string{"DB_URL": "URL", "API_URL": "URL"}}
+
+	// act
+	_, err := f.Renames(false)
+
+	// assert
+	if err != nil {
+		t.Errorf("keys renamed to the same name locally should only fail when reversed")
+	}
+
+	if _, err := f.Renames(true); err == nil {
+		t.Errorf("expected an error for two keys renamed URL")
+	}
+}
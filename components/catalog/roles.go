@@ -0,0 +1,59 @@
+package catalog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	roleRegex       = regexp.MustCompile(`^[A-Za-z0-9+=,.@_-]+$`)
+	keyPatternRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]*\*?$`)
+)
+
+// RoleNames returns the sorted names of the roles declared for the
+// file.
+func (f File) RoleNames() []string {
+	roles := []string{}
+	for role := range f.Roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	return roles
+}
+
+// CanRead returns true when a key is declared readable by the role.
+// Patterns ending with '*' match every key starting with the prefix.
+func (f File) CanRead(role, key string) bool {
+	for _, pattern := range f.Roles[role] {
+		if pattern == key || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(key, strings.TrimSuffix(pattern, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckRoles returns an error when a role or key pattern declared in
+// the catalog cannot be used in a policy.
+func (f File) CheckRoles() error {
+	for _, role := range f.RoleNames() {
+		if !roleRegex.MatchString(role) {
+			return fmt.Errorf("invalid role name %s", role)
+		}
+
+		if len(f.Roles[role]) == 0 {
+			return fmt.Errorf("no keys declared for role %s", role)
+		}
+
+		for _, pattern := range f.Roles[role] {
+			if len(pattern) == 0 || !keyPatternRegex.MatchString(pattern) {
+				return fmt.Errorf("invalid key pattern %s for role %s, use a key name optionally ending with '*'", pattern, role)
+			}
+		}
+	}
+
+	return nil
+}
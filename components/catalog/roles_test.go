@@ -0,0 +1,61 @@
+package catalog
+
+import (
+	"testing"
+)
+
+func TestCanRead(t *testing.T) {
+	// arrange
+	f := File{
+		Roles: map[string][]string{
+			"app": {"DB_HOST", "DB_PASSWORD"},
+			"ci":  {"DEPLOY_*"},
+		},
+	}
+
+	tests := []struct {
+		role     string
+		key      string
+		expected bool
+	}{
+		{"app", "DB_PASSWORD", true},
+		{"app", "DEPLOY_TOKEN", false},
+		{"ci", "DEPLOY_TOKEN", true},
+		{"ci", "DB_HOST", false},
+		{"web", "DB_HOST", false},
+	}
+
+	for _, test := range tests {
+		// act
+		actual := f.CanRead(test.role, test.key)
+
+		// assert
+		if actual != test.expected {
+			t.Errorf("\nEXPECTED: %s can read %s %t \nACTUAL: %t", test.role, test.key, test.expected, actual)
+		}
+	}
+}
+
+func TestCheckRoles(t *testing.T) {
+	// arrange
+	valid := File{Roles: map[string][]string{"app": {"DB_HOST"}, "ci": {"DEPLOY_*"}}}
+	invalid := []File{
+		{Roles: map[string][]string{"app role": {"DB_HOST"}}},
+		{Roles: map[string][]string{"app": {}}},
+		{Roles: map[string][]string{"ci": {"*_TOKEN"}}},
+	}
+
+	// act
+	validErr := valid.CheckRoles()
+
+	// assert
+	if validErr != nil {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %s", nil, validErr)
+	}
+
+	for _, f := range invalid {
+		if err := f.CheckRoles(); err == nil {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "invalid roles error", err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package catalog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rotation declares how often a file or its keys must be rotated.
+type Rotation struct {
+	// Every is the interval the whole file must be rotated within,
+	// like "90d".
+	Every string `yaml:"every,omitempty"`
+
+	// Keys maps keys to the interval they must be rotated within.
+	Keys map[string]string `yaml:"keys,omitempty"`
+}
+
+// Declared ...
+func (r Rotation) Declared() bool {
+	return len(r.Every) > 0 || len(r.Keys) > 0
+}
+
+var intervalUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// ParseInterval converts an interval like "30d", "2w", or "12h" to a
+// duration.
+func ParseInterval(interval string) (time.Duration, error) {
+	s := strings.ToLower(strings.TrimSpace(interval))
+
+	for suffix, unit := range intervalUnits {
+		if strings.HasSuffix(s, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(s, suffix))
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("unknown interval %s", interval)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("unknown interval %s", interval)
+	}
+
+	return d, nil
+}
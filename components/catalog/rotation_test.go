@@ -0,0 +1,40 @@
+package catalog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2W":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+
+	for interval, expected := range tests {
+		// act
+		actual, err := ParseInterval(interval)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+		}
+	}
+}
+
+func TestParseIntervalInvalid(t *testing.T) {
+	for _, interval := range []string{"", "d", "-5d", "monthly", "0h"} {
+		// act
+		_, err := ParseInterval(interval)
+
+		// assert
+		if err == nil {
+			t.Errorf("\nEXPECTED: error for %q \nACTUAL: nil", interval)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// skippedDirs are folders that never contain files worth cataloging.
+var skippedDirs = []string{"node_modules", "vendor"}
+
+// configDirs are folders whose json files are treated as config files.
+var configDirs = []string{"config", "configs"}
+
+// templateSuffixes identify copies of config files that hold no values.
+var templateSuffixes = []string{".example", ".sample", ".template", ".dist", ".secrets"}
+
+// Scan walks the folder returning the paths of env and config files
+// relative to the folder. Env files are named ".env*" or "*.env" and
+// config files are json files in config folders. Hidden folders and
+// dependency folders are skipped.
+func Scan(root string) ([]string, error) {
+	found := []string{}
+
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if rel != "." && (strings.HasPrefix(info.Name(), ".") || contains(skippedDirs, info.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if IsConfigFile(rel) {
+			found = append(found, rel)
+		}
+
+		return nil
+	})
+
+	sort.Strings(found)
+
+	return found, err
+}
+
+// IsConfigFile returns true when the path looks like an env or config
+// file that should be cataloged.
+func IsConfigFile(p string) bool {
+	name := filepath.Base(p)
+
+	for _, suffix := range templateSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+
+	if strings.HasPrefix(name, ".env") || filepath.Ext(name) == ".env" {
+		return true
+	}
+
+	dir := filepath.Base(filepath.Dir(p))
+
+	return filepath.Ext(name) == ".json" && contains(configDirs, dir)
+}
+
+// EnvironmentOf returns the environment named by an env file, like
+// "prod" for ".env.prod" or "prod.env".
+func EnvironmentOf(p string) string {
+	name := filepath.Base(p)
+
+	if strings.HasPrefix(name, ".env.") {
+		return strings.TrimPrefix(name, ".env.")
+	}
+
+	if filepath.Ext(name) == ".env" && name != ".env" {
+		return strings.TrimSuffix(name, ".env")
+	}
+
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package catalog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	// arrange
+	root, err := ioutil.TempDir("", "cstore-scan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	files := []string{
+		".env",
+		".env.example",
+		"api/.env.prod",
+		"api/config/settings.json",
+		"api/package.json",
+		"web/local.env",
+		"web/.env.secrets",
+		"node_modules/lib/.env",
+		".git/.env",
+	}
+
+	for _, f := range files {
+		p := filepath.Join(root, f)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		ioutil.WriteFile(p, []byte("A=1\n"), 0644)
+	}
+
+	// act
+	found, err := Scan(root)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{".env", "api/.env.prod", "api/config/settings.json", "web/local.env"}
+
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected, found)
+	}
+}
+
+func TestEnvironmentOf(t *testing.T) {
+	// arrange
+	tests := map[string]string{
+		".env":          "",
+		"api/.env.prod": "prod",
+		"web/qa.env":    "qa",
+		"config/a.json": "",
+	}
+
+	for p, expected := range tests {
+		// act
+		actual := EnvironmentOf(p)
+
+		// assert
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+		}
+	}
+}
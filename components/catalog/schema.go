@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DataField describes a value saved in a file entry's data, like the
+// bucket an S3 file is pushed to.
+type DataField struct {
+	Key string
+
+	// Prefix matches every key starting with Key, like the commit a
+	// version is pinned to.
+	Prefix bool
+
+	// Required values must be in the data of files in the store.
+	Required bool
+
+	// Type is the value type the value must be. Values are strings when
+	// no type is set.
+	Type string
+
+	// Values lists the allowed values when not empty.
+	Values []string
+}
+
+// DataSchema lists the values a store saves in the data of its files.
+type DataSchema []DataField
+
+var (
+	schemas    = map[string]DataSchema{}
+	commonData = DataSchema{}
+)
+
+// RegisterDataSchema sets the values a store saves in the data of its
+// files. Files in stores without a schema, like plugins, are not
+// checked.
+func RegisterDataSchema(store string, schema DataSchema) {
+	schemas[store] = schema
+}
+
+// RegisterCommonData adds values saved in the data of files in any
+// store, like the settings of the secrets vault.
+func RegisterCommonData(fields ...DataField) {
+	commonData = append(commonData, fields...)
+}
+
+// field returns the schema field matching the key.
+func (s DataSchema) field(key string) (DataField, bool) {
+	for _, f := range s {
+		if f.Key == key || (f.Prefix && strings.HasPrefix(key, f.Key)) {
+			return f, true
+		}
+	}
+
+	return DataField{}, false
+}
+
+// KnownData returns the values in data the store saves in the data of
+// its files, like when moving a file to another store. All values are
+// returned for stores without a schema, like plugins.
+func KnownData(store string, data map[string]string) map[string]string {
+	schema, found := schemas[store]
+
+	known := map[string]string{}
+	for key, value := range data {
+		if found {
+			if _, ok := schema.field(key); !ok {
+				if _, ok := commonData.field(key); !ok {
+					continue
+				}
+			}
+		}
+
+		known[key] = value
+	}
+
+	return known
+}
+
+// check returns an error for the first value that is malformed or
+// required and missing.
+func (f DataField) check(value string) error {
+	if len(f.Values) > 0 {
+		for _, v := range f.Values {
+			if v == value {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("value %q is not one of %s", value, strings.Join(f.Values, ", "))
+	}
+
+	_, err := TypedValue(f.Type, value)
+	return err
+}
+
+// CheckData validates the file's data using its store's schema. An
+// error is returned when a required value is missing or a value is
+// malformed. Keys unknown to the store are returned as warnings since
+// older versions of cStore may have saved them.
+func (f File) CheckData() ([]string, error) {
+	schema, found := schemas[f.Store]
+	if !found {
+		return nil, nil
+	}
+
+	for _, field := range schema {
+		if !field.Required || field.Prefix {
+			continue
+		}
+
+		if len(f.Data[field.Key]) == 0 {
+			return nil, fmt.Errorf("%s is missing %s required by %s", f.Path, field.Key, f.Store)
+		}
+	}
+
+	keys := []string{}
+	for key := range f.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	warnings := []string{}
+
+	for _, key := range keys {
+		field, known := schema.field(key)
+		if !known {
+			field, known = commonData.field(key)
+		}
+
+		if !known {
+			warnings = append(warnings, fmt.Sprintf("%s has data %s unknown to %s.", f.Path, key, f.Store))
+			continue
+		}
+
+		if err := field.check(f.Data[key]); err != nil {
+			return warnings, fmt.Errorf("%s data %s %s", f.Path, key, err)
+		}
+	}
+
+	return warnings, nil
+}
+
+// checkData validates the data of each file in the catalog saving the
+// warnings to display.
+func (c *Catalog) checkData(catalogName string) error {
+	keys := []string{}
+	for key := range c.Files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	c.warnings = []string{}
+
+	for _, key := range keys {
+		warnings, err := c.Files[key].CheckData()
+		if err != nil {
+			return fmt.Errorf("%s in %s", err, catalogName)
+		}
+
+		c.warnings = append(c.warnings, warnings...)
+	}
+
+	return nil
+}
+
+// Warnings returns problems found loading the catalog that do not stop
+// it from being used.
+func (c Catalog) Warnings() []string {
+	return c.warnings
+}
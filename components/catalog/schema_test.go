@@ -0,0 +1,90 @@
+package catalog
+
+import (
+	"testing"
+)
+
+func TestCheckData(t *testing.T) {
+	// arrange
+	RegisterDataSchema("schema-test", DataSchema{
+		{Key: "TEST_BUCKET", Required: true},
+		{Key: "TEST_URL", Type: ValueTypeURL},
+		{Key: "TEST_KIND", Values: []string{"secret", "configmap"}},
+		{Key: "TEST_PIN", Prefix: true},
+	})
+
+	tests := []struct {
+		data     map[string]string
+		warnings int
+		expected string
+	}{
+		{data: map[string]string{"TEST_BUCKET": "b", "TEST_URL": "https://example.com", "TEST_KIND": "secret", "TEST_PIN_V1": "abc"}},
+		{data: map[string]string{"TEST_URL": "https://example.com"}, expected: ".env is missing TEST_BUCKET required by schema-test"},
+		{data: map[string]string{"TEST_BUCKET": "b", "TEST_KIND": "Secret"}, expected: `.env data TEST_KIND value "Secret" is not one of secret, configmap`},
+		{data: map[string]string{"TEST_BUCKET": "b", "TEST_URL": "example.com"}, expected: `.env data TEST_URL value "example.com" is not a url`},
+		{data: map[string]string{"TEST_BUCKET": "b", "TEST_BUCKTE": "b"}, warnings: 1},
+	}
+
+	for _, test := range tests {
+		f := File{Path: ".env", Store: "schema-test", Data: test.data}
+
+		// act
+		warnings, err := f.CheckData()
+
+		// assert
+		actual := ""
+		if err != nil {
+			actual = err.Error()
+		}
+
+		if actual != test.expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", test.expected, actual)
+		}
+
+		if len(warnings) != test.warnings {
+			t.Errorf("\nEXPECTED: %d warning(s) \nACTUAL: %v", test.warnings, warnings)
+		}
+	}
+}
+
+func TestCheckDataSkipsStoresWithoutSchema(t *testing.T) {
+	// arrange
+	f := File{Path: ".env", Store: "my-plugin", Data: map[string]string{"ANYTHING": "x"}}
+
+	// act
+	warnings, err := f.CheckData()
+
+	// assert
+	if err != nil || len(warnings) > 0 {
+		t.Errorf("\nEXPECTED: no problems \nACTUAL: %v %v", warnings, err)
+	}
+}
+
+func TestKnownData(t *testing.T) {
+	// arrange
+	RegisterDataSchema("known-test", DataSchema{
+		{Key: "TEST_BUCKET"},
+		{Key: "TEST_PIN", Prefix: true},
+	})
+	RegisterCommonData(DataField{Key: "TEST_COMMON"})
+
+	data := map[string]string{
+		"TEST_BUCKET":  "b",
+		"TEST_PIN_V1":  "abc",
+		"TEST_COMMON":  "c",
+		"OTHER_DIGEST": "sha256:123",
+	}
+
+	// act
+	known := KnownData("known-test", data)
+	plugin := KnownData("my-plugin", data)
+
+	// assert
+	if len(known) != 3 || len(known["OTHER_DIGEST"]) > 0 {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "TEST_BUCKET TEST_PIN_V1 TEST_COMMON", known)
+	}
+
+	if len(plugin) != len(data) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", data, plugin)
+	}
+}
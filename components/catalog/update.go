@@ -1,8 +1,6 @@
 package catalog
 
 import (
-	"crypto/md5"
-	"encoding/hex"
 	"io/ioutil"
 
 	yaml "gopkg.in/yaml.v2"
@@ -10,6 +8,8 @@ import (
 
 // Write saves the catalog
 func Write(path string, catalog Catalog) error {
+	catalog.Context = catalog.DefaultContext()
+
 	d, err := yaml.Marshal(&catalog)
 	if err != nil {
 		return err
@@ -24,9 +24,3 @@ func Write(path string, catalog Catalog) error {
 
 	return ioutil.WriteFile(path, d, 0644)
 }
-
-func hashPath(path string) string {
-	hasher := md5.New()
-	hasher.Write([]byte(path))
-	return hex.EncodeToString(hasher.Sum(nil))
-}
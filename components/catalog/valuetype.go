@@ -0,0 +1,130 @@
+package catalog
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Value types describe the kind of value a key holds. Pushes validate
+// the values and export formats supporting types, like JSON, emit
+// typed values instead of strings.
+const (
+	// ValueTypeString values are not validated. Undeclared keys are
+	// strings.
+	ValueTypeString = "string"
+
+	// ValueTypeInt values are whole numbers.
+	ValueTypeInt = "int"
+
+	// ValueTypeBool values are true or false.
+	ValueTypeBool = "bool"
+
+	// ValueTypeURL values are absolute URLs, like https://example.com.
+	ValueTypeURL = "url"
+
+	// ValueTypeDuration values are durations, like 30s or 1h30m.
+	ValueTypeDuration = "duration"
+)
+
+// ValueTypes lists the supported value types.
+var ValueTypes = []string{ValueTypeString, ValueTypeInt, ValueTypeBool, ValueTypeURL, ValueTypeDuration}
+
+// IsValueType ...
+func IsValueType(valueType string) bool {
+	for _, t := range ValueTypes {
+		if t == valueType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValueType returns the value type declared for a key. Undeclared keys
+// are strings.
+func (f File) ValueType(key string) string {
+	if t, found := f.ValueTypes[key]; found {
+		return strings.ToLower(t)
+	}
+	return ValueTypeString
+}
+
+// CheckValueTypes returns an error when a value type declared in the
+// catalog is not supported or a value is not its declared type.
+func (f File) CheckValueTypes(values map[string]string) error {
+	keys := []string{}
+	for key := range f.ValueTypes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !IsValueType(f.ValueType(key)) {
+			return fmt.Errorf("unknown value type %s for %s, use %s", f.ValueTypes[key], key, strings.Join(ValueTypes, ", "))
+		}
+
+		value, found := values[key]
+		if !found {
+			continue
+		}
+
+		if _, err := TypedValue(f.ValueType(key), value); err != nil {
+			return fmt.Errorf("%s %s", key, err)
+		}
+	}
+
+	return nil
+}
+
+// TypedValues converts each value to its declared type. Keys without a
+// declared type remain strings.
+func TypedValues(values map[string]string, valueTypes map[string]string) (map[string]interface{}, error) {
+	f := File{ValueTypes: valueTypes}
+	typed := map[string]interface{}{}
+
+	for key, value := range values {
+		v, err := TypedValue(f.ValueType(key), value)
+		if err != nil {
+			return typed, fmt.Errorf("%s %s", key, err)
+		}
+		typed[key] = v
+	}
+
+	return typed, nil
+}
+
+// TypedValue converts a value to the value type. Ints and bools are
+// converted; URLs and durations are validated and remain strings since
+// most formats have no type for them.
+func TypedValue(valueType, value string) (interface{}, error) {
+	switch valueType {
+	case ValueTypeInt:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not an int", value)
+		}
+		return i, nil
+	case ValueTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a bool", value)
+		}
+		return b, nil
+	case ValueTypeURL:
+		u, err := url.Parse(value)
+		if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return nil, fmt.Errorf("value %q is not a url", value)
+		}
+		return value, nil
+	case ValueTypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("value %q is not a duration", value)
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,56 @@
+package catalog
+
+import (
+	"testing"
+)
+
+func TestCheckValueTypes(t *testing.T) {
+	// arrange
+	f := File{
+		ValueTypes: map[string]string{
+			"PORT":    "int",
+			"DEBUG":   "Bool",
+			"API_URL": "url",
+			"TIMEOUT": "duration",
+		},
+	}
+
+	tests := []struct {
+		values   map[string]string
+		expected string
+	}{
+		{values: map[string]string{"PORT": "8080", "DEBUG": "true", "API_URL": "https://example.com", "TIMEOUT": "30s"}},
+		{values: map[string]string{"PORT": "80a"}, expected: `PORT value "80a" is not an int`},
+		{values: map[string]string{"API_URL": "example.com"}, expected: `API_URL value "example.com" is not a url`},
+		{values: map[string]string{"TIMEOUT": "30"}, expected: `TIMEOUT value "30" is not a duration`},
+	}
+
+	for _, test := range tests {
+		// act
+		err := f.CheckValueTypes(test.values)
+
+		// assert
+		actual := ""
+		if err != nil {
+			actual = err.Error()
+		}
+
+		if actual != test.expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", test.expected, actual)
+		}
+	}
+}
+
+func TestCheckValueTypesUnknown(t *testing.T) {
+	// arrange
+	f := File{ValueTypes: map[string]string{"PORT": "number"}}
+
+	// act
+	err := f.CheckValueTypes(map[string]string{})
+
+	// assert
+	expected := "unknown value type number for PORT, use string, int, bool, url, duration"
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
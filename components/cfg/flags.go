@@ -3,14 +3,19 @@ package cfg
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
+// asOfLayouts are the formats accepted for --as-of times.
+var asOfLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
 // UserOptions ...
 type UserOptions struct {
 	Store                string
 	Tags                 string
 	AllTags              bool
 	TagList              []string
+	TagFilter            TagExpression
 	Paths                []string
 	Version              string
 	AlternateRestorePath string
@@ -26,7 +31,86 @@ type UserOptions struct {
 	SecretsVault         string
 	ViewTags             bool
 	ViewVersions         bool
+	ViewKeys             bool
 	Prompt               bool
+	Force                bool
+	Stdout               bool
+	NoBackup             bool
+	Justification        string
+	Offline              bool
+	Cache                bool
+	Concurrency          int
+	Refresh              bool
+	Role                 string
+	Hash                 string
+	Report               bool
+	Wizard               bool
+	AsOf                 string
+	BreakGlass           string
+	Resume               bool
+	AliasDeprecated      bool
+	RemotePath           string
+	Key                  string
+	ChangeSet            string
+	FailOverdue          bool
+	FailPlaintext        bool
+	All                  bool
+	InventoryFormat      string
+	Owner                string
+	NoHooks              bool
+	RemoteHost           string
+	RemoteDir            string
+	RemoteMode           string
+	Template             string
+	PatchCompose         bool
+	Policy               string
+	OutputFile           string
+	Open                 string
+	Recipients           string
+	ExitCode             bool
+	Reveal               bool
+	PreviewFormat        string
+	Serve                string
+	Revision             string
+	Pin                  bool
+	Unpin                bool
+	Secret               string
+	From                 string
+	To                   string
+	DryRun               bool
+	PurgeSource          bool
+	ContinueOnError      bool
+	Failures             string
+	Merge                bool
+	Verify               bool
+	Flatten              bool
+	OutputFormat         string
+	CredentialHelpers    map[string]string
+}
+
+// CredentialHelper returns the helper configured for a store. A helper
+// configured for "*" is used by any store without its own helper.
+func (o UserOptions) CredentialHelper(store string) string {
+	if helper, found := o.CredentialHelpers[store]; found {
+		return helper
+	}
+	return o.CredentialHelpers["*"]
+}
+
+// AsOfTime returns the time files are pulled as of or time.Time{} when
+// the latest state is pulled. Times without a zone are local.
+func (o UserOptions) AsOfTime() (time.Time, error) {
+	if len(o.AsOf) == 0 {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range asOfLayouts {
+		if t, err := time.ParseInLocation(layout, o.AsOf, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("could not parse %s, use a time like 2006-01-02T15:04:05Z07:00, 2006-01-02 15:04, or 2006-01-02", o.AsOf)
 }
 
 // AddPaths ...
@@ -73,10 +157,23 @@ func (o UserOptions) TagsFrom(path string) []string {
 }
 
 // ParseTags ...
-func (o *UserOptions) ParseTags() {
+func (o *UserOptions) ParseTags() error {
 	const and = "&"
 	const or = "|"
 
+	if IsTagExpression(o.Tags) {
+		filter, err := ParseTagExpression(o.Tags)
+		if err != nil {
+			return err
+		}
+
+		o.TagFilter = filter
+		o.TagList = []string{}
+		o.AllTags = false
+
+		return nil
+	}
+
 	sep := and
 	o.AllTags = true
 
@@ -93,4 +190,12 @@ func (o *UserOptions) ParseTags() {
 	if len(o.TagList) == 1 && o.TagList[0] == "" {
 		o.TagList = []string{}
 	}
+
+	if o.AllTags {
+		o.TagFilter = AllTags(o.TagList)
+	} else {
+		o.TagFilter = AnyTags(o.TagList)
+	}
+
+	return nil
 }
@@ -0,0 +1,36 @@
+package cfg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAsOfTime(t *testing.T) {
+	// arrange
+	tests := map[string]time.Time{
+		"":                     time.Time{},
+		"2019-03-05T10:30:00Z": time.Date(2019, 3, 5, 10, 30, 0, 0, time.UTC),
+		"2019-03-05 10:30":     time.Date(2019, 3, 5, 10, 30, 0, 0, time.Local),
+		"2019-03-05":           time.Date(2019, 3, 5, 0, 0, 0, 0, time.Local),
+	}
+
+	for value, expected := range tests {
+		// act
+		actual, err := UserOptions{AsOf: value}.AsOfTime()
+
+		// assert
+		if err != nil || !actual.Equal(expected) {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s (%v)", expected, actual, err)
+		}
+	}
+}
+
+func TestAsOfTimeInvalid(t *testing.T) {
+	// act
+	_, err := UserOptions{AsOf: "last tuesday"}.AsOfTime()
+
+	// assert
+	if err == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "error", err)
+	}
+}
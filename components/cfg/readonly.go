@@ -0,0 +1,28 @@
+package cfg
+
+import "fmt"
+
+// readOnly disables commands that change remote files. Binaries built
+// with the "readonly" build tag are always read-only.
+var readOnly = false
+
+// EnableReadOnly disables commands that change remote files for the
+// rest of the process. Read-only mode cannot be turned off.
+func EnableReadOnly() {
+	readOnly = true
+}
+
+// ReadOnly reports whether read-only mode is enabled.
+func ReadOnly() bool {
+	return readOnly
+}
+
+// Writable returns an error naming the operation when read-only mode
+// is enabled.
+func Writable(operation string) error {
+	if readOnly {
+		return fmt.Errorf("%s is disabled, cstore is running in read-only mode", operation)
+	}
+
+	return nil
+}
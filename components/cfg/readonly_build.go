@@ -0,0 +1,8 @@
+//go:build readonly
+// +build readonly
+
+package cfg
+
+func init() {
+	readOnly = true
+}
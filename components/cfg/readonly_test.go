@@ -0,0 +1,24 @@
+package cfg
+
+import "testing"
+
+func TestWritableInReadOnlyMode(t *testing.T) {
+	// arrange
+	defer func(mode bool) {
+		readOnly = mode
+	}(readOnly)
+
+	readOnly = false
+
+	if err := Writable("push"); err != nil {
+		t.Fatalf("\nEXPECTED: %v \nACTUAL: %s", nil, err)
+	}
+
+	// act
+	EnableReadOnly()
+
+	// assert
+	if err := Writable("push"); err == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "error", err)
+	}
+}
@@ -0,0 +1,296 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	tagMatch = "tag"
+	tagAnd   = "and"
+	tagOr    = "or"
+	tagNot   = "not"
+)
+
+// TagExpression selects files by their tags, like "web and not legacy".
+// The zero value selects every file.
+type TagExpression struct {
+	op       string
+	tag      string
+	operands []TagExpression
+}
+
+// AllTags returns an expression matching files with every tag.
+func AllTags(tags []string) TagExpression {
+	return tagList(tagAnd, tags)
+}
+
+// AnyTags returns an expression matching files with any tag.
+func AnyTags(tags []string) TagExpression {
+	return tagList(tagOr, tags)
+}
+
+func tagList(op string, tags []string) TagExpression {
+	e := TagExpression{op: op}
+
+	for _, t := range tags {
+		e.operands = append(e.operands, TagExpression{op: tagMatch, tag: t})
+	}
+
+	if len(e.operands) == 0 {
+		return TagExpression{}
+	}
+
+	return e
+}
+
+// IsEmpty returns true when the expression selects every file.
+func (e TagExpression) IsEmpty() bool {
+	return len(e.op) == 0
+}
+
+// Matches returns true when the tags satisfy the expression.
+func (e TagExpression) Matches(tags []string) bool {
+	switch e.op {
+	case tagMatch:
+		for _, t := range tags {
+			if t == e.tag {
+				return true
+			}
+		}
+		return false
+	case tagNot:
+		return !e.operands[0].Matches(tags)
+	case tagAnd:
+		for _, o := range e.operands {
+			if !o.Matches(tags) {
+				return false
+			}
+		}
+		return true
+	case tagOr:
+		for _, o := range e.operands {
+			if o.Matches(tags) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// IsTagExpression returns true when the tags use expression syntax
+// instead of a | or & delimited list.
+func IsTagExpression(tags string) bool {
+	if strings.ContainsAny(tags, "()!") {
+		return true
+	}
+
+	for _, word := range strings.Fields(tags) {
+		switch strings.ToLower(word) {
+		case tagAnd, tagOr, tagNot:
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseTagExpression parses tags combined with and, or, not, and
+// parentheses. The operators &, |, and ! can be used instead of words.
+func ParseTagExpression(expr string) (TagExpression, error) {
+	p := tagParser{tokens: tokenizeTags(expr)}
+
+	if len(p.tokens) == 0 {
+		return TagExpression{}, nil
+	}
+
+	e, err := p.or()
+	if err == nil && !p.done() {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+
+	if err != nil {
+		return TagExpression{}, fmt.Errorf("invalid tag expression %q (%s)", expr, err)
+	}
+
+	return e, nil
+}
+
+func tokenizeTags(expr string) []string {
+	tokens := []string{}
+	word := ""
+
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, word)
+			word = ""
+		}
+	}
+
+	for _, r := range expr {
+		switch {
+		case strings.ContainsRune("()&|!", r):
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			word += string(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *tagParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *tagParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+// accept moves past the next token when it is one of the operators.
+func (p *tagParser) accept(operators ...string) bool {
+	for _, o := range operators {
+		if strings.ToLower(p.peek()) == o {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *tagParser) or() (TagExpression, error) {
+	return p.list(tagOr, p.and, tagOr, "|")
+}
+
+func (p *tagParser) and() (TagExpression, error) {
+	return p.list(tagAnd, p.not, tagAnd, "&")
+}
+
+func (p *tagParser) list(op string, next func() (TagExpression, error), operators ...string) (TagExpression, error) {
+	e, err := next()
+	if err != nil {
+		return e, err
+	}
+
+	operands := []TagExpression{e}
+
+	for p.accept(operators...) {
+		e, err := next()
+		if err != nil {
+			return e, err
+		}
+
+		operands = append(operands, e)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+
+	return TagExpression{op: op, operands: operands}, nil
+}
+
+func (p *tagParser) not() (TagExpression, error) {
+	if p.accept(tagNot, "!") {
+		e, err := p.not()
+		if err != nil {
+			return e, err
+		}
+
+		return TagExpression{op: tagNot, operands: []TagExpression{e}}, nil
+	}
+
+	if p.accept("(") {
+		e, err := p.or()
+		if err != nil {
+			return e, err
+		}
+
+		if !p.accept(")") {
+			return e, errors.New("missing )")
+		}
+
+		return e, nil
+	}
+
+	switch strings.ToLower(p.peek()) {
+	case "":
+		return TagExpression{}, errors.New("expected a tag at the end")
+	case tagAnd, tagOr, ")", "&", "|":
+		return TagExpression{}, fmt.Errorf("expected a tag before %s", p.peek())
+	}
+
+	p.pos++
+
+	return TagExpression{op: tagMatch, tag: p.tokens[p.pos-1]}, nil
+}
+
+// TagsValue is a tags flag that can be repeated, like
+// "-t prod -t billing". Repeated tags are combined; so, pushed files
+// get every tag and selected files must match every tag.
+type TagsValue struct {
+	tags    *string
+	changed bool
+}
+
+// NewTagsValue returns a flag value saving the combined tags to p.
+func NewTagsValue(p *string) *TagsValue {
+	return &TagsValue{tags: p}
+}
+
+// String ...
+func (v *TagsValue) String() string {
+	return *v.tags
+}
+
+// Set adds the tags to the tags set by earlier flags.
+func (v *TagsValue) Set(tags string) error {
+	if !v.changed {
+		*v.tags, v.changed = tags, true
+		return nil
+	}
+
+	*v.tags = JoinTags(*v.tags, tags)
+
+	return nil
+}
+
+// Type ...
+func (v *TagsValue) Type() string {
+	return "string"
+}
+
+// JoinTags combines two lists of tags or tag expressions into one
+// matching both. Lists delimited by & stay lists; so, they can still be
+// set on pushed files.
+func JoinTags(a, b string) string {
+	switch {
+	case len(a) == 0:
+		return b
+	case len(b) == 0:
+		return a
+	}
+
+	for _, tags := range []string{a, b} {
+		if IsTagExpression(tags) || strings.Contains(tags, "|") {
+			return fmt.Sprintf("(%s) and (%s)", a, b)
+		}
+	}
+
+	return a + "&" + b
+}
@@ -0,0 +1,135 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTagExpressionMatches(t *testing.T) {
+	// arrange
+	tests := map[string]map[string]bool{
+		"web and not legacy": {
+			"web":        true,
+			"web,legacy": false,
+			"api":        false,
+		},
+		"(web | api) & !legacy": {
+			"web":        true,
+			"api,dev":    true,
+			"api,legacy": false,
+		},
+		"dev or qa and secure": {
+			"dev":       true,
+			"qa":        false,
+			"qa,secure": true,
+		},
+		"NOT prod": {
+			"":     true,
+			"prod": false,
+		},
+	}
+
+	for expr, cases := range tests {
+		e, err := ParseTagExpression(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for tags, expected := range cases {
+			// act
+			actual := e.Matches(splitTags(tags))
+
+			// assert
+			if actual != expected {
+				t.Errorf("\nEXPRESSION: %s \nTAGS: %s \nEXPECTED: %t \nACTUAL: %t", expr, tags, expected, actual)
+			}
+		}
+	}
+}
+
+func TestTagExpressionInvalid(t *testing.T) {
+	// arrange
+	tests := map[string]string{
+		"web and":       `invalid tag expression "web and" (expected a tag at the end)`,
+		"(web or api":   `invalid tag expression "(web or api" (missing ))`,
+		"web api":       `invalid tag expression "web api" (unexpected api)`,
+		"not and web":   `invalid tag expression "not and web" (expected a tag before and)`,
+		"web ) or (api": `invalid tag expression "web ) or (api" (unexpected ))`,
+	}
+
+	for expr, expected := range tests {
+		// act
+		_, err := ParseTagExpression(expr)
+
+		// assert
+		if err == nil || err.Error() != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+		}
+	}
+}
+
+func TestParseTagsKeepsLists(t *testing.T) {
+	// arrange
+	tests := map[string]bool{
+		"dev|qa": true,
+		"dev&qa": false,
+	}
+
+	for tags, expected := range tests {
+		o := UserOptions{Tags: tags}
+
+		// act
+		err := o.ParseTags()
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(o.TagList) != 2 {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "2 tags", o.TagList)
+		}
+
+		if actual := o.TagFilter.Matches([]string{"dev"}); actual != expected {
+			t.Errorf("\nTAGS: %s \nEXPECTED: %t \nACTUAL: %t", tags, expected, actual)
+		}
+	}
+}
+
+func splitTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool { return r == ',' })
+}
+
+func TestTagsValueRepeated(t *testing.T) {
+	// arrange
+	tests := map[string][]string{
+		"prod&billing":                    {"prod", "billing"},
+		"prod&billing&eu":                 {"prod", "billing&eu"},
+		"(web and not legacy) and (prod)": {"web and not legacy", "prod"},
+		"(dev|qa) and (billing)":          {"dev|qa", "billing"},
+		"prod":                            {"prod"},
+	}
+
+	for expected, flags := range tests {
+		tags := "stale"
+		v := NewTagsValue(&tags)
+
+		// act
+		for _, f := range flags {
+			if err := v.Set(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// assert
+		if tags != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, tags)
+		}
+
+		if IsTagExpression(tags) {
+			if _, err := ParseTagExpression(tags); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package cipher
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// DeriveKey derives a 32 byte AES-256 key from a master key using HKDF
+// with SHA-256 (RFC 5869). Keys derived with different info are
+// independent; so, exposing one does not expose the master key or any
+// other derived key.
+func DeriveKey(master string, salt, info []byte) (string, error) {
+	if len(master) == 0 {
+		return "", errors.New("master key is empty")
+	}
+
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(master), salt, info), b); err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,60 @@
+package cipher
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDeriveKeyHKDF(t *testing.T) {
+	// arrange (RFC 5869 test case 1)
+	secret := bytes.Repeat([]byte{0x0b}, 22)
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	expected := "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf"
+
+	// act
+	key, err := DeriveKey(string(secret), salt, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if actual := hex.EncodeToString([]byte(key)); actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	// arrange
+	master := "AES256Key-32Characters1234567890"
+
+	// act
+	a, err := DeriveKey(master, []byte("ctx"), []byte("file-a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := DeriveKey(master, []byte("ctx"), []byte("file-b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Encrypt(a, []byte("my data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if len(a) != 32 {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %d", 32, len(a))
+	}
+
+	if a == b {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "different keys per file", "same key")
+	}
+
+	if plain, _ := Decrypt(b, data); string(plain) == "my data" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "file-b key cannot decrypt file-a", plain)
+	}
+}
@@ -79,7 +79,7 @@ var seededRand = mrand.New(mrand.NewSource(time.Now().UnixNano()))
 func stringWithCharset(length int, charset string) string {
 	b := make([]byte, length)
 	for i := range b {
-		b[i] = charset[seededRand.Intn(len(charset))]
+		b[i] = charset[randomIndex(len(charset))]
 	}
 	return string(b)
 }
@@ -0,0 +1,49 @@
+package cipher
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// fipsValidated is true when the binary is built with a FIPS 140
+// validated crypto module using the "fips" build tag.
+var fipsValidated = false
+
+var fipsMode = false
+
+// ErrFIPSUnavailable is returned when FIPS mode is requested from a
+// binary that was not built with a validated crypto module.
+var ErrFIPSUnavailable = errors.New("FIPS mode requires cstore built with a FIPS 140 validated crypto module (GOEXPERIMENT=boringcrypto go build -tags fips)")
+
+// EnableFIPS restricts client-side encryption to FIPS-approved
+// algorithms provided by the validated crypto module.
+func EnableFIPS() error {
+	if !fipsValidated {
+		return ErrFIPSUnavailable
+	}
+
+	fipsMode = true
+
+	return nil
+}
+
+// FIPS reports whether FIPS mode is enabled.
+func FIPS() bool {
+	return fipsMode
+}
+
+// randomIndex returns a random number in [0,n) using the approved
+// random bit generator in FIPS mode.
+func randomIndex(n int) int {
+	if !fipsMode {
+		return seededRand.Intn(n)
+	}
+
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		panic(err)
+	}
+
+	return int(i.Int64())
+}
@@ -0,0 +1,49 @@
+package cipher
+
+import "testing"
+
+func TestEnableFIPSRequiresValidatedModule(t *testing.T) {
+	// arrange
+	defer func(validated, mode bool) {
+		fipsValidated, fipsMode = validated, mode
+	}(fipsValidated, fipsMode)
+
+	fipsValidated = false
+
+	// act
+	err := EnableFIPS()
+
+	// assert
+	if err != ErrFIPSUnavailable {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", ErrFIPSUnavailable, err)
+	}
+
+	if FIPS() {
+		t.Errorf("\nEXPECTED: %t \nACTUAL: %t", false, FIPS())
+	}
+}
+
+func TestGenerateAES256KeyInFIPSMode(t *testing.T) {
+	// arrange
+	defer func(validated, mode bool) {
+		fipsValidated, fipsMode = validated, mode
+	}(fipsValidated, fipsMode)
+
+	fipsValidated = true
+
+	if err := EnableFIPS(); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	key := GenerateAES256Key()
+
+	// assert
+	if len(key) != 32 {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %d", 32, len(key))
+	}
+
+	if _, err := Encrypt(key, []byte("my data")); err != nil {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %s", nil, err)
+	}
+}
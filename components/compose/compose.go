@@ -0,0 +1,115 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Patch adds an env_file to each service in a docker-compose file and
+// returns the updated file. Services missing from the file are added
+// and env files already referenced are not added again. Comments in the
+// file are not preserved.
+func Patch(file []byte, envFiles map[string]string) ([]byte, error) {
+	doc := yaml.MapSlice{}
+
+	if err := yaml.Unmarshal(file, &doc); err != nil {
+		return nil, err
+	}
+
+	services := yaml.MapSlice{}
+
+	index := indexOf(doc, "services")
+	if index > -1 && doc[index].Value != nil {
+		existing, ok := doc[index].Value.(yaml.MapSlice)
+		if !ok {
+			return nil, fmt.Errorf("services must be a map")
+		}
+		services = existing
+	}
+
+	names := []string{}
+	for name := range envFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service := yaml.MapSlice{}
+
+		i := indexOf(services, name)
+		if i > -1 && services[i].Value != nil {
+			existing, ok := services[i].Value.(yaml.MapSlice)
+			if !ok {
+				return nil, fmt.Errorf("service %s must be a map", name)
+			}
+			service = existing
+		}
+
+		service, err := addEnvFile(service, envFiles[name])
+		if err != nil {
+			return nil, fmt.Errorf("service %s %s", name, err)
+		}
+
+		services = set(services, name, service)
+	}
+
+	doc = set(doc, "services", services)
+
+	return yaml.Marshal(doc)
+}
+
+// Version returns the version declared in a docker-compose file; so,
+// a new override file can declare the same version.
+func Version(file []byte) string {
+	doc := struct {
+		Version string `yaml:"version"`
+	}{}
+
+	yaml.Unmarshal(file, &doc)
+
+	return doc.Version
+}
+
+func addEnvFile(service yaml.MapSlice, envFile string) (yaml.MapSlice, error) {
+	envFiles := []interface{}{}
+
+	if i := indexOf(service, "env_file"); i > -1 {
+		switch value := service[i].Value.(type) {
+		case string:
+			envFiles = append(envFiles, value)
+		case []interface{}:
+			envFiles = value
+		case nil:
+		default:
+			return nil, fmt.Errorf("env_file must be a path or list of paths")
+		}
+	}
+
+	for _, existing := range envFiles {
+		if existing == envFile {
+			return set(service, "env_file", envFiles), nil
+		}
+	}
+
+	return set(service, "env_file", append(envFiles, envFile)), nil
+}
+
+func indexOf(m yaml.MapSlice, key string) int {
+	for i, item := range m {
+		if item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func set(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	if i := indexOf(m, key); i > -1 {
+		m[i].Value = value
+		return m
+	}
+
+	return append(m, yaml.MapItem{Key: key, Value: value})
+}
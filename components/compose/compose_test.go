@@ -0,0 +1,83 @@
+package compose
+
+import (
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	// arrange
+	file := []byte(`version: "3.7"
+services:
+  api:
+    ports:
+    - 8080:8080
+    env_file: local.env
+  worker:
+    env_file:
+    - compose/worker.env
+`)
+
+	// act
+	patched, err := Patch(file, map[string]string{
+		"api":    "compose/api.env",
+		"worker": "compose/worker.env",
+		"web":    "compose/web.env",
+	})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `version: "3.7"
+services:
+  api:
+    ports:
+    - 8080:8080
+    env_file:
+    - local.env
+    - compose/api.env
+  worker:
+    env_file:
+    - compose/worker.env
+  web:
+    env_file:
+    - compose/web.env
+`
+
+	if string(patched) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, string(patched))
+	}
+}
+
+func TestPatchEmpty(t *testing.T) {
+	// arrange
+	file := []byte{}
+
+	// act
+	patched, err := Patch(file, map[string]string{"api": "compose/api.env"})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "services:\n  api:\n    env_file:\n    - compose/api.env\n"
+
+	if string(patched) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, string(patched))
+	}
+}
+
+func TestVersion(t *testing.T) {
+	// arrange
+	file := []byte("version: '2.4'\nservices: {}\n")
+
+	// act
+	version := Version(file)
+
+	// assert
+	if version != "2.4" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "2.4", version)
+	}
+}
@@ -2,6 +2,7 @@ package contract
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/turnerlabs/cstore/components/catalog"
@@ -114,6 +115,328 @@ type IStore interface {
 	Changed(file *catalog.File, fileData []byte, version string) (time.Time, error)
 }
 
+// IConditionalStore is optionally implemented by stores able to
+// identify the current state of a remote file without retrieving
+// its contents. When implemented, files that have not changed since
+// the last pull are not downloaded again.
+type IConditionalStore interface {
+
+	// ETag should return a value that changes every time the remote
+	// file changes, like an S3 ETag or an artifact digest.
+	//
+	// "version" contains the version of the file contents being
+	// checked.
+	//
+	// "error" should return nil if the operation was successful.
+	ETag(file *catalog.File, version string) (string, error)
+}
+
+// IKeyStore is optionally implemented by key/value stores able to
+// report when each key in a file was last modified.
+type IKeyStore interface {
+
+	// KeysModified should return the time each key in the file was
+	// last modified. Stores should use native timestamps when
+	// available and times recorded in "file.Data" during pushes
+	// otherwise. Keys without a known time should map to time.Time{}.
+	//
+	// "version" contains the version of the file contents being
+	// checked.
+	//
+	// "error" should return nil if the operation was successful.
+	KeysModified(file *catalog.File, version string) (map[string]time.Time, error)
+}
+
+// ILocatableStore is optionally implemented by stores able to explain
+// where a file or key is read from and written to.
+type ILocatableStore interface {
+
+	// Locate should describe where the file is stored. When "key" is
+	// specified, the location should identify the key when the store
+	// saves keys individually.
+	//
+	// "version" contains the version of the file contents being
+	// located.
+	//
+	// "error" should return nil if the operation was successful.
+	Locate(file *catalog.File, key, version string) (Location, error)
+}
+
+// IExpiringStore is optionally implemented by stores using credentials
+// that expire, like tokens and STS sessions. Credentials about to expire
+// are refreshed before each store operation; so, they do not expire in
+// the middle of a push or pull.
+type IExpiringStore interface {
+
+	// Expires should return when the store credentials expire or
+	// time.Time{} when they do not expire or the expiry is unknown.
+	Expires() time.Time
+
+	// Refresh should renew the store credentials.
+	//
+	// "error" should return nil if the operation was successful.
+	Refresh() error
+}
+
+// IBlobStore is optionally implemented by key/value stores saving env
+// files. Files of other types are saved as base64 encoded chunks in
+// keys; so, certs, JSON, and yaml files can use the same store.
+type IBlobStore interface {
+
+	// BlobLimits should return the largest value in bytes the store
+	// saves for a key and the largest total in bytes of all values
+	// for a file. Zero means there is no limit.
+	BlobLimits() (value int, total int)
+}
+
+// IReencryptingStore is optionally implemented by stores encrypting
+// files client-side. After the encryption key is rotated in the access
+// vault, files can be re-encrypted without manually pushing each one.
+type IReencryptingStore interface {
+
+	// Reencrypt should decrypt the remote file using "oldKey", encrypt
+	// it using the current key, and push it. Files already encrypted
+	// with the current key should be left unchanged.
+	//
+	// "version" contains the version of the file contents being
+	// re-encrypted.
+	//
+	// The "[]byte" array should be the decrypted contents of the file.
+	//
+	// "error" should return nil if the operation was successful.
+	Reencrypt(file *catalog.File, oldKey, version string) ([]byte, error)
+}
+
+// IHistoricalStore is optionally implemented by stores keeping earlier
+// states of a file; so, a file can be pulled as it was at a point in
+// time.
+type IHistoricalStore interface {
+
+	// PullAsOf should return the contents of the file as they were at
+	// "asOf" using the most recent state saved at or before that time.
+	//
+	// "version" contains the version of the file contents being
+	// retrieved.
+	//
+	// "Attributes" should return the time the returned state was saved.
+	//
+	// "error" should be returned when the file did not exist at "asOf".
+	PullAsOf(file *catalog.File, version string, asOf time.Time) ([]byte, Attributes, error)
+}
+
+// IResumableStore is optionally implemented by key/value stores saving
+// keys individually. When a push fails partway through, only the keys
+// that were not pushed are retried.
+type IResumableStore interface {
+
+	// Resume should push only the listed keys from the file without
+	// comparing them to the stored keys. Keys removed from the file
+	// should be deleted like a push.
+	//
+	// "version" contains the version of the file contents being
+	// pushed.
+	//
+	// "error" should be a PushError when pushing stops partway through.
+	Resume(file *catalog.File, fileData []byte, version string, keys []string) error
+}
+
+// IDiscoverableStore is optionally implemented by stores able to list
+// the files they hold without a catalog; so, catalog entries can be
+// recovered when a catalog is lost.
+type IDiscoverableStore interface {
+
+	// Discover should list the files stored under "prefix", a store
+	// specific path like "/my-app/". When "prefix" is empty, files in
+	// the current context should be listed.
+	//
+	// "error" should return nil if the operation was successful.
+	Discover(prefix string) ([]Discovered, error)
+}
+
+// Discovered describes a file found in a store.
+type Discovered struct {
+	// Context is the catalog context the file was pushed with.
+	Context string
+
+	// Path is the file path relative to the catalog.
+	Path string
+
+	// Version identifies a versioned copy of the file.
+	Version string
+
+	// Keys is the number of keys saved when the store saves keys
+	// individually.
+	Keys int
+
+	// Modified is when the file last changed.
+	Modified time.Time
+}
+
+// IKeyHistoryStore is optionally implemented by stores keeping earlier
+// values of a file's keys; so, the changes to a single key can be
+// listed.
+type IKeyHistoryStore interface {
+
+	// KeyHistory should return each change to the key's value with the
+	// oldest change first. Values should not be returned.
+	//
+	// "version" contains the version of the file contents being
+	// checked.
+	//
+	// "error" should be returned when the key is not found.
+	KeyHistory(file *catalog.File, key, version string) ([]KeyChange, error)
+}
+
+// IRevisionStore is optionally implemented by stores keeping each
+// saved state of a file as a revision; so, revisions can be listed,
+// pulled, and promoted to the latest state.
+type IRevisionStore interface {
+
+	// Revisions should return the saved states of the file with the
+	// newest first. The latest state should be marked.
+	//
+	// "version" contains the version of the file contents being
+	// checked.
+	//
+	// "error" should return nil if the operation was successful.
+	Revisions(file *catalog.File, version string) ([]Revision, error)
+
+	// PullRevision should return the contents of the file as they were
+	// saved in the revision.
+	//
+	// "version" contains the version of the file contents being
+	// retrieved.
+	//
+	// "Attributes" should return the time the revision was saved.
+	//
+	// "error" should be returned when the revision is not found.
+	PullRevision(file *catalog.File, version, revision string) ([]byte, Attributes, error)
+}
+
+// IValueStore is optionally implemented by stores able to look up a
+// single value by name; so, templates rendered on pull can use values
+// saved outside of cataloged files.
+type IValueStore interface {
+
+	// Value should return the value saved under the full name, like
+	// an SSM parameter name.
+	//
+	// "error" should be returned when the value is not found.
+	Value(name string) (string, error)
+}
+
+// IKeyTypeStore is optionally implemented by stores saving a type for
+// each key; so, key types can be changed without pushing values.
+type IKeyTypeStore interface {
+
+	// SetKeyTypes should change the stored type of each key to the
+	// store's type for the catalog key type, leaving values unchanged.
+	//
+	// "types" maps each key to a catalog key type, like "plain".
+	//
+	// "version" contains the version of the file contents being
+	// changed.
+	//
+	// "error" should be returned when a key is not stored.
+	SetKeyTypes(file *catalog.File, types map[string]string, version string) error
+}
+
+// IEncryptionStore is optionally implemented by stores able to report
+// how stored files and keys are encrypted at rest.
+type IEncryptionStore interface {
+
+	// Encryption should describe how the file is encrypted. Stores
+	// saving keys individually should also describe each stored key;
+	// otherwise, "keys" is nil and every key shares the file's
+	// encryption.
+	//
+	// "version" contains the version of the file contents being
+	// described.
+	//
+	// "error" should return nil if the operation was successful.
+	Encryption(file *catalog.File, version string) (Encryption, map[string]Encryption, error)
+}
+
+// Revision describes a saved state of a file or secret.
+type Revision struct {
+	// ID identifies the revision in the store, like an object version
+	// id.
+	ID string
+
+	// Modified is when the revision was saved.
+	Modified time.Time
+
+	// By is the principal that saved the revision when the store
+	// records it.
+	By string
+
+	// Labels are the names the store gives the revision, like
+	// AWSCURRENT.
+	Labels []string
+
+	// Latest is true for the revision returned by a pull.
+	Latest bool
+}
+
+// KeyChange describes a change to a key's value.
+type KeyChange struct {
+	// Revision identifies the change in the store, like a parameter or
+	// secret version.
+	Revision string
+
+	// Modified is when the value changed.
+	Modified time.Time
+
+	// By is the principal that changed the value when the store
+	// records it.
+	By string
+
+	// Removed is true when the key was removed from the file.
+	Removed bool
+}
+
+// Location describes where a store reads and writes a file.
+type Location struct {
+	// Remote is the full remote path, URL, or ARN.
+	Remote string
+
+	// Credentials describes where the store credentials come from.
+	Credentials string
+
+	// Encryption describes the key used to encrypt the data.
+	Encryption string
+}
+
+// Encryption mechanisms protecting stored data.
+const (
+	// EncryptionNone data is saved in plain text.
+	EncryptionNone = "none"
+
+	// EncryptionSSE data is encrypted by the store using keys it
+	// manages.
+	EncryptionSSE = "sse"
+
+	// EncryptionKMS data is encrypted by the store using a KMS key.
+	EncryptionKMS = "kms"
+
+	// EncryptionClientSide data is encrypted before it leaves the
+	// machine.
+	EncryptionClientSide = "client-side"
+
+	// EncryptionUnknown data is protected in a way the store cannot
+	// report.
+	EncryptionUnknown = "unknown"
+)
+
+// Encryption describes how a file or key is encrypted at rest.
+type Encryption struct {
+	// Mechanism is one of the Encryption* mechanisms.
+	Mechanism string
+
+	// Detail describes the key or setting used, like a KMS key id.
+	Detail string
+}
+
 // ErrStoreNotFound is returned when the store is not implemented.
 var ErrStoreNotFound = errors.New("store not found")
 
@@ -121,3 +444,51 @@ var ErrStoreNotFound = errors.New("store not found")
 type Attributes struct {
 	LastModified time.Time
 }
+
+// PurgeError is returned by Purge when only some of a file's keys
+// could be deleted. Failed maps each key that remains in the store to
+// the reason it could not be deleted.
+type PurgeError struct {
+	Failed map[string]string
+}
+
+func (e PurgeError) Error() string {
+	return fmt.Sprintf("%d key(s) could not be deleted", len(e.Failed))
+}
+
+// PushError is returned by Push when only some of a file's keys could
+// be pushed. Remaining lists the keys that were not pushed, including
+// the key that failed.
+type PushError struct {
+	Remaining []string
+	Err       error
+}
+
+func (e PushError) Error() string {
+	return fmt.Sprintf("%d key(s) were not pushed (%s)", len(e.Remaining), e.Err)
+}
+
+// AccessDeniedError is returned by stores when the credentials used are
+// not authorized to perform an action. It explains which permission is
+// missing instead of only returning the store's error code.
+type AccessDeniedError struct {
+	// Action is the denied action, like an IAM action or HTTP request.
+	Action string
+
+	// Resource is the ARN, path, or URL the action was denied on.
+	Resource string
+
+	// Principal is the identity the store was acting as when known.
+	Principal string
+
+	Err error
+}
+
+func (e AccessDeniedError) Error() string {
+	principal := e.Principal
+	if len(principal) == 0 {
+		principal = "unknown principal"
+	}
+
+	return fmt.Sprintf("access denied for %s on %s as %s (%s). Use 'which' command to check the credentials and location used.", e.Action, e.Resource, principal, e.Err)
+}
@@ -2,6 +2,7 @@ package contract
 
 import (
 	"errors"
+	"time"
 
 	"github.com/turnerlabs/cstore/components/catalog"
 	"github.com/turnerlabs/cstore/components/models"
@@ -95,6 +96,60 @@ type IVault interface {
 	BuildKey(contextID, group, prop string) string
 }
 
+// IExpiringVault is optionally implemented by vaults able to record
+// when a cached credential, like a session token, expires. Stores use
+// the expiry to refresh credentials before they are used instead of
+// failing in the middle of an operation.
+type IExpiringVault interface {
+
+	// SetExpiry should record when the secret expires.
+	//
+	// "contextID", "group", and "prop" identify the secret the same
+	// way they do when calling Set.
+	//
+	// "error" should be nil if operation was successful.
+	SetExpiry(contextID, group, prop string, expires time.Time) error
+
+	// Expiry should return when the secret expires or time.Time{} when
+	// the expiry is unknown.
+	//
+	// "error" should be nil if operation was successful.
+	Expiry(contextID, group, prop string) (time.Time, error)
+}
+
+// IListableVault is optionally implemented by vaults able to list
+// their secrets; so, they can be exported and imported on another
+// machine.
+type IListableVault interface {
+
+	// List should return every secret in the vault by the key built
+	// with BuildKey.
+	//
+	// "error" should be nil if operation was successful.
+	List() (map[string]string, error)
+}
+
+// IRevisionVault is optionally implemented by vaults keeping earlier
+// values of a secret group; so, an earlier value can be restored as the
+// current one.
+type IRevisionVault interface {
+
+	// Revisions should return the saved values of the group with the
+	// newest first. The current value should be marked latest. Values
+	// should not be returned.
+	//
+	// "contextID" and "group" identify the secret the same way they do
+	// when calling Set.
+	//
+	// "error" should be nil if operation was successful.
+	Revisions(contextID, group string) ([]Revision, error)
+
+	// Restore should make the revision the current value of the group.
+	//
+	// "error" should be returned when the revision is not found.
+	Restore(contextID, group, revision string) error
+}
+
 // ErrSecretNotFound is returned by the vault when the
 // requested key cannot be found in the vault.
 var ErrSecretNotFound = errors.New("not found")
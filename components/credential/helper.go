@@ -0,0 +1,130 @@
+package credential
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HelperPrefix is prepended to a helper name to find the helper
+// binary on the path. A helper named "sts" is run as
+// "cstore-credential-sts".
+const HelperPrefix = "cstore-credential-"
+
+// Request is written as JSON to the helper's stdin.
+type Request struct {
+	Store   string `json:"store"`
+	Context string `json:"context"`
+	File    string `json:"file"`
+}
+
+// Response is read as JSON from the helper's stdout. Env contains the
+// environment variables the store reads credentials from like
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN.
+type Response struct {
+	Env        map[string]string `json:"env"`
+	Expiration time.Time         `json:"expiration"`
+}
+
+// Expired ...
+func (r Response) Expired() bool {
+	return !r.Expiration.IsZero() && time.Now().After(r.Expiration)
+}
+
+// Export sets the credentials as environment variables for the
+// current process.
+func (r Response) Export() error {
+	for key, value := range r.Env {
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	cache = map[string]Response{}
+
+	// caching serializes helper runs; so, files prepared concurrently
+	// share the credentials of the first run instead of racing on the
+	// cache or prompting for the same login more than once.
+	caching sync.Mutex
+)
+
+// Get runs the helper with the "get" command and returns the
+// credentials. Built-in helpers, like "oidc", are run in process.
+// Credentials are reused for the same store and context until they
+// expire.
+func Get(helper string, req Request) (Response, error) {
+	key := fmt.Sprintf("%s|%s|%s", helper, req.Store, req.Context)
+
+	caching.Lock()
+	defer caching.Unlock()
+
+	if r, found := cache[key]; found && !r.Expired() {
+		return r, nil
+	}
+
+	run := runHelper
+	if broker, found := brokers[helper]; found {
+		run = func(_ string, req Request) (Response, error) {
+			return broker(req)
+		}
+	}
+
+	r, err := run(helper, req)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if r.Expired() {
+		return Response{}, fmt.Errorf("credential helper %s returned expired credentials", helper)
+	}
+
+	cache[key] = r
+
+	return r, nil
+}
+
+// runHelper runs a helper binary writing the request to stdin and
+// reading the response from stdout.
+func runHelper(helper string, req Request) (Response, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	c := exec.Command(binary(helper), "get")
+	c.Stdin = bytes.NewReader(input)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return Response{}, fmt.Errorf("credential helper %s: %s", helper, msg)
+		}
+		return Response{}, fmt.Errorf("credential helper %s: %s", helper, err)
+	}
+
+	r := Response{}
+	if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+		return Response{}, fmt.Errorf("credential helper %s returned invalid JSON (%s)", helper, err)
+	}
+
+	return r, nil
+}
+
+// binary allows a helper to be specified by name or by path.
+func binary(helper string) string {
+	if strings.ContainsRune(helper, os.PathSeparator) {
+		return helper
+	}
+	return HelperPrefix + helper
+}
@@ -0,0 +1,64 @@
+package credential
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestGetReadsHelperResponse(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "cstore-credential")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	helper := filepath.Join(dir, "helper")
+	script := "#!/bin/sh\ncat > /dev/null\necho '{\"env\":{\"AWS_SESSION_TOKEN\":\"token\"}}'\n"
+
+	if err := ioutil.WriteFile(helper, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	r, err := Get(helper, Request{Store: "aws-s3", Context: "test"})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Env["AWS_SESSION_TOKEN"] != "token" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "token", r.Env["AWS_SESSION_TOKEN"])
+	}
+}
+
+func TestGetConcurrentlyRunsBrokerOnce(t *testing.T) {
+	// arrange
+	runs := 0
+	brokers["test-broker"] = func(req Request) (Response, error) {
+		runs++
+		return Response{Env: map[string]string{"TOKEN": "token"}}, nil
+	}
+	defer delete(brokers, "test-broker")
+
+	var wg sync.WaitGroup
+
+	// act
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Get("test-broker", Request{Store: "aws-s3", Context: "concurrent"})
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	if runs != 1 {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %d", 1, runs)
+	}
+}
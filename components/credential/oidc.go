@@ -0,0 +1,210 @@
+package credential
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+//------------------------------------------
+//- CI systems and Kubernetes give jobs an
+//- OIDC token identifying them. The token
+//- can be exchanged for short-lived store
+//- credentials; so, no static credentials
+//- need to be saved in the CI system.
+//------------------------------------------
+
+const (
+	// OIDCHelper is the built-in helper exchanging an ambient OIDC token
+	// for AWS credentials by assuming a role with STS.
+	OIDCHelper = "oidc"
+
+	oidcTokenEnv    = "CSTORE_OIDC_TOKEN"
+	oidcTokenFile   = "CSTORE_OIDC_TOKEN_FILE"
+	oidcAudienceEnv = "CSTORE_OIDC_AUDIENCE"
+	oidcRoleEnv     = "CSTORE_OIDC_ROLE_ARN"
+
+	awsRoleEnv      = "AWS_ROLE_ARN"
+	awsTokenFileEnv = "AWS_WEB_IDENTITY_TOKEN_FILE"
+
+	githubRequestURL   = "ACTIONS_ID_TOKEN_REQUEST_URL"
+	githubRequestToken = "ACTIONS_ID_TOKEN_REQUEST_TOKEN"
+
+	kubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	// STSAudience is the audience AWS expects in tokens exchanged with
+	// STS.
+	STSAudience = "sts.amazonaws.com"
+)
+
+// brokers are helpers built into cStore, run instead of a helper binary.
+var brokers = map[string]func(Request) (Response, error){
+	OIDCHelper: assumeRoleWithWebIdentity,
+}
+
+// OIDCToken finds the OIDC token identifying the current job. The
+// token is read from CSTORE_OIDC_TOKEN, like a GitLab CI id_token, the
+// file in CSTORE_OIDC_TOKEN_FILE or AWS_WEB_IDENTITY_TOKEN_FILE,
+// requested from GitHub Actions, or read from the Kubernetes service
+// account token, in that order. CSTORE_OIDC_AUDIENCE overrides the
+// audience requested from GitHub Actions. The source of the token is
+// returned for error messages.
+func OIDCToken(audience string) (string, string, error) {
+	if aud := os.Getenv(oidcAudienceEnv); len(aud) > 0 {
+		audience = aud
+	}
+
+	if token := os.Getenv(oidcTokenEnv); len(token) > 0 {
+		return strings.TrimSpace(token), oidcTokenEnv, nil
+	}
+
+	for _, name := range []string{oidcTokenFile, awsTokenFileEnv} {
+		if path := os.Getenv(name); len(path) > 0 {
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return "", name, fmt.Errorf("could not read the OIDC token in %s (%s)", name, err)
+			}
+
+			return strings.TrimSpace(string(b)), name, nil
+		}
+	}
+
+	if len(os.Getenv(githubRequestURL)) > 0 {
+		token, err := githubToken(audience)
+		return token, "GitHub Actions", err
+	}
+
+	if b, err := ioutil.ReadFile(kubernetesTokenFile); err == nil {
+		return strings.TrimSpace(string(b)), "Kubernetes service account", nil
+	}
+
+	return "", "", fmt.Errorf("no OIDC token found; set %s or %s, or grant the GitHub Actions job the id-token: write permission", oidcTokenEnv, oidcTokenFile)
+}
+
+// githubToken requests an OIDC token from the GitHub Actions runner. The
+// job must have the "id-token: write" permission.
+func githubToken(audience string) (string, error) {
+	u, err := url.Parse(os.Getenv(githubRequestURL))
+	if err != nil {
+		return "", err
+	}
+
+	if len(audience) > 0 {
+		q := u.Query()
+		q.Set("audience", audience)
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+os.Getenv(githubRequestToken))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not request a GitHub Actions OIDC token (%s)", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("could not request a GitHub Actions OIDC token (%s)", resp.Status)
+	}
+
+	output := struct {
+		Value string `json:"value"`
+	}{}
+
+	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+		return "", err
+	}
+
+	if len(output.Value) == 0 {
+		return "", errors.New("GitHub Actions returned an empty OIDC token")
+	}
+
+	return output.Value, nil
+}
+
+// assumeRoleWithWebIdentity exchanges the OIDC token for temporary AWS
+// credentials for the role in CSTORE_OIDC_ROLE_ARN or AWS_ROLE_ARN.
+func assumeRoleWithWebIdentity(req Request) (Response, error) {
+	role := os.Getenv(oidcRoleEnv)
+	if len(role) == 0 {
+		role = os.Getenv(awsRoleEnv)
+	}
+
+	if len(role) == 0 {
+		return Response{}, fmt.Errorf("credential helper %s: set %s to the IAM role to assume", OIDCHelper, oidcRoleEnv)
+	}
+
+	token, source, err := OIDCToken(STSAudience)
+	if err != nil {
+		return Response{}, fmt.Errorf("credential helper %s: %s", OIDCHelper, err)
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if len(region) == 0 {
+		region = "us-east-1"
+	}
+
+	// The token authenticates the request; so, no AWS credentials
+	// are needed to call STS.
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		Credentials: credentials.AnonymousCredentials,
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	output, err := sts.New(sess).AssumeRoleWithWebIdentity(&sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(role),
+		RoleSessionName:  aws.String(sessionName(req)),
+		WebIdentityToken: aws.String(token),
+	})
+	if err != nil {
+		return Response{}, fmt.Errorf("credential helper %s: could not assume %s with the %s token (%s)", OIDCHelper, role, source, err)
+	}
+
+	if output.Credentials == nil {
+		return Response{}, fmt.Errorf("credential helper %s: STS returned no credentials for %s", OIDCHelper, role)
+	}
+
+	return Response{
+		Env: map[string]string{
+			"AWS_ACCESS_KEY_ID":     aws.StringValue(output.Credentials.AccessKeyId),
+			"AWS_SECRET_ACCESS_KEY": aws.StringValue(output.Credentials.SecretAccessKey),
+			"AWS_SESSION_TOKEN":     aws.StringValue(output.Credentials.SessionToken),
+		},
+		Expiration: aws.TimeValue(output.Credentials.Expiration),
+	}, nil
+}
+
+var sessionNameInvalid = regexp.MustCompile(`[^\w+=,.@-]`)
+
+// sessionName identifies cStore and the catalog in CloudTrail using only
+// the characters and length STS allows.
+func sessionName(req Request) string {
+	name := sessionNameInvalid.ReplaceAllString(fmt.Sprintf("cstore-%s", req.Context), "-")
+
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	return name
+}
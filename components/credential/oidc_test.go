@@ -0,0 +1,72 @@
+package credential
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestOIDCTokenRequestsGitHubActionsToken(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer request-token" || r.URL.Query().Get("audience") != STSAudience {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Write([]byte(`{"value":"id-token"}`))
+	}))
+	defer server.Close()
+
+	os.Setenv(githubRequestURL, server.URL+"?api-version=2.0")
+	os.Setenv(githubRequestToken, "request-token")
+	defer os.Unsetenv(githubRequestURL)
+	defer os.Unsetenv(githubRequestToken)
+
+	// act
+	token, source, err := OIDCToken(STSAudience)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "id-token" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "id-token", token)
+	}
+
+	if source != "GitHub Actions" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "GitHub Actions", source)
+	}
+}
+
+func TestOIDCTokenPrefersTokenVariable(t *testing.T) {
+	// arrange
+	os.Setenv(oidcTokenEnv, "gitlab-token\n")
+	os.Setenv(githubRequestURL, "http://127.0.0.1:0")
+	defer os.Unsetenv(oidcTokenEnv)
+	defer os.Unsetenv(githubRequestURL)
+
+	// act
+	token, _, err := OIDCToken(STSAudience)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "gitlab-token" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "gitlab-token", token)
+	}
+}
+
+func TestSessionNameRemovesInvalidCharacters(t *testing.T) {
+	// act
+	name := sessionName(Request{Context: "my app/dev"})
+
+	// assert
+	if name != "cstore-my-app-dev" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "cstore-my-app-dev", name)
+	}
+}
@@ -0,0 +1,144 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/turnerlabs/cstore/components/cipher"
+)
+
+// Data keys are wrapped for age recipients as age encrypted files; so,
+// keys created with age-keygen can be used and a wrapped key can be
+// decrypted with the age CLI. https://age-encryption.org/v1
+
+// AgeKey wraps data keys for an age X25519 public key like "age1...".
+type AgeKey struct {
+	Recipient string
+
+	recipient *age.X25519Recipient
+}
+
+// NewAgeKey validates an age public key.
+func NewAgeKey(recipient string) (AgeKey, error) {
+	if cipher.FIPS() {
+		return AgeKey{}, errors.New("age recipients use X25519 and ChaCha20-Poly1305 which are not FIPS-approved")
+	}
+
+	r, err := age.ParseX25519Recipient(recipient)
+	if err != nil {
+		return AgeKey{}, fmt.Errorf("invalid age recipient %s (%s)", recipient, err)
+	}
+
+	return AgeKey{Recipient: r.String(), recipient: r}, nil
+}
+
+// Type ...
+func (a AgeKey) Type() string {
+	return AgeRecipient
+}
+
+// ID ...
+func (a AgeKey) ID() string {
+	return a.Recipient
+}
+
+// Wrap ...
+func (a AgeKey) Wrap(dataKey []byte) ([]byte, error) {
+	var wrapped bytes.Buffer
+
+	w, err := age.Encrypt(&wrapped, a.recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(dataKey); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return wrapped.Bytes(), nil
+}
+
+// AgeIdentity unwraps data keys with an age secret key like
+// "AGE-SECRET-KEY-1...".
+type AgeIdentity struct {
+	identity *age.X25519Identity
+}
+
+// NewAgeIdentity parses an age secret key.
+func NewAgeIdentity(identity string) (AgeIdentity, error) {
+	if cipher.FIPS() {
+		return AgeIdentity{}, errors.New("age identities use X25519 and ChaCha20-Poly1305 which are not FIPS-approved")
+	}
+
+	i, err := age.ParseX25519Identity(identity)
+	if err != nil {
+		return AgeIdentity{}, fmt.Errorf("invalid age identity (%s)", err)
+	}
+
+	return AgeIdentity{identity: i}, nil
+}
+
+// ReadAgeIdentities parses the secret keys in an age identity file
+// ignoring comments and blank lines.
+func ReadAgeIdentities(path string) ([]AgeIdentity, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	identities := []AgeIdentity{}
+
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		identity, err := NewAgeIdentity(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%s)", path, err)
+		}
+
+		identities = append(identities, identity)
+	}
+
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("%s has no age identities", path)
+	}
+
+	return identities, nil
+}
+
+// Recipient returns the public key of the identity.
+func (a AgeIdentity) Recipient() string {
+	return a.identity.Recipient().String()
+}
+
+// Type ...
+func (a AgeIdentity) Type() string {
+	return AgeRecipient
+}
+
+// Matches ...
+func (a AgeIdentity) Matches(id string) bool {
+	return id == a.Recipient()
+}
+
+// Unwrap ...
+func (a AgeIdentity) Unwrap(wrapped []byte) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(wrapped), a.identity)
+	if err != nil {
+		return nil, errors.New("age identity does not match the wrapped key")
+	}
+
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,183 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//------------------------------------------
+//- Envelope encryption encrypts each file
+//- with a new data key and saves the data
+//- key wrapped by every recipient, like a
+//- KMS key, an age public key, or a
+//- passphrase, with the encrypted file.
+//- Any one recipient can decrypt the file.
+//------------------------------------------
+
+const (
+	// Recipient types able to wrap data keys.
+	KMSRecipient        = "kms"
+	AgeRecipient        = "age"
+	PassphraseRecipient = "passphrase"
+
+	envelopeVersion = 1
+	envelopeCipher  = "AES-256-GCM"
+	dataKeySize     = 32
+)
+
+// RecipientTypes lists the supported recipient types.
+var RecipientTypes = []string{KMSRecipient, AgeRecipient, PassphraseRecipient}
+
+// ErrNoRecipient is returned when none of the keys able to unwrap the
+// data key are available.
+var ErrNoRecipient = errors.New("no available key can decrypt the file")
+
+// Wrapper encrypts data keys for a recipient.
+type Wrapper interface {
+	Type() string
+	ID() string
+	Wrap(dataKey []byte) ([]byte, error)
+}
+
+// Unwrapper decrypts data keys wrapped for a recipient.
+type Unwrapper interface {
+	Type() string
+
+	// Matches returns true when the unwrapper can decrypt a key wrapped
+	// for the recipient id.
+	Matches(id string) bool
+
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+// WrappedKey is the data key encrypted for a recipient.
+type WrappedKey struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	Key  []byte `json:"key"`
+}
+
+// Envelope is an encrypted file with its wrapped data keys.
+type Envelope struct {
+	Version int          `json:"version"`
+	Cipher  string       `json:"cipher"`
+	Keys    []WrappedKey `json:"keys"`
+	Nonce   []byte       `json:"nonce"`
+	Data    []byte       `json:"data"`
+}
+
+// Seal encrypts the data with a new data key wrapped by each wrapper.
+// The additional data, like the file's catalog key, is authenticated
+// but not encrypted; so, the file cannot be moved to another entry.
+func Seal(data, additional []byte, wrappers []Wrapper) ([]byte, error) {
+	if len(wrappers) == 0 {
+		return nil, errors.New("at least one recipient is required")
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	e := Envelope{
+		Version: envelopeVersion,
+		Cipher:  envelopeCipher,
+	}
+
+	for _, w := range wrappers {
+		wrapped, err := w.Wrap(dataKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s recipient %s (%s)", w.Type(), w.ID(), err)
+		}
+
+		e.Keys = append(e.Keys, WrappedKey{Type: w.Type(), ID: w.ID(), Key: wrapped})
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, e.Nonce); err != nil {
+		return nil, err
+	}
+
+	e.Data = gcm.Seal(nil, e.Nonce, data, additional)
+
+	return json.Marshal(e)
+}
+
+// Open decrypts the data using the first unwrapper able to unwrap the
+// data key. Unwrappers are tried in order; so, list those that do not
+// prompt first.
+func Open(sealed, additional []byte, unwrappers []Unwrapper) ([]byte, error) {
+	e, err := Parse(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := []string{}
+
+	for _, u := range unwrappers {
+		for _, k := range e.Keys {
+			if k.Type != u.Type() || !u.Matches(k.ID) {
+				continue
+			}
+
+			dataKey, err := u.Unwrap(k.Key)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s %s: %s", k.Type, k.ID, err))
+				continue
+			}
+
+			gcm, err := newGCM(dataKey)
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := gcm.Open(nil, e.Nonce, e.Data, additional)
+			if err != nil {
+				return nil, errors.New("file was modified or belongs to a different catalog entry")
+			}
+
+			return data, nil
+		}
+	}
+
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("%s (%s)", ErrNoRecipient, strings.Join(failures, "; "))
+	}
+
+	return nil, ErrNoRecipient
+}
+
+// Parse reads the envelope without decrypting it.
+func Parse(sealed []byte) (Envelope, error) {
+	e := Envelope{}
+
+	if err := json.Unmarshal(sealed, &e); err != nil || e.Version == 0 {
+		return e, errors.New("file is not envelope encrypted")
+	}
+
+	if e.Version != envelopeVersion || e.Cipher != envelopeCipher {
+		return e, fmt.Errorf("unsupported envelope version %d (%s)", e.Version, e.Cipher)
+	}
+
+	return e, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,162 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/aws/aws-sdk-go/service/kms"
+)
+
+func newTestIdentity(t *testing.T) AgeIdentity {
+	generated, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := NewAgeIdentity(generated.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return identity
+}
+
+func TestEnvelope(t *testing.T) {
+	identity := newTestIdentity(t)
+
+	recipient, err := NewAgeKey(identity.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prompts := 0
+	passphrase := Passphrase{Get: func() (string, error) {
+		prompts++
+		return "correct horse battery staple", nil
+	}}
+
+	data := []byte("PASSWORD=secret\n")
+	additional := []byte("my-app/config")
+
+	sealed, err := Seal(data, additional, []Wrapper{recipient, passphrase})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Fatal("sealed envelope contains the data")
+	}
+
+	prompts = 0
+
+	opened, err := Open(sealed, additional, []Unwrapper{identity, passphrase})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(opened, data) {
+		t.Errorf("expected %q, got %q", data, opened)
+	}
+
+	if prompts != 0 {
+		t.Error("passphrase was requested although the age identity could unwrap the key")
+	}
+
+	opened, err = Open(sealed, additional, []Unwrapper{newTestIdentity(t), passphrase})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(opened, data) {
+		t.Errorf("expected %q, got %q", data, opened)
+	}
+
+	if _, err := Open(sealed, []byte("other-app/config"), []Unwrapper{identity}); err == nil {
+		t.Error("expected a file from a different catalog entry to fail")
+	}
+
+	wrong := Passphrase{Get: func() (string, error) { return "wrong", nil }}
+
+	if _, err := Open(sealed, additional, []Unwrapper{wrong}); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("expected wrong passphrase error, got %v", err)
+	}
+
+	if _, err := Open(sealed, additional, []Unwrapper{newTestIdentity(t)}); err != ErrNoRecipient {
+		t.Errorf("expected %v, got %v", ErrNoRecipient, err)
+	}
+
+	if _, err := Open(data, additional, []Unwrapper{identity}); err == nil {
+		t.Error("expected unencrypted data to fail")
+	}
+}
+
+type fakeKMS struct {
+	context map[string]*string
+}
+
+func (f fakeKMS) Encrypt(input *kms.EncryptInput) (*kms.EncryptOutput, error) {
+	blob := append([]byte(*input.KeyId+"|"+*input.EncryptionContext["cstore-file"]+"|"), input.Plaintext...)
+	return &kms.EncryptOutput{CiphertextBlob: blob}, nil
+}
+
+func (f fakeKMS) Decrypt(input *kms.DecryptInput) (*kms.DecryptOutput, error) {
+	parts := bytes.SplitN(input.CiphertextBlob, []byte("|"), 3)
+	if string(parts[1]) != *input.EncryptionContext["cstore-file"] {
+		return nil, errors.New("AccessDeniedException")
+	}
+
+	return &kms.DecryptOutput{Plaintext: parts[2]}, nil
+}
+
+func TestKMSKey(t *testing.T) {
+	file := "my-app/config"
+	key := NewKMSKey(fakeKMS{}, "alias/my-app", map[string]*string{"cstore-file": &file})
+
+	sealed, err := Seal([]byte("data"), nil, []Wrapper{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Parse(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.Keys) != 1 || e.Keys[0].Type != KMSRecipient || e.Keys[0].ID != "alias/my-app" {
+		t.Errorf("unexpected wrapped keys %+v", e.Keys)
+	}
+
+	opened, err := Open(sealed, nil, []Unwrapper{key})
+	if err != nil || string(opened) != "data" {
+		t.Errorf("expected data, got %q (%v)", opened, err)
+	}
+
+	other := "other-app/config"
+	if _, err := Open(sealed, nil, []Unwrapper{NewKMSKey(fakeKMS{}, "", map[string]*string{"cstore-file": &other})}); err == nil {
+		t.Error("expected the encryption context to be checked")
+	}
+}
+
+func TestNewAgeKey(t *testing.T) {
+	valid := "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"
+
+	if _, err := NewAgeKey(valid); err != nil {
+		t.Error(err)
+	}
+
+	invalid := []string{
+		"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8q",
+		"Age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p",
+		"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8b",
+		"ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p",
+	}
+
+	for _, s := range invalid {
+		if _, err := NewAgeKey(s); err == nil {
+			t.Errorf("expected %s to be invalid", s)
+		}
+	}
+}
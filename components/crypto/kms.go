@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"github.com/aws/aws-sdk-go/service/kms"
+)
+
+// KMSClient is the part of the AWS KMS API used to wrap data keys.
+type KMSClient interface {
+	Encrypt(*kms.EncryptInput) (*kms.EncryptOutput, error)
+	Decrypt(*kms.DecryptInput) (*kms.DecryptOutput, error)
+}
+
+// KMSKey wraps data keys with an AWS KMS key. The encryption context
+// binds the wrapped key to the file's catalog entry.
+type KMSKey struct {
+	KeyID   string
+	Context map[string]*string
+
+	svc KMSClient
+}
+
+// NewKMSKey ...
+func NewKMSKey(svc KMSClient, keyID string, context map[string]*string) KMSKey {
+	return KMSKey{KeyID: keyID, Context: context, svc: svc}
+}
+
+// Type ...
+func (k KMSKey) Type() string {
+	return KMSRecipient
+}
+
+// ID ...
+func (k KMSKey) ID() string {
+	return k.KeyID
+}
+
+// Matches returns true for any key id; KMS finds the key from the
+// wrapped key and checks that the caller may use it.
+func (k KMSKey) Matches(id string) bool {
+	return true
+}
+
+// Wrap ...
+func (k KMSKey) Wrap(dataKey []byte) ([]byte, error) {
+	output, err := k.svc.Encrypt(&kms.EncryptInput{
+		KeyId:             &k.KeyID,
+		Plaintext:         dataKey,
+		EncryptionContext: k.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.CiphertextBlob, nil
+}
+
+// Unwrap ...
+func (k KMSKey) Unwrap(wrapped []byte) ([]byte, error) {
+	output, err := k.svc.Decrypt(&kms.DecryptInput{
+		CiphertextBlob:    wrapped,
+		EncryptionContext: k.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Plaintext, nil
+}
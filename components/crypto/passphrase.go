@@ -0,0 +1,96 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	passphraseIterations = 600000
+	passphraseSaltSize   = 16
+)
+
+// Passphrase wraps data keys with a key derived from a passphrase using
+// PBKDF2. Get is only called when a key is wrapped or unwrapped; so, the
+// user is not asked for the passphrase when another recipient is used.
+type Passphrase struct {
+	Get func() (string, error)
+}
+
+// Type ...
+func (p Passphrase) Type() string {
+	return PassphraseRecipient
+}
+
+// ID ...
+func (p Passphrase) ID() string {
+	return ""
+}
+
+// Matches ...
+func (p Passphrase) Matches(id string) bool {
+	return true
+}
+
+// Wrap ...
+func (p Passphrase) Wrap(dataKey []byte) ([]byte, error) {
+	salt := make([]byte, passphraseSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	passphrase, err := p.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+
+	gcm, err := newGCM(pbkdf2.Key([]byte(passphrase), salt, passphraseIterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	wrapped := append(salt, nonce...)
+
+	return gcm.Seal(wrapped, nonce, dataKey, nil), nil
+}
+
+// Unwrap ...
+func (p Passphrase) Unwrap(wrapped []byte) ([]byte, error) {
+	if len(wrapped) < passphraseSaltSize+12 {
+		return nil, errors.New("wrapped key is too short")
+	}
+
+	passphrase, err := p.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	salt := wrapped[:passphraseSaltSize]
+
+	gcm, err := newGCM(pbkdf2.Key([]byte(passphrase), salt, passphraseIterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := wrapped[passphraseSaltSize : passphraseSaltSize+gcm.NonceSize()]
+
+	dataKey, err := gcm.Open(nil, nonce, wrapped[passphraseSaltSize+gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("wrong passphrase")
+	}
+
+	return dataKey, nil
+}
@@ -0,0 +1,251 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Op is how a line changed between two files.
+type Op int
+
+const (
+	// Equal lines are in both files.
+	Equal Op = iota
+
+	// Delete lines are only in the first file.
+	Delete
+
+	// Insert lines are only in the second file.
+	Insert
+)
+
+// maxCells limits the size of the table used to find the longest common
+// lines; larger changes are reported as replacing every line.
+const maxCells = 4000000
+
+// Line is a line of an edit script.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines returns the edit script turning a into b.
+func Lines(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := []Line{}
+
+	for _, text := range a[:prefix] {
+		lines = append(lines, Line{Equal, text})
+	}
+
+	lines = append(lines, middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Equal, text})
+	}
+
+	return lines
+}
+
+// middle finds the edit script for the changed lines using the longest
+// common subsequence.
+func middle(a, b []string) []Line {
+	lines := []Line{}
+
+	if len(a)*len(b) > maxCells {
+		for _, text := range a {
+			lines = append(lines, Line{Delete, text})
+		}
+		for _, text := range b {
+			lines = append(lines, Line{Insert, text})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Equal, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Delete, a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Insert, b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		lines = append(lines, Line{Delete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, Line{Insert, b[j]})
+	}
+
+	return lines
+}
+
+// Hunk is a group of changed lines with the unchanged lines around them.
+type Hunk struct {
+	AStart, ALen int
+	BStart, BLen int
+
+	Lines []Line
+}
+
+// Header returns the unified diff range of the hunk, like
+// "@@ -1,4 +1,5 @@".
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.AStart, h.ALen, h.BStart, h.BLen)
+}
+
+// Hunks groups the changes in an edit script with up to context
+// unchanged lines before and after each change.
+func Hunks(lines []Line, context int) []Hunk {
+	hunks := []Hunk{}
+
+	// aLine and bLine are the line numbers, starting at 1, of each
+	// line in the edit script.
+	aLine, bLine := make([]int, len(lines)), make([]int, len(lines))
+	a, b := 1, 1
+	for i, l := range lines {
+		aLine[i], bLine[i] = a, b
+		if l.Op != Insert {
+			a++
+		}
+		if l.Op != Delete {
+			b++
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Op == Equal {
+			continue
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// Extend the hunk until a run of unchanged lines is long
+		// enough to separate it from the next change.
+		end, equal := i, 0
+		for ; end < len(lines) && equal <= context*2; end++ {
+			if lines[end].Op == Equal {
+				equal++
+			} else {
+				equal = 0
+			}
+		}
+
+		if equal > context {
+			end -= equal - context
+		}
+
+		h := Hunk{AStart: aLine[start], BStart: bLine[start], Lines: lines[start:end]}
+		for _, l := range h.Lines {
+			if l.Op != Insert {
+				h.ALen++
+			}
+			if l.Op != Delete {
+				h.BLen++
+			}
+		}
+
+		// Empty ranges start at the line before them.
+		if h.ALen == 0 {
+			h.AStart--
+		}
+		if h.BLen == 0 {
+			h.BStart--
+		}
+
+		hunks = append(hunks, h)
+		i = end - 1
+	}
+
+	return hunks
+}
+
+// Change is how a key changed between two env files.
+type Change string
+
+const (
+	// Added keys are only in the second file.
+	Added Change = "added"
+
+	// Removed keys are only in the first file.
+	Removed Change = "removed"
+
+	// Changed keys have different values.
+	Changed Change = "changed"
+)
+
+// KeyChange is a key added, removed, or changed between two env files.
+type KeyChange struct {
+	Key    string
+	Change Change
+	Old    string
+	New    string
+}
+
+// Env returns the keys that differ between two env files sorted by key.
+func Env(a, b map[string]string) []KeyChange {
+	changes := []KeyChange{}
+
+	for key, old := range a {
+		value, found := b[key]
+
+		switch {
+		case !found:
+			changes = append(changes, KeyChange{Key: key, Change: Removed, Old: old})
+		case value != old:
+			changes = append(changes, KeyChange{Key: key, Change: Changed, Old: old, New: value})
+		}
+	}
+
+	for key, value := range b {
+		if _, found := a[key]; !found {
+			changes = append(changes, KeyChange{Key: key, Change: Added, New: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHunks(t *testing.T) {
+	// arrange
+	a := strings.Split("a\nb\nc\nd\ne\nf\ng\nh\ni\nj", "\n")
+	b := strings.Split("a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk", "\n")
+
+	// act
+	hunks := Hunks(Lines(a, b), 1)
+
+	// assert
+	headers := []string{}
+	for _, h := range hunks {
+		headers = append(headers, h.Header())
+	}
+
+	expected := []string{"@@ -1,3 +1,3 @@", "@@ -10,1 +10,2 @@"}
+
+	if !reflect.DeepEqual(headers, expected) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected, headers)
+	}
+
+	if !reflect.DeepEqual(hunks[0].Lines, []Line{{Equal, "a"}, {Delete, "b"}, {Insert, "B"}, {Equal, "c"}}) {
+		t.Errorf("\nUNEXPECTED: %v", hunks[0].Lines)
+	}
+}
+
+func TestHunksOfNewFile(t *testing.T) {
+	// act
+	hunks := Hunks(Lines([]string{}, []string{"a", "b"}), 3)
+
+	// assert
+	if len(hunks) != 1 || hunks[0].Header() != "@@ -0,0 +1,2 @@" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "@@ -0,0 +1,2 @@", hunks)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	// act
+	changes := Env(
+		map[string]string{"A": "1", "B": "2", "C": "3"},
+		map[string]string{"A": "1", "B": "two", "D": "4"},
+	)
+
+	// assert
+	expected := []KeyChange{
+		{Key: "B", Change: Changed, Old: "2", New: "two"},
+		{Key: "C", Change: Removed, Old: "3"},
+		{Key: "D", Change: Added, New: "4"},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected, changes)
+	}
+}
+
+func TestReportMarkdown(t *testing.T) {
+	// arrange
+	report := Report{
+		Title: "Pending config changes",
+		Files: []FileReport{
+			{Path: ".env", Store: "aws-parameter", Keys: []KeyChange{
+				{Key: "A", Change: Changed, Old: "1*", New: "a|b"},
+				{Key: "B", Change: Added, New: "2*"},
+			}},
+			{Path: "config.yml", Store: "source-control"},
+		},
+	}
+
+	// act
+	var b strings.Builder
+	if err := report.Markdown(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	for _, expected := range []string{
+		"## Pending config changes",
+		"| `.env` | aws-parameter | 1 | 0 | 1 |",
+		"| `A` | changed | `1*` | `a\\|b` |",
+		"| `B` | added |  | `2*` |",
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, b.String())
+		}
+	}
+
+	if strings.Contains(b.String(), "config.yml") {
+		t.Errorf("\nUNEXPECTED: %s", b.String())
+	}
+}
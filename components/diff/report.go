@@ -0,0 +1,205 @@
+package diff
+
+import (
+	"fmt"
+	html "html/template"
+	"io"
+	"strings"
+	text "text/template"
+)
+
+// FileReport is the differences between a local file and its stored
+// copy. Values should be masked before the report is built.
+type FileReport struct {
+	Path         string
+	Store        string
+	LocalMissing bool
+
+	// Keys are the changed keys of env files.
+	Keys []KeyChange
+
+	// Hunks are the changed lines of other files.
+	Hunks []Hunk
+
+	Binary bool
+}
+
+// Differs returns true when the local file differs from its stored
+// copy.
+func (f FileReport) Differs() bool {
+	return len(f.Keys) > 0 || len(f.Hunks) > 0 || f.Binary
+}
+
+// Count returns the number of keys with the change.
+func (f FileReport) Count(change Change) int {
+	count := 0
+	for _, k := range f.Keys {
+		if k.Change == change {
+			count++
+		}
+	}
+	return count
+}
+
+// Unified returns the changed lines in the unified diff format.
+func (f FileReport) Unified() string {
+	lines := []string{}
+
+	for _, h := range f.Hunks {
+		lines = append(lines, h.Header())
+
+		for _, l := range h.Lines {
+			lines = append(lines, l.String())
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// String returns the line with its unified diff prefix.
+func (l Line) String() string {
+	switch l.Op {
+	case Delete:
+		return "-" + l.Text
+	case Insert:
+		return "+" + l.Text
+	default:
+		return " " + l.Text
+	}
+}
+
+// Report is the pending changes of files for review, like in a pull
+// request.
+type Report struct {
+	Title string
+	Files []FileReport
+}
+
+// Differs returns the files that differ from their stored copies.
+func (r Report) Differs() []FileReport {
+	files := []FileReport{}
+	for _, f := range r.Files {
+		if f.Differs() {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+var funcs = map[string]interface{}{
+	"code": markdownCode,
+}
+
+const markdownReport = `## {{ .Title }}
+
+{{ if .Differs -}}
+| File | Store | Added | Removed | Changed |
+|------|-------|-------|---------|---------|
+{{ range .Differs -}}
+| {{ code .Path }}{{ if .LocalMissing }} (missing locally){{ end }} | {{ .Store }} | {{ if .Keys }}{{ .Count "added" }} | {{ .Count "removed" }} | {{ .Count "changed" }}{{ else }} | | {{ end }} |
+{{ end }}
+{{ range .Differs -}}
+### {{ code .Path }} ({{ .Store }})
+
+{{ if .Binary -}}
+Binary file changed.
+{{ else if .Keys -}}
+| Key | Change | Stored | Local |
+|-----|--------|--------|-------|
+{{ range .Keys -}}
+| {{ code .Key }} | {{ .Change }} | {{ if .Old }}{{ code .Old }}{{ end }} | {{ if .New }}{{ code .New }}{{ end }} |
+{{ end -}}
+{{ else -}}
+` + "```diff" + `
+{{ .Unified }}
+` + "```" + `
+{{ end }}
+{{ end -}}
+{{ else -}}
+No changes. {{ len .Files }} file(s) match their stores.
+{{ end -}}
+`
+
+const htmlReport = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #d0d7de; padding: 4px 10px; text-align: left; }
+code, pre { font-family: SFMono-Regular, Consolas, monospace; }
+pre { background: #f6f8fa; padding: 1em; }
+.added { background: #e6ffec; }
+.removed { background: #ffebe9; }
+.changed { background: #fff8c5; }
+</style>
+</head>
+<body>
+<h2>{{ .Title }}</h2>
+{{ if .Differs -}}
+<table>
+<tr><th>File</th><th>Store</th><th>Added</th><th>Removed</th><th>Changed</th></tr>
+{{ range .Differs -}}
+<tr><td><code>{{ .Path }}</code>{{ if .LocalMissing }} (missing locally){{ end }}</td><td>{{ .Store }}</td>{{ if .Keys }}<td>{{ .Count "added" }}</td><td>{{ .Count "removed" }}</td><td>{{ .Count "changed" }}</td>{{ else }}<td></td><td></td><td></td>{{ end }}</tr>
+{{ end -}}
+</table>
+{{ range .Differs -}}
+<h3><code>{{ .Path }}</code> ({{ .Store }})</h3>
+{{ if .Binary -}}
+<p>Binary file changed.</p>
+{{ else if .Keys -}}
+<table>
+<tr><th>Key</th><th>Change</th><th>Stored</th><th>Local</th></tr>
+{{ range .Keys -}}
+<tr class="{{ .Change }}"><td><code>{{ .Key }}</code></td><td>{{ .Change }}</td><td><code>{{ .Old }}</code></td><td><code>{{ .New }}</code></td></tr>
+{{ end -}}
+</table>
+{{ else -}}
+<pre>{{ .Unified }}</pre>
+{{ end -}}
+{{ end -}}
+{{ else -}}
+<p>No changes. {{ len .Files }} file(s) match their stores.</p>
+{{ end -}}
+</body>
+</html>
+`
+
+// Markdown writes the report as GitHub flavored markdown.
+func (r Report) Markdown(w io.Writer) error {
+	t, err := text.New("report").Funcs(funcs).Parse(markdownReport)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, r)
+}
+
+// HTML writes the report as a standalone HTML page.
+func (r Report) HTML(w io.Writer) error {
+	t, err := html.New("report").Parse(htmlReport)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, r)
+}
+
+// markdownCode formats a value as a code span that is safe to use in a
+// table cell.
+func markdownCode(value string) string {
+	fence := "`"
+	for strings.Contains(value, fence) {
+		fence += "`"
+	}
+
+	value = strings.Replace(value, "|", `\|`, -1)
+
+	if strings.HasPrefix(value, "`") || strings.HasSuffix(value, "`") {
+		value = " " + value + " "
+	}
+
+	return fmt.Sprintf("%s%s%s", fence, value, fence)
+}
@@ -2,19 +2,32 @@ package display
 
 import (
 	"fmt"
-	"io"
 
-	"github.com/fatih/color"
+	"github.com/turnerlabs/cstore/components/message"
+	"github.com/turnerlabs/cstore/components/models"
 )
 
 // Error ...
-func Error(err error, w io.Writer) {
-	ErrorText(err.Error(), w)
+func Error(err error, io models.IO) {
+	ErrorText(err.Error(), io)
+}
+
+// Warn ...
+func Warn(text string, io models.IO) {
+	w, p := Loud(io.UserOutput), io.Messages
+
+	fmt.Fprint(w, "\n"+p.Text(message.WarningIcon))
+	fmt.Fprint(w, p.Style(message.Caution, p.Text(message.WarningLabel)))
+	fmt.Fprintln(w, text)
+	fmt.Fprintln(w)
 }
 
 // ErrorText ...
-func ErrorText(text string, w io.Writer) {
-	color.New(color.Bold, color.FgRed).Fprint(w, "\nERROR: ")
+func ErrorText(text string, io models.IO) {
+	w, p := Loud(io.UserOutput), io.Messages
+
+	fmt.Fprint(w, "\n"+p.Text(message.ErrorIcon))
+	fmt.Fprint(w, p.Style(message.Failure, p.Text(message.ErrorLabel)))
 	fmt.Fprintln(w, text)
 	fmt.Fprintln(w)
 }
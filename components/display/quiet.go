@@ -0,0 +1,23 @@
+package display
+
+import "io"
+
+// QuietWriter discards everything written to it while keeping the
+// original writer available for errors and prompts.
+type QuietWriter struct {
+	W io.Writer
+}
+
+// Write ...
+func (q QuietWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// Loud returns the writer used for output that is displayed even in
+// quiet mode, like errors and prompts.
+func Loud(w io.Writer) io.Writer {
+	if q, ok := w.(QuietWriter); ok {
+		return q.W
+	}
+	return w
+}
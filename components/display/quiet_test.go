@@ -0,0 +1,32 @@
+package display
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+func TestQuietWriterOnlyDisplaysErrors(t *testing.T) {
+	// arrange
+	var buffer bytes.Buffer
+	w := QuietWriter{W: &buffer}
+
+	// act
+	fmt.Fprintln(w, "Retrieving [.env]")
+	Error(errors.New("access denied"), models.IO{UserOutput: w})
+
+	// assert
+	output := buffer.String()
+
+	if strings.Contains(output, "Retrieving") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "no informational output", output)
+	}
+
+	if !strings.Contains(output, "access denied") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "access denied", output)
+	}
+}
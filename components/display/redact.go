@@ -0,0 +1,52 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// Redacted replaces text matching a redaction pattern.
+const Redacted = "[REDACTED]"
+
+// RedactingWriter replaces text matching any of the patterns before it
+// is written; so, identifiers like account IDs and internal host names
+// in store errors are not leaked into shared logs.
+type RedactingWriter struct {
+	W        io.Writer
+	Patterns []*regexp.Regexp
+}
+
+// Write ...
+func (r RedactingWriter) Write(p []byte) (int, error) {
+	if _, err := r.W.Write(Redact(p, r.Patterns)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Redact replaces text matching any of the patterns.
+func Redact(p []byte, patterns []*regexp.Regexp) []byte {
+	for _, pattern := range patterns {
+		p = pattern.ReplaceAllLiteral(p, []byte(Redacted))
+	}
+
+	return p
+}
+
+// CompilePatterns compiles redaction patterns.
+func CompilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := []*regexp.Regexp{}
+
+	for _, p := range patterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s (%s)", p, err)
+		}
+
+		compiled = append(compiled, r)
+	}
+
+	return compiled, nil
+}
@@ -0,0 +1,65 @@
+package display
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+func TestRedactingWriterRedactsErrors(t *testing.T) {
+	// arrange
+	patterns, err := CompilePatterns([]string{`\b\d{12}\b`, `[a-z0-9-]+\.corp\.example\.com`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	w := RedactingWriter{W: &buffer, Patterns: patterns}
+
+	// act
+	fmt.Fprintln(w, "Retrieving [.env] <- [vault.corp.example.com]")
+	Error(errors.New("AccessDenied: arn:aws:iam::123456789012:role/deploy"), models.IO{UserOutput: w})
+
+	// assert
+	output := buffer.String()
+
+	for _, leaked := range []string{"123456789012", "vault.corp.example.com"} {
+		if strings.Contains(output, leaked) {
+			t.Errorf("\nEXPECTED: %s redacted \nACTUAL: %s", leaked, output)
+		}
+	}
+
+	if !strings.Contains(output, "arn:aws:iam::[REDACTED]:role/deploy") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "arn:aws:iam::[REDACTED]:role/deploy", output)
+	}
+}
+
+func TestCompilePatternsInvalid(t *testing.T) {
+	// act
+	_, err := CompilePatterns([]string{"("})
+
+	// assert
+	if err == nil {
+		t.Errorf("\nEXPECTED: error \nACTUAL: nil")
+	}
+}
+
+func TestLoudKeepsRedaction(t *testing.T) {
+	// arrange
+	patterns, _ := CompilePatterns([]string{"secret-host"})
+
+	var buffer bytes.Buffer
+	w := QuietWriter{W: RedactingWriter{W: &buffer, Patterns: patterns}}
+
+	// act
+	Error(errors.New("dial tcp secret-host:443"), models.IO{UserOutput: w})
+
+	// assert
+	if strings.Contains(buffer.String(), "secret-host") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "redacted host", buffer.String())
+	}
+}
@@ -0,0 +1,50 @@
+package display
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// Template renders data using a Go template. When text is the path to
+// an existing file, the file's contents are used as the template.
+func Template(text string, data interface{}, w io.Writer) error {
+	if _, err := os.Stat(text); err == nil {
+		b, err := ioutil.ReadFile(text)
+		if err != nil {
+			return err
+		}
+
+		text = string(b)
+	}
+
+	t, err := template.New("output").Funcs(template.FuncMap{
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"csv":   csvRecord,
+	}).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, data)
+}
+
+// csvRecord formats values as a single CSV line quoting values when
+// required.
+func csvRecord(values ...string) (string, error) {
+	var b bytes.Buffer
+
+	writer := csv.NewWriter(&b)
+	if err := writer.Write(values); err != nil {
+		return "", err
+	}
+	writer.Flush()
+
+	return strings.TrimRight(b.String(), "\n"), writer.Error()
+}
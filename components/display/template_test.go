@@ -0,0 +1,31 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTemplateFormatsCSV(t *testing.T) {
+	// arrange
+	data := []struct {
+		Path string
+		Tags []string
+	}{
+		{Path: "dev/.env", Tags: []string{"dev", "app, web"}},
+	}
+
+	var b bytes.Buffer
+
+	// act
+	err := Template(`{{range .}}{{csv .Path (join .Tags "|")}}{{"\n"}}{{end}}`, data, &b)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "dev/.env,\"dev|app, web\"\n"
+	if b.String() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, b.String())
+	}
+}
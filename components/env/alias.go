@@ -0,0 +1,43 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/subosito/gotenv"
+)
+
+// Alias appends each deprecated key missing from the env file with the
+// value of the key replacing it; so, consumers still reading the old
+// name keep working during a rename. Aliases map deprecated keys to
+// their replacements.
+func Alias(file []byte, aliases map[string]string) []byte {
+	environment := gotenv.Parse(bytes.NewReader(file))
+
+	keys := []string{}
+	for key := range aliases {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	aliased := append([]byte{}, file...)
+	if len(aliased) > 0 && !bytes.HasSuffix(aliased, []byte("\n")) {
+		aliased = append(aliased, '\n')
+	}
+
+	for _, key := range keys {
+		if _, exists := environment[key]; exists {
+			continue
+		}
+
+		value, exists := environment[aliases[key]]
+		if !exists {
+			continue
+		}
+
+		aliased = append(aliased, []byte(fmt.Sprintf("%s=%s\n", key, value))...)
+	}
+
+	return aliased
+}
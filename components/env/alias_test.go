@@ -0,0 +1,39 @@
+package env
+
+import (
+	"testing"
+)
+
+func TestAliasAddsDeprecatedKeys(t *testing.T) {
+	// arrange
+	file := []byte("DATABASE_URL=postgres://db\nAPI_KEY=abc")
+
+	aliases := map[string]string{
+		"DB_URL":  "DATABASE_URL",
+		"API_KEY": "API_TOKEN",
+		"OLD":     "MISSING",
+	}
+
+	// act
+	aliased := Alias(file, aliases)
+
+	// assert
+	expected := "DATABASE_URL=postgres://db\nAPI_KEY=abc\nDB_URL=postgres://db\n"
+
+	if string(aliased) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, string(aliased))
+	}
+}
+
+func TestAliasKeepsExistingKeys(t *testing.T) {
+	// arrange
+	file := []byte("DATABASE_URL=postgres://new\nDB_URL=postgres://old\n")
+
+	// act
+	aliased := Alias(file, map[string]string{"DB_URL": "DATABASE_URL"})
+
+	// assert
+	if string(aliased) != string(file) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", string(file), string(aliased))
+	}
+}
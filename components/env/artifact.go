@@ -0,0 +1,80 @@
+package env
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// k8sSecret is a Kubernetes Secret manifest.
+type k8sSecret struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Type string            `yaml:"type"`
+	Data map[string]string `yaml:"data"`
+}
+
+// FormatK8sSecret converts environment variables into an Opaque
+// Kubernetes Secret manifest ready for 'kubectl apply -f -'.
+func FormatK8sSecret(name string, values map[string]string) ([]byte, error) {
+	s := k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Type:       "Opaque",
+		Data:       map[string]string{},
+	}
+	s.Metadata.Name = name
+
+	for key, value := range values {
+		s.Data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	return yaml.Marshal(s)
+}
+
+// FormatDockerArgs converts environment variables into 'docker run'
+// arguments, like -e 'KEY=VALUE', quoted for a shell.
+func FormatDockerArgs(values map[string]string) []byte {
+	args := []string{}
+
+	for _, key := range sortedKeys(values) {
+		args = append(args, fmt.Sprintf("-e %s", shellQuote(key+"="+values[key])))
+	}
+
+	return []byte(strings.Join(args, " ") + "\n")
+}
+
+// FormatShellExport converts environment variables into shell export
+// commands.
+func FormatShellExport(values map[string]string) []byte {
+	var b bytes.Buffer
+
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(&b, "export %s=%s\n", key, shellQuote(values[key]))
+	}
+
+	return b.Bytes()
+}
+
+// shellQuote wraps a value in single quotes; so, a shell does not
+// expand it.
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,38 @@
+package env
+
+import (
+	"testing"
+)
+
+func TestFormatArtifacts(t *testing.T) {
+	// arrange
+	values := map[string]string{
+		"DB_PASS": "it's",
+		"PORT":    "8080",
+	}
+
+	// act
+	secret, err := FormatK8sSecret("config-dev-env", values)
+	args := FormatDockerArgs(values)
+	exports := FormatShellExport(values)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: config-dev-env\ntype: Opaque\ndata:\n  DB_PASS: aXQncw==\n  PORT: ODA4MA==\n"
+	if string(secret) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, secret)
+	}
+
+	expected = "-e 'DB_PASS=it'\\''s' -e 'PORT=8080'\n"
+	if string(args) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, args)
+	}
+
+	expected = "export DB_PASS='it'\\''s'\nexport PORT='8080'\n"
+	if string(exports) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, exports)
+	}
+}
@@ -0,0 +1,38 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Example returns a copy of an env file safe to commit as a template.
+// Comments and blank lines are kept, values are removed, and each key
+// is annotated with the type returned by keyType.
+func Example(file []byte, keyType func(key string) string) []byte {
+	example := bytes.Buffer{}
+
+	lines := strings.Split(strings.TrimRight(string(file), "\n"), "\n")
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") {
+			example.WriteString(trimmed + "\n")
+			continue
+		}
+
+		key := strings.TrimSpace(strings.TrimPrefix(trimmed, "export "))
+		if i := strings.Index(key, "="); i >= 0 {
+			key = strings.TrimSpace(key[:i])
+		}
+
+		if len(key) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&example, "%s= # %s\n", key, keyType(key))
+	}
+
+	return example.Bytes()
+}
@@ -0,0 +1,36 @@
+package env
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/subosito/gotenv"
+)
+
+func TestExampleRemovesValues(t *testing.T) {
+	// arrange
+	file := []byte("# database\nDB_HOST=localhost\nexport DB_PASS={{env/pass}}\n\nPORT=8080\n")
+
+	keyType := func(key string) string {
+		if key == "PORT" {
+			return "plain"
+		}
+		return "secret"
+	}
+
+	// act
+	example := Example(file, keyType)
+
+	// assert
+	expected := "# database\nDB_HOST= # secret\nDB_PASS= # secret\n\nPORT= # plain\n"
+
+	if string(example) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, string(example))
+	}
+
+	for key, value := range gotenv.Parse(bytes.NewReader(example)) {
+		if len(value) > 0 {
+			t.Errorf("\nEXPECTED: %s= \nACTUAL: %s=%s", key, key, value)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package env
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/subosito/gotenv"
+)
+
+// Layer is the contents of a single env file used when multiple
+// files are merged into one environment.
+type Layer struct {
+	Name string
+	Data []byte
+
+	// ValueTypes are the value types declared for the layer's keys.
+	ValueTypes map[string]string
+}
+
+// Collision describes a key defined by more than one layer. The
+// last layer listed in Sources provided the value that was kept.
+type Collision struct {
+	Key     string
+	Sources []string
+}
+
+// Merge combines the environment variables in each layer. Layers
+// later in the list take precedence over earlier layers; so, a key
+// defined in multiple layers keeps the value from the last layer.
+func Merge(layers []Layer) (gotenv.Env, []Collision) {
+	merged := gotenv.Env{}
+	sources := map[string][]string{}
+
+	for _, l := range layers {
+		for key, value := range gotenv.Parse(bytes.NewReader(l.Data)) {
+			merged[key] = value
+			sources[key] = append(sources[key], l.Name)
+		}
+	}
+
+	collisions := []Collision{}
+	for key, s := range sources {
+		if len(s) > 1 {
+			collisions = append(collisions, Collision{
+				Key:     key,
+				Sources: s,
+			})
+		}
+	}
+
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisions[i].Key < collisions[j].Key
+	})
+
+	return merged, collisions
+}
+
+// MergeValueTypes combines the value types declared for each layer
+// with the same precedence as Merge.
+func MergeValueTypes(layers []Layer) map[string]string {
+	valueTypes := map[string]string{}
+
+	for _, l := range layers {
+		for key, valueType := range l.ValueTypes {
+			valueTypes[key] = valueType
+		}
+	}
+
+	return valueTypes
+}
+
+// Format converts environment variables into the lines of an env file.
+func Format(environment gotenv.Env) []byte {
+	keys := []string{}
+	for key := range environment {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(environment[key])
+		b.WriteString("\n")
+	}
+
+	return b.Bytes()
+}
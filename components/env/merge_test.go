@@ -0,0 +1,59 @@
+package env
+
+import (
+	"testing"
+)
+
+func TestLaterLayersTakePrecedence(t *testing.T) {
+	// arrange
+	layers := []Layer{
+		Layer{
+			Name: "base.env",
+			Data: []byte("URL=http://base\nPORT=80\n"),
+		},
+		Layer{
+			Name: "service.env",
+			Data: []byte("URL=http://service\nNAME=svc\n"),
+		},
+	}
+
+	// act
+	merged, collisions := Merge(layers)
+
+	// assert
+	expected := map[string]string{
+		"URL":  "http://service",
+		"PORT": "80",
+		"NAME": "svc",
+	}
+
+	for key, value := range expected {
+		if merged[key] != value {
+			t.Errorf("\nEXPECTED: %s=%s \nACTUAL: %s=%s", key, value, key, merged[key])
+		}
+	}
+
+	if len(collisions) != 1 || collisions[0].Key != "URL" {
+		t.Errorf("\nEXPECTED: URL collision \nACTUAL: %v", collisions)
+	}
+
+	if len(collisions) == 1 && collisions[0].Sources[1] != "service.env" {
+		t.Errorf("\nEXPECTED: service.env \nACTUAL: %s", collisions[0].Sources[1])
+	}
+}
+
+func TestFormatSortsKeys(t *testing.T) {
+	// arrange
+	environment := map[string]string{
+		"B": "2",
+		"A": "1",
+	}
+
+	// act
+	b := Format(environment)
+
+	// assert
+	if string(b) != "A=1\nB=2\n" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "A=1\nB=2\n", string(b))
+	}
+}
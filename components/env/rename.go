@@ -0,0 +1,30 @@
+package env
+
+import (
+	"strings"
+)
+
+// Rename changes the keys of an env file using the names map. Keys are
+// renamed at the same time; so, two keys can swap names. Values,
+// comments, and the order of lines are kept.
+func Rename(file []byte, names map[string]string) []byte {
+	if len(names) == 0 {
+		return file
+	}
+
+	lines := strings.Split(string(file), "\n")
+
+	for i, line := range lines {
+		key := lineKey(line)
+
+		name, found := names[key]
+		if len(key) == 0 || !found {
+			continue
+		}
+
+		at := strings.Index(line, key)
+		lines[i] = line[:at] + name + line[at+len(key):]
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,20 @@
+package env
+
+import (
+	"testing"
+)
+
+func TestRenameKeepsLines(t *testing.T) {
+	// arrange
+	file := []byte("# db\nexport DATABASE_URL=postgres://db\nDB_USER=app\nDB_PASS=secret\n")
+	names := map[string]string{"DATABASE_URL": "SPRING_DATASOURCE_URL", "DB_USER": "DB_PASS", "DB_PASS": "DB_USER"}
+
+	// act
+	renamed := Rename(file, names)
+
+	// assert
+	expected := "# db\nexport SPRING_DATASOURCE_URL=postgres://db\nDB_PASS=app\nDB_USER=secret\n"
+	if string(renamed) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, string(renamed))
+	}
+}
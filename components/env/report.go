@@ -0,0 +1,120 @@
+package env
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/subosito/gotenv"
+)
+
+const (
+	// LargeValueSize is the size in bytes above which a value is
+	// reported as a possible blob.
+	LargeValueSize = 4096
+
+	// WeakValueBits is the estimated entropy in bits below which a
+	// secret value is reported as weak.
+	WeakValueBits = 40.0
+)
+
+// commonSecrets are values frequently used as placeholder or default
+// secrets.
+var commonSecrets = []string{
+	"password", "password1", "password123", "passw0rd", "secret", "changeme",
+	"admin", "letmein", "welcome", "qwerty", "123456", "12345678", "test", "default",
+}
+
+// ValueStats describes the size and entropy of a key's value without
+// exposing the value.
+type ValueStats struct {
+	Key    string
+	Masked string
+	Size   int
+
+	// Entropy is the Shannon entropy of the value in bits per character
+	// and Bits is the estimated entropy of the whole value.
+	Entropy float64
+	Bits    float64
+
+	Large  bool
+	Common bool
+}
+
+// Weak returns true when a value is a common secret or is too
+// predictable to be a secret.
+func (s ValueStats) Weak() bool {
+	return s.Common || (s.Size > 0 && s.Bits < WeakValueBits)
+}
+
+// Report returns the stats of each value in an env file sorted by key.
+func Report(file []byte) []ValueStats {
+	stats := []ValueStats{}
+
+	for key, value := range gotenv.Parse(bytes.NewReader(file)) {
+		stats = append(stats, Analyze(key, value))
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Key < stats[j].Key
+	})
+
+	return stats
+}
+
+// Analyze ...
+func Analyze(key, value string) ValueStats {
+	entropy := Entropy(value)
+
+	return ValueStats{
+		Key:     key,
+		Masked:  Mask(value),
+		Size:    len(value),
+		Entropy: entropy,
+		Bits:    entropy * float64(len([]rune(value))),
+		Large:   len(value) > LargeValueSize,
+		Common:  isCommon(value),
+	}
+}
+
+// Entropy returns the Shannon entropy of a value in bits per character.
+func Entropy(value string) float64 {
+	runes := []rune(value)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	counts := map[rune]int{}
+	for _, r := range runes {
+		counts[r]++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(len(runes))
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// Mask hides all but the first characters of longer values.
+func Mask(value string) string {
+	runes := []rune(value)
+
+	if len(runes) < 8 {
+		return strings.Repeat("*", len(runes))
+	}
+
+	return string(runes[:2]) + strings.Repeat("*", 6)
+}
+
+func isCommon(value string) bool {
+	for _, common := range commonSecrets {
+		if strings.ToLower(value) == common {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReportFlagsWeakAndLargeValues(t *testing.T) {
+	// arrange
+	file := []byte("PASSWORD=password123\nTOKEN=x8Kq2LmZ9vTb4WnR7pYc1HdF\nCERT=" + strings.Repeat("ab", 3000) + "\n")
+
+	// act
+	stats := Report(file)
+
+	// assert
+	if len(stats) != 3 {
+		t.Fatalf("\nEXPECTED: %d \nACTUAL: %d", 3, len(stats))
+	}
+
+	cert, password, token := stats[0], stats[1], stats[2]
+
+	if !cert.Large || cert.Size != 6000 {
+		t.Errorf("\nEXPECTED: large 6000 byte value \nACTUAL: %v %d", cert.Large, cert.Size)
+	}
+
+	if !password.Weak() {
+		t.Errorf("\nEXPECTED: weak %s \nACTUAL: %.1f bits", password.Key, password.Bits)
+	}
+
+	if token.Weak() || token.Large {
+		t.Errorf("\nEXPECTED: strong %s \nACTUAL: %.1f bits", token.Key, token.Bits)
+	}
+
+	if password.Masked != "pa******" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "pa******", password.Masked)
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	// arrange
+	values := map[string]float64{
+		"":     0,
+		"aaaa": 0,
+		"abab": 1,
+		"abcd": 2,
+	}
+
+	for value, expected := range values {
+		// act
+		actual := Entropy(value)
+
+		// assert
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %.1f \nACTUAL: %.1f", expected, actual)
+		}
+	}
+}
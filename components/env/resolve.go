@@ -0,0 +1,113 @@
+package env
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/subosito/gotenv"
+)
+
+// Resolve combines the changes made to an env file locally and in the
+// store since both were the base file. Keys changed on only one side
+// keep that change. Keys changed differently on both sides keep the
+// local value and are returned as conflicts. Lines and comments of the
+// local file are kept; keys added in the store are appended.
+func Resolve(base, local, remote []byte) ([]byte, []string) {
+	b := gotenv.Parse(bytes.NewReader(base))
+	l := gotenv.Parse(bytes.NewReader(local))
+	r := gotenv.Parse(bytes.NewReader(remote))
+
+	// take lists the keys using the store's value or removal.
+	take := map[string]bool{}
+	conflicts := []string{}
+
+	keys := map[string]bool{}
+	for _, env := range []gotenv.Env{b, l, r} {
+		for key := range env {
+			keys[key] = true
+		}
+	}
+
+	for key := range keys {
+		bv, inBase := b[key]
+		lv, inLocal := l[key]
+		rv, inRemote := r[key]
+
+		switch {
+		case inLocal == inRemote && lv == rv:
+		case inLocal == inBase && lv == bv:
+			take[key] = true
+		case inRemote == inBase && rv == bv:
+		default:
+			conflicts = append(conflicts, key)
+		}
+	}
+
+	sort.Strings(conflicts)
+
+	//------------------------------------------
+	//- Keep the local lines, replacing or
+	//- removing the keys taken from the store.
+	//------------------------------------------
+	remoteLines := map[string]string{}
+	for key, value := range r {
+		remoteLines[key] = key + "=" + value
+	}
+	for _, line := range strings.Split(string(remote), "\n") {
+		if key := lineKey(line); len(key) > 0 {
+			remoteLines[key] = line
+		}
+	}
+
+	resolved := []string{}
+	written := map[string]bool{}
+
+	for _, line := range strings.Split(strings.TrimSuffix(string(local), "\n"), "\n") {
+		key := lineKey(line)
+
+		if len(key) > 0 && take[key] {
+			if _, inRemote := r[key]; !inRemote || written[key] {
+				continue
+			}
+			line = remoteLines[key]
+		}
+
+		if len(key) > 0 {
+			written[key] = true
+		}
+
+		resolved = append(resolved, line)
+	}
+
+	added := []string{}
+	for key := range take {
+		if _, inRemote := r[key]; inRemote && !written[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+
+	for _, key := range added {
+		resolved = append(resolved, remoteLines[key])
+	}
+
+	return []byte(strings.TrimLeft(strings.Join(resolved, "\n"), "\n") + "\n"), conflicts
+}
+
+// lineKey returns the key assigned on a line of an env file.
+func lineKey(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return ""
+	}
+
+	line = strings.TrimPrefix(line, "export ")
+
+	i := strings.IndexAny(line, "=:")
+	if i < 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(line[:i])
+}
@@ -0,0 +1,25 @@
+package env
+
+import (
+	"testing"
+)
+
+func TestResolveKeepsChangesFromBothSides(t *testing.T) {
+	// arrange
+	base := []byte("# db\nDB_HOST=old\nDB_USER=app\nLOG_LEVEL=info\nREMOVED=1\n")
+	local := []byte("# db\nDB_HOST=old\nDB_USER=admin\nLOG_LEVEL=debug\nREMOVED=1\n")
+	remote := []byte("DB_HOST=new\nDB_USER=app\nLOG_LEVEL=warn\nADDED=2\n")
+
+	// act
+	resolved, conflicts := Resolve(base, local, remote)
+
+	// assert
+	expected := "# db\nDB_HOST=new\nDB_USER=admin\nLOG_LEVEL=debug\nADDED=2\n"
+	if string(resolved) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, string(resolved))
+	}
+
+	if len(conflicts) != 1 || conflicts[0] != "LOG_LEVEL" {
+		t.Errorf("\nEXPECTED: [LOG_LEVEL] \nACTUAL: %v", conflicts)
+	}
+}
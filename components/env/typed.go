@@ -0,0 +1,52 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FormatJSON converts typed environment variables into a JSON object.
+func FormatJSON(values map[string]interface{}) ([]byte, error) {
+	b, err := json.MarshalIndent(values, "", "    ")
+	if err != nil {
+		return b, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// FormatYAML converts typed environment variables into a YAML map.
+func FormatYAML(values map[string]interface{}) ([]byte, error) {
+	return yaml.Marshal(values)
+}
+
+// FormatTFVars converts typed environment variables into Terraform
+// variable definitions.
+func FormatTFVars(values map[string]interface{}) ([]byte, error) {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+
+	for _, key := range keys {
+		switch v := values[key].(type) {
+		case string:
+			// Terraform interpolates ${ and %{ sequences in strings
+			escaped := strings.NewReplacer("${", "$${", "%{", "%%{").Replace(v)
+			fmt.Fprintf(&b, "%s = %s\n", key, strconv.Quote(escaped))
+		default:
+			fmt.Fprintf(&b, "%s = %v\n", key, v)
+		}
+	}
+
+	return b.Bytes(), nil
+}
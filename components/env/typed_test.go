@@ -0,0 +1,34 @@
+package env
+
+import (
+	"testing"
+)
+
+func TestFormatTyped(t *testing.T) {
+	// arrange
+	values := map[string]interface{}{
+		"PORT":  int64(8080),
+		"DEBUG": false,
+		"NAME":  "${app}",
+	}
+
+	tests := map[string]func(map[string]interface{}) ([]byte, error){
+		"{\n    \"DEBUG\": false,\n    \"NAME\": \"${app}\",\n    \"PORT\": 8080\n}\n": FormatJSON,
+		"DEBUG: false\nNAME: ${app}\nPORT: 8080\n":                                     FormatYAML,
+		"DEBUG = false\nNAME = \"$${app}\"\nPORT = 8080\n":                             FormatTFVars,
+	}
+
+	for expected, format := range tests {
+		// act
+		b, err := format(values)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(b) != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, b)
+		}
+	}
+}
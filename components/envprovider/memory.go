@@ -0,0 +1,162 @@
+package envprovider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+// MemoryStore is an in-memory store allowing tests to load files
+// without access to a remote store.
+type MemoryStore struct {
+	mutex sync.Mutex
+	files map[string]memoryFile
+}
+
+type memoryFile struct {
+	data     []byte
+	modified time.Time
+}
+
+// NewMemoryStore creates a store containing the files keyed by path.
+func NewMemoryStore(files map[string]string) *MemoryStore {
+	s := &MemoryStore{
+		files: map[string]memoryFile{},
+	}
+
+	for path, data := range files {
+		s.Set(path, "", []byte(data))
+	}
+
+	return s
+}
+
+// Set saves the contents of a file version.
+func (s *MemoryStore) Set(path, version string, data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.files[memoryKey(path, version)] = memoryFile{
+		data:     data,
+		modified: time.Now(),
+	}
+}
+
+// Name ...
+func (s *MemoryStore) Name() string {
+	return "memory"
+}
+
+// SupportsFeature ...
+func (s *MemoryStore) SupportsFeature(feature string) bool {
+	return true
+}
+
+// SupportsFileType ...
+func (s *MemoryStore) SupportsFileType(fileType string) bool {
+	return true
+}
+
+// Description ...
+func (s *MemoryStore) Description() string {
+	return "Files are stored in memory for tests."
+}
+
+// Pre ...
+func (s *MemoryStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	return nil
+}
+
+// Push ...
+func (s *MemoryStore) Push(file *catalog.File, fileData []byte, version string) error {
+	s.Set(file.Path, version, fileData)
+	return nil
+}
+
+// Pull ...
+func (s *MemoryStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, found := s.files[memoryKey(file.Path, version)]
+	if !found {
+		return []byte{}, contract.Attributes{}, errors.New("file not found")
+	}
+
+	return f.data, contract.Attributes{LastModified: f.modified}, nil
+}
+
+// Purge ...
+func (s *MemoryStore) Purge(file *catalog.File, version string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.files, memoryKey(file.Path, version))
+	return nil
+}
+
+// Changed ...
+func (s *MemoryStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.files[memoryKey(file.Path, version)].modified, nil
+}
+
+func memoryKey(path, version string) string {
+	return fmt.Sprintf("%s@%s", path, version)
+}
+
+// MemoryVault is an in-memory vault allowing tests to inject secrets
+// without access to a remote vault. Use Set to add secrets; for the
+// token DB_PASS={{dev/password}}, the group is "dev/DB-PASS" and the
+// prop is "password". Keys are not case sensitive.
+type MemoryVault map[string]string
+
+// Name ...
+func (v MemoryVault) Name() string {
+	return "memory"
+}
+
+// Description ...
+func (v MemoryVault) Description() string {
+	return "Secrets are stored in memory for tests."
+}
+
+// Pre ...
+func (v MemoryVault) Pre(clog catalog.Catalog, fileEntry *catalog.File, userPrompt bool, io models.IO) error {
+	return nil
+}
+
+// Get ...
+func (v MemoryVault) Get(contextID, group, prop string) (string, error) {
+	value, found := v[v.BuildKey(contextID, group, prop)]
+	if !found {
+		return "", contract.ErrSecretNotFound
+	}
+	return value, nil
+}
+
+// Set ...
+func (v MemoryVault) Set(contextID, group, prop, value string) error {
+	v[v.BuildKey(contextID, group, prop)] = value
+	return nil
+}
+
+// Delete ...
+func (v MemoryVault) Delete(contextID, group, prop string) error {
+	delete(v, v.BuildKey(contextID, group, prop))
+	return nil
+}
+
+// BuildKey ...
+func (v MemoryVault) BuildKey(contextID, group, prop string) string {
+	return strings.ToLower(fmt.Sprintf("%s/%s", group, prop))
+}
@@ -0,0 +1,270 @@
+// Package envprovider loads cataloged env files into environment maps
+// using the same catalog, store, vault, transform, and secret
+// resolution cStore uses when pulling files. It allows test suites to
+// load configuration exactly as production does.
+package envprovider
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/env"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/render"
+	"github.com/turnerlabs/cstore/components/store"
+	"github.com/turnerlabs/cstore/components/token"
+	"github.com/turnerlabs/cstore/components/transform"
+	"github.com/turnerlabs/cstore/components/vault"
+)
+
+// Options ...
+type Options struct {
+	// Catalog is the catalog listing the files. (default: cstore.yml)
+	Catalog string
+
+	// Tags limits the files loaded when no paths are requested.
+	Tags    []string
+	AllTags bool
+
+	// Version is the version of the files to load.
+	Version string
+
+	// InjectSecrets replaces secret tokens with values from the
+	// secrets vault.
+	InjectSecrets bool
+
+	// Store, when set, is used instead of the store saved in the
+	// catalog. Files requested by path do not need to be cataloged.
+	Store contract.IStore
+
+	// Secrets, when set, is used instead of the secrets vault saved
+	// in the catalog.
+	Secrets contract.IVault
+
+	// IO is used for store prompts and messages. (default: discarded)
+	IO *models.IO
+}
+
+// Load returns the merged environment variables from the requested
+// files. Files later in the list take precedence. When no paths are
+// requested, all cataloged env files matching the tags are loaded in
+// path order.
+func Load(opt Options, paths ...string) (map[string]string, error) {
+	io := opt.streams()
+
+	clog, err := catalog.Get(opt.catalogName())
+	if err != nil && (opt.Store == nil || !os.IsNotExist(err)) {
+		return nil, err
+	}
+
+	files, err := opt.files(clog, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []env.Layer{}
+
+	for _, fileEntry := range files {
+		if !fileEntry.SupportsConfig() {
+			return nil, fmt.Errorf("%s cannot be loaded due to incompatible file type %s", fileEntry.Path, fileEntry.Type)
+		}
+
+		st, secrets, err := opt.components(&fileEntry, clog, io)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve %s (%s)", fileEntry.Path, err)
+		}
+
+		file, _, err := st.Pull(&fileEntry, opt.Version)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve %s (%s)", fileEntry.Path, err)
+		}
+
+		if file, err = transform.Env(file, fileEntry.Transforms.Pull); err != nil {
+			return nil, fmt.Errorf("failed to transform %s (%s)", fileEntry.Path, err)
+		}
+
+		if fileEntry.Render {
+			if file, err = render.Render(file, render.Sources{
+				Env: os.LookupEnv,
+				Secret: func(group, prop string) (string, error) {
+					return secrets.Get(clog.Context, group, prop)
+				},
+			}); err != nil {
+				return nil, fmt.Errorf("failed to render %s (%s)", fileEntry.Path, err)
+			}
+		}
+
+		names, err := fileEntry.Renames(false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rename keys in %s (%s)", fileEntry.Path, err)
+		}
+		file = env.Rename(file, names)
+
+		if opt.InjectSecrets {
+			if file, err = inject(file, fileEntry, clog.Context, secrets); err != nil {
+				return nil, err
+			}
+		}
+
+		layers = append(layers, env.Layer{
+			Name: fileEntry.Path,
+			Data: file,
+		})
+	}
+
+	merged, _ := env.Merge(layers)
+
+	return merged, nil
+}
+
+// Environ returns the requested files as "KEY=VALUE" pairs sorted by
+// key, like os.Environ.
+func Environ(opt Options, paths ...string) ([]string, error) {
+	vars, err := Load(opt, paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	environ := []string{}
+	for key, value := range vars {
+		environ = append(environ, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(environ)
+
+	return environ, nil
+}
+
+// Setenv loads the requested files into the process environment.
+func Setenv(opt Options, paths ...string) error {
+	vars, err := Load(opt, paths...)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range vars {
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (opt Options) catalogName() string {
+	if len(opt.Catalog) > 0 {
+		return opt.Catalog
+	}
+	return catalog.DefaultFileName
+}
+
+func (opt Options) streams() models.IO {
+	if opt.IO != nil {
+		return *opt.IO
+	}
+
+	return models.IO{
+		UserOutput: ioutil.Discard,
+		UserInput:  bytes.NewReader([]byte{}),
+		Export:     ioutil.Discard,
+	}
+}
+
+func (opt Options) files(clog catalog.Catalog, paths []string) ([]catalog.File, error) {
+	files := []catalog.File{}
+
+	if len(paths) == 0 {
+		for _, f := range clog.FilesBy([]string{}, opt.Tags, opt.AllTags, opt.Version) {
+			if !f.IsRef {
+				files = append(files, f)
+			}
+		}
+
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Path < files[j].Path
+		})
+
+		if len(files) == 0 {
+			return files, fmt.Errorf("%s is not aware of any matching files", opt.catalogName())
+		}
+
+		return files, nil
+	}
+
+	for _, p := range paths {
+		found := false
+
+		for _, f := range clog.Files {
+			if f.Path == p && !f.IsRef {
+				files = append(files, f)
+				found = true
+			}
+		}
+
+		if !found {
+			if opt.Store == nil {
+				return files, fmt.Errorf("%s is not aware of %s", opt.catalogName(), p)
+			}
+
+			files = append(files, catalog.File{
+				Path: p,
+				Type: "env",
+			})
+		}
+	}
+
+	return files, nil
+}
+
+func (opt Options) components(fileEntry *catalog.File, clog catalog.Catalog, io models.IO) (contract.IStore, contract.IVault, error) {
+	secrets := opt.Secrets
+	if secrets == nil && opt.InjectSecrets {
+		v, err := vault.GetBy(fileEntry.Vaults.Secrets, cfg.DefaultSecretsVault, clog, fileEntry, false, io)
+		if err != nil {
+			return nil, nil, err
+		}
+		secrets = v
+	}
+
+	if opt.Store != nil {
+		return opt.Store, secrets, nil
+	}
+
+	access, err := vault.GetBy(fileEntry.Vaults.Access, cfg.DefaultAccessVault, clog, fileEntry, false, io)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st, err := store.Select(fileEntry, clog, access, cfg.UserOptions{}, io)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st, err = store.Pipeline(st, clog, fileEntry, access, cfg.UserOptions{}, io)
+
+	return st, secrets, err
+}
+
+func inject(file []byte, fileEntry catalog.File, context string, secrets contract.IVault) ([]byte, error) {
+	tokens, err := token.Find(file, fileEntry.Type, false)
+	if err != nil {
+		return file, fmt.Errorf("failed to find tokens in %s (%s)", fileEntry.Path, err)
+	}
+
+	for k, t := range tokens {
+		value, err := secrets.Get(context, t.Secret(), t.Prop)
+		if err != nil {
+			return file, fmt.Errorf("failed to get value for %s/%s for %s (%s)", t.Secret(), t.Prop, fileEntry.Path, err)
+		}
+
+		t.Value = value
+		tokens[k] = t
+	}
+
+	return token.Replace(file, fileEntry.Type, tokens)
+}
@@ -0,0 +1,70 @@
+package envprovider
+
+import (
+	"testing"
+)
+
+func TestLoadFromMemoryStore(t *testing.T) {
+	// arrange
+	opt := Options{
+		Catalog: "missing.yml",
+		Store: NewMemoryStore(map[string]string{
+			"base.env":  "URL=http://base\nDEBUG=false\n",
+			"local.env": "DEBUG=true\n",
+		}),
+	}
+
+	// act
+	vars, err := Load(opt, "base.env", "local.env")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"URL":   "http://base",
+		"DEBUG": "true",
+	}
+
+	for key, e := range expected {
+		if vars[key] != e {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", e, vars[key])
+		}
+	}
+}
+
+func TestEnvironInjectsSecrets(t *testing.T) {
+	// arrange
+	secrets := MemoryVault{}
+	secrets.Set("", "dev/DB-PASS", "password", "hunter2")
+
+	opt := Options{
+		Catalog:       "missing.yml",
+		InjectSecrets: true,
+		Secrets:       secrets,
+		Store: NewMemoryStore(map[string]string{
+			".env": "DB_PASS={{dev/password}}\nURL=http://base\n",
+		}),
+	}
+
+	// act
+	environ, err := Environ(opt, ".env")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"DB_PASS=hunter2", "URL=http://base"}
+
+	if len(environ) != len(expected) {
+		t.Fatalf("\nEXPECTED: %s \nACTUAL: %s", expected, environ)
+	}
+
+	for i := range expected {
+		if environ[i] != expected[i] {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected[i], environ[i])
+		}
+	}
+}
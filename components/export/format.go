@@ -0,0 +1,139 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//------------------------------------------
+//- Export formats added without changing
+//- cStore.
+//-
+//- Go programs building cStore can call
+//- Register before running the commands.
+//- Any other program on the path named like
+//- "cstore-format-ini" is used for the "ini"
+//- format. It is run with "format" as the
+//- argument, a PluginRequest as JSON on
+//- stdin, and writes a PluginResponse as
+//- JSON to stdout. Failures exit non-zero
+//- with the reason on stderr.
+//------------------------------------------
+
+// PluginPrefix is prepended to a format name to find the format program
+// on the path.
+const PluginPrefix = "cstore-format-"
+
+// APIVersion is sent with each request and only changes when the
+// request or response changes in a way older programs cannot read.
+const APIVersion = 1
+
+// Format converts typed environment variables into the contents of a
+// file.
+type Format struct {
+	Description string
+	Convert     func(values map[string]interface{}) ([]byte, error)
+}
+
+// PluginRequest is written as JSON to the format program's stdin.
+// Values are typed by the file's value types.
+type PluginRequest struct {
+	APIVersion int                    `json:"api_version"`
+	Format     string                 `json:"format"`
+	Values     map[string]interface{} `json:"values"`
+}
+
+// PluginResponse is read as JSON from the format program's stdout.
+// Data is base64 encoded.
+type PluginResponse struct {
+	Data []byte `json:"data"`
+}
+
+var (
+	mu      sync.Mutex
+	formats = map[string]Format{}
+)
+
+// Register adds a format. Formats built into cStore are used before
+// registered formats with the same name.
+func Register(name string, f Format) error {
+	if len(name) == 0 || f.Convert == nil {
+		return fmt.Errorf("format %q needs a name and a conversion", name)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, found := formats[name]; found {
+		return fmt.Errorf("format %s is already registered", name)
+	}
+
+	formats[name] = f
+
+	return nil
+}
+
+// Get returns a registered format or the format program on the path
+// with the name.
+func Get(name string) (Format, bool) {
+	mu.Lock()
+	f, found := formats[name]
+	mu.Unlock()
+
+	if found {
+		return f, true
+	}
+
+	if len(name) == 0 || strings.ContainsAny(name, `/\`) {
+		return Format{}, false
+	}
+
+	binary, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		return Format{}, false
+	}
+
+	return Format{
+		Description: fmt.Sprintf("%s format", name),
+		Convert: func(values map[string]interface{}) ([]byte, error) {
+			return runPlugin(binary, name, values)
+		},
+	}, true
+}
+
+func runPlugin(binary, name string, values map[string]interface{}) ([]byte, error) {
+	input, err := json.Marshal(PluginRequest{
+		APIVersion: APIVersion,
+		Format:     name,
+		Values:     values,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	c := exec.Command(binary, "format")
+	c.Stdin = bytes.NewReader(input)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return nil, fmt.Errorf("%s failed (%s)", filepath.Base(binary), msg)
+		}
+		return nil, fmt.Errorf("%s failed (%s)", filepath.Base(binary), err)
+	}
+
+	r := PluginResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+		return nil, fmt.Errorf("%s returned invalid JSON (%s)", filepath.Base(binary), err)
+	}
+
+	return r.Data, nil
+}
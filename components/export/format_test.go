@@ -0,0 +1,75 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testPlugin returns the same INI file for any values.
+const testPlugin = `#!/bin/sh
+[ "$1" = "format" ] || { echo "unsupported action $1" >&2; exit 1; }
+cat > /dev/null
+echo '{"data":"W2Vudl0KQT0xCg=="}'
+`
+
+func TestPluginFormat(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "cstore-format")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, PluginPrefix+"ini-test"), []byte(testPlugin), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+
+	f, found := Get("ini-test")
+	if !found {
+		t.Fatal("format plugin not found")
+	}
+
+	// act
+	b, err := f.Convert(map[string]interface{}{"A": 1})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "[env]\nA=1\n" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "[env]\nA=1\n", string(b))
+	}
+}
+
+func TestRegisteredFormatIsUsed(t *testing.T) {
+	// arrange
+	err := Register("upper-test", Format{
+		Description: "test format",
+		Convert: func(values map[string]interface{}) ([]byte, error) {
+			return []byte("A"), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer delete(formats, "upper-test")
+
+	// act
+	f, found := Get("upper-test")
+
+	// assert
+	if !found || f.Description != "test format" {
+		t.Errorf("\nEXPECTED: test format \nACTUAL: %v %s", found, f.Description)
+	}
+
+	if err := Register("upper-test", f); err == nil {
+		t.Error("\nEXPECTED: duplicate registration error")
+	}
+}
@@ -0,0 +1,56 @@
+package hook
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Event describes the file a hook runs for. It is passed to each hook
+// command as CSTORE_* environment variables.
+type Event struct {
+	Hook    string
+	Path    string
+	File    string
+	Context string
+	Store   string
+	Version string
+}
+
+func (e Event) env() []string {
+	return append(os.Environ(),
+		fmt.Sprintf("CSTORE_HOOK=%s", e.Hook),
+		fmt.Sprintf("CSTORE_PATH=%s", e.Path),
+		fmt.Sprintf("CSTORE_FILE=%s", e.File),
+		fmt.Sprintf("CSTORE_CONTEXT=%s", e.Context),
+		fmt.Sprintf("CSTORE_STORE=%s", e.Store),
+		fmt.Sprintf("CSTORE_VERSION=%s", e.Version),
+	)
+}
+
+// Run runs each command using the shell from dir and sends the output
+// to w. The first command to fail stops the remaining commands.
+func Run(commands []string, e Event, dir string, w io.Writer) error {
+	for _, command := range commands {
+		c := shell(command)
+		c.Dir = dir
+		c.Env = e.env()
+		c.Stdout = w
+		c.Stderr = w
+
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("%s hook '%s' failed (%s)", e.Hook, command, err)
+		}
+	}
+
+	return nil
+}
+
+func shell(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
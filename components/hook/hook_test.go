@@ -0,0 +1,65 @@
+package hook
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunPassesEvent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	// arrange
+	dir, err := ioutil.TempDir("", "cstore-hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	e := Event{Hook: "post-pull", File: "dev/.env", Context: "app", Store: "aws-s3"}
+
+	// act
+	err = Run([]string{`echo "$CSTORE_HOOK $CSTORE_FILE $CSTORE_STORE" > hook.out`}, e, dir, &bytes.Buffer{})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "hook.out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "post-pull dev/.env aws-s3"
+	if actual := strings.TrimSpace(string(b)); actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
+
+func TestRunStopsOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	// arrange
+	out := bytes.Buffer{}
+
+	// act
+	err := Run([]string{"exit 3", "echo skipped"}, Event{Hook: "post-pull"}, "", &out)
+
+	// assert
+	if err == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "error", err)
+	}
+
+	if strings.Contains(out.String(), "skipped") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "remaining commands skipped", out.String())
+	}
+}
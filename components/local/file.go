@@ -2,8 +2,10 @@ package local
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/turnerlabs/cstore/components/cipher"
@@ -33,8 +35,40 @@ func Update(name, key string, data []byte) error {
 		}
 	}
 
-	return file.Save(BuildPath(name), data)
+	return save(BuildPath(name), data)
+}
+
+// save writes the data to a temporary file and renames it over the
+// destination; so, a reader never sees a partially written file.
+func save(path string, data []byte) error {
+	dir := filepath.Dir(path)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
 
+	return nil
 }
 
 // Missing ...
@@ -0,0 +1,58 @@
+package local
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const lockExt = ".lock"
+
+// lockTimeout is how long to wait for another process to release a lock.
+var lockTimeout = 30 * time.Second
+
+// staleLockAge is how old a lock can be before it is assumed the process
+// holding it exited without releasing it.
+var staleLockAge = 2 * time.Minute
+
+const lockRetryInterval = 50 * time.Millisecond
+
+// Lock prevents other cstore processes from reading or writing the named
+// file until the returned function is called to release the lock.
+func Lock(name string) (func() error, error) {
+	path := BuildPath(name + lockExt)
+
+	if err := os.MkdirAll(BuildPath(""), 0700); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+
+			return func() error { return os.Remove(path) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		//-------------------------------------------------
+		//- Remove locks left behind by killed processes.
+		//-------------------------------------------------
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s; remove it if no other cstore process is running", path)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
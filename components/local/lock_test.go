@@ -0,0 +1,100 @@
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+func TestLockSerializesUpdates(t *testing.T) {
+	// arrange
+	home, err := ioutil.TempDir("", "cstore-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	os.Setenv("HOME", home)
+	homedir.DisableCache = true
+
+	const name = "counter"
+	const workers = 20
+
+	if err := Update(name, "", []byte("0")); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			unlock, err := Lock(name)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer unlock()
+
+			b, _ := Get(name, "")
+			count, _ := strconv.Atoi(string(b))
+
+			Update(name, "", []byte(strconv.Itoa(count+1)))
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	b, err := Get(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != strconv.Itoa(workers) {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %s", workers, b)
+	}
+
+	if !Missing(name + lockExt) {
+		t.Errorf("\nEXPECTED: lock released \nACTUAL: %s exists", name+lockExt)
+	}
+}
+
+func TestLockRemovesStaleLock(t *testing.T) {
+	// arrange
+	home, err := ioutil.TempDir("", "cstore-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	os.Setenv("HOME", home)
+	homedir.DisableCache = true
+
+	const name = "stale"
+
+	if err := Update(name+lockExt, "", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(BuildPath(name+lockExt), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	unlock, err := Lock(name)
+
+	// assert
+	if err != nil {
+		t.Fatalf("\nEXPECTED: stale lock removed \nACTUAL: %s", err)
+	}
+
+	unlock()
+}
@@ -0,0 +1,628 @@
+package message
+
+// Messages displayed by more than one command.
+const (
+	ErrorLabel   ID = "error.label"
+	WarningLabel ID = "warning.label"
+	ErrorIcon    ID = "error.icon"
+	WarningIcon  ID = "warning.icon"
+	Done         ID = "done"
+	FileCount    ID = "file.count"
+	Aborted      ID = "aborted"
+	KeyAdded     ID = "key.added"
+	KeyRemoved   ID = "key.removed"
+	KeyChanged   ID = "key.changed"
+	KeyFailed    ID = "key.failed"
+
+	SecretsNotSupported ID = "secrets.not-supported"
+	TransformFailed     ID = "transform.failed"
+	TemplateFailed      ID = "template.failed"
+	SpecifyFile         ID = "specify-file"
+	OfVersion           ID = "of-version"
+	ChangedBy           ID = "changed-by"
+	WholeFile           ID = "whole-file"
+)
+
+// Messages displaying the result of each file in a bulk operation.
+const (
+	ResultsHeader    ID = "results.header"
+	ResultRetrieved  ID = "result.retrieved"
+	ResultUpToDate   ID = "result.up-to-date"
+	ResultOffline    ID = "result.offline"
+	ResultAssembled  ID = "result.assembled"
+	ResultPushed     ID = "result.pushed"
+	ResultVerified   ID = "result.verified"
+	ResultUnverified ID = "result.unverified"
+	ResultNotPushed  ID = "result.not-pushed"
+	ResultPurged     ID = "result.purged"
+	ResultFailed     ID = "result.failed"
+	ResultDiffers    ID = "result.differs"
+	ResultMatches    ID = "result.matches"
+	ResultMigrated   ID = "result.migrated"
+)
+
+// Messages displayed by push.
+const (
+	Linking            ID = "push.linking"
+	Pushing            ID = "push.pushing"
+	PushingVersion     ID = "push.pushing-version"
+	PushPinned         ID = "push.pinned"
+	PushBlocked        ID = "push.blocked"
+	PushModifiedFailed ID = "push.modified-failed"
+	PushOverwrite      ID = "push.overwrite"
+	PushSkipping       ID = "push.skipping"
+	TokensFailed       ID = "push.tokens-failed"
+	TokensMissing      ID = "push.tokens-missing"
+	VersionUnsupported ID = "push.version-unsupported"
+	ResumeFailed       ID = "push.resume-failed"
+	ResumeHint         ID = "push.resume-hint"
+	PushSkipped        ID = "push.skipped"
+	PushFailed         ID = "push.failed"
+	PushSummary        ID = "push.summary"
+	ChangeSetCommitted ID = "push.change-set-committed"
+	RollbackFailed     ID = "push.rollback-failed"
+	RolledBack         ID = "push.rolled-back"
+	PolicyFailed       ID = "push.policy-failed"
+	PolicyBlocked      ID = "push.policy-blocked"
+	PolicyViolation    ID = "push.policy-violation"
+	PushVerified       ID = "push.verified"
+	PushUnverified     ID = "push.unverified"
+)
+
+// Messages displayed by pull.
+const (
+	PullOutputConflict ID = "pull.output-conflict"
+	PullPartial        ID = "pull.partial"
+	PullSummary        ID = "pull.summary"
+	LinkedPullFailed   ID = "pull.linked-failed"
+	RetrieveFailed     ID = "pull.retrieve-failed"
+	RetrieveBothFailed ID = "pull.retrieve-both-failed"
+	OfflineCopy        ID = "pull.offline-copy"
+	UpToDate           ID = "pull.up-to-date"
+	RenameFailed       ID = "pull.rename-failed"
+	MergeFailed        ID = "pull.merge-failed"
+	RenderFailed       ID = "pull.render-failed"
+	Exported           ID = "pull.exported"
+	NotRendered        ID = "pull.not-rendered"
+	SaveFailed         ID = "pull.save-failed"
+	Retrieving         ID = "pull.retrieving"
+	RetrievingAsOf     ID = "pull.retrieving-as-of"
+	RetrievingRevision ID = "pull.retrieving-revision"
+	MergeBaseMissing   ID = "pull.merge-base-missing"
+	MergeKeptLocal     ID = "pull.merge-kept-local"
+	AssembleFailed     ID = "pull.assemble-failed"
+	Assembling         ID = "pull.assembling"
+	ReportUnsupported  ID = "pull.report-unsupported"
+	ReportValue        ID = "pull.report-value"
+	ReportLarge        ID = "pull.report-large"
+	ReportWeak         ID = "pull.report-weak"
+)
+
+// Messages displayed by purge.
+const (
+	PurgeNoFiles       ID = "purge.no-files"
+	PurgeDelete        ID = "purge.delete"
+	PurgeDeleteVersion ID = "purge.delete-version"
+	PurgeConfirm       ID = "purge.confirm"
+	PurgeAborted       ID = "purge.aborted"
+	PurgeIncomplete    ID = "purge.incomplete"
+	PurgeGhostFailed   ID = "purge.ghost-failed"
+	PurgeRetry         ID = "purge.retry"
+	PurgeSummary       ID = "purge.summary"
+)
+
+// Messages displayed by diff.
+const (
+	DiffSummary ID = "diff.summary"
+)
+
+// Messages displayed by list.
+const (
+	ListTags        ID = "list.tags"
+	ListVersions    ID = "list.versions"
+	ListKeys        ID = "list.keys"
+	ListKeyModified ID = "list.key-modified"
+	ListUnknown     ID = "list.unknown"
+	ListSummary     ID = "list.summary"
+	ListHint        ID = "list.hint"
+)
+
+// Messages displayed by rehash.
+const (
+	Rehashed      ID = "rehash.rehashed"
+	RehashSummary ID = "rehash.summary"
+	RehashFailed  ID = "rehash.failed"
+)
+
+// Messages displayed by check.
+const (
+	CheckRotationsFailed    ID = "check.rotations-failed"
+	CheckDeprecationsFailed ID = "check.deprecations-failed"
+	CheckRotation           ID = "check.rotation"
+	CheckRotationUnknown    ID = "check.rotation-unknown"
+	CheckOverdue            ID = "check.overdue"
+	CheckDue                ID = "check.due"
+	CheckOverdueSummary     ID = "check.overdue-summary"
+	CheckRemoved            ID = "check.removed"
+	CheckPastSunset         ID = "check.past-sunset"
+	CheckNoSunset           ID = "check.no-sunset"
+	CheckSunsets            ID = "check.sunsets"
+	CheckSunsetSummary      ID = "check.sunset-summary"
+)
+
+// Messages displayed by versions.
+const (
+	VersionsLatest  ID = "versions.latest"
+	VersionsPinned  ID = "versions.pinned"
+	VersionsSummary ID = "versions.summary"
+)
+
+// Messages displayed by history.
+const (
+	HistoryKey      ID = "history.key"
+	HistoryRevision ID = "history.revision"
+	HistoryRemoved  ID = "history.removed"
+	HistoryChanged  ID = "history.changed"
+	HistorySummary  ID = "history.summary"
+)
+
+// Messages displayed by backups.
+const (
+	BackupSaved        ID = "backup.saved"
+	BackupsListFailed  ID = "backup.list-failed"
+	BackupBefore       ID = "backup.before"
+	BackupsSummary     ID = "backup.summary"
+	BackupRestoreUsage ID = "backup.restore-usage"
+	BackupReplace      ID = "backup.replace"
+	BackupRestored     ID = "backup.restored"
+	BackupPushHint     ID = "backup.push-hint"
+)
+
+// Messages displayed by discover.
+const (
+	DiscoverNoFiles         ID = "discover.no-files"
+	DiscoverKeys            ID = "discover.keys"
+	DiscoverModified        ID = "discover.modified"
+	DiscoverOtherContext    ID = "discover.other-context"
+	DiscoverCataloged       ID = "discover.cataloged"
+	DiscoverNoneUncataloged ID = "discover.none-uncataloged"
+	DiscoverAdd             ID = "discover.add"
+	DiscoverNoneAdded       ID = "discover.none-added"
+	DiscoverAdded           ID = "discover.added"
+)
+
+// Messages displayed by encryption.
+const (
+	EncryptionFailed  ID = "encryption.failed"
+	EncryptionExposed ID = "encryption.exposed"
+	EncryptionSummary ID = "encryption.summary"
+)
+
+// Messages displayed by metrics.
+const (
+	MetricsHeading  ID = "metrics.heading"
+	MetricsElapsed  ID = "metrics.elapsed"
+	MetricsCalls    ID = "metrics.calls"
+	MetricsErrors   ID = "metrics.errors"
+	MetricsLatency  ID = "metrics.latency"
+	MetricsLastSync ID = "metrics.last-sync"
+)
+
+// Messages displayed by migrate.
+const (
+	MigrateUsage        ID = "migrate.usage"
+	MigrateNoFiles      ID = "migrate.no-files"
+	MigrateMove         ID = "migrate.move"
+	MigrateMoveVersions ID = "migrate.move-versions"
+	MigrateUnsupported  ID = "migrate.unsupported"
+	MigrateAndPurge     ID = "migrate.and-purge"
+	MigrateDryRun       ID = "migrate.dry-run"
+	MigrateConfirm      ID = "migrate.confirm"
+	MigrateConfirmPurge ID = "migrate.confirm-purge"
+	MigrateFailed       ID = "migrate.failed"
+	MigratePurgeFailed  ID = "migrate.purge-failed"
+	MigrateSummary      ID = "migrate.summary"
+	Migrating           ID = "migrate.migrating"
+	MigratingVersion    ID = "migrate.migrating-version"
+)
+
+// Messages displayed by reencrypt.
+const (
+	ReencryptUsage          ID = "reencrypt.usage"
+	ReencryptOldKey         ID = "reencrypt.old-key"
+	ReencryptSkipLinked     ID = "reencrypt.skip-linked"
+	ReencryptSkipStore      ID = "reencrypt.skip-store"
+	ReencryptFailed         ID = "reencrypt.failed"
+	Reencrypting            ID = "reencrypt.reencrypting"
+	ReencryptingVersion     ID = "reencrypt.reencrypting-version"
+	ReencryptAlreadyDone    ID = "reencrypt.already-done"
+	ReencryptProgressFailed ID = "reencrypt.progress-failed"
+	ReencryptSummary        ID = "reencrypt.summary"
+)
+
+// Messages displayed by compose.
+const (
+	ComposeCollision ID = "compose.collision"
+	ComposeExporting ID = "compose.exporting"
+	ComposePatched   ID = "compose.patched"
+	ComposeSummary   ID = "compose.summary"
+)
+
+// Messages displayed by remote-pull.
+const (
+	RemotePullSkipLinked  ID = "remote-pull.skip-linked"
+	RemotePullWriteFailed ID = "remote-pull.write-failed"
+	RemotePullWritten     ID = "remote-pull.written"
+	RemotePullSummary     ID = "remote-pull.summary"
+)
+
+// Messages displayed by exec.
+const (
+	ExecCollision ID = "exec.collision"
+)
+
+// Messages displayed by example.
+const (
+	ExampleFailed    ID = "example.failed"
+	ExampleGenerated ID = "example.generated"
+	ExampleUnchanged ID = "example.unchanged"
+)
+
+// Messages displayed by context.
+const (
+	ContextUseUsage    ID = "context.use-usage"
+	ContextCreateUsage ID = "context.create-usage"
+	ContextDefault     ID = "context.default"
+	ContextUsing       ID = "context.using"
+)
+
+// Messages displayed by types.
+const (
+	TypesUsage    ID = "types.usage"
+	TypesSetting  ID = "types.setting"
+	TypesPushHint ID = "types.push-hint"
+)
+
+// Messages displayed by restore.
+const (
+	Restored      ID = "restore.restored"
+	RestorePinned ID = "restore.pinned"
+)
+
+// Messages displayed by init.
+const (
+	InitExists         ID = "init.exists"
+	InitCreated        ID = "init.created"
+	WizardNoFiles      ID = "init.wizard-no-files"
+	WizardFound        ID = "init.wizard-found"
+	WizardCatalog      ID = "init.wizard-catalog"
+	WizardStore        ID = "init.wizard-store"
+	WizardTags         ID = "init.wizard-tags"
+	WizardNoneSelected ID = "init.wizard-none-selected"
+	WizardProposed     ID = "init.wizard-proposed"
+	WizardPush         ID = "init.wizard-push"
+	WizardNonePushed   ID = "init.wizard-none-pushed"
+)
+
+// Messages displayed by repair.
+const (
+	RepairUsage           ID = "repair.usage"
+	RepairSummary         ID = "repair.summary"
+	RepairStaleKey        ID = "repair.stale-key"
+	RepairRemoveKey       ID = "repair.remove-key"
+	RepairUnknownType     ID = "repair.unknown-type"
+	RepairRemoveType      ID = "repair.remove-type"
+	RepairMissingSecret   ID = "repair.missing-secret"
+	RepairEnterSecret     ID = "repair.enter-secret"
+	RepairSecretValue     ID = "repair.secret-value"
+	RepairMissingLocally  ID = "repair.missing-locally"
+	RepairRestoreLocal    ID = "repair.restore-local"
+	RepairMissingRemotely ID = "repair.missing-remotely"
+	RepairPushLocal       ID = "repair.push-local"
+	RepairRemoteOnly      ID = "repair.remote-only"
+	RepairAddKeys         ID = "repair.add-keys"
+	RepairLocalOnly       ID = "repair.local-only"
+	RepairNoIssues        ID = "repair.no-issues"
+	RepairFixFailed       ID = "repair.fix-failed"
+	RepairPushFailed      ID = "repair.push-failed"
+)
+
+// Messages displayed by preview.
+const (
+	PreviewFormatUnknown ID = "preview.format-unknown"
+	PreviewSaved         ID = "preview.saved"
+	PreviewServing       ID = "preview.serving"
+)
+
+// Messages displayed by bundle-debug.
+const (
+	BundleOpenFailed ID = "bundle.open-failed"
+	BundleFailed     ID = "bundle.failed"
+	BundleSaved      ID = "bundle.saved"
+	BundleKey        ID = "bundle.key"
+)
+
+// Messages displayed by vault export and import.
+const (
+	VaultsEmpty      ID = "vaults.empty"
+	VaultSecrets     ID = "vaults.secrets"
+	VaultsExported   ID = "vaults.exported"
+	VaultUnavailable ID = "vaults.unavailable"
+	VaultOverwrite   ID = "vaults.overwrite"
+	VaultsImported   ID = "vaults.imported"
+)
+
+// english is the text of every message. Translations replace it.
+var english = map[ID]string{
+	ErrorLabel:   "ERROR: ",
+	WarningLabel: "WARNING: ",
+	ErrorIcon:    "",
+	WarningIcon:  "",
+	Done:         "(done)",
+	FileCount:    "%d file(s)",
+	Aborted:      "Operation Aborted!",
+	KeyAdded:     "  + %s",
+	KeyRemoved:   "  - %s",
+	KeyChanged:   "  ~ %s",
+	KeyFailed:    "  - %s (%s)",
+
+	SecretsNotSupported: "Secrets not supported for %s due to incompatible file type %s.",
+	TransformFailed:     "Failed to transform %s. (%s)",
+	TemplateFailed:      "Failed to render template. (%s)",
+	SpecifyFile:         "Specify a file. (%s)",
+	OfVersion:           "(version %s)",
+	ChangedBy:           "by %s",
+	WholeFile:           "(file)",
+
+	ResultsHeader:    "FILE\tSTORE\tRESULT",
+	ResultRetrieved:  "retrieved",
+	ResultUpToDate:   "up to date",
+	ResultOffline:    "offline copy",
+	ResultAssembled:  "assembled",
+	ResultPushed:     "pushed",
+	ResultVerified:   "pushed and verified",
+	ResultUnverified: "not verified",
+	ResultNotPushed:  "not pushed",
+	ResultPurged:     "purged",
+	ResultFailed:     "failed",
+	ResultDiffers:    "differs",
+	ResultMatches:    "matches",
+	ResultMigrated:   "migrated",
+
+	Linking:            "Linking %s   %s",
+	Pushing:            "Pushing [%s] -> [%s]",
+	PushingVersion:     "Pushing [%s](%s) -> [%s]",
+	PushPinned:         "%[1]s is pinned to revision %[2]s. Pulls retrieve the pinned revision until 'cstore pull %[1]s --unpin'.",
+	PushBlocked:        "Push blocked for %s. (%s)",
+	PushModifiedFailed: "Failed to determine when '%s' version %s was last modified. (%s)",
+	PushOverwrite:      "Remote file '%s' was modified on %s. Overwrite?",
+	PushSkipping:       "Skipping %s",
+	TokensFailed:       "Failed to find tokens in file %s. (%s)",
+	TokensMissing:      "To set secrets, tokens in %s must be in the format {{ENV/TOKEN::VALUE}}. Learn about additional limitations at https://github.com/turnerlabs/cstore/blob/master/docs/SECRETS.md.",
+	VersionUnsupported: "%s store does not support %s feature.",
+	ResumeFailed:       "Cannot resume %s. (%s)",
+	ResumeHint:         "Run 'cstore push %s --resume' to push the remaining key(s).",
+	PushSkipped:        "Push skipped for %s because %s was not pushed.",
+	PushFailed:         "Failed to push %s. (%s)",
+	PushSummary:        "%d of %d file(s) pushed to remote store.",
+	ChangeSetCommitted: "Committed change set [%s]",
+	RollbackFailed:     "Failed to roll back %s; restore it manually. (%s)",
+	RolledBack:         "Rolled back [%s]",
+	PolicyFailed:       "Failed to evaluate policy for %s. (%s)",
+	PolicyBlocked:      "Push blocked by policy for %s.",
+	PolicyViolation:    "  - %s (%s)",
+	PushVerified:       "Verified [%s]",
+	PushUnverified:     "Push of %s could not be verified. (%s)",
+
+	PullOutputConflict: "JSON output cannot be combined with --stdout, -e, or -g since they also use stdout.",
+	PullPartial:        "%d file(s) failed. See %s.",
+	PullSummary:        "%d of %d requested file(s) retrieved.",
+	LinkedPullFailed:   "Could not pull linked catalog %s! (%s)",
+	RetrieveFailed:     "Could not retrieve %s! (%s)",
+	RetrieveBothFailed: "Could not retrieve %s! (%s and %s)",
+	OfflineCopy:        "%s is an offline copy pulled %s ago and may be stale. (%s)",
+	UpToDate:           "Up to date [%s]",
+	RenameFailed:       "Failed to rename keys in %s! (%s)",
+	MergeFailed:        "Failed to merge %s! (%s)",
+	RenderFailed:       "Failed to render %s! (%s)",
+	Exported:           "%s sent to stdout.",
+	NotRendered:        "%s is a template and was not rendered. Set an alternate path to save the rendered file.",
+	SaveFailed:         "Could not save %s! (%s)",
+	Retrieving:         "Retrieving [%s] <- [%s]",
+	RetrievingAsOf:     " as of %s",
+	RetrievingRevision: " revision %s",
+	MergeBaseMissing:   "No copy of %s from the last pull was found; so, keys that differ keep the local value.",
+	MergeKeptLocal:     "%s changed locally and in the store; the local value was kept in %s.",
+	AssembleFailed:     "Could not assemble %s! (%s)",
+	Assembling:         "Assembling [%s] <- [%s]",
+	ReportUnsupported:  "report not supported for %s files",
+	ReportValue:        "%s=%s (%d bytes, %.1f bits/char, %.0f bits)",
+	ReportLarge:        "LARGE",
+	ReportWeak:         "WEAK",
+
+	PurgeNoFiles:       "No matching files stored remotely!",
+	PurgeDelete:        "Delete [%s] from [%s]",
+	PurgeDeleteVersion: "Delete [%s](%s) from [%s]",
+	PurgeConfirm:       "File data will be permanently deleted from remote storage! Local files and secrets stored in AWS Secrets Manager will not be affected.\n\n%s \nContinue?",
+	PurgeAborted:       "Purge aborted for %s. (%s)",
+	PurgeIncomplete:    "Purge incomplete for %s. (%s)",
+	PurgeGhostFailed:   ".cstore file could not be removed for %s! (%s)",
+	PurgeRetry:         "Run purge again to retry the remaining keys.",
+	PurgeSummary:       "%d of %d file(s) purged from remote storage.",
+
+	DiffSummary: "%d of %d file(s) differ from their stores.",
+
+	ListTags:        "tags",
+	ListVersions:    "versions",
+	ListKeys:        "keys",
+	ListKeyModified: "%s (modified %s)",
+	ListUnknown:     "unknown",
+	ListSummary:     "%d file(s) stored remotely.",
+	ListHint:        "Use -g, -v, and -k to display file tags, versions, and keys.",
+
+	Rehashed:      "Rehashed [%s]",
+	RehashSummary: "%d file(s) rehashed using %s. ",
+	RehashFailed:  "%d file(s) failed; run 'rehash' again to retry.",
+
+	CheckRotationsFailed:    "Failed to check rotations for %s. (%s)",
+	CheckDeprecationsFailed: "Failed to check deprecations for %s. (%s)",
+	CheckRotation:           "%s every %s",
+	CheckRotationUnknown:    "(last rotation unknown)",
+	CheckOverdue:            "(overdue by %s)",
+	CheckDue:                "(due in %s)",
+	CheckOverdueSummary:     "%d of %d rotation(s) overdue.",
+	CheckRemoved:            "(removed)",
+	CheckPastSunset:         "(past sunset by %s)",
+	CheckNoSunset:           "(no sunset)",
+	CheckSunsets:            "(sunsets in %s)",
+	CheckSunsetSummary:      "%d of %d deprecated key(s) past sunset.",
+
+	VersionsLatest:  "latest",
+	VersionsPinned:  "pinned",
+	VersionsSummary: "%d revision(s) found.",
+
+	HistoryKey:      "%s in %s",
+	HistoryRevision: "revision %s",
+	HistoryRemoved:  "removed",
+	HistoryChanged:  "changed",
+	HistorySummary:  "%d change(s) found.",
+
+	BackupSaved:        "Backup %[1]s saved. Restore with 'cstore backups restore %[1]s'.",
+	BackupsListFailed:  "Failed to list backups. (%s)",
+	BackupBefore:       "before %s",
+	BackupsSummary:     "%d backup(s) found.",
+	BackupRestoreUsage: "Specify the id of the backup to restore. Use 'backups' command to view available backups.",
+	BackupReplace:      "Local file '%s' will be replaced with backup %s. Continue?",
+	BackupRestored:     "Restored [%s]",
+	BackupPushHint:     "Run '%s' to restore the file remotely.",
+
+	DiscoverNoFiles:         "No files found.",
+	DiscoverKeys:            "%d key(s)",
+	DiscoverModified:        "modified %s",
+	DiscoverOtherContext:    "(other context)",
+	DiscoverCataloged:       "(cataloged)",
+	DiscoverNoneUncataloged: "No uncataloged files found in context %s.",
+	DiscoverAdd:             "Add %d file(s) in context %s to %s?",
+	DiscoverNoneAdded:       "No files added.",
+	DiscoverAdded:           "Added %d file(s) to %s. Use 'pull' to restore them.",
+
+	EncryptionFailed:  "Failed to report encryption for %s. (%s)",
+	EncryptionExposed: "(%s) plain text secret",
+	EncryptionSummary: "%d of %d secret key(s) saved in plain text.",
+
+	MetricsHeading:  "Store Metrics",
+	MetricsElapsed:  "(total %s, stores %s, cstore %s)",
+	MetricsCalls:    "%d call(s) (%s)",
+	MetricsErrors:   "errors %d, retries %d, throttled %d",
+	MetricsLatency:  "latency p50 %s, p90 %s, p99 %s",
+	MetricsLastSync: "last sync %s",
+
+	MigrateUsage:        "Specify the stores to migrate between. (cstore migrate --from {store} --to {store})",
+	MigrateNoFiles:      "No matching files stored in %s!",
+	MigrateMove:         "Move [%s] from [%s] to [%s]",
+	MigrateMoveVersions: "Move [%s](%d version(s)) from [%s] to [%s]",
+	MigrateUnsupported:  "(%s files not supported)",
+	MigrateAndPurge:     "and purge",
+	MigrateDryRun:       "%d file(s) would be migrated.",
+	MigrateConfirm:      "Files will be copied to the new store and the catalog updated.\n\n%s \nContinue?",
+	MigrateConfirmPurge: "Files will be copied to the new store, the catalog updated, and the files permanently deleted from the old store!\n\n%s \nContinue?",
+	MigrateFailed:       "Failed to migrate %s. (%s)",
+	MigratePurgeFailed:  "%s was migrated, but not purged from %s. (%s)",
+	MigrateSummary:      "%d of %d file(s) migrated to %s.",
+	Migrating:           "Migrating [%s] -> [%s] ",
+	MigratingVersion:    "Migrating [%s](%s) -> [%s] ",
+
+	ReencryptUsage:          "Specify files, tags, or --all to re-encrypt every file.",
+	ReencryptOldKey:         "Encryption key or team master key the files were encrypted with before it was rotated.",
+	ReencryptSkipLinked:     "Skipping linked catalog %s, run reencrypt from its directory.",
+	ReencryptSkipStore:      "Skipping %s, %s store does not encrypt files client-side.",
+	ReencryptFailed:         "Failed to re-encrypt %s. (%s)",
+	Reencrypting:            "Re-encrypting [%s] -> [%s] ",
+	ReencryptingVersion:     "Re-encrypting [%s](%s) -> [%s] ",
+	ReencryptAlreadyDone:    "(already done)",
+	ReencryptProgressFailed: "Could not save re-encryption progress. (%s)",
+	ReencryptSummary:        "%d of %d file(s) re-encrypted.",
+
+	ComposeCollision: "%s is defined in %s for %s; using %s",
+	ComposeExporting: "Exporting [%s] for [%s]",
+	ComposePatched:   "Patched [%s]",
+	ComposeSummary:   "%d service env file(s) exported.",
+
+	RemotePullSkipLinked:  "Skipping linked catalog %s, run remote-pull from its directory.",
+	RemotePullWriteFailed: "Failed to write %s to %s. (%s)",
+	RemotePullWritten:     "Retrieving [%s] <- [%s] -> %s",
+	RemotePullSummary:     "%d of %d requested file(s) written to %s.",
+
+	ExecCollision: "%s is defined in %s; using %s",
+
+	ExampleFailed:    "Failed to get %s. (%s)",
+	ExampleGenerated: "Generated [%s] <- [%s]",
+	ExampleUnchanged: "No example files changed.",
+
+	ContextUseUsage:    "Specify the context to use. Use 'context list' command to view available contexts.",
+	ContextCreateUsage: "Specify the context to create.",
+	ContextDefault:     "(default)",
+	ContextUsing:       "Using context [%s]",
+
+	TypesUsage:    "Specify a file and the key types to set. (cstore types set {file} {key}={type} ...)",
+	TypesSetting:  "Setting [%s] to [%s]",
+	TypesPushHint: "Push %s to apply the key types in the store.",
+
+	Restored:      "Restored [%s] revision %s as latest in [%s]",
+	RestorePinned: "%[1]s is pinned to revision %[2]s. Use 'cstore pull %[1]s --unpin' to retrieve the restored state.",
+
+	InitExists:         "%s already exists.",
+	InitCreated:        "Created %s for context %s. Use 'push' to catalog files.",
+	WizardNoFiles:      "No uncataloged env or config files found.",
+	WizardFound:        "Found %d uncataloged file(s).",
+	WizardCatalog:      "Catalog %s?",
+	WizardStore:        "The remote storage solution where %s will be pushed.",
+	WizardTags:         "The | delimited tags used to group %s with other files.",
+	WizardNoneSelected: "No files selected.",
+	WizardProposed:     "Proposed %s",
+	WizardPush:         "Push %d file(s)?",
+	WizardNonePushed:   "No files pushed.",
+
+	RepairUsage:           "Specify the file to repair. (cstore repair {file})",
+	RepairSummary:         "%d of %d issue(s) fixed.",
+	RepairStaleKey:        "%s is in %s but not in the file.",
+	RepairRemoveKey:       "Remove %s from the catalog entry?",
+	RepairUnknownType:     "%s has unknown type %s in %s.",
+	RepairRemoveType:      "Remove the type of %s from %s?",
+	RepairMissingSecret:   "%s has no value in the %s vault for %s.",
+	RepairEnterSecret:     "Enter a value for %s/%s?",
+	RepairSecretValue:     "Secret value for %s in %s.",
+	RepairMissingLocally:  "%s is missing locally.",
+	RepairRestoreLocal:    "Restore %s from %s?",
+	RepairMissingRemotely: "%s could not be pulled from %s. (%s)",
+	RepairPushLocal:       "Push the local %s?",
+	RepairRemoteOnly:      "%s in %s are missing from the local file.",
+	RepairAddKeys:         "Add them to the local %s with their remote values?",
+	RepairLocalOnly:       "%s in the local file are missing from %s.",
+	RepairNoIssues:        "No issues found for [%s]",
+	RepairFixFailed:       "Fix failed. (%s)",
+	RepairPushFailed:      "Push failed. (%s)",
+
+	PreviewFormatUnknown: "Unknown preview format %s. Use markdown or html.",
+	PreviewSaved:         "Saved preview to %s.",
+	PreviewServing:       "Serving preview at http://%s (Ctrl+C to stop)",
+
+	BundleOpenFailed: "Could not open %s! (%s)",
+	BundleFailed:     "Could not create support bundle. (%s)",
+	BundleSaved:      "Support bundle saved to [%s].",
+	BundleKey:        "Attach the bundle to the issue and share this key only with the maintainers:",
+
+	VaultsEmpty:      "No secrets found in the %s vault(s).",
+	VaultSecrets:     "%d secret(s)",
+	VaultsExported:   "Exported to %s. Use 'vault import' on the new machine and delete the export afterwards.",
+	VaultUnavailable: "%s vault is not available on this machine, %d secret(s) skipped.",
+	VaultOverwrite:   "Overwrite %s in the %s vault?",
+	VaultsImported:   "Imported %d secret(s), %d skipped.",
+}
+
+// emoji decorates messages when the theme uses emoji.
+var emoji = map[ID]string{
+	ErrorIcon:   "❌ ",
+	WarningIcon: "⚠️  ",
+	Done:        "✅ ",
+}
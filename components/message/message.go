@@ -0,0 +1,117 @@
+package message
+
+import (
+	"fmt"
+	"io"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ID names a user-facing message. Translations map IDs to the text of
+// the message in a locale.
+type ID string
+
+// DefaultLocale is the locale of the built-in messages.
+const DefaultLocale = "en"
+
+// Printer formats user-facing messages in a locale and theme. The zero
+// value prints English messages using the default theme; so, embedded
+// usages only set what they need to change.
+type Printer struct {
+	// Locale names the language of the translations, like fr.
+	Locale string
+
+	// Translations replace the English text of messages. Messages
+	// without a translation are printed in English.
+	Translations map[ID]string
+
+	Theme Theme
+}
+
+// Text returns the message formatted with the arguments. Messages use
+// fmt verbs; so, translations can reorder arguments using explicit
+// indexes, like %[2]s.
+func (p Printer) Text(id ID, args ...interface{}) string {
+	text := fmt.Sprintf(p.template(id), args...)
+
+	if p.Theme.Emoji {
+		text = emoji[id] + text
+	}
+
+	return text
+}
+
+// Print writes the message unless the theme is quiet.
+func (p Printer) Print(w io.Writer, id ID, args ...interface{}) {
+	if p.Theme.Verbosity == Quiet {
+		return
+	}
+
+	fmt.Fprint(w, p.Text(id, args...))
+}
+
+// Println writes the message followed by a new line unless the theme
+// is quiet.
+func (p Printer) Println(w io.Writer, id ID, args ...interface{}) {
+	if p.Theme.Verbosity == Quiet {
+		return
+	}
+
+	fmt.Fprintln(w, p.Text(id, args...))
+}
+
+// Summary writes the message as the summary of an operation, like the
+// count of files pushed, unless the theme is quiet.
+func (p Printer) Summary(w io.Writer, id ID, args ...interface{}) {
+	if p.Theme.Verbosity == Quiet {
+		return
+	}
+
+	fmt.Fprintf(w, "\n%s\n\n", p.Style(Summary, p.Text(id, args...)))
+}
+
+// Detail writes the message followed by a new line only when the theme
+// is verbose.
+func (p Printer) Detail(w io.Writer, id ID, args ...interface{}) {
+	if p.Theme.Verbosity != Verbose {
+		return
+	}
+
+	fmt.Fprintln(w, p.Text(id, args...))
+}
+
+// Style returns text displayed in a style, like the path of a file,
+// using the theme's colors.
+func (p Printer) Style(style Style, text string) string {
+	return p.Theme.color(style).Sprint(text)
+}
+
+func (p Printer) template(id ID) string {
+	if t, found := p.Translations[id]; found {
+		return t
+	}
+
+	if t, found := english[id]; found {
+		return t
+	}
+
+	return string(id)
+}
+
+// ParseTranslations reads a YAML file mapping message IDs to their text
+// in a locale. Translations must be for known messages.
+func ParseTranslations(data []byte) (map[ID]string, error) {
+	translations := map[ID]string{}
+
+	if err := yaml.Unmarshal(data, &translations); err != nil {
+		return nil, err
+	}
+
+	for id := range translations {
+		if _, found := english[id]; !found {
+			return nil, fmt.Errorf("%s is not a message", id)
+		}
+	}
+
+	return translations, nil
+}
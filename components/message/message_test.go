@@ -0,0 +1,105 @@
+package message
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTextUsesEnglishWithoutTranslation(t *testing.T) {
+	// arrange
+	p := Printer{Locale: "fr", Translations: map[ID]string{PushSkipping: "Ignoré %s"}}
+
+	// act
+	actual := p.Text(PurgeSummary, 1, 2)
+
+	// assert
+	expected := "1 of 2 file(s) purged from remote storage."
+	if actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
+
+func TestTextReordersTranslatedArguments(t *testing.T) {
+	// arrange
+	p := Printer{Locale: "de", Translations: map[ID]string{Pushing: "[%[2]s] <- [%[1]s]"}}
+
+	// act
+	actual := p.Text(Pushing, ".env", "aws-s3")
+
+	// assert
+	expected := "[aws-s3] <- [.env]"
+	if actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
+
+func TestTextUsesEmojiTheme(t *testing.T) {
+	// arrange
+	p := Printer{Locale: "fr", Translations: map[ID]string{Done: "(fait)"}, Theme: Theme{Emoji: true}}
+
+	// act
+	actual := p.Text(Done)
+
+	// assert
+	expected := "✅ (fait)"
+	if actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
+
+func TestVerbosity(t *testing.T) {
+	tests := []struct {
+		verbosity Verbosity
+		expected  string
+	}{
+		{Normal, "Skipping .env\n"},
+		{Quiet, ""},
+		{Verbose, "Skipping .env\n  + PORT\n"},
+	}
+
+	for _, test := range tests {
+		// arrange
+		var buffer bytes.Buffer
+		p := Printer{Theme: Theme{Verbosity: test.verbosity}}
+
+		// act
+		p.Println(&buffer, PushSkipping, ".env")
+		p.Detail(&buffer, KeyAdded, "PORT")
+
+		// assert
+		if buffer.String() != test.expected {
+			t.Errorf("\nEXPECTED: %q \nACTUAL: %q", test.expected, buffer.String())
+		}
+	}
+}
+
+func TestParseTranslations(t *testing.T) {
+	// arrange
+	data := []byte("push.skipping: Ignoré %s\n")
+
+	// act
+	translations, err := ParseTranslations(data)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if translations[PushSkipping] != "Ignoré %s" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "Ignoré %s", translations[PushSkipping])
+	}
+}
+
+func TestParseTranslationsUnknownMessage(t *testing.T) {
+	// arrange
+	data := []byte("push.skiping: Ignoré %s\n")
+
+	// act
+	_, err := ParseTranslations(data)
+
+	// assert
+	expected := "push.skiping is not a message"
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
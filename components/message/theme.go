@@ -0,0 +1,111 @@
+package message
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Verbosity is how much informational output is displayed. Errors,
+// warnings, and prompts are always displayed.
+type Verbosity int
+
+const (
+	// Normal displays the progress of each file.
+	Normal Verbosity = iota
+
+	// Quiet displays nothing but errors, warnings, and prompts.
+	Quiet
+
+	// Verbose also displays details, like the keys a pull changed.
+	Verbose
+)
+
+// Style is the role of text in a message; so, themes can color it.
+type Style int
+
+const (
+	// Plain text is never colored.
+	Plain Style = iota
+
+	// Path is the path of a file.
+	Path
+
+	// Store is the name of a store or a source of files.
+	Store
+
+	// Summary is the count of files an operation changed.
+	Summary
+
+	// Success is an operation that succeeded.
+	Success
+
+	// Failure is an error or an operation that failed.
+	Failure
+
+	// Caution is a warning or an operation that needs attention.
+	Caution
+
+	// Heading names a group of details, like the tags of a file.
+	Heading
+)
+
+// Theme is how messages are displayed.
+type Theme struct {
+	Verbosity Verbosity
+
+	// Emoji decorates text symbols, like (done), with emoji.
+	Emoji bool
+
+	// NoColor displays every style as plain text.
+	NoColor bool
+}
+
+// themes are the themes users can select by name.
+var themes = map[string]Theme{
+	"default": {},
+	"plain":   {NoColor: true},
+	"emoji":   {Emoji: true},
+}
+
+// ParseTheme returns the theme selected by name.
+func ParseTheme(name string) (Theme, error) {
+	t, found := themes[strings.ToLower(name)]
+	if !found {
+		return Theme{}, fmt.Errorf("%s is not a theme, use %s", name, strings.Join(ThemeNames(), ", "))
+	}
+
+	return t, nil
+}
+
+// ThemeNames lists the themes users can select.
+func ThemeNames() []string {
+	names := []string{}
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var colors = map[Style][]color.Attribute{
+	Path:    {color.FgBlue},
+	Store:   {color.Bold},
+	Summary: {color.Bold},
+	Success: {color.FgGreen},
+	Failure: {color.Bold, color.FgRed},
+	Caution: {color.Bold, color.FgYellow},
+	Heading: {color.Bold},
+}
+
+func (t Theme) color(style Style) *color.Color {
+	c := color.New(colors[style]...)
+
+	if t.NoColor || len(colors[style]) == 0 {
+		c.DisableColor()
+	}
+
+	return c
+}
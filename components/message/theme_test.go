@@ -0,0 +1,43 @@
+package message
+
+import (
+	"testing"
+)
+
+func TestParseTheme(t *testing.T) {
+	// act
+	theme, err := ParseTheme("Plain")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !theme.NoColor {
+		t.Error("\nEXPECTED: plain theme without color")
+	}
+}
+
+func TestParseThemeUnknown(t *testing.T) {
+	// act
+	_, err := ParseTheme("neon")
+
+	// assert
+	expected := "neon is not a theme, use default, emoji, plain"
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
+
+func TestStyleWithoutColor(t *testing.T) {
+	// arrange
+	p := Printer{Theme: Theme{NoColor: true}}
+
+	// act
+	actual := p.Style(Failure, "failed")
+
+	// assert
+	if actual != "failed" {
+		t.Errorf("\nEXPECTED: %q \nACTUAL: %q", "failed", actual)
+	}
+}
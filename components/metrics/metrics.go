@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Latency is a duration reported in milliseconds.
+type Latency time.Duration
+
+// MarshalJSON ...
+func (l Latency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(l) / float64(time.Millisecond))
+}
+
+func (l Latency) String() string {
+	return time.Duration(l).Round(time.Millisecond).String()
+}
+
+// Stats summarizes the calls made to a store.
+type Stats struct {
+	Store     string         `json:"store"`
+	Calls     int            `json:"calls"`
+	Errors    int            `json:"errors"`
+	Retries   int            `json:"retries"`
+	Throttles int            `json:"throttles"`
+	Ops       map[string]int `json:"ops"`
+	Total     Latency        `json:"totalMs"`
+	P50       Latency        `json:"p50Ms"`
+	P90       Latency        `json:"p90Ms"`
+	P99       Latency        `json:"p99Ms"`
+}
+
+// File results of retrieving a file.
+const (
+	FilePulled   = "pulled"
+	FileUpToDate = "up_to_date"
+	FileOffline  = "offline"
+	FileFailed   = "failed"
+)
+
+// FileStats summarizes the attempts to retrieve a file.
+type FileStats struct {
+	Context  string         `json:"context"`
+	Path     string         `json:"path"`
+	Results  map[string]int `json:"results"`
+	LastSync time.Time      `json:"lastSync"`
+}
+
+// Summary summarizes the calls made to all stores by the command.
+type Summary struct {
+	Elapsed Latency     `json:"elapsedMs"`
+	Stores  []Stats     `json:"stores"`
+	Files   []FileStats `json:"files,omitempty"`
+}
+
+type call struct {
+	op      string
+	elapsed time.Duration
+	failed  bool
+}
+
+var (
+	mu        sync.Mutex
+	started   = time.Now()
+	calls     = map[string][]call{}
+	retries   = map[string]int{}
+	throttles = map[string]int{}
+	files     = map[string]*FileStats{}
+)
+
+// Record saves the latency and outcome of a store call.
+func Record(store, op string, elapsed time.Duration, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	calls[store] = append(calls[store], call{op: op, elapsed: elapsed, failed: err != nil})
+}
+
+// Retried saves the number of times a store call was retried.
+func Retried(store string, count int) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	retries[store] += count
+}
+
+// Throttled saves a store call rejected for exceeding a rate limit.
+func Throttled(store string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	throttles[store]++
+}
+
+// RecordFile saves the result of retrieving a file. "synced" is when
+// the file was last retrieved from its store, or zero when never.
+func RecordFile(context, path, result string, synced time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := context + "/" + path
+
+	f, found := files[key]
+	if !found {
+		f = &FileStats{Context: context, Path: path, Results: map[string]int{}}
+		files[key] = f
+	}
+
+	f.Results[result]++
+
+	if synced.After(f.LastSync) {
+		f.LastSync = synced.UTC()
+	}
+}
+
+// Summarize returns the stats for each store called, sorted by store.
+func Summarize() Summary {
+	mu.Lock()
+	defer mu.Unlock()
+
+	stores := map[string]bool{}
+	for s := range calls {
+		stores[s] = true
+	}
+	for s := range retries {
+		stores[s] = true
+	}
+	for s := range throttles {
+		stores[s] = true
+	}
+
+	summary := Summary{
+		Elapsed: Latency(time.Since(started)),
+		Stores:  []Stats{},
+	}
+
+	for s := range stores {
+		stats := Stats{
+			Store:     s,
+			Calls:     len(calls[s]),
+			Retries:   retries[s],
+			Throttles: throttles[s],
+			Ops:       map[string]int{},
+		}
+
+		latencies := []time.Duration{}
+		for _, c := range calls[s] {
+			stats.Ops[c.op]++
+			stats.Total += Latency(c.elapsed)
+			if c.failed {
+				stats.Errors++
+			}
+			latencies = append(latencies, c.elapsed)
+		}
+
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		stats.P50 = Latency(percentile(latencies, 50))
+		stats.P90 = Latency(percentile(latencies, 90))
+		stats.P99 = Latency(percentile(latencies, 99))
+
+		summary.Stores = append(summary.Stores, stats)
+	}
+
+	sort.Slice(summary.Stores, func(i, j int) bool {
+		return summary.Stores[i].Store < summary.Stores[j].Store
+	})
+
+	for _, f := range files {
+		results := map[string]int{}
+		for r, count := range f.Results {
+			results[r] = count
+		}
+
+		summary.Files = append(summary.Files, FileStats{Context: f.Context, Path: f.Path, Results: results, LastSync: f.LastSync})
+	}
+
+	sort.Slice(summary.Files, func(i, j int) bool {
+		if summary.Files[i].Context != summary.Files[j].Context {
+			return summary.Files[i].Context < summary.Files[j].Context
+		}
+		return summary.Files[i].Path < summary.Files[j].Path
+	})
+
+	return summary
+}
+
+// StoreTime returns the total time spent in store calls.
+func (s Summary) StoreTime() Latency {
+	total := Latency(0)
+	for _, st := range s.Stores {
+		total += st.Total
+	}
+	return total
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// reset clears recorded calls.
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	started = time.Now()
+	calls = map[string][]call{}
+	retries = map[string]int{}
+	throttles = map[string]int{}
+	files = map[string]*FileStats{}
+}
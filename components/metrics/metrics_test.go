@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	// arrange
+	latencies := []time.Duration{}
+	for i := 1; i <= 10; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := map[int]time.Duration{
+		50: 5 * time.Millisecond,
+		90: 9 * time.Millisecond,
+		99: 10 * time.Millisecond,
+	}
+
+	for p, expected := range tests {
+		// act
+		actual := percentile(latencies, p)
+
+		// assert
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	// arrange
+	reset()
+
+	Record("aws-s3", "pull", 10*time.Millisecond, nil)
+	Record("aws-s3", "push", 30*time.Millisecond, errors.New("throttled"))
+	Retried("aws-s3", 2)
+	Throttled("aws-s3")
+	Retried("harbor", 1)
+
+	// act
+	summary := Summarize()
+
+	// assert
+	if len(summary.Stores) != 2 {
+		t.Fatalf("\nEXPECTED: %d \nACTUAL: %d", 2, len(summary.Stores))
+	}
+
+	s3 := summary.Stores[0]
+	if s3.Store != "aws-s3" || s3.Calls != 2 || s3.Errors != 1 || s3.Retries != 2 || s3.Throttles != 1 {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %+v", "aws-s3 with 2 calls, 1 error, 2 retries, 1 throttle", s3)
+	}
+
+	if summary.StoreTime() != Latency(40*time.Millisecond) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", Latency(40*time.Millisecond), summary.StoreTime())
+	}
+
+	b, err := json.Marshal(s3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"store":"aws-s3","calls":2,"errors":1,"retries":2,"throttles":1,"ops":{"pull":1,"push":1},"totalMs":40,"p50Ms":10,"p90Ms":30,"p99Ms":30}`
+	if string(b) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, string(b))
+	}
+}
+
+func TestRecordFile(t *testing.T) {
+	// arrange
+	reset()
+
+	synced := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	RecordFile("my-app", ".env", FileFailed, synced.Add(-time.Hour))
+	RecordFile("my-app", ".env", FilePulled, synced)
+
+	// act
+	summary := Summarize()
+
+	// assert
+	if len(summary.Files) != 1 {
+		t.Fatalf("\nEXPECTED: %d \nACTUAL: %d", 1, len(summary.Files))
+	}
+
+	f := summary.Files[0]
+	if f.Results[FileFailed] != 1 || f.Results[FilePulled] != 1 || !f.LastSync.Equal(synced) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %+v", "1 failed, 1 pulled, synced at 12:00", f)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	// arrange
+	reset()
+
+	Record("aws-s3", "pull", 10*time.Millisecond, errors.New("denied"))
+	RecordFile("my-app", "config/.env", FileFailed, time.Unix(1772366400, 0))
+
+	out := bytes.Buffer{}
+
+	// act
+	err := WritePrometheus(&out, Summarize())
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`cstore_store_calls{store="aws-s3",op="pull"} 1`,
+		`cstore_store_errors{store="aws-s3"} 1`,
+		`cstore_store_latency_seconds{store="aws-s3",quantile="0.5"} 0.01`,
+		`cstore_file_pulls{context="my-app",file="config/.env",result="failed"} 1`,
+		`cstore_file_pulls{context="my-app",file="config/.env",result="pulled"} 0`,
+		`cstore_file_last_sync_timestamp_seconds{context="my-app",file="config/.env"} 1772366400`,
+	} {
+		if !strings.Contains(out.String(), expected+"\n") {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, out.String())
+		}
+	}
+}
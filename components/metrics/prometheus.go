@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WritePrometheus writes the summary in the Prometheus text format;
+// so, a scheduled pull can be scraped through the node_exporter
+// textfile collector. Counts are for the command, not cumulative.
+func WritePrometheus(w io.Writer, s Summary) error {
+	p := &promWriter{w: w}
+
+	p.metric("cstore_run_timestamp_seconds", "gauge", "When the command finished.")
+	p.sample("cstore_run_timestamp_seconds", nil, float64(time.Now().Unix()))
+
+	p.metric("cstore_store_calls", "gauge", "Store calls made by the command.")
+	for _, st := range s.Stores {
+		ops := []string{}
+		for op := range st.Ops {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops)
+
+		for _, op := range ops {
+			p.sample("cstore_store_calls", []string{"store", st.Store, "op", op}, float64(st.Ops[op]))
+		}
+	}
+
+	p.metric("cstore_store_errors", "gauge", "Store calls that failed.")
+	for _, st := range s.Stores {
+		p.sample("cstore_store_errors", []string{"store", st.Store}, float64(st.Errors))
+	}
+
+	p.metric("cstore_store_retries", "gauge", "Store calls retried by the store SDK.")
+	for _, st := range s.Stores {
+		p.sample("cstore_store_retries", []string{"store", st.Store}, float64(st.Retries))
+	}
+
+	p.metric("cstore_store_throttles", "gauge", "Store calls rejected for exceeding a rate limit.")
+	for _, st := range s.Stores {
+		p.sample("cstore_store_throttles", []string{"store", st.Store}, float64(st.Throttles))
+	}
+
+	p.metric("cstore_store_latency_seconds", "summary", "Latency of store calls.")
+	for _, st := range s.Stores {
+		quantiles := []struct {
+			q string
+			l Latency
+		}{{"0.5", st.P50}, {"0.9", st.P90}, {"0.99", st.P99}}
+
+		for _, q := range quantiles {
+			p.sample("cstore_store_latency_seconds", []string{"store", st.Store, "quantile", q.q}, time.Duration(q.l).Seconds())
+		}
+		p.sample("cstore_store_latency_seconds_sum", []string{"store", st.Store}, time.Duration(st.Total).Seconds())
+		p.sample("cstore_store_latency_seconds_count", []string{"store", st.Store}, float64(st.Calls))
+	}
+
+	p.metric("cstore_file_pulls", "gauge", "Attempts to retrieve a file by result.")
+	for _, f := range s.Files {
+		for _, r := range []string{FilePulled, FileUpToDate, FileOffline, FileFailed} {
+			p.sample("cstore_file_pulls", []string{"context", f.Context, "file", f.Path, "result", r}, float64(f.Results[r]))
+		}
+	}
+
+	p.metric("cstore_file_last_sync_timestamp_seconds", "gauge", "When the file was last retrieved from its store.")
+	for _, f := range s.Files {
+		if !f.LastSync.IsZero() {
+			p.sample("cstore_file_last_sync_timestamp_seconds", []string{"context", f.Context, "file", f.Path}, float64(f.LastSync.Unix()))
+		}
+	}
+
+	return p.err
+}
+
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) metric(name, kind, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a value with labels listed as name, value pairs.
+func (p *promWriter) sample(name string, labels []string, value float64) {
+	pairs := []string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", labels[i], labels[i+1]))
+	}
+
+	if len(pairs) > 0 {
+		name = fmt.Sprintf("%s{%s}", name, strings.Join(pairs, ","))
+	}
+
+	p.printf("%s %s\n", name, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (p *promWriter) printf(format string, a ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, a...)
+	}
+}
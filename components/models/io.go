@@ -1,10 +1,17 @@
 package models
 
-import "io"
+import (
+	"io"
+
+	"github.com/turnerlabs/cstore/components/message"
+)
 
 // IO ...
 type IO struct {
 	UserOutput io.Writer
 	UserInput  io.Reader
 	Export     io.Writer
+
+	// Messages formats user-facing output in a locale and theme.
+	Messages message.Printer
 }
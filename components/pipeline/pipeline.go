@@ -0,0 +1,149 @@
+package pipeline
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/turnerlabs/cstore/components/cipher"
+)
+
+const (
+	// GzipStage compresses files.
+	GzipStage = "gzip"
+
+	// AESStage encrypts files with an AES key from the access vault.
+	AESStage = "aes"
+
+	// KMSStage encrypts files with an AWS KMS key.
+	KMSStage = "kms"
+
+	// EnvelopeStage encrypts files with a data key wrapped for each
+	// recipient recorded in the catalog.
+	EnvelopeStage = "envelope"
+
+	// Base64Stage encodes files as text.
+	Base64Stage = "base64"
+)
+
+// Encrypts reports whether any of the named stages encrypts files.
+func Encrypts(names []string) bool {
+	for _, name := range names {
+		switch name {
+		case AESStage, KMSStage, EnvelopeStage:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Stage transforms the bytes of a file before they are pushed and
+// reverses the transformation after they are pulled.
+type Stage interface {
+	Encode(data []byte) ([]byte, error)
+	Decode(data []byte) ([]byte, error)
+}
+
+// Chain is the ordered list of stages declared for a file.
+type Chain struct {
+	Names  []string
+	Stages []Stage
+}
+
+// Add appends a stage to the end of the chain.
+func (c *Chain) Add(name string, s Stage) {
+	c.Names = append(c.Names, name)
+	c.Stages = append(c.Stages, s)
+}
+
+// Encode passes the file through each stage in order.
+func (c Chain) Encode(data []byte) ([]byte, error) {
+	var err error
+
+	for i, s := range c.Stages {
+		if data, err = s.Encode(data); err != nil {
+			return nil, fmt.Errorf("%s failed (%s)", c.Names[i], err)
+		}
+	}
+
+	return data, nil
+}
+
+// Decode passes the file through each stage in reverse order.
+func (c Chain) Decode(data []byte) ([]byte, error) {
+	var err error
+
+	for i := len(c.Stages) - 1; i >= 0; i-- {
+		if data, err = c.Stages[i].Decode(data); err != nil {
+			return nil, fmt.Errorf("%s failed (%s)", c.Names[i], err)
+		}
+	}
+
+	return data, nil
+}
+
+// Gzip ...
+type Gzip struct{}
+
+// Encode ...
+func (Gzip) Encode(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Decode ...
+func (Gzip) Decode(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
+// Base64 ...
+type Base64 struct{}
+
+// Encode ...
+func (Base64) Encode(data []byte) ([]byte, error) {
+	return []byte(base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// Decode ...
+func (Base64) Decode(data []byte) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+}
+
+// AES encrypts files using a 16 or 32 character key.
+type AES struct {
+	Key string
+}
+
+// Encode ...
+func (a AES) Encode(data []byte) ([]byte, error) {
+	return cipher.Encrypt(a.Key, data)
+}
+
+// Decode ...
+func (a AES) Decode(data []byte) ([]byte, error) {
+	if len(data) < aes.BlockSize {
+		return nil, fmt.Errorf("encrypted data is too short")
+	}
+
+	return cipher.Decrypt(a.Key, data)
+}
@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type upper struct{}
+
+func (upper) Encode(data []byte) ([]byte, error) { return bytes.ToUpper(data), nil }
+func (upper) Decode(data []byte) ([]byte, error) { return bytes.ToLower(data), nil }
+
+func TestChainRoundTrip(t *testing.T) {
+	// arrange
+	file := []byte(strings.Repeat("DB_HOST=localhost\n", 50))
+
+	c := Chain{}
+	c.Add(GzipStage, Gzip{})
+	c.Add(AESStage, AES{Key: "0123456789abcdef0123456789abcdef"})
+	c.Add(Base64Stage, Base64{})
+
+	// act
+	encoded, err := c.Encode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := c.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if bytes.Contains(encoded, []byte("DB_HOST")) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "encoded file", string(encoded))
+	}
+
+	if !bytes.Equal(decoded, file) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", string(file), string(decoded))
+	}
+}
+
+func TestChainDecodesInReverse(t *testing.T) {
+	// arrange
+	c := Chain{}
+	c.Add("upper", upper{})
+	c.Add(Base64Stage, Base64{})
+
+	// act
+	encoded, _ := c.Encode([]byte("a=b"))
+	decoded, err := c.Decode(encoded)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(encoded) != "QT1C" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "QT1C", string(encoded))
+	}
+
+	if string(decoded) != "a=b" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "a=b", string(decoded))
+	}
+}
+
+func TestChainDecodeErrorNamesStage(t *testing.T) {
+	// arrange
+	c := Chain{}
+	c.Add(GzipStage, Gzip{})
+
+	// act
+	_, err := c.Decode([]byte("not compressed"))
+
+	// assert
+	if err == nil || !strings.HasPrefix(err.Error(), "gzip failed") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "gzip failed", err)
+	}
+}
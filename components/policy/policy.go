@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/subosito/gotenv"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Policy contains the rules a push must satisfy. Built-in rules are
+// evaluated first followed by any rego policies. Stores limits the
+// stores every command can use.
+type Policy struct {
+	Rules  []Rule         `yaml:"rules"`
+	Rego   []Rego         `yaml:"rego"`
+	Stores []AllowedStore `yaml:"stores"`
+
+	// allowlists holds the stores allowed by each policy file loaded;
+	// so, a store must be allowed by all of them.
+	allowlists [][]AllowedStore
+}
+
+// Input describes the push being evaluated. Values are never included
+// so policies can be evaluated by external tools without exposing
+// secrets.
+type Input struct {
+	Path          string    `json:"path"`
+	Type          string    `json:"type"`
+	Store         string    `json:"store"`
+	Version       string    `json:"version"`
+	Tags          []string  `json:"tags"`
+	Keys          []string  `json:"keys"`
+	TokenizedKeys []string  `json:"tokenized_keys"`
+	Time          time.Time `json:"time"`
+}
+
+// Violation describes a rule preventing a push.
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// Load reads and combines policy files. Empty paths are skipped and
+// no paths returns an empty policy allowing every push and store.
+func Load(paths ...string) (Policy, error) {
+	p := Policy{}
+
+	for _, path := range paths {
+		if len(path) == 0 {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return p, err
+		}
+
+		loaded := Policy{}
+		if err := yaml.Unmarshal(b, &loaded); err != nil {
+			return p, fmt.Errorf("%s: %s", path, err)
+		}
+
+		p.Rules = append(p.Rules, loaded.Rules...)
+		p.Rego = append(p.Rego, loaded.Rego...)
+
+		if len(loaded.Stores) > 0 {
+			p.allowlists = append(p.allowlists, loaded.Stores)
+		}
+	}
+
+	return p, nil
+}
+
+// NewInput builds the policy input for a file being pushed.
+func NewInput(path, fileType, store, version string, tags []string, data []byte) Input {
+	in := Input{
+		Path:          path,
+		Type:          fileType,
+		Store:         store,
+		Version:       version,
+		Tags:          tags,
+		Keys:          []string{},
+		TokenizedKeys: []string{},
+		Time:          time.Now(),
+	}
+
+	if fileType != "env" {
+		return in
+	}
+
+	for key, value := range gotenv.Parse(bytes.NewReader(data)) {
+		in.Keys = append(in.Keys, key)
+
+		if strings.Contains(value, "{{") && strings.Contains(value, "}}") {
+			in.TokenizedKeys = append(in.TokenizedKeys, key)
+		}
+	}
+
+	sort.Strings(in.Keys)
+	sort.Strings(in.TokenizedKeys)
+
+	return in
+}
+
+// Evaluate returns the violations preventing the push.
+func (p Policy) Evaluate(in Input) ([]Violation, error) {
+	violations := []Violation{}
+
+	for _, r := range p.Rules {
+		v, err := r.Evaluate(in)
+		if err != nil {
+			return violations, err
+		}
+
+		violations = append(violations, v...)
+	}
+
+	for _, r := range p.Rego {
+		v, err := r.Evaluate(in)
+		if err != nil {
+			return violations, err
+		}
+
+		violations = append(violations, v...)
+	}
+
+	return violations, nil
+}
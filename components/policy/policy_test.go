@@ -0,0 +1,153 @@
+package policy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRequiredTags(t *testing.T) {
+	// arrange
+	p := Policy{Rules: []Rule{{Type: RequiredTags, Tags: []string{"owner", "prod"}}}}
+	in := NewInput(".env", "env", "aws-s3", "", []string{"prod"}, []byte{})
+
+	// act
+	violations, err := p.Evaluate(in)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(violations) != 1 || violations[0].Rule != RequiredTags {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %d", 1, len(violations))
+	}
+}
+
+func TestPlaintextSecrets(t *testing.T) {
+	// arrange
+	data := []byte("DB_PASSWORD=hunter2\nAPI_KEY={{dev/API_KEY}}\nURL=http://example.com\n")
+
+	p := Policy{Rules: []Rule{{Type: PlaintextSecrets, Stores: []string{"aws-parameter"}}}}
+
+	// act
+	violations, err := p.Evaluate(NewInput(".env", "env", "aws-parameter", "", nil, data))
+	skipped, _ := p.Evaluate(NewInput(".env", "env", "aws-s3", "", nil, data))
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(violations) != 1 {
+		t.Fatalf("\nEXPECTED: %d \nACTUAL: %d", 1, len(violations))
+	}
+
+	if violations[0].Message != "DB_PASSWORD appears to be a plaintext secret; use a secret token instead" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "DB_PASSWORD violation", violations[0].Message)
+	}
+
+	if len(skipped) != 0 {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %d", 0, len(skipped))
+	}
+}
+
+func TestPushWindow(t *testing.T) {
+	// arrange
+	p := Policy{Rules: []Rule{{
+		Type:     PushWindow,
+		Tags:     []string{"prod"},
+		Days:     []string{"Mon", "Tue", "Wed", "Thu", "Fri"},
+		Start:    "09:00",
+		End:      "17:00",
+		Timezone: "UTC",
+	}}}
+
+	tests := []struct {
+		time     time.Time
+		tags     []string
+		expected int
+	}{
+		{time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC), []string{"prod"}, 0},
+		{time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC), []string{"prod"}, 1},
+		{time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC), []string{"prod"}, 1},
+		{time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC), []string{"dev"}, 0},
+	}
+
+	for _, test := range tests {
+		in := NewInput(".env", "env", "aws-s3", "", test.tags, []byte{})
+		in.Time = test.time
+
+		// act
+		violations, err := p.Evaluate(in)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(violations) != test.expected {
+			t.Errorf("\nEXPECTED: %d \nACTUAL: %d (%s)", test.expected, len(violations), test.time)
+		}
+	}
+}
+
+func TestAllowedStores(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	user := filepath.Join(dir, "user.yml")
+	repo := filepath.Join(dir, "repo.yml")
+
+	ioutil.WriteFile(user, []byte("stores:\n- name: aws-s3\n  endpoints: ['arn:aws:s3:::corp-*']\n- name: hashicorp-vault\n"), 0600)
+	ioutil.WriteFile(repo, []byte("stores:\n- name: aws-s3\n- name: aws-parameter\n"), 0600)
+
+	// act
+	p, err := Load(user, "", repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if err := p.AllowsStore("aws-s3"); err != nil {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %s", nil, err)
+	}
+
+	for _, name := range []string{"hashicorp-vault", "aws-parameter", "bitwarden"} {
+		if err := p.AllowsStore(name); err == nil {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %v", name+" not allowed", err)
+		}
+	}
+
+	if !p.RestrictsEndpoints("aws-s3") {
+		t.Error("expected aws-s3 endpoints to be restricted")
+	}
+
+	if err := p.AllowsEndpoint("aws-s3", "arn:aws:s3:::corp-config/my-app/.env"); err != nil {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %s", nil, err)
+	}
+
+	expected := "aws-s3 store endpoint arn:aws:s3:::personal/.env is not allowed by policy (allowed: arn:aws:s3:::corp-*)"
+	if err := p.AllowsEndpoint("aws-s3", "arn:aws:s3:::personal/.env"); err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
+
+func TestAllowedStoresEmpty(t *testing.T) {
+	// arrange
+	p := Policy{}
+
+	// act
+	err := p.AllowsStore("bitwarden")
+
+	// assert
+	if err != nil {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %s", nil, err)
+	}
+}
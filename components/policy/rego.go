@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	opaCLI       = "opa"
+	defaultQuery = "data.cstore.deny"
+)
+
+// Rego evaluates an OPA policy using the opa CLI. The query should
+// produce a set of messages; each message is a violation.
+type Rego struct {
+	Name  string `yaml:"name"`
+	File  string `yaml:"file"`
+	Query string `yaml:"query"`
+}
+
+// Evaluate ...
+func (r Rego) Evaluate(in Input) ([]Violation, error) {
+	query := r.Query
+	if len(query) == 0 {
+		query = defaultQuery
+	}
+
+	name := r.Name
+	if len(name) == 0 {
+		name = r.File
+	}
+
+	input, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	c := exec.Command(opaCLI, "eval", "--format", "json", "--data", r.File, "--stdin-input", query)
+	c.Stdin = bytes.NewReader(input)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return nil, fmt.Errorf("opa: %s", msg)
+		}
+		return nil, err
+	}
+
+	output := struct {
+		Result []struct {
+			Expressions []struct {
+				Value interface{} `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}{}
+
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, err
+	}
+
+	violations := []Violation{}
+
+	for _, result := range output.Result {
+		for _, exp := range result.Expressions {
+			messages, ok := exp.Value.([]interface{})
+			if !ok {
+				continue
+			}
+
+			for _, m := range messages {
+				violations = append(violations, Violation{
+					Rule:    name,
+					Message: fmt.Sprint(m),
+				})
+			}
+		}
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,160 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// RequiredTags blocks pushes of files missing any of the listed tags.
+	RequiredTags = "required-tags"
+
+	// PlaintextSecrets blocks pushes to the listed stores when variable
+	// names matching a pattern have values that are not tokens.
+	PlaintextSecrets = "plaintext-secrets"
+
+	// PushWindow blocks pushes of files with any of the listed tags
+	// outside of the specified days and hours.
+	PushWindow = "push-window"
+)
+
+var defaultSecretPatterns = []string{`(?i)(password|passwd|secret|token|api_?key|private_?key)`}
+
+// Rule is a built-in policy rule.
+type Rule struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+
+	Tags     []string `yaml:"tags"`
+	Stores   []string `yaml:"stores"`
+	Patterns []string `yaml:"patterns"`
+
+	Days     []string `yaml:"days"`
+	Start    string   `yaml:"start"`
+	End      string   `yaml:"end"`
+	Timezone string   `yaml:"timezone"`
+}
+
+// Evaluate ...
+func (r Rule) Evaluate(in Input) ([]Violation, error) {
+	switch r.Type {
+	case RequiredTags:
+		return r.requiredTags(in), nil
+	case PlaintextSecrets:
+		return r.plaintextSecrets(in)
+	case PushWindow:
+		return r.pushWindow(in)
+	default:
+		return nil, fmt.Errorf("unknown policy rule type: %s", r.Type)
+	}
+}
+
+func (r Rule) violation(format string, a ...interface{}) Violation {
+	name := r.Name
+	if len(name) == 0 {
+		name = r.Type
+	}
+
+	return Violation{
+		Rule:    name,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+func (r Rule) requiredTags(in Input) []Violation {
+	violations := []Violation{}
+
+	for _, tag := range r.Tags {
+		if !contains(in.Tags, tag) {
+			violations = append(violations, r.violation("missing required tag '%s'", tag))
+		}
+	}
+
+	return violations
+}
+
+func (r Rule) plaintextSecrets(in Input) ([]Violation, error) {
+	violations := []Violation{}
+
+	if len(r.Stores) > 0 && !contains(r.Stores, in.Store) {
+		return violations, nil
+	}
+
+	patterns := r.Patterns
+	if len(patterns) == 0 {
+		patterns = defaultSecretPatterns
+	}
+
+	for _, p := range patterns {
+		exp, err := regexp.Compile(p)
+		if err != nil {
+			return violations, err
+		}
+
+		for _, key := range in.Keys {
+			if exp.MatchString(key) && !contains(in.TokenizedKeys, key) {
+				violations = append(violations, r.violation("%s appears to be a plaintext secret; use a secret token instead", key))
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+func (r Rule) pushWindow(in Input) ([]Violation, error) {
+	if len(r.Tags) > 0 && !containsAny(in.Tags, r.Tags) {
+		return nil, nil
+	}
+
+	loc := time.Local
+	if len(r.Timezone) > 0 {
+		l, err := time.LoadLocation(r.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		loc = l
+	}
+
+	now := in.Time.In(loc)
+
+	if len(r.Days) > 0 && !containsFold(r.Days, now.Weekday().String()[0:3]) {
+		return []Violation{r.violation("pushes are not allowed on %s", now.Weekday())}, nil
+	}
+
+	clock := now.Format("15:04")
+
+	if (len(r.Start) > 0 && clock < r.Start) || (len(r.End) > 0 && clock >= r.End) {
+		return []Violation{r.violation("pushes are only allowed between %s and %s", r.Start, r.End)}, nil
+	}
+
+	return nil, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) || (len(v) > 3 && strings.EqualFold(v[0:3], value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values []string, any []string) bool {
+	for _, a := range any {
+		if contains(values, a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RepoFileName is the policy file committed next to a catalog. It is
+// combined with the policy in the user configuration.
+const RepoFileName = "cstore-policy.yml"
+
+// AllowedStore permits a store. When endpoints are listed, the store
+// can only be used when its location matches one of the patterns where
+// '*' matches any characters, like 's3://corp-*'.
+type AllowedStore struct {
+	Name      string   `yaml:"name"`
+	Endpoints []string `yaml:"endpoints"`
+}
+
+// StoreError is returned when a policy does not allow a store or the
+// location it is configured to use.
+type StoreError struct {
+	Store    string
+	Endpoint string
+	Allowed  []string
+}
+
+func (e StoreError) Error() string {
+	if len(e.Endpoint) > 0 {
+		return fmt.Sprintf("%s store endpoint %s is not allowed by policy (allowed: %s)", e.Store, e.Endpoint, strings.Join(e.Allowed, ", "))
+	}
+
+	return fmt.Sprintf("%s store is not allowed by policy (allowed: %s)", e.Store, strings.Join(e.Allowed, ", "))
+}
+
+func (p Policy) lists() [][]AllowedStore {
+	if len(p.allowlists) == 0 && len(p.Stores) > 0 {
+		return [][]AllowedStore{p.Stores}
+	}
+	return p.allowlists
+}
+
+// AllowsStore returns a StoreError when the store is not allowed.
+// Every store is allowed when the policy does not list stores.
+func (p Policy) AllowsStore(name string) error {
+	for _, list := range p.lists() {
+		allowed := []string{}
+		found := false
+
+		for _, s := range list {
+			allowed = append(allowed, s.Name)
+			found = found || s.Name == name
+		}
+
+		if !found {
+			return StoreError{Store: name, Allowed: allowed}
+		}
+	}
+
+	return nil
+}
+
+// RestrictsEndpoints returns true when the store is only allowed for
+// some endpoints.
+func (p Policy) RestrictsEndpoints(name string) bool {
+	for _, list := range p.lists() {
+		for _, s := range list {
+			if s.Name == name && len(s.Endpoints) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// AllowsEndpoint returns a StoreError when the store location does not
+// match an allowed endpoint.
+func (p Policy) AllowsEndpoint(name, endpoint string) error {
+	if err := p.AllowsStore(name); err != nil {
+		return err
+	}
+
+	for _, list := range p.lists() {
+		patterns := []string{}
+
+		for _, s := range list {
+			if s.Name == name {
+				patterns = append(patterns, s.Endpoints...)
+			}
+		}
+
+		if len(patterns) > 0 && !matchesAny(patterns, endpoint) {
+			return StoreError{Store: name, Endpoint: endpoint, Allowed: patterns}
+		}
+	}
+
+	return nil
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		expr := "^" + strings.Replace(regexp.QuoteMeta(pattern), `\*`, ".*", -1) + "$"
+
+		if matched, _ := regexp.MatchString(expr, value); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,50 @@
+package pool
+
+import "sync"
+
+//------------------------------------------
+//- Store operations on many files, like
+//- pulling every cataloged file, are run
+//- by a fixed number of workers. Each job
+//- keeps its own error; so, one file
+//- failing does not stop the others and
+//- every failure can be reported.
+//------------------------------------------
+
+// Run calls job for each index from 0 to count-1 using up to workers
+// goroutines at once and returns the error of each job by index.
+func Run(workers, count int, job func(i int) error) []error {
+	errs := make([]error, count)
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	if workers > count {
+		workers = count
+	}
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				errs[i] = job(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return errs
+}
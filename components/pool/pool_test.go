@@ -0,0 +1,62 @@
+package pool
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunBoundsWorkers(t *testing.T) {
+	// arrange
+	mu := sync.Mutex{}
+	running, most := 0, 0
+
+	job := func(i int) error {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		if i%3 == 0 {
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	// act
+	errs := Run(4, 12, job)
+
+	// assert
+	if most > 4 {
+		t.Errorf("\nEXPECTED: at most %d \nACTUAL: %d", 4, most)
+	}
+
+	if len(errs) != 12 {
+		t.Fatalf("\nEXPECTED: %d \nACTUAL: %d", 12, len(errs))
+	}
+
+	for i, err := range errs {
+		if (i%3 == 0) != (err != nil) {
+			t.Errorf("unexpected error for job %d: %v", i, err)
+		}
+	}
+}
+
+func TestRunWithoutJobs(t *testing.T) {
+	// act
+	errs := Run(4, 0, func(i int) error { return nil })
+
+	// assert
+	if len(errs) != 0 {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %d", 0, len(errs))
+	}
+}
@@ -0,0 +1,121 @@
+package prompt
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var (
+	answers     = map[string]string{}
+	shared      = map[string]string{}
+	remembered  = map[string]string{}
+	assumeYes   = false
+	interactive = true
+)
+
+// AssumeYes causes confirmations to be accepted and prompts without
+// an answer to use their default values instead of waiting for input.
+func AssumeYes(enabled bool) {
+	assumeYes = enabled
+}
+
+// Interactive allows input to be read from the user. When disabled,
+// prompts are answered from answers, environment variables named like
+// the prompt, or default values, and a prompt or confirmation that
+// cannot be answered returns a MissingInputError.
+func Interactive(enabled bool) {
+	interactive = enabled
+}
+
+// Set answers a prompt with a value, like a value passed on the
+// command line. Values set are used before answers loaded from a file.
+func Set(name, value string) {
+	answers[name] = value
+}
+
+// LoadAnswers reads a yml file mapping prompt names to the values used
+// instead of asking the user.
+func LoadAnswers(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	a := map[string]string{}
+	if err := yaml.Unmarshal(b, &a); err != nil {
+		return err
+	}
+
+	for name, value := range answers {
+		a[name] = value
+	}
+
+	answers = a
+
+	return nil
+}
+
+// UseShared sets the answers shared by everyone using a repository.
+// Shared answers are only used for prompts marked shared and are
+// overridden by answers loaded from a file.
+func UseShared(values map[string]string) {
+	shared = map[string]string{}
+
+	for name, value := range values {
+		shared[name] = value
+	}
+}
+
+// Remembered returns the answers given to prompts marked shared that
+// are not already shared.
+func Remembered() map[string]string {
+	return remembered
+}
+
+func sharedFor(name string) (string, bool) {
+	value, found := shared[name]
+	return value, found && len(value) > 0
+}
+
+func remember(name, value string) {
+	if len(value) == 0 {
+		return
+	}
+
+	if current, found := shared[name]; found && current == value {
+		return
+	}
+
+	remembered[name] = value
+}
+
+// MissingInputError is returned when input is needed and cannot be
+// read from the user. Name is empty for confirmations.
+type MissingInputError struct {
+	Name string
+}
+
+func (e MissingInputError) Error() string {
+	if len(e.Name) == 0 {
+		return "missing input for confirmation, use -y to accept confirmations"
+	}
+
+	return fmt.Sprintf("missing input %s, use --set %s={value} or the %s environment variable", e.Name, e.Name, e.Name)
+}
+
+func answerFor(name string) (string, bool) {
+	if value, found := answers[name]; found {
+		return value, true
+	}
+
+	for key, value := range answers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
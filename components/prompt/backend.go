@@ -0,0 +1,117 @@
+package prompt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// HelperPrefix is prepended to a prompt helper name to find the helper
+// binary on the path. A helper named "dialog" is run as
+// "cstore-prompt-dialog".
+const HelperPrefix = "cstore-prompt-"
+
+// Backend reads answers from the user. The terminal is used unless a
+// different frontend is plugged in, like a desktop dialog or a
+// background approver for IDE tasks without a TTY.
+type Backend interface {
+
+	// Input should return the value entered for a prompt. An empty
+	// value selects the default value.
+	Input(name string, v Options) (string, error)
+
+	// Confirm should return true when the user accepts.
+	Confirm(description, level string) (bool, error)
+}
+
+var backend Backend
+
+// UseBackend sets the frontend used to read answers. A nil backend
+// reads answers from the terminal.
+func UseBackend(b Backend) {
+	backend = b
+}
+
+// HelperRequest is written as JSON to the prompt helper's stdin.
+type HelperRequest struct {
+	Type         string `json:"type"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+	DefaultValue string `json:"default,omitempty"`
+	Hidden       bool   `json:"hidden,omitempty"`
+	Level        string `json:"level,omitempty"`
+}
+
+// HelperResponse is read as JSON from the prompt helper's stdout.
+type HelperResponse struct {
+	Value     string `json:"value"`
+	Confirmed bool   `json:"confirmed"`
+}
+
+// Helper is a backend running an external program for each prompt.
+type Helper struct {
+	Name string
+}
+
+// Input ...
+func (h Helper) Input(name string, v Options) (string, error) {
+	r, err := h.run(HelperRequest{
+		Type:         "input",
+		Name:         name,
+		Description:  v.Description,
+		DefaultValue: v.DefaultValue,
+		Hidden:       v.HideInput,
+	})
+
+	return r.Value, err
+}
+
+// Confirm ...
+func (h Helper) Confirm(description, level string) (bool, error) {
+	r, err := h.run(HelperRequest{
+		Type:        "confirm",
+		Description: description,
+		Level:       level,
+	})
+
+	return r.Confirmed, err
+}
+
+func (h Helper) run(req HelperRequest) (HelperResponse, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return HelperResponse{}, err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	c := exec.Command(h.binary())
+	c.Stdin = bytes.NewReader(input)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return HelperResponse{}, fmt.Errorf("prompt helper %s: %s", h.Name, msg)
+		}
+		return HelperResponse{}, fmt.Errorf("prompt helper %s: %s", h.Name, err)
+	}
+
+	r := HelperResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+		return HelperResponse{}, fmt.Errorf("prompt helper %s returned invalid JSON (%s)", h.Name, err)
+	}
+
+	return r, nil
+}
+
+// binary allows a helper to be specified by name or by path.
+func (h Helper) binary() string {
+	if strings.ContainsRune(h.Name, os.PathSeparator) {
+		return h.Name
+	}
+	return HelperPrefix + h.Name
+}
@@ -0,0 +1,52 @@
+package prompt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+func TestHelperAnswersPrompts(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "cstore-prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	helper := filepath.Join(dir, "helper")
+	script := "#!/bin/sh\nif grep -q '\"type\":\"confirm\"'; then echo '{\"confirmed\":true}'; else echo '{\"value\":\"us-west-2\"}'; fi\n"
+
+	if err := ioutil.WriteFile(helper, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	UseBackend(Helper{Name: helper})
+	defer UseBackend(nil)
+
+	io := models.IO{UserOutput: ioutil.Discard, UserInput: bytes.NewReader([]byte{})}
+
+	// act
+	value, err := GetValFromUser("AWS_REGION", Options{DefaultValue: "us-east-1"}, io)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirmed, err := Confirm("Save AWS_REGION?", Warn, io)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if value != "us-west-2" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "us-west-2", value)
+	}
+
+	if !confirmed {
+		t.Errorf("\nEXPECTED: %t \nACTUAL: %t", true, confirmed)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/turnerlabs/cstore/components/display"
 	"github.com/turnerlabs/cstore/components/models"
 )
 
@@ -14,9 +15,14 @@ const (
 )
 
 // Confirm ...
-func Confirm(description, level string, io models.IO) bool {
+func Confirm(description, level string, io models.IO) (bool, error) {
+	asking.Lock()
+	defer asking.Unlock()
+
 	var s string
 
+	io.UserOutput = display.Loud(io.UserOutput)
+
 	switch level {
 	case Warn:
 		fmt.Fprintf(io.UserOutput, "\n%s%s%s%s%s (y/N): ", yellowColor, bold, description, unbold, noColor)
@@ -26,6 +32,32 @@ func Confirm(description, level string, io models.IO) bool {
 		fmt.Fprintf(io.UserOutput, "\n%s (y/N): ", description)
 	}
 
+	if assumeYes {
+		fmt.Fprintln(io.UserOutput, "y")
+		return true, nil
+	}
+
+	if !interactive {
+		fmt.Fprintln(io.UserOutput)
+		return false, MissingInputError{}
+	}
+
+	if backend != nil {
+		confirmed, err := backend.Confirm(description, level)
+		if err != nil {
+			display.Error(err, io)
+			return false, nil
+		}
+
+		if confirmed {
+			fmt.Fprintln(io.UserOutput, "y")
+		} else {
+			fmt.Fprintln(io.UserOutput, "N")
+		}
+
+		return confirmed, nil
+	}
+
 	c, err := fmt.Fscanf(io.UserInput, "%s\n", &s)
 	if c > 0 && err != nil {
 		panic(err)
@@ -35,7 +67,7 @@ func Confirm(description, level string, io models.IO) bool {
 	s = strings.ToLower(s)
 
 	if s == "y" || s == "yes" {
-		return true
+		return true, nil
 	}
-	return false
+	return false, nil
 }
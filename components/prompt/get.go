@@ -2,9 +2,12 @@ package prompt
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"syscall"
 
+	"github.com/turnerlabs/cstore/components/display"
 	"github.com/turnerlabs/cstore/components/models"
 	"golang.org/x/crypto/ssh/terminal"
 )
@@ -24,12 +27,30 @@ type Options struct {
 	Description  string
 	DefaultValue string
 	HideInput    bool
+
+	// Shared marks answers that are not secret and can be saved for
+	// everyone using the repository.
+	Shared bool
 }
 
+// asking serializes prompts; so, files handled concurrently do not
+// ask for input at the same time.
+var asking sync.Mutex
+
 // GetValFromUser ...
-func GetValFromUser(name string, v Options, io models.IO) string {
+func GetValFromUser(name string, v Options, io models.IO) (string, error) {
+	asking.Lock()
+	defer asking.Unlock()
+
 	var s string
 
+	io.UserOutput = display.Loud(io.UserOutput)
+
+	prior, fresh, known := recall(name)
+	if known && !fresh && v.Shared && !v.HideInput {
+		v.DefaultValue = prior
+	}
+
 	fmt.Fprintln(io.UserOutput)
 
 	if len(v.Description) > 0 {
@@ -42,12 +63,53 @@ func GetValFromUser(name string, v Options, io models.IO) string {
 
 	fmt.Fprintf(io.UserOutput, "%s%s:%s ", bold, name, unbold)
 
-	if v.HideInput {
+	asked := false
+
+	if answer, found := answerFor(name); found {
+		s = answer
+		if !v.HideInput {
+			fmt.Fprint(io.UserOutput, s)
+		}
+	} else if answer, found := sharedFor(name); found && v.Shared && !v.HideInput {
+		s = answer
+		fmt.Fprint(io.UserOutput, s)
+	} else if known && fresh && v.Shared && !v.HideInput {
+		s = prior
+		fmt.Fprint(io.UserOutput, s)
+		remember(name, s)
+	} else if value, found := os.LookupEnv(name); found && !interactive {
+		s = value
+		if !v.HideInput {
+			fmt.Fprint(io.UserOutput, s)
+		}
+	} else if assumeYes || !interactive {
+		if len(v.DefaultValue) == 0 && !interactive {
+			fmt.Fprintln(io.UserOutput)
+			return "", MissingInputError{Name: name}
+		}
+
+		s = v.DefaultValue
+		if !v.HideInput {
+			fmt.Fprint(io.UserOutput, s)
+		}
+	} else if backend != nil {
+		asked = true
+		answer, err := backend.Input(name, v)
+		if err != nil {
+			display.Error(err, io)
+		}
+		s = answer
+		if !v.HideInput {
+			fmt.Fprint(io.UserOutput, s)
+		}
+	} else if v.HideInput {
+		asked = true
 		password, err := terminal.ReadPassword(int(syscall.Stdin))
 		if err == nil {
 			s = string(password)
 		}
 	} else {
+		asked = true
 		c, err := fmt.Fscanf(io.UserInput, "%s\n", &s)
 		if c > 0 && err != nil {
 			panic(err)
@@ -59,8 +121,13 @@ func GetValFromUser(name string, v Options, io models.IO) string {
 	s = strings.TrimSpace(s)
 
 	if len(s) == 0 {
-		return v.DefaultValue
+		s = v.DefaultValue
+	}
+
+	if asked && v.Shared && !v.HideInput {
+		remember(name, s)
+		memorize(name, s)
 	}
 
-	return s
+	return s, nil
 }
@@ -0,0 +1,63 @@
+package prompt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+func TestNonInteractivePromptsUseEnvironment(t *testing.T) {
+	// arrange
+	Interactive(false)
+	defer Interactive(true)
+
+	os.Setenv("HARBOR_SHIPMENT", "my-shipment")
+	defer os.Unsetenv("HARBOR_SHIPMENT")
+
+	Set("HARBOR_ENVIRONMENT", "dev")
+	defer delete(answers, "HARBOR_ENVIRONMENT")
+
+	io := models.IO{UserOutput: ioutil.Discard, UserInput: bytes.NewReader([]byte{})}
+
+	// act
+	shipment, _ := GetValFromUser("HARBOR_SHIPMENT", Options{}, io)
+	environment, _ := GetValFromUser("HARBOR_ENVIRONMENT", Options{}, io)
+	region, _ := GetValFromUser("AWS_REGION", Options{DefaultValue: "us-east-1"}, io)
+
+	// assert
+	if shipment != "my-shipment" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "my-shipment", shipment)
+	}
+
+	if environment != "dev" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "dev", environment)
+	}
+
+	if region != "us-east-1" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "us-east-1", region)
+	}
+}
+
+func TestNonInteractivePromptsFailWithoutInput(t *testing.T) {
+	// arrange
+	Interactive(false)
+	defer Interactive(true)
+
+	io := models.IO{UserOutput: ioutil.Discard, UserInput: bytes.NewReader([]byte("typed\ny\n"))}
+
+	// act
+	_, err := GetValFromUser("AWS_S3_BUCKET", Options{}, io)
+	confirmed, cerr := Confirm("Continue?", Warn, io)
+
+	// assert
+	if missing, ok := err.(MissingInputError); !ok || missing.Name != "AWS_S3_BUCKET" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "missing input AWS_S3_BUCKET", err)
+	}
+
+	if _, ok := cerr.(MissingInputError); !ok || confirmed {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %t %v", "missing confirmation", confirmed, cerr)
+	}
+}
@@ -0,0 +1,125 @@
+package prompt
+
+import (
+	"os"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/local"
+	yaml "gopkg.in/yaml.v2"
+)
+
+//------------------------------------------
+//- Answers to prompts marked shared are
+//- remembered in ~/.cstore/prompts.yml;
+//- so, repeated commands, even in other
+//- repositories, are not asked again. Once
+//- an answer is older than the ttl, it is
+//- offered as the default value instead of
+//- being used; so, it is confirmed again.
+//------------------------------------------
+
+const memoryFile = "prompts.yml"
+
+// DefaultMemoryTTL is how long answers are used without asking again
+// when no ttl is configured.
+const DefaultMemoryTTL = 8 * time.Hour
+
+// memory is a remembered answer.
+type memory struct {
+	Value    string    `yaml:"value"`
+	Answered time.Time `yaml:"answered"`
+}
+
+var (
+	recalled  = map[string]memory{}
+	memorized = map[string]memory{}
+	memoryTTL = DefaultMemoryTTL
+)
+
+// UseMemory loads the answers remembered from earlier commands. Answers
+// older than the ttl are only offered as default values.
+func UseMemory(ttl time.Duration) error {
+	memoryTTL = ttl
+
+	m, err := readMemory()
+	if err != nil {
+		return err
+	}
+
+	recalled = m
+
+	return nil
+}
+
+// SaveMemory remembers the answers given to prompts marked shared during
+// the command.
+func SaveMemory() error {
+	if len(memorized) == 0 {
+		return nil
+	}
+
+	m, err := readMemory()
+	if err != nil {
+		m = map[string]memory{}
+	}
+
+	for name, answer := range memorized {
+		m[name] = answer
+	}
+
+	b, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return local.Update(memoryFile, "", b)
+}
+
+// Forget removes the remembered answers; so, prompts are asked again.
+func Forget() error {
+	recalled = map[string]memory{}
+
+	if err := os.Remove(local.BuildPath(memoryFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// recall returns the remembered answer to a prompt and whether it is
+// recent enough to be used without asking.
+func recall(name string) (value string, fresh bool, found bool) {
+	answer, found := recalled[name]
+	if !found || len(answer.Value) == 0 {
+		return "", false, false
+	}
+
+	return answer.Value, time.Since(answer.Answered) < memoryTTL, true
+}
+
+func memorize(name, value string) {
+	if len(value) == 0 {
+		return
+	}
+
+	memorized[name] = memory{Value: value, Answered: time.Now()}
+}
+
+func readMemory() (map[string]memory, error) {
+	m := map[string]memory{}
+
+	if local.Missing(memoryFile) {
+		return m, nil
+	}
+
+	b, err := local.Get(memoryFile, "")
+	if err != nil {
+		return m, err
+	}
+
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return map[string]memory{}, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,46 @@
+package prompt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+func TestRememberedAnswersExpire(t *testing.T) {
+	// arrange
+	recalled = map[string]memory{
+		"AWS_REGION":    {Value: "us-west-2", Answered: time.Now().Add(-time.Minute)},
+		"AWS_S3_BUCKET": {Value: "old-bucket", Answered: time.Now().Add(-2 * time.Hour)},
+	}
+	memoryTTL = time.Hour
+	defer func() {
+		recalled, memorized, memoryTTL = map[string]memory{}, map[string]memory{}, DefaultMemoryTTL
+		remembered = map[string]string{}
+	}()
+
+	io := models.IO{UserOutput: ioutil.Discard, UserInput: bytes.NewReader([]byte("\n"))}
+
+	// act
+	region, _ := GetValFromUser("AWS_REGION", Options{DefaultValue: "us-east-1", Shared: true}, io)
+	bucket, _ := GetValFromUser("AWS_S3_BUCKET", Options{Shared: true}, io)
+
+	// assert
+	if region != "us-west-2" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "us-west-2", region)
+	}
+
+	if bucket != "old-bucket" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "old-bucket", bucket)
+	}
+
+	if _, found := memorized["AWS_REGION"]; found {
+		t.Error("expected a fresh answer to keep its original time")
+	}
+
+	if _, found := memorized["AWS_S3_BUCKET"]; !found {
+		t.Error("expected a confirmed stale answer to be remembered again")
+	}
+}
@@ -0,0 +1,76 @@
+package prompt
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+const selectAttempts = 3
+
+// Select lists options for the user to choose from by number or by
+// value. The user is asked again when the selection is not one of the
+// options.
+func Select(name string, options []string, v Options, io models.IO) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("no options available for %s", name)
+	}
+
+	io.UserOutput = display.Loud(io.UserOutput)
+
+	if answer, found := sharedFor(name); found && v.Shared {
+		for _, o := range options {
+			if o == answer {
+				fmt.Fprintf(io.UserOutput, "\n%s%s:%s %s\n", bold, name, unbold, answer)
+				return o, nil
+			}
+		}
+	}
+
+	list := v.Description
+	if len(list) > 0 {
+		list += "\n"
+	}
+
+	list += "OPTIONS"
+	for i, o := range options {
+		list = fmt.Sprintf("%s\n (%d) %s", list, i+1, o)
+	}
+
+	if len(v.DefaultValue) == 0 && len(options) == 1 {
+		v.DefaultValue = options[0]
+	}
+
+	for attempt := 0; attempt < selectAttempts; attempt++ {
+		value, err := GetValFromUser(name, Options{
+			Description:  list,
+			DefaultValue: v.DefaultValue,
+		}, io)
+		if err != nil {
+			return "", err
+		}
+
+		if i, err := strconv.Atoi(value); err == nil && i > 0 && i <= len(options) {
+			value = options[i-1]
+		}
+
+		for _, o := range options {
+			if o == value {
+				if _, answered := answerFor(name); v.Shared && !answered {
+					remember(name, o)
+				}
+				return o, nil
+			}
+		}
+
+		if !interactive {
+			return "", fmt.Errorf("%s is not a valid %s option", value, name)
+		}
+
+		fmt.Fprintf(io.UserOutput, "%s%s is not a valid option.%s\n", redColor, value, noColor)
+	}
+
+	return "", fmt.Errorf("invalid %s selection", name)
+}
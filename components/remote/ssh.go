@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+const sshCLI = "ssh"
+
+// Write saves data to a file on a remote host over SSH. The file is
+// written to a temporary file with the requested mode and renamed;
+// so, the remote file is never partially written or readable by
+// others while being written.
+//
+// "host" is passed to ssh as is, like user@server or a host alias
+// from ~/.ssh/config. Hosts starting with "-" are rejected; so, they
+// cannot be read by ssh as options.
+func Write(host, filePath string, mode os.FileMode, data []byte) error {
+	if len(host) == 0 || strings.HasPrefix(host, "-") {
+		return fmt.Errorf("%q is not a valid SSH host", host)
+	}
+
+	if _, err := exec.LookPath(sshCLI); err != nil {
+		return errors.New("SSH client (ssh) not found")
+	}
+
+	var stderr bytes.Buffer
+
+	c := exec.Command(sshCLI, "-o", "BatchMode=yes", "--", host, script(filePath, mode))
+	c.Stdin = bytes.NewReader(data)
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return fmt.Errorf("ssh %s: %s", host, msg)
+		}
+		return fmt.Errorf("ssh %s: %s", host, err)
+	}
+
+	return nil
+}
+
+// script returns the remote shell command writing stdin to the file.
+func script(filePath string, mode os.FileMode) string {
+	tmp := filePath + ".cstore-tmp"
+
+	return fmt.Sprintf("umask 077 && mkdir -p %s && cat > %s && chmod %04o %s && mv -f %s %s",
+		quote(path.Dir(filePath)), quote(tmp), mode.Perm(), quote(tmp), quote(tmp), quote(filePath))
+}
+
+// quote wraps a value in single quotes for a POSIX shell.
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,75 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	// arrange
+	expected := `umask 077 && mkdir -p '/etc/app' && cat > '/etc/app/.env.cstore-tmp' && chmod 0640 '/etc/app/.env.cstore-tmp' && mv -f '/etc/app/.env.cstore-tmp' '/etc/app/.env'`
+
+	// act
+	actual := script("/etc/app/.env", 0640)
+
+	// assert
+	if actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
+
+func TestScriptWritesFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	// arrange
+	dir, err := ioutil.TempDir("", "cstore-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "it's", ".env")
+
+	c := exec.Command("sh", "-c", script(filePath, 0600))
+	c.Stdin = strings.NewReader("URL=x\n")
+
+	// act
+	err = c.Run()
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", os.FileMode(0600), info.Mode().Perm())
+	}
+
+	b, _ := ioutil.ReadFile(filePath)
+	if string(b) != "URL=x\n" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "URL=x", string(b))
+	}
+}
+
+func TestWriteRejectsOptionHost(t *testing.T) {
+	// act
+	err := Write("-oProxyCommand=touch /tmp/pwned", "/etc/app/.env", 0600, []byte("URL=x\n"))
+
+	// assert
+	expected := `"-oProxyCommand=touch /tmp/pwned" is not a valid SSH host`
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
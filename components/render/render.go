@@ -0,0 +1,82 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"text/template"
+)
+
+// ParameterStore is the store "ssm" looks up values in.
+const ParameterStore = "aws-parameter"
+
+// Sources resolve the values placed in a template. A nil source fails
+// the template using it.
+type Sources struct {
+
+	// Env should return a local environment variable.
+	Env func(name string) (string, bool)
+
+	// Store should return a value saved in a store by name, like an
+	// SSM parameter.
+	Store func(store, name string) (string, error)
+
+	// Secret should return a secret from the file's secrets vault.
+	Secret func(group, prop string) (string, error)
+}
+
+// Render resolves the placeholders in a file stored as a Go template;
+// so, one template can build a file for each environment.
+//
+//	REGION={{ env "AWS_REGION" }}
+//	DB_PASS={{ ssm "/app/db/pass" }}
+//	API_KEY={{ secret "dev/app" "api_key" }}
+//	TOKEN={{ store "aws-secrets" "app/token" }}
+func Render(tmpl []byte, s Sources) ([]byte, error) {
+	t, err := template.New("render").Option("missingkey=error").Funcs(template.FuncMap{
+		"env": func(name string) (string, error) {
+			if s.Env == nil {
+				return "", errors.New("environment variables are not available")
+			}
+
+			value, found := s.Env(name)
+			if !found {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+
+			return value, nil
+		},
+		"store": func(store, name string) (string, error) {
+			return s.lookup(store, name)
+		},
+		"ssm": func(name string) (string, error) {
+			return s.lookup(ParameterStore, name)
+		},
+		"secret": func(group, prop string) (string, error) {
+			if s.Secret == nil {
+				return "", errors.New("secrets are not available")
+			}
+
+			return s.Secret(group, prop)
+		},
+	}).Parse(string(tmpl))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.Buffer{}
+
+	if err := t.Execute(&buf, nil); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s Sources) lookup(store, name string) (string, error) {
+	if s.Store == nil {
+		return "", fmt.Errorf("%s values are not available", store)
+	}
+
+	return s.Store(store, name)
+}
@@ -0,0 +1,57 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderResolvesSources(t *testing.T) {
+	// arrange
+	tmpl := []byte("REGION={{ env \"REGION\" }}\nDB_PASS={{ ssm \"/app/db/pass\" }}\nAPI_KEY={{ secret \"dev/app\" \"api_key\" }}\n")
+
+	s := Sources{
+		Env: func(name string) (string, bool) {
+			return "us-east-1", name == "REGION"
+		},
+		Store: func(store, name string) (string, error) {
+			return fmt.Sprintf("%s:%s", store, name), nil
+		},
+		Secret: func(group, prop string) (string, error) {
+			return group + "." + prop, nil
+		},
+	}
+
+	// act
+	file, err := Render(tmpl, s)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "REGION=us-east-1\nDB_PASS=aws-parameter:/app/db/pass\nAPI_KEY=dev/app.api_key\n"
+	if string(file) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, string(file))
+	}
+}
+
+func TestRenderFailsForMissingValues(t *testing.T) {
+	// arrange
+	s := Sources{
+		Env: func(name string) (string, bool) { return "", false },
+	}
+
+	// act
+	_, envErr := Render([]byte("{{ env \"REGION\" }}"), s)
+	_, storeErr := Render([]byte("{{ ssm \"/app/db/pass\" }}"), s)
+
+	// assert
+	if envErr == nil || !strings.Contains(envErr.Error(), "REGION is not set") {
+		t.Errorf("expected a missing environment variable error, got %v", envErr)
+	}
+
+	if storeErr == nil || !strings.Contains(storeErr.Error(), "aws-parameter values are not available") {
+		t.Errorf("expected a missing store error, got %v", storeErr)
+	}
+}
@@ -0,0 +1,149 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a five field cron expression matching minutes, hours, days
+// of the month, months, and days of the week.
+type Cron struct {
+	minutes  []bool
+	hours    []bool
+	days     []bool
+	months   []bool
+	weekdays []bool
+
+	anyDay     bool
+	anyWeekday bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Parse reads a cron expression like "0 18 * * 5". Each field can be
+// "*", a number, a range like "1-5", a list like "1,3", or a step
+// like "*/15". Sunday is 0 or 7.
+func Parse(expr string) (Cron, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return Cron{}, fmt.Errorf("%s must have %d fields", expr, len(fields))
+	}
+
+	sets := make([][]bool, len(fields))
+	for i, f := range fields {
+		set, err := parseField(parts[i], f)
+		if err != nil {
+			return Cron{}, err
+		}
+		sets[i] = set
+	}
+
+	// Sunday can be 0 or 7.
+	sets[4][0] = sets[4][0] || sets[4][7]
+
+	return Cron{
+		minutes:    sets[0],
+		hours:      sets[1],
+		days:       sets[2],
+		months:     sets[3],
+		weekdays:   sets[4],
+		anyDay:     parts[2] == "*",
+		anyWeekday: parts[4] == "*",
+	}, nil
+}
+
+// Matches returns true when the minute of the time matches. Like cron,
+// when both days of the month and days of the week are restricted, a
+// time matching either matches.
+func (c Cron) Matches(t time.Time) bool {
+	if !c.minutes[t.Minute()] || !c.hours[t.Hour()] || !c.months[int(t.Month())] {
+		return false
+	}
+
+	day, weekday := c.days[t.Day()], c.weekdays[int(t.Weekday())]
+
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// Within returns the most recent matching minute at or before the time
+// that is less than the duration before it. It returns false when no
+// minute in the duration matches.
+func (c Cron) Within(t time.Time, d time.Duration) (time.Time, bool) {
+	minute := t.Truncate(time.Minute)
+
+	for start := minute; t.Sub(start) < d; start = start.Add(-time.Minute) {
+		if c.Matches(start) {
+			return start, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func parseField(expr string, f field) ([]bool, error) {
+	set := make([]bool, f.max+1)
+
+	for _, part := range strings.Split(expr, ",") {
+		step := 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid %s step %s", f.name, part)
+			}
+			step, part = n, part[:i]
+		}
+
+		low, high := f.min, f.max
+
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+
+			n, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %s", f.name, part)
+			}
+			low, high = n, n
+
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, fmt.Errorf("invalid %s %s", f.name, part)
+				}
+			} else if step > 1 {
+				high = f.max
+			}
+		}
+
+		if low < f.min || high > f.max || low > high {
+			return nil, fmt.Errorf("%s %s is out of range %d-%d", f.name, part, f.min, f.max)
+		}
+
+		for v := low; v <= high; v += step {
+			set[v] = true
+		}
+	}
+
+	return set, nil
+}
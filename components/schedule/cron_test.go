@@ -0,0 +1,75 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatches(t *testing.T) {
+	// arrange
+	tests := []struct {
+		expr     string
+		time     time.Time
+		expected bool
+	}{
+		{"0 18 * * 5", time.Date(2019, 3, 8, 18, 0, 0, 0, time.UTC), true},
+		{"0 18 * * 5", time.Date(2019, 3, 9, 18, 0, 0, 0, time.UTC), false},
+		{"*/15 9-17 * * 1-5", time.Date(2019, 3, 5, 9, 45, 0, 0, time.UTC), true},
+		{"*/15 9-17 * * 1-5", time.Date(2019, 3, 5, 9, 50, 0, 0, time.UTC), false},
+		{"0 0 24 12 *", time.Date(2019, 12, 24, 0, 0, 0, 0, time.UTC), true},
+		{"0 0 1 * 7", time.Date(2019, 3, 10, 0, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, test := range tests {
+		c, err := Parse(test.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		actual := c.Matches(test.time)
+
+		// assert
+		if actual != test.expected {
+			t.Errorf("\nEXPECTED: %s at %s %t \nACTUAL: %t", test.expr, test.time, test.expected, actual)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	// arrange
+	c, err := Parse("0 18 * * 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	friday := time.Date(2019, 3, 8, 18, 0, 0, 0, time.UTC)
+
+	// act
+	start, active := c.Within(friday.Add(59*time.Hour), 60*time.Hour)
+	_, after := c.Within(friday.Add(60*time.Hour), 60*time.Hour)
+
+	// assert
+	if !active || !start.Equal(friday) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s %t", friday, start, active)
+	}
+
+	if after {
+		t.Errorf("\nEXPECTED: %t \nACTUAL: %t", false, after)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	// arrange
+	exprs := []string{"* * * *", "60 * * * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "a * * * *"}
+
+	for _, expr := range exprs {
+		// act
+		_, err := Parse(expr)
+
+		// assert
+		if err == nil {
+			t.Errorf("\nEXPECTED: error for %s \nACTUAL: %v", expr, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/cipher"
+)
+
+// Bundle archives the files and encrypts the archive with a new key
+// returning the encrypted archive and the key.
+func Bundle(files map[string][]byte) ([]byte, string, error) {
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	archive := bytes.Buffer{}
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(files[name])),
+			ModTime: time.Now(),
+		}); err != nil {
+			return nil, "", err
+		}
+
+		if _, err := tw.Write(files[name]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, "", err
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, "", err
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+
+	// 24 random bytes encode to the 32 characters of an AES-256 key
+	key := base64.RawURLEncoding.EncodeToString(b)
+
+	encrypted, err := cipher.Encrypt(key, archive.Bytes())
+
+	return encrypted, key, err
+}
+
+// OpenBundle decrypts a bundle returning the files in it.
+func OpenBundle(bundle []byte, key string) (map[string][]byte, error) {
+	if len(bundle) < 16 {
+		return nil, errors.New("invalid bundle")
+	}
+
+	archive, err := cipher.Decrypt(key, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, errors.New("invalid key or bundle")
+	}
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, errors.New("invalid key or bundle")
+		}
+
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+
+		files[h.Name] = b
+	}
+}
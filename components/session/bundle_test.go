@@ -0,0 +1,33 @@
+package session
+
+import (
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	// arrange
+	files := map[string][]byte{
+		"transcript.json": []byte(`[{"type":"start"}]`),
+	}
+
+	// act
+	bundle, key, err := Bundle(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened, err := OpenBundle(bundle, key)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(opened["transcript.json"]) != `[{"type":"start"}]` {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", `[{"type":"start"}]`, opened["transcript.json"])
+	}
+
+	if _, err := OpenBundle(bundle, "00000000000000000000000000000000"); err == nil {
+		t.Error("bundle opened with the wrong key")
+	}
+}
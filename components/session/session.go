@@ -0,0 +1,350 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/local"
+)
+
+//------------------------------------------
+//- Commands are recorded as they run to a
+//- transcript in ~/.cstore/sessions. Each
+//- event is appended as a line of JSON; so,
+//- the transcript survives commands exiting
+//- part way through. Transcripts of commands
+//- that complete are removed; a transcript
+//- without an end event is a failed command.
+//------------------------------------------
+
+const (
+	dir = "sessions"
+	ext = ".jsonl"
+
+	// kept limits the transcripts of failed commands saved.
+	kept = 10
+)
+
+// Event types recorded in a transcript.
+const (
+	EventStart   = "start"
+	EventOutput  = "output"
+	EventRequest = "request"
+	EventCall    = "call"
+	EventEnd     = "end"
+)
+
+// Event is a line in a transcript.
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+
+	// start
+	Command   []string `json:"command,omitempty"`
+	Version   string   `json:"version,omitempty"`
+	GoVersion string   `json:"goVersion,omitempty"`
+	Platform  string   `json:"platform,omitempty"`
+
+	// output
+	Text string `json:"text,omitempty"`
+
+	// request
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Status int    `json:"status,omitempty"`
+
+	// call
+	Store string `json:"store,omitempty"`
+	Op    string `json:"op,omitempty"`
+
+	// request, call, and end
+	ElapsedMs int64  `json:"elapsedMs,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// builtIn patterns redact values that look like credentials even when
+// no redaction patterns are configured.
+var builtIn = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api[_-]?key|access[_-]?key)[\w-]*["']?\s*[=:]\s*["']?)[^\s"',&]+`),
+	regexp.MustCompile(`(?i)(bearer\s+)\S+`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\beyJ[\w-]+\.[\w-]+\.[\w-]+`),
+}
+
+var (
+	mu       sync.Mutex
+	file     *os.File
+	path     string
+	patterns []*regexp.Regexp
+	output   bytes.Buffer
+)
+
+// Start begins recording the command to a new transcript. Failing to
+// record never stops the command.
+func Start(args []string, version string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	sessions := local.BuildPath(dir)
+
+	if err := os.MkdirAll(sessions, 0700); err != nil {
+		return
+	}
+
+	prune(sessions)
+
+	path = filepath.Join(sessions, fmt.Sprintf("%d-%d%s", time.Now().UnixNano(), os.Getpid(), ext))
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return
+	}
+
+	file = f
+
+	record(Event{
+		Type:      EventStart,
+		Command:   redactAll(args),
+		Version:   version,
+		GoVersion: runtime.Version(),
+		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	})
+}
+
+// UsePatterns adds redaction patterns, like those in the catalog, to
+// the built-in patterns.
+func UsePatterns(p []*regexp.Regexp) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	patterns = append(patterns, p...)
+}
+
+// Call records a call to a store.
+func Call(store, op string, elapsed time.Duration, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	record(Event{Type: EventCall, Store: store, Op: op, ElapsedMs: ms(elapsed), Error: errText(err)})
+}
+
+// Finish records the end of the command. The transcript is removed
+// when the command succeeded and kept for a support bundle when it
+// failed.
+func Finish(err error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if file == nil {
+		return
+	}
+
+	if err == nil {
+		file.Close()
+		os.Remove(path)
+		file = nil
+		return
+	}
+
+	record(Event{Type: EventEnd, Error: errText(err)})
+
+	file.Close()
+	file = nil
+}
+
+// Writer records output, like messages and errors displayed to the
+// user, one line at a time.
+type Writer struct{}
+
+// Write ...
+func (w Writer) Write(p []byte) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	output.Write(p)
+
+	for {
+		line, err := output.ReadString('\n')
+		if err != nil {
+			// keep the partial line until it is finished
+			output.Reset()
+			output.WriteString(line)
+			break
+		}
+
+		if text := strings.TrimSpace(stripColor(line)); len(text) > 0 {
+			record(Event{Type: EventOutput, Text: text})
+		}
+	}
+
+	return len(p), nil
+}
+
+// record appends an event to the transcript. The caller must hold mu.
+func record(e Event) {
+	if file == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	e.Text = redact(e.Text)
+	e.URL = redact(e.URL)
+	e.Error = redact(e.Error)
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	file.Write(append(b, '\n'))
+}
+
+// Redact replaces credentials and text matching the redaction patterns.
+func Redact(text string) string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return redact(text)
+}
+
+func redact(text string) string {
+	if len(text) == 0 {
+		return text
+	}
+
+	for _, p := range builtIn {
+		if p.NumSubexp() > 0 {
+			text = p.ReplaceAllString(text, "${1}"+display.Redacted)
+		} else {
+			text = p.ReplaceAllLiteralString(text, display.Redacted)
+		}
+	}
+
+	return string(display.Redact([]byte(text), patterns))
+}
+
+func redactAll(values []string) []string {
+	redacted := []string{}
+	for _, v := range values {
+		redacted = append(redacted, redact(v))
+	}
+	return redacted
+}
+
+// Failed returns the events in the transcript of the most recent failed
+// command.
+func Failed() ([]Event, error) {
+	transcripts, err := failed(local.BuildPath(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transcripts) == 0 {
+		return nil, fmt.Errorf("no failed command has been recorded")
+	}
+
+	return read(transcripts[len(transcripts)-1])
+}
+
+// failed lists the transcripts of failed commands from oldest to newest
+// leaving out the transcript being recorded.
+func failed(sessions string) ([]string, error) {
+	files, err := ioutil.ReadDir(sessions)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, f := range files {
+		p := filepath.Join(sessions, f.Name())
+
+		if strings.HasSuffix(f.Name(), ext) && p != path {
+			paths = append(paths, p)
+		}
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		return transcriptTime(paths[i]) < transcriptTime(paths[j])
+	})
+
+	return paths, nil
+}
+
+// prune removes the oldest transcripts keeping the most recent.
+func prune(sessions string) {
+	transcripts, err := failed(sessions)
+	if err != nil {
+		return
+	}
+
+	for len(transcripts) >= kept {
+		os.Remove(transcripts[0])
+		transcripts = transcripts[1:]
+	}
+}
+
+func read(transcript string) ([]Event, error) {
+	f, err := os.Open(transcript)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	events := []Event{}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		e := Event{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// the last line is incomplete when the command was killed
+			continue
+		}
+		events = append(events, e)
+	}
+
+	return events, scanner.Err()
+}
+
+// transcriptTime sorts transcripts by when they started; the name
+// begins with the start time in nanoseconds.
+func transcriptTime(transcript string) string {
+	name := filepath.Base(transcript)
+	if i := strings.Index(name, "-"); i > 0 {
+		return fmt.Sprintf("%020s", name[:i])
+	}
+	return name
+}
+
+var colorCodes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func stripColor(text string) string {
+	return colorCodes.ReplaceAllString(text, "")
+}
+
+func ms(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
+
+func errText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
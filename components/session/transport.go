@@ -0,0 +1,39 @@
+package session
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Transport records each HTTP request sent through it. Only the method,
+// the URL without its query, the status, and the time taken are
+// recorded; headers and bodies can hold credentials and are not.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip ...
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	resp, err := t.Base.RoundTrip(req)
+
+	e := Event{
+		Type:      EventRequest,
+		Method:    req.Method,
+		URL:       fmt.Sprintf("%s://%s%s", req.URL.Scheme, req.URL.Host, req.URL.Path),
+		ElapsedMs: ms(time.Since(start)),
+		Error:     errText(err),
+	}
+
+	if resp != nil {
+		e.Status = resp.StatusCode
+	}
+
+	mu.Lock()
+	record(e)
+	mu.Unlock()
+
+	return resp, err
+}
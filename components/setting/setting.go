@@ -21,6 +21,10 @@ type Setting struct {
 	HideInput bool
 	AutoSave  bool
 
+	// Shared values are not secret and the answer given when prompted
+	// is saved in the catalog for everyone using it.
+	Shared bool
+
 	Vault contract.IVault
 }
 
@@ -47,6 +51,7 @@ func (s Setting) Get(context string, io models.IO) (string, error) {
 			DefaultValue: s.DefaultValue,
 			Description:  s.Description,
 			HideInput:    s.HideInput,
+			Shared:       s.Shared,
 		}
 
 		if env := os.Getenv(formattedKey); len(env) > 0 {
@@ -55,9 +60,19 @@ func (s Setting) Get(context string, io models.IO) (string, error) {
 			opt.DefaultValue = value
 		}
 
-		value = prompt.GetValFromUser(formattedKey, opt, io)
+		value, err = prompt.GetValFromUser(formattedKey, opt, io)
+		if err != nil {
+			return value, err
+		}
+
+		save := s.AutoSave
+		if !save {
+			if save, err = prompt.Confirm(fmt.Sprintf("Save %s preference in %s?", formattedKey, s.Vault.Name()), prompt.Warn, io); err != nil {
+				return value, err
+			}
+		}
 
-		if s.AutoSave || prompt.Confirm(fmt.Sprintf("Save %s preference in %s?", formattedKey, s.Vault.Name()), prompt.Warn, io) {
+		if save {
 			if err := s.Vault.Set(context, s.Group, s.Prop, value); err != nil {
 				return value, err
 			}
@@ -0,0 +1,673 @@
+package store
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/setting"
+	"github.com/turnerlabs/cstore/components/vault"
+)
+
+const (
+	akeylessDefaultURL  = "https://api.akeyless.io"
+	akeylessDefaultPath = "/cstore"
+
+	akeylessAccessKey = "access_key"
+	akeylessAWSIAM    = "aws_iam"
+
+	// akeylessTokenLifetime is the default lifetime of an Akeyless
+	// access token.
+	akeylessTokenLifetime = 60 * time.Minute
+)
+
+// AkeylessStore ...
+type AkeylessStore struct {
+	context string
+	url     string
+	path    string
+	token   string
+
+	accessType string
+	accessID   string
+	auth       map[string]interface{}
+	expires    time.Time
+
+	io models.IO
+}
+
+// Name ...
+func (s AkeylessStore) Name() string {
+	return "akeyless"
+}
+
+// SupportsFeature ...
+func (s AkeylessStore) SupportsFeature(feature string) bool {
+	switch feature {
+	case VersionFeature, FIPSFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFileType ...
+func (s AkeylessStore) SupportsFileType(fileType string) bool {
+	return true
+}
+
+// Description ...
+func (s AkeylessStore) Description() string {
+	return `
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/AKEYLESS.md
+`
+}
+
+// Pre ...
+func (s *AkeylessStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.context = clog.Context
+	s.io = io
+
+	url, err := (setting.Setting{
+		Description:  "Akeyless API or Gateway URL.",
+		Group:        "AKEYLESS",
+		Prop:         "URL",
+		DefaultValue: akeylessDefaultURL,
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        vault.EnvVault{},
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.url = strings.TrimRight(url, "/")
+
+	path, err := (setting.Setting{
+		Description:  "Secrets are created under this Akeyless path.",
+		Group:        "AKEYLESS",
+		Prop:         "PATH",
+		DefaultValue: clog.GetAnyDataBy("AKEYLESS_PATH", akeylessDefaultPath),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.path = "/" + strings.Trim(path, "/")
+
+	//------------------------------------------
+	//- Auth Credentials
+	//------------------------------------------
+	accessType, err := (setting.Setting{
+		Description:  fmt.Sprintf("OPTIONS\n %s \n %s", akeylessAccessKey, akeylessAWSIAM),
+		Group:        "AKEYLESS",
+		Prop:         "ACCESS_TYPE",
+		DefaultValue: akeylessAccessKey,
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        vault.EnvVault{},
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	accessID, err := (setting.Setting{
+		Group:    "AKEYLESS",
+		Prop:     "ACCESS_ID",
+		Prompt:   uo.Prompt,
+		AutoSave: true,
+		Vault:    vault.EnvVault{},
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.accessType = accessType
+	s.accessID = accessID
+
+	auth := map[string]interface{}{
+		"access-id":   accessID,
+		"access-type": accessType,
+	}
+
+	switch accessType {
+	case akeylessAccessKey:
+		accessKey, err := (setting.Setting{
+			Group:     "AKEYLESS",
+			Prop:      "ACCESS_KEY",
+			Prompt:    uo.Prompt,
+			HideInput: true,
+			AutoSave:  true,
+			Vault:     access,
+		}).Get(clog.Context, io)
+		if err != nil {
+			return err
+		}
+
+		auth["access-key"] = accessKey
+
+	case akeylessAWSIAM:
+
+	default:
+		return fmt.Errorf("unsupported Akeyless access type: %s", accessType)
+	}
+
+	s.auth = auth
+
+	return s.authenticate()
+}
+
+// authenticate exchanges the access credentials for a token. AWS IAM
+// auth signs a new STS request each time; so, it can be repeated to
+// refresh the token.
+func (s *AkeylessStore) authenticate() error {
+	if s.accessType == akeylessAWSIAM {
+		cloudID, err := awsCloudID()
+		if err != nil {
+			return err
+		}
+
+		s.auth["cloud-id"] = cloudID
+	}
+
+	output := struct {
+		Token string `json:"token"`
+	}{}
+
+	if err := s.call("auth", s.auth, &output); err != nil {
+		return err
+	}
+
+	s.token = output.Token
+	s.expires = time.Now().Add(akeylessTokenLifetime)
+
+	return nil
+}
+
+// Push ...
+func (s AkeylessStore) Push(file *catalog.File, fileData []byte, version string) error {
+
+	if len(fileData) == 0 {
+		return errors.New("empty file")
+	}
+
+	if !file.SupportsConfig() {
+		name := s.secretPath(file.Path, version)
+
+		_, found, err := s.describe(name)
+		if err != nil {
+			return err
+		}
+
+		return s.save(name, string(fileData), found)
+	}
+
+	//------------------------------------------
+	//- Push configuration
+	//------------------------------------------
+	newParams := gotenv.Parse(bytes.NewReader(fileData))
+	if len(newParams) == 0 {
+		return errors.New("failed to parse environment variables")
+	}
+
+	base := s.secretPath(file.Path, version)
+
+	storedItems, err := s.list(base)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.values(storedItems)
+	if err != nil {
+		return err
+	}
+
+	for name, value := range newParams {
+		secret := fmt.Sprintf("%s/%s", base, name)
+
+		current, found := stored[secret]
+		if found && current == value {
+			continue
+		}
+
+		if err := s.save(secret, value, found); err != nil {
+			fmt.Fprintf(s.io.UserOutput, "secret: %s", secret)
+			return err
+		}
+	}
+
+	//------------------------------------------
+	//- Delete removed secrets
+	//------------------------------------------
+	for _, item := range storedItems {
+		if _, found := newParams[strings.TrimPrefix(item.Name, base+"/")]; !found {
+			if err := s.call("delete-item", map[string]interface{}{"name": item.Name}, nil); err != nil {
+				fmt.Fprintf(s.io.UserOutput, "secret: %s", item.Name)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Pull ...
+func (s AkeylessStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+
+	if !file.SupportsConfig() {
+		name := s.secretPath(file.Path, version)
+
+		item, found, err := s.describe(name)
+		if err != nil {
+			return []byte{}, contract.Attributes{}, err
+		}
+
+		if !found {
+			return []byte{}, contract.Attributes{}, errors.New("secret not found, verify Akeyless access")
+		}
+
+		values, err := s.values([]akeylessItem{item})
+		if err != nil {
+			return []byte{}, contract.Attributes{}, err
+		}
+
+		return []byte(values[name]), contract.Attributes{
+			LastModified: item.ModificationDate,
+		}, nil
+	}
+
+	base := s.secretPath(file.Path, version)
+
+	storedItems, err := s.list(base)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	if len(storedItems) == 0 {
+		return []byte{}, contract.Attributes{}, errors.New("secrets not found, verify Akeyless access")
+	}
+
+	values, err := s.values(storedItems)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	var buffer bytes.Buffer
+
+	for _, item := range storedItems {
+		buffer.WriteString(fmt.Sprintf("%s=%s\n", strings.TrimPrefix(item.Name, base+"/"), values[item.Name]))
+	}
+
+	return buffer.Bytes(), contract.Attributes{
+		LastModified: lastModifiedItem(storedItems),
+	}, nil
+}
+
+// Purge ...
+func (s AkeylessStore) Purge(file *catalog.File, version string) error {
+
+	items := []akeylessItem{}
+
+	if file.SupportsConfig() {
+		stored, err := s.list(s.secretPath(file.Path, version))
+		if err != nil {
+			return err
+		}
+
+		items = stored
+	} else {
+		item, found, err := s.describe(s.secretPath(file.Path, version))
+		if err != nil {
+			return err
+		}
+
+		if found {
+			items = append(items, item)
+		}
+	}
+
+	msg := ""
+	for _, item := range items {
+		msg = fmt.Sprintf("%s  - %s\n", msg, item.Name)
+	}
+	msg = fmt.Sprintf("%s \n  Delete secrets?", msg)
+
+	confirmed, err := prompt.Confirm(msg, prompt.Danger, s.io)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		return errors.New("user aborted")
+	}
+
+	names := []string{}
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+
+	return purgeKeys(names, func(name string) error {
+		return s.call("delete-item", map[string]interface{}{"name": name}, nil)
+	}, s.io)
+}
+
+// Changed ...
+func (s AkeylessStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+
+	if !file.SupportsConfig() {
+		item, found, err := s.describe(s.secretPath(file.Path, version))
+		if err != nil || !found {
+			return time.Time{}, err
+		}
+
+		return item.ModificationDate, nil
+	}
+
+	items, err := s.list(s.secretPath(file.Path, version))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return lastModifiedItem(items), nil
+}
+
+// ETag ...
+func (s AkeylessStore) ETag(file *catalog.File, version string) (string, error) {
+
+	items := []akeylessItem{}
+
+	if file.SupportsConfig() {
+		stored, err := s.list(s.secretPath(file.Path, version))
+		if err != nil {
+			return "", err
+		}
+
+		items = stored
+	} else {
+		item, found, err := s.describe(s.secretPath(file.Path, version))
+		if err != nil || !found {
+			return "", err
+		}
+
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		return "", nil
+	}
+
+	parts := []string{}
+	for _, item := range items {
+		parts = append(parts, fmt.Sprintf("%s:%s", item.Name, item.ModificationDate.Format(time.RFC3339Nano)))
+	}
+
+	return etagOf(parts), nil
+}
+
+// KeysModified ...
+func (s AkeylessStore) KeysModified(file *catalog.File, version string) (map[string]time.Time, error) {
+
+	if !file.SupportsConfig() {
+		return nil, fmt.Errorf("store does not support keys for file type: %s", file.Type)
+	}
+
+	base := s.secretPath(file.Path, version)
+
+	items, err := s.list(base)
+	if err != nil {
+		return nil, err
+	}
+
+	modified := map[string]time.Time{}
+	for _, item := range items {
+		modified[strings.TrimPrefix(item.Name, base+"/")] = item.ModificationDate
+	}
+
+	return modified, nil
+}
+
+// Encryption ...
+func (s AkeylessStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	location, err := s.Locate(file, "", version)
+	return contract.Encryption{Mechanism: contract.EncryptionKMS, Detail: location.Encryption}, nil, err
+}
+
+// Locate ...
+func (s AkeylessStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	name := s.secretPath(file.Path, version)
+	if file.SupportsConfig() {
+		name = fmt.Sprintf("%s/*", name)
+
+		if len(key) > 0 {
+			name = fmt.Sprintf("%s/%s", s.secretPath(file.Path, version), key)
+		}
+	}
+
+	return contract.Location{
+		Remote:      fmt.Sprintf("%s %s", s.url, name),
+		Credentials: fmt.Sprintf("%s auth for access id %s", s.accessType, s.accessID),
+		Encryption:  "Akeyless protection key for the secret",
+	}, nil
+}
+
+// Expires ...
+func (s AkeylessStore) Expires() time.Time {
+	return s.expires
+}
+
+// Refresh ...
+func (s *AkeylessStore) Refresh() error {
+	return s.authenticate()
+}
+
+func init() {
+	s := new(AkeylessStore)
+	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: "AKEYLESS_PATH"},
+	})
+}
+
+//------------------------------------------
+//- Akeyless API helpers.
+//------------------------------------------
+type akeylessItem struct {
+	Name             string    `json:"item_name"`
+	ModificationDate time.Time `json:"modification_date"`
+}
+
+func (s AkeylessStore) secretPath(path, version string) string {
+	if len(version) > 0 {
+		return fmt.Sprintf("%s/%s/%s/%s", s.path, s.context, version, path)
+	}
+	return fmt.Sprintf("%s/%s/%s", s.path, s.context, path)
+}
+
+func (s AkeylessStore) save(name, value string, exists bool) error {
+	input := map[string]interface{}{
+		"name":  name,
+		"value": value,
+	}
+
+	if exists {
+		return s.call("update-secret-val", input, nil)
+	}
+
+	return s.call("create-secret", input, nil)
+}
+
+func (s AkeylessStore) describe(name string) (akeylessItem, bool, error) {
+	item := akeylessItem{}
+
+	if err := s.call("describe-item", map[string]interface{}{"name": name}, &item); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "not found") {
+			return item, false, nil
+		}
+		return item, false, err
+	}
+
+	return item, true, nil
+}
+
+func (s AkeylessStore) list(base string) ([]akeylessItem, error) {
+	items := []akeylessItem{}
+	nextPage := ""
+
+	for {
+		input := map[string]interface{}{
+			"path": base,
+			"type": []string{"static-secret"},
+		}
+
+		if len(nextPage) > 0 {
+			input["pagination-token"] = nextPage
+		}
+
+		output := struct {
+			Items    []akeylessItem `json:"items"`
+			NextPage string         `json:"next_page"`
+		}{}
+
+		if err := s.call("list-items", input, &output); err != nil {
+			return nil, err
+		}
+
+		for _, item := range output.Items {
+			if strings.HasPrefix(item.Name, base+"/") {
+				items = append(items, item)
+			}
+		}
+
+		if len(output.NextPage) == 0 {
+			break
+		}
+
+		nextPage = output.NextPage
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items, nil
+}
+
+func (s AkeylessStore) values(items []akeylessItem) (map[string]string, error) {
+	values := map[string]string{}
+
+	if len(items) == 0 {
+		return values, nil
+	}
+
+	names := []string{}
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+
+	err := s.call("get-secret-value", map[string]interface{}{"names": names}, &values)
+
+	return values, err
+}
+
+func (s AkeylessStore) call(command string, input map[string]interface{}, output interface{}) error {
+	if len(s.token) > 0 {
+		input["token"] = s.token
+	}
+
+	b, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(fmt.Sprintf("%s/%s", s.url, command), "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("akeyless %s: %s", command, strings.TrimSpace(string(body)))
+	}
+
+	if output == nil {
+		return nil
+	}
+
+	return json.Unmarshal(body, output)
+}
+
+func lastModifiedItem(items []akeylessItem) time.Time {
+	mostRecentlyModified := time.Time{}
+	for _, item := range items {
+		if mostRecentlyModified.Before(item.ModificationDate) {
+			mostRecentlyModified = item.ModificationDate
+		}
+	}
+
+	return mostRecentlyModified
+}
+
+// awsCloudID builds a signed STS GetCallerIdentity request that
+// Akeyless uses to verify the caller's AWS IAM identity.
+func awsCloudID() (string, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return "", err
+	}
+
+	req, _ := sts.New(sess).GetCallerIdentityRequest(nil)
+	if err := req.Sign(); err != nil {
+		return "", err
+	}
+
+	headers, err := json.Marshal(req.HTTPRequest.Header)
+	if err != nil {
+		return "", err
+	}
+
+	body := []byte{}
+	if req.HTTPRequest.Body != nil {
+		if body, err = ioutil.ReadAll(req.HTTPRequest.Body); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := json.Marshal(map[string]string{
+		"sts_request_method":  req.HTTPRequest.Method,
+		"sts_request_url":     base64.StdEncoding.EncodeToString([]byte(req.HTTPRequest.URL.String())),
+		"sts_request_body":    base64.StdEncoding.EncodeToString(body),
+		"sts_request_headers": base64.StdEncoding.EncodeToString(headers),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
@@ -1,5 +1,22 @@
 package store
 
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/metrics"
+)
+
 const (
 	awsRegion          = "AWS_REGION"
 	awsProfile         = "AWS_PROFILE"
@@ -9,3 +26,171 @@ const (
 	awsDefaultRegion  = "us-east-1"
 	awsDefaultProfile = "default"
 )
+
+// awsCredentials describes the credentials the AWS SDK will use based
+// on the environment.
+func awsCredentials() string {
+	region := os.Getenv(awsRegion)
+	if len(region) == 0 {
+		region = awsDefaultRegion
+	}
+
+	if len(os.Getenv(awsAccessKeyID)) > 0 {
+		return fmt.Sprintf("access key %s (%s) in %s", os.Getenv(awsAccessKeyID), awsAccessKeyID, region)
+	}
+
+	if profile := os.Getenv(awsProfile); len(profile) > 0 {
+		return fmt.Sprintf("profile %s (%s) in %s", profile, awsProfile, region)
+	}
+
+	return fmt.Sprintf("default AWS credential chain in %s", region)
+}
+
+// awsExpires returns when the session credentials expire or time.Time{}
+// when they do not, like static access keys.
+func awsExpires(sess *session.Session) time.Time {
+	if sess == nil || sess.Config == nil || sess.Config.Credentials == nil {
+		return time.Time{}
+	}
+
+	expires, err := sess.Config.Credentials.ExpiresAt()
+	if err != nil {
+		return time.Time{}
+	}
+
+	return expires
+}
+
+// awsRefresh forces the session credentials to be retrieved again, like
+// assuming a role for a new STS session.
+func awsRefresh(sess *session.Session) error {
+	if sess == nil || sess.Config == nil || sess.Config.Credentials == nil {
+		return nil
+	}
+
+	sess.Config.Credentials.Expire()
+
+	_, err := sess.Config.Credentials.Get()
+	return err
+}
+
+// awsMeasure records the retries and throttling the AWS SDK handles
+// internally; so, they can be reported with the store metrics.
+func awsMeasure(sess *session.Session, storeName string) {
+	sess.Handlers.Retry.PushBack(func(r *request.Request) {
+		if request.IsErrorThrottle(r.Error) {
+			metrics.Throttled(storeName)
+		}
+	})
+
+	sess.Handlers.Complete.PushBack(func(r *request.Request) {
+		if r.RetryCount > 0 {
+			metrics.Retried(storeName, r.RetryCount)
+		}
+	})
+}
+
+// awsDeniedCodes are the error codes AWS services return when the
+// credentials are not authorized.
+var awsDeniedCodes = map[string]bool{
+	"AccessDenied":          true,
+	"AccessDeniedException": true,
+	"Forbidden":             true,
+	"UnauthorizedOperation": true,
+}
+
+// awsActions maps operations to the IAM actions authorizing them when
+// the names differ.
+var awsActions = map[string]string{
+	"s3:HeadObject":         "s3:GetObject",
+	"s3:ListObjectsV2":      "s3:ListBucket",
+	"s3:ListObjectVersions": "s3:ListBucketVersions",
+}
+
+// awsExplainDenied replaces access denied errors with errors naming the
+// IAM action, the resource ARN, and the principal making the request.
+func awsExplainDenied(sess *session.Session) {
+	var once sync.Once
+	principal := ""
+
+	sess.Handlers.Complete.PushBack(func(r *request.Request) {
+		aerr, ok := r.Error.(awserr.Error)
+		if !ok || !awsDeniedCodes[aerr.Code()] || r.Operation == nil || r.Operation.Name == "GetCallerIdentity" {
+			return
+		}
+
+		once.Do(func() {
+			if identity, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{}); err == nil {
+				principal = aws.StringValue(identity.Arn)
+			}
+		})
+
+		action := fmt.Sprintf("%s:%s", r.ClientInfo.ServiceName, r.Operation.Name)
+		if mapped, found := awsActions[action]; found {
+			action = mapped
+		}
+
+		r.Error = contract.AccessDeniedError{
+			Action:    action,
+			Resource:  awsResource(r, principal),
+			Principal: principal,
+			Err:       r.Error,
+		}
+	})
+}
+
+// awsResource returns the ARN of the resource a request acted on based
+// on the request parameters.
+func awsResource(r *request.Request, principal string) string {
+	params := reflect.Indirect(reflect.ValueOf(r.Params))
+	if params.Kind() != reflect.Struct {
+		return "*"
+	}
+
+	param := func(name string) string {
+		f := params.FieldByName(name)
+		if f.IsValid() && f.Kind() == reflect.Ptr && !f.IsNil() && f.Elem().Kind() == reflect.String {
+			return f.Elem().String()
+		}
+		return ""
+	}
+
+	region, account := aws.StringValue(r.Config.Region), "*"
+	if parts := strings.Split(principal, ":"); len(parts) > 4 {
+		account = parts[4]
+	}
+
+	switch r.ClientInfo.ServiceName {
+	case "s3":
+		if key := param("Key"); len(key) > 0 {
+			return fmt.Sprintf("arn:aws:s3:::%s/%s", param("Bucket"), key)
+		}
+		return fmt.Sprintf("arn:aws:s3:::%s", param("Bucket"))
+
+	case "ssm":
+		name := param("Name")
+		if len(name) == 0 {
+			name = param("Path")
+		}
+		if len(name) == 0 {
+			return "*"
+		}
+		return fmt.Sprintf("arn:aws:ssm:%s:%s:parameter/%s", region, account, strings.TrimPrefix(name, "/"))
+
+	case "kms":
+		keyID := param("KeyId")
+		switch {
+		case len(keyID) == 0:
+			return "*"
+		case strings.HasPrefix(keyID, "arn:"):
+			return keyID
+		case strings.HasPrefix(keyID, "alias/"):
+			return fmt.Sprintf("arn:aws:kms:%s:%s:%s", region, account, keyID)
+		default:
+			return fmt.Sprintf("arn:aws:kms:%s:%s:key/%s", region, account, keyID)
+		}
+
+	default:
+		return "*"
+	}
+}
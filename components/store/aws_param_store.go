@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,6 +31,9 @@ const (
 	cmdRefFormat = "refs"
 
 	defaultKMSKey = "aws/ssm"
+
+	// ssmMaxResults is the largest page DescribeParameters returns.
+	ssmMaxResults = 50
 )
 
 // AWSParameterStore ...
@@ -55,7 +59,7 @@ func (s AWSParameterStore) Name() string {
 // SupportsFeature ...
 func (s AWSParameterStore) SupportsFeature(feature string) bool {
 	switch feature {
-	case VersionFeature:
+	case VersionFeature, FIPSFeature:
 		return true
 	default:
 		return false
@@ -72,6 +76,12 @@ func (s AWSParameterStore) SupportsFileType(fileType string) bool {
 	}
 }
 
+// BlobLimits ...
+func (s AWSParameterStore) BlobLimits() (int, int) {
+	// standard parameters hold up to 4 KB
+	return 4096, 0
+}
+
 // Description ...
 func (s AWSParameterStore) Description() string {
 	return `
@@ -89,22 +99,30 @@ func (s *AWSParameterStore) Pre(clog catalog.Catalog, file *catalog.File, access
 	s.credentialType = autoDetect
 	s.encryptionType = getEncryptionType(*file)
 
-	(setting.Setting{
+	if _, err := (setting.Setting{
 		Group:        "AWS",
 		Prop:         "REGION",
 		Prompt:       uo.Prompt,
 		AutoSave:     true,
 		DefaultValue: awsDefaultRegion,
 		Vault:        vault.EnvVault{},
-	}).Get(clog.Context, io)
+		Shared:       true,
+	}).Get(clog.Context, io); err != nil {
+		return err
+	}
 
 	//------------------------------------------
 	//- Auth Credentials
 	//------------------------------------------
 	if uo.Prompt {
-		s.credentialType = strings.ToLower(prompt.GetValFromUser("Authentication", prompt.Options{
+		credentialType, err := prompt.GetValFromUser("Authentication", prompt.Options{
 			Description:  "OPTIONS\n (P)rofile \n (U)ser",
-			DefaultValue: "P"}, io))
+			DefaultValue: "P"}, io)
+		if err != nil {
+			return err
+		}
+
+		s.credentialType = strings.ToLower(credentialType)
 	}
 
 	switch s.credentialType {
@@ -112,33 +130,39 @@ func (s *AWSParameterStore) Pre(clog catalog.Catalog, file *catalog.File, access
 		os.Unsetenv(awsSecretAccessKey)
 		os.Unsetenv(awsAccessKeyID)
 
-		(setting.Setting{
+		if _, err := (setting.Setting{
 			Group:        "AWS",
 			Prop:         "PROFILE",
 			DefaultValue: os.Getenv(awsProfile),
 			Prompt:       uo.Prompt,
 			AutoSave:     true,
 			Vault:        vault.EnvVault{},
-		}).Get(clog.Context, io)
+		}).Get(clog.Context, io); err != nil {
+			return err
+		}
 
 	case cTypeUser:
 		os.Unsetenv(awsProfile)
 
-		(setting.Setting{
+		if _, err := (setting.Setting{
 			Group:    "AWS",
 			Prop:     "ACCESS_KEY_ID",
 			Prompt:   uo.Prompt,
 			AutoSave: true,
 			Vault:    access,
-		}).Get(clog.Context, io)
+		}).Get(clog.Context, io); err != nil {
+			return err
+		}
 
-		(setting.Setting{
+		if _, err := (setting.Setting{
 			Group:    "AWS",
 			Prop:     "SECRET_ACCESS_KEY",
 			Prompt:   uo.Prompt,
 			AutoSave: true,
 			Vault:    access,
-		}).Get(clog.Context, io)
+		}).Get(clog.Context, io); err != nil {
+			return err
+		}
 	}
 
 	//------------------------------------------
@@ -152,6 +176,7 @@ func (s *AWSParameterStore) Pre(clog catalog.Catalog, file *catalog.File, access
 		Prompt:       uo.Prompt,
 		AutoSave:     false,
 		Vault:        file,
+		Shared:       true,
 	}
 
 	//------------------------------------------
@@ -162,6 +187,9 @@ func (s *AWSParameterStore) Pre(clog catalog.Catalog, file *catalog.File, access
 		return err
 	}
 
+	awsMeasure(sess, s.Name())
+	awsExplainDenied(sess)
+
 	s.Session = sess
 
 	return err
@@ -169,6 +197,17 @@ func (s *AWSParameterStore) Pre(clog catalog.Catalog, file *catalog.File, access
 
 // Push ...
 func (s AWSParameterStore) Push(file *catalog.File, fileData []byte, version string) error {
+	return s.push(file, fileData, version, nil)
+}
+
+// Resume ...
+func (s AWSParameterStore) Resume(file *catalog.File, fileData []byte, version string, keys []string) error {
+	return s.push(file, fileData, version, keys)
+}
+
+// push saves the file's keys. When resuming, only the listed keys are
+// pushed without comparing them to the stored keys.
+func (s AWSParameterStore) push(file *catalog.File, fileData []byte, version string, resumeKeys []string) error {
 
 	if !file.SupportsConfig() {
 		return fmt.Errorf("store does not support file type: %s", file.Type)
@@ -210,38 +249,64 @@ func (s AWSParameterStore) Push(file *catalog.File, fileData []byte, version str
 
 	svc := ssm.New(s.Session)
 
-	storedParams, err := getStoredParams(s.context, file.Path, version, svc)
+	var storedParams []param
+	var err error
+
+	if resumeKeys == nil {
+		storedParams, err = getStoredParams(s.context, file.Path, version, svc)
+	} else {
+		storedParams, err = getStoredNames(s.context, file.Path, version, svc)
+	}
 	if err != nil {
 		return err
 	}
 
+	pushed := map[string]bool{}
+	for name := range newParams {
+		if resumeKeys != nil && !isKeyIn(name, resumeKeys) {
+			pushed[name] = true
+		}
+	}
+
 	for name, value := range newParams {
+		if pushed[name] {
+			continue
+		}
+
 		remoteKey := buildRemoteKey(s.context, file.Path, name, version)
 
+		pType, keyID := paramType(file, name, input)
+
 		newParam := param{
 			name:  remoteKey,
 			value: value,
-			pType: *input.Type,
+			pType: pType,
 		}
 
-		if input.KeyId != nil {
-			newParam.keyID = *input.KeyId
+		if keyID != nil {
+			newParam.keyID = *keyID
 		}
 
 		if noChange(newParam, storedParams) {
+			pushed[name] = true
 			continue
 		}
 
 		v := formatValue(newParam.value)
 
-		input.Name = &newParam.name
-		input.Value = &v
+		put := input
+		put.Name = &newParam.name
+		put.Value = &v
+		put.Type = aws.String(pType)
+		put.KeyId = keyID
 
-		_, err := svc.PutParameter(&input)
+		_, err := svc.PutParameter(&put)
 		if err != nil {
 			fmt.Fprintf(s.io.UserOutput, "parameter: %s", remoteKey)
-			return err
+			return contract.PushError{Remaining: remainingKeys(newParams, pushed), Err: err}
 		}
+
+		pushed[name] = true
 	}
 
 	//------------------------------------------
@@ -296,6 +361,142 @@ func (s AWSParameterStore) Pull(file *catalog.File, version string) ([]byte, con
 	}, nil
 }
 
+// Value ...
+func (s AWSParameterStore) Value(name string) (string, error) {
+	out, err := ssm.New(s.Session).GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(out.Parameter.Value), nil
+}
+
+// PullAsOf ...
+func (s AWSParameterStore) PullAsOf(file *catalog.File, version string, asOf time.Time) ([]byte, contract.Attributes, error) {
+
+	svc := ssm.New(s.Session)
+
+	storedParams, err := listStoredParams(svc, buildRemotePath(s.context, file.Path, version))
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	//------------------------------------------
+	//- Use the last value of each key saved at
+	//- or before the requested time.
+	//------------------------------------------
+	params := []param{}
+	for _, sp := range storedParams {
+		p, found, err := paramAsOf(*sp.Name, asOf, svc)
+		if err != nil {
+			return []byte{}, contract.Attributes{}, err
+		}
+
+		if found {
+			params = append(params, p)
+		}
+	}
+
+	if len(params) == 0 {
+		return []byte{}, contract.Attributes{}, fmt.Errorf("parameters did not exist at %s", asOf.Format(time.RFC3339))
+	}
+
+	var buffer bytes.Buffer
+
+	for key, value := range toMap(params) {
+		buffer.WriteString(fmt.Sprintf("%s=%s\n", key[strings.LastIndex(key, "/")+1:], value))
+	}
+
+	return buffer.Bytes(), contract.Attributes{
+		LastModified: lastModified(params),
+	}, nil
+}
+
+// KeyHistory ...
+func (s AWSParameterStore) KeyHistory(file *catalog.File, key, version string) ([]contract.KeyChange, error) {
+
+	svc := ssm.New(s.Session)
+
+	changes := []contract.KeyChange{}
+
+	err := svc.GetParameterHistoryPages(&ssm.GetParameterHistoryInput{
+		Name:           aws.String(buildRemoteKey(s.context, file.Path, key, version)),
+		WithDecryption: aws.Bool(false),
+	}, func(page *ssm.GetParameterHistoryOutput, lastPage bool) bool {
+		for _, h := range page.Parameters {
+			change := contract.KeyChange{
+				Revision: fmt.Sprint(aws.Int64Value(h.Version)),
+				Modified: aws.TimeValue(h.LastModifiedDate),
+			}
+
+			if h.LastModifiedUser != nil {
+				change.By = *h.LastModifiedUser
+			}
+
+			changes = append(changes, change)
+		}
+
+		return true
+	})
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Modified.Before(changes[j].Modified)
+	})
+
+	return changes, err
+}
+
+// Revisions ...
+func (s AWSParameterStore) Revisions(file *catalog.File, version string) ([]contract.Revision, error) {
+
+	svc := ssm.New(s.Session)
+
+	storedParams, err := listStoredParams(svc, buildRemotePath(s.context, file.Path, version))
+	if err != nil {
+		return nil, err
+	}
+
+	//------------------------------------------
+	//- Group the changes to every key into the
+	//- pushes that saved them.
+	//------------------------------------------
+	changes := []contract.KeyChange{}
+	for _, sp := range storedParams {
+		err := svc.GetParameterHistoryPages(&ssm.GetParameterHistoryInput{
+			Name:           sp.Name,
+			WithDecryption: aws.Bool(false),
+		}, func(page *ssm.GetParameterHistoryOutput, lastPage bool) bool {
+			for _, h := range page.Parameters {
+				changes = append(changes, contract.KeyChange{
+					Revision: fmt.Sprint(aws.Int64Value(h.Version)),
+					Modified: aws.TimeValue(h.LastModifiedDate),
+					By:       aws.StringValue(h.LastModifiedUser),
+				})
+			}
+
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keyRevisions(changes, revisionWindow), nil
+}
+
+// PullRevision ...
+func (s AWSParameterStore) PullRevision(file *catalog.File, version, revision string) ([]byte, contract.Attributes, error) {
+	asOf, err := revisionTime(revision)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	return s.PullAsOf(file, version, asOf)
+}
+
 // Purge ...
 func (s AWSParameterStore) Purge(file *catalog.File, version string) error {
 
@@ -312,20 +513,26 @@ func (s AWSParameterStore) Purge(file *catalog.File, version string) error {
 	}
 	msg = fmt.Sprintf("%s \n  Delete parameters?", msg)
 
-	if !prompt.Confirm(msg, prompt.Danger, s.io) {
+	confirmed, err := prompt.Confirm(msg, prompt.Danger, s.io)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
 		return errors.New("user aborted")
 	}
 
+	names := []string{}
 	for _, p := range storedParams {
-		if _, err := svc.DeleteParameter(&ssm.DeleteParameterInput{
-			Name: aws.String(p.name),
-		}); err != nil {
-			fmt.Fprintf(s.io.UserOutput, "parameter: %s", p.name)
-			return err
-		}
+		names = append(names, p.name)
 	}
 
-	return nil
+	return purgeKeys(names, func(name string) error {
+		_, err := svc.DeleteParameter(&ssm.DeleteParameterInput{
+			Name: aws.String(name),
+		})
+		return err
+	}, s.io)
 }
 
 // Changed ...
@@ -381,6 +588,118 @@ func (s AWSParameterStore) Changed(file *catalog.File, fileData []byte, version
 	return lastModified(changedParams), nil
 }
 
+// ETag ...
+func (s AWSParameterStore) ETag(file *catalog.File, version string) (string, error) {
+
+	storedParamData, err := listStoredParams(ssm.New(s.Session), buildRemotePath(s.context, file.Path, version))
+	if err != nil {
+		return "", err
+	}
+
+	if len(storedParamData) == 0 {
+		return "", nil
+	}
+
+	parts := []string{}
+	for _, p := range storedParamData {
+		parts = append(parts, fmt.Sprintf("%s:%d", aws.StringValue(p.Name), aws.Int64Value(p.Version)))
+	}
+	sort.Strings(parts)
+
+	return etagOf(parts), nil
+}
+
+// KeysModified ...
+func (s AWSParameterStore) KeysModified(file *catalog.File, version string) (map[string]time.Time, error) {
+
+	storedParamData, err := listStoredParams(ssm.New(s.Session), buildRemotePath(s.context, file.Path, version))
+	if err != nil {
+		return nil, err
+	}
+
+	modified := map[string]time.Time{}
+	for _, p := range storedParamData {
+		name := aws.StringValue(p.Name)
+		modified[name[strings.LastIndex(name, "/")+1:]] = aws.TimeValue(p.LastModifiedDate)
+	}
+
+	return modified, nil
+}
+
+// Encryption ...
+func (s AWSParameterStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+
+	storedParamData, err := listStoredParams(ssm.New(s.Session), buildRemotePath(s.context, file.Path, version))
+	if err != nil {
+		return contract.Encryption{}, nil, err
+	}
+
+	keys := map[string]contract.Encryption{}
+	for _, p := range storedParamData {
+		name := aws.StringValue(p.Name)
+
+		e := contract.Encryption{Mechanism: contract.EncryptionNone, Detail: ssm.ParameterTypeString}
+		if aws.StringValue(p.Type) == ssm.ParameterTypeSecureString {
+			e = contract.Encryption{Mechanism: contract.EncryptionKMS, Detail: aws.StringValue(p.KeyId)}
+		}
+
+		keys[name[strings.LastIndex(name, "/")+1:]] = e
+	}
+
+	return contract.Encryption{Mechanism: contract.EncryptionKMS, Detail: "SecureString parameters"}, keys, nil
+}
+
+// Expires ...
+func (s AWSParameterStore) Expires() time.Time {
+	return awsExpires(s.Session)
+}
+
+// Refresh ...
+func (s AWSParameterStore) Refresh() error {
+	return awsRefresh(s.Session)
+}
+
+// Locate ...
+func (s AWSParameterStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	location := contract.Location{
+		Remote:      fmt.Sprintf("%s/*", buildRemotePath(s.context, file.Path, version)),
+		Credentials: awsCredentials(),
+		Encryption:  fmt.Sprintf("KMS key %s", defaultKMSKey),
+	}
+
+	if len(key) > 0 {
+		location.Remote = buildRemoteKey(s.context, file.Path, key, version)
+	}
+
+	if kms := file.Data["AWS_STORE_KMS_KEY_ID"]; len(kms) > 0 {
+		location.Encryption = fmt.Sprintf("KMS key %s", kms)
+	}
+
+	return location, nil
+}
+
+// Discover ...
+func (s AWSParameterStore) Discover(prefix string) ([]contract.Discovered, error) {
+
+	if len(prefix) == 0 {
+		prefix = fmt.Sprintf("/%s/", s.context)
+	}
+
+	found := discovery{}
+
+	err := eachStoredParam(ssm.New(s.Session), prefix, func(p *ssm.ParameterMetadata) {
+		name := aws.StringValue(p.Name)
+
+		found.add(name[:strings.LastIndex(name, "/")+1], 1, aws.TimeValue(p.LastModifiedDate))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return found.files(), nil
+}
+
 func lastModified(params []param) time.Time {
 	mostRecentlyModified := time.Time{}
 	for _, sp := range params {
@@ -392,39 +711,104 @@ func lastModified(params []param) time.Time {
 	return mostRecentlyModified
 }
 
-func listStoredParams(svc *ssm.SSM, startsWith string) ([]*ssm.ParameterMetadata, error) {
-	return describeParams(svc, startsWith, "", []*ssm.ParameterMetadata{})
+// paramAsOf returns the last value of a parameter saved at or before
+// the time and false when the parameter did not exist yet.
+func paramAsOf(name string, asOf time.Time, svc *ssm.SSM) (param, bool, error) {
+	p, found := param{}, false
+
+	err := svc.GetParameterHistoryPages(&ssm.GetParameterHistoryInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	}, func(page *ssm.GetParameterHistoryOutput, lastPage bool) bool {
+		for _, h := range page.Parameters {
+			if h.LastModifiedDate.After(asOf) || (found && !h.LastModifiedDate.After(p.lastModified)) {
+				continue
+			}
+
+			p, found = param{
+				name:         name,
+				value:        unformatValue(*h.Value),
+				pType:        *h.Type,
+				lastModified: *h.LastModifiedDate,
+			}, true
+		}
+
+		return true
+	})
+
+	return p, found, err
 }
 
-func describeParams(svc *ssm.SSM, startsWith string, nextToken string, params []*ssm.ParameterMetadata) ([]*ssm.ParameterMetadata, error) {
-	filters := []*ssm.ParameterStringFilter{
-		&ssm.ParameterStringFilter{
-			Key:    aws.String(ssm.ParametersFilterKeyName),
-			Option: aws.String("BeginsWith"),
-			Values: aws.StringSlice([]string{startsWith}),
-		},
+// getStoredNames returns the names of previously pushed params without
+// their values.
+func getStoredNames(context, path, version string, svc *ssm.SSM) ([]param, error) {
+	storedParamData, err := listStoredParams(svc, buildRemotePath(context, path, version))
+	if err != nil {
+		return nil, err
 	}
 
-	input := &ssm.DescribeParametersInput{
-		ParameterFilters: filters,
+	params := []param{}
+	for _, p := range storedParamData {
+		params = append(params, param{name: *p.Name})
 	}
 
-	if len(nextToken) > 0 {
-		input.SetNextToken(nextToken)
+	return params, nil
+}
+
+// remainingKeys returns the sorted keys that have not been pushed.
+func remainingKeys(params gotenv.Env, pushed map[string]bool) []string {
+	keys := []string{}
+	for name := range params {
+		if !pushed[name] {
+			keys = append(keys, name)
+		}
 	}
 
-	output, err := svc.DescribeParameters(input)
-	if err != nil {
-		return nil, err
+	sort.Strings(keys)
+
+	return keys
+}
+
+func isKeyIn(key string, keys []string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
 	}
+	return false
+}
+
+func listStoredParams(svc *ssm.SSM, startsWith string) ([]*ssm.ParameterMetadata, error) {
+	params := []*ssm.ParameterMetadata{}
 
-	params = append(params, output.Parameters...)
+	err := eachStoredParam(svc, startsWith, func(p *ssm.ParameterMetadata) {
+		params = append(params, p)
+	})
 
-	if output.NextToken == nil || len(*output.NextToken) == 0 {
-		return params, nil
+	return params, err
+}
+
+// eachStoredParam calls fn for every parameter starting with the prefix
+// as each page is read; so, thousands of parameters are neither
+// truncated nor held in memory by callers that do not need them all.
+func eachStoredParam(svc *ssm.SSM, startsWith string, fn func(*ssm.ParameterMetadata)) error {
+	input := &ssm.DescribeParametersInput{
+		ParameterFilters: []*ssm.ParameterStringFilter{
+			&ssm.ParameterStringFilter{
+				Key:    aws.String(ssm.ParametersFilterKeyName),
+				Option: aws.String("BeginsWith"),
+				Values: aws.StringSlice([]string{startsWith}),
+			},
+		},
+		MaxResults: aws.Int64(ssmMaxResults),
 	}
 
-	return describeParams(svc, startsWith, *output.NextToken, params)
+	return svc.DescribeParametersPages(input, func(page *ssm.DescribeParametersOutput, last bool) bool {
+		for _, p := range page.Parameters {
+			fn(p)
+		}
+		return true
+	})
 }
 
 func formatValue(value string) string {
@@ -516,6 +900,22 @@ func isParamIn(param string, params gotenv.Env) bool {
 	return false
 }
 
+// paramType returns the parameter type and KMS key used for a key. Key
+// types declared in the catalog override the store encryption setting.
+func paramType(file *catalog.File, name string, input ssm.PutParameterInput) (string, *string) {
+	keyType, declared := file.DeclaredKeyType(name)
+	if !declared {
+		return *input.Type, input.KeyId
+	}
+
+	switch keyType {
+	case catalog.KeyTypePlain, catalog.KeyTypeReference:
+		return ssm.ParameterTypeString, nil
+	default:
+		return ssm.ParameterTypeSecureString, input.KeyId
+	}
+}
+
 func noChange(np param, params []param) bool {
 	for _, p := range params {
 		if p.name == np.name {
@@ -595,4 +995,8 @@ type param struct {
 func init() {
 	s := new(AWSParameterStore)
 	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: "AWS_STORE_KMS_KEY_ID"},
+	})
 }
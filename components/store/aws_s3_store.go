@@ -2,12 +2,15 @@ package store
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -61,7 +64,7 @@ func (s S3Store) Name() string {
 // SupportsFeature ...
 func (s S3Store) SupportsFeature(feature string) bool {
 	switch feature {
-	case VersionFeature:
+	case VersionFeature, FIPSFeature, PipelineFeature:
 		return true
 	default:
 		return false
@@ -89,22 +92,30 @@ func (s *S3Store) Pre(clog catalog.Catalog, file *catalog.File, access contract.
 	s.credentialType = autoDetect
 	s.encryptionType = getEncryptionType(*file)
 
-	(setting.Setting{
+	if _, err := (setting.Setting{
 		Group:        "AWS",
 		Prop:         "REGION",
 		Prompt:       uo.Prompt,
 		AutoSave:     true,
 		DefaultValue: awsDefaultRegion,
 		Vault:        vault.EnvVault{},
-	}).Get(clog.Context, io)
+		Shared:       true,
+	}).Get(clog.Context, io); err != nil {
+		return err
+	}
 
 	//---------------------------------------------
 	//- Store authentication and encryption options
 	//---------------------------------------------
 	if uo.Prompt {
-		s.credentialType = strings.ToLower(prompt.GetValFromUser("Authentication", prompt.Options{
+		credentialType, err := prompt.GetValFromUser("Authentication", prompt.Options{
 			Description:  "OPTIONS\n (P)rofile \n (U)ser",
-			DefaultValue: "P"}, io))
+			DefaultValue: "P"}, io)
+		if err != nil {
+			return err
+		}
+
+		s.credentialType = strings.ToLower(credentialType)
 	}
 
 	//------------------------------------------
@@ -115,33 +126,39 @@ func (s *S3Store) Pre(clog catalog.Catalog, file *catalog.File, access contract.
 		os.Unsetenv(awsSecretAccessKey)
 		os.Unsetenv(awsAccessKeyID)
 
-		(setting.Setting{
+		if _, err := (setting.Setting{
 			Group:        "AWS",
 			Prop:         "PROFILE",
 			DefaultValue: os.Getenv(awsProfile),
 			Prompt:       uo.Prompt,
 			AutoSave:     true,
 			Vault:        vault.EnvVault{},
-		}).Get(clog.Context, io)
+		}).Get(clog.Context, io); err != nil {
+			return err
+		}
 
 	case cTypeUser:
 		os.Unsetenv(awsProfile)
 
-		(setting.Setting{
+		if _, err := (setting.Setting{
 			Group:    "AWS",
 			Prop:     "ACCESS_KEY_ID",
 			Prompt:   uo.Prompt,
 			AutoSave: true,
 			Vault:    access,
-		}).Get(clog.Context, io)
+		}).Get(clog.Context, io); err != nil {
+			return err
+		}
 
-		(setting.Setting{
+		if _, err := (setting.Setting{
 			Group:    "AWS",
 			Prop:     "SECRET_ACCESS_KEY",
 			Prompt:   uo.Prompt,
 			AutoSave: true,
 			Vault:    access,
-		}).Get(clog.Context, io)
+		}).Get(clog.Context, io); err != nil {
+			return err
+		}
 	}
 
 	//------------------------------------------
@@ -155,6 +172,7 @@ func (s *S3Store) Pre(clog catalog.Catalog, file *catalog.File, access contract.
 		AutoSave:     true,
 		DefaultValue: clog.GetAnyDataBy(awsBucketName, fmt.Sprintf("cstore-%s", clog.Context)),
 		Vault:        file,
+		Shared:       true,
 	}
 
 	//------------------------------------------
@@ -168,6 +186,7 @@ func (s *S3Store) Pre(clog catalog.Catalog, file *catalog.File, access contract.
 		DefaultValue: clog.GetAnyDataBy("AWS_STORE_KMS_KEY_ID", ""),
 		AutoSave:     false,
 		Vault:        file,
+		Shared:       true,
 	}
 
 	//------------------------------------------
@@ -178,6 +197,9 @@ func (s *S3Store) Pre(clog catalog.Catalog, file *catalog.File, access contract.
 		return err
 	}
 
+	awsMeasure(sess, s.Name())
+	awsExplainDenied(sess)
+
 	s.Session = sess
 
 	return err
@@ -225,10 +247,24 @@ func (s S3Store) Push(file *catalog.File, fileData []byte, version string) error
 		awsBucketName: bucket,
 	})
 
+	checksum := sha256Checksum(fileData)
+
 	input := &s3manager.UploadInput{
 		Bucket: &bucket,
 		Key:    &contextKey,
 		Body:   bytes.NewReader(fileData),
+		Metadata: map[string]*string{
+			checksumMetadata: &checksum,
+		},
+	}
+
+	//------------------------------------------
+	//- S3 rejects single part uploads that do
+	//- not match the Content-MD5 header.
+	//------------------------------------------
+	if len(fileData) < s3manager.DefaultUploadPartSize {
+		md5 := contentMD5(fileData)
+		input.ContentMD5 = &md5
 	}
 
 	//------------------------------------------
@@ -251,9 +287,32 @@ func (s S3Store) Push(file *catalog.File, fileData []byte, version string) error
 
 	uploader := s3manager.NewUploader(s.Session)
 
-	_, err = uploader.Upload(input)
+	output, err := uploader.Upload(input)
+	if err != nil {
+		return err
+	}
 
-	return err
+	//------------------------------------------
+	//- Verify the stored object is complete.
+	//------------------------------------------
+	head, err := s3.New(s.Session).HeadObject(&s3.HeadObjectInput{
+		Bucket:    &bucket,
+		Key:       &contextKey,
+		VersionId: output.VersionID,
+	})
+	if err != nil {
+		return fmt.Errorf("could not verify upload (%s)", err)
+	}
+
+	if head.ContentLength == nil || *head.ContentLength != int64(len(fileData)) {
+		return fmt.Errorf("upload verification failed, %d bytes were sent but the stored size differs", len(fileData))
+	}
+
+	if stored, found := metadataValue(head.Metadata, checksumMetadata); !found || stored != checksum {
+		return errors.New("upload verification failed, the stored checksum differs")
+	}
+
+	return nil
 }
 
 // Pull ...
@@ -274,22 +333,136 @@ func (s S3Store) Pull(file *catalog.File, version string) ([]byte, contract.Attr
 		Key:    &contextKey,
 	}
 
+	return getObject(s3.New(s.Session), &input)
+}
+
+// PullAsOf ...
+func (s S3Store) PullAsOf(file *catalog.File, version string, asOf time.Time) ([]byte, contract.Attributes, error) {
+
+	contextKey := s.key(file.Path, version)
+
+	setting, _ := s.settings[awsBucketName]
+	setting.Prompt = false
+
+	bucket, err := setting.Get(s.context, s.io)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
 	s3svc := s3.New(s.Session)
 
-	fileData, err := s3svc.GetObject(&input)
+	//------------------------------------------
+	//- Find the last version saved or deleted
+	//- at or before the requested time.
+	//------------------------------------------
+	var match *s3.ObjectVersion
+	deleted := time.Time{}
+
+	err = s3svc.ListObjectVersionsPages(&s3.ListObjectVersionsInput{
+		Bucket: &bucket,
+		Prefix: &contextKey,
+	}, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		for _, v := range page.Versions {
+			if *v.Key != contextKey || v.LastModified.After(asOf) {
+				continue
+			}
+
+			if match == nil || v.LastModified.After(*match.LastModified) {
+				match = v
+			}
+		}
+
+		for _, m := range page.DeleteMarkers {
+			if *m.Key == contextKey && !m.LastModified.After(asOf) && m.LastModified.After(deleted) {
+				deleted = *m.LastModified
+			}
+		}
+
+		return true
+	})
 	if err != nil {
 		return []byte{}, contract.Attributes{}, err
 	}
-	defer fileData.Body.Close()
 
-	b, err := ioutil.ReadAll(fileData.Body)
+	if match == nil || deleted.After(*match.LastModified) {
+		return []byte{}, contract.Attributes{}, fmt.Errorf("%s did not exist at %s, verify bucket versioning is enabled", contextKey, asOf.Format(time.RFC3339))
+	}
+
+	return getObject(s3svc, &s3.GetObjectInput{
+		Bucket:    &bucket,
+		Key:       &contextKey,
+		VersionId: match.VersionId,
+	})
+}
+
+// Revisions ...
+func (s S3Store) Revisions(file *catalog.File, version string) ([]contract.Revision, error) {
+
+	contextKey := s.key(file.Path, version)
+
+	setting, _ := s.settings[awsBucketName]
+	setting.Prompt = false
+
+	bucket, err := setting.Get(s.context, s.io)
 	if err != nil {
-		return b, contract.Attributes{}, err
+		return nil, err
 	}
 
-	return b, contract.Attributes{
-		LastModified: *fileData.LastModified,
-	}, nil
+	revisions := []contract.Revision{}
+
+	err = s3.New(s.Session).ListObjectVersionsPages(&s3.ListObjectVersionsInput{
+		Bucket: &bucket,
+		Prefix: &contextKey,
+	}, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		for _, v := range page.Versions {
+			if *v.Key != contextKey {
+				continue
+			}
+
+			r := contract.Revision{
+				ID:       aws.StringValue(v.VersionId),
+				Modified: aws.TimeValue(v.LastModified),
+				Latest:   aws.BoolValue(v.IsLatest),
+			}
+
+			if v.Owner != nil {
+				r.By = aws.StringValue(v.Owner.DisplayName)
+			}
+
+			revisions = append(revisions, r)
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Modified.After(revisions[j].Modified)
+	})
+
+	return revisions, nil
+}
+
+// PullRevision ...
+func (s S3Store) PullRevision(file *catalog.File, version, revision string) ([]byte, contract.Attributes, error) {
+
+	contextKey := s.key(file.Path, version)
+
+	setting, _ := s.settings[awsBucketName]
+	setting.Prompt = false
+
+	bucket, err := setting.Get(s.context, s.io)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	return getObject(s3.New(s.Session), &s3.GetObjectInput{
+		Bucket:    &bucket,
+		Key:       &contextKey,
+		VersionId: &revision,
+	})
 }
 
 // Changed ...
@@ -327,9 +500,147 @@ func (s S3Store) Changed(file *catalog.File, fileData []byte, version string) (t
 	return *fileMetaData.LastModified, nil
 }
 
+// ETag ...
+func (s S3Store) ETag(file *catalog.File, version string) (string, error) {
+
+	contextKey := s.key(file.Path, version)
+
+	setting, _ := s.settings[awsBucketName]
+	setting.Prompt = false
+
+	bucket, err := setting.Get(s.context, s.io)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := s3.New(s.Session).HeadObject(&s3.HeadObjectInput{
+		Bucket: &bucket,
+		Key:    &contextKey,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if output.ETag == nil {
+		return "", nil
+	}
+
+	return *output.ETag, nil
+}
+
+// Encryption ...
+func (s S3Store) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+
+	contextKey := s.key(file.Path, version)
+
+	setting, _ := s.settings[awsBucketName]
+	setting.Prompt = false
+
+	bucket, err := setting.Get(s.context, s.io)
+	if err != nil {
+		return contract.Encryption{}, nil, err
+	}
+
+	output, err := s3.New(s.Session).HeadObject(&s3.HeadObjectInput{
+		Bucket: &bucket,
+		Key:    &contextKey,
+	})
+	if err != nil {
+		return contract.Encryption{}, nil, err
+	}
+
+	switch aws.StringValue(output.ServerSideEncryption) {
+	case "":
+		return contract.Encryption{Mechanism: contract.EncryptionNone}, nil, nil
+	case s3.ServerSideEncryptionAes256:
+		return contract.Encryption{Mechanism: contract.EncryptionSSE, Detail: "SSE-S3"}, nil, nil
+	default:
+		return contract.Encryption{Mechanism: contract.EncryptionKMS, Detail: aws.StringValue(output.SSEKMSKeyId)}, nil, nil
+	}
+}
+
+// Expires ...
+func (s S3Store) Expires() time.Time {
+	return awsExpires(s.Session)
+}
+
+// Refresh ...
+func (s S3Store) Refresh() error {
+	return awsRefresh(s.Session)
+}
+
+// Locate ...
+func (s S3Store) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	setting, _ := s.settings[awsBucketName]
+	setting.Prompt = false
+
+	bucket, err := setting.Get(s.context, s.io)
+	if err != nil {
+		return contract.Location{}, err
+	}
+
+	location := contract.Location{
+		Remote:      fmt.Sprintf("arn:aws:s3:::%s/%s", bucket, s.key(file.Path, version)),
+		Credentials: awsCredentials(),
+		Encryption:  "default bucket encryption",
+	}
+
+	if kms := file.Data["AWS_STORE_KMS_KEY_ID"]; len(kms) > 0 {
+		location.Encryption = fmt.Sprintf("KMS key %s", kms)
+	}
+
+	return location, nil
+}
+
+// Discover ...
+func (s S3Store) Discover(prefix string) ([]contract.Discovered, error) {
+
+	setting, _ := s.settings[awsBucketName]
+	setting.Prompt = false
+
+	bucket, err := setting.Get(s.context, s.io)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(prefix) == 0 {
+		prefix = fmt.Sprintf("%s/", s.context)
+	}
+	prefix = strings.TrimPrefix(prefix, "/")
+
+	input := s3.ListObjectsV2Input{
+		Bucket: &bucket,
+		Prefix: &prefix,
+	}
+
+	found := discovery{}
+
+	err = s3.New(s.Session).ListObjectsV2Pages(&input, func(page *s3.ListObjectsV2Output, last bool) bool {
+		for _, o := range page.Contents {
+			if o.Key == nil || o.LastModified == nil {
+				continue
+			}
+			found.add(*o.Key, 0, *o.LastModified)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return found.files(), nil
+}
+
 func init() {
 	s := new(S3Store)
 	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: awsBucketName, Required: true},
+		{Key: "AWS_STORE_KMS_KEY_ID"},
+		{Key: fileDataEncryptionKey},
+	})
 }
 
 //------------------------------------------
@@ -344,6 +655,31 @@ func (s S3Store) key(path, version string) string {
 	return fmt.Sprintf("%s/%s", s.context, path)
 }
 
+// getObject reads an object verifying its checksum when one was
+// saved.
+func getObject(s3svc *s3.S3, input *s3.GetObjectInput) ([]byte, contract.Attributes, error) {
+	fileData, err := s3svc.GetObject(input)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+	defer fileData.Body.Close()
+
+	b, err := ioutil.ReadAll(fileData.Body)
+	if err != nil {
+		return b, contract.Attributes{}, err
+	}
+
+	if checksum, found := metadataValue(fileData.Metadata, checksumMetadata); found {
+		if err := verifyChecksum(b, checksum); err != nil {
+			return []byte{}, contract.Attributes{}, fmt.Errorf("%s is corrupted (%s)", *input.Key, err)
+		}
+	}
+
+	return b, contract.Attributes{
+		LastModified: *fileData.LastModified,
+	}, nil
+}
+
 func getEncryptionType(file catalog.File) string {
 	if _, found := file.Data[fileDataEncryptionKey]; found {
 		return eTypeClient
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+func TestAWSResource(t *testing.T) {
+	// arrange
+	principal := "arn:aws:sts::123456789012:assumed-role/dev/jane"
+
+	tests := []struct {
+		service  string
+		params   interface{}
+		expected string
+	}{
+		{"ssm", &ssm.PutParameterInput{Name: aws.String("/my-app/.env/DB_PASSWORD")}, "arn:aws:ssm:us-east-1:123456789012:parameter/my-app/.env/DB_PASSWORD"},
+		{"s3", &s3.GetObjectInput{Bucket: aws.String("configs"), Key: aws.String("my-app/.env")}, "arn:aws:s3:::configs/my-app/.env"},
+		{"sts", nil, "*"},
+	}
+
+	for _, test := range tests {
+		r := &request.Request{Params: test.params}
+		r.Config.Region = aws.String("us-east-1")
+		r.ClientInfo.ServiceName = test.service
+
+		// act
+		actual := awsResource(r, principal)
+
+		// assert
+		if actual != test.expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,448 @@
+package store
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"time"
+
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/setting"
+)
+
+const (
+	bitwardenCLI = "bw"
+
+	bitwardenSecureNote  = 2
+	bitwardenHiddenField = 1
+
+	bitwardenOrganizationToken = "BW_ORGANIZATION"
+	bitwardenCollectionToken   = "BW_COLLECTION"
+
+	bitwardenSessionEnvVar = "BW_SESSION"
+)
+
+// BitwardenStore ...
+type BitwardenStore struct {
+	context  string
+	session  string
+	settings map[string]setting.Setting
+
+	io models.IO
+}
+
+// Name ...
+func (s BitwardenStore) Name() string {
+	return "bitwarden"
+}
+
+// SupportsFeature ...
+func (s BitwardenStore) SupportsFeature(feature string) bool {
+	switch feature {
+	case VersionFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFileType ...
+func (s BitwardenStore) SupportsFileType(fileType string) bool {
+	return true
+}
+
+// Description ...
+func (s BitwardenStore) Description() string {
+	return `
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/BITWARDEN.md
+`
+}
+
+// Pre ...
+func (s *BitwardenStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.settings = map[string]setting.Setting{}
+	s.context = clog.Context
+	s.io = io
+
+	if _, err := exec.LookPath(bitwardenCLI); err != nil {
+		return errors.New("Bitwarden CLI (bw) not found, install it and run 'bw login' before using this store")
+	}
+
+	//------------------------------------------
+	//- Auth Credentials
+	//------------------------------------------
+	session, err := (setting.Setting{
+		Description: "The session key returned by 'bw unlock' is used to access the Bitwarden vault.",
+		Group:       "BW",
+		Prop:        "SESSION",
+		Prompt:      uo.Prompt,
+		HideInput:   true,
+		AutoSave:    true,
+		Vault:       access,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.session = session
+
+	//------------------------------------------
+	//- Sharing
+	//------------------------------------------
+	s.settings[bitwardenOrganizationToken] = setting.Setting{
+		Description:  "Organization ID owning the item. Leave blank to store the item in the personal vault.",
+		Group:        "BW",
+		Prop:         "ORGANIZATION_ID",
+		DefaultValue: clog.GetAnyDataBy("BW_ORGANIZATION_ID", ""),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}
+
+	s.settings[bitwardenCollectionToken] = setting.Setting{
+		Description:  "Collection ID used to share the item with organization members.",
+		Group:        "BW",
+		Prop:         "COLLECTION_ID",
+		DefaultValue: clog.GetAnyDataBy("BW_COLLECTION_ID", ""),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}
+
+	_, err = s.bw("sync")
+
+	return err
+}
+
+// Push ...
+func (s BitwardenStore) Push(file *catalog.File, fileData []byte, version string) error {
+
+	if len(fileData) == 0 {
+		return errors.New("empty file")
+	}
+
+	name := s.itemName(file.Path, version)
+
+	item, found, err := s.find(name)
+	if err != nil {
+		return err
+	}
+
+	//------------------------------------------
+	//- Build a new item when one does not exist
+	//------------------------------------------
+	if !found {
+		item = map[string]interface{}{
+			"type":       bitwardenSecureNote,
+			"name":       name,
+			"secureNote": map[string]interface{}{"type": 0},
+		}
+
+		organization, err := s.setting(bitwardenOrganizationToken)
+		if err != nil {
+			return err
+		}
+
+		if len(organization) > 0 {
+			collection, err := s.setting(bitwardenCollectionToken)
+			if err != nil {
+				return err
+			}
+
+			item["organizationId"] = organization
+
+			if len(collection) > 0 {
+				item["collectionIds"] = []string{collection}
+			}
+		}
+	}
+
+	//------------------------------------------
+	//- Env files are saved as hidden fields
+	//------------------------------------------
+	if file.SupportsConfig() {
+		config := gotenv.Parse(bytes.NewReader(fileData))
+		if len(config) == 0 {
+			return errors.New("failed to parse environment variables")
+		}
+
+		recordKeysModified(file, fieldValues(item), config, version, time.Now())
+
+		keys := []string{}
+		for key := range config {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fields := []map[string]interface{}{}
+		for _, key := range keys {
+			fields = append(fields, map[string]interface{}{
+				"name":  key,
+				"value": config[key],
+				"type":  bitwardenHiddenField,
+			})
+		}
+
+		item["fields"] = fields
+		item["notes"] = nil
+	} else {
+		item["fields"] = []map[string]interface{}{}
+		item["notes"] = string(fileData)
+	}
+
+	encoded, err := encodeItem(item)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		_, err = s.bw("edit", "item", fmt.Sprint(item["id"]), encoded)
+	} else {
+		_, err = s.bw("create", "item", encoded)
+	}
+
+	return err
+}
+
+// Pull ...
+func (s BitwardenStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+
+	item, found, err := s.find(s.itemName(file.Path, version))
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	if !found {
+		return []byte{}, contract.Attributes{}, errors.New("item not found, verify Bitwarden account and session")
+	}
+
+	attr := contract.Attributes{
+		LastModified: revisionDate(item),
+	}
+
+	if !file.SupportsConfig() {
+		notes, _ := item["notes"].(string)
+		return []byte(notes), attr, nil
+	}
+
+	var buffer bytes.Buffer
+
+	values := fieldValues(item)
+
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		buffer.WriteString(fmt.Sprintf("%s=%s\n", key, values[key]))
+	}
+
+	return buffer.Bytes(), attr, nil
+}
+
+// Purge ...
+func (s BitwardenStore) Purge(file *catalog.File, version string) error {
+
+	name := s.itemName(file.Path, version)
+
+	item, found, err := s.find(name)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return nil
+	}
+
+	confirmed, err := prompt.Confirm(fmt.Sprintf("  - %s\n \n  Delete item?", name), prompt.Danger, s.io)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		return errors.New("user aborted")
+	}
+
+	_, err = s.bw("delete", "item", fmt.Sprint(item["id"]))
+
+	return err
+}
+
+// Changed ...
+func (s BitwardenStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+
+	item, found, err := s.find(s.itemName(file.Path, version))
+	if err != nil || !found {
+		return time.Time{}, err
+	}
+
+	return revisionDate(item), nil
+}
+
+// ETag ...
+func (s BitwardenStore) ETag(file *catalog.File, version string) (string, error) {
+
+	item, found, err := s.find(s.itemName(file.Path, version))
+	if err != nil || !found {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%s", item["id"], item["revisionDate"]), nil
+}
+
+// KeysModified ...
+func (s BitwardenStore) KeysModified(file *catalog.File, version string) (map[string]time.Time, error) {
+
+	if !file.SupportsConfig() {
+		return nil, fmt.Errorf("store does not support keys for file type: %s", file.Type)
+	}
+
+	item, found, err := s.find(s.itemName(file.Path, version))
+	if err != nil || !found {
+		return map[string]time.Time{}, err
+	}
+
+	keys := []string{}
+	for key := range fieldValues(item) {
+		keys = append(keys, key)
+	}
+
+	return recordedKeysModified(file, keys, version), nil
+}
+
+// Encryption ...
+func (s BitwardenStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	location, err := s.Locate(file, "", version)
+	return contract.Encryption{Mechanism: contract.EncryptionClientSide, Detail: location.Encryption}, nil, err
+}
+
+// Locate ...
+func (s BitwardenStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	location := contract.Location{
+		Remote:      fmt.Sprintf("item %s", s.itemName(file.Path, version)),
+		Credentials: fmt.Sprintf("BW_SESSION from %s vault", file.Vaults.Access),
+		Encryption:  "Bitwarden vault encryption",
+	}
+
+	if len(key) > 0 {
+		location.Remote = fmt.Sprintf("%s, hidden field %s", location.Remote, key)
+	}
+
+	if organization, _ := s.setting(bitwardenOrganizationToken); len(organization) > 0 {
+		location.Remote = fmt.Sprintf("%s in organization %s", location.Remote, organization)
+	}
+
+	return location, nil
+}
+
+func init() {
+	s := new(BitwardenStore)
+	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: "BW_ORGANIZATION_ID"},
+		{Key: "BW_COLLECTION_ID"},
+	})
+}
+
+//------------------------------------------
+//- Bitwarden CLI helpers.
+//------------------------------------------
+func (s BitwardenStore) itemName(path, version string) string {
+	if len(version) > 0 {
+		return fmt.Sprintf("cstore/%s/%s/%s", s.context, version, path)
+	}
+	return fmt.Sprintf("cstore/%s/%s", s.context, path)
+}
+
+func (s BitwardenStore) setting(token string) (string, error) {
+	return s.settings[token].Get(s.context, s.io)
+}
+
+func (s BitwardenStore) find(name string) (map[string]interface{}, bool, error) {
+	out, err := s.bw("list", "items", "--search", name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	items := []map[string]interface{}{}
+	if err := json.Unmarshal(out, &items); err != nil {
+		return nil, false, err
+	}
+
+	// search is a partial match, so only an exact name is accepted
+	for _, item := range items {
+		if item["name"] == name {
+			return item, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+func (s BitwardenStore) bw(args ...string) ([]byte, error) {
+	args = append(args, "--nointeraction")
+
+	c := exec.Command(bitwardenCLI, args...)
+
+	// the session is passed in the environment since arguments are
+	// visible to other users of the machine
+	if len(s.session) > 0 {
+		c.Env = append(os.Environ(), bitwardenSessionEnvVar+"="+s.session)
+	}
+
+	return run(c)
+}
+
+func encodeItem(item map[string]interface{}) (string, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func fieldValues(item map[string]interface{}) map[string]string {
+	values := map[string]string{}
+
+	fields, _ := item["fields"].([]interface{})
+	for _, f := range fields {
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := field["name"].(string)
+		value, _ := field["value"].(string)
+		values[name] = value
+	}
+
+	return values
+}
+
+func revisionDate(item map[string]interface{}) time.Time {
+	date, _ := item["revisionDate"].(string)
+
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
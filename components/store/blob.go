@@ -0,0 +1,240 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+const (
+	blobPrefix   = "CSTORE_BLOB"
+	blobChecksum = "SHA256"
+
+	// blobChunk is the encoded size of each key for stores without
+	// a value limit.
+	blobChunk = 32 * 1024
+)
+
+// SupportsFile returns true when the store supports the file type or
+// can save the file as a blob.
+func SupportsFile(st contract.IStore, fileType string) bool {
+	_, blob := st.(contract.IBlobStore)
+	return st.SupportsFileType(fileType) || blob
+}
+
+// Blob wraps a key/value store when it does not support the file type;
+// so, the file is saved as base64 encoded chunks in keys.
+func Blob(st contract.IStore, file *catalog.File) (contract.IStore, error) {
+	if st.SupportsFileType(file.Type) {
+		return st, nil
+	}
+
+	limited, ok := st.(contract.IBlobStore)
+	if !ok {
+		return nil, fmt.Errorf("%s store does not support file type %s", st.Name(), file.Type)
+	}
+
+	value, total := limited.BlobLimits()
+
+	return newBlobStore(st, value, total), nil
+}
+
+// blobStore encodes files pushed to and decodes files pulled from the
+// key/value store it wraps.
+type blobStore struct {
+	forwardingStore
+
+	value int
+	total int
+}
+
+func newBlobStore(st contract.IStore, value, total int) blobStore {
+	s := blobStore{
+		forwardingStore: forwardingStore{
+			IStore: st,
+			entry:  asEnv,
+			decode: decodeBlob,
+		},
+		value: value,
+		total: total,
+	}
+	s.forwardingStore.encode = s.split
+
+	return s
+}
+
+// SupportsFeature ...
+func (s blobStore) SupportsFeature(feature string) bool {
+	return feature == PipelineFeature || s.IStore.SupportsFeature(feature)
+}
+
+// SupportsFileType ...
+func (s blobStore) SupportsFileType(fileType string) bool {
+	return true
+}
+
+// KeysModified reports no keys since the keys holding the file are not
+// the file's keys.
+func (s blobStore) KeysModified(file *catalog.File, version string) (map[string]time.Time, error) {
+	return map[string]time.Time{}, nil
+}
+
+// KeyHistory ...
+func (s blobStore) KeyHistory(file *catalog.File, key, version string) ([]contract.KeyChange, error) {
+	return nil, fmt.Errorf("%s is saved as a blob in the %s store; so, key history is not kept", file.Path, s.Name())
+}
+
+// SetKeyTypes ...
+func (s blobStore) SetKeyTypes(file *catalog.File, types map[string]string, version string) error {
+	return fmt.Errorf("%s is saved as a blob in the %s store; so, key types cannot be set", file.Path, s.Name())
+}
+
+// Encryption reports the encryption of the keys holding the file. Any
+// key saved in plain text means the file is not encrypted.
+func (s blobStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	described, ok := s.IStore.(contract.IEncryptionStore)
+	if !ok {
+		return contract.Encryption{Mechanism: contract.EncryptionUnknown}, nil, nil
+	}
+
+	e, keys, err := described.Encryption(asEnv(file), version)
+	if err != nil {
+		return e, nil, err
+	}
+
+	names := []string{}
+	for name := range keys {
+		if strings.HasPrefix(name, blobKeyPrefix(file)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		if i == 0 || keys[name].Mechanism == contract.EncryptionNone {
+			e = keys[name]
+		}
+	}
+
+	return e, nil, nil
+}
+
+// Locate ...
+func (s blobStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+	location := contract.Location{Remote: "unknown", Credentials: "unknown", Encryption: "unknown"}
+
+	if locatable, ok := s.IStore.(contract.ILocatableStore); ok {
+		l, err := locatable.Locate(asEnv(file), "", version)
+		if err != nil {
+			return l, err
+		}
+		location = l
+	}
+
+	location.Remote = fmt.Sprintf("%s (as %s_* keys)", location.Remote, blobKeyPrefix(file))
+
+	return location, nil
+}
+
+// split splits the file into keys sized for the store.
+func (s blobStore) split(file *catalog.File, data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty file")
+	}
+
+	chunk := blobChunk
+	if s.value > 0 && s.value < chunk {
+		chunk = s.value
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+
+	if s.total > 0 && len(encoded) > s.total {
+		return nil, fmt.Errorf("%s is %d bytes encoded, over the %d byte limit of the %s store", file.Path, len(encoded), s.total, s.Name())
+	}
+
+	prefix := blobKeyPrefix(file)
+	sum := sha256.Sum256(data)
+
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("%s_%s=%s\n", prefix, blobChecksum, hex.EncodeToString(sum[:])))
+
+	for i := 0; i*chunk < len(encoded); i++ {
+		end := (i + 1) * chunk
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+
+		buffer.WriteString(fmt.Sprintf("%s_%04d=%s\n", prefix, i, encoded[i*chunk:end]))
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// decodeBlob joins the keys pulled for the file and verifies the file
+// is complete.
+func decodeBlob(file *catalog.File, data []byte) ([]byte, error) {
+	prefix := blobKeyPrefix(file) + "_"
+
+	keys := gotenv.Parse(bytes.NewReader(data))
+
+	checksum, found := keys[prefix+blobChecksum]
+	if !found {
+		return nil, fmt.Errorf("%s was not saved as a blob, push it again", file.Path)
+	}
+
+	chunks := []int{}
+	for key := range keys {
+		if n, err := strconv.Atoi(strings.TrimPrefix(key, prefix)); err == nil && strings.HasPrefix(key, prefix) {
+			chunks = append(chunks, n)
+		}
+	}
+	sort.Ints(chunks)
+
+	var encoded bytes.Buffer
+	for i, n := range chunks {
+		if i != n {
+			return nil, fmt.Errorf("%s is missing part %d", file.Path, i)
+		}
+
+		encoded.WriteString(keys[fmt.Sprintf("%s%04d", prefix, n)])
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s could not be decoded (%s)", file.Path, err)
+	}
+
+	sum := sha256.Sum256(decoded)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return nil, fmt.Errorf("%s does not match the checksum saved with it, push it again", file.Path)
+	}
+
+	return decoded, nil
+}
+
+// blobKeyPrefix names the keys for the file; so, files saved in a
+// shared set of keys do not overwrite each other.
+func blobKeyPrefix(file *catalog.File) string {
+	sum := sha256.Sum256([]byte(file.Path))
+	return fmt.Sprintf("%s_%s", blobPrefix, strings.ToUpper(hex.EncodeToString(sum[:4])))
+}
+
+// asEnv returns a copy of the file entry the wrapped store saves as an
+// env file.
+func asEnv(file *catalog.File) *catalog.File {
+	f := *file
+	f.Type = EnvFeature
+	return &f
+}
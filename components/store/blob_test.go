@@ -0,0 +1,180 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+// keyStore saves env files as keys in memory like a key/value store.
+type keyStore struct {
+	HarborStore
+
+	keys  map[string]string
+	value int
+	total int
+}
+
+func (s keyStore) BlobLimits() (int, int) { return s.value, s.total }
+
+func (s keyStore) Push(file *catalog.File, fileData []byte, version string) error {
+	if !file.SupportsConfig() {
+		return fmt.Errorf("store does not support file type: %s", file.Type)
+	}
+
+	for key, value := range gotenv.Parse(bytes.NewReader(fileData)) {
+		if len(value) > s.value {
+			return fmt.Errorf("%s is over the value limit", key)
+		}
+
+		s.keys[key] = value
+		file.AddData(map[string]string{key: "pushed"})
+	}
+
+	return nil
+}
+
+func (s keyStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+	var buffer bytes.Buffer
+	for key, value := range s.keys {
+		buffer.WriteString(fmt.Sprintf("%s=%s\n", key, value))
+	}
+
+	return buffer.Bytes(), contract.Attributes{}, nil
+}
+
+func TestBlobPushPull(t *testing.T) {
+	// arrange
+	s := keyStore{keys: map[string]string{}, value: 100}
+
+	st, err := Blob(&s, &catalog.File{Type: "pem"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := catalog.File{Path: "certs/server.pem", Type: "pem"}
+	data := []byte(strings.Repeat("-----BEGIN CERTIFICATE-----\n\x00\xff", 20))
+
+	// act
+	if err := st.Push(&cert, data, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled, _, err := st.Pull(&cert, "")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(pulled) != string(data) {
+		t.Errorf("\nEXPECTED: %q \nACTUAL: %q", data, pulled)
+	}
+
+	if len(s.keys) < 2 || len(cert.Data) != len(s.keys) {
+		t.Errorf("\nEXPECTED: %d keys recorded \nACTUAL: %d", len(s.keys), len(cert.Data))
+	}
+
+	if cert.Type != "pem" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "pem", cert.Type)
+	}
+}
+
+func TestBlobMissingPart(t *testing.T) {
+	// arrange
+	s := keyStore{keys: map[string]string{}, value: 64}
+
+	st, _ := Blob(&s, &catalog.File{Type: "json"})
+	file := catalog.File{Path: "config.json", Type: "json"}
+
+	if err := st.Push(&file, []byte(strings.Repeat(`{"url":"https://example.com"}`, 5)), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	delete(s.keys, blobKeyPrefix(&file)+"_0001")
+
+	// act
+	_, _, err := st.Pull(&file, "")
+
+	// assert
+	expected := "config.json is missing part 1"
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
+
+func TestBlobTotalLimit(t *testing.T) {
+	// arrange
+	s := keyStore{keys: map[string]string{}, value: 100, total: 100}
+
+	st, _ := Blob(&s, &catalog.File{Type: "json"})
+	file := catalog.File{Path: "config.json", Type: "json"}
+
+	// act
+	err := st.Push(&file, bytes.Repeat([]byte("a"), 100), "")
+
+	// assert
+	expected := "config.json is 134 bytes encoded, over the 100 byte limit of the harbor store"
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
+
+func TestBlobSupportedFileType(t *testing.T) {
+	// arrange
+	s := keyStore{keys: map[string]string{}}
+
+	// act
+	st, err := Blob(&s, &catalog.File{Type: "env"})
+
+	// assert
+	if _, wrapped := st.(blobStore); wrapped || err != nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %T %v", "store not wrapped", st, err)
+	}
+
+	if _, err := Blob(&S3Store{}, &catalog.File{Type: "json"}); err != nil {
+		t.Error(err)
+	}
+}
+
+// typedKeyStore reports the encryption of each key saved.
+type typedKeyStore struct {
+	keyStore
+
+	encryption map[string]contract.Encryption
+}
+
+func (s typedKeyStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	return contract.Encryption{Mechanism: contract.EncryptionSSE}, s.encryption, nil
+}
+
+func TestBlobEncryption(t *testing.T) {
+	// arrange
+	file := catalog.File{Path: "config.json", Type: "json"}
+	prefix := blobKeyPrefix(&file)
+
+	s := typedKeyStore{encryption: map[string]contract.Encryption{
+		prefix + "_0000": {Mechanism: contract.EncryptionSSE},
+		prefix + "_0001": {Mechanism: contract.EncryptionNone},
+		"OTHER_SETTING":  {Mechanism: contract.EncryptionKMS},
+	}}
+
+	st, _ := Blob(&s, &catalog.File{Type: "json"})
+
+	// act
+	e, keys, err := st.(contract.IEncryptionStore).Encryption(&file, "")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Mechanism != contract.EncryptionNone || keys != nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s %v", contract.EncryptionNone, e.Mechanism, keys)
+	}
+}
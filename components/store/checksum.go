@@ -0,0 +1,49 @@
+package store
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// checksumMetadata is the remote metadata holding the SHA-256 checksum
+// of the pushed contents.
+const checksumMetadata = "cstore-sha256"
+
+// sha256Checksum ...
+func sha256Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// contentMD5 returns the Content-MD5 header value used by stores to
+// reject uploads corrupted in transit.
+func contentMD5(data []byte) string {
+	sum := md5.Sum(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// metadataValue returns a metadata value regardless of the case the
+// store returns the key in.
+func metadataValue(metadata map[string]*string, key string) (string, bool) {
+	for k, v := range metadata {
+		if strings.EqualFold(k, key) && v != nil {
+			return *v, true
+		}
+	}
+
+	return "", false
+}
+
+// verifyChecksum returns an error when the contents do not match the
+// checksum recorded when they were pushed.
+func verifyChecksum(data []byte, checksum string) error {
+	if actual := sha256Checksum(data); actual != checksum {
+		return fmt.Errorf("checksum mismatch, expected %s but computed %s", checksum, actual)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestContentMD5(t *testing.T) {
+	// act
+	actual := contentMD5([]byte("SECRET=1\n"))
+
+	// assert
+	if expected := "qAFlcpGQTeYXcCTLjfUQ1A=="; actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	// arrange
+	data := []byte("SECRET=1\n")
+	checksum := sha256Checksum(data)
+
+	stored := checksum
+	metadata := map[string]*string{"Cstore-Sha256": &stored}
+
+	// act
+	value, found := metadataValue(metadata, checksumMetadata)
+	validErr := verifyChecksum(data, value)
+	truncatedErr := verifyChecksum(data[:4], value)
+
+	// assert
+	if !found || value != checksum {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s (found %t)", checksum, value, found)
+	}
+
+	if validErr != nil {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %s", nil, validErr)
+	}
+
+	if truncatedErr == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "checksum mismatch error", truncatedErr)
+	}
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+// discovery collects the files found in a store keyed by their remote
+// name, like "context/path" or "context/version/path".
+type discovery map[string]*contract.Discovered
+
+// add records a remote file name, the number of keys found for it, and
+// when it was modified. Names without a path are ignored.
+func (d discovery) add(name string, keys int, modified time.Time) {
+	name = strings.Trim(name, "/")
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) < 2 || len(parts[1]) == 0 {
+		return
+	}
+
+	f, found := d[name]
+	if !found {
+		f = &contract.Discovered{Context: parts[0], Path: parts[1]}
+		d[name] = f
+	}
+
+	f.Keys += keys
+
+	if modified.After(f.Modified) {
+		f.Modified = modified
+	}
+}
+
+// files returns the files found sorted by context and path. Since
+// versions are stored as a folder in front of the path, a file whose
+// path is another file's path after its first folder is treated as a
+// version of that file.
+func (d discovery) files() []contract.Discovered {
+	files := []contract.Discovered{}
+
+	for _, f := range d {
+		found := *f
+
+		if parts := strings.SplitN(f.Path, "/", 2); len(parts) == 2 {
+			if _, versioned := d[f.Context+"/"+parts[1]]; versioned {
+				found.Version, found.Path = parts[0], parts[1]
+			}
+		}
+
+		files = append(files, found)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Context != files[j].Context {
+			return files[i].Context < files[j].Context
+		}
+		if files[i].Path != files[j].Path {
+			return files[i].Path < files[j].Path
+		}
+		return files[i].Version < files[j].Version
+	})
+
+	return files
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+func TestDiscoveryFiles(t *testing.T) {
+	// arrange
+	earlier := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	d := discovery{}
+	d.add("/my-app/prod/.env", 1, earlier)
+	d.add("/my-app/prod/.env", 1, now)
+	d.add("/my-app/v1/prod/.env", 1, earlier)
+	d.add("my-app/config/app.json", 0, now)
+	d.add("/my-app", 1, now)
+
+	// act
+	files := d.files()
+
+	// assert
+	expected := []contract.Discovered{
+		{Context: "my-app", Path: "config/app.json", Modified: now},
+		{Context: "my-app", Path: "prod/.env", Keys: 2, Modified: now},
+		{Context: "my-app", Path: "prod/.env", Version: "v1", Keys: 1, Modified: earlier},
+	}
+
+	if len(files) != len(expected) {
+		t.Fatalf("\nEXPECTED: %v \nACTUAL: %v", expected, files)
+	}
+
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected[i], files[i])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/crypto"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/transform"
+)
+
+const (
+	encryptedKeysStageName = "encrypted-keys"
+
+	encryptedValuePrefix = "ENC["
+	encryptedValueSuffix = "]"
+)
+
+// encryptedKeysStage encrypts the values of the keys marked encrypted
+// in the catalog with a data key wrapped for each key's recipients.
+// Other values and comments are left readable; so, the store's UI
+// still shows which keys a file has.
+type encryptedKeysStage struct {
+	envelopeKeys
+
+	keys map[string][]catalog.Recipient
+
+	// context binds each value to its file and key; so, encrypted
+	// values cannot be swapped between keys.
+	context string
+}
+
+func newEncryptedKeysStage(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) (encryptedKeysStage, error) {
+	if !file.SupportsConfig() {
+		return encryptedKeysStage{}, fmt.Errorf("encrypted keys not supported for file type %s", file.Type)
+	}
+
+	names := []string{}
+	for key := range file.EncryptedKeys {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	keys := map[string][]catalog.Recipient{}
+
+	for _, key := range names {
+		recipients, err := ParseRecipients(file.EncryptedKeys[key])
+		if err != nil {
+			return encryptedKeysStage{}, fmt.Errorf("%s (%s)", key, err)
+		}
+
+		keys[key] = recipients
+	}
+
+	return encryptedKeysStage{
+		envelopeKeys: newEnvelopeKeys(clog, file, access, uo, io),
+		keys:         keys,
+		context:      file.ContextKey(clog.Context),
+	}, nil
+}
+
+// Encode ...
+func (e encryptedKeysStage) Encode(data []byte) ([]byte, error) {
+	return transform.Map(data, func(key, value string) (string, bool, error) {
+		recipients, found := e.keys[key]
+		if !found || IsEncryptedValue(value) {
+			return value, false, nil
+		}
+
+		wrappers, err := e.wrappers(recipients)
+		if err != nil {
+			return value, false, err
+		}
+
+		sealed, err := crypto.Seal([]byte(value), e.additional(key), wrappers)
+		if err != nil {
+			return value, false, err
+		}
+
+		return encryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed) + encryptedValueSuffix, true, nil
+	})
+}
+
+// Decode leaves values pushed before their key was marked encrypted
+// as they are.
+func (e encryptedKeysStage) Decode(data []byte) ([]byte, error) {
+	return transform.Map(data, func(key, value string) (string, bool, error) {
+		if _, found := e.keys[key]; !found || !IsEncryptedValue(value) {
+			return value, false, nil
+		}
+
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, encryptedValuePrefix), encryptedValueSuffix))
+		if err != nil {
+			return value, false, err
+		}
+
+		unwrappers, err := e.unwrappers(sealed)
+		if err != nil {
+			return value, false, err
+		}
+
+		plain, err := crypto.Open(sealed, e.additional(key), unwrappers)
+		if err != nil {
+			return value, false, err
+		}
+
+		return string(plain), true, nil
+	})
+}
+
+func (e encryptedKeysStage) additional(key string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", e.context, key))
+}
+
+// IsEncryptedValue returns true when the value was encrypted by the
+// encrypted keys stage.
+func IsEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix) && strings.HasSuffix(value, encryptedValueSuffix)
+}
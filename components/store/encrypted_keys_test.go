@@ -0,0 +1,63 @@
+package store
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/crypto"
+)
+
+func TestEncryptedKeysStage(t *testing.T) {
+	// arrange
+	stage := encryptedKeysStage{
+		envelopeKeys: envelopeKeys{
+			passphrase: func() (string, error) { return "passphrase", nil },
+		},
+		keys: map[string][]catalog.Recipient{
+			"DB_PASSWORD": {{Type: crypto.PassphraseRecipient}},
+			"API_TOKEN":   {{Type: crypto.PassphraseRecipient}},
+		},
+		context: "my-app/abc",
+	}
+
+	file := "# database\nDB_HOST=db.internal\nDB_PASSWORD='p@ss word'\nexport API_TOKEN=abc123\n"
+
+	// act
+	encoded, err := stage.Encode([]byte(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	lines := strings.Split(string(encoded), "\n")
+
+	if lines[0] != "# database" || lines[1] != "DB_HOST=db.internal" {
+		t.Errorf("expected plain lines to be unchanged, got %q", encoded)
+	}
+
+	if !strings.HasPrefix(lines[2], "DB_PASSWORD=ENC[") || !strings.HasPrefix(lines[3], "export API_TOKEN=ENC[") {
+		t.Errorf("expected encrypted values, got %q", encoded)
+	}
+
+	reencoded, err := stage.Encode(encoded)
+	if err != nil || string(reencoded) != string(encoded) {
+		t.Errorf("expected encrypted values to be left as they are (%v)", err)
+	}
+
+	decoded, err := stage.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(decoded) != file {
+		t.Errorf("\nEXPECTED: %q \nACTUAL: %q", file, decoded)
+	}
+
+	// values cannot be swapped between keys
+	swapped := strings.Replace(string(encoded), lines[3][len("export API_TOKEN="):], lines[2][len("DB_PASSWORD="):], 1)
+
+	if _, err := stage.Decode([]byte(swapped)); err == nil {
+		t.Error("expected a value moved to another key to fail")
+	}
+}
@@ -0,0 +1,292 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/crypto"
+	"github.com/turnerlabs/cstore/components/local"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/setting"
+	"github.com/turnerlabs/cstore/components/vault"
+)
+
+const (
+	ageIdentityEnvVar = "CSTORE_AGE_IDENTITY"
+	ageIdentityName   = "age-identity.txt"
+)
+
+// envelopeStage encrypts files with a new data key wrapped for each
+// recipient recorded in the file's catalog entry. Recipients are only
+// asked for when the file has none; so, changing them means editing
+// the catalog and pushing the file again.
+type envelopeStage struct {
+	envelopeKeys
+
+	recipients []catalog.Recipient
+
+	// additional binds the encrypted file to its catalog entry.
+	additional []byte
+}
+
+func newEnvelopeStage(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) (envelopeStage, error) {
+	if len(file.Encryption.Recipients) == 0 {
+		recipients, err := PromptRecipients(clog, uo, io)
+		if err != nil {
+			return envelopeStage{}, err
+		}
+
+		file.Encryption.Recipients = recipients
+	}
+
+	return envelopeStage{
+		envelopeKeys: newEnvelopeKeys(clog, file, access, uo, io),
+		recipients:   file.Encryption.Recipients,
+		additional:   []byte(file.ContextKey(clog.Context)),
+	}, nil
+}
+
+// Encode ...
+func (e envelopeStage) Encode(data []byte) ([]byte, error) {
+	wrappers, err := e.wrappers(e.recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Seal(data, e.additional, wrappers)
+}
+
+// Decode ...
+func (e envelopeStage) Decode(data []byte) ([]byte, error) {
+	unwrappers, err := e.unwrappers(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Open(data, e.additional, unwrappers)
+}
+
+// envelopeKeys wraps and unwraps data keys for recipients. The KMS
+// client is created and the passphrase asked for only when a recipient
+// needs them.
+type envelopeKeys struct {
+	kms        func() (crypto.KMSClient, error)
+	kmsContext map[string]*string
+
+	passphrase func() (string, error)
+}
+
+func newEnvelopeKeys(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) envelopeKeys {
+	keys := envelopeKeys{
+		kmsContext: map[string]*string{
+			"cstore-file": aws.String(file.ContextKey(clog.Context)),
+		},
+	}
+
+	var svc crypto.KMSClient
+
+	keys.kms = func() (crypto.KMSClient, error) {
+		if svc != nil {
+			return svc, nil
+		}
+
+		if _, err := (setting.Setting{
+			Group:        "AWS",
+			Prop:         "REGION",
+			Prompt:       uo.Prompt,
+			AutoSave:     true,
+			DefaultValue: awsDefaultRegion,
+			Vault:        vault.EnvVault{},
+			Shared:       true,
+		}).Get(clog.Context, io); err != nil {
+			return nil, err
+		}
+
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, err
+		}
+
+		awsExplainDenied(sess)
+
+		svc = kms.New(sess)
+
+		return svc, nil
+	}
+
+	passphrase := ""
+
+	keys.passphrase = func() (string, error) {
+		if len(passphrase) > 0 {
+			return passphrase, nil
+		}
+
+		value, err := (setting.Setting{
+			Description: "Passphrase used to wrap the key encrypting the file. Anyone pulling the file without another recipient's key will need this passphrase.",
+			Group:       "CSTORE",
+			Prop:        "ENVELOPE_PASSPHRASE",
+			Prompt:      uo.Prompt,
+			HideInput:   true,
+			AutoSave:    true,
+			Vault:       access,
+		}).Get(clog.Context, io)
+
+		passphrase = value
+
+		return value, err
+	}
+
+	return keys
+}
+
+func (k envelopeKeys) wrappers(recipients []catalog.Recipient) ([]crypto.Wrapper, error) {
+	wrappers := []crypto.Wrapper{}
+
+	for _, r := range recipients {
+		switch r.Type {
+		case crypto.KMSRecipient:
+			svc, err := k.kms()
+			if err != nil {
+				return nil, err
+			}
+
+			wrappers = append(wrappers, crypto.NewKMSKey(svc, r.ID, k.kmsContext))
+
+		case crypto.AgeRecipient:
+			key, err := crypto.NewAgeKey(r.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			wrappers = append(wrappers, key)
+
+		case crypto.PassphraseRecipient:
+			wrappers = append(wrappers, crypto.Passphrase{Get: k.passphrase})
+
+		default:
+			return nil, fmt.Errorf("unknown recipient type %s, expected %s", r.Type, strings.Join(crypto.RecipientTypes, ", "))
+		}
+	}
+
+	return wrappers, nil
+}
+
+// unwrappers returns local age identities first, then KMS, and the
+// passphrase last; so, it is only asked for when neither can unwrap
+// the data key.
+func (k envelopeKeys) unwrappers(sealed []byte) ([]crypto.Unwrapper, error) {
+	envelope, err := crypto.Parse(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	types := map[string]bool{}
+	for _, key := range envelope.Keys {
+		types[key.Type] = true
+	}
+
+	unwrappers := []crypto.Unwrapper{}
+
+	if types[crypto.AgeRecipient] {
+		identities, err := ageIdentities()
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		for _, identity := range identities {
+			unwrappers = append(unwrappers, identity)
+		}
+	}
+
+	if types[crypto.KMSRecipient] {
+		svc, err := k.kms()
+		if err != nil {
+			return nil, err
+		}
+
+		unwrappers = append(unwrappers, crypto.NewKMSKey(svc, "", k.kmsContext))
+	}
+
+	if types[crypto.PassphraseRecipient] {
+		unwrappers = append(unwrappers, crypto.Passphrase{Get: k.passphrase})
+	}
+
+	return unwrappers, nil
+}
+
+// PromptRecipients asks for the recipients able to decrypt a file, or
+// reads them from CSTORE_ENVELOPE_RECIPIENTS.
+func PromptRecipients(clog catalog.Catalog, uo cfg.UserOptions, io models.IO) ([]catalog.Recipient, error) {
+	spec, err := (setting.Setting{
+		Description:  "Comma separated recipients able to decrypt the file, like 'kms:alias/my-app, age:age1..., passphrase'. Recipients are saved in the catalog.",
+		Group:        "CSTORE",
+		Prop:         "ENVELOPE_RECIPIENTS",
+		Prompt:       uo.Prompt,
+		DefaultValue: crypto.PassphraseRecipient,
+		Vault:        vault.EnvVault{},
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseRecipients(spec)
+}
+
+// ageIdentities reads the age identity file from CSTORE_AGE_IDENTITY
+// or the cstore home directory.
+func ageIdentities() ([]crypto.AgeIdentity, error) {
+	path := os.Getenv(ageIdentityEnvVar)
+	if len(path) == 0 {
+		path = local.BuildPath(ageIdentityName)
+	}
+
+	return crypto.ReadAgeIdentities(path)
+}
+
+// ParseRecipients reads recipients like "kms:alias/app, age:age1...,
+// passphrase". Only the first colon separates the type; so, KMS key
+// ARNs can be used.
+func ParseRecipients(spec string) ([]catalog.Recipient, error) {
+	recipients := []catalog.Recipient{}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+
+		parts := strings.SplitN(item, ":", 2)
+
+		r := catalog.Recipient{Type: strings.ToLower(parts[0])}
+		if len(parts) == 2 {
+			r.ID = strings.TrimSpace(parts[1])
+		}
+
+		switch r.Type {
+		case crypto.KMSRecipient, crypto.AgeRecipient:
+			if len(r.ID) == 0 {
+				return nil, fmt.Errorf("%s recipient requires a key, like %s:{key}", r.Type, r.Type)
+			}
+		case crypto.PassphraseRecipient:
+			r.ID = ""
+		default:
+			return nil, fmt.Errorf("unknown recipient type %s, expected %s", r.Type, strings.Join(crypto.RecipientTypes, ", "))
+		}
+
+		recipients = append(recipients, r)
+	}
+
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	return recipients, nil
+}
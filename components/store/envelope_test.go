@@ -0,0 +1,68 @@
+package store
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/crypto"
+)
+
+func TestParseRecipients(t *testing.T) {
+	recipients, err := ParseRecipients("kms:arn:aws:kms:us-east-1:123456789012:key/abc, AGE:age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p, passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []catalog.Recipient{
+		{Type: "kms", ID: "arn:aws:kms:us-east-1:123456789012:key/abc"},
+		{Type: "age", ID: "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"},
+		{Type: "passphrase"},
+	}
+
+	if len(recipients) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, recipients)
+	}
+
+	for i := range expected {
+		if recipients[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], recipients[i])
+		}
+	}
+
+	for _, spec := range []string{"", "kms", "age:", "gpg:ABCDEF"} {
+		if _, err := ParseRecipients(spec); err == nil {
+			t.Errorf("expected %q to be invalid", spec)
+		}
+	}
+}
+
+func TestEnvelopeStage(t *testing.T) {
+	stage := envelopeStage{
+		envelopeKeys: envelopeKeys{
+			passphrase: func() (string, error) { return "passphrase", nil },
+		},
+		recipients: []catalog.Recipient{{Type: crypto.PassphraseRecipient}},
+		additional: []byte("my-app/abc"),
+	}
+
+	encoded, err := stage.Encode([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := stage.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(decoded, []byte("data")) {
+		t.Errorf("expected data, got %q", decoded)
+	}
+
+	stage.additional = []byte("my-app/def")
+
+	if _, err := stage.Decode(encoded); err == nil {
+		t.Error("expected a file moved to another entry to fail")
+	}
+}
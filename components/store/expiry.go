@@ -0,0 +1,57 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+// RefreshWindow is how long before store credentials expire that they
+// are refreshed. It should be longer than a typical push or pull.
+var RefreshWindow = 5 * time.Minute
+
+// Refresh renews store credentials that expire within the refresh
+// window. Stores that do not use expiring credentials are ignored.
+func Refresh(st contract.IStore) error {
+	es, ok := st.(contract.IExpiringStore)
+	if !ok {
+		return nil
+	}
+
+	if !expiresSoon(es.Expires()) {
+		return nil
+	}
+
+	return es.Refresh()
+}
+
+func expiresSoon(expires time.Time) bool {
+	return !expires.IsZero() && time.Until(expires) < RefreshWindow
+}
+
+// tokenExpiry returns the "exp" claim when the token is a JWT or
+// time.Time{} when the expiry cannot be determined.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type expiringStore struct {
+	AkeylessStore
+
+	expires   time.Time
+	refreshed bool
+}
+
+func (s expiringStore) Expires() time.Time { return s.expires }
+
+func (s *expiringStore) Refresh() error {
+	s.refreshed = true
+	return nil
+}
+
+func TestRefreshRenewsCredentialsExpiringSoon(t *testing.T) {
+	tests := []struct {
+		expires time.Time
+		refresh bool
+	}{
+		{expires: time.Time{}, refresh: false},
+		{expires: time.Now().Add(time.Hour), refresh: false},
+		{expires: time.Now().Add(time.Minute), refresh: true},
+		{expires: time.Now().Add(-time.Minute), refresh: true},
+	}
+
+	for _, test := range tests {
+		// arrange
+		s := &expiringStore{expires: test.expires}
+
+		// act
+		if err := Refresh(s); err != nil {
+			t.Fatal(err)
+		}
+
+		// assert
+		if s.refreshed != test.refresh {
+			t.Errorf("\nEXPECTED: %t \nACTUAL: %t", test.refresh, s.refreshed)
+		}
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	// arrange
+	exp := time.Now().Add(time.Hour).Unix()
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"user","exp":%d}`, exp)))
+
+	// act
+	jwt := tokenExpiry("header." + payload + ".signature")
+	opaque := tokenExpiry("c2b4e1f0a9")
+
+	// assert
+	if jwt.Unix() != exp {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %d", exp, jwt.Unix())
+	}
+
+	if !opaque.IsZero() {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", time.Time{}, opaque)
+	}
+}
@@ -0,0 +1,289 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+// wrapper is implemented by stores wrapping another store.
+type wrapper interface {
+	Unwrap() contract.IStore
+}
+
+// Unwrap returns the store at the bottom of any wrappers. Wrappers
+// implement every optional interface; so, check the unwrapped store
+// to tell if a feature, like resuming pushes, is supported.
+func Unwrap(st contract.IStore) contract.IStore {
+	for {
+		w, ok := st.(wrapper)
+		if !ok {
+			return st
+		}
+
+		st = w.Unwrap()
+	}
+}
+
+// forwardingStore forwards the optional interfaces of the store it
+// wraps. Wrappers changing how files are saved embed it with the
+// conversions they make; so, a wrapped store keeps its features.
+type forwardingStore struct {
+	contract.IStore
+
+	// entry returns the file entry the wrapped store saves.
+	entry func(file *catalog.File) *catalog.File
+
+	// key returns the name the wrapped store saves a key under.
+	key func(key string) string
+
+	// encode converts a file before it is saved in the wrapped store
+	// and decode converts it back after it is retrieved.
+	encode func(file *catalog.File, data []byte) ([]byte, error)
+	decode func(file *catalog.File, data []byte) ([]byte, error)
+}
+
+// Unwrap ...
+func (s forwardingStore) Unwrap() contract.IStore {
+	return s.IStore
+}
+
+// Push ...
+func (s forwardingStore) Push(file *catalog.File, fileData []byte, version string) error {
+	encoded, err := s.encoded(file, fileData)
+	if err != nil {
+		return err
+	}
+
+	f := s.wrapped(file)
+	defer func() { file.Data = f.Data }()
+
+	return s.IStore.Push(f, encoded, version)
+}
+
+// Pull ...
+func (s forwardingStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+	data, attr, err := s.IStore.Pull(s.wrapped(file), version)
+	if err != nil {
+		return data, attr, err
+	}
+
+	decoded, err := s.decoded(file, data)
+
+	return decoded, attr, err
+}
+
+// PullAsOf ...
+func (s forwardingStore) PullAsOf(file *catalog.File, version string, asOf time.Time) ([]byte, contract.Attributes, error) {
+	historical, ok := s.IStore.(contract.IHistoricalStore)
+	if !ok {
+		return nil, contract.Attributes{}, fmt.Errorf("%s store does not keep file history", s.Name())
+	}
+
+	data, attr, err := historical.PullAsOf(s.wrapped(file), version, asOf)
+	if err != nil {
+		return data, attr, err
+	}
+
+	decoded, err := s.decoded(file, data)
+
+	return decoded, attr, err
+}
+
+// Revisions ...
+func (s forwardingStore) Revisions(file *catalog.File, version string) ([]contract.Revision, error) {
+	revisioned, ok := s.IStore.(contract.IRevisionStore)
+	if !ok {
+		return nil, fmt.Errorf("%s store does not keep file revisions", s.Name())
+	}
+
+	return revisioned.Revisions(s.wrapped(file), version)
+}
+
+// PullRevision ...
+func (s forwardingStore) PullRevision(file *catalog.File, version, revision string) ([]byte, contract.Attributes, error) {
+	revisioned, ok := s.IStore.(contract.IRevisionStore)
+	if !ok {
+		return nil, contract.Attributes{}, fmt.Errorf("%s store does not keep file revisions", s.Name())
+	}
+
+	data, attr, err := revisioned.PullRevision(s.wrapped(file), version, revision)
+	if err != nil {
+		return data, attr, err
+	}
+
+	decoded, err := s.decoded(file, data)
+
+	return decoded, attr, err
+}
+
+// Purge ...
+func (s forwardingStore) Purge(file *catalog.File, version string) error {
+	f := s.wrapped(file)
+	defer func() { file.Data = f.Data }()
+
+	return s.IStore.Purge(f, version)
+}
+
+// Changed ...
+func (s forwardingStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+	return s.IStore.Changed(s.wrapped(file), fileData, version)
+}
+
+// ETag ...
+func (s forwardingStore) ETag(file *catalog.File, version string) (string, error) {
+	if conditional, ok := s.IStore.(contract.IConditionalStore); ok {
+		return conditional.ETag(s.wrapped(file), version)
+	}
+
+	return "", nil
+}
+
+// KeysModified ...
+func (s forwardingStore) KeysModified(file *catalog.File, version string) (map[string]time.Time, error) {
+	keyStore, ok := s.IStore.(contract.IKeyStore)
+	if !ok {
+		return nil, fmt.Errorf("%s store does not track keys", s.Name())
+	}
+
+	return keyStore.KeysModified(s.wrapped(file), version)
+}
+
+// KeyHistory ...
+func (s forwardingStore) KeyHistory(file *catalog.File, key, version string) ([]contract.KeyChange, error) {
+	historical, ok := s.IStore.(contract.IKeyHistoryStore)
+	if !ok {
+		return nil, fmt.Errorf("%s store does not keep key history", s.Name())
+	}
+
+	return historical.KeyHistory(s.wrapped(file), s.wrappedKey(key), version)
+}
+
+// SetKeyTypes ...
+func (s forwardingStore) SetKeyTypes(file *catalog.File, types map[string]string, version string) error {
+	typeStore, ok := s.IStore.(contract.IKeyTypeStore)
+	if !ok {
+		return fmt.Errorf("%s store does not save key types", s.Name())
+	}
+
+	wrappedTypes := map[string]string{}
+	for key, keyType := range types {
+		wrappedTypes[s.wrappedKey(key)] = keyType
+	}
+
+	f := s.wrapped(file)
+	defer func() { file.Data = f.Data }()
+
+	return typeStore.SetKeyTypes(f, wrappedTypes, version)
+}
+
+// Resume ...
+func (s forwardingStore) Resume(file *catalog.File, fileData []byte, version string, keys []string) error {
+	resumable, ok := s.IStore.(contract.IResumableStore)
+	if !ok {
+		return fmt.Errorf("%s store cannot resume pushes", s.Name())
+	}
+
+	encoded, err := s.encoded(file, fileData)
+	if err != nil {
+		return err
+	}
+
+	wrappedKeys := []string{}
+	for _, key := range keys {
+		wrappedKeys = append(wrappedKeys, s.wrappedKey(key))
+	}
+
+	f := s.wrapped(file)
+	defer func() { file.Data = f.Data }()
+
+	return resumable.Resume(f, encoded, version, wrappedKeys)
+}
+
+// Reencrypt ...
+func (s forwardingStore) Reencrypt(file *catalog.File, oldKey, version string) ([]byte, error) {
+	reencrypter, ok := s.IStore.(contract.IReencryptingStore)
+	if !ok {
+		return nil, fmt.Errorf("%s store does not encrypt files client-side", s.Name())
+	}
+
+	f := s.wrapped(file)
+	defer func() { file.Data = f.Data }()
+
+	data, err := reencrypter.Reencrypt(f, oldKey, version)
+	if err != nil {
+		return data, err
+	}
+
+	return s.decoded(file, data)
+}
+
+// Expires ...
+func (s forwardingStore) Expires() time.Time {
+	if expiring, ok := s.IStore.(contract.IExpiringStore); ok {
+		return expiring.Expires()
+	}
+
+	return time.Time{}
+}
+
+// Refresh ...
+func (s forwardingStore) Refresh() error {
+	if expiring, ok := s.IStore.(contract.IExpiringStore); ok {
+		return expiring.Refresh()
+	}
+
+	return nil
+}
+
+// Encryption ...
+func (s forwardingStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	if described, ok := s.IStore.(contract.IEncryptionStore); ok {
+		return described.Encryption(s.wrapped(file), version)
+	}
+
+	return contract.Encryption{Mechanism: contract.EncryptionUnknown}, nil, nil
+}
+
+// Locate ...
+func (s forwardingStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+	if locatable, ok := s.IStore.(contract.ILocatableStore); ok {
+		return locatable.Locate(s.wrapped(file), s.wrappedKey(key), version)
+	}
+
+	return contract.Location{Remote: "unknown", Credentials: "unknown", Encryption: "unknown"}, nil
+}
+
+func (s forwardingStore) wrapped(file *catalog.File) *catalog.File {
+	if s.entry == nil {
+		return file
+	}
+
+	return s.entry(file)
+}
+
+func (s forwardingStore) wrappedKey(key string) string {
+	if s.key == nil || len(key) == 0 {
+		return key
+	}
+
+	return s.key(key)
+}
+
+func (s forwardingStore) encoded(file *catalog.File, data []byte) ([]byte, error) {
+	if s.encode == nil {
+		return data, nil
+	}
+
+	return s.encode(file, data)
+}
+
+func (s forwardingStore) decoded(file *catalog.File, data []byte) ([]byte, error) {
+	if s.decode == nil {
+		return data, nil
+	}
+
+	return s.decode(file, data)
+}
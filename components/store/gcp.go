@@ -0,0 +1,290 @@
+package store
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	gcpCredentialsFile = "GOOGLE_APPLICATION_CREDENTIALS"
+	gcpCloudProject    = "GOOGLE_CLOUD_PROJECT"
+
+	gcpScope       = "https://www.googleapis.com/auth/cloud-platform"
+	gcpTokenURL    = "https://oauth2.googleapis.com/token"
+	gcpMetadataURL = "http://metadata.google.internal/computeMetadata/v1"
+
+	gcpServiceAccount = "service_account"
+	gcpAuthorizedUser = "authorized_user"
+	gcpMetadata       = "metadata"
+)
+
+// gcpCredentials are Application Default Credentials found the same
+// way Google's client libraries find them.
+type gcpCredentials struct {
+	Type           string `json:"type"`
+	ProjectID      string `json:"project_id"`
+	QuotaProjectID string `json:"quota_project_id"`
+
+	// service_account
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+
+	// authorized_user
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+
+	source string
+}
+
+// gcpDefaultCredentials returns the credentials file set by
+// GOOGLE_APPLICATION_CREDENTIALS, the file written by 'gcloud auth
+// application-default login', or the metadata server when running on
+// Google Cloud.
+func gcpDefaultCredentials() (gcpCredentials, error) {
+	path := os.Getenv(gcpCredentialsFile)
+
+	if len(path) == 0 {
+		path = gcpWellKnownFile()
+
+		if _, err := os.Stat(path); err != nil {
+			return gcpCredentials{Type: gcpMetadata, source: "metadata server"}, nil
+		}
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return gcpCredentials{}, fmt.Errorf("could not read Google credentials (%s)", err)
+	}
+
+	creds := gcpCredentials{}
+	if err := json.Unmarshal(b, &creds); err != nil {
+		return creds, fmt.Errorf("could not parse Google credentials %s (%s)", path, err)
+	}
+
+	switch creds.Type {
+	case gcpServiceAccount, gcpAuthorizedUser:
+	default:
+		return creds, fmt.Errorf("unsupported Google credential type %s in %s", creds.Type, path)
+	}
+
+	creds.source = fmt.Sprintf("%s %s", strings.Replace(creds.Type, "_", " ", -1), path)
+
+	return creds, nil
+}
+
+func gcpWellKnownFile() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+	}
+
+	home, _ := homedir.Dir()
+
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// project returns the project the credentials belong to when known.
+func (c gcpCredentials) project() string {
+	if project := os.Getenv(gcpCloudProject); len(project) > 0 {
+		return project
+	}
+
+	if len(c.ProjectID) > 0 {
+		return c.ProjectID
+	}
+
+	if len(c.QuotaProjectID) > 0 {
+		return c.QuotaProjectID
+	}
+
+	if c.Type == gcpMetadata {
+		project, _ := gcpMetadataGet("project/project-id")
+		return project
+	}
+
+	return ""
+}
+
+// principal returns the identity the credentials act as when known.
+func (c gcpCredentials) principal() string {
+	switch c.Type {
+	case gcpServiceAccount:
+		return c.ClientEmail
+	case gcpMetadata:
+		if email, err := gcpMetadataGet("instance/service-accounts/default/email"); err == nil {
+			return email
+		}
+	}
+
+	return c.source
+}
+
+// token exchanges the credentials for an OAuth2 access token returning
+// when the token expires.
+func (c gcpCredentials) token() (string, time.Time, error) {
+	output := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+
+	var b []byte
+	var err error
+
+	switch c.Type {
+	case gcpServiceAccount:
+		assertion, aerr := c.assertion(time.Now())
+		if aerr != nil {
+			return "", time.Time{}, aerr
+		}
+
+		b, err = gcpPostForm(c.tokenURI(), url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		})
+
+	case gcpAuthorizedUser:
+		b, err = gcpPostForm(c.tokenURI(), url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {c.ClientID},
+			"client_secret": {c.ClientSecret},
+			"refresh_token": {c.RefreshToken},
+		})
+
+	default:
+		var token string
+		token, err = gcpMetadataGet("instance/service-accounts/default/token")
+		b = []byte(token)
+	}
+
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("could not get a Google access token using %s (%s)", c.source, err)
+	}
+
+	if err := json.Unmarshal(b, &output); err != nil {
+		return "", time.Time{}, err
+	}
+
+	if len(output.AccessToken) == 0 {
+		return "", time.Time{}, fmt.Errorf("no Google access token returned using %s", c.source)
+	}
+
+	return output.AccessToken, time.Now().Add(time.Duration(output.ExpiresIn) * time.Second), nil
+}
+
+func (c gcpCredentials) tokenURI() string {
+	if len(c.TokenURI) > 0 {
+		return c.TokenURI
+	}
+	return gcpTokenURL
+}
+
+// assertion returns a JWT signed with the service account's private key
+// requesting a token for the cloud-platform scope.
+func (c gcpCredentials) assertion(now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(c.PrivateKey))
+	if block == nil {
+		return "", errors.New("service account private key is not PEM encoded")
+	}
+
+	var key *rsa.PrivateKey
+
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return "", errors.New("service account private key is not an RSA key")
+		}
+		key = rsaKey
+	} else if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("could not parse service account private key (%s)", err)
+	}
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   c.ClientEmail,
+		"scope": gcpScope,
+		"aud":   c.tokenURI(),
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := fmt.Sprintf("%s.%s",
+		base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`)),
+		base64.RawURLEncoding.EncodeToString(claims))
+
+	sum := sha256.Sum256([]byte(unsigned))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s.%s", unsigned, base64.RawURLEncoding.EncodeToString(signature)), nil
+}
+
+func gcpPostForm(tokenURL string, values url.Values) ([]byte, error) {
+	resp, err := http.PostForm(tokenURL, values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	return b, nil
+}
+
+func gcpMetadataGet(path string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s", gcpMetadataURL, path), nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	client := http.Client{Timeout: 3 * time.Second}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("no credentials found, set %s or run 'gcloud auth application-default login' (%s)", gcpCredentialsFile, err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned %d %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,821 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/setting"
+)
+
+const (
+	gcpSecretManagerURL = "https://secretmanager.googleapis.com/v1"
+
+	gcpProjectToken    = "GCP_PROJECT"
+	gcpSecretModeToken = "GCP_SECRET_MODE"
+
+	// gcpSecretVersionEnv is the environment variable set to pull a
+	// specific version of a file saved in a single secret.
+	gcpSecretVersionEnv = "GCP_SECRET_VERSION"
+
+	gcpModeKey  = "key"
+	gcpModeFile = "file"
+
+	gcpLatest = "latest"
+
+	// gcpKeySeparator separates the file's secret id from the key name
+	// for secrets holding a single key.
+	gcpKeySeparator = "--"
+)
+
+var (
+	gcpInvalidIDChars = regexp.MustCompile("[^a-zA-Z0-9_-]")
+
+	errSecretNotFound = errors.New("secret not found, verify Secret Manager access")
+)
+
+// GCPSecretManagerStore ...
+type GCPSecretManagerStore struct {
+	context       string
+	project       string
+	mode          string
+	secretVersion string
+	endpoint      string
+
+	credentials gcpCredentials
+	token       string
+	expires     time.Time
+
+	io models.IO
+}
+
+// Name ...
+func (s GCPSecretManagerStore) Name() string {
+	return "gcp-secret-manager"
+}
+
+// SupportsFeature ...
+func (s GCPSecretManagerStore) SupportsFeature(feature string) bool {
+	switch feature {
+	case VersionFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFileType ...
+func (s GCPSecretManagerStore) SupportsFileType(fileType string) bool {
+	return true
+}
+
+// Description ...
+func (s GCPSecretManagerStore) Description() string {
+	return `
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/GCP_SECRET_MANAGER.md
+`
+}
+
+// Pre ...
+func (s *GCPSecretManagerStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.context = clog.Context
+	s.io = io
+	s.endpoint = gcpSecretManagerURL
+
+	s.secretVersion = os.Getenv(gcpSecretVersionEnv)
+	if len(s.secretVersion) == 0 {
+		s.secretVersion = gcpLatest
+	}
+
+	creds, err := gcpDefaultCredentials()
+	if err != nil {
+		return err
+	}
+
+	s.credentials = creds
+
+	project, err := (setting.Setting{
+		Description:  "Google Cloud project the secrets are saved in.",
+		Group:        "GCP",
+		Prop:         "PROJECT",
+		DefaultValue: clog.GetAnyDataBy(gcpProjectToken, creds.project()),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.project = project
+
+	s.mode = gcpModeFile
+
+	if file.SupportsConfig() {
+		mode, err := (setting.Setting{
+			Description:  fmt.Sprintf("OPTIONS\n %s (a secret for each key)\n %s (a single secret for the file)", gcpModeKey, gcpModeFile),
+			Group:        "GCP",
+			Prop:         "SECRET_MODE",
+			DefaultValue: gcpModeKey,
+			Prompt:       uo.Prompt,
+			AutoSave:     true,
+			Vault:        file,
+			Shared:       true,
+		}).Get(clog.Context, io)
+		if err != nil {
+			return err
+		}
+
+		if mode != gcpModeKey && mode != gcpModeFile {
+			return fmt.Errorf("unsupported %s %s, expected %s or %s", gcpSecretModeToken, mode, gcpModeKey, gcpModeFile)
+		}
+
+		s.mode = mode
+	}
+
+	return s.Refresh()
+}
+
+// Push ...
+func (s GCPSecretManagerStore) Push(file *catalog.File, fileData []byte, version string) error {
+
+	if len(fileData) == 0 {
+		return errors.New("empty file")
+	}
+
+	id := s.secretID(file.Path, version)
+
+	if s.mode == gcpModeFile {
+		return s.write(id, file.Path, "", version, fileData)
+	}
+
+	newParams := gotenv.Parse(bytes.NewReader(fileData))
+	if len(newParams) == 0 {
+		return errors.New("failed to parse environment variables")
+	}
+
+	for key := range newParams {
+		if gcpInvalidIDChars.MatchString(key) {
+			return fmt.Errorf("%s cannot be saved as a secret, keys can only contain letters, numbers, underscores, and dashes", key)
+		}
+	}
+
+	stored, err := s.keySecrets(id)
+	if err != nil {
+		return err
+	}
+
+	//------------------------------------------
+	//- Confirm deleting removed keys before
+	//- anything is changed
+	//------------------------------------------
+	removed := []string{}
+	for key, secret := range stored {
+		if _, found := newParams[key]; !found {
+			removed = append(removed, secret)
+		}
+	}
+	sort.Strings(removed)
+
+	if len(removed) > 0 {
+		msg := ""
+		for _, secret := range removed {
+			msg = fmt.Sprintf("%s  - %s\n", msg, secret)
+		}
+		msg = fmt.Sprintf("%s \n  Delete secrets for removed keys?", msg)
+
+		confirmed, err := prompt.Confirm(msg, prompt.Danger, s.io)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			return errors.New("user aborted")
+		}
+	}
+
+	for key, value := range newParams {
+		if err := s.write(id+gcpKeySeparator+key, file.Path, key, version, []byte(value)); err != nil {
+			return err
+		}
+	}
+
+	//------------------------------------------
+	//- Delete removed keys
+	//------------------------------------------
+	for _, secret := range removed {
+		if _, err := s.call(http.MethodDelete, s.secretName(secret), nil, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Pull ...
+func (s GCPSecretManagerStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+
+	id := s.secretID(file.Path, version)
+
+	if s.mode == gcpModeFile {
+		data, modified, err := s.access(id, s.secretVersion)
+		if err != nil {
+			return []byte{}, contract.Attributes{}, err
+		}
+
+		return data, contract.Attributes{LastModified: modified}, nil
+	}
+
+	if s.secretVersion != gcpLatest {
+		return []byte{}, contract.Attributes{}, fmt.Errorf("%s is only supported for files saved in a single secret, use --as-of to pull keys as they were at a time", gcpSecretVersionEnv)
+	}
+
+	stored, err := s.keySecrets(id)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	if len(stored) == 0 {
+		return []byte{}, contract.Attributes{}, errors.New("secrets not found, verify Secret Manager access")
+	}
+
+	values := map[string]string{}
+	lastModified := time.Time{}
+
+	for key, secret := range stored {
+		data, modified, err := s.access(secret, gcpLatest)
+		if err != nil {
+			return []byte{}, contract.Attributes{}, err
+		}
+
+		values[key] = string(data)
+
+		if modified.After(lastModified) {
+			lastModified = modified
+		}
+	}
+
+	return formatEnv(values), contract.Attributes{LastModified: lastModified}, nil
+}
+
+// PullAsOf ...
+func (s GCPSecretManagerStore) PullAsOf(file *catalog.File, version string, asOf time.Time) ([]byte, contract.Attributes, error) {
+
+	id := s.secretID(file.Path, version)
+
+	secrets := map[string]string{"": id}
+
+	if s.mode == gcpModeKey {
+		stored, err := s.keySecrets(id)
+		if err != nil {
+			return []byte{}, contract.Attributes{}, err
+		}
+		secrets = stored
+	}
+
+	//------------------------------------------
+	//- Use the last version of each secret
+	//- added at or before the requested time.
+	//------------------------------------------
+	values := map[string]string{}
+	lastModified := time.Time{}
+
+	for key, secret := range secrets {
+		v, found, err := s.versionAsOf(secret, asOf)
+		if err != nil {
+			return []byte{}, contract.Attributes{}, err
+		}
+
+		if !found {
+			continue
+		}
+
+		data, modified, err := s.access(secret, v)
+		if err != nil {
+			return []byte{}, contract.Attributes{}, err
+		}
+
+		if s.mode == gcpModeFile {
+			return data, contract.Attributes{LastModified: modified}, nil
+		}
+
+		values[key] = string(data)
+
+		if modified.After(lastModified) {
+			lastModified = modified
+		}
+	}
+
+	if len(values) == 0 {
+		return []byte{}, contract.Attributes{}, fmt.Errorf("secrets did not exist at %s", asOf.Format(time.RFC3339))
+	}
+
+	return formatEnv(values), contract.Attributes{LastModified: lastModified}, nil
+}
+
+// Purge ...
+func (s GCPSecretManagerStore) Purge(file *catalog.File, version string) error {
+
+	id := s.secretID(file.Path, version)
+
+	secrets := map[string]string{"": id}
+
+	if s.mode == gcpModeKey {
+		stored, err := s.keySecrets(id)
+		if err != nil {
+			return err
+		}
+		secrets = stored
+	}
+
+	for _, secret := range secrets {
+		status, err := s.call(http.MethodDelete, s.secretName(secret), nil, nil)
+		if err != nil && status != http.StatusNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Changed ...
+func (s GCPSecretManagerStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+	latest, err := s.latestVersions(file.Path, version)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	changed := time.Time{}
+	for _, v := range latest {
+		if v.CreateTime.After(changed) {
+			changed = v.CreateTime
+		}
+	}
+
+	return changed, nil
+}
+
+// ETag ...
+func (s GCPSecretManagerStore) ETag(file *catalog.File, version string) (string, error) {
+	latest, err := s.latestVersions(file.Path, version)
+	if err != nil || len(latest) == 0 {
+		return "", err
+	}
+
+	names := []string{}
+	for _, v := range latest {
+		names = append(names, v.Name)
+	}
+	sort.Strings(names)
+
+	return etagOf(names), nil
+}
+
+// KeyHistory ...
+func (s GCPSecretManagerStore) KeyHistory(file *catalog.File, key, version string) ([]contract.KeyChange, error) {
+
+	id := s.secretID(file.Path, version)
+
+	if s.mode == gcpModeKey {
+		id = id + gcpKeySeparator + key
+	}
+
+	versions, err := s.versions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, errSecretNotFound
+	}
+
+	//------------------------------------------
+	//- Each version of a key's secret is a
+	//- change. Files saved in a single secret
+	//- compare the key in each version.
+	//------------------------------------------
+	states := []keyState{}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+
+		state := keyState{
+			revision: v.number(),
+			modified: v.CreateTime,
+			value:    v.number(),
+			found:    true,
+		}
+
+		if s.mode == gcpModeFile {
+			if v.State != "ENABLED" {
+				continue
+			}
+
+			data, _, err := s.access(id, v.number())
+			if err != nil {
+				return nil, err
+			}
+
+			state.value, state.found = gotenv.Parse(bytes.NewReader(data))[key]
+		}
+
+		states = append(states, state)
+	}
+
+	return keyChanges(states), nil
+}
+
+// Encryption ...
+func (s GCPSecretManagerStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	location, err := s.Locate(file, "", version)
+	return contract.Encryption{Mechanism: contract.EncryptionSSE, Detail: location.Encryption}, nil, err
+}
+
+// Locate ...
+func (s GCPSecretManagerStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	id := s.secretID(file.Path, version)
+
+	switch {
+	case s.mode == gcpModeFile && len(key) > 0:
+		id = fmt.Sprintf("%s (key %s)", id, key)
+	case s.mode == gcpModeKey && len(key) > 0:
+		id = id + gcpKeySeparator + key
+	case s.mode == gcpModeKey:
+		id = id + gcpKeySeparator + "*"
+	}
+
+	return contract.Location{
+		Remote:      fmt.Sprintf("%s/%s", s.endpoint, s.secretName(id)),
+		Credentials: s.credentials.source,
+		Encryption:  "Google-managed encryption",
+	}, nil
+}
+
+// Expires ...
+func (s GCPSecretManagerStore) Expires() time.Time {
+	return s.expires
+}
+
+// Refresh ...
+func (s *GCPSecretManagerStore) Refresh() error {
+	token, expires, err := s.credentials.token()
+	if err != nil {
+		return err
+	}
+
+	s.token = token
+	s.expires = expires
+
+	return nil
+}
+
+func init() {
+	s := new(GCPSecretManagerStore)
+	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: gcpProjectToken, Required: true},
+		{Key: gcpSecretModeToken, Values: []string{gcpModeKey, gcpModeFile}},
+	})
+}
+
+//------------------------------------------
+//- Secret Manager API helpers.
+//------------------------------------------
+
+type gcpSecretVersion struct {
+	Name       string    `json:"name"`
+	CreateTime time.Time `json:"createTime"`
+	State      string    `json:"state"`
+}
+
+func (v gcpSecretVersion) number() string {
+	return v.Name[strings.LastIndex(v.Name, "/")+1:]
+}
+
+// secretID returns the id of the secret holding the file. Secret ids
+// only allow letters, numbers, underscores, and dashes; so, the file is
+// identified by a hash of its context, version, and path.
+func (s GCPSecretManagerStore) secretID(path, version string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s", s.context, version, path)))
+
+	return fmt.Sprintf("%s-%s", gcpInvalidIDChars.ReplaceAllString(s.context, "_"), hex.EncodeToString(sum[:8]))
+}
+
+func (s GCPSecretManagerStore) secretName(id string) string {
+	return fmt.Sprintf("projects/%s/secrets/%s", s.project, id)
+}
+
+// keySecrets returns the ids of the secrets holding each key of a file.
+func (s GCPSecretManagerStore) keySecrets(id string) (map[string]string, error) {
+	prefix := id + gcpKeySeparator
+	secrets := map[string]string{}
+
+	query := url.Values{
+		"filter":   {fmt.Sprintf("name:%s", prefix)},
+		"pageSize": {"250"},
+	}
+
+	for {
+		output := struct {
+			Secrets []struct {
+				Name string `json:"name"`
+			} `json:"secrets"`
+			NextPageToken string `json:"nextPageToken"`
+		}{}
+
+		if _, err := s.call(http.MethodGet, fmt.Sprintf("projects/%s/secrets?%s", s.project, query.Encode()), nil, &output); err != nil {
+			return nil, err
+		}
+
+		for _, secret := range output.Secrets {
+			secretID := secret.Name[strings.LastIndex(secret.Name, "/")+1:]
+
+			if strings.HasPrefix(secretID, prefix) {
+				secrets[strings.TrimPrefix(secretID, prefix)] = secretID
+			}
+		}
+
+		if len(output.NextPageToken) == 0 {
+			return secrets, nil
+		}
+
+		query.Set("pageToken", output.NextPageToken)
+	}
+}
+
+// write adds a version to the secret when the value changed creating
+// the secret when missing.
+func (s GCPSecretManagerStore) write(id, path, key, version string, value []byte) error {
+	stored, _, err := s.access(id, gcpLatest)
+
+	switch {
+	case err == nil && bytes.Equal(stored, value):
+		return nil
+	case err == errSecretNotFound:
+		annotations := map[string]string{
+			"cstore-context": s.context,
+			"cstore-path":    path,
+		}
+
+		if len(key) > 0 {
+			annotations["cstore-key"] = key
+		}
+
+		if len(version) > 0 {
+			annotations["cstore-version"] = version
+		}
+
+		status, err := s.call(http.MethodPost, fmt.Sprintf("projects/%s/secrets?secretId=%s", s.project, id), map[string]interface{}{
+			"replication": map[string]interface{}{"automatic": map[string]interface{}{}},
+			"annotations": annotations,
+		}, nil)
+		if err != nil && status != http.StatusConflict {
+			return err
+		}
+	case err != nil:
+		return err
+	}
+
+	_, err = s.call(http.MethodPost, s.secretName(id)+":addVersion", map[string]interface{}{
+		"payload": map[string]string{"data": base64.StdEncoding.EncodeToString(value)},
+	}, nil)
+
+	return err
+}
+
+// access returns the value of a secret version and when it was added.
+func (s GCPSecretManagerStore) access(id, version string) ([]byte, time.Time, error) {
+	output := struct {
+		Name    string `json:"name"`
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}{}
+
+	status, err := s.call(http.MethodGet, fmt.Sprintf("%s/versions/%s:access", s.secretName(id), version), nil, &output)
+	if status == http.StatusNotFound {
+		return nil, time.Time{}, errSecretNotFound
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(output.Payload.Data)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	v := gcpSecretVersion{}
+	if _, err := s.call(http.MethodGet, output.Name, nil, &v); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return data, v.CreateTime, nil
+}
+
+// latestVersions returns the latest version of each secret holding the
+// file.
+func (s GCPSecretManagerStore) latestVersions(path, version string) ([]gcpSecretVersion, error) {
+	id := s.secretID(path, version)
+
+	secrets := map[string]string{"": id}
+
+	if s.mode == gcpModeKey {
+		stored, err := s.keySecrets(id)
+		if err != nil {
+			return nil, err
+		}
+		secrets = stored
+	}
+
+	latest := []gcpSecretVersion{}
+
+	for _, secret := range secrets {
+		v := gcpSecretVersion{}
+
+		status, err := s.call(http.MethodGet, fmt.Sprintf("%s/versions/%s", s.secretName(secret), gcpLatest), nil, &v)
+		if status == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		latest = append(latest, v)
+	}
+
+	return latest, nil
+}
+
+// versionAsOf returns the last enabled version of a secret added at or
+// before the time.
+func (s GCPSecretManagerStore) versionAsOf(id string, asOf time.Time) (string, bool, error) {
+	versions, err := s.versions(id)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, v := range versions {
+		if v.State == "ENABLED" && !v.CreateTime.After(asOf) {
+			return v.number(), true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// versions returns the versions of a secret newest first. Missing
+// secrets have no versions.
+func (s GCPSecretManagerStore) versions(id string) ([]gcpSecretVersion, error) {
+	versions := []gcpSecretVersion{}
+	query := url.Values{"pageSize": {"250"}}
+
+	for {
+		output := struct {
+			Versions      []gcpSecretVersion `json:"versions"`
+			NextPageToken string             `json:"nextPageToken"`
+		}{}
+
+		status, err := s.call(http.MethodGet, fmt.Sprintf("%s/versions?%s", s.secretName(id), query.Encode()), nil, &output)
+		if status == http.StatusNotFound {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, output.Versions...)
+
+		if len(output.NextPageToken) == 0 {
+			return versions, nil
+		}
+
+		query.Set("pageToken", output.NextPageToken)
+	}
+}
+
+// call sends a request to the Secret Manager API returning the response
+// status. Responses other than 200 are returned as errors.
+func (s GCPSecretManagerStore) call(method, path string, input, output interface{}) (int, error) {
+	body := bytes.NewReader([]byte{})
+
+	if input != nil {
+		b, err := json.Marshal(input)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s", s.endpoint, path), body)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		return resp.StatusCode, contract.AccessDeniedError{
+			Action:    gcpPermission(method, path),
+			Resource:  gcpResource(path),
+			Principal: s.credentials.principal(),
+			Err:       fmt.Errorf("%d %s", resp.StatusCode, strings.TrimSpace(string(b))),
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("secret manager %s %s: %d %s", method, path, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	if output == nil {
+		return resp.StatusCode, nil
+	}
+
+	return resp.StatusCode, json.Unmarshal(b, output)
+}
+
+// gcpPermission returns the IAM permission a request needs.
+func gcpPermission(method, path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+
+	switch {
+	case strings.HasSuffix(path, ":access"):
+		return "secretmanager.versions.access"
+	case strings.HasSuffix(path, ":addVersion"):
+		return "secretmanager.versions.add"
+	case strings.HasSuffix(path, "/versions"):
+		return "secretmanager.versions.list"
+	case strings.Contains(path, "/versions/"):
+		return "secretmanager.versions.get"
+	case strings.HasSuffix(path, "/secrets") && method == http.MethodPost:
+		return "secretmanager.secrets.create"
+	case strings.HasSuffix(path, "/secrets"):
+		return "secretmanager.secrets.list"
+	case method == http.MethodDelete:
+		return "secretmanager.secrets.delete"
+	default:
+		return "secretmanager.secrets.get"
+	}
+}
+
+// gcpResource returns the secret or project a request acted on.
+func gcpResource(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+	path = strings.SplitN(path, ":", 2)[0]
+
+	if i := strings.Index(path, "/versions"); i > 0 {
+		return path[:i]
+	}
+
+	return strings.TrimSuffix(path, "/secrets")
+}
+
+// formatEnv returns the values as an env file sorted by key.
+func formatEnv(values map[string]string) []byte {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buffer bytes.Buffer
+
+	for _, key := range keys {
+		buffer.WriteString(fmt.Sprintf("%s=%s\n", key, values[key]))
+	}
+
+	return buffer.Bytes()
+}
@@ -0,0 +1,281 @@
+package store
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+type fakeSecretVersion struct {
+	data    string
+	created time.Time
+}
+
+// fakeSecretManager serves the Secret Manager endpoints used by the
+// store for project "my-project" with versions created a minute apart.
+func fakeSecretManager(t *testing.T, start time.Time) (*httptest.Server, map[string][]fakeSecretVersion) {
+	secrets := map[string][]fakeSecretVersion{}
+	prefix := "/projects/my-project/secrets"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+
+		switch {
+		case path == "" && r.Method == http.MethodGet:
+			filter := strings.TrimPrefix(r.URL.Query().Get("filter"), "name:")
+			list := []map[string]string{}
+			for id := range secrets {
+				if strings.Contains(id, filter) {
+					list = append(list, map[string]string{"name": "projects/123/secrets/" + id})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"secrets": list})
+
+		case path == "" && r.Method == http.MethodPost:
+			secrets[r.URL.Query().Get("secretId")] = []fakeSecretVersion{}
+			w.Write([]byte(`{}`))
+
+		case strings.HasSuffix(path, ":addVersion"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, "/"), ":addVersion")
+			input := struct {
+				Payload struct {
+					Data string `json:"data"`
+				} `json:"payload"`
+			}{}
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+				t.Fatal(err)
+			}
+			created := start.Add(time.Duration(len(secrets[id])) * time.Minute)
+			secrets[id] = append(secrets[id], fakeSecretVersion{data: input.Payload.Data, created: created})
+			w.Write([]byte(`{}`))
+
+		case r.Method == http.MethodDelete:
+			delete(secrets, strings.TrimPrefix(path, "/"))
+			w.Write([]byte(`{}`))
+
+		default:
+			parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+			versions, found := secrets[parts[0]]
+			if !found || len(versions) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			if len(parts) == 2 {
+				list := []map[string]interface{}{}
+				for i := len(versions) - 1; i >= 0; i-- {
+					list = append(list, map[string]interface{}{
+						"name":       fmt.Sprintf("projects/my-project/secrets/%s/versions/%d", parts[0], i+1),
+						"createTime": versions[i].created,
+						"state":      "ENABLED",
+					})
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"versions": list})
+				return
+			}
+
+			v := strings.TrimSuffix(parts[2], ":access")
+			n := len(versions)
+			if v != "latest" {
+				fmt.Sscan(v, &n)
+			}
+
+			name := fmt.Sprintf("projects/my-project/secrets/%s/versions/%d", parts[0], n)
+
+			if strings.HasSuffix(parts[2], ":access") {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"name":    name,
+					"payload": map[string]string{"data": versions[n-1].data},
+				})
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"name":       name,
+				"createTime": versions[n-1].created,
+				"state":      "ENABLED",
+			})
+		}
+	}))
+
+	return server, secrets
+}
+
+func TestGCPSecretManagerPushPullKeys(t *testing.T) {
+	// arrange
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	server, secrets := fakeSecretManager(t, start)
+	defer server.Close()
+
+	s := GCPSecretManagerStore{context: "my-app", project: "my-project", mode: gcpModeKey, secretVersion: gcpLatest, endpoint: server.URL, token: "test-token"}
+	s.io = models.IO{UserOutput: ioutil.Discard, UserInput: strings.NewReader("y\n")}
+	file := catalog.File{Path: ".env", Type: "env"}
+
+	// act
+	if err := s.Push(&file, []byte("A=1\nB=2\nC=3\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Push(&file, []byte("A=1\nB=20\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled, attr, err := s.Pull(&file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	historical, _, err := s.PullAsOf(&file, "", start)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 2 {
+		t.Errorf("\nEXPECTED: %d secrets \nACTUAL: %d", 2, len(secrets))
+	}
+
+	if len(secrets[s.secretID(".env", "")+"--A"]) != 1 {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %d versions", "unchanged key skipped", len(secrets[s.secretID(".env", "")+"--A"]))
+	}
+
+	if string(pulled) != "A=1\nB=20\n" {
+		t.Errorf("\nEXPECTED: %q \nACTUAL: %q", "A=1\nB=20\n", string(pulled))
+	}
+
+	if !attr.LastModified.Equal(start.Add(time.Minute)) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", start.Add(time.Minute), attr.LastModified)
+	}
+
+	if string(historical) != "A=1\nB=2\n" {
+		t.Errorf("\nEXPECTED: %q \nACTUAL: %q", "A=1\nB=2\n", string(historical))
+	}
+}
+
+func TestGCPSecretManagerPushKeepsRemovedKeysWhenDeclined(t *testing.T) {
+	// arrange
+	server, secrets := fakeSecretManager(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	defer server.Close()
+
+	s := GCPSecretManagerStore{context: "my-app", project: "my-project", mode: gcpModeKey, secretVersion: gcpLatest, endpoint: server.URL, token: "test-token"}
+	s.io = models.IO{UserOutput: ioutil.Discard, UserInput: strings.NewReader("n\n")}
+	file := catalog.File{Path: ".env", Type: "env"}
+
+	if err := s.Push(&file, []byte("A=1\nB=2\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err := s.Push(&file, []byte("A=10\n"), "")
+
+	// assert
+	if err == nil || err.Error() != "user aborted" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "user aborted", err)
+	}
+
+	if len(secrets) != 2 || len(secrets[s.secretID(".env", "")+"--A"]) != 1 {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "no changes", secrets)
+	}
+}
+
+func TestGCPSecretManagerPullFileVersion(t *testing.T) {
+	// arrange
+	server, _ := fakeSecretManager(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	defer server.Close()
+
+	s := GCPSecretManagerStore{context: "my-app", project: "my-project", mode: gcpModeFile, secretVersion: gcpLatest, endpoint: server.URL, token: "test-token"}
+	file := catalog.File{Path: "config.json", Type: "json"}
+
+	if err := s.Push(&file, []byte(`{"a":1}`), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Push(&file, []byte(`{"a":2}`), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	s.secretVersion = "1"
+	pulled, _, err := s.Pull(&file, "")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(pulled) != `{"a":1}` {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", `{"a":1}`, string(pulled))
+	}
+}
+
+func TestGCPServiceAccountToken(t *testing.T) {
+	// arrange
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(r.FormValue("assertion"), ".")
+		if len(parts) != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], signature); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	creds := gcpCredentials{
+		Type:        gcpServiceAccount,
+		ClientEmail: "cstore@my-project.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		TokenURI:    server.URL,
+	}
+
+	// act
+	token, expires, err := creds.token()
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "test-token" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "test-token", token)
+	}
+
+	if expires.Before(time.Now().Add(59 * time.Minute)) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "expires in an hour", expires)
+	}
+}
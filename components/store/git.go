@@ -0,0 +1,294 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/local"
+)
+
+//------------------------------------------
+//- A local bare clone of the remote branch
+//- updated with git plumbing commands; so,
+//- files are committed without a checkout.
+//------------------------------------------
+
+const (
+	gitCLI = "git"
+
+	gitPushAttempts = 3
+)
+
+// gitFetching serializes fetches; so, files pulled concurrently do not
+// update the same local clone at once.
+var gitFetching sync.Mutex
+
+type gitRepo struct {
+	dir    string
+	remote string
+	branch string
+
+	synced bool
+}
+
+// openGitRepo returns the local clone of the remote, creating it in
+// the cstore folder the first time the remote is used.
+func openGitRepo(remote, branch string) (*gitRepo, error) {
+	sum := sha256.Sum256([]byte(remote))
+
+	r := &gitRepo{
+		dir:    local.BuildPath(filepath.Join("git", hex.EncodeToString(sum[:8]))),
+		remote: remote,
+		branch: branch,
+	}
+
+	if _, err := os.Stat(filepath.Join(r.dir, "HEAD")); err == nil {
+		return r, nil
+	}
+
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.git(nil, nil, "init", "--bare", "--quiet"); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *gitRepo) ref() string {
+	return "refs/heads/" + r.branch
+}
+
+func (r *gitRepo) git(stdin []byte, env []string, args ...string) ([]byte, error) {
+	c := exec.Command(gitCLI, append([]string{"--git-dir", r.dir}, args...)...)
+	c.Env = append(os.Environ(), env...)
+
+	if stdin != nil {
+		c.Stdin = bytes.NewReader(stdin)
+	}
+
+	return run(c)
+}
+
+// sync fetches the branch once; later reads use the local clone.
+func (r *gitRepo) sync() error {
+	if r.synced {
+		return nil
+	}
+
+	if err := r.fetch(); err != nil {
+		return err
+	}
+
+	r.synced = true
+
+	return nil
+}
+
+// fetch replaces the local branch with the remote branch. A branch
+// missing remotely is removed locally; so, the first push creates it.
+func (r *gitRepo) fetch() error {
+	gitFetching.Lock()
+	defer gitFetching.Unlock()
+
+	_, err := r.git(nil, nil, "fetch", "--quiet", "--no-tags", r.remote, fmt.Sprintf("+%s:%s", r.ref(), r.ref()))
+	if err != nil && strings.Contains(err.Error(), "couldn't find remote ref") {
+		r.git(nil, nil, "update-ref", "-d", r.ref())
+		return nil
+	}
+
+	return err
+}
+
+// resolve returns the object name of the revision or false when it
+// does not exist.
+func (r *gitRepo) resolve(rev string) (string, bool, error) {
+	out, err := r.git(nil, nil, "rev-parse", "--quiet", "--verify", rev)
+	if _, missing := err.(*exec.ExitError); missing {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, err
+	}
+
+	return strings.TrimSpace(string(out)), true, nil
+}
+
+// head returns the latest commit on the branch or false before the
+// first push.
+func (r *gitRepo) head() (string, bool, error) {
+	if err := r.sync(); err != nil {
+		return "", false, err
+	}
+
+	return r.resolve(r.ref())
+}
+
+// read returns the file at the path in the commit or false when the
+// commit does not have the file.
+func (r *gitRepo) read(commit, p string) ([]byte, bool, error) {
+	if len(commit) == 0 {
+		return nil, false, nil
+	}
+
+	blob, found, err := r.resolve(fmt.Sprintf("%s:%s", commit, p))
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	data, err := r.git(nil, nil, "cat-file", "blob", blob)
+
+	return data, true, err
+}
+
+// modified returns when the path last changed in the history of the
+// commit or zero when the path was never committed.
+func (r *gitRepo) modified(commit, p string) (time.Time, error) {
+	out, err := r.git(nil, nil, "log", "-1", "--format=%ct", commit, "--", p)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if len(bytes.TrimSpace(out)) == 0 {
+		return time.Time{}, nil
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(seconds, 0), nil
+}
+
+// asOf returns the latest commit on the branch made at or before the
+// time or false when there is none.
+func (r *gitRepo) asOf(t time.Time) (string, bool, error) {
+	if _, found, err := r.head(); !found || err != nil {
+		return "", found, err
+	}
+
+	out, err := r.git(nil, nil, "rev-list", "-1", fmt.Sprintf("--before=%d", t.Unix()), r.ref())
+	if err != nil {
+		return "", false, err
+	}
+
+	commit := strings.TrimSpace(string(out))
+
+	return commit, len(commit) > 0, nil
+}
+
+// commit saves the data at the path, or removes the path when data is
+// nil, and pushes the new commit to the branch. When the branch changed
+// remotely, the commit is made again on the latest remote commit.
+func (r *gitRepo) commit(p string, data []byte, message string) (string, error) {
+	for attempt := 1; ; attempt++ {
+		commit, err := r.tryCommit(p, data, message)
+		if err == nil {
+			return commit, nil
+		}
+
+		if !strings.Contains(err.Error(), "rejected") || attempt == gitPushAttempts {
+			return "", err
+		}
+
+		if err := r.fetch(); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (r *gitRepo) tryCommit(p string, data []byte, message string) (string, error) {
+	parent, found, err := r.head()
+	if err != nil {
+		return "", err
+	}
+
+	index, err := ioutil.TempFile("", "cstore-git-index")
+	if err != nil {
+		return "", err
+	}
+	index.Close()
+	os.Remove(index.Name())
+	defer os.Remove(index.Name())
+
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	//------------------------------------------
+	//- Build the tree from the latest commit
+	//------------------------------------------
+	if found {
+		_, err = r.git(nil, env, "read-tree", parent)
+	} else {
+		_, err = r.git(nil, env, "read-tree", "--empty")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if data != nil {
+		blob, err := r.git(data, nil, "hash-object", "-w", "--stdin")
+		if err != nil {
+			return "", err
+		}
+
+		_, err = r.git(nil, env, "update-index", "--add", "--cacheinfo", fmt.Sprintf("100644,%s,%s", strings.TrimSpace(string(blob)), p))
+		if err != nil {
+			return "", err
+		}
+	} else {
+		// mode 0 removes the path without a work tree
+		_, err := r.git([]byte(fmt.Sprintf("0 %s\t%s\n", strings.Repeat("0", 40), p)), env, "update-index", "--index-info")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	out, err := r.git(nil, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	tree := strings.TrimSpace(string(out))
+
+	//------------------------------------------
+	//- Skip the commit when nothing changed
+	//------------------------------------------
+	args := []string{"commit-tree", tree, "-m", message}
+
+	if found {
+		if parentTree, _, err := r.resolve(parent + "^{tree}"); err != nil || parentTree == tree {
+			return parent, err
+		}
+
+		args = append(args, "-p", parent)
+	}
+
+	out, err = r.git(nil, nil, args...)
+	if err != nil {
+		return "", err
+	}
+	commit := strings.TrimSpace(string(out))
+
+	if _, err := r.git(nil, nil, "push", "--quiet", r.remote, fmt.Sprintf("%s:%s", commit, r.ref())); err != nil {
+		return "", err
+	}
+
+	if _, err := r.git(nil, nil, "update-ref", r.ref(), commit); err != nil {
+		return "", err
+	}
+
+	return commit, nil
+}
@@ -0,0 +1,430 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/cipher"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/setting"
+)
+
+const (
+	gitRemoteToken = "GIT_REMOTE"
+	gitBranchToken = "GIT_BRANCH"
+	gitPinToken    = "GIT_PIN"
+
+	gitDefaultBranch = "cstore"
+
+	// gitPinCommand pins the pushed commit in the catalog.
+	gitPinCommand = "pin"
+)
+
+// GitStore ...
+type GitStore struct {
+	context  string
+	settings map[string]setting.Setting
+	pin      bool
+
+	repo *gitRepo
+
+	io models.IO
+}
+
+// Name ...
+func (s GitStore) Name() string {
+	return "git"
+}
+
+// SupportsFeature ...
+func (s GitStore) SupportsFeature(feature string) bool {
+	switch feature {
+	case VersionFeature, FIPSFeature, HashedKeyFeature, PipelineFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFileType ...
+func (s GitStore) SupportsFileType(fileType string) bool {
+	return true
+}
+
+// Description ...
+func (s GitStore) Description() string {
+	return `
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/GIT.md
+`
+}
+
+// Pre ...
+func (s *GitStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.settings = map[string]setting.Setting{}
+	s.context = clog.Context
+	s.pin = uo.StoreCommand == gitPinCommand
+	s.io = io
+
+	_, pushed := file.Data[gitRemoteToken]
+
+	if _, err := exec.LookPath(gitCLI); err != nil {
+		return errors.New("git CLI (git) not found, install it and configure access to the repository before using this store")
+	}
+
+	//------------------------------------------
+	//- Store Configuration
+	//------------------------------------------
+	remote, err := (setting.Setting{
+		Description:  "Git repository files are committed to. (example: git@github.com:org/config.git)",
+		Group:        "GIT",
+		Prop:         "REMOTE",
+		DefaultValue: clog.GetAnyDataBy(gitRemoteToken, ""),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	if len(remote) == 0 {
+		return fmt.Errorf("%s is required", gitRemoteToken)
+	}
+
+	branch, err := (setting.Setting{
+		Description:  "Branch files are committed to. The branch is created by the first push.",
+		Group:        "GIT",
+		Prop:         "BRANCH",
+		DefaultValue: clog.GetAnyDataBy(gitBranchToken, gitDefaultBranch),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	if s.repo, err = openGitRepo(remote, branch); err != nil {
+		return err
+	}
+
+	//------------------------------------------
+	//- Encryption
+	//------------------------------------------
+	if !pushed {
+		if _, err := access.Get(clog.Context, "CSTORE", "MASTER_KEY"); err == nil {
+			file.AddData(map[string]string{
+				keyDerivationToken: keyDerivationHKDF,
+			})
+		}
+	}
+
+	if file.Data[keyDerivationToken] == keyDerivationHKDF {
+		s.settings[masterKeyToken] = setting.Setting{
+			Description: "Team master key used to derive a separate encryption key for each file. Anyone pulling the file will need this key.",
+			Group:       "CSTORE",
+			Prop:        "MASTER_KEY",
+			Prompt:      uo.Prompt,
+			HideInput:   true,
+			AutoSave:    true,
+			Vault:       access,
+		}
+
+		return nil
+	}
+
+	s.settings[clientEncryptionToken] = setting.Setting{
+		Description:  "32 character key used to encrypt the file before it is committed. Anyone pulling the file will need this key.",
+		Group:        "CSTORE",
+		Prop:         "ENCRYPTION_KEY",
+		Prompt:       uo.Prompt,
+		HideInput:    true,
+		AutoSave:     true,
+		DefaultValue: cipher.GenerateAES256Key(),
+		Vault:        access,
+	}
+
+	return nil
+}
+
+// Push ...
+func (s GitStore) Push(file *catalog.File, fileData []byte, version string) error {
+
+	if len(fileData) == 0 {
+		return errors.New("empty file")
+	}
+
+	encrypted, err := s.encrypt(file, version, fileData)
+	if err != nil {
+		return err
+	}
+
+	commit, err := s.repo.commit(s.path(file, version), encrypted, fmt.Sprintf("Push %s", s.path(file, version)))
+	if err != nil {
+		return err
+	}
+
+	//------------------------------------------
+	//- Pin the pushed commit in the catalog
+	//------------------------------------------
+	if s.pin {
+		file.AddData(map[string]string{
+			pinKey(version): commit,
+		})
+	} else {
+		delete(file.Data, pinKey(version))
+	}
+
+	return nil
+}
+
+// Pull ...
+func (s GitStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+
+	commit, err := s.commit(file, version)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	return s.pullFrom(file, commit, version)
+}
+
+// PullAsOf ...
+func (s GitStore) PullAsOf(file *catalog.File, version string, asOf time.Time) ([]byte, contract.Attributes, error) {
+
+	commit, found, err := s.repo.asOf(asOf)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	if !found {
+		return []byte{}, contract.Attributes{}, fmt.Errorf("%s was not committed to %s as of %s", file.Path, s.repo.branch, asOf.Format(time.RFC3339))
+	}
+
+	return s.pullFrom(file, commit, version)
+}
+
+// Purge ...
+func (s GitStore) Purge(file *catalog.File, version string) error {
+
+	if _, found, err := s.repo.head(); !found || err != nil {
+		return err
+	}
+
+	if _, err := s.repo.commit(s.path(file, version), nil, fmt.Sprintf("Purge %s", s.path(file, version))); err != nil {
+		return err
+	}
+
+	delete(file.Data, pinKey(version))
+
+	return nil
+}
+
+// Changed ...
+func (s GitStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+
+	commit, err := s.commit(file, version)
+	if err != nil || len(commit) == 0 {
+		return time.Time{}, err
+	}
+
+	if _, found, err := s.repo.read(commit, s.path(file, version)); !found || err != nil {
+		return time.Time{}, err
+	}
+
+	return s.repo.modified(commit, s.path(file, version))
+}
+
+// ETag ...
+func (s GitStore) ETag(file *catalog.File, version string) (string, error) {
+
+	commit, err := s.commit(file, version)
+	if err != nil || len(commit) == 0 {
+		return "", err
+	}
+
+	blob, _, err := s.repo.resolve(fmt.Sprintf("%s:%s", commit, s.path(file, version)))
+
+	return blob, err
+}
+
+// Reencrypt ...
+func (s GitStore) Reencrypt(file *catalog.File, oldKey, version string) ([]byte, error) {
+
+	commit, err := s.commit(file, version)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	encrypted, found, err := s.repo.read(commit, s.path(file, version))
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if !found {
+		return []byte{}, fmt.Errorf("%s not found on %s", s.path(file, version), s.repo.branch)
+	}
+
+	key, err := s.key(file)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	b, rotated, err := rotate(*file, version, encrypted, key, oldKey, s.context)
+	if err != nil || !rotated {
+		return b, err
+	}
+
+	return b, s.Push(file, b, version)
+}
+
+// Encryption ...
+func (s GitStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	location, err := s.Locate(file, "", version)
+	return contract.Encryption{Mechanism: contract.EncryptionClientSide, Detail: location.Encryption}, nil, err
+}
+
+// Locate ...
+func (s GitStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	location := contract.Location{
+		Remote:      fmt.Sprintf("%s (branch %s) %s", s.repo.remote, s.repo.branch, s.path(file, version)),
+		Credentials: "git credentials used by the git CLI",
+		Encryption:  fmt.Sprintf("client-side AES-256 key %s from %s vault", ceKeyName, file.Vaults.Access),
+	}
+
+	if file.Data[keyDerivationToken] == keyDerivationHKDF {
+		location.Encryption = fmt.Sprintf("client-side AES-256 key derived (%s) for this file from %s in %s vault", keyDerivationHKDF, mkKeyName, file.Vaults.Access)
+	}
+
+	if pin, found := file.Data[pinKey(version)]; found {
+		location.Remote = fmt.Sprintf("%s (pinned to %s)", location.Remote, pin)
+	}
+
+	return location, nil
+}
+
+func init() {
+	s := new(GitStore)
+	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: gitRemoteToken, Required: true},
+		{Key: gitBranchToken},
+		{Key: gitPinToken, Prefix: true},
+		{Key: keyDerivationToken, Values: []string{keyDerivationHKDF}},
+		{Key: keyIDToken, Prefix: true},
+	})
+}
+
+// ------------------------------------------
+// - Repository helpers.
+// ------------------------------------------
+func (s GitStore) path(file *catalog.File, version string) string {
+	return path.Join(s.context, version, filepath.ToSlash(file.Path))
+}
+
+// commit returns the pinned commit or the latest commit on the branch.
+// Before the first push, no commit is returned.
+func (s GitStore) commit(file *catalog.File, version string) (string, error) {
+	head, _, err := s.repo.head()
+	if err != nil {
+		return "", err
+	}
+
+	pin, pinned := file.Data[pinKey(version)]
+	if !pinned {
+		return head, nil
+	}
+
+	commit, found, err := s.repo.resolve(pin + "^{commit}")
+	if err != nil {
+		return "", err
+	}
+
+	if !found {
+		return "", fmt.Errorf("pinned commit %s is not on %s, push the file again or remove %s from the catalog", pin, s.repo.branch, pinKey(version))
+	}
+
+	return commit, nil
+}
+
+func (s GitStore) pullFrom(file *catalog.File, commit, version string) ([]byte, contract.Attributes, error) {
+	p := s.path(file, version)
+
+	encrypted, found, err := s.repo.read(commit, p)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	if !found {
+		return []byte{}, contract.Attributes{}, fmt.Errorf("%s not found on %s", p, s.repo.branch)
+	}
+
+	b, err := s.decrypt(file, encrypted)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	modified, err := s.repo.modified(commit, p)
+
+	return b, contract.Attributes{
+		LastModified: modified,
+	}, err
+}
+
+func (s GitStore) setting(token string) (string, error) {
+	return s.settings[token].Get(s.context, s.io)
+}
+
+func (s GitStore) encrypt(file *catalog.File, version string, data []byte) ([]byte, error) {
+	key, err := s.key(file)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	recordKey(file, version, key)
+
+	return cipher.Encrypt(key, data)
+}
+
+func (s GitStore) decrypt(file *catalog.File, data []byte) ([]byte, error) {
+	key, err := s.key(file)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return cipher.Decrypt(key, data)
+}
+
+// key returns the file's encryption key, deriving it from the team
+// master key when the file was pushed with key derivation.
+func (s GitStore) key(file *catalog.File) (string, error) {
+	if file.Data[keyDerivationToken] != keyDerivationHKDF {
+		return s.setting(clientEncryptionToken)
+	}
+
+	master, err := s.setting(masterKeyToken)
+	if err != nil {
+		return "", err
+	}
+
+	return deriveFileKey(master, s.context, *file)
+}
+
+func pinKey(version string) string {
+	if len(version) > 0 {
+		return fmt.Sprintf("%s_%s", gitPinToken, strings.ToUpper(version))
+	}
+	return gitPinToken
+}
@@ -0,0 +1,282 @@
+package store
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cipher"
+	"github.com/turnerlabs/cstore/components/setting"
+	"github.com/turnerlabs/cstore/components/vault"
+)
+
+// testGitRemote creates an empty bare repository to push to and sets
+// the commit author used by git.
+func testGitRemote(t *testing.T) (string, func()) {
+	if _, err := exec.LookPath(gitCLI); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "cstore-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range map[string]string{
+		"GIT_AUTHOR_NAME":       "cstore",
+		"GIT_AUTHOR_EMAIL":      "cstore@example.com",
+		"GIT_COMMITTER_NAME":    "cstore",
+		"GIT_COMMITTER_EMAIL":   "cstore@example.com",
+		"CSTORE_ENCRYPTION_KEY": "0123456789abcdef0123456789abcdef",
+	} {
+		os.Setenv(k, v)
+	}
+
+	if out, err := exec.Command(gitCLI, "init", "--bare", "--quiet", filepath.Join(dir, "remote.git")).CombinedOutput(); err != nil {
+		t.Fatal(string(out))
+	}
+
+	return filepath.Join(dir, "remote.git"), func() { os.RemoveAll(dir) }
+}
+
+// testGitStore returns a store with its own local clone; so, separate
+// stores act like separate machines.
+func testGitStore(t *testing.T, remote string) GitStore {
+	home, err := ioutil.TempDir(filepath.Dir(remote), "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("HOME", home)
+	homedir.Reset()
+
+	repo, err := openGitRepo(remote, gitDefaultBranch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return GitStore{
+		context: "my-app",
+		repo:    repo,
+		settings: map[string]setting.Setting{
+			clientEncryptionToken: {Group: "CSTORE", Prop: "ENCRYPTION_KEY", Vault: vault.EnvVault{}},
+		},
+	}
+}
+
+func TestGitPushPull(t *testing.T) {
+	// arrange
+	remote, cleanup := testGitRemote(t)
+	defer cleanup()
+
+	s := testGitStore(t, remote)
+
+	file := catalog.File{Path: "config/.env", Type: "env", Data: map[string]string{}}
+	data := []byte("A=1\n")
+
+	// act
+	if err := s.Push(&file, []byte("A=0\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Push(&file, data, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled, attr, err := testGitStore(t, remote).Pull(&file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if string(pulled) != string(data) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", data, pulled)
+	}
+
+	if attr.LastModified.IsZero() {
+		t.Error("last modified not set")
+	}
+
+	head, _, _ := s.repo.head()
+	committed, _, _ := s.repo.read(head, "my-app/config/.env")
+
+	if bytes.Contains(committed, data) {
+		t.Error("file committed without encryption")
+	}
+}
+
+func TestGitPin(t *testing.T) {
+	// arrange
+	remote, cleanup := testGitRemote(t)
+	defer cleanup()
+
+	s := testGitStore(t, remote)
+	s.pin = true
+
+	pinned := catalog.File{Path: ".env", Type: "env", Data: map[string]string{}}
+	latest := catalog.File{Path: ".env", Type: "env", Data: map[string]string{}}
+
+	if err := s.Push(&pinned, []byte("A=1\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	other := testGitStore(t, remote)
+
+	if err := other.Push(&latest, []byte("A=2\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	fromPin, _, err := other.Pull(&pinned, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromLatest, _, err := other.Pull(&latest, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if len(pinned.Data[gitPinToken]) != 40 {
+		t.Errorf("\nEXPECTED: commit \nACTUAL: %s", pinned.Data[gitPinToken])
+	}
+
+	if string(fromPin) != "A=1\n" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "A=1", fromPin)
+	}
+
+	if string(fromLatest) != "A=2\n" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "A=2", fromLatest)
+	}
+}
+
+func TestGitPushAfterRemoteChange(t *testing.T) {
+	// arrange
+	remote, cleanup := testGitRemote(t)
+	defer cleanup()
+
+	first := testGitStore(t, remote)
+	second := testGitStore(t, remote)
+
+	api := catalog.File{Path: "api/.env", Type: "env", Data: map[string]string{}}
+	web := catalog.File{Path: "web/.env", Type: "env", Data: map[string]string{}}
+
+	if err := first.Push(&api, []byte("A=1\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := second.Push(&web, []byte("B=1\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err := first.Push(&api, []byte("A=2\n"), "")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	third := testGitStore(t, remote)
+
+	for file, expected := range map[*catalog.File]string{&api: "A=2\n", &web: "B=1\n"} {
+		pulled, _, err := third.Pull(file, "")
+		if err != nil || string(pulled) != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s %v", expected, pulled, err)
+		}
+	}
+}
+
+func TestGitPurgeVersion(t *testing.T) {
+	// arrange
+	remote, cleanup := testGitRemote(t)
+	defer cleanup()
+
+	s := testGitStore(t, remote)
+
+	file := catalog.File{Path: ".env", Type: "env", Data: map[string]string{}}
+
+	if err := s.Push(&file, []byte("A=1\n"), "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err := s.Purge(&file, "v1")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := s.Changed(&file, nil, "v1")
+	if err != nil || !changed.IsZero() {
+		t.Errorf("\nEXPECTED: zero \nACTUAL: %s %v", changed, err)
+	}
+
+	if _, _, err := s.Pull(&file, "v1"); err == nil {
+		t.Error("purged file pulled")
+	}
+}
+
+func TestGitReencrypt(t *testing.T) {
+	// arrange
+	remote, cleanup := testGitRemote(t)
+	defer cleanup()
+	defer os.Setenv("CSTORE_ENCRYPTION_KEY", os.Getenv("CSTORE_ENCRYPTION_KEY"))
+
+	s := testGitStore(t, remote)
+
+	oldKey := os.Getenv("CSTORE_ENCRYPTION_KEY")
+	newKey := "fedcba9876543210fedcba9876543210"
+
+	file := catalog.File{Path: ".env", Type: "env", Data: map[string]string{}}
+	data := []byte("A=1\n")
+
+	if err := s.Push(&file, data, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	head, _, _ := s.repo.head()
+	before, _, _ := s.repo.read(head, "my-app/.env")
+
+	os.Setenv("CSTORE_ENCRYPTION_KEY", newKey)
+
+	// act
+	reencrypted, err := s.Reencrypt(&file, oldKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head, _, _ = s.repo.head()
+
+	again, err := s.Reencrypt(&file, oldKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	latest, _, _ := s.repo.head()
+	after, _, _ := s.repo.read(latest, "my-app/.env")
+
+	if string(reencrypted) != string(data) || string(again) != string(data) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s %s", data, reencrypted, again)
+	}
+
+	if bytes.Equal(before, after) {
+		t.Error("file was not pushed again")
+	}
+
+	if decrypted, _ := cipher.Decrypt(newKey, after); string(decrypted) != string(data) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", data, decrypted)
+	}
+
+	if latest != head {
+		t.Error("file using the current key was pushed again")
+	}
+}
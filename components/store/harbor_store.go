@@ -1,449 +1,1082 @@
 package store
 
-// import (
-// 	"bytes"
-// 	"encoding/json"
-// 	"errors"
-// 	"fmt"
-// 	"net/http"
-// 	"time"
-
-// 	"github.com/subosito/gotenv"
-// 	"github.com/turnerlabs/cstore/components/catalog"
-// 	"github.com/turnerlabs/cstore/components/contract"
-// 	"github.com/turnerlabs/cstore/components/models"
-// 	"github.com/turnerlabs/cstore/components/prompt"
-// 	"github.com/turnerlabs/cstore/components/token"
-// 	harborauth "github.com/turnerlabs/harbor-auth-client"
-// )
-
-// const (
-// 	authURL = "http://auth.services.dmtio.net"
-// 	shipURL = "http://shipit.services.dmtio.net"
-
-// 	tokenToken = "HARBOR_TOKEN"
-// 	userToken  = "HARBOR_USER"
-// 	passToken  = "HARBOR_PASS"
-
-// 	shipmentToken  = "HARBOR_SHIPMENT"
-// 	containerToken = "HARBOR_CONTAINER"
-// 	envToken       = "HARBOR_ENV"
-
-// 	modifiedToken  = "CSTORE_MODIFIED"
-// 	modifiedLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
-
-// 	envTypeBasic    = "basic"
-// 	envTypeDiscover = "discover"
-// 	envTypeHidden   = "hidden"
-// )
-
-// // HarborStore ...
-// type HarborStore struct {
-// 	Vault contract.IVault
-
-// 	Auth     HarborAuth
-// 	Shipment HarborShipment
-
-// 	io models.IO
-// }
-
-// // HarborAuth ...
-// type HarborAuth struct {
-// 	User  string
-// 	Token string
-// }
-
-// // HarborShipment ...
-// type HarborShipment struct {
-// 	Name      string
-// 	Container string
-// 	Env       string
-// }
-
-// // Name ...
-// func (s HarborStore) Name() string {
-// 	return "harbor"
-// }
-
-// // Supports ...
-// func (s HarborStore) Supports(feature string) bool {
-// 	return false
-// }
-
-// // Description ...
-// func (s HarborStore) Description() string {
-// 	return `Environment variables listed in a .env file can be stored in Harbor at the shipment container level.
-
-// 	When pushing a .env file, a user will be prompted for NT credentails. When the temporary access token expires, the user will be prompted for credentials again.
-
-// 	A shipment, environment, and container are required when using this store to identify which container will store the environment variables.
-// `
-// }
-
-// // Pre ...
-// func (s *HarborStore) Pre(contextID string, file catalog.File, cv contract.IVault, promptUser bool, io models.IO) error {
-// 	s.io = io
-
-// 	client, err := harborauth.NewAuthClient(authURL)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	s.Shipment = HarborShipment{}
-// 	s.Auth = HarborAuth{}
-
-// 	isAuth := false
-
-// 	// Argonauts Login ID
-// 	if value, err := cv.Get(contextID, userToken, ""); err == nil {
-// 		s.Auth.User = value
-// 	}
-
-// 	if value, err := cv.Get(contextID, tokenToken, ""); err == nil {
-// 		s.Auth.Token = value
-// 	}
-
-// 	if len(s.Auth.Token) > 0 && len(s.Auth.User) > 0 {
-// 		isAuth, _ = client.IsAuthenticated(s.Auth.User, s.Auth.Token)
-// 	}
-
-// 	if !isAuth {
-// 		// Argonauts Login ID
-// 		s.Auth.User = prompt.GetValFromUser(userToken, prompt.Options{}, io)
-// 		pass := prompt.GetValFromUser(passToken, prompt.Options{HideInput: true}, s.io)
-
-// 		token, success, err := client.Login(s.Auth.User, pass)
-// 		if err != nil || !success {
-// 			return err
-// 		}
-
-// 		err = cv.Set(contextID, userToken, "", s.Auth.User)
-// 		if err != nil {
-// 			return err
-// 		}
-
-// 		err = cv.Set(contextID, tokenToken, "", token)
-// 		if err != nil {
-// 			return err
-// 		}
-
-// 		s.Auth.Token = token
-// 	}
-
-// 	if shipment, found := file.Data[shipmentToken]; found {
-// 		s.Shipment.Name = shipment
-// 	} else {
-// 		s.Shipment.Name = prompt.GetValFromUser(shipmentToken, prompt.Options{}, s.io)
-// 	}
-
-// 	if container, found := file.Data[containerToken]; found {
-// 		s.Shipment.Container = container
-// 	} else {
-// 		s.Shipment.Container = prompt.GetValFromUser(containerToken, prompt.Options{}, s.io)
-// 	}
-
-// 	if env, found := file.Data[envToken]; found {
-// 		s.Shipment.Env = env
-// 	} else {
-// 		s.Shipment.Env = prompt.GetValFromUser(envToken, prompt.Options{}, s.io)
-// 	}
-
-// 	return nil
-// }
-
-// // Push ...
-// func (s HarborStore) Push(file catalog.File, fileData []byte, version string) (map[string]string, error) {
-
-// 	if !file.IsEnv {
-// 		return map[string]string{}, fmt.Errorf("cannot store file %s", file.Path)
-// 	}
-
-// 	data := map[string]string{
-// 		shipmentToken:  s.Shipment.Name,
-// 		containerToken: s.Shipment.Container,
-// 		envToken:       s.Shipment.Env,
-// 	}
-
-// 	localKeys := gotenv.Parse(bytes.NewReader(fileData))
-// 	localKeys[modifiedToken] = time.Now().UTC().String()
-
-// 	url := buildURL(s.Shipment)
-
-// 	for key, value := range localKeys {
-
-// 		prefixedKey := addEnvVarPrefix(key)
-
-// 		keyType := envTypeHidden
-// 		if storedKeyType, found := file.Data[prefixedKey]; found {
-// 			if storedKeyType != envVarType {
-// 				keyType = storedKeyType
-// 			}
-// 		}
-
-// 		p := pair{
-// 			Name:  key,
-// 			Value: value,
-// 			Type:  keyType,
-// 		}
-
-// 		if err := createKey(p, url, s.Auth); err != nil {
-// 			if err := updateKey(p, url, s.Auth); err != nil {
-// 				return data, err
-// 			}
-// 		}
-
-// 		data[prefixedKey] = keyType
-// 	}
-
-// 	harborKeys, err := getHarborKeys(s.Shipment, s.Auth)
-// 	if err != nil {
-// 		return data, err
-// 	}
-
-// 	for key := range harborKeys {
-// 		prefixedKey := addEnvVarPrefix(key)
-
-// 		if _, found := file.Data[prefixedKey]; found {
-// 			if _, found := localKeys[key]; !found {
-// 				if err := deleteKey(key, url, s.Auth); err != nil {
-// 					return data, err
-// 				}
-// 			}
-// 		}
-// 	}
-
-// 	return data, nil
-// }
-
-// // Pull ...
-// func (s HarborStore) Pull(file catalog.File, version string) ([]byte, error) {
-
-// 	keys, err := getHarborKeys(s.Shipment, s.Auth)
-// 	if err != nil {
-// 		return []byte{}, err
-// 	}
-
-// 	var buffer bytes.Buffer
-
-// 	for key, contents := range keys {
-// 		if key == modifiedToken {
-// 			continue
-// 		}
-
-// 		if _, found := file.Data[addEnvVarPrefix(key)]; found {
-// 			buffer.WriteString(fmt.Sprintf("%s=%s\n", key, contents.value))
-// 		}
-// 	}
-
-// 	// if modified, found := keys[modifiedToken]; found {
-// 	// 	m, err := time.Parse(modifiedLayout, modified.value)
-// 	// 	if err == nil {
-// 	// 		attr.LastModified = m
-// 	// 	}
-// 	// }
-
-// 	return buffer.Bytes(), nil
-// }
-
-// // Purge ...
-// func (s HarborStore) Purge(file catalog.File, version string) error {
-
-// 	url := buildURL(s.Shipment)
-
-// 	for key, value := range file.Data {
-// 		if isEnvVarType(value) {
-// 			if err := deleteKey(key, url, s.Auth); err != nil {
-// 				return err
-// 			}
-// 		}
-// 	}
-
-// 	return nil
-// }
-
-// // GetTokenValues ...
-// func (s HarborStore) GetTokenValues(tokens map[string]token.Token, contextID string) (map[string]token.Token, error) {
-// 	return map[string]token.Token{}, nil
-// }
-
-// // SaveTokenValues ...
-// func (s HarborStore) SaveTokenValues(tokens map[string]token.Token, contextID string) (map[string]token.Token, error) {
-// 	return map[string]token.Token{}, nil
-// }
-
-// func isEnvVarType(envVarType string) bool {
-// 	switch envVarType {
-// 	case envTypeBasic:
-// 		return true
-// 	case envTypeDiscover:
-// 		return true
-// 	case envTypeHidden:
-// 		return true
-// 	default:
-// 		return false
-// 	}
-// }
-
-// type pair struct {
-// 	Name  string `json:"name"`
-// 	Value string `json:"value"`
-// 	Type  string `json:"type"`
-// }
-
-// func createKey(p pair, url string, auth HarborAuth) error {
-// 	client := &http.Client{}
-
-// 	b, err := json.Marshal(p)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	url = fmt.Sprintf("%s/envVars", url)
-
-// 	r := bytes.NewReader(b)
-
-// 	req, err := http.NewRequest("POST", url, r)
-// 	req.Header.Add("x-token", auth.Token)
-// 	req.Header.Add("x-username", auth.User)
-// 	req.Header.Add("Content-Type", "application/json")
-
-// 	resp, err := client.Do(req)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	if resp.StatusCode != http.StatusCreated {
-// 		return errors.New(resp.Status)
-// 	}
-
-// 	return nil
-// }
-
-// func updateKey(p pair, url string, auth HarborAuth) error {
-
-// 	client := &http.Client{}
-
-// 	b, err := json.Marshal(p)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	url = fmt.Sprintf("%s/envVar/%s", url, p.Name)
-
-// 	r := bytes.NewReader(b)
-
-// 	req, err := http.NewRequest("PUT", url, r)
-// 	req.Header.Add("x-token", auth.Token)
-// 	req.Header.Add("x-username", auth.User)
-// 	req.Header.Add("Content-Type", "application/json")
-
-// 	resp, err := client.Do(req)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	if resp.StatusCode != http.StatusOK {
-// 		return errors.New(resp.Status)
-// 	}
-
-// 	return nil
-// }
-
-// func deleteKey(key, url string, auth HarborAuth) error {
-
-// 	client := &http.Client{}
-
-// 	url = fmt.Sprintf("%s/envVar/%s", url, key)
-
-// 	req, err := http.NewRequest("DELETE", url, nil)
-// 	req.Header.Add("x-token", auth.Token)
-// 	req.Header.Add("x-username", auth.User)
-// 	req.Header.Add("Content-Type", "application/json")
-
-// 	resp, err := client.Do(req)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	if resp.StatusCode != http.StatusOK {
-// 		return errors.New(resp.Status)
-// 	}
-
-// 	return nil
-// }
-
-// type harborKey struct {
-// 	value string
-// 	vType string
-// }
-
-// func getHarborKeys(shipment HarborShipment, auth HarborAuth) (map[string]harborKey, error) {
-
-// 	client := &http.Client{}
-
-// 	url := fmt.Sprintf("%s/v1/shipment/%s/environment/%s", shipURL, shipment.Name, shipment.Env)
-
-// 	req, err := http.NewRequest("GET", url, nil)
-// 	req.Header.Add("x-token", auth.Token)
-// 	req.Header.Add("x-username", auth.User)
-// 	req.Header.Add("Content-Type", "application/json")
-
-// 	resp, err := client.Do(req)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	if resp.StatusCode != http.StatusOK {
-// 		return nil, errors.New(resp.Status)
-// 	}
-
-// 	s := new(HShipment)
-// 	if err = json.NewDecoder(resp.Body).Decode(s); err != nil {
-// 		return nil, err
-// 	}
-
-// 	envVars := map[string]harborKey{}
-
-// 	for _, c := range s.Containers {
-// 		if c.Name == shipment.Container {
-// 			for _, envVar := range c.EnvVars {
-// 				envVars[envVar.Name] = harborKey{
-// 					value: envVar.Value,
-// 					vType: envVar.Type,
-// 				}
-// 			}
-// 		}
-// 	}
-
-// 	return envVars, nil
-// }
-
-// // HShipment ...
-// type HShipment struct {
-// 	Containers []HContainers `json:"containers"`
-// }
-
-// // HContainers ...
-// type HContainers struct {
-// 	Name    string    `json:"name"`
-// 	EnvVars []HEnvVar `json:"envVars"`
-// }
-
-// // HEnvVar ...
-// type HEnvVar struct {
-// 	Name  string `json:"name"`
-// 	Value string `json:"value"`
-// 	Type  string `json:"type"`
-// }
-
-// func buildURL(shipment HarborShipment) string {
-// 	return fmt.Sprintf("%s/v1/shipment/%s/environment/%s/container/%s", shipURL, shipment.Name, shipment.Env, shipment.Container)
-// }
-
-// func init() {
-// 	//--------------------------------
-// 	//- Disabled until converted to v2
-// 	//--------------------------------
-// 	//s := new(HarborStore)
-// 	//stores[s.Name()] = s
-// }
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/setting"
+	"github.com/turnerlabs/cstore/components/vault"
+	harborauth "github.com/turnerlabs/harbor-auth-client"
+)
+
+const (
+	harborDefaultAuthURL = "http://auth.services.dmtio.net"
+	harborDefaultShipURL = "http://shipit.services.dmtio.net"
+
+	// Optional settings for Harbor installations behind a private
+	// certificate authority or a proxy.
+	harborCACertEnv   = "HARBOR_CA_CERT"
+	harborInsecureEnv = "HARBOR_INSECURE_SKIP_VERIFY"
+	harborProxyEnv    = "HARBOR_PROXY"
+
+	shipmentToken  = "HARBOR_SHIPMENT"
+	containerToken = "HARBOR_CONTAINER"
+	envToken       = "HARBOR_ENV"
+
+	modifiedToken  = "CSTORE_MODIFIED"
+	modifiedLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+	envVarPrefix = "ENV_"
+
+	envTypeBasic    = "basic"
+	envTypeDiscover = "discover"
+	envTypeHidden   = "hidden"
+)
+
+// HarborStore ...
+type HarborStore struct {
+	Auth     HarborAuth
+	Shipment HarborShipment
+
+	authURL string
+	api     shipIt
+
+	// reauthenticated is set once the user logged in again after
+	// Harbor rejected the token; so, the user is only asked once.
+	reauthenticated bool
+
+	access  contract.IVault
+	context string
+
+	io models.IO
+}
+
+// HarborAuth ...
+type HarborAuth struct {
+	User    string
+	Token   string
+	Expires time.Time
+}
+
+// HarborShipment ...
+type HarborShipment struct {
+	Name      string
+	Container string
+	Env       string
+}
+
+// Name ...
+func (s HarborStore) Name() string {
+	return "harbor"
+}
+
+// SupportsFeature ...
+func (s HarborStore) SupportsFeature(feature string) bool {
+	return false
+}
+
+// SupportsFileType ...
+func (s HarborStore) SupportsFileType(fileType string) bool {
+	switch fileType {
+	case EnvFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// BlobLimits ...
+func (s HarborStore) BlobLimits() (int, int) {
+	return 4096, 0
+}
+
+// Description ...
+func (s HarborStore) Description() string {
+	return `
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/HARBOR.md
+`
+}
+
+// Pre ...
+func (s *HarborStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.io = io
+
+	s.Shipment = HarborShipment{}
+	s.Auth = HarborAuth{}
+	s.access = access
+	s.context = clog.Context
+
+	//------------------------------------------
+	//- Harbor Installation
+	//------------------------------------------
+	authURL, err := harborEndpoint("AUTH_URL", "Harbor auth service URL.", harborDefaultAuthURL, clog, file, uo, io)
+	if err != nil {
+		return err
+	}
+
+	shipURL, err := harborEndpoint("SHIPIT_URL", "Harbor ShipIt API URL.", harborDefaultShipURL, clog, file, uo, io)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := harborHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	s.authURL = authURL
+	s.api = shipIt{url: shipURL, client: httpClient}
+
+	client, err := newHarborAuthClient(s.authURL)
+	if err != nil {
+		return err
+	}
+
+	//------------------------------------------
+	//- Auth Credentials
+	//------------------------------------------
+	isAuth := false
+
+	if value, err := access.Get(clog.Context, "HARBOR", "USER"); err == nil {
+		s.Auth.User = value
+	}
+
+	if value, err := access.Get(clog.Context, "HARBOR", "TOKEN"); err == nil {
+		s.Auth.Token = value
+	}
+
+	if expires, err := vault.Expiry(access, clog.Context, "HARBOR", "TOKEN"); err == nil {
+		s.Auth.Expires = expires
+	}
+
+	//------------------------------------------
+	//- Login again when the token expires soon.
+	//------------------------------------------
+	if len(s.Auth.Token) > 0 && len(s.Auth.User) > 0 && !expiresSoon(s.Auth.Expires) {
+		isAuth, _ = client.IsAuthenticated(s.Auth.User, s.Auth.Token)
+	}
+
+	if !isAuth {
+		if err := s.login(); err != nil {
+			return err
+		}
+	}
+
+	//------------------------------------------
+	//- Shipment Discovery
+	//------------------------------------------
+	if shipment, found := file.Data[shipmentToken]; found && !uo.Prompt {
+		s.Shipment.Name = shipment
+	} else {
+		shipments, err := s.api.shipments(s.Auth)
+		if err != nil {
+			return err
+		}
+
+		if s.Shipment.Name, err = prompt.Select(shipmentToken, shipments, prompt.Options{
+			Description:  "Shipment that will store the environment variables.",
+			DefaultValue: shipment,
+			Shared:       true,
+		}, io); err != nil {
+			return err
+		}
+	}
+
+	if env, found := file.Data[envToken]; found && !uo.Prompt {
+		s.Shipment.Env = env
+	} else {
+		envs, err := s.api.environments(s.Shipment.Name, s.Auth)
+		if err != nil {
+			return err
+		}
+
+		if s.Shipment.Env, err = prompt.Select(envToken, envs, prompt.Options{
+			Description:  fmt.Sprintf("Environment of shipment %s.", s.Shipment.Name),
+			DefaultValue: env,
+		}, io); err != nil {
+			return err
+		}
+	}
+
+	if container, found := file.Data[containerToken]; found && !uo.Prompt {
+		s.Shipment.Container = container
+	} else {
+		containers, err := s.api.containers(s.Shipment, s.Auth)
+		if err != nil {
+			return err
+		}
+
+		if s.Shipment.Container, err = prompt.Select(containerToken, containers, prompt.Options{
+			Description:  fmt.Sprintf("Container in shipment %s %s.", s.Shipment.Name, s.Shipment.Env),
+			DefaultValue: container,
+		}, io); err != nil {
+			return err
+		}
+	}
+
+	file.AddData(map[string]string{
+		shipmentToken:  s.Shipment.Name,
+		envToken:       s.Shipment.Env,
+		containerToken: s.Shipment.Container,
+	})
+
+	return nil
+}
+
+// harborAuthClient logs in to the Harbor auth service.
+type harborAuthClient interface {
+	Login(username, password string) (string, bool, error)
+	IsAuthenticated(username, token string) (bool, error)
+}
+
+// newHarborAuthClient creates the client used to log in to the Harbor
+// auth service at the url.
+var newHarborAuthClient = func(url string) (harborAuthClient, error) {
+	return harborauth.NewAuthClient(url)
+}
+
+// harborEndpoint returns the URL of a Harbor service. The URL is saved
+// with the file; so, pulls on other machines reach the same Harbor
+// installation. An environment variable, like HARBOR_AUTH_URL, takes
+// precedence over the saved URL.
+func harborEndpoint(prop, description, defaultValue string, clog catalog.Catalog, file *catalog.File, uo cfg.UserOptions, io models.IO) (string, error) {
+	st := setting.Setting{
+		Description:  description,
+		Group:        "HARBOR",
+		Prop:         prop,
+		DefaultValue: clog.GetAnyDataBy(file.BuildKey(clog.Context, "HARBOR", prop), defaultValue),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}
+
+	_, saved := file.Data[st.Key(clog.Context)]
+	_, linked := file.Data[shipmentToken]
+
+	value := ""
+
+	switch env := os.Getenv(st.Key(clog.Context)); {
+	case len(env) > 0 && !uo.Prompt:
+		value = env
+	case !saved && linked && !uo.Prompt:
+		// files linked before the URLs were configurable were pushed
+		// to the default installation
+		value = defaultValue
+	default:
+		v, err := st.Get(clog.Context, io)
+		if err != nil {
+			return v, err
+		}
+		value = v
+	}
+
+	value = strings.TrimRight(value, "/")
+
+	if _, err := url.ParseRequestURI(value); err != nil {
+		return value, fmt.Errorf("invalid %s (%s)", st.Key(clog.Context), err)
+	}
+
+	return value, file.Set(clog.Context, st.Group, st.Prop, value)
+}
+
+// harborHTTPClient returns the client used for ShipIt requests trusting
+// the certificate authority in HARBOR_CA_CERT and using the proxy in
+// HARBOR_PROXY or the standard proxy environment variables.
+func harborHTTPClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{}
+
+	if insecure := os.Getenv(harborInsecureEnv); len(insecure) > 0 {
+		skip, err := strconv.ParseBool(insecure)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s (%s)", harborInsecureEnv, err)
+		}
+		tlsConfig.InsecureSkipVerify = skip
+	}
+
+	if path := os.Getenv(harborCACertEnv); len(path) > 0 {
+		ca, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid certificate authority in %s", path)
+		}
+	}
+
+	proxy := http.ProxyFromEnvironment
+
+	if p := os.Getenv(harborProxyEnv); len(p) > 0 {
+		proxyURL, err := url.Parse(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s (%s)", harborProxyEnv, err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: proxy},
+	}, nil
+}
+
+// login prompts for Harbor credentials and caches the new token and
+// its expiry in the access vault.
+func (s *HarborStore) login() error {
+	client, err := newHarborAuthClient(s.authURL)
+	if err != nil {
+		return err
+	}
+
+	if s.Auth.User, err = prompt.GetValFromUser(s.access.BuildKey(s.context, "HARBOR", "USER"), prompt.Options{DefaultValue: s.Auth.User}, s.io); err != nil {
+		return err
+	}
+
+	pass, err := prompt.GetValFromUser(s.access.BuildKey(s.context, "HARBOR", "PASS"), prompt.Options{HideInput: true}, s.io)
+	if err != nil {
+		return err
+	}
+
+	token, success, err := client.Login(s.Auth.User, pass)
+	if err != nil {
+		return err
+	}
+
+	if !success {
+		return errors.New("Harbor login failed")
+	}
+
+	if err := s.access.Set(s.context, "HARBOR", "USER", s.Auth.User); err != nil {
+		return err
+	}
+
+	if err := s.access.Set(s.context, "HARBOR", "TOKEN", token); err != nil {
+		return err
+	}
+
+	s.Auth.Token = token
+	s.Auth.Expires = tokenExpiry(token)
+
+	return vault.SetExpiry(s.access, s.context, "HARBOR", "TOKEN", s.Auth.Expires)
+}
+
+// Expires ...
+func (s HarborStore) Expires() time.Time {
+	return s.Auth.Expires
+}
+
+// Refresh ...
+func (s *HarborStore) Refresh() error {
+	return s.login()
+}
+
+// authorized sends a ShipIt request and, when Harbor rejects the token,
+// sends it again after authenticating; so, long pushes do not fail part
+// way through when the token expires.
+func (s *HarborStore) authorized(request func(auth HarborAuth) error) error {
+	err := request(s.Auth)
+	if !isHarborAuthError(err) {
+		return err
+	}
+
+	if err := s.reauthenticate(); err != nil {
+		return err
+	}
+
+	return request(s.Auth)
+}
+
+// reauthenticate uses the token in the access vault when another
+// command already logged in again; otherwise, the user is asked to log
+// in once.
+func (s *HarborStore) reauthenticate() error {
+	client, err := newHarborAuthClient(s.authURL)
+	if err != nil {
+		return err
+	}
+
+	if token, err := s.access.Get(s.context, "HARBOR", "TOKEN"); err == nil && token != s.Auth.Token {
+		if isAuth, _ := client.IsAuthenticated(s.Auth.User, token); isAuth {
+			s.Auth.Token = token
+			s.Auth.Expires, _ = vault.Expiry(s.access, s.context, "HARBOR", "TOKEN")
+			return nil
+		}
+	}
+
+	if s.reauthenticated {
+		return errors.New("Harbor rejected the token after logging in again")
+	}
+
+	s.reauthenticated = true
+
+	fmt.Fprintln(s.io.UserOutput, "Harbor token expired.")
+
+	return s.login()
+}
+
+// Push ...
+func (s HarborStore) Push(file *catalog.File, fileData []byte, version string) error {
+
+	if !file.SupportsConfig() {
+		return fmt.Errorf("store does not support file type: %s", file.Type)
+	}
+
+	var harborKeys map[string]harborKey
+
+	if err := s.authorized(func(auth HarborAuth) (err error) {
+		harborKeys, err = s.api.keys(s.Shipment, auth)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	localKeys := gotenv.Parse(bytes.NewReader(fileData))
+
+	//------------------------------------------
+	//- Record keys modified by this push
+	//------------------------------------------
+	stored := map[string]string{}
+	for key, harborKey := range harborKeys {
+		if _, found := file.Data[addEnvVarPrefix(key)]; found && key != modifiedToken {
+			stored[key] = harborKey.value
+		}
+	}
+
+	recordKeysModified(file, stored, localKeys, version, time.Now())
+
+	localKeys[modifiedToken] = time.Now().UTC().Format(modifiedLayout)
+
+	url := s.api.containerURL(s.Shipment)
+
+	for key, value := range localKeys {
+
+		prefixedKey := addEnvVarPrefix(key)
+
+		keyType := harborKeyType(file, key, harborKeys[key].vType)
+
+		p := pair{
+			Name:  key,
+			Value: value,
+			Type:  keyType,
+		}
+
+		if err := s.authorized(func(auth HarborAuth) error {
+			return s.api.createKey(p, url, auth)
+		}); err != nil {
+			if err := s.authorized(func(auth HarborAuth) error {
+				return s.api.updateKey(p, url, auth)
+			}); err != nil {
+				return err
+			}
+		}
+
+		file.AddData(map[string]string{
+			prefixedKey: keyType,
+		})
+	}
+
+	//------------------------------------------
+	//- Delete removed keys pushed by cStore
+	//------------------------------------------
+	for key := range harborKeys {
+		prefixedKey := addEnvVarPrefix(key)
+
+		if _, found := file.Data[prefixedKey]; found {
+			if _, found := localKeys[key]; !found {
+				if err := s.authorized(func(auth HarborAuth) error {
+					return s.api.deleteKey(key, url, auth)
+				}); err != nil {
+					return err
+				}
+
+				delete(file.Data, prefixedKey)
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetKeyTypes ...
+func (s HarborStore) SetKeyTypes(file *catalog.File, types map[string]string, version string) error {
+	var harborKeys map[string]harborKey
+
+	if err := s.authorized(func(auth HarborAuth) (err error) {
+		harborKeys, err = s.api.keys(s.Shipment, auth)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	keys := []string{}
+	for key := range types {
+		if _, found := harborKeys[key]; !found {
+			return fmt.Errorf("%s is not in %s", key, s.Shipment.Container)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	url := s.api.containerURL(s.Shipment)
+
+	for _, key := range keys {
+		p := pair{
+			Name:  key,
+			Value: harborKeys[key].value,
+			Type:  harborType(types[key]),
+		}
+
+		if err := s.authorized(func(auth HarborAuth) error {
+			return s.api.updateKey(p, url, auth)
+		}); err != nil {
+			return err
+		}
+
+		file.AddData(map[string]string{
+			addEnvVarPrefix(key): p.Type,
+		})
+	}
+
+	return nil
+}
+
+// Pull ...
+func (s HarborStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+
+	var keys map[string]harborKey
+
+	if err := s.authorized(func(auth HarborAuth) (err error) {
+		keys, err = s.api.keys(s.Shipment, auth)
+		return err
+	}); err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	var buffer bytes.Buffer
+
+	names := []string{}
+	for key := range keys {
+		if _, found := file.Data[addEnvVarPrefix(key)]; found && key != modifiedToken {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+
+	for _, key := range names {
+		buffer.WriteString(fmt.Sprintf("%s=%s\n", key, keys[key].value))
+
+		//------------------------------------------
+		//- Keep types changed in Harbor; so, the
+		//- next push does not revert them.
+		//------------------------------------------
+		if isEnvVarType(keys[key].vType) {
+			file.Data[addEnvVarPrefix(key)] = keys[key].vType
+		}
+	}
+
+	return buffer.Bytes(), contract.Attributes{
+		LastModified: lastModifiedKey(keys),
+	}, nil
+}
+
+// Purge ...
+func (s HarborStore) Purge(file *catalog.File, version string) error {
+
+	url := s.api.containerURL(s.Shipment)
+
+	keys := []string{}
+	for key, value := range file.Data {
+		if strings.HasPrefix(key, envVarPrefix) && isEnvVarType(value) {
+			keys = append(keys, removeEnvVarPrefix(key))
+		}
+	}
+
+	//------------------------------------------
+	//- Keys are deleted concurrently; so, check
+	//- the token before deleting them.
+	//------------------------------------------
+	if err := s.authorized(func(auth HarborAuth) error {
+		_, err := s.api.keys(s.Shipment, auth)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return purgeKeys(keys, func(key string) error {
+		return s.api.deleteKey(key, url, s.Auth)
+	}, s.io)
+}
+
+// Changed ...
+func (s HarborStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+
+	var keys map[string]harborKey
+
+	if err := s.authorized(func(auth HarborAuth) (err error) {
+		keys, err = s.api.keys(s.Shipment, auth)
+		return err
+	}); err != nil {
+		return time.Time{}, err
+	}
+
+	return lastModifiedKey(keys), nil
+}
+
+// ETag ...
+func (s HarborStore) ETag(file *catalog.File, version string) (string, error) {
+
+	var keys map[string]harborKey
+
+	if err := s.authorized(func(auth HarborAuth) (err error) {
+		keys, err = s.api.keys(s.Shipment, auth)
+		return err
+	}); err != nil {
+		return "", err
+	}
+
+	//------------------------------------------
+	//- Keys changed outside of cStore do not
+	//- update CSTORE_MODIFIED; so, the values
+	//- of the file's keys are included.
+	//------------------------------------------
+	parts := []string{}
+	for key, value := range keys {
+		if _, found := file.Data[addEnvVarPrefix(key)]; found || key == modifiedToken {
+			parts = append(parts, fmt.Sprintf("%s:%s:%s", key, value.vType, value.value))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(parts)
+
+	return etagOf(parts), nil
+}
+
+// KeysModified ...
+func (s HarborStore) KeysModified(file *catalog.File, version string) (map[string]time.Time, error) {
+
+	keys := []string{}
+	for key, value := range file.Data {
+		if strings.HasPrefix(key, envVarPrefix) && isEnvVarType(value) && removeEnvVarPrefix(key) != modifiedToken {
+			keys = append(keys, removeEnvVarPrefix(key))
+		}
+	}
+
+	return recordedKeysModified(file, keys, version), nil
+}
+
+// Encryption ...
+func (s HarborStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	var harborKeys map[string]harborKey
+
+	if err := s.authorized(func(auth HarborAuth) (err error) {
+		harborKeys, err = s.api.keys(s.Shipment, auth)
+		return err
+	}); err != nil {
+		return contract.Encryption{}, nil, err
+	}
+
+	keys := map[string]contract.Encryption{}
+	for key, k := range harborKeys {
+		if _, found := file.Data[addEnvVarPrefix(key)]; !found || key == modifiedToken {
+			continue
+		}
+
+		keys[key] = contract.Encryption{Mechanism: contract.EncryptionNone, Detail: k.vType}
+		if k.vType == envTypeHidden {
+			keys[key] = contract.Encryption{Mechanism: contract.EncryptionSSE, Detail: "hidden, managed by Harbor"}
+		}
+	}
+
+	return contract.Encryption{Mechanism: contract.EncryptionSSE, Detail: "hidden keys, managed by Harbor"}, keys, nil
+}
+
+// Locate ...
+func (s HarborStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	location := contract.Location{
+		Remote:      fmt.Sprintf("%s/envVars", s.api.containerURL(s.Shipment)),
+		Credentials: fmt.Sprintf("HARBOR_USER and HARBOR_TOKEN from %s vault", file.Vaults.Access),
+		Encryption:  "managed by Harbor",
+	}
+
+	if len(key) > 0 {
+		location.Remote = fmt.Sprintf("%s/%s", location.Remote, key)
+
+		if keyType, found := file.Data[addEnvVarPrefix(key)]; found {
+			location.Encryption = fmt.Sprintf("managed by Harbor (%s)", keyType)
+		}
+	}
+
+	return location, nil
+}
+
+func init() {
+	s := new(HarborStore)
+	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: shipmentToken, Required: true},
+		{Key: envToken, Required: true},
+		{Key: containerToken, Required: true},
+		{Key: "HARBOR_AUTH_URL", Type: catalog.ValueTypeURL},
+		{Key: "HARBOR_SHIPIT_URL", Type: catalog.ValueTypeURL},
+		{Key: envVarPrefix, Prefix: true, Values: []string{envTypeBasic, envTypeDiscover, envTypeHidden}},
+	})
+}
+
+//------------------------------------------
+//- Harbor environment variable helpers.
+//------------------------------------------
+func addEnvVarPrefix(key string) string {
+	return envVarPrefix + key
+}
+
+func removeEnvVarPrefix(key string) string {
+	return key[len(envVarPrefix):]
+}
+
+// harborKeyType maps the key type declared in the catalog to a Harbor
+// type. Undeclared keys keep their type in Harbor, then the Harbor type
+// stored in the catalog data, or default to hidden.
+func harborKeyType(file *catalog.File, key, remoteType string) string {
+	if keyType, declared := file.DeclaredKeyType(key); declared {
+		return harborType(keyType)
+	}
+
+	if isEnvVarType(remoteType) {
+		return remoteType
+	}
+
+	if storedKeyType, found := file.Data[addEnvVarPrefix(key)]; found && isEnvVarType(storedKeyType) {
+		return storedKeyType
+	}
+
+	return envTypeHidden
+}
+
+// harborType maps a catalog key type to a Harbor type.
+func harborType(keyType string) string {
+	switch keyType {
+	case catalog.KeyTypePlain:
+		return envTypeBasic
+	case catalog.KeyTypeReference:
+		return envTypeDiscover
+	default:
+		return envTypeHidden
+	}
+}
+
+func isEnvVarType(envVarType string) bool {
+	switch envVarType {
+	case envTypeBasic:
+		return true
+	case envTypeDiscover:
+		return true
+	case envTypeHidden:
+		return true
+	default:
+		return false
+	}
+}
+
+func lastModifiedKey(keys map[string]harborKey) time.Time {
+	if modified, found := keys[modifiedToken]; found {
+		if m, err := time.Parse(modifiedLayout, modified.value); err == nil {
+			return m
+		}
+	}
+
+	return time.Time{}
+}
+
+//------------------------------------------
+//- ShipIt API.
+//------------------------------------------
+type pair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+type harborKey struct {
+	value string
+	vType string
+}
+
+// HShipment ...
+type HShipment struct {
+	Name       string        `json:"name"`
+	Containers []HContainers `json:"containers"`
+}
+
+// HEnvironment ...
+type HEnvironment struct {
+	Name string `json:"name"`
+}
+
+// HContainers ...
+type HContainers struct {
+	Name    string    `json:"name"`
+	EnvVars []HEnvVar `json:"envVars"`
+}
+
+// HEnvVar ...
+type HEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// harborStatusError is returned when ShipIt responds with an unexpected
+// status.
+type harborStatusError struct {
+	code   int
+	status string
+}
+
+func (e harborStatusError) Error() string {
+	return e.status
+}
+
+// isHarborAuthError returns true when Harbor rejected the token.
+func isHarborAuthError(err error) bool {
+	e, ok := err.(harborStatusError)
+	return ok && (e.code == http.StatusUnauthorized || e.code == http.StatusForbidden)
+}
+
+// shipIt sends requests to the ShipIt API of a Harbor installation.
+type shipIt struct {
+	url    string
+	client *http.Client
+}
+
+func (api shipIt) createKey(p pair, url string, auth HarborAuth) error {
+	return api.send("POST", fmt.Sprintf("%s/envVars", url), p, http.StatusCreated, nil, auth)
+}
+
+func (api shipIt) updateKey(p pair, url string, auth HarborAuth) error {
+	return api.send("PUT", fmt.Sprintf("%s/envVar/%s", url, p.Name), p, http.StatusOK, nil, auth)
+}
+
+func (api shipIt) deleteKey(key, url string, auth HarborAuth) error {
+	return api.send("DELETE", fmt.Sprintf("%s/envVar/%s", url, key), nil, http.StatusOK, nil, auth)
+}
+
+func (api shipIt) shipments(auth HarborAuth) ([]string, error) {
+	names := []string{}
+
+	err := api.pages(fmt.Sprintf("%s/v1/shipments", api.url), auth, func(d *json.Decoder) error {
+		page := []HShipment{}
+		if err := d.Decode(&page); err != nil {
+			return err
+		}
+
+		for _, s := range page {
+			names = append(names, s.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (api shipIt) environments(shipment string, auth HarborAuth) ([]string, error) {
+	names := []string{}
+
+	err := api.pages(fmt.Sprintf("%s/v1/shipment/%s/environments", api.url, shipment), auth, func(d *json.Decoder) error {
+		page := []HEnvironment{}
+		if err := d.Decode(&page); err != nil {
+			return err
+		}
+
+		for _, e := range page {
+			names = append(names, e.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (api shipIt) containers(shipment HarborShipment, auth HarborAuth) ([]string, error) {
+	s, err := api.shipment(shipment, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, c := range s.Containers {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (api shipIt) keys(shipment HarborShipment, auth HarborAuth) (map[string]harborKey, error) {
+	s, err := api.shipment(shipment, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	envVars := map[string]harborKey{}
+
+	for _, c := range s.Containers {
+		if c.Name == shipment.Container {
+			for _, envVar := range c.EnvVars {
+				envVars[envVar.Name] = harborKey{
+					value: envVar.Value,
+					vType: envVar.Type,
+				}
+			}
+		}
+	}
+
+	return envVars, nil
+}
+
+func (api shipIt) shipment(shipment HarborShipment, auth HarborAuth) (HShipment, error) {
+	s := HShipment{}
+
+	url := fmt.Sprintf("%s/v1/shipment/%s/environment/%s", api.url, shipment.Name, shipment.Env)
+
+	err := api.send("GET", url, nil, http.StatusOK, &s, auth)
+
+	return s, err
+}
+
+func (api shipIt) send(method, url string, input interface{}, status int, output interface{}, auth HarborAuth) error {
+	resp, err := api.do(method, url, input, status, auth)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if output == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(output)
+}
+
+// pages reads a list one page at a time following the Link header's
+// next URL (RFC 5988); so, long lists are not truncated when ShipIt
+// paginates them. Lists returned whole have no Link header.
+func (api shipIt) pages(url string, auth HarborAuth, page func(d *json.Decoder) error) error {
+	seen := map[string]bool{}
+
+	for len(url) > 0 && !seen[url] {
+		seen[url] = true
+
+		resp, err := api.do("GET", url, nil, http.StatusOK, auth)
+		if err != nil {
+			return err
+		}
+
+		err = page(json.NewDecoder(resp.Body))
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		url = nextPage(resp)
+	}
+
+	return nil
+}
+
+func (api shipIt) do(method, url string, input interface{}, status int, auth HarborAuth) (*http.Response, error) {
+	var body io.Reader
+
+	if input != nil {
+		b, err := json.Marshal(input)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("x-token", auth.Token)
+	req.Header.Add("x-username", auth.User)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != status {
+		resp.Body.Close()
+		return nil, harborStatusError{code: resp.StatusCode, status: resp.Status}
+	}
+
+	return resp, nil
+}
+
+// nextPage returns the absolute URL of the response's rel="next" link.
+func nextPage(resp *http.Response) string {
+	for _, header := range resp.Header["Link"] {
+		for _, link := range strings.Split(header, ",") {
+			parts := strings.Split(link, ";")
+
+			target := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+
+			for _, param := range parts[1:] {
+				if strings.Replace(strings.TrimSpace(param), " ", "", -1) != `rel="next"` {
+					continue
+				}
+
+				next, err := resp.Request.URL.Parse(target)
+				if err != nil {
+					return ""
+				}
+
+				return next.String()
+			}
+		}
+	}
+
+	return ""
+}
+
+func (api shipIt) containerURL(shipment HarborShipment) string {
+	return fmt.Sprintf("%s/v1/shipment/%s/environment/%s/container/%s", api.url, shipment.Name, shipment.Env, shipment.Container)
+}
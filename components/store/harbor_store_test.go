@@ -0,0 +1,260 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+func TestHarborShipItPrivateCA(t *testing.T) {
+	// arrange
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/shipments" || r.Header.Get("x-token") != "test-token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[{"name":"web"},{"name":"api"}]`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "cstore-harbor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(ca, cert, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv(harborCACertEnv, ca)
+	defer os.Unsetenv(harborCACertEnv)
+
+	client, err := harborHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := shipIt{url: server.URL, client: client}
+
+	// act
+	shipments, err := api.shipments(HarborAuth{Token: "test-token"})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"api", "web"}; !reflect.DeepEqual(shipments, expected) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected, shipments)
+	}
+}
+
+func TestHarborEndpoint(t *testing.T) {
+	// arrange
+	tests := []struct {
+		env      string
+		data     map[string]string
+		expected string
+	}{
+		{data: map[string]string{"HARBOR_AUTH_URL": "https://auth.example.com"}, expected: "https://auth.example.com"},
+		{data: map[string]string{shipmentToken: "web"}, expected: harborDefaultAuthURL},
+		{env: "https://auth.local/", data: map[string]string{"HARBOR_AUTH_URL": "https://auth.example.com"}, expected: "https://auth.local"},
+	}
+
+	defer os.Unsetenv("HARBOR_AUTH_URL")
+
+	for _, test := range tests {
+		os.Setenv("HARBOR_AUTH_URL", test.env)
+
+		file := catalog.File{Data: test.data}
+
+		// act
+		actual, err := harborEndpoint("AUTH_URL", "", harborDefaultAuthURL, catalog.Catalog{}, &file, cfg.UserOptions{}, models.IO{})
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != test.expected || file.Data["HARBOR_AUTH_URL"] != test.expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s (saved %s)", test.expected, actual, file.Data["HARBOR_AUTH_URL"])
+		}
+	}
+}
+
+// fakeHarborAuth accepts a single token.
+type fakeHarborAuth struct {
+	token string
+}
+
+func (a fakeHarborAuth) Login(username, password string) (string, bool, error) {
+	return a.token, true, nil
+}
+
+func (a fakeHarborAuth) IsAuthenticated(username, token string) (bool, error) {
+	return token == a.token, nil
+}
+
+func TestHarborRetriesExpiredToken(t *testing.T) {
+	// arrange
+	keys := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-token") != "new-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"name":"web","containers":[{"name":"app","envVars":[]}]}`))
+		case http.MethodPost:
+			p := pair{}
+			json.NewDecoder(r.Body).Decode(&p)
+			keys[p.Name] = p.Value
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	newAuthClient := newHarborAuthClient
+	defer func() { newHarborAuthClient = newAuthClient }()
+	newHarborAuthClient = func(url string) (harborAuthClient, error) {
+		return fakeHarborAuth{token: "new-token"}, nil
+	}
+
+	// another command already logged in again
+	access := &catalog.File{}
+	access.Set("", "HARBOR", "TOKEN", "new-token")
+
+	s := HarborStore{
+		Auth:     HarborAuth{User: "user", Token: "old-token"},
+		Shipment: HarborShipment{Name: "web", Env: "dev", Container: "app"},
+		api:      shipIt{url: server.URL, client: http.DefaultClient},
+		access:   access,
+		io:       models.IO{UserOutput: &bytes.Buffer{}},
+	}
+
+	file := catalog.File{Path: ".env", Type: "env", Data: map[string]string{}}
+
+	// act
+	err := s.Push(&file, []byte("A=1\n"), "")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keys["A"] != "1" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "1", keys["A"])
+	}
+}
+
+func TestHarborSetKeyTypesKeepsValues(t *testing.T) {
+	// arrange
+	updated := map[string]pair{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"name":"web","containers":[{"name":"app","envVars":[{"name":"URL","value":"https://example.com","type":"hidden"}]}]}`))
+		case http.MethodPut:
+			p := pair{}
+			json.NewDecoder(r.Body).Decode(&p)
+			updated[p.Name] = p
+		}
+	}))
+	defer server.Close()
+
+	s := HarborStore{
+		Auth:     HarborAuth{User: "user", Token: "token"},
+		Shipment: HarborShipment{Name: "web", Env: "dev", Container: "app"},
+		api:      shipIt{url: server.URL, client: http.DefaultClient},
+		io:       models.IO{UserOutput: &bytes.Buffer{}},
+	}
+
+	file := catalog.File{Path: ".env", Type: "env", Data: map[string]string{}}
+
+	// act
+	err := s.SetKeyTypes(&file, map[string]string{"URL": catalog.KeyTypePlain}, "")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := pair{Name: "URL", Value: "https://example.com", Type: envTypeBasic}
+	if updated["URL"] != expected {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected, updated["URL"])
+	}
+
+	if file.Data["ENV_URL"] != envTypeBasic {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", envTypeBasic, file.Data["ENV_URL"])
+	}
+}
+
+func TestHarborAuthError(t *testing.T) {
+	// arrange
+	tests := map[error]bool{
+		harborStatusError{code: http.StatusUnauthorized, status: "401 Unauthorized"}: true,
+		harborStatusError{code: http.StatusForbidden, status: "403 Forbidden"}:       true,
+		harborStatusError{code: http.StatusNotFound, status: "404 Not Found"}:        false,
+	}
+
+	for err, expected := range tests {
+		// act
+		actual := isHarborAuthError(err)
+
+		// assert
+		if actual != expected {
+			t.Errorf("\nERROR: %s \nEXPECTED: %t \nACTUAL: %t", err, expected, actual)
+		}
+	}
+}
+
+func TestHarborShipmentsFollowsPages(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Add("Link", `</v1/shipments?page=2>; rel="next", </v1/shipments?page=3>; rel="last"`)
+			w.Write([]byte(`[{"name":"web"},{"name":"api"}]`))
+		case "2":
+			w.Header().Add("Link", `</v1/shipments?page=3>; rel="next"`)
+			w.Write([]byte(`[{"name":"worker"}]`))
+		case "3":
+			w.Write([]byte(`[{"name":"cron"}]`))
+		}
+	}))
+	defer server.Close()
+
+	api := shipIt{url: server.URL, client: http.DefaultClient}
+
+	// act
+	shipments, err := api.shipments(HarborAuth{})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"api", "cron", "web", "worker"}
+
+	if !reflect.DeepEqual(shipments, expected) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected, shipments)
+	}
+}
@@ -0,0 +1,640 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/credential"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/setting"
+	"github.com/turnerlabs/cstore/components/vault"
+)
+
+const (
+	vaultDefaultAddr  = "https://127.0.0.1:8200"
+	vaultDefaultMount = "secret"
+
+	vaultMountToken = "VAULT_MOUNT"
+
+	vaultAuthToken      = "token"
+	vaultAuthAppRole    = "approle"
+	vaultAuthKubernetes = "kubernetes"
+	vaultAuthJWT        = "jwt"
+
+	// vaultKubernetesJWT is where Kubernetes mounts the service account
+	// token used to log in with Kubernetes auth.
+	vaultKubernetesJWT = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// HashicorpVaultStore ...
+type HashicorpVaultStore struct {
+	context string
+	addr    string
+	mount   string
+	token   string
+
+	authMethod string
+	authPath   string
+	login      map[string]interface{}
+	expires    time.Time
+
+	io models.IO
+}
+
+// Name ...
+func (s HashicorpVaultStore) Name() string {
+	return "hashicorp-vault"
+}
+
+// SupportsFeature ...
+func (s HashicorpVaultStore) SupportsFeature(feature string) bool {
+	switch feature {
+	case VersionFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFileType ...
+func (s HashicorpVaultStore) SupportsFileType(fileType string) bool {
+	switch fileType {
+	case EnvFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// BlobLimits ...
+func (s HashicorpVaultStore) BlobLimits() (int, int) {
+	// all keys are written in a single secret, which storage backends
+	// like Consul limit to 512 KB
+	return 0, 512 * 1024
+}
+
+// Description ...
+func (s HashicorpVaultStore) Description() string {
+	return `
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/HASHICORP_VAULT.md
+`
+}
+
+// Pre ...
+func (s *HashicorpVaultStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.context = clog.Context
+	s.io = io
+
+	addr, err := (setting.Setting{
+		Description:  "Vault server address.",
+		Group:        "VAULT",
+		Prop:         "ADDR",
+		DefaultValue: vaultDefaultAddr,
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        vault.EnvVault{},
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.addr = strings.TrimRight(addr, "/")
+
+	mount, err := (setting.Setting{
+		Description:  "Path the KV version 2 secrets engine is mounted at.",
+		Group:        "VAULT",
+		Prop:         "MOUNT",
+		DefaultValue: clog.GetAnyDataBy(vaultMountToken, vaultDefaultMount),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.mount = strings.Trim(mount, "/")
+
+	//------------------------------------------
+	//- Auth Credentials
+	//------------------------------------------
+	authMethod, err := (setting.Setting{
+		Description:  fmt.Sprintf("OPTIONS\n %s \n %s \n %s \n %s", vaultAuthToken, vaultAuthAppRole, vaultAuthKubernetes, vaultAuthJWT),
+		Group:        "VAULT",
+		Prop:         "AUTH_METHOD",
+		DefaultValue: vaultAuthToken,
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        vault.EnvVault{},
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.authMethod = authMethod
+
+	switch authMethod {
+	case vaultAuthToken:
+		token, err := (setting.Setting{
+			Group:     "VAULT",
+			Prop:      "TOKEN",
+			Prompt:    uo.Prompt,
+			HideInput: true,
+			AutoSave:  true,
+			Vault:     access,
+		}).Get(clog.Context, io)
+		if err != nil {
+			return err
+		}
+
+		s.token = token
+
+		return nil
+
+	case vaultAuthAppRole:
+		roleID, err := (setting.Setting{
+			Group:    "VAULT",
+			Prop:     "ROLE_ID",
+			Prompt:   uo.Prompt,
+			AutoSave: true,
+			Vault:    vault.EnvVault{},
+		}).Get(clog.Context, io)
+		if err != nil {
+			return err
+		}
+
+		secretID, err := (setting.Setting{
+			Group:     "VAULT",
+			Prop:      "SECRET_ID",
+			Prompt:    uo.Prompt,
+			HideInput: true,
+			AutoSave:  true,
+			Vault:     access,
+		}).Get(clog.Context, io)
+		if err != nil {
+			return err
+		}
+
+		s.login = map[string]interface{}{
+			"role_id":   roleID,
+			"secret_id": secretID,
+		}
+
+	case vaultAuthKubernetes, vaultAuthJWT:
+		role, err := (setting.Setting{
+			Group:    "VAULT",
+			Prop:     "ROLE",
+			Prompt:   uo.Prompt,
+			AutoSave: true,
+			Vault:    vault.EnvVault{},
+		}).Get(clog.Context, io)
+		if err != nil {
+			return err
+		}
+
+		s.login = map[string]interface{}{
+			"role": role,
+		}
+
+	default:
+		return fmt.Errorf("unsupported Vault auth method: %s", authMethod)
+	}
+
+	authPath, err := (setting.Setting{
+		Description:  "Path the auth method is mounted at.",
+		Group:        "VAULT",
+		Prop:         "AUTH_PATH",
+		DefaultValue: authMethod,
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        vault.EnvVault{},
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.authPath = strings.Trim(authPath, "/")
+
+	return s.authenticate()
+}
+
+// authenticate logs in using the auth method exchanging the role
+// credentials for a token. Kubernetes and JWT auth read the token each
+// time; so, a rotated token is used when refreshing.
+func (s *HashicorpVaultStore) authenticate() error {
+	switch s.authMethod {
+	case vaultAuthKubernetes:
+		jwt, err := ioutil.ReadFile(vaultKubernetesJWT)
+		if err != nil {
+			return fmt.Errorf("could not read the Kubernetes service account token (%s)", err)
+		}
+
+		s.login["jwt"] = strings.TrimSpace(string(jwt))
+
+	case vaultAuthJWT:
+		jwt, _, err := credential.OIDCToken("")
+		if err != nil {
+			return err
+		}
+
+		s.login["jwt"] = jwt
+	}
+
+	output := struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}{}
+
+	s.token = ""
+
+	if _, err := s.call(http.MethodPost, fmt.Sprintf("auth/%s/login", s.authPath), s.login, &output); err != nil {
+		return err
+	}
+
+	s.token = output.Auth.ClientToken
+	s.expires = time.Time{}
+
+	if output.Auth.LeaseDuration > 0 {
+		s.expires = time.Now().Add(time.Duration(output.Auth.LeaseDuration) * time.Second)
+	}
+
+	return nil
+}
+
+// Push ...
+func (s HashicorpVaultStore) Push(file *catalog.File, fileData []byte, version string) error {
+
+	if !file.SupportsConfig() {
+		return fmt.Errorf("store does not support file type: %s", file.Type)
+	}
+
+	if len(fileData) == 0 {
+		return errors.New("empty file")
+	}
+
+	newParams := gotenv.Parse(bytes.NewReader(fileData))
+	if len(newParams) == 0 {
+		return errors.New("failed to parse environment variables")
+	}
+
+	file.AddData(map[string]string{
+		vaultMountToken: s.mount,
+	})
+
+	//------------------------------------------
+	//- Skip writing a new secret version when
+	//- no values changed.
+	//------------------------------------------
+	stored, found, err := s.read(file.Path, version)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]string(newParams)
+
+	if found && reflect.DeepEqual(stored.values(), data) {
+		return nil
+	}
+
+	_, err = s.call(http.MethodPost, s.dataPath(file.Path, version), map[string]interface{}{"data": data}, nil)
+
+	return err
+}
+
+// Pull ...
+func (s HashicorpVaultStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+
+	secret, found, err := s.read(file.Path, version)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	if !found {
+		return []byte{}, contract.Attributes{}, errors.New("secret not found, verify Vault access")
+	}
+
+	values := secret.values()
+
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buffer bytes.Buffer
+
+	for _, key := range keys {
+		buffer.WriteString(fmt.Sprintf("%s=%s\n", key, values[key]))
+	}
+
+	return buffer.Bytes(), contract.Attributes{
+		LastModified: secret.Metadata.CreatedTime,
+	}, nil
+}
+
+// Purge ...
+func (s HashicorpVaultStore) Purge(file *catalog.File, version string) error {
+	_, err := s.call(http.MethodDelete, s.metadataPath(file.Path, version), nil, nil)
+	return err
+}
+
+// Changed ...
+func (s HashicorpVaultStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+	metadata, found, err := s.metadata(file.Path, version)
+	if err != nil || !found {
+		return time.Time{}, err
+	}
+
+	return metadata.UpdatedTime, nil
+}
+
+// ETag ...
+func (s HashicorpVaultStore) ETag(file *catalog.File, version string) (string, error) {
+	metadata, found, err := s.metadata(file.Path, version)
+	if err != nil || !found {
+		return "", err
+	}
+
+	return etagOf([]string{
+		s.secretPath(file.Path, version),
+		fmt.Sprint(metadata.CurrentVersion),
+		metadata.UpdatedTime.Format(time.RFC3339Nano),
+	}), nil
+}
+
+// KeyHistory ...
+func (s HashicorpVaultStore) KeyHistory(file *catalog.File, key, version string) ([]contract.KeyChange, error) {
+	metadata, found, err := s.metadata(file.Path, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, errors.New("secret not found, verify Vault access")
+	}
+
+	//------------------------------------------
+	//- Compare the key in each version still
+	//- kept by Vault.
+	//------------------------------------------
+	states := []keyState{}
+
+	for n := 1; n <= metadata.CurrentVersion; n++ {
+		v, kept := metadata.Versions[fmt.Sprint(n)]
+		if !kept || v.Destroyed || len(v.DeletionTime) > 0 {
+			continue
+		}
+
+		output := struct {
+			Data vaultSecret `json:"data"`
+		}{}
+
+		if _, err := s.call(http.MethodGet, fmt.Sprintf("%s?version=%d", s.dataPath(file.Path, version), n), nil, &output); err != nil {
+			return nil, err
+		}
+
+		value, found := output.Data.values()[key]
+
+		states = append(states, keyState{
+			revision: fmt.Sprint(n),
+			modified: v.CreatedTime,
+			value:    value,
+			found:    found,
+		})
+	}
+
+	return keyChanges(states), nil
+}
+
+// Encryption ...
+func (s HashicorpVaultStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	location, err := s.Locate(file, "", version)
+	return contract.Encryption{Mechanism: contract.EncryptionSSE, Detail: location.Encryption}, nil, err
+}
+
+// Locate ...
+func (s HashicorpVaultStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	remote := fmt.Sprintf("%s/v1/%s", s.addr, s.dataPath(file.Path, version))
+	if len(key) > 0 {
+		remote = fmt.Sprintf("%s (key %s)", remote, key)
+	}
+
+	return contract.Location{
+		Remote:      remote,
+		Credentials: fmt.Sprintf("%s auth", s.authMethod),
+		Encryption:  "Vault barrier encryption",
+	}, nil
+}
+
+// Expires ...
+func (s HashicorpVaultStore) Expires() time.Time {
+	return s.expires
+}
+
+// Refresh ...
+func (s *HashicorpVaultStore) Refresh() error {
+	if s.authMethod == vaultAuthToken {
+		return nil
+	}
+
+	return s.authenticate()
+}
+
+func init() {
+	s := new(HashicorpVaultStore)
+	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: vaultMountToken, Required: true},
+	})
+}
+
+//------------------------------------------
+//- Vault API helpers.
+//------------------------------------------
+
+type vaultSecret struct {
+	Data     map[string]interface{} `json:"data"`
+	Metadata struct {
+		CreatedTime time.Time `json:"created_time"`
+		Version     int       `json:"version"`
+	} `json:"metadata"`
+}
+
+// values returns the secret's values as strings. Values written by
+// other tools as numbers, booleans, or objects are formatted as JSON.
+func (v vaultSecret) values() map[string]string {
+	values := map[string]string{}
+
+	for key, value := range v.Data {
+		if str, ok := value.(string); ok {
+			values[key] = str
+			continue
+		}
+
+		b, _ := json.Marshal(value)
+		values[key] = string(b)
+	}
+
+	return values
+}
+
+type vaultMetadata struct {
+	CurrentVersion int       `json:"current_version"`
+	UpdatedTime    time.Time `json:"updated_time"`
+	Versions       map[string]struct {
+		CreatedTime  time.Time `json:"created_time"`
+		DeletionTime string    `json:"deletion_time"`
+		Destroyed    bool      `json:"destroyed"`
+	} `json:"versions"`
+}
+
+func (s HashicorpVaultStore) secretPath(path, version string) string {
+	if len(version) > 0 {
+		return fmt.Sprintf("%s/%s/%s", s.context, version, path)
+	}
+	return fmt.Sprintf("%s/%s", s.context, path)
+}
+
+func (s HashicorpVaultStore) dataPath(path, version string) string {
+	return fmt.Sprintf("%s/data/%s", s.mount, s.secretPath(path, version))
+}
+
+func (s HashicorpVaultStore) metadataPath(path, version string) string {
+	return fmt.Sprintf("%s/metadata/%s", s.mount, s.secretPath(path, version))
+}
+
+// read returns the current version of a secret. Secrets whose current
+// version was deleted are not found.
+func (s HashicorpVaultStore) read(path, version string) (vaultSecret, bool, error) {
+	output := struct {
+		Data vaultSecret `json:"data"`
+	}{}
+
+	status, err := s.call(http.MethodGet, s.dataPath(path, version), nil, &output)
+	if status == http.StatusNotFound {
+		return output.Data, false, nil
+	}
+
+	return output.Data, err == nil, err
+}
+
+func (s HashicorpVaultStore) metadata(path, version string) (vaultMetadata, bool, error) {
+	output := struct {
+		Data vaultMetadata `json:"data"`
+	}{}
+
+	status, err := s.call(http.MethodGet, s.metadataPath(path, version), nil, &output)
+	if status == http.StatusNotFound {
+		return output.Data, false, nil
+	}
+
+	return output.Data, err == nil, err
+}
+
+// call sends a request to the Vault HTTP API returning the response
+// status. Responses other than 200 and 204 are returned as errors.
+func (s HashicorpVaultStore) call(method, path string, input, output interface{}) (int, error) {
+	body := bytes.NewReader([]byte{})
+
+	if input != nil {
+		b, err := json.Marshal(input)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/v1/%s", s.addr, path), body)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(s.token) > 0 {
+		req.Header.Set("X-Vault-Token", s.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return resp.StatusCode, nil
+	case http.StatusForbidden:
+		if strings.HasPrefix(path, "auth/") {
+			return resp.StatusCode, fmt.Errorf("vault %s %s: %d %s", method, path, resp.StatusCode, strings.TrimSpace(string(b)))
+		}
+
+		return resp.StatusCode, contract.AccessDeniedError{
+			Action:    fmt.Sprintf("%s capability", vaultCapability(method)),
+			Resource:  strings.SplitN(path, "?", 2)[0],
+			Principal: s.principal(),
+			Err:       fmt.Errorf("%d %s", resp.StatusCode, strings.TrimSpace(string(b))),
+		}
+	default:
+		return resp.StatusCode, fmt.Errorf("vault %s %s: %d %s", method, path, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	if output == nil {
+		return resp.StatusCode, nil
+	}
+
+	return resp.StatusCode, json.Unmarshal(b, output)
+}
+
+// vaultCapability returns the policy capability a request needs.
+func vaultCapability(method string) string {
+	switch method {
+	case http.MethodGet:
+		return "read"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "create/update"
+	}
+}
+
+// principal describes the token used, like its display name and
+// policies, when the token can look itself up.
+func (s HashicorpVaultStore) principal() string {
+	output := struct {
+		Data struct {
+			DisplayName string   `json:"display_name"`
+			Policies    []string `json:"policies"`
+		} `json:"data"`
+	}{}
+
+	if _, err := s.call(http.MethodGet, "auth/token/lookup-self", nil, &output); err != nil {
+		return fmt.Sprintf("%s auth", s.authMethod)
+	}
+
+	return fmt.Sprintf("%s (policies %s)", output.Data.DisplayName, strings.Join(output.Data.Policies, ", "))
+}
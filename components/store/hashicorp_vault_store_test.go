@@ -0,0 +1,193 @@
+package store
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+// fakeKV serves the Vault KV version 2 data endpoint for one secret.
+func fakeKV(t *testing.T, created time.Time) (*httptest.Server, *int) {
+	var data map[string]interface{}
+	writes := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.URL.Path != "/v1/kv/data/my-app/.env" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			input := struct {
+				Data map[string]interface{} `json:"data"`
+			}{}
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+				t.Fatal(err)
+			}
+			data = input.Data
+			writes++
+			w.Write([]byte(`{"data":{"version":1}}`))
+		case http.MethodGet:
+			if data == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     data,
+					"metadata": map[string]interface{}{"created_time": created, "version": writes},
+				},
+			})
+		}
+	}))
+
+	return server, &writes
+}
+
+func TestHashicorpVaultPushPull(t *testing.T) {
+	// arrange
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	server, writes := fakeKV(t, created)
+	defer server.Close()
+
+	s := HashicorpVaultStore{context: "my-app", addr: server.URL, mount: "kv", token: "test-token"}
+	file := catalog.File{Path: ".env", Type: "env"}
+
+	// act
+	if err := s.Push(&file, []byte("B=2\nA=1\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Push(&file, []byte("A=1\nB=2\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled, attr, err := s.Pull(&file, "")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(pulled) != "A=1\nB=2\n" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "A=1\nB=2\n", string(pulled))
+	}
+
+	if !attr.LastModified.Equal(created) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", created, attr.LastModified)
+	}
+
+	if *writes != 1 {
+		t.Errorf("\nEXPECTED: %d write(s) \nACTUAL: %d", 1, *writes)
+	}
+
+	if file.Data[vaultMountToken] != "kv" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "kv", file.Data[vaultMountToken])
+	}
+}
+
+func TestHashicorpVaultPullNotFound(t *testing.T) {
+	// arrange
+	server, _ := fakeKV(t, time.Now())
+	defer server.Close()
+
+	s := HashicorpVaultStore{context: "my-app", addr: server.URL, mount: "kv", token: "test-token"}
+
+	// act
+	_, _, err := s.Pull(&catalog.File{Path: ".env", Type: "env"}, "")
+
+	// assert
+	if err == nil {
+		t.Errorf("\nEXPECTED: error \nACTUAL: nil")
+	}
+}
+
+func TestHashicorpVaultAccessDenied(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/token/lookup-self" {
+			w.Write([]byte(`{"data":{"display_name":"token-jane","policies":["default","dev"]}}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+	}))
+	defer server.Close()
+
+	s := HashicorpVaultStore{context: "my-app", addr: server.URL, mount: "kv", token: "test-token", authMethod: vaultAuthToken}
+
+	// act
+	_, _, err := s.Pull(&catalog.File{Path: ".env", Type: "env"}, "")
+
+	// assert
+	denied, ok := err.(contract.AccessDeniedError)
+	if !ok {
+		t.Fatalf("\nEXPECTED: %s \nACTUAL: %v", "access denied error", err)
+	}
+
+	if denied.Action != "read capability" || denied.Resource != "kv/data/my-app/.env" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s on %s", "read capability on kv/data/my-app/.env", denied.Action, denied.Resource)
+	}
+
+	if denied.Principal != "token-jane (policies default, dev)" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "token-jane (policies default, dev)", denied.Principal)
+	}
+}
+
+func TestHashicorpVaultJWTLogin(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		input := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Fatal(err)
+		}
+
+		if r.URL.Path != "/v1/auth/jwt/login" || input["role"] != "deploy" || input["jwt"] != "ci-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Write([]byte(`{"auth":{"client_token":"test-token","lease_duration":60}}`))
+	}))
+	defer server.Close()
+
+	os.Setenv("CSTORE_OIDC_TOKEN", "ci-token")
+	defer os.Unsetenv("CSTORE_OIDC_TOKEN")
+
+	s := HashicorpVaultStore{
+		addr:       server.URL,
+		authMethod: vaultAuthJWT,
+		authPath:   "jwt",
+		login:      map[string]interface{}{"role": "deploy"},
+	}
+
+	// act
+	err := s.authenticate()
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.token != "test-token" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "test-token", s.token)
+	}
+
+	if s.expires.IsZero() {
+		t.Error("expected the token expiration to be set")
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+// keyState is the value of a key in a revision of a file.
+type keyState struct {
+	revision string
+	modified time.Time
+	by       string
+	value    string
+	found    bool
+}
+
+// keyChanges returns the revisions where the key was added, changed, or
+// removed for stores saving keys together in a file. States must be
+// ordered oldest first.
+func keyChanges(states []keyState) []contract.KeyChange {
+	changes := []contract.KeyChange{}
+	previous := keyState{}
+
+	for _, state := range states {
+		if state.found == previous.found && state.value == previous.value {
+			continue
+		}
+
+		changes = append(changes, contract.KeyChange{
+			Revision: state.revision,
+			Modified: state.modified,
+			By:       state.by,
+			Removed:  !state.found,
+		})
+
+		previous = state
+	}
+
+	return changes
+}
+
+// revisionLayout formats the time of a revision built from key changes
+// as its id.
+const revisionLayout = "20060102T150405Z"
+
+// revisionWindow groups the key changes saved by the same push into one
+// revision.
+const revisionWindow = time.Minute
+
+// keyRevisions groups the changes to a file's keys into revisions of the
+// file for stores saving keys individually. A revision ends when no key
+// changes for the window. Revisions are returned newest first and are
+// identified by the time of their last change.
+func keyRevisions(changes []contract.KeyChange, window time.Duration) []contract.Revision {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Modified.Before(changes[j].Modified)
+	})
+
+	revisions := []contract.Revision{}
+
+	for i, c := range changes {
+		if i == 0 || c.Modified.Sub(changes[i-1].Modified) > window {
+			revisions = append([]contract.Revision{{}}, revisions...)
+		}
+
+		revisions[0].ID = c.Modified.UTC().Format(revisionLayout)
+		revisions[0].Modified = c.Modified
+		revisions[0].By = c.By
+	}
+
+	if len(revisions) > 0 {
+		revisions[0].Latest = true
+	}
+
+	return revisions
+}
+
+// revisionTime returns the time of a revision built from key changes.
+// Changes within the second of the id belong to the revision.
+func revisionTime(revision string) (time.Time, error) {
+	t, err := time.Parse(revisionLayout, revision)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("revision %s not found", revision)
+	}
+
+	return t.Add(time.Second - time.Nanosecond), nil
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+func TestKeyChanges(t *testing.T) {
+	// arrange
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	states := []keyState{
+		{revision: "1"},
+		{revision: "2", value: "a", found: true},
+		{revision: "3", value: "a", found: true},
+		{revision: "4", value: "b", found: true},
+		{revision: "5"},
+		{revision: "6"},
+		{revision: "7", value: "b", found: true},
+	}
+
+	for i := range states {
+		states[i].modified = start.Add(time.Duration(i) * time.Hour)
+	}
+
+	// act
+	changes := keyChanges(states)
+
+	// assert
+	expected := []string{"2", "4", "5 removed", "7"}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("\nEXPECTED: %v \nACTUAL: %v", expected, changes)
+	}
+
+	for i, c := range changes {
+		actual := c.Revision
+		if c.Removed {
+			actual += " removed"
+		}
+
+		if actual != expected[i] {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected[i], actual)
+		}
+	}
+
+	if !changes[1].Modified.Equal(start.Add(3 * time.Hour)) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", start.Add(3*time.Hour), changes[1].Modified)
+	}
+}
+
+func TestKeyRevisions(t *testing.T) {
+	// arrange
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	changes := []contract.KeyChange{
+		{Revision: "1", Modified: start.Add(2 * time.Hour), By: "ci"},
+		{Revision: "1", Modified: start},
+		{Revision: "1", Modified: start.Add(10 * time.Second)},
+		{Revision: "2", Modified: start.Add(2*time.Hour + 30*time.Second), By: "jane"},
+	}
+
+	// act
+	revisions := keyRevisions(changes, time.Minute)
+
+	// assert
+	expected := []string{"20260301T140030Z", "20260301T120010Z"}
+
+	if len(revisions) != len(expected) {
+		t.Fatalf("\nEXPECTED: %v \nACTUAL: %v", expected, revisions)
+	}
+
+	for i, r := range revisions {
+		if r.ID != expected[i] {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected[i], r.ID)
+		}
+	}
+
+	if !revisions[0].Latest || revisions[1].Latest || revisions[0].By != "jane" {
+		t.Errorf("\nUNEXPECTED: %v", revisions)
+	}
+
+	asOf, err := revisionTime(revisions[1].ID)
+	if err != nil || asOf.Before(start.Add(10*time.Second)) || !asOf.Before(start.Add(11*time.Second)) {
+		t.Errorf("\nUNEXPECTED: %s (%v)", asOf, err)
+	}
+}
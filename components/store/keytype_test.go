@@ -0,0 +1,79 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/turnerlabs/cstore/components/catalog"
+)
+
+func TestHarborKeyType(t *testing.T) {
+	// arrange
+	file := &catalog.File{
+		KeyTypes: map[string]string{
+			"URL":      catalog.KeyTypePlain,
+			"API_HOST": catalog.KeyTypeReference,
+			"TOKEN":    catalog.KeyTypeGenerated,
+		},
+		Data: map[string]string{
+			"ENV_URL":    envTypeHidden,
+			"ENV_LEGACY": envTypeBasic,
+		},
+	}
+
+	remote := map[string]string{
+		"URL":     envTypeHidden,
+		"LEGACY":  "",
+		"CHANGED": envTypeBasic,
+	}
+
+	tests := map[string]string{
+		"URL":      envTypeBasic,
+		"API_HOST": envTypeDiscover,
+		"TOKEN":    envTypeHidden,
+		"LEGACY":   envTypeBasic,
+		"CHANGED":  envTypeBasic,
+		"PASSWORD": envTypeHidden,
+	}
+
+	for key, expected := range tests {
+		// act
+		actual := harborKeyType(file, key, remote[key])
+
+		// assert
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+		}
+	}
+}
+
+func TestParamType(t *testing.T) {
+	// arrange
+	file := &catalog.File{
+		KeyTypes: map[string]string{
+			"URL":    catalog.KeyTypePlain,
+			"SECRET": catalog.KeyTypeSecret,
+		},
+	}
+
+	input := ssm.PutParameterInput{
+		Type: aws.String(ssm.ParameterTypeString),
+	}
+
+	tests := map[string]string{
+		"URL":    ssm.ParameterTypeString,
+		"SECRET": ssm.ParameterTypeSecureString,
+		"OTHER":  ssm.ParameterTypeString,
+	}
+
+	for key, expected := range tests {
+		// act
+		actual, _ := paramType(file, key, input)
+
+		// assert
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+		}
+	}
+}
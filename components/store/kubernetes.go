@@ -0,0 +1,415 @@
+package store
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/turnerlabs/cstore/components/contract"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	kubeConfigEnv = "KUBECONFIG"
+
+	// kubeServiceAccount is where Kubernetes mounts the service account
+	// credentials in a pod.
+	kubeServiceAccount = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+// kubeConfig is the subset of a kubeconfig file needed to reach a
+// cluster.
+type kubeConfig struct {
+	CurrentContext string `yaml:"current-context"`
+
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+
+	Users []struct {
+		Name string   `yaml:"name"`
+		User kubeUser `yaml:"user"`
+	} `yaml:"users"`
+}
+
+type kubeUser struct {
+	Token                 string    `yaml:"token"`
+	TokenFile             string    `yaml:"tokenFile"`
+	ClientCertificate     string    `yaml:"client-certificate"`
+	ClientCertificateData string    `yaml:"client-certificate-data"`
+	ClientKey             string    `yaml:"client-key"`
+	ClientKeyData         string    `yaml:"client-key-data"`
+	Username              string    `yaml:"username"`
+	Password              string    `yaml:"password"`
+	Exec                  *kubeExec `yaml:"exec"`
+}
+
+// kubeExec is a credential plugin, like the ones used by EKS and GKE,
+// run to get a token.
+type kubeExec struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Command    string   `yaml:"command"`
+	Args       []string `yaml:"args"`
+	Env        []struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	} `yaml:"env"`
+}
+
+// kubeClient sends requests to the Kubernetes API.
+type kubeClient struct {
+	server    string
+	namespace string
+	user      string
+	source    string
+
+	client    *http.Client
+	token     string
+	tokenFile string
+	username  string
+	password  string
+	exec      *kubeExec
+	expires   time.Time
+}
+
+// kubeConfigPath returns the kubeconfig file set by KUBECONFIG or the
+// default file. Only the first file in KUBECONFIG is used.
+func kubeConfigPath() string {
+	if paths := filepath.SplitList(os.Getenv(kubeConfigEnv)); len(paths) > 0 && len(paths[0]) > 0 {
+		return paths[0]
+	}
+
+	home, _ := homedir.Dir()
+
+	return filepath.Join(home, ".kube", "config")
+}
+
+// kubeInCluster returns true when running in a pod with a service
+// account and KUBECONFIG is not set.
+func kubeInCluster() bool {
+	if len(os.Getenv(kubeConfigEnv)) > 0 || len(os.Getenv("KUBERNETES_SERVICE_HOST")) == 0 {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(kubeServiceAccount, "token"))
+	return err == nil
+}
+
+// readKubeConfig reads the kubeconfig file.
+func readKubeConfig(path string) (kubeConfig, error) {
+	config := kubeConfig{}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("could not read kubeconfig (%s)", err)
+	}
+
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return config, fmt.Errorf("could not parse kubeconfig %s (%s)", path, err)
+	}
+
+	return config, nil
+}
+
+// client returns a client for the cluster and user of the context. The
+// current context is used when "context" is empty.
+func (c kubeConfig) client(context, dir string) (*kubeClient, error) {
+	if len(context) == 0 {
+		context = c.CurrentContext
+	}
+
+	kc := &kubeClient{source: fmt.Sprintf("kubeconfig context %s", context)}
+
+	found := false
+	clusterName := ""
+
+	for _, ctx := range c.Contexts {
+		if ctx.Name == context {
+			found = true
+			clusterName, kc.user, kc.namespace = ctx.Context.Cluster, ctx.Context.User, ctx.Context.Namespace
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("context %s not found in kubeconfig", context)
+	}
+
+	tlsConfig := &tls.Config{}
+
+	for _, cluster := range c.Clusters {
+		if cluster.Name != clusterName {
+			continue
+		}
+
+		kc.server = strings.TrimRight(cluster.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = cluster.Cluster.InsecureSkipTLSVerify
+
+		ca, err := kubeData(cluster.Cluster.CertificateAuthorityData, cluster.Cluster.CertificateAuthority, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ca) > 0 {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("invalid certificate authority for cluster %s", clusterName)
+			}
+		}
+	}
+
+	if len(kc.server) == 0 {
+		return nil, fmt.Errorf("cluster %s not found in kubeconfig", clusterName)
+	}
+
+	for _, user := range c.Users {
+		if user.Name != kc.user {
+			continue
+		}
+
+		u := user.User
+		kc.token, kc.username, kc.password, kc.exec = u.Token, u.Username, u.Password, u.Exec
+
+		if len(u.TokenFile) > 0 {
+			kc.tokenFile = kubePath(u.TokenFile, dir)
+		}
+
+		cert, err := kubeData(u.ClientCertificateData, u.ClientCertificate, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := kubeData(u.ClientKeyData, u.ClientKey, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cert) > 0 {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid client certificate for user %s (%s)", kc.user, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+	}
+
+	kc.client = &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+
+	return kc, nil
+}
+
+// inClusterClient returns a client using the pod's service account.
+func inClusterClient() (*kubeClient, error) {
+	ca, err := ioutil.ReadFile(filepath.Join(kubeServiceAccount, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+
+	namespace, _ := ioutil.ReadFile(filepath.Join(kubeServiceAccount, "namespace"))
+
+	return &kubeClient{
+		server:    fmt.Sprintf("https://%s:%s", os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")),
+		namespace: strings.TrimSpace(string(namespace)),
+		user:      "pod service account",
+		source:    "in-cluster service account",
+		tokenFile: filepath.Join(kubeServiceAccount, "token"),
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+	}, nil
+}
+
+// kubeData returns base64 encoded data or the contents of the file.
+func kubeData(data, path, dir string) ([]byte, error) {
+	if len(data) > 0 {
+		return base64.StdEncoding.DecodeString(data)
+	}
+
+	if len(path) > 0 {
+		return ioutil.ReadFile(kubePath(path, dir))
+	}
+
+	return nil, nil
+}
+
+// kubePath resolves paths relative to the kubeconfig's folder.
+func kubePath(path, dir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// authenticate reads the token file or runs the credential plugin to
+// get a new token.
+func (kc *kubeClient) authenticate() error {
+	switch {
+	case len(kc.tokenFile) > 0:
+		b, err := ioutil.ReadFile(kc.tokenFile)
+		if err != nil {
+			return err
+		}
+
+		kc.token = strings.TrimSpace(string(b))
+
+	case kc.exec != nil:
+		cmd := exec.Command(kc.exec.Command, kc.exec.Args...)
+		cmd.Env = os.Environ()
+		cmd.Stderr = os.Stderr
+
+		for _, e := range kc.exec.Env {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", e.Name, e.Value))
+		}
+
+		cmd.Env = append(cmd.Env, fmt.Sprintf(`KUBERNETES_EXEC_INFO={"apiVersion":"%s","kind":"ExecCredential","spec":{"interactive":false}}`, kc.exec.APIVersion))
+
+		out, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("kubeconfig credential plugin %s failed (%s)", kc.exec.Command, err)
+		}
+
+		credential := struct {
+			Status struct {
+				Token               string    `json:"token"`
+				ExpirationTimestamp time.Time `json:"expirationTimestamp"`
+			} `json:"status"`
+		}{}
+
+		if err := json.Unmarshal(out, &credential); err != nil {
+			return fmt.Errorf("kubeconfig credential plugin %s returned invalid output (%s)", kc.exec.Command, err)
+		}
+
+		kc.token, kc.expires = credential.Status.Token, credential.Status.ExpirationTimestamp
+	}
+
+	return nil
+}
+
+// call sends a request to the Kubernetes API returning the response
+// status. Responses other than 200 and 201 are returned as errors.
+func (kc *kubeClient) call(method, path string, input, output interface{}) (int, error) {
+	var body []byte
+
+	if input != nil {
+		b, err := json.Marshal(input)
+		if err != nil {
+			return 0, err
+		}
+		body = b
+	}
+
+	req, err := http.NewRequest(method, kc.server+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	switch {
+	case len(kc.token) > 0:
+		req.Header.Set("Authorization", "Bearer "+kc.token)
+	case len(kc.username) > 0:
+		req.SetBasicAuth(kc.username, kc.password)
+	}
+
+	resp, err := kc.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	status := struct {
+		Message string `json:"message"`
+	}{}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+	case http.StatusForbidden:
+		json.Unmarshal(b, &status)
+
+		return resp.StatusCode, contract.AccessDeniedError{
+			Action:    fmt.Sprintf("%s %s", kubeVerb(method), kubeResource(path)),
+			Resource:  path,
+			Principal: kc.user,
+			Err:       errors.New(status.Message),
+		}
+	default:
+		if err := json.Unmarshal(b, &status); err != nil || len(status.Message) == 0 {
+			status.Message = strings.TrimSpace(string(b))
+		}
+
+		return resp.StatusCode, fmt.Errorf("kubernetes %s %s: %d %s", method, path, resp.StatusCode, status.Message)
+	}
+
+	if output == nil {
+		return resp.StatusCode, nil
+	}
+
+	return resp.StatusCode, json.Unmarshal(b, output)
+}
+
+// kubeVerb returns the RBAC verb a request needs.
+func kubeVerb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "get"
+	}
+}
+
+// kubeResource returns the resource type in an API path.
+func kubeResource(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, part := range parts {
+		if part == "namespaces" && i+2 < len(parts) {
+			return parts[i+2]
+		}
+	}
+
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,482 @@
+package store
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/setting"
+)
+
+const (
+	k8sNamespaceToken = "K8S_NAMESPACE"
+	k8sKindToken      = "K8S_KIND"
+
+	// k8sContextEnv is the environment variable set to use a kubeconfig
+	// context other than the current context.
+	k8sContextEnv = "K8S_CONTEXT"
+
+	k8sKindSecret    = "secret"
+	k8sKindConfigMap = "configmap"
+
+	k8sManagedBy          = "app.kubernetes.io/managed-by"
+	k8sContextAnnotation  = "cstore.io/context"
+	k8sPathAnnotation     = "cstore.io/path"
+	k8sModifiedAnnotation = "cstore.io/modified"
+)
+
+var (
+	k8sInvalidNameChars = regexp.MustCompile("[^a-z0-9-]+")
+	k8sValidKey         = regexp.MustCompile("^[-._a-zA-Z0-9]+$")
+
+	errObjectNotFound = errors.New("object not found, verify the namespace and cluster access")
+)
+
+// KubernetesStore ...
+type KubernetesStore struct {
+	context   string
+	namespace string
+	name      string
+	kind      string
+
+	client *kubeClient
+
+	io models.IO
+}
+
+// Name ...
+func (s KubernetesStore) Name() string {
+	return "kubernetes"
+}
+
+// SupportsFeature ...
+func (s KubernetesStore) SupportsFeature(feature string) bool {
+	switch feature {
+	case VersionFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFileType ...
+func (s KubernetesStore) SupportsFileType(fileType string) bool {
+	switch fileType {
+	case EnvFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// BlobLimits ...
+func (s KubernetesStore) BlobLimits() (int, int) {
+	// objects are limited to 1 MB after secret data is base64 encoded
+	return 0, 700 * 1024
+}
+
+// Description ...
+func (s KubernetesStore) Description() string {
+	return `
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/KUBERNETES.md
+`
+}
+
+// Pre ...
+func (s *KubernetesStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.context = clog.Context
+	s.io = io
+
+	if kubeInCluster() {
+		client, err := inClusterClient()
+		if err != nil {
+			return err
+		}
+		s.client = client
+	} else {
+		path := kubeConfigPath()
+
+		config, err := readKubeConfig(path)
+		if err != nil {
+			return err
+		}
+
+		client, err := config.client(os.Getenv(k8sContextEnv), filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		s.client = client
+	}
+
+	defaultNamespace := s.client.namespace
+	if len(defaultNamespace) == 0 {
+		defaultNamespace = "default"
+	}
+
+	namespace, err := (setting.Setting{
+		Description:  "Kubernetes namespace the file is saved in.",
+		Group:        "K8S",
+		Prop:         "NAMESPACE",
+		DefaultValue: clog.GetAnyDataBy(k8sNamespaceToken, defaultNamespace),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.namespace = namespace
+
+	name, err := (setting.Setting{
+		Description:  "Name of the Secret or ConfigMap the file is saved in.",
+		Group:        "K8S",
+		Prop:         "NAME",
+		DefaultValue: K8sName(file.Path),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.name = name
+
+	//------------------------------------------
+	//- The store command chooses the object
+	//- kind and replaces the saved kind.
+	//------------------------------------------
+	switch uo.StoreCommand {
+	case k8sKindSecret, k8sKindConfigMap:
+		if err := file.Set(clog.Context, "K8S", "KIND", uo.StoreCommand); err != nil {
+			return err
+		}
+	}
+
+	kind, err := (setting.Setting{
+		Description:  fmt.Sprintf("OPTIONS\n %s (values are base64 encoded in a Secret)\n %s (values are saved in plain text in a ConfigMap)", k8sKindSecret, k8sKindConfigMap),
+		Group:        "K8S",
+		Prop:         "KIND",
+		DefaultValue: k8sKindSecret,
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	if kind != k8sKindSecret && kind != k8sKindConfigMap {
+		return fmt.Errorf("unsupported %s %s, expected %s or %s", k8sKindToken, kind, k8sKindSecret, k8sKindConfigMap)
+	}
+
+	s.kind = kind
+
+	return s.Refresh()
+}
+
+// Push ...
+func (s KubernetesStore) Push(file *catalog.File, fileData []byte, version string) error {
+
+	if len(fileData) == 0 {
+		return errors.New("empty file")
+	}
+
+	values := gotenv.Parse(bytes.NewReader(fileData))
+	if len(values) == 0 {
+		return errors.New("failed to parse environment variables")
+	}
+
+	for key := range values {
+		if !k8sValidKey.MatchString(key) {
+			return fmt.Errorf("%s cannot be saved in a %s, keys can only contain letters, numbers, dashes, underscores, and dots", key, s.kind)
+		}
+	}
+
+	data := map[string]string{}
+	for key, value := range values {
+		data[key] = s.encode(value)
+	}
+
+	stored, found, err := s.get(version)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		if stored.Metadata.Labels[k8sManagedBy] != "cstore" {
+			return fmt.Errorf("%s %s in namespace %s was not created by cstore and will not be replaced", s.kind, stored.Metadata.Name, s.namespace)
+		}
+
+		if reflect.DeepEqual(stored.Data, data) {
+			return nil
+		}
+	}
+
+	object := kubeObject{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Type:       "Opaque",
+		Metadata: kubeMetadata{
+			Name:      s.objectName(version),
+			Namespace: s.namespace,
+			Labels:    map[string]string{k8sManagedBy: "cstore"},
+			Annotations: map[string]string{
+				k8sContextAnnotation:  s.context,
+				k8sPathAnnotation:     file.Path,
+				k8sModifiedAnnotation: time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+		Data: data,
+	}
+
+	if s.kind == k8sKindConfigMap {
+		object.Kind, object.Type = "ConfigMap", ""
+	}
+
+	//------------------------------------------
+	//- Replacing an object sends the version
+	//- read; so, a change made since then is
+	//- rejected instead of overwritten.
+	//------------------------------------------
+	if found {
+		object.Metadata.ResourceVersion = stored.Metadata.ResourceVersion
+
+		_, err = s.client.call(http.MethodPut, s.objectPath(version), object, nil)
+		return err
+	}
+
+	_, err = s.client.call(http.MethodPost, s.collectionPath(), object, nil)
+	return err
+}
+
+// Pull ...
+func (s KubernetesStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+
+	stored, found, err := s.get(version)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	if !found {
+		return []byte{}, contract.Attributes{}, errObjectNotFound
+	}
+
+	values := map[string]string{}
+
+	for key, value := range stored.Data {
+		decoded, err := s.decode(value)
+		if err != nil {
+			return []byte{}, contract.Attributes{}, fmt.Errorf("could not decode %s (%s)", key, err)
+		}
+		values[key] = decoded
+	}
+
+	return formatEnv(values), contract.Attributes{LastModified: stored.modified()}, nil
+}
+
+// Purge ...
+func (s KubernetesStore) Purge(file *catalog.File, version string) error {
+	status, err := s.client.call(http.MethodDelete, s.objectPath(version), nil, nil)
+	if err != nil && status != http.StatusNotFound {
+		return err
+	}
+
+	return nil
+}
+
+// Changed ...
+func (s KubernetesStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+	stored, found, err := s.get(version)
+	if err != nil || !found {
+		return time.Time{}, err
+	}
+
+	return stored.modified(), nil
+}
+
+// ETag ...
+func (s KubernetesStore) ETag(file *catalog.File, version string) (string, error) {
+	stored, found, err := s.get(version)
+	if err != nil || !found {
+		return "", err
+	}
+
+	return etagOf([]string{stored.Metadata.UID, stored.Metadata.ResourceVersion}), nil
+}
+
+// Encryption ...
+func (s KubernetesStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	location, err := s.Locate(file, "", version)
+
+	//------------------------------------------
+	//- Secrets are only base64 encoded unless
+	//- the cluster encrypts etcd, which cannot
+	//- be read through the API.
+	//------------------------------------------
+	mechanism := contract.EncryptionUnknown
+	if s.kind == k8sKindConfigMap {
+		mechanism = contract.EncryptionNone
+	}
+
+	return contract.Encryption{Mechanism: mechanism, Detail: location.Encryption}, nil, err
+}
+
+// Locate ...
+func (s KubernetesStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	remote := s.client.server + s.objectPath(version)
+	if len(key) > 0 {
+		remote = fmt.Sprintf("%s (key %s)", remote, key)
+	}
+
+	encryption := "Kubernetes Secret, encrypted at rest only when the cluster enables etcd encryption"
+	if s.kind == k8sKindConfigMap {
+		encryption = "none, ConfigMap data is saved in plain text"
+	}
+
+	return contract.Location{
+		Remote:      remote,
+		Credentials: fmt.Sprintf("%s (user %s)", s.client.source, s.client.user),
+		Encryption:  encryption,
+	}, nil
+}
+
+// Expires ...
+func (s KubernetesStore) Expires() time.Time {
+	if s.client == nil {
+		return time.Time{}
+	}
+	return s.client.expires
+}
+
+// Refresh ...
+func (s *KubernetesStore) Refresh() error {
+	return s.client.authenticate()
+}
+
+func init() {
+	s := new(KubernetesStore)
+	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: k8sNamespaceToken, Required: true},
+		{Key: "K8S_NAME"},
+		{Key: k8sKindToken, Values: []string{k8sKindSecret, k8sKindConfigMap}},
+	})
+}
+
+//------------------------------------------
+//- Kubernetes API helpers.
+//------------------------------------------
+
+type kubeObject struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   kubeMetadata      `json:"metadata"`
+	Type       string            `json:"type,omitempty"`
+	Data       map[string]string `json:"data"`
+}
+
+type kubeMetadata struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace,omitempty"`
+	UID               string            `json:"uid,omitempty"`
+	ResourceVersion   string            `json:"resourceVersion,omitempty"`
+	CreationTimestamp string            `json:"creationTimestamp,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+}
+
+// modified returns when cstore last pushed the object or when the
+// object was created by another tool.
+func (o kubeObject) modified() time.Time {
+	for _, value := range []string{o.Metadata.Annotations[k8sModifiedAnnotation], o.Metadata.CreationTimestamp} {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// K8sName returns a valid Kubernetes object name for the file path, like
+// "config-dev-env" for "config/dev/.env".
+func K8sName(path string) string {
+	name := strings.Trim(k8sInvalidNameChars.ReplaceAllString(strings.ToLower(path), "-"), "-")
+
+	if len(name) > 200 {
+		name = strings.Trim(name[len(name)-200:], "-")
+	}
+
+	if len(name) == 0 {
+		return "cstore"
+	}
+
+	return name
+}
+
+func (s KubernetesStore) objectName(version string) string {
+	if len(version) == 0 {
+		return s.name
+	}
+	return fmt.Sprintf("%s-%s", s.name, K8sName(version))
+}
+
+func (s KubernetesStore) collectionPath() string {
+	resource := "secrets"
+	if s.kind == k8sKindConfigMap {
+		resource = "configmaps"
+	}
+
+	return fmt.Sprintf("/api/v1/namespaces/%s/%s", s.namespace, resource)
+}
+
+func (s KubernetesStore) objectPath(version string) string {
+	return fmt.Sprintf("%s/%s", s.collectionPath(), s.objectName(version))
+}
+
+// get returns the object and false when it does not exist.
+func (s KubernetesStore) get(version string) (kubeObject, bool, error) {
+	object := kubeObject{}
+
+	status, err := s.client.call(http.MethodGet, s.objectPath(version), nil, &object)
+	if status == http.StatusNotFound {
+		return object, false, nil
+	}
+
+	return object, err == nil, err
+}
+
+func (s KubernetesStore) encode(value string) string {
+	if s.kind == k8sKindConfigMap {
+		return value
+	}
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+func (s KubernetesStore) decode(value string) (string, error) {
+	if s.kind == k8sKindConfigMap {
+		return value, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(value)
+	return string(b), err
+}
@@ -0,0 +1,252 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+)
+
+// fakeKubernetes serves the Secret and ConfigMap endpoints used by the
+// store keeping objects by path.
+func fakeKubernetes(t *testing.T) (*httptest.Server, map[string]kubeObject) {
+	objects := map[string]kubeObject{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path := r.URL.Path
+
+		switch r.Method {
+		case http.MethodGet:
+			object, found := objects[path]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"message":"not found"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(object)
+
+		case http.MethodPost, http.MethodPut:
+			object := kubeObject{}
+			if err := json.NewDecoder(r.Body).Decode(&object); err != nil {
+				t.Fatal(err)
+			}
+
+			if r.Method == http.MethodPost {
+				path = fmt.Sprintf("%s/%s", path, object.Metadata.Name)
+				object.Metadata.UID = "uid-" + object.Metadata.Name
+				object.Metadata.ResourceVersion = "1"
+			} else {
+				if objects[path].Metadata.ResourceVersion != object.Metadata.ResourceVersion {
+					w.WriteHeader(http.StatusConflict)
+					w.Write([]byte(`{"message":"the object has been modified"}`))
+					return
+				}
+				object.Metadata.UID = objects[path].Metadata.UID
+				object.Metadata.ResourceVersion += "1"
+			}
+
+			objects[path] = object
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(object)
+
+		case http.MethodDelete:
+			if _, found := objects[path]; !found {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(objects, path)
+			w.Write([]byte(`{}`))
+		}
+	}))
+
+	return server, objects
+}
+
+func testKubernetesStore(server, kind string) KubernetesStore {
+	return KubernetesStore{
+		context:   "my-app",
+		namespace: "team",
+		name:      "my-app-env",
+		kind:      kind,
+		client:    &kubeClient{server: server, token: "test-token", client: http.DefaultClient},
+	}
+}
+
+func TestKubernetesPushPullSecret(t *testing.T) {
+	// arrange
+	server, objects := fakeKubernetes(t)
+	defer server.Close()
+
+	s := testKubernetesStore(server.URL, k8sKindSecret)
+	file := catalog.File{Path: ".env", Type: "env"}
+
+	// act
+	if err := s.Push(&file, []byte("B=2\nA=1\nC=3\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Push(&file, []byte("A=1\nB=20\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	data, attr, err := s.Pull(&file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	object := objects["/api/v1/namespaces/team/secrets/my-app-env"]
+
+	if object.Data["B"] != "MjA=" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "MjA=", object.Data["B"])
+	}
+
+	if object.Metadata.Labels[k8sManagedBy] != "cstore" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "cstore", object.Metadata.Labels[k8sManagedBy])
+	}
+
+	expected := "A=1\nB=20\n"
+	if string(data) != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, string(data))
+	}
+
+	if attr.LastModified.IsZero() {
+		t.Error("expected last modified time")
+	}
+}
+
+func TestKubernetesPushConfigMapVersion(t *testing.T) {
+	// arrange
+	server, objects := fakeKubernetes(t)
+	defer server.Close()
+
+	s := testKubernetesStore(server.URL, k8sKindConfigMap)
+	file := catalog.File{Path: ".env", Type: "env"}
+
+	// act
+	if err := s.Push(&file, []byte("A=1\n"), "v1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	object, found := objects["/api/v1/namespaces/team/configmaps/my-app-env-v1-0"]
+	if !found {
+		t.Fatal("configmap not created")
+	}
+
+	if object.Kind != "ConfigMap" || object.Data["A"] != "1" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s %v", "ConfigMap A=1", object.Kind, object.Data)
+	}
+
+	if err := s.Purge(&file, "v1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := objects["/api/v1/namespaces/team/configmaps/my-app-env-v1-0"]; found {
+		t.Error("configmap not deleted")
+	}
+}
+
+func TestKubernetesPushUnmanagedObject(t *testing.T) {
+	// arrange
+	server, objects := fakeKubernetes(t)
+	defer server.Close()
+
+	objects["/api/v1/namespaces/team/secrets/my-app-env"] = kubeObject{Kind: "Secret", Metadata: kubeMetadata{Name: "my-app-env"}}
+
+	s := testKubernetesStore(server.URL, k8sKindSecret)
+	file := catalog.File{Path: ".env", Type: "env"}
+
+	// act
+	err := s.Push(&file, []byte("A=1\n"), "")
+
+	// assert
+	if err == nil || !strings.Contains(err.Error(), "not created by cstore") {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "not created by cstore", err)
+	}
+}
+
+func TestKubeConfigClient(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ioutil.WriteFile(filepath.Join(dir, "token"), []byte("file-token\n"), 0600)
+
+	config := `
+current-context: dev
+clusters:
+- name: dev-cluster
+  cluster:
+    server: https://dev.example.com/
+contexts:
+- name: dev
+  context:
+    cluster: dev-cluster
+    user: dev-user
+    namespace: team
+users:
+- name: dev-user
+  user:
+    tokenFile: token
+`
+	path := filepath.Join(dir, "config")
+	ioutil.WriteFile(path, []byte(config), 0600)
+
+	// act
+	kc, err := readKubeConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := kc.client("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.authenticate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	actual := fmt.Sprintf("%s %s %s", client.server, client.namespace, client.token)
+	expected := "https://dev.example.com team file-token"
+
+	if actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
+
+func TestK8sName(t *testing.T) {
+	// arrange
+	paths := map[string]string{
+		"config/dev/.env": "config-dev-env",
+		".env":            "env",
+		"App_1.env":       "app-1-env",
+	}
+
+	for path, expected := range paths {
+		// act
+		actual := K8sName(path)
+
+		// assert
+		if actual != expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+)
+
+// keyModifiedPrefix identifies file data recording when a key was last
+// modified for stores without native key timestamps.
+const keyModifiedPrefix = "MODIFIED_"
+
+func keyModifiedToken(key, version string) string {
+	if len(version) > 0 {
+		return fmt.Sprintf("%s%s_%s", keyModifiedPrefix, strings.ToUpper(version), key)
+	}
+	return keyModifiedPrefix + key
+}
+
+// recordKeysModified saves the current time for each pushed key that is
+// new or has a different value than the stored key, and removes the
+// records of keys no longer pushed.
+func recordKeysModified(file *catalog.File, stored, pushed map[string]string, version string, now time.Time) {
+	for key, value := range pushed {
+		if storedValue, found := stored[key]; found && storedValue == value {
+			if _, recorded := file.Data[keyModifiedToken(key, version)]; recorded {
+				continue
+			}
+		}
+
+		file.AddData(map[string]string{
+			keyModifiedToken(key, version): now.UTC().Format(time.RFC3339),
+		})
+	}
+
+	for key := range stored {
+		if _, found := pushed[key]; !found {
+			delete(file.Data, keyModifiedToken(key, version))
+		}
+	}
+}
+
+// recordedKeysModified returns the recorded modification times for the
+// keys. Keys without a record map to time.Time{}.
+func recordedKeysModified(file *catalog.File, keys []string, version string) map[string]time.Time {
+	modified := map[string]time.Time{}
+
+	for _, key := range keys {
+		modified[key] = time.Time{}
+
+		if value, found := file.Data[keyModifiedToken(key, version)]; found {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				modified[key] = t
+			}
+		}
+	}
+
+	return modified
+}
+
+func init() {
+	catalog.RegisterCommonData(catalog.DataField{Key: keyModifiedPrefix, Prefix: true})
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+)
+
+func TestRecordKeysModified(t *testing.T) {
+	// arrange
+	earlier := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	file := catalog.File{Data: map[string]string{
+		keyModifiedToken("URL", ""):         earlier.Format(time.RFC3339),
+		keyModifiedToken("DB_PASSWORD", ""): earlier.Format(time.RFC3339),
+		keyModifiedToken("REMOVED", ""):     earlier.Format(time.RFC3339),
+	}}
+
+	stored := map[string]string{"URL": "a", "DB_PASSWORD": "old", "REMOVED": "x"}
+	pushed := map[string]string{"URL": "a", "DB_PASSWORD": "new", "ADDED": "y"}
+
+	// act
+	recordKeysModified(&file, stored, pushed, "", now)
+	modified := recordedKeysModified(&file, []string{"URL", "DB_PASSWORD", "ADDED", "REMOVED"}, "")
+
+	// assert
+	expected := map[string]time.Time{
+		"URL":         earlier,
+		"DB_PASSWORD": now,
+		"ADDED":       now,
+		"REMOVED":     time.Time{},
+	}
+
+	for key, e := range expected {
+		if !modified[key].Equal(e) {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s (%s)", e, modified[key], key)
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/cipher"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/prompt"
+	"github.com/turnerlabs/cstore/components/setting"
+)
+
+const (
+	orasCLI = "oras"
+
+	ociRepository = "OCI_REPOSITORY"
+	ociDigest     = "OCI_DIGEST"
+
+	masterKeyToken = "MASTER_KEY"
+
+	ociBundleName      = "bundle"
+	ociBundleMediaType = "application/vnd.cstore.bundle.v1+encrypted"
+	ociCreated         = "org.opencontainers.image.created"
+)
+
+var ociDigestRegex = regexp.MustCompile(`sha256:[a-f0-9]{64}`)
+
+// OCIStore ...
+type OCIStore struct {
+	context  string
+	settings map[string]setting.Setting
+
+	io models.IO
+}
+
+// Name ...
+func (s OCIStore) Name() string {
+	return "oci"
+}
+
+// SupportsFeature ...
+func (s OCIStore) SupportsFeature(feature string) bool {
+	switch feature {
+	case VersionFeature, FIPSFeature, HashedKeyFeature, PipelineFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFileType ...
+func (s OCIStore) SupportsFileType(fileType string) bool {
+	return true
+}
+
+// Description ...
+func (s OCIStore) Description() string {
+	return `
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/OCI.md
+`
+}
+
+// Pre ...
+func (s *OCIStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.settings = map[string]setting.Setting{}
+	s.context = clog.Context
+	s.io = io
+
+	if _, err := exec.LookPath(orasCLI); err != nil {
+		return errors.New("ORAS CLI (oras) not found, install it and authenticate with the registry before using this store")
+	}
+
+	//------------------------------------------
+	//- Store Configuration
+	//------------------------------------------
+	s.settings[ociRepository] = setting.Setting{
+		Description:  "Registry repository that will store the file. (example: ghcr.io/org/config)",
+		Group:        "OCI",
+		Prop:         "REPOSITORY",
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		DefaultValue: clog.GetAnyDataBy(ociRepository, ""),
+		Vault:        file,
+		Shared:       true,
+	}
+
+	//------------------------------------------
+	//- Encryption
+	//------------------------------------------
+	if _, pushed := file.Data[ociRepository]; !pushed {
+		if _, err := access.Get(clog.Context, "CSTORE", "MASTER_KEY"); err == nil {
+			file.AddData(map[string]string{
+				keyDerivationToken: keyDerivationHKDF,
+			})
+		}
+	}
+
+	if file.Data[keyDerivationToken] == keyDerivationHKDF {
+		s.settings[masterKeyToken] = setting.Setting{
+			Description: "Team master key used to derive a separate encryption key for each file. Anyone pulling the file will need this key.",
+			Group:       "CSTORE",
+			Prop:        "MASTER_KEY",
+			Prompt:      uo.Prompt,
+			HideInput:   true,
+			AutoSave:    true,
+			Vault:       access,
+		}
+
+		return nil
+	}
+
+	s.settings[clientEncryptionToken] = setting.Setting{
+		Description:  "32 character key used to encrypt the file before it is pushed to the registry. Anyone pulling the file will need this key.",
+		Group:        "CSTORE",
+		Prop:         "ENCRYPTION_KEY",
+		Prompt:       uo.Prompt,
+		HideInput:    true,
+		AutoSave:     true,
+		DefaultValue: cipher.GenerateAES256Key(),
+		Vault:        access,
+	}
+
+	return nil
+}
+
+// Push ...
+func (s OCIStore) Push(file *catalog.File, fileData []byte, version string) error {
+
+	if len(fileData) == 0 {
+		return errors.New("empty file")
+	}
+
+	repository, err := s.setting(ociRepository)
+	if err != nil {
+		return err
+	}
+
+	file.AddData(map[string]string{
+		ociRepository: repository,
+	})
+
+	encrypted, err := s.encrypt(file, version, fileData)
+	if err != nil {
+		return err
+	}
+
+	//------------------------------------------
+	//- Push the encrypted bundle as an artifact
+	//------------------------------------------
+	dir, err := ioutil.TempDir("", "cstore-oci")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, ociBundleName), encrypted, 0600); err != nil {
+		return err
+	}
+
+	c := exec.Command(orasCLI, "push", s.ref(repository, *file, version), fmt.Sprintf("%s:%s", ociBundleName, ociBundleMediaType))
+	c.Dir = dir
+
+	out, err := run(c)
+	if err != nil {
+		return err
+	}
+
+	//------------------------------------------
+	//- Pin the pushed digest in the catalog
+	//------------------------------------------
+	digest := ociDigestRegex.FindString(string(out))
+	if len(digest) == 0 {
+		return errors.New("registry did not return a digest for the pushed artifact")
+	}
+
+	file.AddData(map[string]string{
+		digestKey(version): digest,
+	})
+
+	return nil
+}
+
+// Pull ...
+func (s OCIStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+
+	encrypted, ref, err := s.fetch(file, version)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	b, err := s.decrypt(file, encrypted)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	created, _ := s.created(ref)
+
+	return b, contract.Attributes{
+		LastModified: created,
+	}, nil
+}
+
+// Purge ...
+func (s OCIStore) Purge(file *catalog.File, version string) error {
+
+	repository, err := s.setting(ociRepository)
+	if err != nil {
+		return err
+	}
+
+	ref := s.ref(repository, *file, version)
+
+	confirmed, err := prompt.Confirm(fmt.Sprintf("  - %s\n \n  Delete artifact?", ref), prompt.Danger, s.io)
+	if err != nil {
+		return err
+	}
+
+	if !confirmed {
+		return errors.New("user aborted")
+	}
+
+	if _, err := run(exec.Command(orasCLI, "manifest", "delete", "--force", ref)); err != nil {
+		return err
+	}
+
+	delete(file.Data, digestKey(version))
+
+	return nil
+}
+
+// Changed ...
+func (s OCIStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+
+	setting := s.settings[ociRepository]
+	setting.Prompt = false
+
+	repository, err := setting.Get(s.context, s.io)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	created, err := s.created(s.ref(repository, *file, version))
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "not found") {
+		return time.Time{}, nil
+	}
+
+	return created, err
+}
+
+// ETag ...
+func (s OCIStore) ETag(file *catalog.File, version string) (string, error) {
+
+	if digest, found := file.Data[digestKey(version)]; found {
+		return digest, nil
+	}
+
+	setting := s.settings[ociRepository]
+	setting.Prompt = false
+
+	repository, err := setting.Get(s.context, s.io)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := run(exec.Command(orasCLI, "resolve", s.ref(repository, *file, version)))
+	if err != nil {
+		return "", err
+	}
+
+	return ociDigestRegex.FindString(string(out)), nil
+}
+
+// Reencrypt ...
+func (s OCIStore) Reencrypt(file *catalog.File, oldKey, version string) ([]byte, error) {
+
+	encrypted, _, err := s.fetch(file, version)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	key, err := s.key(file)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	b, rotated, err := rotate(*file, version, encrypted, key, oldKey, s.context)
+	if err != nil || !rotated {
+		return b, err
+	}
+
+	return b, s.Push(file, b, version)
+}
+
+// Encryption ...
+func (s OCIStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	location, err := s.Locate(file, "", version)
+	return contract.Encryption{Mechanism: contract.EncryptionClientSide, Detail: location.Encryption}, nil, err
+}
+
+// Locate ...
+func (s OCIStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	setting := s.settings[ociRepository]
+	setting.Prompt = false
+
+	repository, err := setting.Get(s.context, s.io)
+	if err != nil {
+		return contract.Location{}, err
+	}
+
+	location := contract.Location{
+		Remote:      s.ref(repository, *file, version),
+		Credentials: "registry login used by the oras CLI",
+		Encryption:  fmt.Sprintf("client-side AES-256 key %s from %s vault", ceKeyName, file.Vaults.Access),
+	}
+
+	if file.Data[keyDerivationToken] == keyDerivationHKDF {
+		location.Encryption = fmt.Sprintf("client-side AES-256 key derived (%s) for this file from %s in %s vault", keyDerivationHKDF, mkKeyName, file.Vaults.Access)
+	}
+
+	if digest, found := file.Data[digestKey(version)]; found {
+		location.Remote = fmt.Sprintf("%s@%s (pinned from %s)", repository, digest, location.Remote)
+	}
+
+	return location, nil
+}
+
+func init() {
+	s := new(OCIStore)
+	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: ociRepository, Required: true},
+		{Key: ociDigest, Prefix: true},
+		{Key: keyDerivationToken, Values: []string{keyDerivationHKDF}},
+		{Key: keyIDToken, Prefix: true},
+	})
+}
+
+//------------------------------------------
+//- Registry helpers.
+//------------------------------------------
+func (s OCIStore) ref(repository string, file catalog.File, version string) string {
+	tag := file.Key()
+
+	if len(version) > 0 {
+		tag = fmt.Sprintf("%s-%s", tag, regexp.MustCompile(`[^\w.-]`).ReplaceAllString(version, "-"))
+	}
+
+	return fmt.Sprintf("%s:%s", repository, tag)
+}
+
+// fetch pulls the encrypted bundle, using the pinned digest when
+// available, and returns it with the reference pulled.
+func (s OCIStore) fetch(file *catalog.File, version string) ([]byte, string, error) {
+	repository, err := s.setting(ociRepository)
+	if err != nil {
+		return []byte{}, "", err
+	}
+
+	ref := s.ref(repository, *file, version)
+	if digest, found := file.Data[digestKey(version)]; found {
+		ref = fmt.Sprintf("%s@%s", repository, digest)
+	}
+
+	dir, err := ioutil.TempDir("", "cstore-oci")
+	if err != nil {
+		return []byte{}, ref, err
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := run(exec.Command(orasCLI, "pull", ref, "-o", dir)); err != nil {
+		return []byte{}, ref, err
+	}
+
+	encrypted, err := ioutil.ReadFile(filepath.Join(dir, ociBundleName))
+
+	return encrypted, ref, err
+}
+
+func (s OCIStore) setting(token string) (string, error) {
+	return s.settings[token].Get(s.context, s.io)
+}
+
+func (s OCIStore) encrypt(file *catalog.File, version string, data []byte) ([]byte, error) {
+	key, err := s.key(file)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	recordKey(file, version, key)
+
+	return cipher.Encrypt(key, data)
+}
+
+func (s OCIStore) decrypt(file *catalog.File, data []byte) ([]byte, error) {
+	key, err := s.key(file)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return cipher.Decrypt(key, data)
+}
+
+// key returns the file's encryption key, deriving it from the team
+// master key when the file was pushed with key derivation.
+func (s OCIStore) key(file *catalog.File) (string, error) {
+	if file.Data[keyDerivationToken] != keyDerivationHKDF {
+		return s.setting(clientEncryptionToken)
+	}
+
+	master, err := s.setting(masterKeyToken)
+	if err != nil {
+		return "", err
+	}
+
+	return deriveFileKey(master, s.context, *file)
+}
+
+func (s OCIStore) created(ref string) (time.Time, error) {
+	out, err := run(exec.Command(orasCLI, "manifest", "fetch", ref))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	manifest := struct {
+		Annotations map[string]string `json:"annotations"`
+	}{}
+
+	if err := json.Unmarshal(out, &manifest); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339, manifest.Annotations[ociCreated])
+}
+
+func digestKey(version string) string {
+	if len(version) > 0 {
+		return fmt.Sprintf("%s_%s", ociDigest, strings.ToUpper(version))
+	}
+	return ociDigest
+}
+
+func run(c *exec.Cmd) ([]byte, error) {
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+
+	out, err := c.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return out, fmt.Errorf("%s: %s", filepath.Base(c.Path), msg)
+		}
+		return out, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cipher"
+	"github.com/turnerlabs/cstore/components/setting"
+	"github.com/turnerlabs/cstore/components/vault"
+)
+
+// fakeOras saves pushed bundles by digest in the registry directory
+// and pulls them back by digest.
+const fakeOras = `#!/bin/sh
+case "$1" in
+push)
+	digest="sha256:$(sha256sum bundle | cut -d' ' -f1)"
+	cp bundle "$CSTORE_TEST_REGISTRY/$digest"
+	echo "Digest: $digest"
+	;;
+pull)
+	cp "$CSTORE_TEST_REGISTRY/${2#*@}" "$4/bundle"
+	;;
+manifest)
+	echo '{"annotations":{}}'
+	;;
+esac
+`
+
+// testOCIStore puts a fake oras CLI first in the path and returns a
+// store using it.
+func testOCIStore(t *testing.T) (OCIStore, func()) {
+	dir, err := ioutil.TempDir("", "cstore-oci")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, orasCLI), []byte(fakeOras), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	path := os.Getenv("PATH")
+
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	os.Setenv("CSTORE_TEST_REGISTRY", dir)
+	os.Setenv("OCI_REPOSITORY", "registry.example.com/config")
+
+	return OCIStore{
+		context: "my-app",
+		settings: map[string]setting.Setting{
+			ociRepository:         {Group: "OCI", Prop: "REPOSITORY", Vault: vault.EnvVault{}},
+			clientEncryptionToken: {Group: "CSTORE", Prop: "ENCRYPTION_KEY", Vault: vault.EnvVault{}},
+		},
+	}, func() {
+		os.Setenv("PATH", path)
+		os.Unsetenv("CSTORE_TEST_REGISTRY")
+		os.Unsetenv("OCI_REPOSITORY")
+		os.Unsetenv("CSTORE_ENCRYPTION_KEY")
+		os.RemoveAll(dir)
+	}
+}
+
+func TestOCIReencrypt(t *testing.T) {
+	// arrange
+	s, cleanup := testOCIStore(t)
+	defer cleanup()
+
+	oldKey := "0123456789abcdef0123456789abcdef"
+	newKey := "fedcba9876543210fedcba9876543210"
+
+	file := catalog.File{Path: ".env", Type: "env", Data: map[string]string{}}
+	data := []byte("A=1\n")
+
+	os.Setenv("CSTORE_ENCRYPTION_KEY", oldKey)
+
+	if err := s.Push(&file, data, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	before, _, err := s.fetch(&file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("CSTORE_ENCRYPTION_KEY", newKey)
+
+	// act
+	reencrypted, err := s.Reencrypt(&file, oldKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := file.Data[ociDigest]
+
+	again, err := s.Reencrypt(&file, oldKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	after, _, err := s.fetch(&file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(reencrypted) != string(data) || string(again) != string(data) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s %s", data, reencrypted, again)
+	}
+
+	if bytes.Equal(before, after) {
+		t.Error("file was not pushed again")
+	}
+
+	if decrypted, _ := cipher.Decrypt(newKey, after); string(decrypted) != string(data) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", data, decrypted)
+	}
+
+	if file.Data[ociDigest] != digest {
+		t.Error("file using the current key was pushed again")
+	}
+}
+
+func TestOCIReencryptWrongOldKey(t *testing.T) {
+	// arrange
+	s, cleanup := testOCIStore(t)
+	defer cleanup()
+
+	os.Setenv("CSTORE_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+
+	file := catalog.File{Path: ".env", Type: "env", Data: map[string]string{}}
+
+	if err := s.Push(&file, []byte("A=1\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	digest := file.Data[ociDigest]
+
+	os.Setenv("CSTORE_ENCRYPTION_KEY", "fedcba9876543210fedcba9876543210")
+
+	// act
+	_, err := s.Reencrypt(&file, "00000000000000000000000000000000", "")
+
+	// assert
+	if err == nil {
+		t.Error("\nEXPECTED: error for wrong old key")
+	}
+
+	if file.Data[ociDigest] != digest {
+		t.Error("file was pushed using a wrong old key")
+	}
+}
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/cipher"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/pipeline"
+	"github.com/turnerlabs/cstore/components/setting"
+	"github.com/turnerlabs/cstore/components/vault"
+)
+
+// Pipeline wraps a store; so, files are passed through the stages
+// declared for them in the catalog before they are pushed and in
+// reverse after they are pulled. Values of encrypted keys are
+// encrypted before any other stage in every store.
+func Pipeline(st contract.IStore, clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) (contract.IStore, error) {
+	if len(file.Pipeline) == 0 && len(file.EncryptedKeys) == 0 {
+		return st, nil
+	}
+
+	if len(file.Pipeline) > 0 && !st.SupportsFeature(PipelineFeature) {
+		return nil, fmt.Errorf("%s store does not support pipelines", st.Name())
+	}
+
+	chain := pipeline.Chain{}
+
+	if len(file.EncryptedKeys) > 0 {
+		stage, err := newEncryptedKeysStage(clog, file, access, uo, io)
+		if err != nil {
+			return nil, err
+		}
+
+		chain.Add(encryptedKeysStageName, stage)
+	}
+
+	for _, name := range file.Pipeline {
+		stage, err := newStage(name, clog, file, access, uo, io)
+		if err != nil {
+			return nil, err
+		}
+
+		chain.Add(name, stage)
+	}
+
+	return newPipelineStore(st, chain), nil
+}
+
+func newStage(name string, clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) (pipeline.Stage, error) {
+	switch name {
+	case pipeline.GzipStage:
+		return pipeline.Gzip{}, nil
+
+	case pipeline.Base64Stage:
+		return pipeline.Base64{}, nil
+
+	case pipeline.AESStage:
+		key, err := (setting.Setting{
+			Description:  "32 character key used to encrypt the file before it is pushed. Anyone pulling the file will need this key.",
+			Group:        "CSTORE",
+			Prop:         "ENCRYPTION_KEY",
+			Prompt:       uo.Prompt,
+			HideInput:    true,
+			AutoSave:     true,
+			DefaultValue: cipher.GenerateAES256Key(),
+			Vault:        access,
+		}).Get(clog.Context, io)
+		if err != nil {
+			return nil, err
+		}
+
+		return pipeline.AES{Key: key}, nil
+
+	case pipeline.KMSStage:
+		if _, err := (setting.Setting{
+			Group:        "AWS",
+			Prop:         "REGION",
+			Prompt:       uo.Prompt,
+			AutoSave:     true,
+			DefaultValue: awsDefaultRegion,
+			Vault:        vault.EnvVault{},
+			Shared:       true,
+		}).Get(clog.Context, io); err != nil {
+			return nil, err
+		}
+
+		keyID, err := (setting.Setting{
+			Description: "KMS Key ID used to encrypt the file before it is pushed. Anyone pulling the file will need access to the key.",
+			Group:       "AWS",
+			Prop:        "PIPELINE_KMS_KEY_ID",
+			Prompt:      uo.Prompt,
+			AutoSave:    true,
+			Vault:       file,
+			Shared:      true,
+		}).Get(clog.Context, io)
+		if err != nil {
+			return nil, err
+		}
+
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, err
+		}
+
+		awsExplainDenied(sess)
+
+		return kmsStage{
+			svc:   kms.New(sess),
+			keyID: keyID,
+			context: map[string]*string{
+				"cstore-file": aws.String(file.ContextKey(clog.Context)),
+			},
+		}, nil
+
+	case pipeline.EnvelopeStage:
+		return newEnvelopeStage(clog, file, access, uo, io)
+
+	default:
+		return nil, fmt.Errorf("unknown pipeline stage %s, expected %s", name, strings.Join([]string{
+			pipeline.GzipStage,
+			pipeline.AESStage,
+			pipeline.KMSStage,
+			pipeline.EnvelopeStage,
+			pipeline.Base64Stage,
+		}, ", "))
+	}
+}
+
+// kmsStage encrypts files with an AWS KMS key. The encryption context
+// binds the encrypted file to its catalog entry.
+type kmsStage struct {
+	svc     *kms.KMS
+	keyID   string
+	context map[string]*string
+}
+
+// Encode ...
+func (k kmsStage) Encode(data []byte) ([]byte, error) {
+	output, err := k.svc.Encrypt(&kms.EncryptInput{
+		KeyId:             &k.keyID,
+		Plaintext:         data,
+		EncryptionContext: k.context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.CiphertextBlob, nil
+}
+
+// Decode ...
+func (k kmsStage) Decode(data []byte) ([]byte, error) {
+	output, err := k.svc.Decrypt(&kms.DecryptInput{
+		CiphertextBlob:    data,
+		EncryptionContext: k.context,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Plaintext, nil
+}
+
+// pipelineStore encodes files pushed to and decodes files pulled from
+// the store it wraps.
+type pipelineStore struct {
+	forwardingStore
+
+	chain pipeline.Chain
+}
+
+func newPipelineStore(st contract.IStore, chain pipeline.Chain) pipelineStore {
+	return pipelineStore{
+		forwardingStore: forwardingStore{
+			IStore: st,
+			encode: func(file *catalog.File, data []byte) ([]byte, error) {
+				return chain.Encode(data)
+			},
+			decode: func(file *catalog.File, data []byte) ([]byte, error) {
+				return chain.Decode(data)
+			},
+		},
+		chain: chain,
+	}
+}
+
+// Encryption ...
+func (s pipelineStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	if pipeline.Encrypts(file.Pipeline) {
+		return contract.Encryption{
+			Mechanism: contract.EncryptionClientSide,
+			Detail:    fmt.Sprintf("pipeline %s", strings.Join(file.Pipeline, " -> ")),
+		}, nil, nil
+	}
+
+	return s.forwardingStore.Encryption(file, version)
+}
+
+// Locate ...
+func (s pipelineStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+	location, err := s.forwardingStore.Locate(file, key, version)
+	if err != nil {
+		return location, err
+	}
+
+	location.Encryption = fmt.Sprintf("%s after pipeline %s", location.Encryption, strings.Join(s.chain.Names, " -> "))
+
+	return location, nil
+}
+
+func init() {
+	catalog.RegisterCommonData(catalog.DataField{Key: "AWS_PIPELINE_KMS_KEY_ID"})
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/pipeline"
+)
+
+// resumableKeyStore records the keys and data of resumed pushes.
+type resumableKeyStore struct {
+	keyStore
+
+	resumed []string
+	data    []byte
+}
+
+func (s *resumableKeyStore) Resume(file *catalog.File, fileData []byte, version string, keys []string) error {
+	s.resumed, s.data = keys, fileData
+	return nil
+}
+
+func TestPipelineForwardsResume(t *testing.T) {
+	// arrange
+	s := resumableKeyStore{keyStore: keyStore{keys: map[string]string{}}}
+
+	chain := pipeline.Chain{}
+	chain.Add(pipeline.Base64Stage, pipeline.Base64{})
+
+	st := newPipelineStore(&s, chain)
+
+	// act
+	resumable, ok := contract.IStore(st).(contract.IResumableStore)
+	if !ok {
+		t.Fatal("\nEXPECTED: pipeline store to resume pushes")
+	}
+
+	err := resumable.Resume(&catalog.File{Path: ".env", Type: "env"}, []byte("A=1\n"), "", []string{"A"})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.resumed) != 1 || s.resumed[0] != "A" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "A", s.resumed)
+	}
+
+	if string(s.data) != "QT0xCg==" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "QT0xCg==", s.data)
+	}
+
+	if Unwrap(st) != contract.IStore(&s) {
+		t.Errorf("\nEXPECTED: %T \nACTUAL: %T", &s, Unwrap(st))
+	}
+}
+
+func TestPipelineResumeUnsupported(t *testing.T) {
+	// arrange
+	st := newPipelineStore(&keyStore{keys: map[string]string{}}, pipeline.Chain{})
+
+	// act
+	err := st.Resume(&catalog.File{Path: ".env", Type: "env"}, []byte("A=1\n"), "", []string{"A"})
+
+	// assert
+	expected := "harbor store cannot resume pushes"
+	if err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+
+	if _, ok := Unwrap(st).(contract.IResumableStore); ok {
+		t.Error("\nEXPECTED: unwrapped store not to resume pushes")
+	}
+}
@@ -0,0 +1,271 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/logger"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/setting"
+)
+
+//------------------------------------------
+//- Stores shipped as separate programs.
+//-
+//- A program on the path named like
+//- "cstore-store-acme" is added as the
+//- "acme" store. Each store action runs the
+//- program with the action as the argument,
+//- a PluginRequest as JSON on stdin, and
+//- reads a PluginResponse as JSON from
+//- stdout. Failures exit non-zero with the
+//- reason on stderr.
+//------------------------------------------
+
+// PluginPrefix is prepended to a store name to find the store program
+// on the path.
+const PluginPrefix = "cstore-store-"
+
+// PluginDescription is returned by the "describe" action.
+type PluginDescription struct {
+	Description string          `json:"description"`
+	Features    []string        `json:"features"`
+	FileTypes   []string        `json:"file_types"`
+	Settings    []PluginSetting `json:"settings"`
+}
+
+// PluginSetting is a value the store needs, like a URL or token, that
+// is asked for once and saved. Secret values are saved in the access
+// vault; others are saved in the catalog.
+type PluginSetting struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	DefaultValue string `json:"default"`
+	Secret       bool   `json:"secret"`
+}
+
+// PluginRequest is written as JSON to the store program's stdin. Data
+// is base64 encoded.
+type PluginRequest struct {
+	Context  string            `json:"context"`
+	Path     string            `json:"path"`
+	Key      string            `json:"key"`
+	Type     string            `json:"type"`
+	Version  string            `json:"version,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	FileData map[string]string `json:"file_data,omitempty"`
+	Settings map[string]string `json:"settings"`
+}
+
+// PluginResponse is read as JSON from the store program's stdout.
+// FileData is saved with the file in the catalog, like remote ids.
+type PluginResponse struct {
+	Data         []byte            `json:"data,omitempty"`
+	FileData     map[string]string `json:"file_data,omitempty"`
+	LastModified time.Time         `json:"last_modified,omitempty"`
+}
+
+// PluginStore ...
+type PluginStore struct {
+	name    string
+	binary  string
+	details PluginDescription
+
+	context  string
+	settings map[string]string
+}
+
+// Name ...
+func (s PluginStore) Name() string {
+	return s.name
+}
+
+// SupportsFeature ...
+func (s PluginStore) SupportsFeature(feature string) bool {
+	for _, f := range s.details.Features {
+		if strings.EqualFold(f, feature) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SupportsFileType ...
+func (s PluginStore) SupportsFileType(fileType string) bool {
+	for _, t := range s.details.FileTypes {
+		if t == "*" || strings.EqualFold(t, fileType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Description ...
+func (s PluginStore) Description() string {
+	return fmt.Sprintf(`
+	%s
+
+	program: %s
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/PLUGINS.md
+`, strings.TrimSpace(s.details.Description), s.binary)
+}
+
+// Pre ...
+func (s *PluginStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.context = clog.Context
+	s.settings = map[string]string{}
+
+	group := strings.ToUpper(strings.Replace(s.name, "-", "_", -1))
+
+	for _, ps := range s.details.Settings {
+		st := setting.Setting{
+			Description:  ps.Description,
+			Group:        group,
+			Prop:         strings.ToUpper(ps.Name),
+			DefaultValue: ps.DefaultValue,
+			Prompt:       uo.Prompt,
+			AutoSave:     true,
+			HideInput:    ps.Secret,
+			Vault:        access,
+		}
+
+		if !ps.Secret {
+			st.DefaultValue = clog.GetAnyDataBy(fmt.Sprintf("%s_%s", st.Group, st.Prop), ps.DefaultValue)
+			st.Vault = file
+			st.Shared = true
+		}
+
+		value, err := st.Get(clog.Context, io)
+		if err != nil {
+			return err
+		}
+
+		s.settings[ps.Name] = value
+	}
+
+	return nil
+}
+
+// Push ...
+func (s PluginStore) Push(file *catalog.File, fileData []byte, version string) error {
+	r, err := s.run("push", file, fileData, version)
+	if err != nil {
+		return err
+	}
+
+	file.AddData(r.FileData)
+
+	return nil
+}
+
+// Pull ...
+func (s PluginStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+	r, err := s.run("pull", file, nil, version)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, err
+	}
+
+	return r.Data, contract.Attributes{
+		LastModified: r.LastModified,
+	}, nil
+}
+
+// Purge ...
+func (s PluginStore) Purge(file *catalog.File, version string) error {
+	_, err := s.run("purge", file, nil, version)
+
+	return err
+}
+
+// Changed ...
+func (s PluginStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+	r, err := s.run("changed", file, fileData, version)
+
+	return r.LastModified, err
+}
+
+func (s PluginStore) run(action string, file *catalog.File, data []byte, version string) (PluginResponse, error) {
+	input, err := json.Marshal(PluginRequest{
+		Context:  s.context,
+		Path:     file.Path,
+		Key:      file.Key(),
+		Type:     file.Type,
+		Version:  version,
+		Data:     data,
+		FileData: file.Data,
+		Settings: s.settings,
+	})
+	if err != nil {
+		return PluginResponse{}, err
+	}
+
+	r := PluginResponse{}
+
+	err = runPlugin(s.binary, action, input, &r)
+
+	return r, err
+}
+
+// loadPlugins adds each store program on the path unless a built-in
+// store has the same name. Programs that cannot describe themselves
+// are skipped.
+func loadPlugins() {
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, f := range files {
+			name := strings.TrimPrefix(f.Name(), PluginPrefix)
+
+			if name == f.Name() || len(name) == 0 || f.IsDir() || f.Mode()&0111 == 0 {
+				continue
+			}
+
+			if _, found := stores[name]; found {
+				continue
+			}
+
+			s := &PluginStore{
+				name:   name,
+				binary: filepath.Join(dir, f.Name()),
+			}
+
+			if err := runPlugin(s.binary, "describe", []byte("{}"), &s.details); err != nil {
+				logger.L.Printf("store plugin %s skipped (%s)", s.binary, err)
+				continue
+			}
+
+			stores[name] = s
+		}
+	}
+}
+
+func runPlugin(binary, action string, input []byte, out interface{}) error {
+	c := exec.Command(binary, action)
+	c.Stdin = bytes.NewReader(input)
+
+	b, err := run(c)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("%s returned invalid JSON for %s (%s)", filepath.Base(binary), action, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+)
+
+// testPlugin saves pushed files next to the program and returns them
+// when pulled.
+const testPlugin = `#!/bin/sh
+dir=$(dirname "$0")
+request=$(cat)
+case "$1" in
+describe) echo '{"features":["VERSIONING"],"file_types":["env"]}' ;;
+push) echo "$request" | sed 's/.*"data":"\([^"]*\)".*/\1/' > "$dir/pushed"; echo '{"file_data":{"ACME_ID":"42"}}' ;;
+pull) echo "{\"data\":\"$(cat "$dir/pushed")\"}" ;;
+*) echo "unsupported action $1" >&2; exit 1 ;;
+esac
+`
+
+func TestPluginPushPull(t *testing.T) {
+	// arrange
+	dir, err := ioutil.TempDir("", "cstore-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, PluginPrefix+"acme-test"), []byte(testPlugin), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+
+	loadPlugins()
+
+	defer delete(stores, "acme-test")
+
+	s, found := stores["acme-test"]
+	if !found {
+		t.Fatal("plugin not loaded")
+	}
+
+	file := catalog.File{Path: ".env", Type: "env", Data: map[string]string{}}
+
+	// act
+	if err := s.Push(&file, []byte("A=1\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled, _, err := s.Pull(&file, "")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(pulled) != "A=1\n" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "A=1", pulled)
+	}
+
+	if file.Data["ACME_ID"] != "42" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", "42", file.Data["ACME_ID"])
+	}
+
+	if !s.SupportsFeature(VersionFeature) || s.SupportsFileType("json") {
+		t.Error("plugin features or file types not described")
+	}
+
+	expected := "cstore-store-acme-test: unsupported action purge"
+	if err := s.Purge(&file, ""); err == nil || err.Error() != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
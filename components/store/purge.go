@@ -0,0 +1,60 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+const purgeWorkers = 10
+
+// purgeKeys deletes keys in parallel displaying progress. Keys that
+// fail to delete do not stop the remaining deletes and are returned
+// in a contract.PurgeError.
+func purgeKeys(keys []string, del func(key string) error, io models.IO) error {
+	failed := map[string]string{}
+
+	jobs := make(chan string)
+	wg := sync.WaitGroup{}
+	mu := sync.Mutex{}
+
+	done := 0
+
+	for w := 0; w < purgeWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for key := range jobs {
+				err := del(key)
+
+				mu.Lock()
+				if err != nil {
+					failed[key] = err.Error()
+				}
+				done++
+				fmt.Fprintf(io.UserOutput, "\rDeleting keys... %d/%d", done, len(keys))
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	if len(keys) > 0 {
+		fmt.Fprintln(io.UserOutput)
+	}
+
+	if len(failed) > 0 {
+		return contract.PurgeError{Failed: failed}
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package store
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+)
+
+func TestPurgeKeysReportsFailedKeys(t *testing.T) {
+	// arrange
+	keys := []string{"/ctx/a", "/ctx/b", "/ctx/c"}
+
+	del := func(key string) error {
+		if key == "/ctx/b" {
+			return errors.New("throttled")
+		}
+		return nil
+	}
+
+	// act
+	err := purgeKeys(keys, del, models.IO{UserOutput: ioutil.Discard})
+
+	// assert
+	perr, ok := err.(contract.PurgeError)
+	if !ok {
+		t.Fatalf("\nEXPECTED: %s \nACTUAL: %v", "PurgeError", err)
+	}
+
+	if len(perr.Failed) != 1 || perr.Failed["/ctx/b"] != "throttled" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "/ctx/b (throttled)", perr.Failed)
+	}
+}
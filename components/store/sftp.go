@@ -0,0 +1,483 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+//------------------------------------------
+//- A minimal SFTP version 3 client with the
+//- requests needed to save whole files.
+//------------------------------------------
+
+const (
+	sftpInit     = 1
+	sftpVersion  = 2
+	sftpOpen     = 3
+	sftpClose    = 4
+	sftpRead     = 5
+	sftpWrite    = 6
+	sftpRemove   = 13
+	sftpMkdir    = 14
+	sftpStat     = 17
+	sftpRename   = 18
+	sftpStatus   = 101
+	sftpHandle   = 102
+	sftpData     = 103
+	sftpAttrs    = 105
+	sftpProtocol = 3
+
+	sftpFlagRead     = 0x01
+	sftpFlagWrite    = 0x02
+	sftpFlagCreate   = 0x08
+	sftpFlagTruncate = 0x10
+
+	sftpAttrSize        = 0x01
+	sftpAttrUIDGID      = 0x02
+	sftpAttrPermissions = 0x04
+	sftpAttrTimes       = 0x08
+
+	sftpOK       = 0
+	sftpEOF      = 1
+	sftpNoFile   = 2
+	sftpDenied   = 3
+	sftpChunk    = 32 * 1024
+	sftpMaxReply = 256 * 1024
+)
+
+var errSFTPShortPacket = errors.New("sftp packet is too short")
+
+// sftpStatusError is returned when the server responds to a request
+// with a failure status.
+type sftpStatusError struct {
+	Code    uint32
+	Message string
+}
+
+func (e sftpStatusError) Error() string {
+	return fmt.Sprintf("sftp status %d: %s", e.Code, e.Message)
+}
+
+// sftpNotFound returns true when the error means the file does not exist.
+func sftpNotFound(err error) bool {
+	status, ok := err.(sftpStatusError)
+	return ok && status.Code == sftpNoFile
+}
+
+// sftpFileInfo contains the attributes of a remote file.
+type sftpFileInfo struct {
+	size     uint64
+	modified time.Time
+}
+
+type sftpClient struct {
+	r io.Reader
+	w io.WriteCloser
+
+	conn *ssh.Client
+
+	id uint32
+	mu sync.Mutex
+}
+
+// dialSFTP connects to the host and starts the sftp subsystem.
+func dialSFTP(addr string, config *ssh.ClientConfig) (*sftpClient, error) {
+	conn, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := conn.NewSession()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	r, err := session.StdoutPipe()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("sftp is not enabled on %s (%s)", addr, err)
+	}
+
+	c, err := newSFTPClient(r, w)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c.conn = conn
+
+	return c, nil
+}
+
+// newSFTPClient negotiates the protocol version over the streams.
+func newSFTPClient(r io.Reader, w io.WriteCloser) (*sftpClient, error) {
+	c := &sftpClient{r: r, w: w}
+
+	init := sftpPacket{}
+	init.uint32(sftpProtocol)
+
+	if err := c.send(sftpInit, init.b); err != nil {
+		return nil, err
+	}
+
+	typ, _, err := c.receive()
+	if err != nil {
+		return nil, err
+	}
+
+	if typ != sftpVersion {
+		return nil, fmt.Errorf("unexpected sftp response %d to init", typ)
+	}
+
+	return c, nil
+}
+
+// Close ends the session and connection.
+func (c *sftpClient) Close() error {
+	c.w.Close()
+
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+
+	return nil
+}
+
+func (c *sftpClient) send(typ byte, payload []byte) error {
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header, uint32(len(payload)+1))
+	header[4] = typ
+
+	if _, err := c.w.Write(append(header, payload...)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *sftpClient) receive() (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(c.r, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header)
+	if length < 1 || length > sftpMaxReply {
+		return 0, nil, fmt.Errorf("invalid sftp packet length %d", length)
+	}
+
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return header[4], payload, nil
+}
+
+// request sends a request and returns the response without the
+// request id. Failure statuses are returned as errors.
+func (c *sftpClient) request(typ byte, build func(p *sftpPacket)) (byte, *sftpReader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.id++
+
+	p := sftpPacket{}
+	p.uint32(c.id)
+	build(&p)
+
+	if err := c.send(typ, p.b); err != nil {
+		return 0, nil, err
+	}
+
+	respType, payload, err := c.receive()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	r := &sftpReader{b: payload}
+
+	if id, err := r.uint32(); err != nil || id != c.id {
+		return 0, nil, fmt.Errorf("unexpected sftp response id %d", id)
+	}
+
+	if respType == sftpStatus {
+		code, err := r.uint32()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if code != sftpOK {
+			message, _ := r.string()
+			return respType, r, sftpStatusError{Code: code, Message: message}
+		}
+	}
+
+	return respType, r, nil
+}
+
+func (c *sftpClient) open(name string, flags uint32) (string, error) {
+	typ, r, err := c.request(sftpOpen, func(p *sftpPacket) {
+		p.string(name)
+		p.uint32(flags)
+		p.uint32(sftpAttrPermissions)
+		p.uint32(0600)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if typ != sftpHandle {
+		return "", fmt.Errorf("unexpected sftp response %d to open", typ)
+	}
+
+	return r.string()
+}
+
+func (c *sftpClient) close(handle string) error {
+	_, _, err := c.request(sftpClose, func(p *sftpPacket) {
+		p.string(handle)
+	})
+	return err
+}
+
+// ReadFile returns the contents of the remote file.
+func (c *sftpClient) ReadFile(name string) ([]byte, error) {
+	handle, err := c.open(name, sftpFlagRead)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close(handle)
+
+	data := []byte{}
+
+	for {
+		typ, r, err := c.request(sftpRead, func(p *sftpPacket) {
+			p.string(handle)
+			p.uint64(uint64(len(data)))
+			p.uint32(sftpChunk)
+		})
+		if status, ok := err.(sftpStatusError); ok && status.Code == sftpEOF {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if typ != sftpData {
+			return nil, fmt.Errorf("unexpected sftp response %d to read", typ)
+		}
+
+		chunk, err := r.string()
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, chunk...)
+	}
+}
+
+// WriteFile replaces the remote file with the data.
+func (c *sftpClient) WriteFile(name string, data []byte) error {
+	handle, err := c.open(name, sftpFlagWrite|sftpFlagCreate|sftpFlagTruncate)
+	if err != nil {
+		return err
+	}
+
+	for offset := 0; offset < len(data); offset += sftpChunk {
+		end := offset + sftpChunk
+		if end > len(data) {
+			end = len(data)
+		}
+
+		if _, _, err := c.request(sftpWrite, func(p *sftpPacket) {
+			p.string(handle)
+			p.uint64(uint64(offset))
+			p.string(string(data[offset:end]))
+		}); err != nil {
+			c.close(handle)
+			return err
+		}
+	}
+
+	return c.close(handle)
+}
+
+// Stat returns the size and modified time of the remote file.
+func (c *sftpClient) Stat(name string) (sftpFileInfo, error) {
+	typ, r, err := c.request(sftpStat, func(p *sftpPacket) {
+		p.string(name)
+	})
+	if err != nil {
+		return sftpFileInfo{}, err
+	}
+
+	if typ != sftpAttrs {
+		return sftpFileInfo{}, fmt.Errorf("unexpected sftp response %d to stat", typ)
+	}
+
+	return r.attrs()
+}
+
+// Remove deletes the remote file.
+func (c *sftpClient) Remove(name string) error {
+	_, _, err := c.request(sftpRemove, func(p *sftpPacket) {
+		p.string(name)
+	})
+	return err
+}
+
+// Rename moves the remote file. The new name must not exist.
+func (c *sftpClient) Rename(oldName, newName string) error {
+	_, _, err := c.request(sftpRename, func(p *sftpPacket) {
+		p.string(oldName)
+		p.string(newName)
+	})
+	return err
+}
+
+// MkdirAll creates the remote folder and any missing parents.
+func (c *sftpClient) MkdirAll(dir string) error {
+	if dir == "." || dir == "/" || len(dir) == 0 {
+		return nil
+	}
+
+	_, err := c.Stat(dir)
+	if err == nil || !sftpNotFound(err) {
+		return err
+	}
+
+	if err := c.MkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+
+	_, _, err = c.request(sftpMkdir, func(p *sftpPacket) {
+		p.string(dir)
+		p.uint32(sftpAttrPermissions)
+		p.uint32(0700)
+	})
+	return err
+}
+
+//------------------------------------------
+//- Packet encoding.
+//------------------------------------------
+
+type sftpPacket struct {
+	b []byte
+}
+
+func (p *sftpPacket) uint32(v uint32) {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	p.b = append(p.b, b...)
+}
+
+func (p *sftpPacket) uint64(v uint64) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	p.b = append(p.b, b...)
+}
+
+func (p *sftpPacket) string(s string) {
+	p.uint32(uint32(len(s)))
+	p.b = append(p.b, s...)
+}
+
+type sftpReader struct {
+	b []byte
+}
+
+func (r *sftpReader) uint32() (uint32, error) {
+	if len(r.b) < 4 {
+		return 0, errSFTPShortPacket
+	}
+
+	v := binary.BigEndian.Uint32(r.b)
+	r.b = r.b[4:]
+
+	return v, nil
+}
+
+func (r *sftpReader) uint64() (uint64, error) {
+	if len(r.b) < 8 {
+		return 0, errSFTPShortPacket
+	}
+
+	v := binary.BigEndian.Uint64(r.b)
+	r.b = r.b[8:]
+
+	return v, nil
+}
+
+func (r *sftpReader) string() (string, error) {
+	length, err := r.uint32()
+	if err != nil {
+		return "", err
+	}
+
+	if uint32(len(r.b)) < length {
+		return "", errSFTPShortPacket
+	}
+
+	s := string(r.b[:length])
+	r.b = r.b[length:]
+
+	return s, nil
+}
+
+func (r *sftpReader) attrs() (sftpFileInfo, error) {
+	info := sftpFileInfo{}
+
+	flags, err := r.uint32()
+	if err != nil {
+		return info, err
+	}
+
+	if flags&sftpAttrSize != 0 {
+		if info.size, err = r.uint64(); err != nil {
+			return info, err
+		}
+	}
+
+	if flags&sftpAttrUIDGID != 0 {
+		r.uint32()
+		r.uint32()
+	}
+
+	if flags&sftpAttrPermissions != 0 {
+		r.uint32()
+	}
+
+	if flags&sftpAttrTimes != 0 {
+		r.uint32()
+
+		mtime, err := r.uint32()
+		if err != nil {
+			return info, err
+		}
+
+		info.modified = time.Unix(int64(mtime), 0)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,416 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/models"
+	"github.com/turnerlabs/cstore/components/setting"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	sftpHostToken = "SFTP_HOST"
+	sftpPathToken = "SFTP_PATH"
+
+	// sftpUserEnv, sftpIdentityEnv, and sftpKnownHostsEnv override the
+	// user, private key, and known hosts file used to connect.
+	sftpUserEnv       = "SFTP_USER"
+	sftpIdentityEnv   = "SFTP_IDENTITY_FILE"
+	sftpKnownHostsEnv = "SFTP_KNOWN_HOSTS"
+
+	sftpDefaultPath = "cstore"
+	sftpTempSuffix  = ".cstore-tmp"
+)
+
+var sftpDefaultKeys = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// SFTPStore ...
+type SFTPStore struct {
+	context string
+	host    string
+	path    string
+	user    string
+	auth    string
+
+	client *sftpClient
+
+	io models.IO
+}
+
+// Name ...
+func (s SFTPStore) Name() string {
+	return "sftp"
+}
+
+// SupportsFeature ...
+func (s SFTPStore) SupportsFeature(feature string) bool {
+	switch feature {
+	case VersionFeature, PipelineFeature:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFileType ...
+func (s SFTPStore) SupportsFileType(fileType string) bool {
+	return true
+}
+
+// Description ...
+func (s SFTPStore) Description() string {
+	return `
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/SFTP.md
+`
+}
+
+// Pre ...
+func (s *SFTPStore) Pre(clog catalog.Catalog, file *catalog.File, access contract.IVault, uo cfg.UserOptions, io models.IO) error {
+	s.context = clog.Context
+	s.io = io
+
+	host, err := (setting.Setting{
+		Description:  "SSH host and optional port files are saved on, like config.example.com:22.",
+		Group:        "SFTP",
+		Prop:         "HOST",
+		DefaultValue: clog.GetAnyDataBy(sftpHostToken, ""),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	if len(host) == 0 {
+		return fmt.Errorf("%s is required", sftpHostToken)
+	}
+
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+
+	s.host = host
+
+	remotePath, err := (setting.Setting{
+		Description:  "Folder on the host files are saved in. Relative folders are in the user's home folder.",
+		Group:        "SFTP",
+		Prop:         "PATH",
+		DefaultValue: clog.GetAnyDataBy(sftpPathToken, sftpDefaultPath),
+		Prompt:       uo.Prompt,
+		AutoSave:     true,
+		Vault:        file,
+		Shared:       true,
+	}).Get(clog.Context, io)
+	if err != nil {
+		return err
+	}
+
+	s.path = remotePath
+
+	s.user = os.Getenv(sftpUserEnv)
+	if len(s.user) == 0 {
+		s.user = sftpLocalUser()
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return err
+	}
+
+	signers, err := s.signers(home, access, uo, io)
+	if err != nil {
+		return err
+	}
+
+	hostKeys, err := sftpHostKeys(home)
+	if err != nil {
+		return err
+	}
+
+	client, err := dialSFTP(s.host, &ssh.ClientConfig{
+		User:            s.user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+		HostKeyCallback: hostKeys,
+		Timeout:         30 * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("could not connect to %s as %s (%s)", s.host, s.user, err)
+	}
+
+	s.client = client
+
+	return nil
+}
+
+// Push ...
+func (s SFTPStore) Push(file *catalog.File, fileData []byte, version string) error {
+
+	if len(fileData) == 0 {
+		return errors.New("empty file")
+	}
+
+	remote := s.remotePath(file.Path, version)
+
+	if err := s.client.MkdirAll(path.Dir(remote)); err != nil {
+		return s.explain("mkdir", path.Dir(remote), err)
+	}
+
+	//------------------------------------------
+	//- Write a temporary file and rename it;
+	//- so, a failed push does not leave a
+	//- partial file on the host.
+	//------------------------------------------
+	temp := remote + sftpTempSuffix
+
+	if err := s.client.WriteFile(temp, fileData); err != nil {
+		return s.explain("write", temp, err)
+	}
+
+	if err := s.client.Remove(remote); err != nil && !sftpNotFound(err) {
+		s.client.Remove(temp)
+		return s.explain("remove", remote, err)
+	}
+
+	if err := s.client.Rename(temp, remote); err != nil {
+		return s.explain("rename", remote, err)
+	}
+
+	return nil
+}
+
+// Pull ...
+func (s SFTPStore) Pull(file *catalog.File, version string) ([]byte, contract.Attributes, error) {
+
+	remote := s.remotePath(file.Path, version)
+
+	info, err := s.client.Stat(remote)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, s.explain("stat", remote, err)
+	}
+
+	data, err := s.client.ReadFile(remote)
+	if err != nil {
+		return []byte{}, contract.Attributes{}, s.explain("read", remote, err)
+	}
+
+	return data, contract.Attributes{LastModified: info.modified}, nil
+}
+
+// Purge ...
+func (s SFTPStore) Purge(file *catalog.File, version string) error {
+	remote := s.remotePath(file.Path, version)
+
+	if err := s.client.Remove(remote); err != nil && !sftpNotFound(err) {
+		return s.explain("remove", remote, err)
+	}
+
+	return nil
+}
+
+// Changed ...
+func (s SFTPStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+	info, err := s.client.Stat(s.remotePath(file.Path, version))
+	if sftpNotFound(err) {
+		return time.Time{}, nil
+	}
+
+	return info.modified, err
+}
+
+// ETag ...
+func (s SFTPStore) ETag(file *catalog.File, version string) (string, error) {
+	info, err := s.client.Stat(s.remotePath(file.Path, version))
+	if sftpNotFound(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return etagOf([]string{fmt.Sprint(info.size), info.modified.UTC().Format(time.RFC3339)}), nil
+}
+
+// Encryption ...
+func (s SFTPStore) Encryption(file *catalog.File, version string) (contract.Encryption, map[string]contract.Encryption, error) {
+	location, err := s.Locate(file, "", version)
+	return contract.Encryption{Mechanism: contract.EncryptionNone, Detail: location.Encryption}, nil, err
+}
+
+// Locate ...
+func (s SFTPStore) Locate(file *catalog.File, key, version string) (contract.Location, error) {
+
+	remote := fmt.Sprintf("sftp://%s@%s/%s", s.user, s.host, strings.TrimPrefix(s.remotePath(file.Path, version), "/"))
+	if len(key) > 0 {
+		remote = fmt.Sprintf("%s (key %s)", remote, key)
+	}
+
+	return contract.Location{
+		Remote:      remote,
+		Credentials: s.auth,
+		Encryption:  "none at rest, encrypted in transit by SSH",
+	}, nil
+}
+
+func init() {
+	s := new(SFTPStore)
+	stores[s.Name()] = s
+
+	catalog.RegisterDataSchema(s.Name(), catalog.DataSchema{
+		{Key: sftpHostToken, Required: true},
+		{Key: sftpPathToken},
+	})
+}
+
+//------------------------------------------
+//- SSH helpers.
+//------------------------------------------
+
+func (s SFTPStore) remotePath(filePath, version string) string {
+	if len(version) > 0 {
+		return path.Join(s.path, s.context, version, filepath.ToSlash(filePath))
+	}
+
+	return path.Join(s.path, s.context, filepath.ToSlash(filePath))
+}
+
+// explain returns permission failures as access denied errors.
+func (s SFTPStore) explain(action, remote string, err error) error {
+	if status, ok := err.(sftpStatusError); ok && status.Code == sftpDenied {
+		return contract.AccessDeniedError{
+			Action:    "sftp " + action,
+			Resource:  fmt.Sprintf("%s:%s", s.host, remote),
+			Principal: s.user,
+			Err:       err,
+		}
+	}
+
+	return err
+}
+
+// signers returns the keys from SFTP_IDENTITY_FILE or the keys in the
+// ssh agent and the default key files.
+func (s *SFTPStore) signers(home string, access contract.IVault, uo cfg.UserOptions, io models.IO) ([]ssh.Signer, error) {
+	signers := []ssh.Signer{}
+	sources := []string{}
+
+	keyFiles := []string{}
+
+	if identity := os.Getenv(sftpIdentityEnv); len(identity) > 0 {
+		keyFiles = append(keyFiles, identity)
+	} else {
+		if sock := os.Getenv("SSH_AUTH_SOCK"); len(sock) > 0 {
+			if conn, err := net.Dial("unix", sock); err == nil {
+				if agentSigners, err := agent.NewClient(conn).Signers(); err == nil && len(agentSigners) > 0 {
+					signers = append(signers, agentSigners...)
+					sources = append(sources, "ssh-agent")
+				}
+			}
+		}
+
+		for _, name := range sftpDefaultKeys {
+			keyFile := filepath.Join(home, ".ssh", name)
+			if _, err := os.Stat(keyFile); err == nil {
+				keyFiles = append(keyFiles, keyFile)
+			}
+		}
+	}
+
+	for _, keyFile := range keyFiles {
+		b, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		signer, err := ssh.ParsePrivateKey(b)
+		if _, ok := err.(*ssh.PassphraseMissingError); ok && len(signers) > 0 {
+			// the agent holds the unlocked keys
+			continue
+		}
+
+		if _, ok := err.(*ssh.PassphraseMissingError); ok {
+			passphrase, perr := (setting.Setting{
+				Description: fmt.Sprintf("Passphrase for %s.", keyFile),
+				Group:       "SFTP",
+				Prop:        "KEY_PASSPHRASE",
+				Prompt:      uo.Prompt,
+				HideInput:   true,
+				AutoSave:    true,
+				Vault:       access,
+			}).Get(s.context, io)
+			if perr != nil {
+				return nil, perr
+			}
+
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(b, []byte(passphrase))
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read private key %s (%s)", keyFile, err)
+		}
+
+		signers = append(signers, signer)
+		sources = append(sources, keyFile)
+	}
+
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("no ssh keys found, start ssh-agent or set %s", sftpIdentityEnv)
+	}
+
+	s.auth = fmt.Sprintf("%s (user %s)", strings.Join(sources, ", "), s.user)
+
+	return signers, nil
+}
+
+// sftpHostKeys verifies hosts using the known hosts file; so, files
+// are never sent to an unknown host.
+func sftpHostKeys(home string) (ssh.HostKeyCallback, error) {
+	knownHosts := os.Getenv(sftpKnownHostsEnv)
+	if len(knownHosts) == 0 {
+		knownHosts = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	callback, err := knownhosts.New(knownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("could not read known hosts %s (%s)", knownHosts, err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+
+		if keyErr, ok := err.(*knownhosts.KeyError); ok {
+			if len(keyErr.Want) == 0 {
+				return fmt.Errorf("%s is not in %s, verify the host key and add it with 'ssh-keyscan'", hostname, knownHosts)
+			}
+
+			return fmt.Errorf("host key for %s does not match %s, the host may have been replaced or the connection intercepted", hostname, knownHosts)
+		}
+
+		return err
+	}, nil
+}
+
+func sftpLocalUser() string {
+	for _, env := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if name := os.Getenv(env); len(name) > 0 {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,217 @@
+package store
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+)
+
+// fakeSFTPServer serves the sftp requests used by the store from
+// memory. Folders are not tracked and every folder exists.
+type fakeSFTPServer struct {
+	files    map[string][]byte
+	modified time.Time
+	handles  map[string]string
+	denied   string
+}
+
+func (f *fakeSFTPServer) serve(r io.Reader, w io.WriteCloser) {
+	c := &sftpClient{r: r, w: w}
+
+	for {
+		typ, payload, err := c.receive()
+		if err != nil {
+			return
+		}
+
+		in := &sftpReader{b: payload}
+		out := sftpPacket{}
+
+		if typ == sftpInit {
+			out.uint32(sftpProtocol)
+			c.send(sftpVersion, out.b)
+			continue
+		}
+
+		id, _ := in.uint32()
+		out.uint32(id)
+
+		status := func(code uint32) {
+			out.uint32(code)
+			out.string("")
+			out.string("")
+			c.send(sftpStatus, out.b)
+		}
+
+		name, _ := in.string()
+
+		if strings.HasPrefix(name, f.denied) && len(f.denied) > 0 {
+			status(sftpDenied)
+			continue
+		}
+
+		switch typ {
+		case sftpOpen:
+			flags, _ := in.uint32()
+			if _, found := f.files[name]; !found && flags&sftpFlagCreate == 0 {
+				status(sftpNoFile)
+				continue
+			}
+			if flags&sftpFlagTruncate != 0 {
+				f.files[name] = []byte{}
+			}
+			f.handles[name] = name
+			out.string(name)
+			c.send(sftpHandle, out.b)
+
+		case sftpRead:
+			offset, _ := in.uint64()
+			data := f.files[f.handles[name]]
+			if offset >= uint64(len(data)) {
+				status(sftpEOF)
+				continue
+			}
+			out.string(string(data[offset:]))
+			c.send(sftpData, out.b)
+
+		case sftpWrite:
+			offset, _ := in.uint64()
+			data, _ := in.string()
+			f.files[name] = append(f.files[name][:offset], data...)
+			status(sftpOK)
+
+		case sftpStat:
+			data, found := f.files[name]
+			if !found {
+				if strings.HasSuffix(name, ".env") || strings.HasSuffix(name, sftpTempSuffix) {
+					status(sftpNoFile)
+					continue
+				}
+			}
+			out.uint32(sftpAttrSize | sftpAttrTimes)
+			out.uint64(uint64(len(data)))
+			out.uint32(uint32(f.modified.Unix()))
+			out.uint32(uint32(f.modified.Unix()))
+			c.send(sftpAttrs, out.b)
+
+		case sftpRemove:
+			if _, found := f.files[name]; !found {
+				status(sftpNoFile)
+				continue
+			}
+			delete(f.files, name)
+			status(sftpOK)
+
+		case sftpRename:
+			newName, _ := in.string()
+			f.files[newName] = f.files[name]
+			delete(f.files, name)
+			status(sftpOK)
+
+		default:
+			status(sftpOK)
+		}
+	}
+}
+
+func testSFTPStore(t *testing.T, server *fakeSFTPServer) SFTPStore {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	go server.serve(serverReader, serverWriter)
+
+	client, err := newSFTPClient(clientReader, clientWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return SFTPStore{context: "my-app", host: "config.example.com:22", path: "cstore", user: "deploy", client: client}
+}
+
+func TestSFTPPushPull(t *testing.T) {
+	// arrange
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	server := &fakeSFTPServer{files: map[string][]byte{}, handles: map[string]string{}, modified: modified}
+
+	s := testSFTPStore(t, server)
+	defer s.client.Close()
+
+	file := catalog.File{Path: "config/.env", Type: "env"}
+	data := []byte(strings.Repeat("A=1\n", 10000))
+
+	// act
+	if err := s.Push(&file, []byte("A=0\n"), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Push(&file, data, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled, attr, err := s.Pull(&file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if _, found := server.files["cstore/my-app/config/.env"+sftpTempSuffix]; found {
+		t.Error("temporary file not renamed")
+	}
+
+	if string(pulled) != string(data) {
+		t.Errorf("\nEXPECTED: %d bytes \nACTUAL: %d bytes", len(data), len(pulled))
+	}
+
+	if !attr.LastModified.Equal(modified) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", modified, attr.LastModified)
+	}
+}
+
+func TestSFTPPurgeVersion(t *testing.T) {
+	// arrange
+	server := &fakeSFTPServer{files: map[string][]byte{"cstore/my-app/v1/.env": []byte("A=1\n")}, handles: map[string]string{}}
+
+	s := testSFTPStore(t, server)
+	defer s.client.Close()
+
+	file := catalog.File{Path: ".env", Type: "env"}
+
+	// act
+	err := s.Purge(&file, "v1")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.files) != 0 {
+		t.Errorf("\nEXPECTED: %d \nACTUAL: %d", 0, len(server.files))
+	}
+
+	changed, err := s.Changed(&file, nil, "v1")
+	if err != nil || !changed.IsZero() {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s %v", time.Time{}, changed, err)
+	}
+}
+
+func TestSFTPAccessDenied(t *testing.T) {
+	// arrange
+	server := &fakeSFTPServer{files: map[string][]byte{}, handles: map[string]string{}, denied: "cstore/my-app/.env"}
+
+	s := testSFTPStore(t, server)
+	defer s.client.Close()
+
+	file := catalog.File{Path: ".env", Type: "env"}
+
+	// act
+	err := s.Push(&file, []byte("A=1\n"), "")
+
+	// assert
+	expected := "access denied for sftp write on config.example.com:22:cstore/my-app/.env.cstore-tmp as deploy"
+	if err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", expected, err)
+	}
+}
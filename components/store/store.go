@@ -1,21 +1,51 @@
 package store
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/turnerlabs/cstore/components/catalog"
 	"github.com/turnerlabs/cstore/components/cfg"
+	"github.com/turnerlabs/cstore/components/cipher"
 	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/credential"
 	"github.com/turnerlabs/cstore/components/models"
 	"github.com/turnerlabs/cstore/components/prompt"
 )
 
 const (
 	ceKeyName = "CSTORE_ENCRYPTION_KEY"
+	mkKeyName = "CSTORE_MASTER_KEY"
+
+	// keyDerivationToken marks files encrypted with a key derived from
+	// the team master key instead of a shared encryption key.
+	keyDerivationToken = "KEY_DERIVATION"
+	keyDerivationHKDF  = "hkdf-sha256"
+
+	// keyIDToken records the ID of the key each version of a file was
+	// last encrypted with client-side.
+	keyIDToken = "ENCRYPTION_KEY_ID"
 
 	// VersionFeature ...
 	VersionFeature = "VERSIONING"
 
+	// FIPSFeature indicates the store only uses FIPS-approved client-side
+	// encryption and TLS.
+	FIPSFeature = "FIPS"
+
+	// HashedKeyFeature indicates the store uses the file key remotely,
+	// like in names or key derivation; so, files are pushed again when
+	// the catalog hash changes.
+	HashedKeyFeature = "HASHED_KEY"
+
+	// PipelineFeature indicates the store saves files as opaque bytes;
+	// so, they can be compressed and encrypted by a pipeline.
+	PipelineFeature = "PIPELINE"
+
 	// EnvFeature ...
 	EnvFeature = "env"
 
@@ -25,8 +55,12 @@ const (
 
 var stores = map[string]contract.IStore{}
 
+var plugins sync.Once
+
 // Get ...
 func Get() map[string]contract.IStore {
+	plugins.Do(loadPlugins)
+
 	return stores
 }
 
@@ -34,8 +68,8 @@ func Get() map[string]contract.IStore {
 func Select(file *catalog.File, clog catalog.Catalog, v contract.IVault, uo cfg.UserOptions, io models.IO) (contract.IStore, error) {
 
 	if len(file.Store) > 0 {
-		if store, found := stores[file.Store]; found {
-			return store, store.Pre(clog, file, v, uo, io)
+		if store, found := Get()[file.Store]; found {
+			return prepare(store, clog, file, v, uo, io)
 		}
 
 		return nil, contract.ErrStoreNotFound
@@ -43,7 +77,11 @@ func Select(file *catalog.File, clog catalog.Catalog, v contract.IVault, uo cfg.
 
 	supportedStores := ""
 	for _, s := range Get() {
-		if s.SupportsFileType(file.Type) {
+		if cipher.FIPS() && !s.SupportsFeature(FIPSFeature) {
+			continue
+		}
+
+		if SupportsFile(s, file.Type) {
 			if len(supportedStores) == 0 {
 				supportedStores = s.Name()
 			} else {
@@ -52,14 +90,124 @@ func Select(file *catalog.File, clog catalog.Catalog, v contract.IVault, uo cfg.
 		}
 	}
 
-	val := prompt.GetValFromUser("Remote Store", prompt.Options{
+	val, err := prompt.GetValFromUser("Remote Store", prompt.Options{
 		Description:  fmt.Sprintf("The remote storage solution where %s data will be pushed. (%s)", file.Path, supportedStores),
 		DefaultValue: cfg.DefaultStore,
 	}, io)
+	if err != nil {
+		return nil, err
+	}
 
-	if store, found := stores[val]; found {
-		return store, store.Pre(clog, file, v, uo, io)
+	if store, found := Get()[val]; found {
+		return prepare(store, clog, file, v, uo, io)
 	}
 
 	return nil, contract.ErrStoreNotFound
 }
+
+// prepare gets credentials from the store's credential helper, when
+// one is configured, before the store is made ready. A copy of the
+// ready store is returned; so, files prepared before others are used
+// keep their own settings.
+func prepare(store contract.IStore, clog catalog.Catalog, file *catalog.File, v contract.IVault, uo cfg.UserOptions, io models.IO) (contract.IStore, error) {
+	if cipher.FIPS() && !store.SupportsFeature(FIPSFeature) {
+		return nil, fmt.Errorf("%s store is not FIPS compliant and cannot be used in FIPS mode", store.Name())
+	}
+
+	if helper := uo.CredentialHelper(store.Name()); len(helper) > 0 {
+		creds, err := credential.Get(helper, credential.Request{
+			Store:   store.Name(),
+			Context: clog.Context,
+			File:    file.Path,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := creds.Export(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := store.Pre(clog, file, v, uo, io); err != nil {
+		return nil, err
+	}
+
+	return detach(store), nil
+}
+
+// detach copies a store registered as a pointer, since Pre saves the
+// settings for a file in the store.
+func detach(store contract.IStore) contract.IStore {
+	v := reflect.ValueOf(store)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return store
+	}
+
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+
+	return c.Interface().(contract.IStore)
+}
+
+// etagOf combines the values identifying the remote state of a
+// file into a single etag.
+func etagOf(parts []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// deriveFileKey derives the client-side encryption key for a single file
+// from the team master key.
+func deriveFileKey(master, context string, file catalog.File) (string, error) {
+	return cipher.DeriveKey(master, []byte(context), []byte(file.Key()))
+}
+
+// keyID identifies an encryption key without revealing it.
+func keyID(key string) string {
+	sum := sha256.Sum256([]byte("cstore-key-id:" + key))
+	return hex.EncodeToString(sum[:8])
+}
+
+func keyIDKey(version string) string {
+	if len(version) > 0 {
+		return fmt.Sprintf("%s_%s", keyIDToken, strings.ToUpper(version))
+	}
+	return keyIDToken
+}
+
+// recordKey saves the ID of the key a version of the file was
+// encrypted with. AES-CFB decrypts using any key without an error;
+// so, the ID is the only way to tell which key a file needs.
+func recordKey(file *catalog.File, version, key string) {
+	file.AddData(map[string]string{keyIDKey(version): keyID(key)})
+}
+
+// rotate decrypts a file encrypted client-side with the old key. A
+// file recorded as encrypted with the current key is only decrypted;
+// rotated is false since it does not need to be pushed again.
+func rotate(file catalog.File, version string, encrypted []byte, key, oldKey, context string) (data []byte, rotated bool, err error) {
+	recorded, found := file.Data[keyIDKey(version)]
+
+	if found && recorded == keyID(key) {
+		data, err = cipher.Decrypt(key, append([]byte{}, encrypted...))
+		return data, false, err
+	}
+
+	if file.Data[keyDerivationToken] == keyDerivationHKDF {
+		if oldKey, err = deriveFileKey(oldKey, context, file); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if found && recorded != keyID(oldKey) {
+		return nil, false, fmt.Errorf("%s was not encrypted with the old key", file.Path)
+	}
+
+	data, err = cipher.Decrypt(oldKey, append([]byte{}, encrypted...))
+	if err != nil {
+		return nil, false, fmt.Errorf("old key could not decrypt the file (%s)", err)
+	}
+
+	return data, true, nil
+}
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/subosito/gotenv"
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/structured"
+)
+
+// structuredPathSeparator joins field names in the catalog, like the
+// key types of JSON fields.
+const structuredPathSeparator = "/"
+
+// Structured wraps a key/value store when a JSON, YAML, or TOML file is
+// flattened; so, each value is saved as a key named by its dotted path
+// and the structure is rebuilt when the file is pulled.
+func Structured(st contract.IStore, file *catalog.File) (contract.IStore, error) {
+	if !file.Flatten {
+		return st, nil
+	}
+
+	if !structured.Supports(file.Type) {
+		return nil, fmt.Errorf("%s files cannot be flattened, use json, yaml, or toml", file.Type)
+	}
+
+	if st.SupportsFileType(file.Type) || !st.SupportsFileType(EnvFeature) {
+		return nil, fmt.Errorf("%s store does not save keys individually; so, %s cannot be flattened", st.Name(), file.Path)
+	}
+
+	return newStructuredStore(st), nil
+}
+
+// structuredStore flattens files pushed to and rebuilds files pulled
+// from the key/value store it wraps.
+type structuredStore struct {
+	forwardingStore
+}
+
+func newStructuredStore(st contract.IStore) structuredStore {
+	return structuredStore{forwardingStore{
+		IStore: st,
+		entry:  asFlat,
+		key:    storePath,
+		encode: flatten,
+		decode: rebuild,
+	}}
+}
+
+// SupportsFileType ...
+func (s structuredStore) SupportsFileType(fileType string) bool {
+	return structured.Supports(fileType) || s.IStore.SupportsFileType(fileType)
+}
+
+// Changed ...
+func (s structuredStore) Changed(file *catalog.File, fileData []byte, version string) (time.Time, error) {
+	if values, _, err := structured.Flatten(file.Type, fileData); err == nil {
+		fileData = formatFlat(values)
+	}
+
+	return s.IStore.Changed(asFlat(file), fileData, version)
+}
+
+// asFlat returns a copy of the file entry the wrapped store saves as an
+// env file with field paths in the catalog named like the stored keys.
+func asFlat(file *catalog.File) *catalog.File {
+	f := asEnv(file)
+	f.KeyTypes = storePaths(file.KeyTypes)
+	f.ValueTypes = storePaths(file.ValueTypes)
+	return f
+}
+
+// flatten converts the structured file into the env file pushed to the
+// wrapped store. Strings that look like numbers or booleans are marked
+// in the catalog; so, they are kept as strings when pulled.
+func flatten(file *catalog.File, data []byte) ([]byte, error) {
+	values, strs, err := structured.Flatten(file.Type, data)
+	if err != nil {
+		return nil, fmt.Errorf("could not flatten %s (%s)", file.Path, err)
+	}
+
+	keep := map[string]bool{}
+	for _, key := range strs {
+		keep[key] = true
+	}
+
+	for key := range values {
+		path := catalogPath(key)
+
+		switch {
+		case keep[key]:
+			if file.ValueTypes == nil {
+				file.ValueTypes = map[string]string{}
+			}
+			file.ValueTypes[path] = catalog.ValueTypeString
+		case file.ValueTypes[path] == catalog.ValueTypeString:
+			delete(file.ValueTypes, path)
+		}
+	}
+
+	return formatFlat(values), nil
+}
+
+// rebuild converts the env file pulled from the wrapped store back into
+// the structured file.
+func rebuild(file *catalog.File, data []byte) ([]byte, error) {
+	keep := map[string]bool{}
+	for path, valueType := range file.ValueTypes {
+		if valueType == catalog.ValueTypeString {
+			keep[storePath(path)] = true
+		}
+	}
+
+	rebuilt, err := structured.Unflatten(file.Type, gotenv.Parse(bytes.NewReader(data)), keep)
+	if err != nil {
+		return nil, fmt.Errorf("could not rebuild %s (%s)", file.Path, err)
+	}
+
+	return rebuilt, nil
+}
+
+// formatFlat converts flattened values into the lines of an env file,
+// quoting values the env file format would change.
+func formatFlat(values map[string]string) []byte {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for _, key := range keys {
+		value := values[key]
+
+		if strings.ContainsAny(value, "\n\r\"'#\\$") || strings.TrimSpace(value) != value {
+			value = `"` + flatEscaper.Replace(value) + `"`
+		}
+
+		fmt.Fprintf(&b, "%s=%s\n", key, value)
+	}
+
+	return b.Bytes()
+}
+
+var flatEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+
+func storePaths(paths map[string]string) map[string]string {
+	if paths == nil {
+		return nil
+	}
+
+	m := map[string]string{}
+	for path, value := range paths {
+		m[storePath(path)] = value
+	}
+
+	return m
+}
+
+func storePath(path string) string {
+	return strings.Replace(path, structuredPathSeparator, structured.Separator, -1)
+}
+
+func catalogPath(key string) string {
+	return strings.Replace(key, structured.Separator, structuredPathSeparator, -1)
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+func TestStructuredPushPull(t *testing.T) {
+	// arrange
+	s := keyStore{keys: map[string]string{}, value: 100}
+
+	config := catalog.File{Path: "config.json", Type: "json", Flatten: true}
+
+	st, err := Structured(&s, &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := "{\n    \"db\": {\n        \"host\": \"db.local\",\n        \"port\": 5432\n    },\n    \"version\": \"8080\"\n}\n"
+
+	// act
+	if err := st.Push(&config, []byte(data), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	pulled, _, err := st.Pull(&config, "")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(pulled) != data {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", data, pulled)
+	}
+
+	if s.keys["db.port"] != "5432" {
+		t.Errorf("\nEXPECTED: 5432 \nACTUAL: %s", s.keys["db.port"])
+	}
+
+	if config.ValueTypes["version"] != catalog.ValueTypeString {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", catalog.ValueTypeString, config.ValueTypes["version"])
+	}
+}
+
+func TestStructuredFileStore(t *testing.T) {
+	// arrange
+	config := catalog.File{Path: "config.json", Type: "json", Flatten: true}
+
+	// act
+	_, err := Structured(&S3Store{}, &config)
+
+	// assert
+	if err == nil {
+		t.Error("\nEXPECTED: error \nACTUAL: nil")
+	}
+}
+
+func TestStructuredForwardsResume(t *testing.T) {
+	// arrange
+	s := resumableKeyStore{keyStore: keyStore{keys: map[string]string{}}}
+
+	config := catalog.File{Path: "config.json", Type: "json", Flatten: true}
+
+	st, err := Structured(&s, &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err = st.(contract.IResumableStore).Resume(&config, []byte(`{"db":{"host":"db.local"}}`), "", []string{"db/host"})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.resumed) != 1 || s.resumed[0] != "db.host" {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "db.host", s.resumed)
+	}
+
+	if string(s.data) != "db.host=db.local\n" {
+		t.Errorf("\nEXPECTED: %q \nACTUAL: %q", "db.host=db.local\n", s.data)
+	}
+}
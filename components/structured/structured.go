@@ -0,0 +1,313 @@
+package structured
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Separator joins the names of nested fields into the key of a value.
+const Separator = "."
+
+// Empty objects and arrays have no values; so, they are saved as keys
+// holding these values.
+const (
+	emptyObject = "{}"
+	emptyArray  = "[]"
+)
+
+var number = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// Supports returns true when files of the type can be flattened.
+func Supports(fileType string) bool {
+	switch strings.ToLower(fileType) {
+	case "json", "yaml", "yml", "toml":
+		return true
+	default:
+		return false
+	}
+}
+
+// Flatten converts a structured file into a value for each field named
+// by the path to the field, like "db.port". Array items are named by
+// their index. Values are text; so, strings that would be read back as
+// another type, like "8080", are also returned to be kept as strings.
+func Flatten(fileType string, data []byte) (map[string]string, []string, error) {
+	root, err := parse(fileType, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("%s files must contain an object to be saved as keys", fileType)
+	}
+
+	values := map[string]string{}
+	strs := []string{}
+
+	if err := flatten("", object, values, &strs); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(strs)
+
+	return values, strs, nil
+}
+
+// Unflatten converts the values of flattened fields back into a
+// structured file. Values are typed by their text unless the key is
+// one of the strings.
+func Unflatten(fileType string, values map[string]string, strs map[string]bool) ([]byte, error) {
+	root := map[string]interface{}{}
+
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := interface{}(values[key])
+		if !strs[key] {
+			value = infer(values[key])
+		}
+
+		if err := set(root, strings.Split(key, Separator), value); err != nil {
+			return nil, fmt.Errorf("%s (%s)", key, err)
+		}
+	}
+
+	for name, child := range root {
+		root[name] = arrays(child)
+	}
+
+	return format(fileType, root)
+}
+
+func parse(fileType string, data []byte) (interface{}, error) {
+	var root interface{}
+
+	switch strings.ToLower(fileType) {
+	case "json":
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		if err := d.Decode(&root); err != nil {
+			return nil, err
+		}
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, err
+		}
+		root = stringKeys(root)
+	case "toml":
+		tree, err := toml.LoadBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		root = tree.ToMap()
+	default:
+		return nil, fmt.Errorf("%s files cannot be saved as keys", fileType)
+	}
+
+	if root == nil {
+		root = map[string]interface{}{}
+	}
+
+	return root, nil
+}
+
+func format(fileType string, root map[string]interface{}) ([]byte, error) {
+	switch strings.ToLower(fileType) {
+	case "json":
+		b, err := json.MarshalIndent(root, "", "    ")
+		if err != nil {
+			return b, err
+		}
+		return append(b, '\n'), nil
+	case "yaml", "yml":
+		return yaml.Marshal(root)
+	case "toml":
+		tree, err := toml.TreeFromMap(root)
+		if err != nil {
+			return nil, err
+		}
+		s, err := tree.ToTomlString()
+		return []byte(s), err
+	default:
+		return nil, fmt.Errorf("%s files cannot be saved as keys", fileType)
+	}
+}
+
+func flatten(prefix string, value interface{}, values map[string]string, strs *[]string) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && len(prefix) > 0 {
+			values[prefix] = emptyObject
+			return nil
+		}
+
+		for name, child := range v {
+			if len(name) == 0 || strings.Contains(name, Separator) {
+				return fmt.Errorf("field %q cannot be saved as a key, names must not be empty or contain %q", join(prefix, name), Separator)
+			}
+
+			if err := flatten(join(prefix, name), child, values, strs); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			values[prefix] = emptyArray
+			return nil
+		}
+
+		for i, child := range v {
+			if err := flatten(join(prefix, strconv.Itoa(i)), child, values, strs); err != nil {
+				return err
+			}
+		}
+	case string:
+		values[prefix] = v
+		if _, text := infer(v).(string); !text {
+			*strs = append(*strs, prefix)
+		}
+	case nil:
+		values[prefix] = "null"
+	case bool:
+		values[prefix] = strconv.FormatBool(v)
+	case float64:
+		values[prefix] = formatFloat(v)
+	case float32:
+		values[prefix] = formatFloat(float64(v))
+	case time.Time:
+		values[prefix] = v.Format(time.RFC3339Nano)
+	default:
+		values[prefix] = fmt.Sprint(v)
+	}
+
+	return nil
+}
+
+// infer types a value by its text.
+func infer(value string) interface{} {
+	switch {
+	case value == "true":
+		return true
+	case value == "false":
+		return false
+	case value == "null":
+		return nil
+	case value == emptyObject:
+		return map[string]interface{}{}
+	case value == emptyArray:
+		return []interface{}{}
+	case number.MatchString(value):
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+
+	return value
+}
+
+// formatFloat keeps a decimal point; so, whole floats are not read back
+// as integers.
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEnN") {
+		s += ".0"
+	}
+	return s
+}
+
+func set(object map[string]interface{}, path []string, value interface{}) error {
+	name := path[0]
+
+	if len(path) == 1 {
+		if _, found := object[name]; found {
+			return fmt.Errorf("%s is both a value and an object", name)
+		}
+		object[name] = value
+		return nil
+	}
+
+	child, found := object[name]
+	if !found {
+		child = map[string]interface{}{}
+		object[name] = child
+	}
+
+	nested, ok := child.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s is both a value and an object", name)
+	}
+
+	return set(nested, path[1:], value)
+}
+
+// arrays converts objects named by the indexes 0 to n-1 back into
+// arrays.
+func arrays(value interface{}) interface{} {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	for name, child := range object {
+		object[name] = arrays(child)
+	}
+
+	if len(object) == 0 {
+		return object
+	}
+
+	items := make([]interface{}, len(object))
+	for i := range items {
+		item, found := object[strconv.Itoa(i)]
+		if !found {
+			return object
+		}
+		items[i] = item
+	}
+
+	return items
+}
+
+// stringKeys converts the maps decoded from YAML to maps with string
+// keys.
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, child := range v {
+			m[fmt.Sprint(key)] = stringKeys(child)
+		}
+		return m
+	case []interface{}:
+		for i, child := range v {
+			v[i] = stringKeys(child)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func join(prefix, name string) string {
+	if len(prefix) == 0 {
+		return name
+	}
+	return prefix + Separator + name
+}
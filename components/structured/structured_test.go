@@ -0,0 +1,103 @@
+package structured
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	// arrange
+	data := []byte(`{
+		"db": {"host": "db.local", "port": 5432, "password": "secret"},
+		"debug": false,
+		"ratio": 2.0,
+		"version": "8080",
+		"servers": [{"name": "a"}, {"name": "b"}],
+		"tags": [],
+		"extra": {},
+		"missing": null
+	}`)
+
+	// act
+	values, strs, err := Flatten("json", data)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"db.host":        "db.local",
+		"db.port":        "5432",
+		"db.password":    "secret",
+		"debug":          "false",
+		"ratio":          "2.0",
+		"version":        "8080",
+		"servers.0.name": "a",
+		"servers.1.name": "b",
+		"tags":           "[]",
+		"extra":          "{}",
+		"missing":        "null",
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", expected, values)
+	}
+
+	if !reflect.DeepEqual(strs, []string{"version"}) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", []string{"version"}, strs)
+	}
+}
+
+func TestFlattenRoundTrip(t *testing.T) {
+	// arrange
+	tests := map[string]string{
+		"json": "{\n    \"db\": {\n        \"port\": 5432\n    },\n    \"servers\": [\n        \"a\",\n        \"b\"\n    ],\n    \"version\": \"8080\"\n}\n",
+		"yaml": "db:\n  port: 5432\nservers:\n- a\n- b\nversion: \"8080\"\n",
+		"toml": "version = \"8080\"\n\n[db]\n  port = 5432\n",
+	}
+
+	for fileType, data := range tests {
+		values, strs, err := Flatten(fileType, []byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keep := map[string]bool{}
+		for _, key := range strs {
+			keep[key] = true
+		}
+
+		// act
+		actual, err := Unflatten(fileType, values, keep)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(actual) != data {
+			t.Errorf("\nTYPE: %s \nEXPECTED: %s \nACTUAL: %s", fileType, data, actual)
+		}
+	}
+}
+
+func TestFlattenSeparatorInName(t *testing.T) {
+	// act
+	_, _, err := Flatten("json", []byte(`{"db": {"host.name": "db.local"}}`))
+
+	// assert
+	if err == nil {
+		t.Error("\nEXPECTED: error \nACTUAL: nil")
+	}
+}
+
+func TestUnflattenConflict(t *testing.T) {
+	// act
+	_, err := Unflatten("json", map[string]string{"db": "x", "db.host": "y"}, nil)
+
+	// assert
+	if err == nil {
+		t.Error("\nEXPECTED: error \nACTUAL: nil")
+	}
+}
@@ -0,0 +1,60 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// secretNames matches field names that commonly hold secrets.
+var secretNames = regexp.MustCompile(`(?i)(password|passwd|pwd|secret|token|credential|private_?key|key)$`)
+
+// Suspects returns the paths of JSON fields, like "db/password", with
+// names that suggest a secret and plaintext values instead of tokens.
+// Paths are separated by "/" like token paths and include the index of
+// array items.
+func Suspects(b []byte) ([]string, error) {
+	var f interface{}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+
+	paths := suspects(f, "", "")
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func suspects(f interface{}, path, name string) []string {
+	paths := []string{}
+
+	switch v := f.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			paths = append(paths, suspects(child, join(path, k), k)...)
+		}
+	case []interface{}:
+		for i, child := range v {
+			paths = append(paths, suspects(child, join(path, fmt.Sprint(i)), name)...)
+		}
+	case string:
+		if len(v) > 0 && secretNames.MatchString(name) && !isToken(v) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+func isToken(value string) bool {
+	return strings.Contains(value, "{{") && strings.Contains(value, "}}")
+}
+
+func join(path, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s/%s", path, name)
+}
@@ -23,7 +23,7 @@ func replaceJSON(b []byte, tokens map[string]Token) (d []byte, err error) {
 
 		json, err = sjson.Set(json, path, string(b))
 		if err != nil {
-			fmt.Println(err)
+			return []byte(json), err
 		}
 	}
 
@@ -173,3 +173,25 @@ func TestEnsureENVTokensAreReplacedWithValues(t *testing.T) {
 		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expectedFile, string(b))
 	}
 }
+
+func TestJSONSuspectsAreFound(t *testing.T) {
+	// arrange
+	data := []byte(`{
+		"db": {"host": "db.local", "password": "hunter2", "user": "app"},
+		"apiKey": "{{dev/api::abc123}}",
+		"clients": [{"name": "web", "clientSecret": "s3cr3t"}],
+		"token": ""
+	}`)
+
+	// act
+	paths, err := Suspects(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	expected := "clients/0/clientSecret,db/password"
+	if actual := strings.Join(paths, ","); actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
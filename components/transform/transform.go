@@ -0,0 +1,148 @@
+package transform
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Trim removes leading and trailing whitespace.
+	Trim = "trim"
+
+	// Base64Encode encodes the value using standard base64.
+	Base64Encode = "base64-encode"
+
+	// Base64Decode decodes a standard base64 value.
+	Base64Decode = "base64-decode"
+
+	// JSONMinify removes insignificant whitespace from a JSON value.
+	JSONMinify = "json-minify"
+)
+
+// Value applies each transformation to a value in order.
+func Value(value string, transforms []string) (string, error) {
+	for _, t := range transforms {
+		switch strings.ToLower(t) {
+		case Trim:
+			value = strings.TrimSpace(value)
+		case Base64Encode:
+			value = base64.StdEncoding.EncodeToString([]byte(value))
+		case Base64Decode:
+			b, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return value, fmt.Errorf("%s: %s", t, err)
+			}
+			value = string(b)
+		case JSONMinify:
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, []byte(value)); err != nil {
+				return value, fmt.Errorf("%s: %s", t, err)
+			}
+			value = buf.String()
+		default:
+			return value, fmt.Errorf("unknown transform: %s", t)
+		}
+	}
+
+	return value, nil
+}
+
+// Env applies the transformations declared for each key to the values
+// in an env file. Lines without transformations are left untouched.
+func Env(file []byte, transforms map[string][]string) ([]byte, error) {
+	if len(transforms) == 0 {
+		return file, nil
+	}
+
+	return Map(file, func(key, value string) (string, bool, error) {
+		t, found := transforms[key]
+		if !found {
+			return value, false, nil
+		}
+
+		v, err := Value(value, t)
+
+		return v, true, err
+	})
+}
+
+// Map passes the unquoted value of each key in an env file to fn and
+// writes the value returned when fn replaces it. Other lines, including
+// comments, are left untouched.
+func Map(file []byte, fn func(key, value string) (string, bool, error)) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	scanner := bufio.NewScanner(bytes.NewReader(file))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if prefix, key, value, ok := split(line); ok {
+			v, replace, err := fn(key, unquote(value))
+			if err != nil {
+				return file, fmt.Errorf("%s (%s)", key, err)
+			}
+
+			if replace {
+				line = fmt.Sprintf("%s%s=%s", prefix, key, quote(v))
+			}
+		}
+
+		buffer.WriteString(line)
+		buffer.WriteString("\n")
+	}
+
+	return buffer.Bytes(), scanner.Err()
+}
+
+func split(line string) (string, string, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") {
+		return "", "", "", false
+	}
+
+	prefix := ""
+	if strings.HasPrefix(trimmed, "export ") {
+		prefix = "export "
+		trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "export "))
+	}
+
+	i := strings.Index(trimmed, "=")
+	if i < 1 {
+		return "", "", "", false
+	}
+
+	return prefix, strings.TrimSpace(trimmed[:i]), strings.TrimSpace(trimmed[i+1:]), true
+}
+
+func unquote(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+
+	switch {
+	case value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1]
+	case value[0] == '"' && value[len(value)-1] == '"':
+		r := strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`)
+		return r.Replace(value[1 : len(value)-1])
+	default:
+		return value
+	}
+}
+
+func quote(value string) string {
+	if !strings.ContainsAny(value, " \t\n\"'#\\") {
+		return value
+	}
+
+	if !strings.ContainsAny(value, "'\n") {
+		return fmt.Sprintf("'%s'", value)
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return fmt.Sprintf(`"%s"`, r.Replace(value))
+}
@@ -0,0 +1,78 @@
+package transform
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/subosito/gotenv"
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		value      string
+		transforms []string
+		expected   string
+	}{
+		{"  text \n", []string{Trim}, "text"},
+		{"text", []string{Base64Encode}, "dGV4dA=="},
+		{"dGV4dA==", []string{Base64Decode}, "text"},
+		{"{ \"a\": [1, 2] }", []string{JSONMinify}, `{"a":[1,2]}`},
+		{" text ", []string{Trim, Base64Encode}, "dGV4dA=="},
+	}
+
+	for _, test := range tests {
+		// act
+		actual, err := Value(test.value, test.transforms)
+
+		// assert
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != test.expected {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", test.expected, actual)
+		}
+	}
+}
+
+func TestValueUnknown(t *testing.T) {
+	// act
+	_, err := Value("text", []string{"rot13"})
+
+	// assert
+	if err == nil {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", "error", err)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	// arrange
+	file := []byte("# config\nURL=http://example.com\nCONFIG='{ \"debug\": true }'\nexport CERT=\"line1\\nline2\"\n")
+
+	transforms := map[string][]string{
+		"CONFIG": []string{JSONMinify},
+		"CERT":   []string{Base64Encode},
+	}
+
+	// act
+	actual, err := Env(file, transforms)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := gotenv.Parse(bytes.NewReader(actual))
+
+	expected := map[string]string{
+		"URL":    "http://example.com",
+		"CONFIG": `{"debug":true}`,
+		"CERT":   "bGluZTEKbGluZTI=",
+	}
+
+	for key, e := range expected {
+		if values[key] != e {
+			t.Errorf("\nEXPECTED: %s \nACTUAL: %s", e, values[key])
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -18,6 +19,10 @@ import (
 
 const defaultKMSKey = "aws/secretsmanager"
 
+// currentStage is the staging label of the secret version returned
+// when no version is requested.
+const currentStage = "AWSCURRENT"
+
 type vaultSettings struct {
 	KMSKeyID setting.Setting
 }
@@ -168,10 +173,93 @@ func (v AWSSecretsManagerVault) Get(contextID, group, prop string) (string, erro
 	return "", contract.ErrSecretNotFound
 }
 
+// Revisions ...
+func (v AWSSecretsManagerVault) Revisions(contextID, group string) ([]contract.Revision, error) {
+	svc := secretsmanager.New(v.Session)
+
+	revisions := []contract.Revision{}
+
+	err := svc.ListSecretVersionIdsPages(&secretsmanager.ListSecretVersionIdsInput{
+		SecretId:          aws.String(v.BuildKey(contextID, group, "")),
+		IncludeDeprecated: aws.Bool(true),
+	}, func(page *secretsmanager.ListSecretVersionIdsOutput, lastPage bool) bool {
+		for _, sv := range page.Versions {
+			r := contract.Revision{
+				ID:       aws.StringValue(sv.VersionId),
+				Modified: aws.TimeValue(sv.CreatedDate),
+				Labels:   aws.StringValueSlice(sv.VersionStages),
+			}
+
+			for _, label := range r.Labels {
+				if label == currentStage {
+					r.Latest = true
+				}
+			}
+
+			revisions = append(revisions, r)
+		}
+
+		return true
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == secretsmanager.ErrCodeResourceNotFoundException {
+		return nil, contract.ErrSecretNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Modified.After(revisions[j].Modified)
+	})
+
+	return revisions, nil
+}
+
+// Restore moves the AWSCURRENT staging label to the revision. Secrets
+// Manager labels the replaced version AWSPREVIOUS.
+func (v AWSSecretsManagerVault) Restore(contextID, group, revision string) error {
+	revisions, err := v.Revisions(contextID, group)
+	if err != nil {
+		return err
+	}
+
+	current, found := "", false
+	for _, r := range revisions {
+		if r.Latest {
+			current = r.ID
+		}
+		if r.ID == revision {
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("revision %s not found", revision)
+	}
+
+	if current == revision {
+		return nil
+	}
+
+	input := &secretsmanager.UpdateSecretVersionStageInput{
+		SecretId:        aws.String(v.BuildKey(contextID, group, "")),
+		VersionStage:    aws.String(currentStage),
+		MoveToVersionId: aws.String(revision),
+	}
+
+	if len(current) > 0 {
+		input.RemoveFromVersionId = aws.String(current)
+	}
+
+	_, err = secretsmanager.New(v.Session).UpdateSecretVersionStage(input)
+
+	return err
+}
+
 func getSecret(key string, svc *secretsmanager.SecretsManager) (map[string]string, error) {
 	input := &secretsmanager.GetSecretValueInput{
 		SecretId:     aws.String(key),
-		VersionStage: aws.String("AWSCURRENT"),
+		VersionStage: aws.String(currentStage),
 	}
 
 	output, err := svc.GetSecretValue(input)
@@ -207,4 +295,6 @@ func extractSecrets(tokens map[string]token.Token) map[string]string {
 func init() {
 	v := AWSSecretsManagerVault{}
 	vaults[v.Name()] = &v
+
+	catalog.RegisterCommonData(catalog.DataField{Key: "AWS_VAULT_KMS_KEY_ID"})
 }
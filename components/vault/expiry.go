@@ -0,0 +1,47 @@
+package vault
+
+import (
+	"time"
+
+	"github.com/turnerlabs/cstore/components/contract"
+)
+
+const expirySuffix = "_EXPIRES"
+
+// SetExpiry records when a secret expires when the vault supports it.
+func SetExpiry(v contract.IVault, contextID, group, prop string, expires time.Time) error {
+	if ev, ok := v.(contract.IExpiringVault); ok {
+		return ev.SetExpiry(contextID, group, prop, expires)
+	}
+
+	return nil
+}
+
+// Expiry returns when a secret expires or time.Time{} when the vault
+// does not know.
+func Expiry(v contract.IVault, contextID, group, prop string) (time.Time, error) {
+	if ev, ok := v.(contract.IExpiringVault); ok {
+		return ev.Expiry(contextID, group, prop)
+	}
+
+	return time.Time{}, nil
+}
+
+// setExpiryBeside stores the expiry as a secret next to the secret
+// that expires.
+func setExpiryBeside(v contract.IVault, contextID, group, prop string, expires time.Time) error {
+	return v.Set(contextID, group, prop+expirySuffix, expires.UTC().Format(time.RFC3339))
+}
+
+// expiryBeside reads an expiry stored by setExpiryBeside.
+func expiryBeside(v contract.IVault, contextID, group, prop string) (time.Time, error) {
+	value, err := v.Get(contextID, group, prop+expirySuffix)
+	if err != nil {
+		if err == contract.ErrSecretNotFound {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
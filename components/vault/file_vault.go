@@ -2,6 +2,7 @@ package vault
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/turnerlabs/cstore/components/catalog"
 	"github.com/turnerlabs/cstore/components/cipher"
@@ -48,6 +49,12 @@ func (v FileVault) Pre(clog catalog.Catalog, fileEntry *catalog.File, userPrompt
 
 // Set ...
 func (v FileVault) Set(contextID, group, prop, value string) error {
+	unlock, err := local.Lock(fileName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	eKey, _ := getEncryptionKey()
 	data, _ := get(fileName, eKey)
 
@@ -83,8 +90,23 @@ func create(eKey string, data map[string]string) error {
 	return saveEncryptionKey(eKey)
 }
 
+// SetExpiry ...
+func (v FileVault) SetExpiry(contextID, group, prop string, expires time.Time) error {
+	return setExpiryBeside(v, contextID, group, prop, expires)
+}
+
+// Expiry ...
+func (v FileVault) Expiry(contextID, group, prop string) (time.Time, error) {
+	return expiryBeside(v, contextID, group, prop)
+}
+
 // Delete ...
 func (v FileVault) Delete(contextID, group, prop string) error {
+	unlock, err := local.Lock(fileName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	if local.Missing(fileName) {
 		return nil
@@ -112,6 +134,11 @@ func (v FileVault) Delete(contextID, group, prop string) error {
 
 // Get ...
 func (v FileVault) Get(contextID, group, prop string) (string, error) {
+	unlock, err := local.Lock(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
 
 	if local.Missing(fileName) {
 		return "", contract.ErrSecretNotFound
@@ -137,6 +164,26 @@ func (v FileVault) Get(contextID, group, prop string) (string, error) {
 	return "", contract.ErrSecretNotFound
 }
 
+// List ...
+func (v FileVault) List() (map[string]string, error) {
+	unlock, err := local.Lock(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if local.Missing(fileName) {
+		return map[string]string{}, nil
+	}
+
+	eKey, err := getEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return get(fileName, eKey)
+}
+
 func get(file, key string) (map[string]string, error) {
 
 	data := map[string]string{}
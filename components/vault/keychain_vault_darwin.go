@@ -3,6 +3,7 @@ package vault
 import (
 	"fmt"
 	"os/user"
+	"time"
 
 	keychain "github.com/keybase/go-keychain"
 	"github.com/turnerlabs/cstore/components/catalog"
@@ -78,6 +79,16 @@ func (v KeychainVault) Get(contextID, group, prop string) (string, error) {
 	return getFromKeychain(u.Username, v.BuildKey(contextID, group, prop))
 }
 
+// SetExpiry ...
+func (v KeychainVault) SetExpiry(contextID, group, prop string, expires time.Time) error {
+	return setExpiryBeside(v, contextID, group, prop, expires)
+}
+
+// Expiry ...
+func (v KeychainVault) Expiry(contextID, group, prop string) (time.Time, error) {
+	return expiryBeside(v, contextID, group, prop)
+}
+
 // Delete ...
 func (v KeychainVault) Delete(contextID, group, prop string) error {
 	u, err := user.Current()
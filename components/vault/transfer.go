@@ -0,0 +1,141 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/turnerlabs/cstore/components/contract"
+	"golang.org/x/crypto/pbkdf2"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	exportHeader     = "CSTORE-VAULT-EXPORT-1\n"
+	exportIterations = 600000
+	exportSaltSize   = 16
+)
+
+// ErrWrongPassphrase is returned when an export cannot be decrypted.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted export")
+
+// Export holds the secrets of each vault by vault name and key.
+type Export map[string]map[string]string
+
+// Collect returns the secrets in the named vaults. Vaults unable to
+// list their secrets are skipped.
+func Collect(names []string) (Export, error) {
+	export := Export{}
+
+	for _, name := range names {
+		listable, ok := vaults[name].(contract.IListableVault)
+		if !ok {
+			continue
+		}
+
+		secrets, err := listable.List()
+		if err != nil {
+			return export, err
+		}
+
+		if len(secrets) > 0 {
+			export[name] = secrets
+		}
+	}
+
+	return export, nil
+}
+
+// Listable returns the names of the vaults able to list their secrets.
+func Listable() []string {
+	names := []string{}
+
+	for name, v := range vaults {
+		if _, ok := v.(contract.IListableVault); ok {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Seal encrypts the export using AES-256-GCM with a key derived from
+// the passphrase using PBKDF2.
+func Seal(export Export, passphrase string) ([]byte, error) {
+	plain, err := yaml.Marshal(export)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, exportSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := exportCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append([]byte(exportHeader), salt...)
+	sealed = append(sealed, nonce...)
+
+	return gcm.Seal(sealed, nonce, plain, []byte(exportHeader)), nil
+}
+
+// Open decrypts an export sealed with the passphrase.
+func Open(sealed []byte, passphrase string) (Export, error) {
+	if !bytes.HasPrefix(sealed, []byte(exportHeader)) {
+		return nil, errors.New("not a cStore vault export")
+	}
+
+	sealed = sealed[len(exportHeader):]
+
+	if len(sealed) < exportSaltSize {
+		return nil, ErrWrongPassphrase
+	}
+
+	salt, sealed := sealed[:exportSaltSize], sealed[exportSaltSize:]
+
+	gcm, err := exportCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	nonce, sealed := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	plain, err := gcm.Open(nil, nonce, sealed, []byte(exportHeader))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	export := Export{}
+
+	return export, yaml.Unmarshal(plain, &export)
+}
+
+func exportCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), salt, exportIterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,44 @@
+package vault
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	// arrange
+	export := Export{"file": {"CSTORE_ENCRYPTION_KEY": "abc", "HARBOR_TOKEN": "xyz"}}
+
+	sealed, err := Seal(export, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	opened, err := Open(sealed, "correct horse")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(opened, export) {
+		t.Errorf("\nEXPECTED: %v \nACTUAL: %v", export, opened)
+	}
+}
+
+func TestOpenWrongPassphrase(t *testing.T) {
+	// arrange
+	sealed, err := Seal(Export{"file": {"KEY": "value"}}, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = Open(sealed, "battery staple")
+
+	// assert
+	if err != ErrWrongPassphrase {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %v", ErrWrongPassphrase, err)
+	}
+}
@@ -0,0 +1,263 @@
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/cstore/components/catalog"
+	"github.com/turnerlabs/cstore/components/cipher"
+	"github.com/turnerlabs/cstore/components/contract"
+	"github.com/turnerlabs/cstore/components/display"
+	"github.com/turnerlabs/cstore/components/local"
+	"github.com/turnerlabs/cstore/components/models"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	ageCLI = "age"
+
+	yubiKeyFileName     = "yubikey.vlt"
+	yubiKeyIdentityName = "yubikey-identity.txt"
+
+	yubiKeyIdentityEnvVar  = "CSTORE_YUBIKEY_IDENTITY"
+	yubiKeyRecipientEnvVar = "CSTORE_YUBIKEY_RECIPIENT"
+
+	recipientComment = "Recipient:"
+)
+
+// YubiKeyVault ...
+type YubiKeyVault struct {
+	identity  string
+	recipient string
+
+	// data caches the decrypted vault; so, the YubiKey is only touched
+	// once per command.
+	data map[string]string
+
+	io models.IO
+}
+
+// Name ...
+func (v YubiKeyVault) Name() string {
+	return "yubikey"
+}
+
+// Description ...
+func (v YubiKeyVault) Description() string {
+	return fmt.Sprintf(`
+Secrets, like client-side encryption keys, are stored in the file '%s' encrypted to a YubiKey PIV slot using age and age-plugin-yubikey. Decrypting requires the YubiKey to be present along with its PIN and touch when configured; so, a stolen laptop cannot decrypt configuration.
+
+Create an identity with 'age-plugin-yubikey --generate' and save the output of 'age-plugin-yubikey --identity' to '%s' or set %s to its path.
+
+	detail: https://github.com/turnerlabs/cstore/blob/master/docs/YUBIKEY.md
+`, local.BuildPath(yubiKeyFileName), local.BuildPath(yubiKeyIdentityName), yubiKeyIdentityEnvVar)
+}
+
+// BuildKey ...
+func (v YubiKeyVault) BuildKey(contextID, group, prop string) string {
+	if len(prop) > 0 {
+		return fmt.Sprintf("%s-%s", group, prop)
+	}
+
+	return group
+}
+
+// Pre ...
+func (v *YubiKeyVault) Pre(clog catalog.Catalog, fileEntry *catalog.File, userPrompts bool, io models.IO) error {
+	v.io = io
+
+	if cipher.FIPS() {
+		return errors.New("yubikey vault uses age encryption which is not FIPS-approved")
+	}
+
+	if _, err := exec.LookPath(ageCLI); err != nil {
+		return errors.New("age CLI (age) not found, install it and age-plugin-yubikey before using this vault")
+	}
+
+	v.identity = os.Getenv(yubiKeyIdentityEnvVar)
+	if len(v.identity) == 0 {
+		v.identity = local.BuildPath(yubiKeyIdentityName)
+	}
+
+	b, err := ioutil.ReadFile(v.identity)
+	if err != nil {
+		return fmt.Errorf("YubiKey identity not found, save 'age-plugin-yubikey --identity' output to %s (%s)", v.identity, err)
+	}
+
+	v.recipient = os.Getenv(yubiKeyRecipientEnvVar)
+	if len(v.recipient) == 0 {
+		v.recipient = recipientFrom(b)
+	}
+
+	if len(v.recipient) == 0 {
+		return fmt.Errorf("YubiKey recipient not found in %s, set %s", v.identity, yubiKeyRecipientEnvVar)
+	}
+
+	return nil
+}
+
+// Get ...
+func (v *YubiKeyVault) Get(contextID, group, prop string) (string, error) {
+	if local.Missing(yubiKeyFileName) {
+		return "", contract.ErrSecretNotFound
+	}
+
+	data, err := v.load()
+	if err != nil {
+		return "", err
+	}
+
+	if value, found := data[v.BuildKey(contextID, group, prop)]; found && len(value) > 0 {
+		return value, nil
+	}
+
+	return "", contract.ErrSecretNotFound
+}
+
+// Set ...
+func (v *YubiKeyVault) Set(contextID, group, prop, value string) error {
+	unlock, err := local.Lock(yubiKeyFileName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// The cached vault may have been changed by another process before
+	// the lock was taken; so, it is read again to avoid losing changes.
+	v.data = nil
+
+	data := map[string]string{}
+
+	if !local.Missing(yubiKeyFileName) {
+		if data, err = v.load(); err != nil {
+			return err
+		}
+	}
+
+	data[v.BuildKey(contextID, group, prop)] = value
+
+	return v.save(data)
+}
+
+// SetExpiry ...
+func (v *YubiKeyVault) SetExpiry(contextID, group, prop string, expires time.Time) error {
+	return setExpiryBeside(v, contextID, group, prop, expires)
+}
+
+// Expiry ...
+func (v *YubiKeyVault) Expiry(contextID, group, prop string) (time.Time, error) {
+	return expiryBeside(v, contextID, group, prop)
+}
+
+// Delete ...
+func (v *YubiKeyVault) Delete(contextID, group, prop string) error {
+	if local.Missing(yubiKeyFileName) {
+		return nil
+	}
+
+	unlock, err := local.Lock(yubiKeyFileName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	v.data = nil
+
+	data, err := v.load()
+	if err != nil {
+		return err
+	}
+
+	delete(data, v.BuildKey(contextID, group, prop))
+
+	return v.save(data)
+}
+
+// load decrypts the vault using the YubiKey, which prompts for the
+// PIN and touch as configured for the PIV slot.
+func (v *YubiKeyVault) load() (map[string]string, error) {
+	if v.data != nil {
+		return v.data, nil
+	}
+
+	fmt.Fprintf(display.Loud(v.io.UserOutput), "Unlocking %s vault, touch the YubiKey if it blinks.\n", v.Name())
+
+	var stdout bytes.Buffer
+
+	c := exec.Command(ageCLI, "--decrypt", "--identity", v.identity, local.BuildPath(yubiKeyFileName))
+	c.Stdin = v.io.UserInput
+	c.Stdout = &stdout
+	c.Stderr = display.Loud(v.io.UserOutput)
+
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("YubiKey vault could not be decrypted (%s)", err)
+	}
+
+	data := map[string]string{}
+	if err := yaml.Unmarshal(stdout.Bytes(), &data); err != nil {
+		return nil, err
+	}
+
+	v.data = data
+
+	return data, nil
+}
+
+// save encrypts the vault to the YubiKey recipient. Encrypting does not
+// require the YubiKey.
+func (v *YubiKeyVault) save(data map[string]string) error {
+	b, err := yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	c := exec.Command(ageCLI, "--encrypt", "--recipient", v.recipient)
+	c.Stdin = bytes.NewReader(b)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return fmt.Errorf("%s: %s", ageCLI, msg)
+		}
+		return err
+	}
+
+	if err := local.Update(yubiKeyFileName, "", stdout.Bytes()); err != nil {
+		return err
+	}
+
+	v.data = data
+
+	return nil
+}
+
+// recipientFrom reads the recipient from the comments age-plugin-yubikey
+// writes to identity files.
+func recipientFrom(identity []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(identity))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimLeft(scanner.Text(), "#"))
+
+		if strings.HasPrefix(line, recipientComment) {
+			return strings.TrimSpace(strings.TrimPrefix(line, recipientComment))
+		}
+	}
+
+	return ""
+}
+
+func init() {
+	v := YubiKeyVault{}
+	vaults[v.Name()] = &v
+}
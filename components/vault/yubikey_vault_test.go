@@ -0,0 +1,24 @@
+package vault
+
+import "testing"
+
+func TestRecipientFrom(t *testing.T) {
+	// arrange
+	identity := []byte(`#       Serial: 15373391, Slot: 1
+#         Name: cstore
+#      Created: Wed, 14 Oct 2026 16:22:41 +0000
+#   PIN policy: Once   (A PIN is required once per session, if set)
+# Touch policy: Always (A physical touch is required for every decryption)
+#    Recipient: age1yubikey1qwt50d05nh5vutpdzmlg5wn80xq5negm4uj9ghv0snvdd3yysf5yw3rhl3t
+AGE-PLUGIN-YUBIKEY-1XQZQ5QQYQGJNZLHX0ZP2SQ
+`)
+	expected := "age1yubikey1qwt50d05nh5vutpdzmlg5wn80xq5negm4uj9ghv0snvdd3yysf5yw3rhl3t"
+
+	// act
+	actual := recipientFrom(identity)
+
+	// assert
+	if actual != expected {
+		t.Errorf("\nEXPECTED: %s \nACTUAL: %s", expected, actual)
+	}
+}
@@ -1,9 +1,6 @@
 package main
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/turnerlabs/cstore/cmd"
 	"github.com/turnerlabs/cstore/components/cfg"
 )
@@ -13,8 +10,5 @@ var version = "v2.0.0-rc"
 func main() {
 	cfg.Version = version
 
-	if err := cmd.RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(-1)
-	}
+	cmd.Execute()
 }